@@ -11,20 +11,40 @@ import (
 
 	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"claudefu/internal/audit"
 	"claudefu/internal/auth"
+	"claudefu/internal/backup"
+	"claudefu/internal/control"
 	"claudefu/internal/defaults"
+	"claudefu/internal/events"
+	"claudefu/internal/git"
+	"claudefu/internal/hooks"
+	"claudefu/internal/integrations"
+	"claudefu/internal/logging"
 	"claudefu/internal/mcpserver"
+	"claudefu/internal/metrics"
+	"claudefu/internal/monitor"
+	"claudefu/internal/notifications"
+	"claudefu/internal/outbox"
 	"claudefu/internal/providers"
 	"claudefu/internal/proxy"
 	"claudefu/internal/runtime"
+	"claudefu/internal/schedule"
+	"claudefu/internal/search"
 	"claudefu/internal/session"
 	"claudefu/internal/settings"
+	"claudefu/internal/summary"
+	"claudefu/internal/tasks"
 	"claudefu/internal/terminal"
 	"claudefu/internal/types"
+	"claudefu/internal/usage"
 	"claudefu/internal/watcher"
 	"claudefu/internal/workspace"
 )
 
+// logger is the app subsystem logger (see internal/logging).
+var logger = logging.For(logging.App)
+
 // App struct holds the application state
 type App struct {
 	ctx              context.Context
@@ -38,12 +58,46 @@ type App struct {
 	currentWorkspace *workspace.Workspace
 	workspaceState   *workspace.WorkspaceState // Per-machine runtime state (local/workspace-state/)
 	mcpServer        *mcpserver.MCPService
+	events           *events.Bus      // Runtime, watcher, MCP and app events; the frontend is one subscriber
 	proxy            *proxy.Service   // Cache fix reverse proxy
+	metrics          *metrics.Service // Optional Prometheus /metrics endpoint
 	sessionService   *session.Service // Instant session creation (no CLI wait)
+	tasks            *tasks.Manager   // Workspace task graphs (~/.claudefu/tasks/)
+	summaries        *summary.Service // Cached session summaries (local/summaries/)
+	control          *control.Service // Headless CLI control socket (~/.claudefu/control.sock)
+	backup           *backup.Service  // Config directory git backup (local/backup.git)
+	search           *search.Index    // Session full-text index (local/search.db)
+	usage            *usage.Tracker   // Token usage aggregated from session files
+	schedules        *schedule.Manager // Scheduled agent prompts (~/.claudefu/schedules.json)
+	gitStatus        *git.Service      // Cached, polled git status of agent folders
+	workspaceScanner *monitor.UnreadScanner // Unread counts of non-active workspaces
+	turnDiffs        *git.TurnTracker  // Working tree snapshots around Claude turns
+	outbox           *outbox.Outbox    // In-flight sends (~/.claudefu/outbox.json)
+	inboxDispatch    *inboxDispatcher  // Inbox auto-respond loop
+	notifications    *notifications.Center // Notification center history (~/.claudefu/notifications.json)
+	audit            *audit.Log            // Activity timeline (local/audit.db)
+	hooks            *hooks.Runner         // User scripts run on emitted events (Settings.Hooks)
+	integrations     *integrations.Service // Slack/Discord posts (Workspace.Integrations)
 	terminalManager  *terminal.Manager
 	cliArgs          *CLIArgs         // CLI arguments (e.g., `claudefu .`)
 	reconciledIDs    map[string]string // oldAgentID → newAgentID from registry reconciliation
 
+	// Non-active workspaces kept running (runtime, watcher, MCP server)
+	background   map[string]*backgroundWorkspace
+	backgroundMu sync.Mutex
+
+	// Last failed send per session, for RetryFailedSend
+	failedSends   map[string]failedSend
+	failedSendsMu sync.Mutex
+
+	// Sessions with a title being generated (folder + session ID), so overlapping turns title once
+	autoTitling   map[string]bool
+	autoTitlingMu sync.Mutex
+
+	// Last desktop notification per kind + session/agent, for repeat suppression
+	desktopNotifyLast map[string]time.Time
+	desktopNotifyMu   sync.Mutex
+
 	// Self-update state
 	updateReady   bool   // True when update is downloaded and staged
 	updateVersion string // Version that's staged (e.g., "0.5.10")
@@ -52,7 +106,7 @@ type App struct {
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{events: events.NewBus()}
 }
 
 // =============================================================================
@@ -71,6 +125,9 @@ func (a *App) emitLoadingStatus(status string) {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
+	// Step 0: Subscribe the frontend and internal consumers to the event bus
+	a.subscribeEvents()
+
 	// Step 1: Load persisted state (settings, session timestamps)
 	a.emitLoadingStatus("Initializing settings...")
 	a.loadPersistedState()
@@ -78,6 +135,7 @@ func (a *App) startup(ctx context.Context) {
 	// Step 2: Load current workspace
 	a.emitLoadingStatus("Loading workspace...")
 	a.loadCurrentWorkspace()
+	a.purgeExpiredTrash()
 
 	// Step 3: Initialize file watcher
 	a.emitLoadingStatus("Setting up file watchers...")
@@ -86,12 +144,22 @@ func (a *App) startup(ctx context.Context) {
 	// Step 4: Initialize runtime and start watching
 	a.initializeRuntime()
 
+	// Step 5a: Apply query session retention before sessions are loaded
+	a.applyQuerySessionRetention()
+
 	// Step 5: Start watching all agents (emits per-agent status internally)
 	a.startWatchingAllAgents()
 
 	// Step 5b: Restore per-agent session file watches from persisted SelectedSessionID
 	a.restoreAgentSessionWatches()
 
+	// Step 5c: Open session search index and catch up in the background
+	a.initializeSearch()
+	a.indexWorkspaceSessions()
+
+	// Step 5d: Aggregate token usage in the background
+	a.initializeUsage()
+
 	// Step 6: Initialize Claude CLI
 	a.emitLoadingStatus("Initializing Claude CLI...")
 	a.initializeClaude()
@@ -100,10 +168,46 @@ func (a *App) startup(ctx context.Context) {
 	a.emitLoadingStatus("Starting cache fix proxy...")
 	a.initializeProxy()
 
+	// Step 7b: Register runtime gauges and start /metrics endpoint (if enabled)
+	a.initializeMetrics()
+
+	// Step 7c: Load notification center history (fed by the event bus)
+	a.initializeNotifications()
+
+	// Step 7d: Open the activity timeline (fed by the event bus)
+	a.initializeAudit()
+
+	// Step 7e: Load user hooks (run on event bus events)
+	a.initializeHooks()
+
+	// Step 7f: Post MCP events to the workspace's Slack/Discord (before MCP mounts its endpoint)
+	a.initializeIntegrations()
+
 	// Step 8: Initialize MCP server for inter-agent communication
 	a.emitLoadingStatus("Starting MCP server...")
 	a.initializeMCPServer()
 
+	// Step 8b: Serve the headless CLI control socket
+	a.initializeControlSocket()
+
+	// Step 8c: Start config backups (after MCP so backlog exports are available)
+	a.initializeBackup()
+
+	// Step 8d: Start firing scheduled prompts (needs Claude CLI and runtime)
+	a.initializeSchedules()
+
+	// Step 8e: Poll git status of agent folders and track per-turn diffs
+	a.initializeGitStatus()
+
+	// Step 8e2: Check other workspaces for unread activity
+	a.initializeWorkspaceScanner()
+
+	// Step 8f: Find sends interrupted by the last quit
+	a.initializeOutbox()
+
+	// Step 8g: Resume idle auto-respond agents when high-priority inbox messages arrive
+	a.initializeInboxDispatch()
+
 	// Step 8: Initialize terminal manager
 	a.terminalManager = terminal.NewManager(func(eventType string, args ...any) {
 		if len(args) > 0 {
@@ -180,6 +284,7 @@ func (a *App) loadPersistedState() {
 		return
 	}
 	a.settings = sm
+	a.applyLogSettings(sm.GetSettings())
 
 	// Initialize session manager (for lastViewed timestamps and names)
 	sessMgr, err := settings.NewSessionManager(sm.GetConfigPath())
@@ -203,6 +308,13 @@ func (a *App) loadPersistedState() {
 	// Initialize session service (instant session creation)
 	a.sessionService = session.NewService()
 
+	// Initialize task graph manager (status derives from live session activity)
+	a.tasks = tasks.NewManager(filepath.Join(sm.GetConfigPath(), "tasks"))
+	a.tasks.SetActivityFunc(a.taskSessionActivity)
+
+	// Initialize session summaries (generated on demand with claude --print)
+	a.summaries = summary.NewService(filepath.Join(sm.GetConfigPath(), "local", "summaries"), a.runSummaryPrompt)
+
 	// Ensure default templates exist (UPSERT: create if missing, never overwrite)
 	a.ensureDefaultTemplates()
 }
@@ -268,7 +380,7 @@ func (a *App) loadCurrentWorkspace() {
 	// Reconcile agent IDs against global registry (ensures same folder = same UUID)
 	a.reconciledIDs = a.workspace.SyncAgentIDsFromRegistry(ws)
 	if len(a.reconciledIDs) > 0 {
-		fmt.Printf("[INFO] Reconciled %d agent IDs against global registry\n", len(a.reconciledIDs))
+		logger.Infof("Reconciled %d agent IDs against global registry", len(a.reconciledIDs))
 	}
 
 	// Migrate runtime fields from workspace JSON to local/workspace-state/ (one-time).
@@ -302,12 +414,14 @@ func (a *App) loadCurrentWorkspace() {
 	if a.settings != nil {
 		settings := a.settings.GetSettings()
 		if err := a.workspace.EnsureSifuAgent(ws, settings.SifuEnabled, settings.SifuRootFolder); err != nil {
-			fmt.Printf("[WARN] EnsureSifuAgent: %v\n", err)
+			logger.Warnf("EnsureSifuAgent: %v", err)
 		}
 	}
 
 	a.currentWorkspace = ws
 	a.workspaceState = wsState
+
+	a.RefreshWhosWho()
 }
 
 // populateWorkspaceFromState sets runtime fields on the in-memory workspace
@@ -355,6 +469,74 @@ func (a *App) initializeWatcher() {
 		return
 	}
 	a.watcher = fw
+	fw.SetSessionChangeHook(a.onSessionFileChanged)
+	fw.SetSessionRemoveHook(a.onSessionFileRemoved)
+	fw.SetWatchErrorHook(a.onWatchError)
+	if a.settings != nil {
+		fw.SetPollInterval(time.Duration(a.settings.GetSettings().WatchPollIntervalMs) * time.Millisecond)
+		if err := fw.SetPlanWatching(a.settings.GetSettings().WatchPlanFiles); err != nil {
+			wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to watch plan files: %v", err))
+		}
+		fw.SetLoadFullHistory(a.settings.GetSettings().LoadFullHistory)
+		fw.SetOffsetIndex(watcher.NewOffsetIndex(a.settings.GetConfigPath()))
+	}
+}
+
+// onSessionFileChanged updates derived per-session data (search index, usage)
+// after the watcher sees a session file written or created.
+func (a *App) onSessionFileChanged(folder, sessionID string) {
+	go func() {
+		if a.search != nil {
+			if _, err := a.search.IndexSession(folder, sessionID); err != nil {
+				logger.Warnf("search: failed to index session %s: %v", sessionID, err)
+			}
+		}
+		a.updateSessionUsage(folder, sessionID)
+	}()
+}
+
+// onSessionFileRemoved drops derived per-session data after a session file is
+// deleted outside ClaudeFu.
+func (a *App) onSessionFileRemoved(folder, sessionID string) {
+	if a.search != nil {
+		a.search.RemoveSession(folder, sessionID)
+	}
+	if a.usage != nil {
+		a.usage.RemoveSession(folder, sessionID)
+	}
+}
+
+// onWatchError surfaces a file watcher failure to the frontend: watcher:degraded
+// when an agent folder fell back to polling (OS watch limit), watcher:error otherwise.
+func (a *App) onWatchError(watchErr watcher.WatchError) {
+	eventType := "watcher:error"
+	if watchErr.Degraded {
+		eventType = "watcher:degraded"
+	}
+	agentIDs := []string{}
+	if a.currentWorkspace != nil && watchErr.Folder != "" {
+		for _, agent := range a.currentWorkspace.Agents {
+			if agent.Folder == watchErr.Folder {
+				agentIDs = append(agentIDs, agent.ID)
+			}
+		}
+	}
+	envelope := types.EventEnvelope{
+		EventType: eventType,
+		Payload: map[string]any{
+			"folder":   watchErr.Folder,
+			"agentIds": agentIDs,
+			"error":    watchErr.Error,
+			"degraded": watchErr.Degraded,
+		},
+	}
+	if len(agentIDs) == 1 {
+		envelope.AgentID = agentIDs[0]
+	}
+	if a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	a.events.Publish(events.Event{EventEnvelope: envelope, Source: events.SourceWatcher})
 }
 
 // initializeRuntime creates the workspace runtime if we have a workspace
@@ -363,14 +545,12 @@ func (a *App) initializeRuntime() {
 		return
 	}
 
-	// Create emit function that wraps events in EventEnvelope
-	emitFunc := func(envelope types.EventEnvelope) {
-		wailsrt.EventsEmit(a.ctx, envelope.EventType, envelope)
+	// Create runtime (its events go to the bus)
+	a.rt = runtime.NewWorkspaceRuntime(a.currentWorkspace, a.events.Publisher(events.SourceRuntime))
+	if a.settings != nil {
+		a.applyBufferSettings(a.settings.GetSettings())
 	}
 
-	// Create runtime
-	a.rt = runtime.NewWorkspaceRuntime(a.currentWorkspace, emitFunc)
-
 	// Connect watcher to runtime
 	a.watcher.SetRuntime(a.rt)
 }
@@ -385,18 +565,24 @@ func (a *App) startWatchingAllAgents() {
 		// Emit per-agent loading status
 		a.emitLoadingStatus(fmt.Sprintf("Loading %s...", agent.GetSlug()))
 
-		// Get last viewed timestamps for this agent's sessions
-		var lastViewedMap map[string]int64
-		if a.sessions != nil {
-			lastViewedMap = a.sessions.GetAllLastViewed(agent.Folder)
-		}
-
-		if err := a.watcher.StartWatchingAgent(agent.ID, agent.Folder, lastViewedMap); err != nil {
+		if err := a.startWatchingAgent(&agent); err != nil {
 			wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to start watching agent %s: %v", agent.GetSlug(), err))
 		}
 	}
 }
 
+// startWatchingAgent loads an agent's sessions and watches its folder, polling
+// instead of using fsnotify when the agent's watch mode is "poll".
+func (a *App) startWatchingAgent(agent *workspace.Agent) error {
+	// Get last viewed timestamps for this agent's sessions
+	var lastViewedMap map[string]int64
+	if a.sessions != nil {
+		lastViewedMap = a.sessions.GetAllLastViewed(agent.Folder)
+	}
+	a.watcher.SetFolderPolling(agent.Folder, agent.GetWatchMode() == types.WatchModePoll)
+	return a.watcher.StartWatchingAgent(agent.ID, agent.Folder, lastViewedMap)
+}
+
 // restoreAgentSessionWatches sets up file-level watches for each agent's persisted
 // session selection. Reads from workspace state (local/) rather than workspace JSON.
 // This ensures all agents' selected sessions are watched from startup, not just
@@ -437,17 +623,49 @@ func (a *App) initializeClaude() {
 			providers.SetClaudeCommand(s.ClaudeCodeCommand)
 			wailsrt.LogInfo(a.ctx, fmt.Sprintf("Claude CLI command: %s", s.ClaudeCodeCommand))
 		}
+		providers.SetDefaultCLIArgs(s.ClaudeExtraArgs)
+		providers.SetDangerousAcknowledgements(s.AcknowledgedDangerousPermissions)
+		providers.Spawns().SetMaxConcurrent(s.MaxConcurrentSpawns)
+		providers.SetMCPConfigDir(filepath.Join(a.settings.GetConfigPath(), "local", "mcp"))
 	}
+	a.applyAgentCLIOverrides()
+	a.applyEnvProfile()
+
+	// Push spawn queue changes to the UI (queued sends show as waiting)
+	providers.Spawns().SetOnChange(func() {
+		wailsrt.EventsEmit(a.ctx, "spawn:queue", providers.Spawns().Status())
+	})
+
+	// Push rate-limit cooldowns (new spawns wait until the deadline)
+	providers.RateLimits().SetOnChange(func(status providers.RateLimitStatus) {
+		wailsrt.EventsEmit(a.ctx, "ratelimit:status", status)
+	})
 
 	// Set up emit function for debug info (CLI commands)
+	publishClaude := a.events.Publisher(events.SourceClaude)
 	a.claude.SetEmitFunc(func(eventType string, data map[string]any) {
-		wailsrt.EventsEmit(a.ctx, eventType, data)
+		envelope := types.EventEnvelope{EventType: eventType, Payload: data}
+		if a.currentWorkspace != nil {
+			envelope.WorkspaceID = a.currentWorkspace.ID
+		}
+		envelope.SessionID, _ = data["sessionId"].(string)
+		publishClaude(envelope)
 	})
 
+	// Feed live send output into the runtime for stream watch mode agents
+	a.claude.SetStreamSink(a.onStreamLine)
+
 	if providers.IsClaudeInstalled() {
 		if version, err := providers.GetClaudeVersion(); err == nil {
 			wailsrt.LogInfo(a.ctx, fmt.Sprintf("Claude Code CLI detected: %s", version))
 		}
+		// Probe supported flags in the background; only surface the report when degraded
+		go func() {
+			caps := providers.ProbeCLICapabilities()
+			if len(caps.Unsupported) > 0 || len(caps.MissingRequired) > 0 {
+				wailsrt.EventsEmit(a.ctx, "cli:capabilities", caps)
+			}
+		}()
 	} else {
 		wailsrt.LogWarning(a.ctx, "Claude Code CLI not found in PATH - message sending will be disabled")
 	}
@@ -517,6 +735,59 @@ func (a *App) initializeProxy() {
 	a.claude.SetEnvironment(envVars)
 }
 
+// initializeMetrics registers scrape-time gauges and starts the /metrics endpoint
+// if enabled in settings. Gauges read a.rt lazily so they survive workspace switches.
+func (a *App) initializeMetrics() {
+	metrics.RegisterGauge("claudefu_active_sessions", "Sessions with a running Claude CLI process.", func() float64 {
+		if a.claude == nil {
+			return 0
+		}
+		return float64(a.claude.ActiveSessionCount())
+	})
+	metrics.RegisterGauge("claudefu_loaded_sessions", "Sessions loaded in the current workspace runtime.", func() float64 {
+		if a.rt == nil {
+			return 0
+		}
+		return float64(a.rt.SessionCount())
+	})
+	metrics.RegisterGauge("claudefu_agents", "Agents in the current workspace.", func() float64 {
+		if a.currentWorkspace == nil {
+			return 0
+		}
+		return float64(len(a.currentWorkspace.Agents))
+	})
+	metrics.RegisterGauge("claudefu_buffered_messages", "Messages held in session buffers.", func() float64 {
+		if a.rt == nil {
+			return 0
+		}
+		return float64(a.rt.GetStats().TotalMessages)
+	})
+	metrics.RegisterGauge("claudefu_buffered_bytes", "Estimated size of all session buffers in bytes.", func() float64 {
+		if a.rt == nil {
+			return 0
+		}
+		return float64(a.rt.GetStats().TotalBytes)
+	})
+	metrics.RegisterGauge("claudefu_spawn_queue_length", "Sends waiting for a Claude process slot.", func() float64 {
+		return float64(len(providers.Spawns().Status().Queued))
+	})
+
+	if a.settings == nil {
+		return
+	}
+	s := a.settings.GetSettings()
+	if !s.MetricsEnabled {
+		return
+	}
+
+	a.metrics = metrics.NewService(s.MetricsHost, s.MetricsPort)
+	if err := a.metrics.Start(); err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to start metrics endpoint: %v", err))
+		return
+	}
+	wailsrt.LogInfo(a.ctx, fmt.Sprintf("Metrics endpoint started on %s:%d/metrics", a.metrics.GetHost(), a.metrics.GetPort()))
+}
+
 // initializeMCPServer initializes the MCP server for inter-agent communication
 func (a *App) initializeMCPServer() {
 	// Default port 9315 for MCP server
@@ -529,8 +800,18 @@ func (a *App) initializeMCPServer() {
 	backlogPath := filepath.Join(configPath, "backlog")
 	a.mcpServer = mcpserver.NewMCPService(port, configPath, inboxPath, backlogPath)
 
+	// Session-scoped permission grants outlive restarts via the permission log
+	if restored, err := a.mcpServer.GetPermissionLog().RestoreSessionGrants(); err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to restore session permission grants: %v", err))
+	} else if restored > 0 {
+		wailsrt.LogInfo(a.ctx, fmt.Sprintf("Restored permission grants for %d sessions", restored))
+	}
+
 	// Set up dependencies
 	a.mcpServer.SetClaudeService(a.claude)
+	a.mcpServer.SetSessionService(a.sessionService)
+	a.mcpServer.SetTaskManager(a.tasks)
+	a.mcpServer.SetSummaryService(a.summaries)
 	a.mcpServer.SetWorkspaceGetter(func() *workspace.Workspace {
 		return a.currentWorkspace
 	})
@@ -565,22 +846,48 @@ func (a *App) initializeMCPServer() {
 		return "", "", "", ""
 	})
 
-	// Set up emit function to forward events to Wails
+	// Report runtime activity (selected session, unread, last message) for AgentStatus
+	a.mcpServer.SetAgentActivityGetter(func(agentID string) mcpserver.AgentActivity {
+		sessionID := ""
+		if agent := a.getAgentByID(agentID); agent != nil {
+			sessionID = agent.SelectedSessionID
+		}
+		if a.rt == nil {
+			return mcpserver.AgentActivity{SessionID: sessionID}
+		}
+		if activeAgentID, activeSessionID := a.rt.GetActiveSession(); activeAgentID == agentID && activeSessionID != "" {
+			sessionID = activeSessionID
+		}
+		return runtimeAgentActivity(a.rt, agentID, sessionID)
+	})
+
+	// BrowserAgent bridge endpoint (workspaces can override it)
+	a.applyBrowserBridgeSettings(a.settings.GetSettings())
+
+	// AgentHandoff links the target session and sends it the handoff packet
+	a.mcpServer.SetHandoffFunc(a.startHandoff)
+
+	// Publish MCP events to the bus
+	publishMCP := a.events.Publisher(events.SourceMCP)
 	a.mcpServer.SetEmitFunc(func(envelope types.EventEnvelope) {
 		// Add workspace ID to envelope if available
 		if a.currentWorkspace != nil {
 			envelope.WorkspaceID = a.currentWorkspace.ID
 		}
-		wailsrt.EventsEmit(a.ctx, envelope.EventType, envelope)
+		publishMCP(envelope)
 	})
+	if a.integrations != nil {
+		a.mcpServer.HandleHTTP(integrations.SlackEventsPath, a.integrations.SlackEventsHandler())
+	}
 
 	// Start the server
+	a.ensureMCPAuthToken()
 	if err := a.mcpServer.Start(); err != nil {
 		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to start MCP server: %v", err))
 	} else {
 		wailsrt.LogInfo(a.ctx, fmt.Sprintf("MCP server started on port %d", port))
-		// Configure ClaudeCodeService to inject MCP config into spawned processes
-		a.claude.SetMCPServerPort(port)
+		// Configure ClaudeCodeService to inject MCP config (with auth token) into spawned processes
+		a.configureMCPClients()
 	}
 
 	// Load inbox and backlog for current workspace
@@ -620,46 +927,59 @@ func (a *App) initializeMCPServer() {
 	}
 }
 
-// emitInitialState emits the workspace:loaded event with all initial state
+// emitInitialState emits initial workspace state incrementally so the frontend
+// can render the agent list before every agent's sessions are serialized:
+//   - workspace:loaded         — workspace + agents (no sessions)
+//   - workspace:agent-loaded   — one per agent: sessions + unread counts
+//   - workspace:load-complete  — completion marker with totals
+//
+// GetWorkspaceSnapshot returns the same data in a single call on demand.
 func (a *App) emitInitialState() {
 	if a.currentWorkspace == nil || a.rt == nil {
 		return
 	}
 
-	// Build sessions map
-	sessionsMap := make(map[string][]types.Session)
-	unreadMap := make(map[string]map[string]int)
+	a.rt.Emit("workspace:loaded", "", "", map[string]any{
+		"workspace": a.currentWorkspace,
+		"agents":    a.currentWorkspace.Agents,
+	})
 
+	totalSessions := 0
 	for _, agent := range a.currentWorkspace.Agents {
-		sessions := a.rt.GetSessionsForAgent(agent.ID)
-		typeSessions := make([]types.Session, 0, len(sessions))
-		sessionUnread := make(map[string]int)
-
-		for _, s := range sessions {
-			typeSessions = append(typeSessions, types.Session{
-				ID:           s.SessionID,
-				AgentID:      s.AgentID,
-				Preview:      s.Preview,
-				MessageCount: len(s.Messages),
-				CreatedAt:    s.CreatedAt,
-				UpdatedAt:    s.UpdatedAt,
-			})
-			sessionUnread[s.SessionID] = s.UnreadCount
-		}
+		sessions, unread := a.buildAgentSessionState(agent.ID)
+		totalSessions += len(sessions)
 
-		sessionsMap[agent.ID] = typeSessions
-		unreadMap[agent.ID] = sessionUnread
+		a.rt.Emit("workspace:agent-loaded", agent.ID, "", map[string]any{
+			"sessions":     sessions,
+			"unreadCounts": unread,
+			"agentTotal":   a.rt.GetAgentTotalUnread(agent.ID),
+		})
 	}
 
-	// Emit workspace:loaded event
-	a.rt.Emit("workspace:loaded", "", "", map[string]any{
-		"workspace":    a.currentWorkspace,
-		"agents":       a.currentWorkspace.Agents,
-		"sessions":     sessionsMap,
-		"unreadCounts": unreadMap,
+	a.rt.Emit("workspace:load-complete", "", "", map[string]any{
+		"agentCount":   len(a.currentWorkspace.Agents),
+		"sessionCount": totalSessions,
 	})
 }
 
+// buildAgentSessionState returns the session list and per-session unread counts
+// for an agent from the runtime.
+func (a *App) buildAgentSessionState(agentID string) ([]types.Session, map[string]int) {
+	typeSessions := a.rt.GetSessionSummaries(agentID)
+	sessionUnread := a.rt.GetAllUnreadCounts(agentID)
+	if typeSessions == nil {
+		typeSessions = []types.Session{}
+	}
+	if sessionUnread == nil {
+		sessionUnread = make(map[string]int)
+	}
+	if agent := a.getAgentByID(agentID); agent != nil {
+		a.applySessionLabels(agent.Folder, typeSessions)
+	}
+
+	return typeSessions, sessionUnread
+}
+
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
 	// Stop terminal sessions
@@ -672,6 +992,49 @@ func (a *App) shutdown(ctx context.Context) {
 		a.proxy.Stop()
 	}
 
+	// Stop metrics endpoint
+	if a.metrics != nil {
+		a.metrics.Stop()
+	}
+
+	// Stop config backups
+	if a.backup != nil {
+		a.backup.Stop()
+	}
+
+	// Stop scheduled prompts
+	if a.schedules != nil {
+		a.schedules.Stop()
+	}
+
+	// Stop inbox auto-respond
+	a.stopInboxDispatch()
+
+	// Stop git status polling
+	if a.gitStatus != nil {
+		a.gitStatus.Stop()
+	}
+
+	// Stop checking other workspaces for unread activity
+	if a.workspaceScanner != nil {
+		a.workspaceScanner.Stop()
+	}
+
+	// Close session search index
+	if a.search != nil {
+		a.search.Close()
+	}
+
+	// Close activity timeline
+	if a.audit != nil {
+		a.audit.Close()
+	}
+
+	// Close headless control socket
+	if a.control != nil {
+		a.control.Stop()
+	}
+
 	// Stop MCP server and close databases
 	if a.mcpServer != nil {
 		a.mcpServer.Stop()
@@ -682,4 +1045,7 @@ func (a *App) shutdown(ctx context.Context) {
 	if a.watcher != nil {
 		a.watcher.StopAllWatchers()
 	}
+
+	// Stop background workspaces (their watchers and MCP servers)
+	a.closeAllBackgroundWorkspaces()
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/audit"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// ACTIVITY TIMELINE METHODS (Bound to frontend)
+// =============================================================================
+
+// GetActivityTimeline returns a newest-first page of workspace activity
+// (messages sent, sessions created, MCP tool calls, permission decisions,
+// agent messages and broadcasts), filtered by agent, type and time range.
+func (a *App) GetActivityTimeline(filter audit.Filter) (audit.Page, error) {
+	if a.audit == nil {
+		return audit.Page{}, fmt.Errorf("activity timeline not initialized")
+	}
+	return a.audit.Query(filter)
+}
+
+// =============================================================================
+// ACTIVITY TIMELINE LIFECYCLE
+// =============================================================================
+
+// initializeAudit opens the activity timeline database
+func (a *App) initializeAudit() {
+	if a.settings == nil {
+		return
+	}
+	log, err := audit.Open(a.settings.GetConfigPath())
+	if err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Activity timeline unavailable: %v", err))
+		return
+	}
+	a.audit = log
+}
+
+// recordActivity adds an emitted event to the activity timeline if it is one
+// the timeline tracks. Subscribed to the active workspace's events (see subscribeEvents).
+func (a *App) recordActivity(envelope types.EventEnvelope) {
+	if a.audit == nil {
+		return
+	}
+	activity, ok := audit.FromEnvelope(envelope)
+	if !ok {
+		return
+	}
+	if activity.WorkspaceID == "" && a.currentWorkspace != nil {
+		activity.WorkspaceID = a.currentWorkspace.ID
+	}
+	if activity.AgentID == "" && activity.AgentSlug != "" && a.currentWorkspace != nil {
+		for _, agent := range a.currentWorkspace.Agents {
+			if agent.GetSlug() == activity.AgentSlug {
+				activity.AgentID = agent.ID
+				break
+			}
+		}
+	}
+	if activity.AgentSlug == "" && activity.AgentID != "" {
+		if agent := a.getAgentByID(activity.AgentID); agent != nil {
+			activity.AgentSlug = agent.GetSlug()
+		}
+	}
+	if err := a.audit.Add(activity); err != nil {
+		logger.Warnf("%v", err)
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"claudefu/internal/permissions"
+	"claudefu/internal/providers"
+	"claudefu/internal/runtime"
 	"claudefu/internal/scaffold"
 	"claudefu/internal/session"
 	"claudefu/internal/types"
@@ -84,9 +88,9 @@ func (a *App) ScaffoldAgent(folder, name string, opts scaffold.ScaffoldOptions)
 	// Generate Sifu CLAUDE.md immediately (don't wait for next workspace load)
 	if isSifu && a.currentWorkspace != nil {
 		if err := a.workspace.GenerateSifuClaudeMD(a.currentWorkspace, folder); err != nil {
-			fmt.Printf("[WARN] Failed to generate Sifu CLAUDE.md during scaffold: %v\n", err)
+			logger.Warnf("Failed to generate Sifu CLAUDE.md during scaffold: %v", err)
 		} else {
-			fmt.Printf("[INFO] Generated Sifu CLAUDE.md at %s/CLAUDE.md\n", folder)
+			logger.Infof("Generated Sifu CLAUDE.md at %s/CLAUDE.md", folder)
 		}
 	}
 
@@ -97,26 +101,77 @@ func (a *App) ScaffoldAgent(folder, name string, opts scaffold.ScaffoldOptions)
 		svc := session.NewService()
 		sessionID, err := svc.CreateSession(folder)
 		if err != nil {
-			fmt.Printf("[WARN] ScaffoldAgent: failed to create first session: %v\n", err)
+			logger.Warnf("ScaffoldAgent: failed to create first session: %v", err)
 		} else {
 			result.SessionID = sessionID
-			fmt.Printf("[DEBUG] ScaffoldAgent: created first session %s for %s\n", sessionID[:8], folder)
+			logger.Debugf("ScaffoldAgent: created first session %s for %s", sessionID[:8], folder)
 		}
 	}
 
 	return result, nil
 }
 
-// AddAgent adds a new agent to the current workspace.
+// ValidateAgentFolder checks a folder before it is added as an agent: errors
+// (missing folder, already in the workspace) block AddAgent; warnings (not a
+// git repo, no CLAUDE.md, ...) are for the add dialog to show.
+func (a *App) ValidateAgentFolder(folder string) *scaffold.FolderValidation {
+	v := scaffold.ValidateFolder(folder)
+	if a.currentWorkspace != nil && workspace.HasAgentWithFolder(a.currentWorkspace, folder) {
+		v.Add("already_added", scaffold.SeverityError, "Folder already exists in this workspace: "+folder)
+	}
+	return v
+}
+
+// AddAgentOptions controls AddAgentWithOptions.
+type AddAgentOptions struct {
+	CreateClaudeMD bool `json:"createClaudeMD"` // Write the default CLAUDE.md if the folder has none
+}
+
+// AddAgentResult is the added agent and what validation found about its folder.
+type AddAgentResult struct {
+	Agent      *workspace.Agent           `json:"agent"`
+	Validation *scaffold.FolderValidation `json:"validation"`
+}
+
+// AddAgentWithOptions validates folder, adds it as an agent, and returns the
+// validation warnings alongside the agent. With CreateClaudeMD a missing
+// CLAUDE.md is created from the default template (and no longer reported).
+func (a *App) AddAgentWithOptions(name, folder string, opts AddAgentOptions) (*AddAgentResult, error) {
+	agent, err := a.AddAgent(name, folder)
+	if err != nil {
+		return nil, err
+	}
+	v := a.ValidateAgentFolder(folder)
+	v.Issues = slices.DeleteFunc(v.Issues, func(issue scaffold.FolderIssue) bool {
+		return issue.Code == "already_added" // That's us now
+	})
+	if opts.CreateClaudeMD && !v.HasClaudeMD {
+		if _, err := a.ScaffoldAgent(folder, agent.GetSlug(), scaffold.ScaffoldOptions{ClaudeMD: true}); err != nil {
+			logger.Warnf("AddAgentWithOptions: failed to create CLAUDE.md in %s: %v", folder, err)
+		} else {
+			v = scaffold.ValidateFolder(folder)
+		}
+	}
+	return &AddAgentResult{Agent: agent, Validation: v}, nil
+}
+
+// AddAgent adds a new agent to the current workspace. Folders failing
+// ValidateAgentFolder are rejected; its warnings are only logged.
 // Note: Scaffold + permissions are handled by the frontend via ScaffoldAgent before calling this.
 func (a *App) AddAgent(name, folder string) (*workspace.Agent, error) {
 	if a.currentWorkspace == nil {
 		return nil, fmt.Errorf("no workspace loaded")
 	}
 
-	// Prevent adding the same folder twice to one workspace
-	if workspace.HasAgentWithFolder(a.currentWorkspace, folder) {
-		return nil, fmt.Errorf("folder already exists in this workspace: %s", folder)
+	// Reject missing folders and folders already in this workspace
+	validation := a.ValidateAgentFolder(folder)
+	if !validation.OK() {
+		return nil, fmt.Errorf("%s", validation.FirstError())
+	}
+	for _, issue := range validation.Issues {
+		if issue.Severity == scaffold.SeverityWarning {
+			logger.Warnf("AddAgent %s: %s", folder, issue.Message)
+		}
 	}
 
 	agentID := a.workspace.GetOrCreateAgentID(folder)
@@ -148,16 +203,15 @@ func (a *App) AddAgent(name, folder string) (*workspace.Agent, error) {
 	if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
 		return nil, fmt.Errorf("failed to save workspace after adding agent %s: %w", agent.ID, err)
 	}
-	fmt.Printf("[DEBUG] AddAgent: saved workspace with %d agents (added %s / %s)\n",
+	logger.Debugf("AddAgent: saved workspace with %d agents (added %s / %s)",
 		len(a.currentWorkspace.Agents), agent.GetSlug(), agent.ID[:8])
 
 	// Start watching the new agent
+	if a.rt != nil {
+		a.rt.AddAgent(agent)
+	}
 	if a.watcher != nil && a.rt != nil {
-		var lastViewedMap map[string]int64
-		if a.sessions != nil {
-			lastViewedMap = a.sessions.GetAllLastViewed(folder)
-		}
-		a.watcher.StartWatchingAgent(agent.ID, folder, lastViewedMap)
+		a.startWatchingAgent(&agent)
 	}
 
 	// Emit agent:added event
@@ -169,6 +223,7 @@ func (a *App) AddAgent(name, folder string) (*workspace.Agent, error) {
 
 	// Refresh Sifu permissions (adds new agent folder)
 	a.RefreshSifuPermissions()
+	a.RefreshWhosWho()
 
 	return &agent, nil
 }
@@ -262,70 +317,287 @@ func (a *App) applyDefaultPermissionSets(folder string) {
 	}
 }
 
-// RemoveAgent removes an agent from the current workspace
+// RemoveAgent removes an agent from the current workspace.
+// The agent is moved to trash and can be brought back with RestoreAgent.
 func (a *App) RemoveAgent(agentID string) error {
 	if a.currentWorkspace == nil {
 		return fmt.Errorf("no workspace loaded")
 	}
 
-	// Find and remove the agent
-	var folder string
+	// Find the agent
+	index := -1
 	for i, agent := range a.currentWorkspace.Agents {
 		if agent.ID == agentID {
-			folder = agent.Folder
-			a.currentWorkspace.Agents = append(a.currentWorkspace.Agents[:i], a.currentWorkspace.Agents[i+1:]...)
+			index = i
 			break
 		}
 	}
-
-	if folder == "" {
+	if index < 0 {
 		return fmt.Errorf("agent not found: %s", agentID)
 	}
+	removed := a.currentWorkspace.Agents[index]
+	folder := removed.Folder
 
+	// Record in trash first so the removal can be undone via RestoreAgent
+	entry, err := a.workspace.TrashAgent(a.currentWorkspace, removed, index)
+	if err != nil {
+		return fmt.Errorf("failed to move agent to trash: %w", err)
+	}
+
+	a.currentWorkspace.Agents = append(a.currentWorkspace.Agents[:index], a.currentWorkspace.Agents[index+1:]...)
 	if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
+		a.workspace.RemoveTrashEntry(entry.ID)
 		return err
 	}
 
+	// Stop the agent's claude processes; their output would have nowhere to go
+	a.cancelAgentSends(removed)
+
 	// Stop watching the agent
 	if a.watcher != nil {
 		a.watcher.StopWatchingAgent(agentID, folder)
 	}
 
+	// Drop buffered messages, unread counts, and subscriptions, and deselect it
+	if a.rt != nil {
+		a.rt.RemoveAgent(agentID)
+	}
+	a.clearSelectedSessionOf(agentID)
+
 	// Emit agent:removed event
 	if a.rt != nil {
 		a.rt.Emit("agent:removed", agentID, "", map[string]any{
 			"agentId": agentID,
 		})
 	}
+	a.emitTrashEvent("trash:added", entry)
 
 	// Refresh Sifu permissions (removed agent folder no longer needed)
 	a.RefreshSifuPermissions()
+	if folder != "" {
+		if err := workspace.RemoveWhosWho(folder); err != nil {
+			logger.Warnf("RemoveAgent: failed to remove %s from %s: %v", workspace.WhosWhoFileName, folder, err)
+		}
+	}
+	a.RefreshWhosWho()
 
 	return nil
 }
 
-// UpdateAgent updates an existing agent
+// cancelAgentSends cancels the running and queued sends of an agent's sessions
+func (a *App) cancelAgentSends(agent workspace.Agent) {
+	if a.claude == nil {
+		return
+	}
+	sessions := a.claude.ActiveSessionsInFolder(agent.Folder)
+	if agent.SelectedSessionID != "" && !slices.Contains(sessions, agent.SelectedSessionID) {
+		sessions = append(sessions, agent.SelectedSessionID) // May be waiting for a spawn slot
+	}
+	for _, sessionID := range sessions {
+		a.claude.CancelQueuedSend(sessionID, "")
+		if err := a.claude.CancelSession(sessionID); err != nil {
+			logger.Warnf("RemoveAgent: failed to cancel session %s: %v", sessionID, err)
+		}
+	}
+}
+
+// clearSelectedSessionOf clears the workspace's selected session if it belongs to agentID
+func (a *App) clearSelectedSessionOf(agentID string) {
+	if a.currentWorkspace == nil {
+		return
+	}
+	if sel := a.currentWorkspace.SelectedSession; sel != nil && sel.AgentID == agentID {
+		a.currentWorkspace.SelectedSession = nil
+	}
+	if a.workspaceState == nil {
+		return
+	}
+	if sel := a.workspaceState.SelectedSession; sel != nil && sel.AgentID == agentID {
+		a.workspaceState.SelectedSession = nil
+		if err := a.workspace.SaveWorkspaceState(a.currentWorkspace.ID, a.workspaceState); err != nil {
+			logger.Warnf("Failed to save workspace state after RemoveAgent: %v", err)
+		}
+	}
+}
+
+// UpdateAgent replaces an existing agent's editable fields with those of agent.
+// Validated and applied atomically via UpdateAgentFields; the folder cannot change.
 func (a *App) UpdateAgent(agent workspace.Agent) error {
+	if existing := a.getAgentByID(agent.ID); existing != nil && agent.Folder != "" && agent.Folder != existing.Folder {
+		return fmt.Errorf("agent folder cannot be changed; remove the agent and add the new folder")
+	}
+	_, err := a.UpdateAgentFields(agent.ID, workspace.AgentUpdateFrom(agent))
+	return err
+}
+
+// UpdateAgentFields applies a partial update to an agent. All fields are validated
+// together (unique slug, folder exists, supported provider) before anything is saved.
+// Emits agent:updated with the changed fields so listeners can react (e.g. slug rename).
+func (a *App) UpdateAgentFields(agentID string, update workspace.AgentUpdate) (*workspace.Agent, error) {
 	if a.currentWorkspace == nil {
-		return fmt.Errorf("no workspace loaded")
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	if update.PostProcessors != nil {
+		for _, p := range *update.PostProcessors {
+			if !slices.Contains(types.AllPostProcessors, p) {
+				return nil, fmt.Errorf("unknown post-processor: %s", p)
+			}
+		}
 	}
 
-	for i := range a.currentWorkspace.Agents {
-		if a.currentWorkspace.Agents[i].ID == agent.ID {
-			a.currentWorkspace.Agents[i] = agent
+	// Apply to a copy so a validation failure leaves the workspace untouched
+	updated := *agent
+	changed := update.Apply(&updated)
+	if len(changed) == 0 {
+		return agent, nil
+	}
+	if err := a.workspace.ValidateAgent(a.currentWorkspace, updated, changed); err != nil {
+		return nil, err
+	}
+	if slices.Contains(changed, "claudeCommand") && updated.ClaudeCommand != "" && providers.ResolveClaudeCommand(updated.ClaudeCommand) == "" {
+		return nil, fmt.Errorf("claude binary not found: %s", updated.ClaudeCommand)
+	}
+
+	previous := *agent
+	*agent = updated
+	if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
+		*agent = previous
+		return nil, err
+	}
+
+	if slices.Contains(changed, "slug") {
+		// Sync slug to global registry for cross-workspace resolution
+		a.workspace.UpdateAgentSlug(updated.Folder, updated.GetSlug())
+	}
+	if slices.Contains(changed, "postProcessors") && a.rt != nil {
+		a.rt.SetAgentPostProcessors(agentID, updated.PostProcessors)
+	}
+	if (slices.Contains(changed, "bufferMaxMessages") || slices.Contains(changed, "bufferMaxBytes")) && a.rt != nil {
+		a.rt.SetAgentBufferLimits(agentID, agentBufferLimits(updated))
+	}
+	if slices.Contains(changed, "claudeCommand") || slices.Contains(changed, "claudeArgs") || slices.Contains(changed, "env") || slices.Contains(changed, "provider") ||
+		slices.Contains(changed, "defaultPermissionMode") {
+		providers.SetFolderCLIOverride(updated.Folder, agentCLIOverride(updated))
+	}
+	if slices.Contains(changed, "watchMode") && a.watcher != nil {
+		a.watcher.SetFolderPolling(updated.Folder, updated.GetWatchMode() == types.WatchModePoll)
+	}
 
-			// Sync slug to global registry for cross-workspace resolution
-			a.workspace.UpdateAgentSlug(agent.Folder, agent.GetSlug())
+	if a.rt != nil {
+		data := map[string]any{
+			"agent":         updated,
+			"changedFields": changed,
+		}
+		if slices.Contains(changed, "provider") {
+			data["providerCapabilities"] = providers.CapabilitiesFor(updated.Provider)
+		}
+		a.rt.Emit("agent:updated", agentID, "", data)
+	}
 
-			return a.workspace.SaveWorkspace(a.currentWorkspace)
+	// Slugs, the MCP-enabled set, specializations, and tags are baked into tool descriptions at Start
+	listingChanged := slices.ContainsFunc(changed, func(field string) bool {
+		return slices.Contains([]string{"slug", "mcpEnabled", "specialization", "tags"}, field)
+	})
+	if listingChanged {
+		a.RefreshWhosWho()
+	}
+	if listingChanged && a.mcpServer != nil && a.mcpServer.IsRunning() {
+		if err := a.mcpServer.Restart(); err != nil {
+			logger.Warnf("UpdateAgentFields: failed to restart MCP server: %v", err)
 		}
 	}
 
-	return fmt.Errorf("agent not found: %s", agent.ID)
+	return &updated, nil
+}
+
+// GetAvailablePostProcessors returns the names of all message post-processors.
+func (a *App) GetAvailablePostProcessors() []string {
+	return types.AllPostProcessors
+}
+
+// SetAgentPostProcessors sets which post-processors run on an agent's incoming messages.
+// Takes effect for new messages; reload the session to reprocess history.
+func (a *App) SetAgentPostProcessors(agentID string, processors []string) error {
+	_, err := a.UpdateAgentFields(agentID, workspace.AgentUpdate{PostProcessors: &processors})
+	return err
+}
+
+// SetAgentBufferLimits sets how many messages (and estimated bytes) of each of the
+// agent's sessions stay in memory. Zero values revert to the global settings.
+func (a *App) SetAgentBufferLimits(agentID string, maxMessages int, maxBytes int64) (*workspace.Agent, error) {
+	return a.UpdateAgentFields(agentID, workspace.AgentUpdate{BufferMaxMessages: &maxMessages, BufferMaxBytes: &maxBytes})
+}
+
+// SetAgentCLIOverride sets the claude binary (name or path) and extra CLI args
+// used for this agent's sessions and queries. Empty values revert to the globals.
+func (a *App) SetAgentCLIOverride(agentID, command string, args []string) (*workspace.Agent, error) {
+	return a.UpdateAgentFields(agentID, workspace.AgentUpdate{ClaudeCommand: &command, ClaudeArgs: &args})
+}
+
+// SetAgentEnv replaces the env vars set for this agent's claude processes
+// (applied over the global ones). Takes effect for the next process spawned.
+func (a *App) SetAgentEnv(agentID string, env map[string]string) (*workspace.Agent, error) {
+	return a.UpdateAgentFields(agentID, workspace.AgentUpdate{Env: &env})
+}
+
+// GetAgentClaudePath returns the resolved claude binary for an agent ("" if not found),
+// so the UI can show which install an override points at.
+func (a *App) GetAgentClaudePath(agentID string) (string, error) {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	return providers.ClaudePathFor(agent.Folder), nil
+}
+
+// SetAgentProvider switches the backend that drives an agent's sessions
+// (claude_code, anthropic or codex). Empty reverts to claude_code.
+func (a *App) SetAgentProvider(agentID, provider string) (*workspace.Agent, error) {
+	return a.UpdateAgentFields(agentID, workspace.AgentUpdate{Provider: &provider})
+}
+
+// GetAgentProviderCapabilities returns what the agent's provider supports, so
+// the UI can flag non-Claude agents that run with reduced tool support.
+func (a *App) GetAgentProviderCapabilities(agentID string) (providers.ProviderCapabilities, error) {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return providers.ProviderCapabilities{}, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return providers.CapabilitiesFor(agent.Provider), nil
+}
+
+// agentCLIOverride returns the provider-level CLI override for an agent.
+func agentCLIOverride(agent workspace.Agent) providers.CLIOverride {
+	return providers.CLIOverride{Command: agent.ClaudeCommand, Args: agent.ClaudeArgs, Env: agent.Env, Provider: agent.Provider, PermissionMode: agent.DefaultPermissionMode}
+}
+
+// agentBufferLimits returns the runtime buffer limits an agent overrides.
+func agentBufferLimits(agent workspace.Agent) runtime.BufferLimits {
+	return runtime.BufferLimits{MaxMessages: agent.BufferMaxMessages, MaxBytes: agent.BufferMaxBytes}
+}
+
+// applyAgentCLIOverrides registers every current-workspace agent's CLI override
+// with the providers package (which resolves binaries by folder).
+func (a *App) applyAgentCLIOverrides() {
+	providers.ClearFolderCLIOverrides()
+	// Background workspaces first, so a folder in the active workspace too
+	// gets the active workspace's override (and MCP server)
+	a.applyBackgroundCLIOverrides()
+	if a.currentWorkspace == nil {
+		return
+	}
+	for _, agent := range a.currentWorkspace.Agents {
+		providers.SetFolderCLIOverride(agent.Folder, agentCLIOverride(agent))
+	}
 }
 
 // ReorderAgents reorders agents in the current workspace.
-// orderedIDs is the full list of agent IDs in the desired order.
+// orderedIDs is the full list of agent IDs in the desired order; within each
+// group agents are listed in this order (see MoveAgentToGroup).
 func (a *App) ReorderAgents(orderedIDs []string) error {
 	if a.currentWorkspace == nil {
 		return fmt.Errorf("no workspace loaded")
@@ -357,6 +629,7 @@ func (a *App) ReorderAgents(orderedIDs []string) error {
 		return err
 	}
 
+	a.emitAgentGroupsChanged()
 	return nil
 }
 
@@ -368,3 +641,72 @@ func (a *App) GetAgent(agentID string) (*workspace.Agent, error) {
 	}
 	return agent, nil
 }
+
+// agentDescriptionPrompt asks Claude for a routing-oriented summary of the agent's codebase.
+const agentDescriptionPrompt = `Summarize what this codebase does in ONE or TWO sentences (max 200 characters), ` +
+	`written so another AI agent can decide whether to route questions here. ` +
+	`Mention the main language/framework and the domain. Output ONLY the description, no preamble or quotes.`
+
+// GenerateAgentDescription runs a quick claude --print in the agent's folder to produce
+// a concise MCP description, stores it as AGENT_DESCRIPTION in the agent registry,
+// and restarts the MCP server so AgentQuery/AgentMessage tool descriptions pick it up.
+func (a *App) GenerateAgentDescription(agentID string) (string, error) {
+	if a.claude == nil {
+		return "", fmt.Errorf("claude service not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	folder := agent.Folder
+
+	output, err := a.claude.RunPrint(folder, agentDescriptionPrompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate description: %w", err)
+	}
+	description := cleanAgentDescription(output)
+	if description == "" {
+		return "", fmt.Errorf("claude returned an empty description")
+	}
+
+	// Persist to the registry (identity lives in agents.json, not the workspace file)
+	meta := make(map[string]string)
+	if info := a.workspace.GetAgentInfo(folder); info != nil {
+		for k, v := range info.Meta {
+			meta[k] = v
+		}
+	}
+	meta["AGENT_DESCRIPTION"] = description
+	if err := a.workspace.UpdateAgentCustomMeta(folder, meta); err != nil {
+		return "", err
+	}
+
+	// Re-resolve after the slow CLI call — the agent slice may have changed meanwhile
+	if agent = a.getAgentByID(agentID); agent != nil {
+		agent.Description = description
+		if a.rt != nil {
+			a.rt.Emit("agent:updated", agentID, "", map[string]any{
+				"agent":         *agent,
+				"changedFields": []string{"description"},
+			})
+		}
+	}
+
+	a.RefreshWhosWho()
+
+	// Refresh MCP tool descriptions (agent list is baked in at Start)
+	if a.mcpServer != nil && a.mcpServer.IsRunning() {
+		if err := a.mcpServer.Restart(); err != nil {
+			logger.Warnf("GenerateAgentDescription: failed to restart MCP server: %v", err)
+		}
+	}
+
+	return description, nil
+}
+
+// cleanAgentDescription collapses CLI output to a single line and strips wrapping quotes.
+func cleanAgentDescription(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.Trim(s, "\"'`")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// AGENT GROUP METHODS (Bound to frontend)
+// =============================================================================
+
+// GetAgentGroups returns the current workspace's agent groups in display order.
+func (a *App) GetAgentGroups() []workspace.AgentGroup {
+	if a.currentWorkspace == nil || a.currentWorkspace.Groups == nil {
+		return []workspace.AgentGroup{}
+	}
+	return a.currentWorkspace.Groups
+}
+
+// GetGroupedAgents returns the current workspace's agents section by section:
+// each group in order, then the ungrouped agents.
+func (a *App) GetGroupedAgents() []workspace.GroupedAgents {
+	if a.currentWorkspace == nil {
+		return []workspace.GroupedAgents{}
+	}
+	return a.currentWorkspace.GroupedAgents()
+}
+
+// CreateAgentGroup adds an empty group at the end of the agent list.
+func (a *App) CreateAgentGroup(name string) (*workspace.AgentGroup, error) {
+	var group workspace.AgentGroup
+	err := a.updateAgentGroups(true, func(ws *workspace.Workspace) error {
+		created, err := ws.AddGroup(name)
+		if err == nil {
+			group = *created
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// RenameAgentGroup renames a group.
+func (a *App) RenameAgentGroup(groupID, name string) error {
+	return a.updateAgentGroups(true, func(ws *workspace.Workspace) error {
+		return ws.RenameGroup(groupID, name)
+	})
+}
+
+// SetAgentGroupCollapsed collapses or expands a group in the sidebar.
+func (a *App) SetAgentGroupCollapsed(groupID string, collapsed bool) error {
+	return a.updateAgentGroups(false, func(ws *workspace.Workspace) error {
+		return ws.SetGroupCollapsed(groupID, collapsed)
+	})
+}
+
+// DeleteAgentGroup deletes a group. Its agents become ungrouped.
+func (a *App) DeleteAgentGroup(groupID string) error {
+	return a.updateAgentGroups(true, func(ws *workspace.Workspace) error {
+		return ws.RemoveGroup(groupID)
+	})
+}
+
+// ReorderAgentGroups reorders the groups.
+// orderedIDs is the full list of group IDs in the desired order.
+func (a *App) ReorderAgentGroups(orderedIDs []string) error {
+	return a.updateAgentGroups(true, func(ws *workspace.Workspace) error {
+		return ws.ReorderGroups(orderedIDs)
+	})
+}
+
+// MoveAgentToGroup moves an agent into a group (groupID "" = ungrouped). A
+// non-negative index also places it at that position within the group;
+// -1 keeps its current position.
+func (a *App) MoveAgentToGroup(agentID, groupID string, index int) error {
+	return a.updateAgentGroups(true, func(ws *workspace.Workspace) error {
+		return ws.SetAgentGroup(agentID, groupID, index)
+	})
+}
+
+// =============================================================================
+// AGENT GROUP HELPERS
+// =============================================================================
+
+// updateAgentGroups applies change to the current workspace, saves it, and
+// emits agent:groups_changed. The workspace is left untouched if change or the
+// save fails. listingChanged restarts the MCP server, whose tool descriptions
+// list agents by group.
+func (a *App) updateAgentGroups(listingChanged bool, change func(ws *workspace.Workspace) error) error {
+	ws := a.currentWorkspace
+	if ws == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+
+	prevGroups := append([]workspace.AgentGroup(nil), ws.Groups...)
+	prevAgents := append([]workspace.Agent(nil), ws.Agents...)
+	if err := change(ws); err != nil {
+		ws.Groups, ws.Agents = prevGroups, prevAgents
+		return err
+	}
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		ws.Groups, ws.Agents = prevGroups, prevAgents
+		return err
+	}
+
+	a.emitAgentGroupsChanged()
+	if listingChanged && a.mcpServer != nil && a.mcpServer.IsRunning() {
+		if err := a.mcpServer.Restart(); err != nil {
+			logger.Warnf("Agent groups: failed to restart MCP server: %v", err)
+		}
+	}
+	return nil
+}
+
+// emitAgentGroupsChanged emits agent:groups_changed with the groups and the
+// agents' order and membership.
+func (a *App) emitAgentGroupsChanged() {
+	if a.rt == nil || a.currentWorkspace == nil {
+		return
+	}
+	type agentPlacement struct {
+		ID      string `json:"id"`
+		GroupID string `json:"groupId,omitempty"`
+	}
+	placements := make([]agentPlacement, 0, len(a.currentWorkspace.Agents))
+	for _, agent := range a.currentWorkspace.Agents {
+		placements = append(placements, agentPlacement{ID: agent.ID, GroupID: agent.GroupID})
+	}
+	a.rt.Emit("agent:groups_changed", "", "", map[string]any{
+		"workspaceId": a.currentWorkspace.ID,
+		"groups":      a.GetAgentGroups(),
+		"agents":      placements,
+	})
+}
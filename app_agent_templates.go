@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudefu/internal/permissions"
+	"claudefu/internal/scaffold"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// AGENT TEMPLATE METHODS (Bound to frontend)
+// =============================================================================
+
+// SaveAgentAsTemplate saves an agent's settings, own ClaudeFu permissions, MCP
+// slug/description, and CLAUDE.md (as a skeleton) as a reusable agent template.
+func (a *App) SaveAgentAsTemplate(agentID, name, description string) (*workspace.AgentTemplate, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	claudeMD := ""
+	if data, err := os.ReadFile(agentClaudeMDPath(*agent)); err == nil {
+		claudeMD = string(data)
+	}
+	tmpl := workspace.NewAgentTemplate(*agent, name, description, claudeMD)
+	if mgr, err := permissions.NewManager(); err == nil {
+		perms, err := mgr.LoadAgentPermissions(agent.Folder)
+		if err != nil {
+			logger.Warnf("SaveAgentAsTemplate: permissions for %s: %v", agent.GetSlug(), err)
+		}
+		tmpl.Permissions = perms
+	} else {
+		logger.Warnf("Failed to create permissions manager: %v", err)
+	}
+
+	if err := a.workspace.SaveAgentTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	logger.Infof("Saved agent %s as template %q", agent.GetSlug(), name)
+	return tmpl, nil
+}
+
+// GetAgentTemplates lists saved agent templates.
+func (a *App) GetAgentTemplates() ([]workspace.AgentTemplateSummary, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.ListAgentTemplates()
+}
+
+// GetAgentTemplate returns an agent template by ID.
+func (a *App) GetAgentTemplate(templateID string) (*workspace.AgentTemplate, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.GetAgentTemplate(templateID)
+}
+
+// DeleteAgentTemplate removes a saved agent template.
+func (a *App) DeleteAgentTemplate(templateID string) error {
+	if a.workspace == nil {
+		return fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.DeleteAgentTemplate(templateID)
+}
+
+// AddAgentFromTemplate adds folder as an agent and bootstraps it from a
+// template: settings, MCP description (if the agent has none), permissions (if
+// the folder has none of its own), the Claude projects dir, and CLAUDE.md (if
+// missing). An empty name uses the template's slug pattern.
+func (a *App) AddAgentFromTemplate(name, folder, templateID string) (*workspace.Agent, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	tmpl, err := a.workspace.GetAgentTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = a.workspace.UniqueAgentSlug(a.currentWorkspace, tmpl.Slug(folder), folder)
+	}
+
+	agent, err := a.AddAgent(name, folder)
+	if err != nil {
+		return nil, err
+	}
+	a.applyAgentTemplateFiles(tmpl, *agent)
+	described := a.applyAgentTemplateDescription(tmpl, *agent)
+
+	update := workspace.AgentUpdate{
+		MCPEnabled:     tmpl.MCPEnabled,
+		PostProcessors: &tmpl.PostProcessors,
+		Specialization: &tmpl.Specialization,
+		Tags:           &tmpl.Tags,
+		ClaudeCommand:  &tmpl.ClaudeCommand,
+		ClaudeArgs:     &tmpl.ClaudeArgs,
+	}
+	if tmpl.WatchMode != "" {
+		update.WatchMode = &tmpl.WatchMode
+	}
+	if tmpl.DefaultPermissionMode != "" {
+		update.DefaultPermissionMode = &tmpl.DefaultPermissionMode
+	}
+	updated, err := a.UpdateAgentFields(agent.ID, update)
+	if err != nil {
+		return nil, fmt.Errorf("agent added but template settings failed: %w", err)
+	}
+
+	if described {
+		a.RefreshWhosWho()
+		if a.mcpServer != nil && a.mcpServer.IsRunning() {
+			if err := a.mcpServer.Restart(); err != nil {
+				logger.Warnf("AddAgentFromTemplate: failed to restart MCP server: %v", err)
+			}
+		}
+	}
+	logger.Infof("Added agent %s from template %q", updated.GetSlug(), tmpl.Name)
+	return updated, nil
+}
+
+// =============================================================================
+// AGENT TEMPLATE HELPERS
+// =============================================================================
+
+// agentClaudeMDPath returns the agent's CLAUDE.md (its override, else the folder's)
+func agentClaudeMDPath(agent workspace.Agent) string {
+	if agent.ClaudeMdPath != "" {
+		return agent.ClaudeMdPath
+	}
+	return filepath.Join(agent.Folder, "CLAUDE.md")
+}
+
+// applyAgentTemplateFiles writes the template's permissions and CLAUDE.md to a
+// new agent's folder, leaving existing ones alone. Without a template
+// CLAUDE.md or permissions, the usual scaffold defaults are used.
+func (a *App) applyAgentTemplateFiles(tmpl *workspace.AgentTemplate, agent workspace.Agent) {
+	var opts scaffold.ScaffoldOptions
+	if check, err := scaffold.CheckAgentSetup(agent.Folder); err == nil {
+		opts.ProjectsDir = !check.HasProjectsDir
+	}
+
+	if mgr, err := permissions.NewManager(); err != nil {
+		logger.Warnf("Failed to create permissions manager: %v", err)
+	} else if existing, _ := mgr.LoadAgentPermissions(agent.Folder); existing == nil {
+		if tmpl.Permissions != nil {
+			if err := mgr.SaveAgentPermissions(agent.Folder, tmpl.Permissions); err != nil {
+				logger.Warnf("Failed to apply template permissions to %s: %v", agent.Folder, err)
+			}
+		} else {
+			opts.Permissions = true
+		}
+	}
+
+	claudeMDPath := agentClaudeMDPath(agent)
+	if _, err := os.Stat(claudeMDPath); os.IsNotExist(err) {
+		if tmpl.ClaudeMD != "" {
+			content := workspace.RenderAgentTemplate(tmpl.ClaudeMD, agent.ID, agent.GetSlug(), agent.Folder)
+			if err := os.WriteFile(claudeMDPath, []byte(content), 0644); err != nil {
+				logger.Warnf("Failed to write template CLAUDE.md to %s: %v", claudeMDPath, err)
+			}
+		} else {
+			opts.ClaudeMD = true
+		}
+	}
+
+	if opts == (scaffold.ScaffoldOptions{}) {
+		return
+	}
+	if _, err := a.ScaffoldAgent(agent.Folder, agent.GetSlug(), opts); err != nil {
+		logger.Warnf("AddAgentFromTemplate: scaffold %s: %v", agent.Folder, err)
+	}
+}
+
+// applyAgentTemplateDescription stores the template's MCP description for an
+// agent that doesn't have one yet. Reports whether it did.
+func (a *App) applyAgentTemplateDescription(tmpl *workspace.AgentTemplate, agent workspace.Agent) bool {
+	if tmpl.MCPDescription == "" {
+		return false
+	}
+	meta := make(map[string]string)
+	if info := a.workspace.GetAgentInfo(agent.Folder); info != nil {
+		if info.Meta["AGENT_DESCRIPTION"] != "" {
+			return false
+		}
+		for k, v := range info.Meta {
+			meta[k] = v
+		}
+	}
+	description := workspace.RenderAgentTemplate(tmpl.MCPDescription, agent.ID, agent.GetSlug(), agent.Folder)
+	meta["AGENT_DESCRIPTION"] = description
+	if err := a.workspace.UpdateAgentCustomMeta(agent.Folder, meta); err != nil {
+		logger.Warnf("AddAgentFromTemplate: description for %s: %v", agent.GetSlug(), err)
+		return false
+	}
+	if live := a.getAgentByID(agent.ID); live != nil {
+		live.Description = description
+	}
+	return true
+}
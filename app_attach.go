@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"claudefu/internal/git"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+)
+
+// Folder attachment limits. The total stays well inside the context window.
+const (
+	attachFolderMaxTotalBytes = 600 * 1024
+	attachFolderMaxFiles      = 500
+)
+
+// AttachFolderResult reports what AttachFolder packed into the message.
+type AttachFolderResult struct {
+	Folder     string        `json:"folder"`
+	Files      []string      `json:"files"` // Relative paths, in send order
+	TotalBytes int           `json:"totalBytes"`
+	Skipped    []SkippedFile `json:"skipped,omitempty"`
+}
+
+// SkippedFile is a matching file AttachFolder left out.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"` // "too large", "binary", "total limit", "unreadable"
+}
+
+// =============================================================================
+// FOLDER ATTACHMENT METHODS (Bound to frontend)
+// =============================================================================
+
+// AttachFolder sends the text files under path to a session as one message of
+// file attachments (<claudefu-file> blocks). Inside a git repository .gitignore
+// is honored; elsewhere hidden directories and node_modules are skipped. globs
+// (e.g. "*.go", "src/**/*.ts") filter by relative path or file name; empty
+// matches everything. Files over the per-file limit, binary files, and files
+// past the total limit are skipped and listed in the result. The send runs in
+// the background and completes with response_complete like SendMessage.
+func (a *App) AttachFolder(agentID, sessionID, folderPath string, globs []string) (AttachFolderResult, error) {
+	if a.getAgentByID(agentID) == nil {
+		return AttachFolderResult{}, fmt.Errorf("agent not found: %s", agentID)
+	}
+	root, err := filepath.Abs(folderPath)
+	if err != nil {
+		return AttachFolderResult{}, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return AttachFolderResult{}, fmt.Errorf("not a folder: %s", folderPath)
+	}
+
+	maxFileBytes := providers.DefaultMaxFileBytes
+	if a.settings != nil && a.settings.GetSettings().MaxFileBytes > 0 {
+		maxFileBytes = a.settings.GetSettings().MaxFileBytes
+	}
+
+	files, err := listFolderFiles(root)
+	if err != nil {
+		return AttachFolderResult{}, err
+	}
+	result, attachments := packFolderFiles(root, files, globs, maxFileBytes)
+	if len(attachments) == 0 {
+		return result, fmt.Errorf("no text files in %s match %v", folderPath, globs)
+	}
+
+	message := fmt.Sprintf("Attached %d files from %s as context.", len(result.Files), root)
+	if len(result.Skipped) > 0 {
+		message += fmt.Sprintf(" %d matching files were left out (binary or over the size limits).", len(result.Skipped))
+	}
+	go func() {
+		if _, err := a.sendMessageWithContext(agentID, sessionID, "", message, attachments, false, "", "", providers.PriorityInteractive); err != nil {
+			logger.Warnf("AttachFolder: send failed for %s: %v", root, err)
+		}
+	}()
+	return result, nil
+}
+
+// =============================================================================
+// FOLDER ATTACHMENT HELPERS
+// =============================================================================
+
+// listFolderFiles lists the files under root relative to it, using git when
+// root is in a repository so ignored files are left out.
+func listFolderFiles(root string) ([]string, error) {
+	if files, err := git.ListFiles(root); err == nil {
+		slices.Sort(files)
+		return files, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, _ := filepath.Rel(root, p)
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// packFolderFiles reads the files matching globs into file attachments.
+func packFolderFiles(root string, files, globs []string, maxFileBytes int) (AttachFolderResult, []types.Attachment) {
+	result := AttachFolderResult{Folder: root, Files: []string{}}
+	var attachments []types.Attachment
+	for _, rel := range files {
+		if !matchesAnyGlob(rel, globs) {
+			continue
+		}
+		skip := func(reason string) { result.Skipped = append(result.Skipped, SkippedFile{Path: rel, Reason: reason}) }
+
+		abs := filepath.Join(root, rel)
+		info, err := os.Stat(abs)
+		if err != nil || !info.Mode().IsRegular() {
+			continue // Deleted but still tracked, or not a regular file
+		}
+		if info.Size() > int64(maxFileBytes) {
+			skip("too large")
+			continue
+		}
+		if len(attachments) >= attachFolderMaxFiles || result.TotalBytes+int(info.Size()) > attachFolderMaxTotalBytes {
+			skip("total limit")
+			continue
+		}
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			skip("unreadable")
+			continue
+		}
+		if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 || !utf8.Valid(data) {
+			skip("binary")
+			continue
+		}
+
+		attachments = append(attachments, types.Attachment{
+			Type:      "file",
+			MediaType: "text/plain",
+			Data:      string(data),
+			FilePath:  abs,
+			FileName:  filepath.ToSlash(rel),
+			Extension: strings.TrimPrefix(filepath.Ext(rel), "."),
+		})
+		result.Files = append(result.Files, filepath.ToSlash(rel))
+		result.TotalBytes += len(data)
+	}
+	return result, attachments
+}
+
+// matchesAnyGlob reports whether rel matches one of globs by relative path or
+// file name. "**/" in a glob matches any number of directories.
+func matchesAnyGlob(rel string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+	for _, g := range globs {
+		g = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(g)), "./")
+		if g == "" {
+			continue
+		}
+		if ok, _ := path.Match(g, path.Base(rel)); ok {
+			return true
+		}
+		if matchGlobPath(strings.Split(g, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobPath matches path segments against glob segments, where a "**"
+// segment matches zero or more path segments.
+func matchGlobPath(glob, parts []string) bool {
+	if len(glob) == 0 {
+		return len(parts) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlobPath(glob[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(glob[0], parts[0]); !ok {
+		return false
+	}
+	return matchGlobPath(glob[1:], parts[1:])
+}
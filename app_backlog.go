@@ -1,9 +1,13 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
 	"claudefu/internal/types"
 )
 
@@ -42,9 +46,13 @@ func (a *App) UpdateBacklogItem(item mcpserver.BacklogItem) bool {
 	if a.mcpServer == nil {
 		return false
 	}
+	previous := a.mcpServer.GetBacklog().GetItem(item.ID)
 	ok := a.mcpServer.GetBacklog().UpdateItem(item)
 	if ok {
 		a.emitBacklogChanged(item.AgentID)
+		if previous != nil && previous.Status != item.Status {
+			a.emitAppEvent(mcpserver.BacklogStatusChangedEvent(item, previous.Status))
+		}
 	}
 	return ok
 }
@@ -73,6 +81,140 @@ func (a *App) MoveBacklogItem(agentID, id, newParentID, afterID string) bool {
 	return ok
 }
 
+// MoveBacklogItemToAgent moves an item (with its subtasks) into another agent's
+// backlog and emits a change event for both agents
+func (a *App) MoveBacklogItemToAgent(id, targetAgentID string) error {
+	if a.mcpServer == nil {
+		return fmt.Errorf("MCP server not initialized")
+	}
+	sourceAgentID, err := a.mcpServer.GetBacklog().MoveItemToAgent(id, targetAgentID)
+	if err != nil {
+		return err
+	}
+	a.emitBacklogChanged(sourceAgentID)
+	a.emitBacklogChanged(targetAgentID)
+	return nil
+}
+
+// ExportBacklog writes an agent's backlog as json or markdown to a file chosen
+// in a save dialog. Returns the written path, or "" if the dialog was cancelled.
+func (a *App) ExportBacklog(agentID, format string) (string, error) {
+	if a.mcpServer == nil {
+		return "", fmt.Errorf("MCP server not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	data, err := a.mcpServer.GetBacklog().ExportAgent(agentID, format)
+	if err != nil {
+		return "", err
+	}
+
+	ext := ".json"
+	if format == mcpserver.BacklogFormatMarkdown {
+		ext = ".md"
+	}
+	path, err := wailsrt.SaveFileDialog(a.ctx, wailsrt.SaveDialogOptions{
+		Title:           "Export Backlog",
+		DefaultFilename: agent.GetSlug() + "-backlog" + ext,
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	return path, nil
+}
+
+// ImportBacklog adds the items of a JSON backlog export, chosen in an open
+// dialog, to an agent's backlog. Returns the number of imported items (0 if the
+// dialog was cancelled).
+func (a *App) ImportBacklog(agentID string) (int, error) {
+	if a.mcpServer == nil {
+		return 0, fmt.Errorf("MCP server not initialized")
+	}
+	path, err := wailsrt.OpenFileDialog(a.ctx, wailsrt.OpenDialogOptions{
+		Title:   "Import Backlog",
+		Filters: []wailsrt.FileFilter{{DisplayName: "Backlog export (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	count, err := a.mcpServer.GetBacklog().ImportAgent(agentID, data)
+	if count > 0 {
+		a.emitBacklogChanged(agentID)
+	}
+	return count, err
+}
+
+// StartBacklogItem starts work on a backlog item: it creates a new session named
+// after the item, links it to the item, marks the item in_progress, and sends an
+// opening prompt with the item attached. Returns the session ID right away; the
+// turn runs in the background and reports through the usual session events.
+func (a *App) StartBacklogItem(agentID, itemID string) (string, error) {
+	if a.mcpServer == nil {
+		return "", fmt.Errorf("MCP server not initialized")
+	}
+	backlog := a.mcpServer.GetBacklog()
+	item := backlog.GetItem(itemID)
+	if item == nil {
+		return "", fmt.Errorf("backlog item not found: %s", itemID)
+	}
+	if item.AgentID != agentID {
+		return "", fmt.Errorf("backlog item %s belongs to another agent", itemID)
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	sessionID, err := a.NewSession(agentID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	if a.sessions != nil {
+		_ = a.sessions.SetSessionName(agent.Folder, sessionID, item.Title)
+	}
+
+	backlog.LinkSession(item.ID, sessionID)
+	if item.Status != "in_progress" {
+		item.Status = "in_progress"
+		backlog.UpdateItem(*item)
+	}
+	a.emitBacklogChanged(item.AgentID)
+
+	message := fmt.Sprintf("Start work on backlog item %q (id: %s), described in the backlog context above. "+
+		"Investigate first, then implement it. When you finish, update the item with BacklogUpdate.", item.Title, item.ID)
+	go func() {
+		if _, err := a.sendMessageWithContext(agentID, sessionID, mcpserver.FormatBacklogContext([]mcpserver.BacklogItem{*item}), message, nil, false, "", "", providers.PriorityInteractive); err != nil {
+			logger.Warnf("StartBacklogItem: send failed for %s: %v", item.ID, err)
+		}
+	}()
+	return sessionID, nil
+}
+
+// GetSessionBacklogItems returns the backlog items linked to a session (via SendMessageWithBacklog)
+func (a *App) GetSessionBacklogItems(agentID, sessionID string) []mcpserver.BacklogItem {
+	if a.mcpServer == nil {
+		return []mcpserver.BacklogItem{}
+	}
+	return a.mcpServer.GetBacklog().GetSessionItems(agentID, sessionID)
+}
+
+// GetBacklogItemSessions returns the session IDs that worked on a backlog item
+func (a *App) GetBacklogItemSessions(id string) []string {
+	if a.mcpServer == nil {
+		return []string{}
+	}
+	return a.mcpServer.GetBacklog().GetItemSessions(id)
+}
+
 // GetBacklogCount returns the number of non-done backlog items for an agent (for badge).
 // Returns 0 during the startup race where mcpServer is not yet wired —
 // the Sidebar listens for the "mcp:ready" event to re-poll once initialization completes.
@@ -88,7 +230,7 @@ func (a *App) emitBacklogChanged(agentID string) {
 	if a.mcpServer == nil {
 		return
 	}
-	wailsrt.EventsEmit(a.ctx, "backlog:changed", types.EventEnvelope{
+	a.emitAppEvent(types.EventEnvelope{
 		AgentID:   agentID,
 		EventType: "backlog:changed",
 		Payload: map[string]any{
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/backup"
+	"claudefu/internal/settings"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// CONFIG BACKUP METHODS (Bound to frontend)
+// =============================================================================
+
+// ListRestorePoints returns config backup restore points, newest first (limit <= 0 = all).
+func (a *App) ListRestorePoints(limit int) ([]backup.RestorePoint, error) {
+	if a.backup == nil {
+		return nil, fmt.Errorf("backup not initialized")
+	}
+	return a.backup.ListRestorePoints(limit)
+}
+
+// GetRestorePointDiff returns the patch a restore point introduced.
+// path limits the diff to one config-relative file (empty = all files).
+func (a *App) GetRestorePointDiff(hash, path string) (string, error) {
+	if a.backup == nil {
+		return "", fmt.Errorf("backup not initialized")
+	}
+	return a.backup.GetRestorePointDiff(hash, path)
+}
+
+// GetRestorePointFile returns a config-relative file's content at a restore point.
+func (a *App) GetRestorePointFile(hash, path string) (string, error) {
+	if a.backup == nil {
+		return "", fmt.Errorf("backup not initialized")
+	}
+	return a.backup.GetFileAt(hash, path)
+}
+
+// CreateRestorePoint snapshots the config directory now, even if automatic
+// backups are disabled. Returns nil when nothing changed since the last point.
+func (a *App) CreateRestorePoint(message string) (*backup.RestorePoint, error) {
+	if a.backup == nil {
+		return nil, fmt.Errorf("backup not initialized")
+	}
+	if !backup.IsAvailable() {
+		return nil, fmt.Errorf("git not found - install git to use config backups")
+	}
+	return a.backup.Snapshot(strings.TrimSpace(message))
+}
+
+// RestoreRestorePoint rewrites ~/.claudefu to match a restore point, then reloads
+// settings and the current workspace. The prior state is kept as its own restore point.
+// Backlog exports are restored as files only; the SQLite databases are not rewritten.
+func (a *App) RestoreRestorePoint(hash string) (*backup.RestorePoint, error) {
+	if a.backup == nil {
+		return nil, fmt.Errorf("backup not initialized")
+	}
+	point, err := a.backup.Restore(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.settings.ReloadSettings(); err != nil {
+		logger.Warnf("Failed to reload settings after restore: %v", err)
+	}
+	if _, err := a.ReloadCurrentWorkspace(); err != nil {
+		logger.Warnf("Failed to reload workspace after restore: %v", err)
+	}
+
+	a.emitBackupEvent("backup:restored", map[string]any{
+		"hash": hash,
+	})
+	return point, nil
+}
+
+// =============================================================================
+// CONFIG BACKUP LIFECYCLE
+// =============================================================================
+
+// initializeBackup creates the backup service and starts automatic snapshots if enabled.
+func (a *App) initializeBackup() {
+	if a.settings == nil {
+		return
+	}
+	a.backup = backup.NewService(a.settings.GetConfigPath())
+	a.backup.SetExporter(a.exportBacklogsForBackup)
+	a.backup.SetOnSnapshot(func(point backup.RestorePoint) {
+		a.emitBackupEvent("backup:snapshot", point)
+	})
+	a.applyBackupSettings(a.settings.GetSettings())
+}
+
+// applyBackupSettings starts, restarts, or stops automatic snapshots based on settings.
+func (a *App) applyBackupSettings(s settings.Settings) {
+	if a.backup == nil {
+		return
+	}
+	a.backup.Stop()
+	if !s.BackupEnabled {
+		return
+	}
+	if !backup.IsAvailable() {
+		wailsrt.LogWarning(a.ctx, "Config backup enabled but git was not found in PATH")
+		return
+	}
+	interval := time.Duration(s.BackupIntervalMinutes) * time.Minute
+	if err := a.backup.Start(interval); err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to start config backup: %v", err))
+	}
+}
+
+// exportBacklogsForBackup writes each agent's backlog as JSON to {dir}/backlog/{agentID}.json,
+// since the SQLite databases themselves are excluded from the backup.
+func (a *App) exportBacklogsForBackup(dir string) error {
+	if a.mcpServer == nil {
+		return nil
+	}
+	dbs, err := filepath.Glob(filepath.Join(a.settings.GetConfigPath(), "backlog", "agents", "*.db"))
+	if err != nil {
+		return err
+	}
+
+	exportDir := filepath.Join(dir, "backlog")
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return err
+	}
+	for _, db := range dbs {
+		agentID := strings.TrimSuffix(filepath.Base(db), ".db")
+		items := a.mcpServer.GetBacklog().GetItemsByAgent(agentID)
+		path := filepath.Join(exportDir, agentID+".json")
+		if len(items) == 0 {
+			os.Remove(path)
+			continue
+		}
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitBackupEvent publishes a backup event (backups cover every workspace;
+// the envelope carries the active one).
+func (a *App) emitBackupEvent(eventType string, payload any) {
+	envelope := types.EventEnvelope{EventType: eventType, Payload: payload}
+	if a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	a.emitAppEvent(envelope)
+}
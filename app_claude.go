@@ -2,12 +2,18 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/metrics"
+	"claudefu/internal/outbox"
 	"claudefu/internal/providers"
 	"claudefu/internal/types"
 	"claudefu/internal/workspace"
@@ -69,6 +75,9 @@ func (a *App) emitResponseComplete(agentID, sessionID, userModel string, err err
 		return
 	}
 	wasCancelled := a.claude.WasCancelled(sessionID)
+	if wasCancelled {
+		a.takeFailedSend(sessionID)
+	}
 	payload := map[string]any{
 		"success":   err == nil,
 		"cancelled": wasCancelled,
@@ -76,6 +85,7 @@ func (a *App) emitResponseComplete(agentID, sessionID, userModel string, err err
 	if err != nil && !wasCancelled {
 		errStr := err.Error()
 		payload["error"] = errStr
+		a.emitSendFailed(agentID, sessionID, err)
 
 		// Detect OAuth token expiry and emit auth:expired for frontend modal
 		if strings.Contains(errStr, "authentication_failed") || strings.Contains(errStr, "OAuth token has expired") {
@@ -105,47 +115,248 @@ func (a *App) emitResponseComplete(agentID, sessionID, userModel string, err err
 
 // SendMessage sends a message to Claude Code, optionally with image attachments.
 // If attachments are provided, uses stdin with stream-json format.
-// If planMode is true, forces Claude into planning mode.
+// If planMode is true, forces Claude into planning mode; otherwise the agent's
+// DefaultPermissionMode applies (acceptEdits if unset).
 // The model parameter (alias or full ID, e.g. "opus[1m]" or "claude-sonnet-4-6[1m]") is passed
 // to --model verbatim; empty = omit the flag (use CLI default).
 // The effort parameter (low|medium|high|xhigh|max|auto) is passed to --effort; empty = omit.
 // Emits "response_complete" event when the Claude CLI process exits.
 func (a *App) SendMessage(agentID, sessionID, message string, attachments []types.Attachment, planMode bool, model, effort string) error {
-	if a.claude == nil {
-		return fmt.Errorf("claude service not initialized")
+	_, err := a.sendMessage(agentID, sessionID, message, attachments, planMode, model, effort)
+	return err
+}
+
+// SendMessageWithBacklog sends a message with backlog items attached as a
+// <backlog_context> block (title, context, tags) ahead of the text. Each item is
+// linked to the session, and idea/planned items move to in_progress (back again
+// if the send fails).
+func (a *App) SendMessageWithBacklog(agentID, sessionID, message string, backlogItemIDs []string, attachments []types.Attachment, planMode bool, model, effort string) error {
+	if a.mcpServer == nil {
+		return fmt.Errorf("MCP server not initialized")
+	}
+	backlog := a.mcpServer.GetBacklog()
+
+	items := make([]mcpserver.BacklogItem, 0, len(backlogItemIDs))
+	for _, id := range backlogItemIDs {
+		item := backlog.GetItem(id)
+		if item == nil {
+			return fmt.Errorf("backlog item not found: %s", id)
+		}
+		items = append(items, *item)
+	}
+
+	changed := make(map[string]bool)
+	started := make(map[string]string) // Item ID -> status before the send
+	for _, item := range items {
+		backlog.LinkSession(item.ID, sessionID)
+		if item.Status == "idea" || item.Status == "planned" {
+			started[item.ID] = item.Status
+			item.Status = "in_progress"
+			backlog.UpdateItem(item)
+			changed[item.AgentID] = true
+		}
 	}
-	if !providers.IsClaudeInstalled() {
-		return fmt.Errorf("claude CLI not installed - please install Claude Code first")
+	for id := range changed {
+		a.emitBacklogChanged(id)
+	}
+
+	_, err := a.sendMessageWithContext(agentID, sessionID, mcpserver.FormatBacklogContext(items), message, attachments, planMode, model, effort, providers.PriorityInteractive)
+	if err != nil && len(started) > 0 {
+		// The work never started: put the items back where they were, unless
+		// they were moved on in the meantime
+		for id, status := range started {
+			if item := backlog.GetItem(id); item != nil && item.Status == "in_progress" {
+				item.Status = status
+				backlog.UpdateItem(*item)
+			}
+		}
+		for id := range changed {
+			a.emitBacklogChanged(id)
+		}
+	}
+	return err
+}
+
+// sendMessage implements SendMessage and also returns the final result text
+// (used by the headless control socket, which prints the response).
+func (a *App) sendMessage(agentID, sessionID, message string, attachments []types.Attachment, planMode bool, model, effort string) (string, error) {
+	return a.sendMessageWithContext(agentID, sessionID, "", message, attachments, planMode, model, effort, providers.PriorityInteractive)
+}
+
+// sendMessageWithContext sends contextBlock (if any) followed by message. Only the
+// message itself goes into prompt history. priority is the spawn scheduler lane.
+func (a *App) sendMessageWithContext(agentID, sessionID, contextBlock, message string, attachments []types.Attachment, planMode bool, model, effort string, priority providers.SpawnPriority) (string, error) {
+	if a.claude == nil {
+		return "", fmt.Errorf("claude service not initialized")
 	}
 
 	agent := a.getAgentByID(agentID)
 	if agent == nil {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	if providers.ProviderFor(agent.Folder) == providers.ProviderClaudeCode && !providers.IsClaudeInstalled() {
+		return "", fmt.Errorf("claude CLI not installed - please install Claude Code first")
+	}
+
+	// Agents that default to plan mode plan every send (for the plan review UI too)
+	if agent.GetDefaultPermissionMode() == types.PermissionModePlan {
+		planMode = true
+	}
+
+	// Deliver the agent's pending inbox messages with the user's next message.
+	// They are marked delivered once the send is accepted (see below).
+	var inboxMessages []mcpserver.InboxMessage
+	if agent.InboxAutoInject && priority == providers.PriorityInteractive {
+		if inboxMessages = a.undeliveredInbox(agentID); len(inboxMessages) > 0 {
+			contextBlock = strings.TrimSpace(mcpserver.FormatInboxContext(inboxMessages) + "\n\n" + contextBlock)
+		}
+	}
+
+	prompt := message
+	if contextBlock != "" {
+		prompt = contextBlock + "\n\n" + message
+	}
+
+	// Reject oversized attachments here rather than letting the CLI fail mid-stream
+	if len(attachments) > 0 {
+		if err := a.EstimateSendSize(prompt, attachments).Err(); err != nil {
+			return "", err
+		}
+	}
+	a.markInboxDelivered(agentID, inboxMessages)
+
+	// Remember the prompt for composer up-arrow recall
+	if a.sessions != nil {
+		if err := a.sessions.AddPrompt(agent.Folder, sessionID, message); err != nil {
+			logger.Warnf("Failed to save prompt history: %v", err)
+		}
 	}
 
 	// Record send time BEFORE calling Claude - used to filter out historical context
 	// that Claude Code writes when resuming a session (those have old timestamps)
+	// Echo the message right away; the copy the CLI writes to the JSONL replaces it
+	var pendingID string
 	if a.rt != nil {
 		a.rt.SetLastSendTime(agentID, sessionID, time.Now())
+		a.rt.SetStreaming(agentID, sessionID, true, planMode)
+		if message != "" {
+			pendingID = a.rt.AddPendingMessage(agentID, sessionID, message)
+		}
+	}
+
+	// Snapshot the working tree so the turn's changes can be reviewed (GetSessionDiff).
+	// A message queued behind a running send extends that send's turn.
+	if !a.claude.SessionBusy(sessionID) {
+		a.beginTurnSnapshot(agent.Folder, sessionID)
+	}
+
+	// Record the send so it can be re-dispatched if the app quits mid-turn
+	outboxID := a.recordOutbox(outbox.Entry{
+		AgentID:        agentID,
+		Folder:         agent.Folder,
+		SessionID:      sessionID,
+		Context:        contextBlock,
+		Message:        message,
+		PlanMode:       planMode,
+		Model:          model,
+		Effort:         effort,
+		HadAttachments: len(attachments) > 0,
+	})
+	metrics.MessageSent(agent.GetSlug())
+	if a.rt != nil {
+		a.rt.Emit("session:message-sent", agentID, sessionID, map[string]any{
+			"message":     message,
+			"attachments": len(attachments),
+			"planMode":    planMode,
+			"model":       model,
+		})
 	}
 
 	// Call Claude - BLOCKS until CLI process exits
-	err := a.claude.SendMessage(agent.Folder, sessionID, message, attachments, planMode, model, effort)
+	result, err := a.claude.SendMessageWithPriority(agent.Folder, sessionID, prompt, attachments, planMode, model, effort, priority)
+
+	a.clearOutbox(outboxID)
+	if err != nil && a.rt != nil {
+		a.rt.RemovePendingMessage(agentID, sessionID, pendingID)
+	}
+	if errors.Is(err, providers.ErrSendCancelled) {
+		// Cancelled while queued: nothing ran, the earlier send still owns the session
+		if a.rt != nil {
+			a.rt.Emit("response_complete", agentID, sessionID, map[string]any{
+				"success":   false,
+				"cancelled": true,
+				"queued":    true,
+			})
+		}
+		return "", err
+	}
+	a.endTurnSnapshot(agent.Folder, sessionID)
+
+	// Keep failed sends for RetryFailedSend (emitResponseComplete drops cancellations)
+	if err != nil {
+		a.recordFailedSend(sessionID, failedSend{
+			agentID:      agentID,
+			contextBlock: contextBlock,
+			message:      message,
+			attachments:  attachments,
+			planMode:     planMode,
+			model:        model,
+			effort:       effort,
+			priority:     priority,
+		})
+	} else {
+		a.takeFailedSend(sessionID)
+	}
+
+	// Stay streaming if another message queued for this session has started
+	if a.rt != nil && !a.claude.SessionBusy(sessionID) {
+		a.rt.SetStreaming(agentID, sessionID, false, planMode)
+	}
 
 	// Emit response_complete event AFTER Claude finishes
 	// This is the authoritative signal that the response is complete
 	a.emitResponseComplete(agentID, sessionID, model, err)
 
-	return err
+	// Name the session after its first turn (Settings.AutoTitleSessions)
+	if err == nil {
+		go a.autoTitleSession(agentID, agent.Folder, sessionID)
+	}
+
+	// The turn may have edited files; update the sidebar's git status
+	a.requestGitStatusRefresh(agent.Folder)
+
+	return result, err
+}
+
+// EstimateSendSize reports the approximate byte and token cost of a message with
+// attachments, with warnings and errors for anything over the configured send limits.
+// The frontend calls this before SendMessage to warn the user; SendMessage enforces it.
+func (a *App) EstimateSendSize(message string, attachments []types.Attachment) providers.SendSizeEstimate {
+	var limits providers.SendSizeLimits
+	if a.settings != nil {
+		s := a.settings.GetSettings()
+		limits = providers.SendSizeLimits{
+			MaxImageBytes:  s.MaxImageBytes,
+			MaxFileBytes:   s.MaxFileBytes,
+			MaxPDFBytes:    s.MaxPDFBytes,
+			MaxSendBytes:   s.MaxSendBytes,
+			SendWarnTokens: s.SendWarnTokens,
+		}
+	}
+	est := providers.EstimateSendSize(message, attachments, limits)
+	for _, w := range est.Warnings {
+		logger.Warnf("EstimateSendSize: %s", w)
+	}
+	return est
 }
 
 // NewSession creates a new Claude Code session
 func (a *App) NewSession(agentID string) (string, error) {
-	fmt.Printf("[DEBUG] NewSession called for agentID: %s\n", agentID)
+	logger.Debugf("NewSession called for agentID: %s", agentID)
 
 	agent := a.getAgentByID(agentID)
 	if agent == nil {
-		fmt.Printf("[DEBUG] NewSession error: agent not found: %s\n", agentID)
+		logger.Debugf("NewSession error: agent not found: %s", agentID)
 		return "", fmt.Errorf("agent not found: %s", agentID)
 	}
 
@@ -157,19 +368,76 @@ func (a *App) NewSession(agentID string) (string, error) {
 
 	sessionID, err := a.sessionService.CreateSession(agent.Folder)
 	if err != nil {
-		fmt.Printf("[DEBUG] NewSession error: %v\n", err)
+		logger.Debugf("NewSession error: %v", err)
 		return "", err
 	}
 
-	fmt.Printf("[DEBUG] NewSession: created instant session %s for folder: %s\n", sessionID, agent.Folder)
+	logger.Debugf("NewSession: created instant session %s for folder: %s", sessionID, agent.Folder)
 	return sessionID, nil
 }
 
+// GetCLICapabilities returns which flags and permission modes the installed
+// claude CLI supports. Unsupported optional flags are omitted from spawned commands.
+func (a *App) GetCLICapabilities() *providers.CLICapabilities {
+	return providers.GetCLICapabilities()
+}
+
+// ProbeCLICapabilities re-runs `claude --help` (e.g. after upgrading the CLI)
+// and emits cli:capabilities with the fresh report.
+func (a *App) ProbeCLICapabilities() *providers.CLICapabilities {
+	caps := providers.ProbeCLICapabilities()
+	if a.ctx != nil {
+		wailsrt.EventsEmit(a.ctx, "cli:capabilities", caps)
+	}
+	return caps
+}
+
 // IsClaudeInstalled checks if the Claude Code CLI is available
 func (a *App) IsClaudeInstalled() bool {
 	return providers.IsClaudeInstalled()
 }
 
+// GetClaudePath returns the resolved global claude binary ("" if not found).
+func (a *App) GetClaudePath() string {
+	return providers.GetClaudePath()
+}
+
+// ResolveClaudeCommand resolves a candidate binary name or path (e.g. from the
+// settings form) without saving it. Returns "" if it cannot be found.
+func (a *App) ResolveClaudeCommand(command string) string {
+	return providers.ResolveClaudeCommand(command)
+}
+
+// GetSpawnQueue returns the claude processes currently running and waiting for
+// a spawn slot (see Settings.MaxConcurrentSpawns). Also pushed as spawn:queue events.
+func (a *App) GetSpawnQueue() providers.SpawnQueueStatus {
+	return providers.Spawns().Status()
+}
+
+// GetRateLimitStatus returns the app-wide rate-limit cooldown (pushed as
+// ratelimit:status events when it starts, extends, or ends).
+func (a *App) GetRateLimitStatus() providers.RateLimitStatus {
+	return providers.RateLimits().Status()
+}
+
+// GetQueuedMessages returns the messages waiting for an earlier send to the
+// session to finish. Changes are pushed as queue:position events.
+func (a *App) GetQueuedMessages(sessionID string) []providers.QueuedSend {
+	if a.claude == nil {
+		return []providers.QueuedSend{}
+	}
+	return a.claude.QueuedSends(sessionID)
+}
+
+// CancelQueuedMessage cancels a queued message before it is sent (all of the
+// session's queued messages if sendID is empty). Returns how many were cancelled.
+func (a *App) CancelQueuedMessage(sessionID, sendID string) (int, error) {
+	if a.claude == nil {
+		return 0, fmt.Errorf("claude service not initialized")
+	}
+	return a.claude.CancelQueuedSend(sessionID, sendID), nil
+}
+
 // ReadPlanFile reads the contents of a plan file
 func (a *App) ReadPlanFile(filePath string) (string, error) {
 	if filePath == "" {
@@ -195,7 +463,7 @@ func (a *App) GetPlanFilePath(agentID, sessionID string) string {
 func (a *App) TouchPlanFile(agentID, sessionID string) (string, error) {
 	planPath := a.GetPlanFilePath(agentID, sessionID)
 	if planPath == "" {
-		fmt.Printf("[DEBUG] TouchPlanFile: GetPlanFilePath returned empty for agent=%s session=%s\n", agentID, sessionID)
+		logger.Debugf("TouchPlanFile: GetPlanFilePath returned empty for agent=%s session=%s", agentID, sessionID)
 		return "", fmt.Errorf("no plan file path available (session may not have a slug yet)")
 	}
 
@@ -240,7 +508,7 @@ func (a *App) AnswerQuestion(agentID, sessionID, toolUseID string, questions []m
 	// This ensures GetMessages returns fresh data with is_error=false
 	if a.watcher != nil {
 		if err := a.watcher.ReloadSession(agentID, agent.Folder, sessionID); err != nil {
-			fmt.Printf("[WARN] Failed to reload session after patch: %v\n", err)
+			logger.Warnf("Failed to reload session after patch: %v", err)
 			// Continue anyway - the data is on disk, worst case user refreshes
 		}
 	}
@@ -291,7 +559,7 @@ func (a *App) RunSlashCommand(agentID, sessionID, command string) (string, error
 	// For /compact, reload the session since it rewrites the JSONL file
 	if command == "/compact" && a.watcher != nil {
 		if reloadErr := a.watcher.ReloadSession(agentID, agent.Folder, sessionID); reloadErr != nil {
-			fmt.Printf("[WARN] Failed to reload session after /compact: %v\n", reloadErr)
+			logger.Warnf("Failed to reload session after /compact: %v", reloadErr)
 		}
 	}
 
@@ -312,7 +580,7 @@ func (a *App) CancelSession(agentID, sessionID string) error {
 		return fmt.Errorf("agent not found: %s", agentID)
 	}
 
-	fmt.Printf("[DEBUG] CancelSession: agentID=%s sessionID=%s\n", agentID, sessionID)
+	logger.Debugf("CancelSession: agentID=%s sessionID=%s", agentID, sessionID)
 	return a.claude.CancelSession(sessionID)
 }
 
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"claudefu/internal/claudehome"
 )
 
 // =============================================================================
@@ -127,12 +129,7 @@ func (a *App) GetClaudeSettingsEnv(folder string) (map[string]string, error) {
 
 // GetGlobalClaudeSettingsEnv reads the "env" section from ~/.claude/settings.local.json
 func (a *App) GetGlobalClaudeSettingsEnv() (map[string]string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home dir: %w", err)
-	}
-
-	settingsPath := filepath.Join(home, ".claude", "settings.local.json")
+	settingsPath := filepath.Join(claudehome.Dir(), "settings.local.json")
 	return readSettingsEnv(settingsPath)
 }
 
@@ -262,11 +259,7 @@ func (a *App) SaveClaudeMD(folder, content string) error {
 
 // GetGlobalClaudeMD reads ~/.claude/CLAUDE.md
 func (a *App) GetGlobalClaudeMD() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home dir: %w", err)
-	}
-	data, err := os.ReadFile(filepath.Join(home, ".claude", "CLAUDE.md"))
+	data, err := os.ReadFile(filepath.Join(claudehome.Dir(), "CLAUDE.md"))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "", nil
@@ -278,11 +271,7 @@ func (a *App) GetGlobalClaudeMD() (string, error) {
 
 // SaveGlobalClaudeMD writes ~/.claude/CLAUDE.md
 func (a *App) SaveGlobalClaudeMD(content string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home dir: %w", err)
-	}
-	dir := filepath.Join(home, ".claude")
+	dir := claudehome.Dir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create .claude dir: %w", err)
 	}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/control"
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// HEADLESS CONTROL SOCKET
+// =============================================================================
+
+// initializeControlSocket serves the headless CLI (`claudefu send`, ...) on ~/.claudefu/control.sock.
+func (a *App) initializeControlSocket() {
+	if a.settings == nil {
+		return
+	}
+	a.control = control.NewService(control.SocketPath(a.settings.GetConfigPath()), controlHandler{a})
+	if err := a.control.Start(); err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Headless control socket unavailable: %v", err))
+		a.control = nil
+	}
+}
+
+// controlHandler adapts App to control.Handler. It is a separate type so the
+// handler methods are not bound to the frontend.
+type controlHandler struct {
+	a *App
+}
+
+// findAgent resolves a slug in the current workspace (case-insensitive).
+func (h controlHandler) findAgent(slug string) (*workspace.Agent, error) {
+	if h.a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	for i := range h.a.currentWorkspace.Agents {
+		agent := &h.a.currentWorkspace.Agents[i]
+		if strings.EqualFold(agent.GetSlug(), slug) {
+			return agent, nil
+		}
+	}
+	return nil, fmt.Errorf("agent %q not found in workspace %s", slug, h.a.currentWorkspace.Name)
+}
+
+// ControlAgents lists the agents in the current workspace.
+func (h controlHandler) ControlAgents() ([]control.Agent, error) {
+	if h.a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	agents := make([]control.Agent, 0, len(h.a.currentWorkspace.Agents))
+	for _, agent := range h.a.currentWorkspace.Agents {
+		agents = append(agents, control.Agent{ID: agent.ID, Slug: agent.GetSlug(), Folder: agent.Folder})
+	}
+	return agents, nil
+}
+
+// ControlSessions lists an agent's sessions from the runtime.
+func (h controlHandler) ControlSessions(slug string) ([]types.Session, error) {
+	agent, err := h.findAgent(slug)
+	if err != nil {
+		return nil, err
+	}
+	return h.a.GetSessions(agent.ID)
+}
+
+// ControlMCP hands a JSON-RPC message from `claudefu mcp-stdio` to the running MCP server.
+func (h controlHandler) ControlMCP(ctx context.Context, message json.RawMessage) (json.RawMessage, error) {
+	if h.a.mcpServer == nil || !h.a.mcpServer.IsRunning() {
+		return nil, fmt.Errorf("MCP server is not running - enable it in MCP Settings")
+	}
+	return h.a.mcpServer.HandleMessage(ctx, message)
+}
+
+// ControlSend sends through the same path as the UI, so the conversation streams
+// into any open ClaudeFu window while the CLI waits for the result.
+func (h controlHandler) ControlSend(req control.SendRequest) (*control.SendResponse, error) {
+	agent, err := h.findAgent(req.Agent)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		if sessionID, err = h.a.NewSession(agent.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := h.a.sendMessage(agent.ID, sessionID, req.Message, nil, req.PlanMode, req.Model, req.Effort)
+	if err != nil {
+		return nil, err
+	}
+	return &control.SendResponse{SessionID: sessionID, Result: result}, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"claudefu/internal/desktopnotify"
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/types"
+)
+
+// Desktop notification kinds (keys of Settings.DesktopNotificationEvents)
+const (
+	DesktopNotifyResponseComplete  = "response_complete"   // Assistant finished responding in a non-active session
+	DesktopNotifyQuestion          = "question"            // AskUserQuestion pending
+	DesktopNotifyPermissionRequest = "permission_request"  // RequestToolPermission pending
+	DesktopNotifyInboxHighPriority = "inbox_high_priority" // High-priority inbox message arrived
+)
+
+// desktopNotifyDedupWindow suppresses repeats of the same kind for the same
+// session or agent (e.g. several sends finishing back to back).
+const desktopNotifyDedupWindow = 3 * time.Second
+
+// =============================================================================
+// DESKTOP NOTIFICATION METHODS (Bound to frontend)
+// =============================================================================
+
+// TestDesktopNotification shows a sample notification, ignoring settings,
+// so the user can check OS permissions.
+func (a *App) TestDesktopNotification() error {
+	return desktopnotify.Send("ClaudeFu", "Desktop notifications are working")
+}
+
+// =============================================================================
+// DESKTOP NOTIFICATION HELPERS
+// =============================================================================
+
+// desktopNotifyEnabled reports whether notifications of kind are turned on.
+func (a *App) desktopNotifyEnabled(kind string) bool {
+	if a.settings == nil {
+		return false
+	}
+	s := a.settings.GetSettings()
+	if !s.DesktopNotifications {
+		return false
+	}
+	enabled, ok := s.DesktopNotificationEvents[kind]
+	return !ok || enabled
+}
+
+// notifyDesktop shows a notification of kind in the background if enabled.
+// target (session or agent ID) scopes the repeat suppression.
+func (a *App) notifyDesktop(kind, target, title, message string) {
+	if !a.desktopNotifyEnabled(kind) {
+		return
+	}
+	key := kind + "/" + target
+	a.desktopNotifyMu.Lock()
+	if time.Since(a.desktopNotifyLast[key]) < desktopNotifyDedupWindow {
+		a.desktopNotifyMu.Unlock()
+		return
+	}
+	if a.desktopNotifyLast == nil {
+		a.desktopNotifyLast = make(map[string]time.Time)
+	}
+	a.desktopNotifyLast[key] = time.Now()
+	a.desktopNotifyMu.Unlock()
+
+	go func() {
+		if err := desktopnotify.Send(title, message); err != nil {
+			logger.Warnf("%v", err)
+		}
+	}()
+}
+
+// notifyDesktopForEvent shows desktop notifications for the emitted events
+// that need the user. Subscribed to the active workspace's events (see subscribeEvents).
+func (a *App) notifyDesktopForEvent(envelope types.EventEnvelope) {
+	payload, _ := envelope.Payload.(map[string]any)
+	str := func(key string) string {
+		s, _ := payload[key].(string)
+		return s
+	}
+
+	switch envelope.EventType {
+	case "response_complete":
+		if cancelled, _ := payload["cancelled"].(bool); cancelled {
+			return
+		}
+		if a.rt != nil {
+			if agentID, sessionID := a.rt.GetActiveSession(); agentID == envelope.AgentID && sessionID == envelope.SessionID {
+				return // The user is looking at it
+			}
+		}
+		title := a.agentLabel(envelope.AgentID) + " finished responding"
+		message := "Response ready"
+		if success, _ := payload["success"].(bool); !success {
+			title = a.agentLabel(envelope.AgentID) + " failed"
+			message = str("error")
+		}
+		a.notifyDesktop(DesktopNotifyResponseComplete, envelope.SessionID, title, message)
+	case "mcp:askuser":
+		a.notifyDesktop(DesktopNotifyQuestion, str("id"), str("agentSlug")+" has a question", firstQuestionText(payload["questions"]))
+	case "mcp:permission-request":
+		a.notifyDesktop(DesktopNotifyPermissionRequest, str("id"), str("agentSlug")+" requests permission", str("reason"))
+	}
+}
+
+// notifyDesktopInbox shows a notification for a high-priority inbox message.
+func (a *App) notifyDesktopInbox(msg mcpserver.InboxMessage) {
+	if msg.Priority != "high" {
+		return
+	}
+	title := fmt.Sprintf("High-priority message for %s", a.agentLabel(msg.ToAgentID))
+	a.notifyDesktop(DesktopNotifyInboxHighPriority, msg.ID, title, msg.FromAgentName+": "+msg.Message)
+}
+
+// agentLabel returns an agent's slug, or "Agent" if it is unknown.
+func (a *App) agentLabel(agentID string) string {
+	if agent := a.getAgentByID(agentID); agent != nil && agent.GetSlug() != "" {
+		return agent.GetSlug()
+	}
+	return "Agent"
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net"
+	"strconv"
+
+	"claudefu/internal/diagnostics"
+	"claudefu/internal/permissions"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// DIAGNOSTICS METHODS (Bound to frontend)
+// =============================================================================
+
+// RunDiagnostics checks the claude CLI, the MCP server, each agent's sessions
+// directory and permission files, file watch usage, and ~/.claude/projects size.
+// `claudefu doctor` prints the same report from the command line.
+func (a *App) RunDiagnostics() diagnostics.Report {
+	opts := diagnosticsOptions(a.currentWorkspace)
+	opts.WatchCount = -1
+	if a.watcher != nil {
+		opts.WatchCount = a.watcher.WatchCount()
+	}
+	opts.MCPRunning = a.mcpServer != nil && a.mcpServer.IsRunning()
+	return diagnostics.Run(opts)
+}
+
+// =============================================================================
+// DIAGNOSTICS HELPERS
+// =============================================================================
+
+// diagnosticsOptions describes ws (agents, MCP address) for diagnostics.Run.
+func diagnosticsOptions(ws *workspace.Workspace) diagnostics.Options {
+	opts := diagnostics.Options{WatchCount: -1}
+	if pm, err := permissions.NewManager(); err == nil {
+		opts.Permissions = pm
+	}
+	if ws == nil {
+		return opts
+	}
+	if ws.MCPConfig.IsEnabled() {
+		opts.MCPAddr = net.JoinHostPort(ws.MCPConfig.ClientHost(), strconv.Itoa(ws.MCPConfig.GetPort()))
+	}
+	for _, agent := range ws.Agents {
+		opts.Agents = append(opts.Agents, diagnostics.Agent{Slug: agent.GetSlug(), Folder: agent.Folder})
+	}
+	return opts
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/export"
+	"claudefu/internal/git"
+	"claudefu/internal/runtime"
+)
+
+// =============================================================================
+// SESSION DIFF METHODS (Bound to frontend)
+// =============================================================================
+
+// GetSessionDiff returns per-file unified diffs of what changed in the agent's
+// repository during the session's last Claude turn (still running = live diff).
+func (a *App) GetSessionDiff(agentID, sessionID string) (*git.TurnDiff, error) {
+	folder, err := a.turnDiffFolder(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return a.turnDiffs.Diff(folder, sessionID)
+}
+
+// AcceptSessionDiffFile marks a file changed in the session's last turn as reviewed and kept.
+func (a *App) AcceptSessionDiffFile(agentID, sessionID, path string) error {
+	folder, err := a.turnDiffFolder(agentID)
+	if err != nil {
+		return err
+	}
+	return a.turnDiffs.Accept(folder, sessionID, path)
+}
+
+// RevertSessionDiffFile restores a file to its state before the session's last turn.
+func (a *App) RevertSessionDiffFile(agentID, sessionID, path string) error {
+	folder, err := a.turnDiffFolder(agentID)
+	if err != nil {
+		return err
+	}
+	if err := a.turnDiffs.Revert(folder, sessionID, path); err != nil {
+		return err
+	}
+	logger.Infof("RevertSessionDiffFile: restored %s in %s (session %s)", path, folder, sessionID[:8])
+	a.requestGitStatusRefresh(folder)
+	return nil
+}
+
+// GetSessionFileActivity returns the files a session created, modified or
+// deleted through its tool calls (Edit, Write, file-changing Bash commands),
+// each with the messages that changed it. Uses the runtime's tracking when the
+// whole session is loaded, otherwise reads the session file.
+func (a *App) GetSessionFileActivity(agentID, sessionID string) ([]runtime.FileActivity, error) {
+	if a.rt != nil {
+		if files, complete, ok := a.rt.GetSessionFileActivity(agentID, sessionID); ok && complete {
+			return files, nil
+		}
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	messages, err := export.LoadMessages(agent.Folder, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return runtime.ExtractFileActivity(agent.Folder, messages), nil
+}
+
+// =============================================================================
+// SESSION DIFF LIFECYCLE
+// =============================================================================
+
+// beginTurnSnapshot snapshots an agent's repository before a Claude turn, in
+// the background. Folders outside a git repository are skipped.
+func (a *App) beginTurnSnapshot(folder, sessionID string) {
+	if a.turnDiffs == nil {
+		return
+	}
+	a.turnDiffs.Begin(folder, sessionID)
+}
+
+// endTurnSnapshot snapshots an agent's repository after a Claude turn.
+func (a *App) endTurnSnapshot(folder, sessionID string) {
+	if a.turnDiffs == nil {
+		return
+	}
+	if err := a.turnDiffs.End(folder, sessionID); err != nil {
+		logger.Warnf("End-of-turn snapshot failed for %s: %v", folder, err)
+	}
+}
+
+// turnDiffFolder resolves an agent's folder for diff operations.
+func (a *App) turnDiffFolder(agentID string) (string, error) {
+	if a.turnDiffs == nil {
+		return "", fmt.Errorf("session diffs not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	return agent.Folder, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"claudefu/internal/permissions"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// ENVIRONMENT PROFILE METHODS (Bound to frontend)
+// =============================================================================
+
+// GetEnvProfiles returns the current workspace's environment profiles.
+func (a *App) GetEnvProfiles() ([]workspace.EnvProfile, error) {
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	if a.currentWorkspace.EnvProfiles == nil {
+		return []workspace.EnvProfile{}, nil
+	}
+	return a.currentWorkspace.EnvProfiles, nil
+}
+
+// GetActiveEnvProfile returns the name of the current workspace's active profile ("" if none).
+func (a *App) GetActiveEnvProfile() string {
+	if a.currentWorkspace == nil {
+		return ""
+	}
+	return a.currentWorkspace.ActiveProfile
+}
+
+// SaveEnvProfile creates or replaces (by name) an environment profile.
+// Saving the active profile re-applies it immediately.
+func (a *App) SaveEnvProfile(profile workspace.EnvProfile) error {
+	if a.currentWorkspace == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+	if err := normalizeEnvProfile(&profile); err != nil {
+		return err
+	}
+
+	ws := a.currentWorkspace
+	if i := slices.IndexFunc(ws.EnvProfiles, func(p workspace.EnvProfile) bool { return p.Name == profile.Name }); i >= 0 {
+		ws.EnvProfiles[i] = profile
+	} else {
+		ws.EnvProfiles = append(ws.EnvProfiles, profile)
+	}
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		return err
+	}
+	if ws.ActiveProfile == profile.Name {
+		a.applyEnvProfile()
+		a.emitEnvProfileChanged(profile.Name)
+	}
+	return nil
+}
+
+// DeleteEnvProfile removes a profile. Deleting the active profile deactivates it.
+func (a *App) DeleteEnvProfile(name string) error {
+	if a.currentWorkspace == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+	ws := a.currentWorkspace
+	i := slices.IndexFunc(ws.EnvProfiles, func(p workspace.EnvProfile) bool { return p.Name == name })
+	if i < 0 {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	ws.EnvProfiles = slices.Delete(ws.EnvProfiles, i, i+1)
+	wasActive := ws.ActiveProfile == name
+	if wasActive {
+		ws.ActiveProfile = ""
+	}
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		return err
+	}
+	if wasActive {
+		a.applyEnvProfile()
+		a.emitEnvProfileChanged(name)
+	}
+	return nil
+}
+
+// SetActiveEnvProfile switches the workspace to a profile ("" = no profile).
+// Takes effect for the next Claude process spawned; running processes keep
+// the environment they started with.
+func (a *App) SetActiveEnvProfile(name string) error {
+	if a.currentWorkspace == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+	ws := a.currentWorkspace
+	if name != "" && !slices.ContainsFunc(ws.EnvProfiles, func(p workspace.EnvProfile) bool { return p.Name == name }) {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	if ws.ActiveProfile == name {
+		return nil
+	}
+	previous := ws.ActiveProfile
+	ws.ActiveProfile = name
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		ws.ActiveProfile = previous
+		return err
+	}
+	logger.Infof("Environment profile for workspace %s: %q -> %q", ws.Name, previous, name)
+	a.applyEnvProfile()
+	a.emitEnvProfileChanged(previous)
+	return nil
+}
+
+// =============================================================================
+// ENVIRONMENT PROFILE LIFECYCLE
+// =============================================================================
+
+// applyEnvProfile pushes the current workspace's active profile to the Claude service.
+func (a *App) applyEnvProfile() {
+	if a.claude == nil {
+		return
+	}
+	profile := a.currentWorkspace.GetActiveProfile()
+	if profile == nil {
+		a.claude.SetEnvProfile("", nil, nil)
+		return
+	}
+	guard := &permissions.Guard{
+		DenySets:       profile.DenySets,
+		DenyPatterns:   profile.DenyPatterns,
+		ProtectedPaths: profile.ProtectedPaths,
+	}
+	for _, tier := range profile.DenyTiers {
+		guard.DenyTiers = append(guard.DenyTiers, permissions.RiskLevel(tier))
+	}
+	a.claude.SetEnvProfile(profile.Name, profile.EnvVars, guard)
+}
+
+// emitEnvProfileChanged emits workspace:env-profile with the active profile (nil if none)
+// and the name of the one it replaced, which the audit log records.
+func (a *App) emitEnvProfileChanged(previous string) {
+	if a.rt == nil || a.currentWorkspace == nil {
+		return
+	}
+	a.rt.Emit("workspace:env-profile", "", "", map[string]any{
+		"activeProfile":   a.currentWorkspace.ActiveProfile,
+		"previousProfile": previous,
+		"profile":         a.currentWorkspace.GetActiveProfile(),
+	})
+}
+
+// normalizeEnvProfile validates a profile and cleans its lists in place.
+func normalizeEnvProfile(p *workspace.EnvProfile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	for _, tier := range p.DenyTiers {
+		switch permissions.RiskLevel(tier) {
+		case permissions.RiskCommon, permissions.RiskPermissive, permissions.RiskYOLO:
+		default:
+			return fmt.Errorf("invalid risk tier: %s", tier)
+		}
+	}
+	for _, id := range p.DenySets {
+		if permissions.GetSetByID(id) == nil {
+			return fmt.Errorf("unknown permission set: %s", id)
+		}
+	}
+
+	var paths []string
+	for _, raw := range p.ProtectedPaths {
+		path, err := permissions.NormalizePath(raw)
+		if err != nil {
+			return err
+		}
+		if path != "" && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	p.ProtectedPaths = paths
+
+	var patterns []string
+	for _, pattern := range p.DenyPatterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	p.DenyPatterns = patterns
+
+	return workspace.ValidateEnvVarNames(p.EnvVars)
+}
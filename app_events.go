@@ -0,0 +1,63 @@
+package main
+
+import (
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/events"
+	"claudefu/internal/metrics"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// EVENT BUS LIFECYCLE
+// =============================================================================
+
+// subscribeEvents registers the app's consumers on the event bus. The frontend
+// gets every event (background workspaces' under their namespaced names) and
+// hooks run for every event; the other consumers only follow the active
+// workspace. Each consumer picks the event types it handles.
+func (a *App) subscribeEvents() {
+	a.events.Subscribe("metrics", func(e events.Event) {
+		metrics.EventPublished(e.Source)
+	})
+	a.events.Subscribe("frontend", a.emitToFrontend)
+	a.events.Subscribe("notifications", func(e events.Event) {
+		a.recordNotification(e.EventEnvelope)
+	}, events.Foreground())
+	a.events.Subscribe("activity", func(e events.Event) {
+		a.recordActivity(e.EventEnvelope)
+	}, events.Foreground())
+	a.events.Subscribe("hooks", func(e events.Event) {
+		a.dispatchHooks(e.EventEnvelope)
+	})
+	a.events.Subscribe("desktop-notifications", func(e events.Event) {
+		a.notifyDesktopForEvent(e.EventEnvelope)
+	}, events.Foreground(), events.EventTypes("response_complete", "mcp:askuser", "mcp:permission-request"))
+	a.events.Subscribe("integrations", func(e events.Event) {
+		if a.integrations != nil {
+			a.integrations.HandleEvent(e.EventEnvelope)
+		}
+	}, events.Foreground())
+}
+
+// =============================================================================
+// EVENT BUS HELPERS
+// =============================================================================
+
+// emitAppEvent publishes an envelope from the app itself (events outside the
+// runtime and MCP server).
+func (a *App) emitAppEvent(envelope types.EventEnvelope) {
+	a.events.Publish(events.Event{EventEnvelope: envelope, Source: events.SourceApp})
+}
+
+// emitToFrontend forwards a bus event to the frontend.
+func (a *App) emitToFrontend(e events.Event) {
+	if a.ctx == nil {
+		return
+	}
+	name := e.EventType
+	if e.Background {
+		name = types.WorkspaceEvent(e.WorkspaceID, e.EventType)
+	}
+	wailsrt.EventsEmit(a.ctx, name, e.EventEnvelope)
+}
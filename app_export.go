@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"claudefu/internal/export"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// EXPORT METHODS (Bound to frontend)
+// =============================================================================
+
+// ExportConversation renders a session as markdown, html, or json and writes it
+// to a file chosen in a save dialog. Returns the written path, or "" if the
+// dialog was cancelled.
+func (a *App) ExportConversation(agentID, sessionID, format string) (string, error) {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	if !export.IsValidFormat(format) {
+		return "", fmt.Errorf("unsupported export format: %q", format)
+	}
+
+	meta := export.Meta{
+		Title:     "Session " + sessionID,
+		AgentName: agent.GetSlug(),
+		Folder:    agent.Folder,
+		SessionID: sessionID,
+	}
+	if a.sessions != nil {
+		if name := a.sessions.GetSessionName(agent.Folder, sessionID); name != "" {
+			meta.Title = name
+		}
+	}
+	data, err := export.ExportSession(meta, format)
+	if err != nil {
+		return "", err
+	}
+
+	name := agent.GetSlug()
+	if name == "" {
+		name = "session"
+	}
+	path, err := wailsrt.SaveFileDialog(a.ctx, wailsrt.SaveDialogOptions{
+		Title:           "Export Conversation",
+		DefaultFilename: fmt.Sprintf("%s-%.8s%s", name, sessionID, export.FileExtension(format)),
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	return path, nil
+}
@@ -87,7 +87,7 @@ func (a *App) ListFiles(agentID string, query string, maxResults int) ([]FileInf
 	perms, err := a.GetClaudePermissions(agent.Folder)
 	if err != nil {
 		// Log but continue with just agent folder
-		fmt.Printf("[ListFiles] Warning: failed to get permissions: %v\n", err)
+		logger.Warnf("[ListFiles] Warning: failed to get permissions: %v", err)
 	}
 
 	// Build list of root directories to search
@@ -178,7 +178,7 @@ func (a *App) ListFiles(agentID string, query string, maxResults int) ([]FileInf
 		})
 
 		if err != nil && err != filepath.SkipAll {
-			fmt.Printf("[ListFiles] Walk error for %s: %v\n", root, err)
+			logger.Infof("[ListFiles] Walk error for %s: %v", root, err)
 		}
 	}
 
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"claudefu/internal/git"
+)
+
+// gitStatusMaxAge is how stale a cached status may be when the frontend asks for it.
+const gitStatusMaxAge = 5 * time.Second
+
+// =============================================================================
+// GIT STATUS METHODS (Bound to frontend)
+// =============================================================================
+
+// GetGitStatus returns an agent folder's branch, changed files, ahead/behind
+// counts, and recent commits. Served from cache when fresh.
+func (a *App) GetGitStatus(agentID string) (*git.Status, error) {
+	if a.gitStatus == nil {
+		return nil, fmt.Errorf("git status not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.gitStatus.Get(agent.Folder, gitStatusMaxAge)
+}
+
+// GetWorkspaceGitStatus returns git status for every agent in the current
+// workspace, keyed by agent ID. Agents whose status can't be read are omitted.
+func (a *App) GetWorkspaceGitStatus() (map[string]*git.Status, error) {
+	if a.gitStatus == nil {
+		return nil, fmt.Errorf("git status not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	result := make(map[string]*git.Status, len(a.currentWorkspace.Agents))
+	for _, agent := range a.currentWorkspace.Agents {
+		st, err := a.gitStatus.Get(agent.Folder, gitStatusMaxAge)
+		if err != nil {
+			logger.Warnf("GetWorkspaceGitStatus: %s: %v", agent.GetSlug(), err)
+			continue
+		}
+		result[agent.ID] = st
+	}
+	return result, nil
+}
+
+// RefreshGitStatus re-reads an agent's git status now, bypassing the cache.
+func (a *App) RefreshGitStatus(agentID string) (*git.Status, error) {
+	if a.gitStatus == nil {
+		return nil, fmt.Errorf("git status not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.gitStatus.Refresh(agent.Folder)
+}
+
+// =============================================================================
+// GIT STATUS LIFECYCLE
+// =============================================================================
+
+// initializeGitStatus starts polling git status for the current workspace's agents
+// and creates the per-turn snapshot tracker behind GetSessionDiff.
+func (a *App) initializeGitStatus() {
+	a.gitStatus = git.NewService(a.emitGitStatus)
+	a.gitStatus.Start(git.DefaultPollInterval, a.agentFolders)
+	a.turnDiffs = git.NewTurnTracker()
+}
+
+// agentFolders returns the current workspace's agent folders.
+func (a *App) agentFolders() []string {
+	ws := a.currentWorkspace
+	if ws == nil {
+		return nil
+	}
+	folders := make([]string, 0, len(ws.Agents))
+	for _, agent := range ws.Agents {
+		folders = append(folders, agent.Folder)
+	}
+	return folders
+}
+
+// requestGitStatusRefresh schedules a debounced status refresh for an agent's folder
+// (e.g. after a Claude turn that may have changed files).
+func (a *App) requestGitStatusRefresh(folder string) {
+	if a.gitStatus != nil {
+		a.gitStatus.RequestRefresh(folder)
+	}
+}
+
+// emitGitStatus emits git:status for the agent owning st.Folder.
+func (a *App) emitGitStatus(st *git.Status) {
+	if a.rt == nil {
+		return
+	}
+	agentID, ok := a.rt.GetAgentIDByFolder(st.Folder)
+	if !ok {
+		return
+	}
+	a.rt.Emit("git:status", agentID, "", map[string]any{
+		"status": st,
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"claudefu/internal/events"
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
+	"claudefu/internal/settings"
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// HANDOFF METHODS (Bound to frontend)
+// =============================================================================
+
+// GetSessionLink returns where a session's work was handed off from via
+// AgentHandoff (nil if the session wasn't started by a handoff)
+func (a *App) GetSessionLink(agentID, sessionID string) (*settings.SessionLink, error) {
+	if a.sessions == nil {
+		return nil, fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.sessions.GetSessionLink(agent.Folder, sessionID), nil
+}
+
+// GetSessionLinks returns the handoff links of all of an agent's sessions, keyed by session ID
+func (a *App) GetSessionLinks(agentID string) (map[string]settings.SessionLink, error) {
+	if a.sessions == nil {
+		return nil, fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.sessions.GetAllSessionLinks(agent.Folder), nil
+}
+
+// =============================================================================
+// HANDOFF HELPERS
+// =============================================================================
+
+// startHandoff links the target session to the handing-off session and sends
+// it the handoff packet in the background. Set as the MCP server's handoff func.
+func (a *App) startHandoff(agentID, sessionID string, handoff mcpserver.Handoff) error {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	workspaceID := ""
+	if a.currentWorkspace != nil {
+		workspaceID = a.currentWorkspace.ID
+	}
+	err := a.linkHandoffSession(agent, sessionID, handoff, func(envelope types.EventEnvelope) {
+		envelope.WorkspaceID = workspaceID
+		a.emitAppEvent(envelope)
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if _, err := a.sendMessageWithContext(agentID, sessionID, "", handoff.Message, nil, false, "", "", providers.PriorityBackground); err != nil {
+			logger.Warnf("AgentHandoff: send to %s failed: %v", agent.GetSlug(), err)
+		}
+	}()
+	return nil
+}
+
+// backgroundHandoffFunc returns the handoff func for a background workspace's
+// MCP server. The packet goes straight to the CLI: sendMessageWithContext
+// tracks sends in the active workspace's runtime, while bg's watcher picks the
+// new turn up from the JSONL.
+func (a *App) backgroundHandoffFunc(bg *backgroundWorkspace) func(agentID, sessionID string, handoff mcpserver.Handoff) error {
+	publish := a.events.BackgroundPublisher(events.SourceApp, bg.ws.ID)
+	return func(agentID, sessionID string, handoff mcpserver.Handoff) error {
+		agent := bg.findAgent(agentID)
+		if agent == nil {
+			return fmt.Errorf("agent not found: %s", agentID)
+		}
+		if a.claude == nil {
+			return fmt.Errorf("claude service not initialized")
+		}
+		if err := a.linkHandoffSession(agent, sessionID, handoff, publish); err != nil {
+			return err
+		}
+
+		go func() {
+			if _, err := a.claude.SendMessageWithPriority(agent.Folder, sessionID, handoff.Message, nil, false, "", "", providers.PriorityBackground); err != nil {
+				logger.Warnf("AgentHandoff: send to %s (workspace %s) failed: %v", agent.GetSlug(), bg.ws.Name, err)
+			}
+		}()
+		return nil
+	}
+}
+
+// linkHandoffSession records where the target session was handed off from and
+// emits session:linked.
+func (a *App) linkHandoffSession(agent *workspace.Agent, sessionID string, handoff mcpserver.Handoff, emit func(types.EventEnvelope)) error {
+	if a.sessions == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+	link := settings.SessionLink{
+		FromAgentID:   handoff.FromAgentID,
+		FromAgentSlug: handoff.FromAgentSlug,
+		FromFolder:    handoff.FromFolder,
+		FromSessionID: handoff.FromSessionID,
+		Summary:       handoff.Summary,
+		Files:         handoff.Files,
+		CreatedAt:     time.Now().UnixMilli(),
+	}
+	if err := a.sessions.SetSessionLink(agent.Folder, sessionID, link); err != nil {
+		return fmt.Errorf("failed to save session link: %w", err)
+	}
+	emit(types.EventEnvelope{
+		AgentID:   agent.ID,
+		SessionID: sessionID,
+		EventType: "session:linked",
+		Payload: map[string]any{
+			"link": link,
+		},
+	})
+	return nil
+}
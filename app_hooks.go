@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/hooks"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// HOOK METHODS (Bound to frontend)
+// =============================================================================
+
+// TestHook runs a hook once against a sample hook:test event and returns its
+// exit code and output, so a hook can be checked before saving it.
+func (a *App) TestHook(hook hooks.Hook) (hooks.Result, error) {
+	if a.hooks == nil {
+		return hooks.Result{}, fmt.Errorf("hooks not initialized")
+	}
+	envelope := types.EventEnvelope{
+		EventType: "hook:test",
+		Payload:   map[string]any{"message": "Test event from ClaudeFu"},
+	}
+	if a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	return a.hooks.Run(hook, envelope, "")
+}
+
+// =============================================================================
+// HOOK LIFECYCLE
+// =============================================================================
+
+// initializeHooks creates the hook runner with the hooks from settings.
+func (a *App) initializeHooks() {
+	a.hooks = hooks.NewRunner(providers.BuildShellEnv)
+	if a.settings != nil {
+		a.hooks.SetHooks(a.settings.GetSettings().Hooks)
+	}
+}
+
+// dispatchHooks runs the hooks matching an emitted event.
+// Subscribed to every event on the bus (see subscribeEvents).
+func (a *App) dispatchHooks(envelope types.EventEnvelope) {
+	if a.hooks == nil {
+		return
+	}
+	if envelope.WorkspaceID == "" && a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	slug := ""
+	if envelope.AgentID != "" {
+		if agent := a.getAgentByID(envelope.AgentID); agent != nil {
+			slug = agent.GetSlug()
+		}
+	}
+	a.hooks.Dispatch(envelope, slug)
+}
@@ -3,10 +3,10 @@ package main
 import (
 	"fmt"
 
-	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
-
 	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
 	"claudefu/internal/types"
+	"claudefu/internal/workspace"
 )
 
 // =============================================================================
@@ -21,6 +21,14 @@ func (a *App) GetInboxMessages(agentID string) []mcpserver.InboxMessage {
 	return a.mcpServer.GetInbox().GetMessages(agentID)
 }
 
+// GetInboxThread returns all messages in a conversation thread across local agent inboxes, oldest first
+func (a *App) GetInboxThread(threadID string) []mcpserver.InboxMessage {
+	if a.mcpServer == nil {
+		return []mcpserver.InboxMessage{}
+	}
+	return a.mcpServer.GetInbox().GetThread(threadID)
+}
+
 // GetInboxUnreadCount returns the number of unread inbox messages for an agent.
 // Returns 0 during the startup race where mcpServer is not yet wired —
 // the Sidebar listens for the "mcp:ready" event to re-poll once initialization completes.
@@ -57,7 +65,7 @@ func (a *App) DeleteInboxMessage(agentID, messageID string) bool {
 	deleted := a.mcpServer.GetInbox().DeleteMessage(agentID, messageID)
 	if deleted {
 		// Emit updated unread count
-		wailsrt.EventsEmit(a.ctx, "mcp:inbox", types.EventEnvelope{
+		a.emitAppEvent(types.EventEnvelope{
 			AgentID:   agentID,
 			EventType: "mcp:inbox",
 			Payload: map[string]any{
@@ -92,8 +100,11 @@ func (a *App) InjectInboxMessage(agentID, sessionID, messageID string) error {
 	// Format the injected message with context
 	formattedMsg := fmt.Sprintf("[Message from %s]\n\n%s", msg.FromAgentName, msg.Message)
 
-	// Send to Claude session — no model/effort override, use the agent's configured default.
-	if err := a.claude.SendMessage(agent.Folder, sessionID, formattedMsg, nil, false, "", ""); err != nil {
+	// Send through the regular pipeline (echo, streaming state, outbox) — no
+	// model/effort override, use the agent's configured default. Marked
+	// delivered first so auto-inject doesn't prepend it a second time.
+	a.mcpServer.GetInbox().MarkDelivered(agentID, []string{messageID})
+	if _, err := a.sendMessageWithContext(agentID, sessionID, "", formattedMsg, nil, false, "", "", providers.PriorityInteractive); err != nil {
 		return err
 	}
 
@@ -102,7 +113,7 @@ func (a *App) InjectInboxMessage(agentID, sessionID, messageID string) error {
 	a.mcpServer.GetInbox().DeleteMessage(agentID, messageID)
 
 	// Emit updated count
-	wailsrt.EventsEmit(a.ctx, "mcp:inbox", types.EventEnvelope{
+	a.emitAppEvent(types.EventEnvelope{
 		AgentID:   agentID,
 		EventType: "mcp:inbox",
 		Payload: map[string]any{
@@ -113,6 +124,44 @@ func (a *App) InjectInboxMessage(agentID, sessionID, messageID string) error {
 	return nil
 }
 
+// SetInboxAutoInject turns automatic inbox delivery on or off for an agent:
+// when on, undelivered inbox messages are prepended to the next message sent
+func (a *App) SetInboxAutoInject(agentID string, enabled bool) (*workspace.Agent, error) {
+	return a.UpdateAgentFields(agentID, workspace.AgentUpdate{InboxAutoInject: &enabled})
+}
+
+// undeliveredInbox returns the agent's unread inbox messages not yet delivered
+// into a session, oldest first
+func (a *App) undeliveredInbox(agentID string) []mcpserver.InboxMessage {
+	if a.mcpServer == nil {
+		return nil
+	}
+	return a.mcpServer.GetInbox().GetUndelivered(agentID)
+}
+
+// markInboxDelivered marks inbox messages injected into a send as delivered
+func (a *App) markInboxDelivered(agentID string, messages []mcpserver.InboxMessage) {
+	if a.mcpServer == nil || len(messages) == 0 {
+		return
+	}
+	inbox := a.mcpServer.GetInbox()
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	inbox.MarkDelivered(agentID, ids)
+	logger.Infof("Inbox: Delivering %d messages to agent %s with the next send", len(messages), agentID)
+
+	a.emitAppEvent(types.EventEnvelope{
+		AgentID:   agentID,
+		EventType: "mcp:inbox",
+		Payload: map[string]any{
+			"unreadCount": inbox.GetUnreadCount(agentID),
+			"delivered":   ids,
+		},
+	})
+}
+
 // GetMCPServerPort returns the port the MCP server is running on
 func (a *App) GetMCPServerPort() int {
 	if a.mcpServer == nil {
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// INBOX AUTO-RESPOND
+// Agents with InboxAutoRespond set are resumed in their selected session when
+// a high-priority inbox message is waiting and no claude process is running in
+// their folder. All undelivered messages go along as a <claudefu-inbox> block.
+// The Settings.InboxAutoRespondPaused kill-switch stops every dispatch.
+// =============================================================================
+
+const (
+	// inboxDispatchInterval is how often idle agents are re-checked for
+	// messages that arrived while they were busy
+	inboxDispatchInterval = 15 * time.Second
+
+	// inboxDispatchCooldown is the minimum time between automatic dispatches
+	// to one agent, so two auto-responding agents can't ping-pong endlessly
+	inboxDispatchCooldown = time.Minute
+
+	// inboxDispatchPrompt is the message sent after the inbox block
+	inboxDispatchPrompt = "You have new high-priority inbox messages (above). Handle them and reply to the senders with AgentMessage where a response is expected."
+)
+
+// inboxDispatcher runs the auto-respond loop.
+type inboxDispatcher struct {
+	nudge chan struct{}
+	stop  chan struct{}
+	last  map[string]time.Time // agentID → last automatic dispatch
+	mu    sync.Mutex
+}
+
+// =============================================================================
+// INBOX AUTO-RESPOND METHODS (Bound to frontend)
+// =============================================================================
+
+// SetInboxAutoRespond turns automatic inbox dispatch on or off for an agent
+func (a *App) SetInboxAutoRespond(agentID string, enabled bool) (*workspace.Agent, error) {
+	agent, err := a.UpdateAgentFields(agentID, workspace.AgentUpdate{InboxAutoRespond: &enabled})
+	if err == nil && enabled {
+		a.nudgeInboxDispatch()
+	}
+	return agent, err
+}
+
+// SetInboxAutoRespondPaused sets the global kill-switch for inbox auto-respond
+func (a *App) SetInboxAutoRespondPaused(paused bool) error {
+	if a.settings == nil {
+		return fmt.Errorf("settings manager not initialized")
+	}
+	s := a.settings.GetSettings()
+	s.InboxAutoRespondPaused = paused
+	if err := a.settings.SaveSettings(s); err != nil {
+		return err
+	}
+	logger.Infof("Inbox auto-respond paused=%v", paused)
+	if !paused {
+		a.nudgeInboxDispatch()
+	}
+	return nil
+}
+
+// =============================================================================
+// INBOX AUTO-RESPOND LIFECYCLE
+// =============================================================================
+
+// initializeInboxDispatch starts the auto-respond loop, woken by every new
+// inbox message and by a periodic re-check
+func (a *App) initializeInboxDispatch() {
+	if a.mcpServer == nil {
+		return
+	}
+	a.inboxDispatch = &inboxDispatcher{
+		nudge: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		last:  make(map[string]time.Time),
+	}
+	a.mcpServer.GetInbox().SetOnMessage(func(msg mcpserver.InboxMessage) {
+		if msg.Priority == "high" {
+			a.nudgeInboxDispatch()
+			a.notifyDesktopInbox(msg)
+		}
+	})
+	go a.runInboxDispatch(a.inboxDispatch)
+}
+
+// stopInboxDispatch stops the auto-respond loop. Sends already started run to completion.
+func (a *App) stopInboxDispatch() {
+	if a.inboxDispatch == nil {
+		return
+	}
+	if a.mcpServer != nil {
+		a.mcpServer.GetInbox().SetOnMessage(nil)
+	}
+	close(a.inboxDispatch.stop)
+}
+
+// runInboxDispatch checks for dispatchable messages until stopped
+func (a *App) runInboxDispatch(d *inboxDispatcher) {
+	ticker := time.NewTicker(inboxDispatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.nudge:
+		}
+		a.dispatchInbox(d)
+	}
+}
+
+// =============================================================================
+// INBOX AUTO-RESPOND HELPERS
+// =============================================================================
+
+// nudgeInboxDispatch asks the loop to check now (no-op if a check is already pending)
+func (a *App) nudgeInboxDispatch() {
+	if a.inboxDispatch == nil {
+		return
+	}
+	select {
+	case a.inboxDispatch.nudge <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchInbox resumes every idle auto-respond agent that has a high-priority message waiting
+func (a *App) dispatchInbox(d *inboxDispatcher) {
+	if a.settings == nil || a.settings.GetSettings().InboxAutoRespondPaused {
+		return
+	}
+	ws := a.currentWorkspace
+	if ws == nil || a.claude == nil || a.mcpServer == nil {
+		return
+	}
+	for _, agent := range ws.Agents {
+		if agent.InboxAutoRespond {
+			a.dispatchAgentInbox(d, agent)
+		}
+	}
+}
+
+// dispatchAgentInbox sends an agent's undelivered messages to its selected
+// session if one of them is high priority and the agent is idle
+func (a *App) dispatchAgentInbox(d *inboxDispatcher, agent workspace.Agent) {
+	inbox := a.mcpServer.GetInbox()
+	messages := inbox.GetUndelivered(agent.ID)
+	if !hasHighPriority(messages) {
+		return
+	}
+	sessionID := agent.SelectedSessionID
+	if sessionID == "" {
+		logger.Debugf("Inbox auto-respond: %s has no selected session", agent.GetSlug())
+		return
+	}
+	if len(a.claude.ActiveSessionsInFolder(agent.Folder)) > 0 || a.claude.SessionBusy(sessionID) {
+		return // Re-checked on the next tick
+	}
+
+	d.mu.Lock()
+	if time.Since(d.last[agent.ID]) < inboxDispatchCooldown {
+		d.mu.Unlock()
+		return
+	}
+	d.last[agent.ID] = time.Now()
+	d.mu.Unlock()
+
+	ids := make([]string, len(messages))
+	from := make([]string, 0, len(messages))
+	seen := make(map[string]bool)
+	for i, msg := range messages {
+		ids[i] = msg.ID
+		if !seen[msg.FromAgentName] {
+			seen[msg.FromAgentName] = true
+			from = append(from, msg.FromAgentName)
+		}
+	}
+	inbox.MarkDelivered(agent.ID, ids)
+	logger.Infof("Inbox auto-respond: Dispatching %d messages to %s (session %s)", len(messages), agent.GetSlug(), sessionID)
+
+	a.emitAppEvent(types.EventEnvelope{
+		AgentID:   agent.ID,
+		EventType: "mcp:inbox",
+		Payload: map[string]any{
+			"unreadCount": inbox.GetUnreadCount(agent.ID),
+			"delivered":   ids,
+		},
+	})
+	if a.rt != nil {
+		a.rt.Emit("inbox:auto-dispatch", agent.ID, sessionID, map[string]any{
+			"messageIds": ids,
+			"from":       from,
+		})
+	}
+
+	contextBlock := mcpserver.FormatInboxContext(messages)
+	go func() {
+		if _, err := a.sendMessageWithContext(agent.ID, sessionID, contextBlock, inboxDispatchPrompt, nil, false, "", "", providers.PriorityBackground); err != nil {
+			logger.Warnf("Inbox auto-respond: send to %s failed: %v", agent.GetSlug(), err)
+			if a.rt != nil {
+				a.rt.Emit("inbox:auto-dispatch-failed", agent.ID, sessionID, map[string]any{
+					"messageIds": ids,
+					"error":      err.Error(),
+				})
+			}
+		}
+		// Messages may have arrived during the turn
+		a.nudgeInboxDispatch()
+	}()
+}
+
+// hasHighPriority reports whether any of messages is high priority
+func hasHighPriority(messages []mcpserver.InboxMessage) bool {
+	for _, msg := range messages {
+		if msg.Priority == "high" {
+			return true
+		}
+	}
+	return false
+}
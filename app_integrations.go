@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/integrations"
+)
+
+// =============================================================================
+// INTEGRATION METHODS (Bound to frontend)
+// =============================================================================
+
+// GetWorkspaceIntegrations returns the current workspace's Slack/Discord
+// configuration (nil if none).
+func (a *App) GetWorkspaceIntegrations() *integrations.Config {
+	if a.currentWorkspace == nil {
+		return nil
+	}
+	return a.currentWorkspace.Integrations
+}
+
+// SaveWorkspaceIntegrations validates, saves and applies the current
+// workspace's integrations. nil removes them.
+func (a *App) SaveWorkspaceIntegrations(cfg *integrations.Config) error {
+	if a.workspace == nil || a.currentWorkspace == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	a.currentWorkspace.Integrations = cfg
+	if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
+		return fmt.Errorf("failed to save workspace: %w", err)
+	}
+	a.applyIntegrations()
+	return nil
+}
+
+// TestIntegration posts a sample message to target ("slack" or "discord")
+// using cfg, so a webhook can be checked before saving it.
+func (a *App) TestIntegration(target string, cfg integrations.Config) error {
+	if a.integrations == nil {
+		return fmt.Errorf("integrations not initialized")
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	return a.integrations.Test(target, cfg)
+}
+
+// =============================================================================
+// INTEGRATION LIFECYCLE
+// =============================================================================
+
+// initializeIntegrations creates the integrations service. Slack reaction
+// answers resolve pending AskUserQuestion calls on the MCP server.
+func (a *App) initializeIntegrations() {
+	a.integrations = integrations.NewService(func(questionID string, answers map[string]string) error {
+		return a.AnswerMCPQuestion(questionID, answers)
+	})
+	a.applyIntegrations()
+}
+
+// applyIntegrations points the integrations service at the current workspace.
+func (a *App) applyIntegrations() {
+	if a.integrations == nil {
+		return
+	}
+	if a.currentWorkspace == nil {
+		a.integrations.SetConfig("", nil)
+		return
+	}
+	a.integrations.SetConfig(a.currentWorkspace.Name, a.currentWorkspace.Integrations)
+}
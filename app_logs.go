@@ -0,0 +1,21 @@
+package main
+
+import (
+	"claudefu/internal/logging"
+)
+
+// =============================================================================
+// LOG METHODS (Bound to frontend)
+// =============================================================================
+
+// GetRecentLogs returns recent log entries (oldest first) matching filter.
+// Only entries at or above the configured levels were recorded.
+func (a *App) GetRecentLogs(filter logging.Filter) []logging.Entry {
+	return logging.GetRecent(filter)
+}
+
+// GetLogSubsystems returns the subsystem names that can be given their own
+// level in the logSubsystems setting.
+func (a *App) GetLogSubsystems() []string {
+	return logging.Subsystems
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
+	"claudefu/internal/settings"
+	"claudefu/internal/workspace"
 )
 
 // =============================================================================
@@ -173,11 +181,13 @@ func (a *App) GetDefaultMCPToolAvailability() mcpserver.ToolAvailability {
 
 // MCPPendingPermission represents a pending permission request for the frontend
 type MCPPendingPermission struct {
-	ID         string `json:"id"`
-	AgentSlug  string `json:"agentSlug"`
-	Permission string `json:"permission"`
-	Reason     string `json:"reason"`
-	CreatedAt  string `json:"createdAt"`
+	ID          string   `json:"id"`
+	AgentSlug   string   `json:"agentSlug"`
+	Permission  string   `json:"permission"`
+	Permissions []string `json:"permissions"`
+	Reason      string   `json:"reason"`
+	SessionID   string   `json:"sessionId,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
 }
 
 // AnswerPermissionRequest responds to a pending MCP permission request
@@ -192,6 +202,51 @@ func (a *App) AnswerPermissionRequest(requestID string, granted bool, permanent
 	return ppm.Respond(requestID, granted, permanent, denyReason)
 }
 
+// AnswerPermissionRequestBatch responds to a (batch) permission request with the granted
+// subset of its permissions (empty = deny) and a scope: "once", "session", or "permanent".
+// Session grants are added to --allowedTools for the requesting session's later turns.
+func (a *App) AnswerPermissionRequestBatch(requestID string, permissions []string, scope string, denyReason string) error {
+	if a.mcpServer == nil {
+		return fmt.Errorf("MCP server not initialized")
+	}
+	ppm := a.mcpServer.GetPendingPermissions()
+	if ppm == nil {
+		return fmt.Errorf("pending permissions manager not initialized")
+	}
+	return ppm.RespondWithScope(requestID, permissions, scope, denyReason)
+}
+
+// GetSessionPermissionGrants returns the session-scoped grants for a session
+func (a *App) GetSessionPermissionGrants(sessionID string) []string {
+	return providers.GetSessionGrants(sessionID)
+}
+
+// RevokeSessionPermissionGrants removes session-scoped grants (empty patterns = all).
+// Takes effect on the session's next turn.
+func (a *App) RevokeSessionPermissionGrants(sessionID string, patterns []string) {
+	providers.RevokeSessionGrants(sessionID, patterns)
+	if a.mcpServer == nil {
+		return
+	}
+	err := a.mcpServer.GetPermissionLog().Record(mcpserver.PermissionLogEntry{
+		SessionID:   sessionID,
+		Permissions: patterns,
+		Decision:    mcpserver.PermissionDecisionRevoked,
+	})
+	if err != nil {
+		logger.Warnf("Failed to log session grant revocation: %v", err)
+	}
+}
+
+// GetPermissionHistory returns logged RequestToolPermission requests and their
+// decisions (plus session grant revocations), newest first
+func (a *App) GetPermissionHistory(filter mcpserver.PermissionLogFilter) ([]mcpserver.PermissionLogEntry, error) {
+	if a.mcpServer == nil {
+		return nil, fmt.Errorf("MCP server not initialized")
+	}
+	return a.mcpServer.GetPermissionLog().History(filter)
+}
+
 // GetPendingPermissionRequests returns all pending MCP permission requests (for UI state recovery)
 func (a *App) GetPendingPermissionRequests() []MCPPendingPermission {
 	if a.mcpServer == nil {
@@ -206,11 +261,13 @@ func (a *App) GetPendingPermissionRequests() []MCPPendingPermission {
 	result := make([]MCPPendingPermission, len(pending))
 	for i, pr := range pending {
 		result[i] = MCPPendingPermission{
-			ID:         pr.ID,
-			AgentSlug:  pr.AgentSlug,
-			Permission: pr.Permission,
-			Reason:     pr.Reason,
-			CreatedAt:  pr.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:          pr.ID,
+			AgentSlug:   pr.AgentSlug,
+			Permission:  pr.Permission,
+			Permissions: pr.Permissions,
+			Reason:      pr.Reason,
+			SessionID:   pr.SessionID,
+			CreatedAt:   pr.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 	}
 	return result
@@ -262,3 +319,373 @@ func (a *App) SkipPlanReview(reviewID string) error {
 	}
 	return prm.Skip(reviewID)
 }
+
+// PlanRevisionView is a submitted plan with its diff against the previous submission
+type PlanRevisionView struct {
+	mcpserver.PlanRevision
+	Diff []mcpserver.PlanDiffLine `json:"diff,omitempty"` // Empty for the first revision
+}
+
+// GetPlanRevisions returns the plans a session submitted for review, oldest
+// first, each with a line diff against the one before it
+func (a *App) GetPlanRevisions(agentID, sessionID string) ([]PlanRevisionView, error) {
+	if a.mcpServer == nil {
+		return nil, fmt.Errorf("MCP server not initialized")
+	}
+	revisions, err := a.mcpServer.GetPlanRevisions().List(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []PlanRevisionView{}
+	var prev *mcpserver.PlanRevision
+	for i := range revisions {
+		rev := revisions[i]
+		if agentID != "" && rev.AgentID != "" && rev.AgentID != agentID {
+			continue
+		}
+		view := PlanRevisionView{PlanRevision: rev}
+		if prev != nil {
+			view.Diff = mcpserver.DiffPlans(prev.Content, rev.Content)
+		}
+		result = append(result, view)
+		prev = &revisions[i]
+	}
+	return result, nil
+}
+
+// =============================================================================
+// MCP QUERY CACHE METHODS (Bound to frontend)
+// =============================================================================
+
+// GetQueryCache returns the cached AgentQuery/SelfQuery answers, newest first
+func (a *App) GetQueryCache() ([]mcpserver.QueryCacheEntry, error) {
+	if a.mcpServer == nil {
+		return nil, fmt.Errorf("MCP server not initialized")
+	}
+	return a.mcpServer.GetQueryCache().List(), nil
+}
+
+// ClearQueryCache drops cached answers for an agent (all agents if agentID is empty)
+// and returns how many were removed
+func (a *App) ClearQueryCache(agentID string) (int, error) {
+	if a.mcpServer == nil {
+		return 0, fmt.Errorf("MCP server not initialized")
+	}
+	return a.mcpServer.GetQueryCache().Clear(agentID)
+}
+
+// =============================================================================
+// MCP REMOTE ANSWER METHODS (Bound to frontend)
+// =============================================================================
+
+// RemoteAccessInfo describes the remote answer page of the current workspace
+type RemoteAccessInfo struct {
+	Enabled  bool     `json:"enabled"`
+	Token    string   `json:"token,omitempty"`
+	URLs     []string `json:"urls"`               // One per address the server listens on, token included
+	Loopback bool     `json:"loopback,omitempty"` // Bound to loopback: no other device can reach the page
+	Notice   string   `json:"notice,omitempty"`   // Why URLs is empty
+}
+
+// GetRemoteAccess returns whether remote answers are on and the URLs to open on a phone.
+// URLs only cover addresses the running server listens on.
+func (a *App) GetRemoteAccess() RemoteAccessInfo {
+	if a.currentWorkspace == nil {
+		return RemoteAccessInfo{URLs: []string{}}
+	}
+	cfg := a.currentWorkspace.MCPConfig
+	info := RemoteAccessInfo{URLs: []string{}}
+	token := cfg.RemoteAccessToken()
+	if token == "" {
+		return info
+	}
+	info.Enabled = true
+	info.Token = token
+
+	port := a.GetMCPServerPort()
+	if port == 0 {
+		info.Notice = "MCP server is not running"
+		return info
+	}
+	bind := cfg.GetBindAddress()
+	hosts := []string{bind}
+	if bind == "" {
+		hosts = lanAddresses() // All interfaces
+	} else if ip := net.ParseIP(bind); ip != nil && ip.IsLoopback() {
+		info.Loopback = true
+		info.Notice = "MCP server is bound to loopback; set bind address 0.0.0.0 for remote access"
+		return info
+	}
+	for _, host := range hosts {
+		info.URLs = append(info.URLs, fmt.Sprintf("http://%s/remote/?token=%s", net.JoinHostPort(host, strconv.Itoa(port)), token))
+	}
+	return info
+}
+
+// SetRemoteAnswersEnabled turns the remote answer page on or off for the current
+// workspace, generating a token the first time
+func (a *App) SetRemoteAnswersEnabled(enabled bool) (RemoteAccessInfo, error) {
+	return a.updateRemoteAccess(func(cfg *workspace.MCPConfig) error {
+		cfg.RemoteEnabled = enabled
+		if enabled && cfg.RemoteToken == "" {
+			token, err := newRemoteToken()
+			if err != nil {
+				return err
+			}
+			cfg.RemoteToken = token
+		}
+		return nil
+	})
+}
+
+// RegenerateRemoteToken replaces the remote answer token, signing out every
+// browser that used the old one
+func (a *App) RegenerateRemoteToken() (RemoteAccessInfo, error) {
+	return a.updateRemoteAccess(func(cfg *workspace.MCPConfig) error {
+		token, err := newRemoteToken()
+		if err != nil {
+			return err
+		}
+		cfg.RemoteToken = token
+		return nil
+	})
+}
+
+// updateRemoteAccess applies update to the current workspace's MCP config and saves it
+func (a *App) updateRemoteAccess(update func(cfg *workspace.MCPConfig) error) (RemoteAccessInfo, error) {
+	if err := a.updateMCPConfig(update); err != nil {
+		return RemoteAccessInfo{}, err
+	}
+	return a.GetRemoteAccess(), nil
+}
+
+// =============================================================================
+// MCP AUTHENTICATION METHODS (Bound to frontend)
+// =============================================================================
+
+// MCPSecurityInfo describes how the current workspace's MCP server is exposed
+type MCPSecurityInfo struct {
+	BindAddress   string `json:"bindAddress"`   // As configured ("" = default)
+	ListenAddress string `json:"listenAddress"` // Effective host:port
+	AuthEnabled   bool   `json:"authEnabled"`
+	Token         string `json:"token,omitempty"`
+}
+
+// GetMCPSecurity returns the bind address and agent auth token of the current workspace
+func (a *App) GetMCPSecurity() MCPSecurityInfo {
+	if a.currentWorkspace == nil {
+		return MCPSecurityInfo{}
+	}
+	cfg := a.currentWorkspace.MCPConfig
+	info := MCPSecurityInfo{
+		ListenAddress: net.JoinHostPort(cfg.GetBindAddress(), strconv.Itoa(a.GetMCPServerPort())),
+		AuthEnabled:   cfg.AuthRequired(),
+	}
+	if cfg != nil {
+		info.BindAddress = cfg.BindAddress
+		info.Token = cfg.AuthToken
+	}
+	return info
+}
+
+// SetMCPBindAddress sets the interface the MCP server listens on ("" = default,
+// "0.0.0.0" = all interfaces) and restarts the server on it
+func (a *App) SetMCPBindAddress(addr string) (MCPSecurityInfo, error) {
+	addr = strings.TrimSpace(addr)
+	if err := workspace.ValidateBindAddress(addr); err != nil {
+		return MCPSecurityInfo{}, err
+	}
+	err := a.updateMCPConfig(func(cfg *workspace.MCPConfig) error {
+		cfg.BindAddress = addr
+		return nil
+	})
+	return a.GetMCPSecurity(), err
+}
+
+// SetMCPAuthEnabled turns agent authentication on or off. Turning it off lets
+// any local process use the MCP tools.
+func (a *App) SetMCPAuthEnabled(enabled bool) (MCPSecurityInfo, error) {
+	err := a.updateMCPConfig(func(cfg *workspace.MCPConfig) error {
+		cfg.AuthDisabled = !enabled
+		return nil
+	})
+	return a.GetMCPSecurity(), err
+}
+
+// RegenerateMCPAuthToken replaces the agent auth token. Claude processes
+// started after this use the new token; running ones lose MCP access.
+func (a *App) RegenerateMCPAuthToken() (MCPSecurityInfo, error) {
+	err := a.updateMCPConfig(func(cfg *workspace.MCPConfig) error {
+		token, err := newRemoteToken()
+		if err != nil {
+			return err
+		}
+		cfg.AuthToken = token
+		return nil
+	})
+	return a.GetMCPSecurity(), err
+}
+
+// =============================================================================
+// BROWSER BRIDGE METHODS (Bound to frontend)
+// =============================================================================
+
+// GetBrowserBridgeStatus returns the latest BrowserAgent bridge health check
+func (a *App) GetBrowserBridgeStatus() mcpserver.BrowserBridgeStatus {
+	if a.mcpServer == nil {
+		return mcpserver.BrowserBridgeStatus{}
+	}
+	return a.mcpServer.GetBrowserBridgeStatus()
+}
+
+// CheckBrowserBridge health-checks the BrowserAgent bridge now
+func (a *App) CheckBrowserBridge() (mcpserver.BrowserBridgeStatus, error) {
+	if a.mcpServer == nil {
+		return mcpserver.BrowserBridgeStatus{}, fmt.Errorf("MCP server not initialized")
+	}
+	return a.mcpServer.CheckBrowserBridge(a.ctx), nil
+}
+
+// SetWorkspaceBrowserBridge sets the current workspace's BrowserAgent bridge
+// endpoint. nil reverts to the endpoint in app settings.
+func (a *App) SetWorkspaceBrowserBridge(bridge *workspace.BrowserBridgeConfig) error {
+	if bridge != nil && (bridge.Port < 0 || bridge.Port > 65535) {
+		return fmt.Errorf("invalid bridge port: %d", bridge.Port)
+	}
+	return a.updateMCPConfig(func(cfg *workspace.MCPConfig) error {
+		cfg.BrowserBridge = bridge
+		return nil
+	})
+}
+
+// applyBrowserBridgeSettings sets the app-wide BrowserAgent bridge endpoint
+// on the active and background MCP servers
+func (a *App) applyBrowserBridgeSettings(s settings.Settings) {
+	defaults := browserBridgeDefaults(s)
+	if a.mcpServer != nil {
+		a.mcpServer.SetBrowserBridgeDefaults(defaults)
+	}
+	a.backgroundMu.Lock()
+	defer a.backgroundMu.Unlock()
+	for _, bg := range a.background {
+		if bg.mcp != nil {
+			bg.mcp.SetBrowserBridgeDefaults(defaults)
+		}
+	}
+}
+
+// browserBridgeDefaults returns the BrowserAgent bridge endpoint in app settings
+func browserBridgeDefaults(s settings.Settings) workspace.BrowserBridgeConfig {
+	return workspace.BrowserBridgeConfig{
+		Host:               s.BrowserBridgeHost,
+		Port:               s.BrowserBridgePort,
+		TLS:                s.BrowserBridgeTLS,
+		InsecureSkipVerify: s.BrowserBridgeInsecure,
+		Token:              s.BrowserBridgeToken,
+	}
+}
+
+// =============================================================================
+// MCP AUTHENTICATION HELPERS
+// =============================================================================
+
+// updateMCPConfig applies update to the current workspace's MCP config, saves
+// it, restarts the server if its listen address changed, and refreshes the
+// --mcp-config passed to new Claude processes
+func (a *App) updateMCPConfig(update func(cfg *workspace.MCPConfig) error) error {
+	if a.workspace == nil || a.currentWorkspace == nil {
+		return fmt.Errorf("no workspace loaded")
+	}
+	if a.currentWorkspace.MCPConfig == nil {
+		a.currentWorkspace.MCPConfig = &workspace.MCPConfig{Enabled: true}
+	}
+	cfg := a.currentWorkspace.MCPConfig
+	oldBind := cfg.GetBindAddress()
+	if err := update(cfg); err != nil {
+		return err
+	}
+	if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
+		return err
+	}
+	if a.mcpServer != nil && cfg.GetBindAddress() != oldBind {
+		if err := a.mcpServer.Restart(); err != nil {
+			return fmt.Errorf("failed to restart MCP server: %w", err)
+		}
+	}
+	a.configureMCPClients()
+	return nil
+}
+
+// ensureMCPAuthToken generates the current workspace's agent auth token on
+// first use and saves it
+func (a *App) ensureMCPAuthToken() {
+	a.ensureWorkspaceMCPAuthToken(a.currentWorkspace)
+}
+
+// ensureWorkspaceMCPAuthToken generates and saves ws's MCP agent auth token if
+// it has none yet
+func (a *App) ensureWorkspaceMCPAuthToken(ws *workspace.Workspace) {
+	if a.workspace == nil || ws == nil {
+		return
+	}
+	if cfg := ws.MCPConfig; cfg != nil && cfg.AuthToken != "" {
+		return
+	}
+	token, err := newRemoteToken()
+	if err != nil {
+		logger.Warnf("MCP auth token: %v", err)
+		return
+	}
+	if ws.MCPConfig == nil {
+		ws.MCPConfig = &workspace.MCPConfig{Enabled: true}
+	}
+	ws.MCPConfig.AuthToken = token
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		logger.Warnf("MCP auth token: failed to save workspace: %v", err)
+	}
+}
+
+// configureMCPClients points the --mcp-config of new Claude processes at the
+// MCP server's host with the current workspace's auth token
+func (a *App) configureMCPClients() {
+	if a.claude == nil || a.mcpServer == nil {
+		return
+	}
+	var cfg *workspace.MCPConfig
+	if a.currentWorkspace != nil {
+		cfg = a.currentWorkspace.MCPConfig
+	}
+	token := ""
+	if cfg.AuthRequired() && cfg != nil {
+		token = cfg.AuthToken
+	}
+	a.claude.SetMCPServer(cfg.ClientHost(), a.mcpServer.GetPort(), token)
+}
+
+// newRemoteToken returns a random 128-bit hex token
+func newRemoteToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// lanAddresses returns this machine's non-loopback IPv4 addresses, falling back
+// to localhost
+func lanAddresses() []string {
+	var hosts []string
+	addrs, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				hosts = append(hosts, ipnet.IP.String())
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{"localhost"}
+	}
+	return hosts
+}
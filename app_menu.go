@@ -104,7 +104,7 @@ func (a *App) populateWorkspaceMenu(workspaceMenu *menu.Menu) {
 	// Get all workspaces
 	workspaces, err := a.GetAllWorkspaces()
 	if err != nil {
-		fmt.Printf("[Menu] Error getting workspaces: %v\n", err)
+		logger.Warnf("[Menu] Error getting workspaces: %v", err)
 		return
 	}
 
@@ -118,7 +118,7 @@ func (a *App) populateWorkspaceMenu(workspaceMenu *menu.Menu) {
 		isSelected := ws.ID == currentID
 
 		item := workspaceMenu.AddRadio(wsName, isSelected, nil, func(_ *menu.CallbackData) {
-			fmt.Printf("[Menu] Workspace selected: %s\n", wsID)
+			logger.Infof("[Menu] Workspace selected: %s", wsID)
 			// Emit event - let frontend handle the switch
 			wailsRuntime.EventsEmit(a.ctx, "menu:switch-workspace", map[string]string{
 				"workspaceId": wsID,
@@ -163,7 +163,7 @@ func (a *App) populateAgentMenu(agentMenu *menu.Menu) {
 
 		// Use AddRadio instead of AddText for checkmark
 		agentMenu.AddRadio(agentName, isSelected, nil, func(_ *menu.CallbackData) {
-			fmt.Printf("[Menu] Switching to agent: %s\n", agentID)
+			logger.Infof("[Menu] Switching to agent: %s", agentID)
 			// Emit event to frontend to switch agent
 			wailsRuntime.EventsEmit(a.ctx, "menu:switch-agent", map[string]string{
 				"agentId": agentID,
@@ -174,7 +174,7 @@ func (a *App) populateAgentMenu(agentMenu *menu.Menu) {
 
 // emitMenuAction sends a menu action event to the frontend
 func (a *App) emitMenuAction(action string) {
-	fmt.Printf("[Menu] Action: %s\n", action)
+	logger.Infof("[Menu] Action: %s", action)
 	wailsRuntime.EventsEmit(a.ctx, action)
 }
 
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/notifications"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// NOTIFICATION CENTER METHODS (Bound to frontend)
+// =============================================================================
+
+// GetNotifications returns a newest-first page of persisted notifications
+func (a *App) GetNotifications(filter notifications.Filter) (notifications.Page, error) {
+	if a.notifications == nil {
+		return notifications.Page{}, fmt.Errorf("notification center not initialized")
+	}
+	return a.notifications.List(filter), nil
+}
+
+// GetUnreadNotificationCount returns the notification center badge count
+func (a *App) GetUnreadNotificationCount() int {
+	if a.notifications == nil {
+		return 0
+	}
+	return a.notifications.UnreadCount()
+}
+
+// MarkNotificationsRead marks notifications read (all of them if ids is empty)
+// and emits notifications:badge
+func (a *App) MarkNotificationsRead(ids []string) (int, error) {
+	if a.notifications == nil {
+		return 0, fmt.Errorf("notification center not initialized")
+	}
+	changed, err := a.notifications.MarkRead(ids)
+	if changed > 0 {
+		a.emitNotificationBadge()
+	}
+	return changed, err
+}
+
+// ClearNotifications deletes notifications (only read ones if readOnly)
+func (a *App) ClearNotifications(readOnly bool) (int, error) {
+	if a.notifications == nil {
+		return 0, fmt.Errorf("notification center not initialized")
+	}
+	removed, err := a.notifications.Clear(readOnly)
+	if removed > 0 {
+		a.emitNotificationBadge()
+	}
+	return removed, err
+}
+
+// =============================================================================
+// NOTIFICATION CENTER LIFECYCLE
+// =============================================================================
+
+// initializeNotifications loads the persisted notification history
+func (a *App) initializeNotifications() {
+	if a.settings == nil {
+		return
+	}
+	center, err := notifications.New(a.settings.GetConfigPath())
+	if err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to load notifications: %v", err))
+		return
+	}
+	a.notifications = center
+}
+
+// =============================================================================
+// NOTIFICATION CENTER HELPERS
+// =============================================================================
+
+// recordNotification persists the MCP events that need the user's attention and
+// marks question/permission notifications read once they are dismissed.
+// Subscribed to the active workspace's events (see subscribeEvents).
+func (a *App) recordNotification(envelope types.EventEnvelope) {
+	if a.notifications == nil {
+		return
+	}
+	payload, _ := envelope.Payload.(map[string]any)
+	str := func(key string) string {
+		s, _ := payload[key].(string)
+		return s
+	}
+
+	n := notifications.Notification{
+		WorkspaceID: envelope.WorkspaceID,
+		SessionID:   envelope.SessionID,
+	}
+	switch envelope.EventType {
+	case "mcp:notification":
+		n.Kind = notifications.KindNotification
+		n.Level = str("type")
+		n.Title = str("title")
+		n.Message = str("message")
+		n.AgentSlug = str("from_agent")
+	case "mcp:askuser":
+		n.Kind = notifications.KindQuestion
+		n.Level = "question"
+		n.Message = firstQuestionText(payload["questions"])
+		n.AgentSlug = str("agentSlug")
+		n.RefID = str("id")
+	case "mcp:askuser:timeout":
+		n.Kind = notifications.KindQuestionTimeout
+		n.Level = "warning"
+		n.Title = "Question timed out after " + str("timeout")
+		n.Message = firstQuestionText(payload["questions"])
+		n.AgentSlug = str("agentSlug")
+		n.RefID = str("id")
+	case "mcp:permission-request":
+		n.Kind = notifications.KindPermissionRequest
+		n.Level = "question"
+		n.Title = "Permission requested"
+		n.Message = str("reason")
+		n.AgentSlug = str("agentSlug")
+		n.SessionID = str("sessionId")
+		n.RefID = str("id")
+	case "mcp:askuser:dismissed":
+		a.markNotificationRefRead(str("questionId"), notifications.KindQuestion)
+		return
+	case "mcp:permission-request:dismissed":
+		a.markNotificationRefRead(str("requestId"), notifications.KindPermissionRequest)
+		return
+	default:
+		return
+	}
+
+	if _, err := a.notifications.Add(n); err != nil {
+		logger.Warnf("Failed to record notification: %v", err)
+		return
+	}
+	a.emitNotificationBadge()
+}
+
+// markNotificationRefRead marks the notification of a dismissed question or
+// permission request read
+func (a *App) markNotificationRefRead(refID, kind string) {
+	changed, err := a.notifications.MarkRefRead(refID, kind)
+	if err != nil {
+		logger.Warnf("Failed to mark notification read: %v", err)
+	}
+	if changed > 0 {
+		a.emitNotificationBadge()
+	}
+}
+
+// emitNotificationBadge emits notifications:badge with the unread count
+func (a *App) emitNotificationBadge() {
+	if a.ctx == nil || a.notifications == nil {
+		return
+	}
+	wailsrt.EventsEmit(a.ctx, "notifications:badge", map[string]any{
+		"unreadCount": a.notifications.UnreadCount(),
+	})
+}
+
+// firstQuestionText returns the text of the first AskUserQuestion question,
+// with " (+N more)" when several were asked
+func firstQuestionText(questions any) string {
+	list, _ := questions.([]map[string]any)
+	if len(list) == 0 {
+		return ""
+	}
+	text, _ := list[0]["question"].(string)
+	if len(list) > 1 {
+		text = strings.TrimSpace(fmt.Sprintf("%s (+%d more)", text, len(list)-1))
+	}
+	return text
+}
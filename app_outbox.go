@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/outbox"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// OUTBOX METHODS (Bound to frontend)
+// =============================================================================
+
+// GetInterruptedSends returns the current workspace's sends whose turn was cut
+// short when ClaudeFu last quit.
+func (a *App) GetInterruptedSends() ([]outbox.Entry, error) {
+	if a.outbox == nil {
+		return nil, fmt.Errorf("outbox not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	return a.outbox.Interrupted(a.currentWorkspace.ID), nil
+}
+
+// ResendInterrupted re-dispatches an interrupted send to its session with the
+// original mode, model, and effort. Attachments were not persisted and are not
+// re-sent. Blocks until the response completes, like SendMessage.
+func (a *App) ResendInterrupted(id string) error {
+	if a.outbox == nil {
+		return fmt.Errorf("outbox not initialized")
+	}
+	entry, err := a.outbox.Get(id)
+	if err != nil {
+		return err
+	}
+	if a.currentWorkspace == nil || a.currentWorkspace.ID != entry.WorkspaceID {
+		return fmt.Errorf("send belongs to another workspace")
+	}
+	if a.getAgentByID(entry.AgentID) == nil {
+		return fmt.Errorf("agent not found: %s", entry.AgentID)
+	}
+	if err := a.outbox.Remove(id); err != nil {
+		return err
+	}
+	logger.Infof("Re-sending interrupted message to session %s", entry.SessionID)
+	_, err = a.sendMessageWithContext(entry.AgentID, entry.SessionID, entry.Context, entry.Message, nil, entry.PlanMode, entry.Model, entry.Effort, providers.PriorityInteractive)
+	return err
+}
+
+// DismissInterrupted discards an interrupted send without re-sending it.
+func (a *App) DismissInterrupted(id string) error {
+	if a.outbox == nil {
+		return fmt.Errorf("outbox not initialized")
+	}
+	return a.outbox.Remove(id)
+}
+
+// =============================================================================
+// OUTBOX LIFECYCLE
+// =============================================================================
+
+// initializeOutbox loads sends left in flight by the previous run, drops those
+// whose turn finished anyway, and emits outbox:interrupted for the rest.
+func (a *App) initializeOutbox() {
+	if a.settings == nil {
+		return
+	}
+	ob, err := outbox.New(a.settings.GetConfigPath())
+	if err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to load outbox: %v", err))
+		return
+	}
+	a.outbox = ob
+	if _, err := a.outbox.PruneCompleted(); err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to prune outbox: %v", err))
+	}
+
+	if a.currentWorkspace == nil {
+		return
+	}
+	if interrupted := a.outbox.Interrupted(a.currentWorkspace.ID); len(interrupted) > 0 {
+		wailsrt.LogInfo(a.ctx, fmt.Sprintf("Found %d interrupted send(s)", len(interrupted)))
+		a.emitAppEvent(types.EventEnvelope{
+			WorkspaceID: a.currentWorkspace.ID,
+			EventType:   "outbox:interrupted",
+			Payload: map[string]any{
+				"entries": interrupted,
+			},
+		})
+	}
+}
+
+// recordOutbox persists a send that is about to start. Returns "" if the
+// outbox is unavailable or the write failed (the send still goes ahead).
+func (a *App) recordOutbox(entry outbox.Entry) string {
+	if a.outbox == nil {
+		return ""
+	}
+	if a.currentWorkspace != nil {
+		entry.WorkspaceID = a.currentWorkspace.ID
+	}
+	id, err := a.outbox.Add(entry)
+	if err != nil {
+		logger.Warnf("Failed to record send in outbox: %v", err)
+		return ""
+	}
+	return id
+}
+
+// clearOutbox removes a send recorded by recordOutbox once its process has exited.
+func (a *App) clearOutbox(id string) {
+	if a.outbox == nil || id == "" {
+		return
+	}
+	if err := a.outbox.Remove(id); err != nil {
+		logger.Warnf("Failed to clear outbox entry: %v", err)
+	}
+}
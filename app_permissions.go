@@ -3,8 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 
 	"claudefu/internal/permissions"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
 )
 
 // =============================================================================
@@ -69,7 +73,9 @@ func (a *App) GetAgentPermissionsOrGlobal(folder string) (*permissions.ClaudeFuP
 }
 
 // SaveAgentPermissions saves permissions for a specific agent folder.
-// Automatically syncs to Claude's settings.local.json after saving.
+// Automatically syncs to Claude's settings.local.json after saving, unless the
+// file has entries ClaudeFu lacks; then permissions:sync-conflict is emitted
+// so they can be reconciled (ReconcileClaudeSettings) instead of overwritten.
 func (a *App) SaveAgentPermissions(folder string, perms permissions.ClaudeFuPermissions) error {
 	if folder == "" {
 		return fmt.Errorf("folder is required")
@@ -80,13 +86,29 @@ func (a *App) SaveAgentPermissions(folder string, perms permissions.ClaudeFuPerm
 		return fmt.Errorf("failed to create permissions manager: %w", err)
 	}
 
+	// Entries that only settings.local.json had before this save were added
+	// outside ClaudeFu; overwriting would silently drop them
+	before, _, diffErr := a.diffClaudeSettings(folder)
+
 	if err := mgr.SaveAgentPermissions(folder, &perms); err != nil {
 		return err
 	}
 
+	if diffErr == nil && before.HasClaudeOnly() {
+		logger.Warnf("[Permissions] settings.local.json for %s has entries ClaudeFu lacks; skipping auto-sync", folder)
+		a.emitAppEvent(types.EventEnvelope{
+			EventType: "permissions:sync-conflict",
+			Payload: map[string]any{
+				"folder":  folder,
+				"entries": before.Entries,
+			},
+		})
+		return nil
+	}
+
 	// Auto-sync to Claude's settings.local.json so CLI picks up changes immediately
 	if syncErr := a.SyncToClaudeSettings(folder); syncErr != nil {
-		fmt.Printf("[Permissions] Auto-sync to settings.local.json failed: %v\n", syncErr)
+		logger.Warnf("[Permissions] Auto-sync to settings.local.json failed: %v", syncErr)
 		// Don't fail the save — the ClaudeFu permissions are saved, sync is best-effort
 	}
 
@@ -187,16 +209,28 @@ func (a *App) SyncToClaudeSettings(folder string) error {
 	allowList := mgr.CompileAllowList(perms)
 	denyList := mgr.CompileDenyList(perms)
 
-	// Convert stored paths to Claude's gitignore-style syntax for settings.local.json
-	// ~/svml stays as ~/svml (Claude understands ~)
-	// /mnt/external becomes //mnt/external (// = absolute in gitignore syntax)
-	claudeDirs := make([]string, 0, len(perms.AdditionalDirectories))
-	for _, d := range perms.AdditionalDirectories {
-		expanded, err := permissions.ExpandPath(d)
-		if err != nil {
-			continue
+	// Keep the file's own deny entries unless ClaudeFu allows them (a deny
+	// collision, reported by DiffClaudeSettings)
+	if existing, err := a.GetClaudePermissions(folder); err == nil {
+		for _, d := range existing.Deny {
+			if !slices.Contains(allowList, d) && !slices.Contains(denyList, d) {
+				denyList = append(denyList, d)
+			}
 		}
-		claudeDirs = append(claudeDirs, permissions.ToClaudeSettingsPath(expanded))
+	}
+
+	// Directories are global + agent ones, as passed to --add-dir
+	dirs, err := mgr.CompileDirectories(folder)
+	if err != nil {
+		return fmt.Errorf("failed to compile directories: %w", err)
+	}
+
+	// Convert expanded paths to Claude's gitignore-style syntax for settings.local.json
+	// /Users/jasdeep/svml becomes ~/svml (Claude understands ~)
+	// /mnt/external becomes //mnt/external (// = absolute in gitignore syntax)
+	claudeDirs := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		claudeDirs = append(claudeDirs, permissions.ToClaudeSettingsPath(d))
 	}
 
 	// Write to Claude's settings.local.json using existing method
@@ -492,3 +526,152 @@ func findPermissionTier(perm string, set permissions.PermissionSet) string {
 
 	return ""
 }
+
+// =============================================================================
+// DANGEROUS PERMISSION METHODS (Bound to frontend)
+// =============================================================================
+
+// GetEffectivePermissions returns the exact --tools, --allowedTools and
+// --disallowedTools values (and --add-dir entries) a claude process spawned
+// in folder gets, and which YOLO-tier patterns are withheld until acknowledged.
+func (a *App) GetEffectivePermissions(folder string) (providers.EffectivePermissions, error) {
+	if a.claude == nil {
+		return providers.EffectivePermissions{}, fmt.Errorf("claude service not initialized")
+	}
+	if folder == "" {
+		return providers.EffectivePermissions{}, fmt.Errorf("folder is required")
+	}
+	return a.claude.EffectivePermissions(folder, ""), nil
+}
+
+// AcknowledgeDangerousPermissions confirms that YOLO-tier patterns (as reported
+// by permissions:dangerous or GetEffectivePermissions) may be auto-approved
+// for folder's agent. Acknowledgements are saved in settings.
+func (a *App) AcknowledgeDangerousPermissions(folder string, patterns []string) error {
+	if a.settings == nil {
+		return fmt.Errorf("settings manager not initialized")
+	}
+	if folder == "" {
+		return fmt.Errorf("folder is required")
+	}
+	folder = filepath.Clean(folder)
+	s := a.settings.GetSettings()
+	if s.AcknowledgedDangerousPermissions == nil {
+		s.AcknowledgedDangerousPermissions = make(map[string][]string)
+	}
+	acked := s.AcknowledgedDangerousPermissions[folder]
+	for _, p := range patterns {
+		if !slices.Contains(acked, p) {
+			acked = append(acked, p)
+		}
+	}
+	s.AcknowledgedDangerousPermissions[folder] = acked
+	if err := a.settings.SaveSettings(s); err != nil {
+		return err
+	}
+	providers.SetDangerousAcknowledgements(s.AcknowledgedDangerousPermissions)
+	logger.Infof("Acknowledged %d YOLO-tier permissions for %s", len(patterns), folder)
+	return nil
+}
+
+// RevokeDangerousPermissions withdraws folder's acknowledgements, so its
+// YOLO-tier patterns are withheld again.
+func (a *App) RevokeDangerousPermissions(folder string) error {
+	if a.settings == nil {
+		return fmt.Errorf("settings manager not initialized")
+	}
+	s := a.settings.GetSettings()
+	delete(s.AcknowledgedDangerousPermissions, filepath.Clean(folder))
+	if err := a.settings.SaveSettings(s); err != nil {
+		return err
+	}
+	providers.SetDangerousAcknowledgements(s.AcknowledgedDangerousPermissions)
+	return nil
+}
+
+// =============================================================================
+// CLAUDE SETTINGS SYNC METHODS (Bound to frontend)
+// SyncToClaudeSettings overwrites settings.local.json with ClaudeFu's view.
+// These diff the two sides instead and apply a direction chosen per entry.
+// =============================================================================
+
+// DiffClaudeSettings reports the differences between folder's ClaudeFu
+// permissions and its settings.local.json: patterns or directories present on
+// only one side, and deny-list collisions.
+func (a *App) DiffClaudeSettings(folder string) (*permissions.SyncReport, error) {
+	if folder == "" {
+		return nil, fmt.Errorf("folder is required")
+	}
+	report, _, err := a.diffClaudeSettings(folder)
+	return report, err
+}
+
+// ReconcileClaudeSettings applies a direction to each chosen entry of
+// DiffClaudeSettings' report (entries left out are untouched) and returns the
+// report afterwards.
+func (a *App) ReconcileClaudeSettings(folder string, resolutions []permissions.SyncResolution) (*permissions.SyncReport, error) {
+	if folder == "" {
+		return nil, fmt.Errorf("folder is required")
+	}
+	report, claude, err := a.diffClaudeSettings(folder)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := permissions.PlanSync(report, claude.Allow, claude.Deny, claude.AdditionalDirectories, resolutions)
+	if err != nil {
+		return nil, err
+	}
+
+	mgr, err := permissions.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create permissions manager: %w", err)
+	}
+	if len(plan.GrantTools) > 0 {
+		if _, err := mgr.GrantAgentPermissions(folder, plan.GrantTools); err != nil {
+			return nil, fmt.Errorf("failed to grant permissions: %w", err)
+		}
+	}
+	if len(plan.RevokeTools) > 0 {
+		if _, err := mgr.RevokeAgentPermissions(folder, plan.RevokeTools); err != nil {
+			return nil, fmt.Errorf("failed to revoke permissions: %w", err)
+		}
+	}
+	if len(plan.AddDirs) > 0 || len(plan.RemoveDirs) > 0 {
+		if err := mgr.UpdateAgentDirectories(folder, plan.AddDirs, plan.RemoveDirs); err != nil {
+			return nil, err
+		}
+	}
+	if plan.ClaudeChanged {
+		if err := a.SaveClaudePermissions(folder, plan.ClaudeAllow, plan.ClaudeDeny, plan.ClaudeDirs); err != nil {
+			return nil, err
+		}
+	}
+	logger.Infof("[Permissions] Reconciled %d entries for %s with settings.local.json", len(resolutions), folder)
+
+	report, _, err = a.diffClaudeSettings(folder)
+	return report, err
+}
+
+// diffClaudeSettings diffs folder's compiled ClaudeFu allow list and
+// directories (global + agent, as passed to --add-dir) against
+// settings.local.json, returning the report and the file's permissions.
+func (a *App) diffClaudeSettings(folder string) (*permissions.SyncReport, ClaudePermissions, error) {
+	mgr, err := permissions.NewManager()
+	if err != nil {
+		return nil, ClaudePermissions{}, fmt.Errorf("failed to create permissions manager: %w", err)
+	}
+	perms, err := mgr.GetAgentPermissionsOrGlobal(folder)
+	if err != nil {
+		return nil, ClaudePermissions{}, fmt.Errorf("failed to load permissions: %w", err)
+	}
+	dirs, err := mgr.CompileDirectories(folder)
+	if err != nil {
+		return nil, ClaudePermissions{}, fmt.Errorf("failed to compile directories: %w", err)
+	}
+	claude, err := a.GetClaudePermissions(folder)
+	if err != nil {
+		return nil, ClaudePermissions{}, err
+	}
+	report := permissions.DiffSettings(mgr.CompileAllowList(perms), dirs, claude.Allow, claude.Deny, claude.AdditionalDirectories)
+	return report, claude, nil
+}
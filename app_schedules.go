@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/providers"
+	"claudefu/internal/schedule"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// SCHEDULE METHODS (Bound to frontend)
+// =============================================================================
+
+// GetSchedules returns the current workspace's scheduled prompts.
+func (a *App) GetSchedules() ([]schedule.Schedule, error) {
+	wsID, err := a.scheduleWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	return a.schedules.List(wsID), nil
+}
+
+// CreateSchedule adds a scheduled prompt for an agent in the current workspace.
+func (a *App) CreateSchedule(input schedule.Input) (*schedule.Schedule, error) {
+	wsID, err := a.scheduleWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	if a.getAgentByID(input.AgentID) == nil {
+		return nil, fmt.Errorf("agent not found: %s", input.AgentID)
+	}
+	s, err := a.schedules.Create(wsID, input)
+	if err != nil {
+		return nil, err
+	}
+	a.emitScheduleEvent("schedules:changed", *s, map[string]any{"action": "created"})
+	return s, nil
+}
+
+// UpdateSchedule replaces a schedule's agent, cron, prompt, session, model, and enabled state.
+func (a *App) UpdateSchedule(id string, input schedule.Input) (*schedule.Schedule, error) {
+	if _, err := a.scheduleWorkspaceID(); err != nil {
+		return nil, err
+	}
+	if a.getAgentByID(input.AgentID) == nil {
+		return nil, fmt.Errorf("agent not found: %s", input.AgentID)
+	}
+	s, err := a.schedules.Update(id, input)
+	if err != nil {
+		return nil, err
+	}
+	a.emitScheduleEvent("schedules:changed", *s, map[string]any{"action": "updated"})
+	return s, nil
+}
+
+// SetScheduleEnabled pauses or resumes a schedule.
+func (a *App) SetScheduleEnabled(id string, enabled bool) (*schedule.Schedule, error) {
+	if _, err := a.scheduleWorkspaceID(); err != nil {
+		return nil, err
+	}
+	s, err := a.schedules.SetEnabled(id, enabled)
+	if err != nil {
+		return nil, err
+	}
+	a.emitScheduleEvent("schedules:changed", *s, map[string]any{"action": "updated"})
+	return s, nil
+}
+
+// DeleteSchedule removes a schedule.
+func (a *App) DeleteSchedule(id string) error {
+	if _, err := a.scheduleWorkspaceID(); err != nil {
+		return err
+	}
+	s, err := a.schedules.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := a.schedules.Delete(id); err != nil {
+		return err
+	}
+	a.emitScheduleEvent("schedules:changed", *s, map[string]any{"action": "deleted"})
+	return nil
+}
+
+// RunScheduleNow fires a schedule immediately in the background.
+func (a *App) RunScheduleNow(id string) error {
+	if _, err := a.scheduleWorkspaceID(); err != nil {
+		return err
+	}
+	return a.schedules.RunNow(id)
+}
+
+// ValidateCron checks a cron expression and returns its next few run times
+// (RFC 3339, local time) for the schedule editor preview.
+func (a *App) ValidateCron(expr string, count int) ([]string, error) {
+	cron, err := schedule.ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	count = min(max(count, 1), 20)
+	runs := make([]string, 0, count)
+	next := time.Now()
+	for range count {
+		if next = cron.Next(next); next.IsZero() {
+			break
+		}
+		runs = append(runs, next.Format(time.RFC3339))
+	}
+	return runs, nil
+}
+
+// =============================================================================
+// SCHEDULER LIFECYCLE
+// =============================================================================
+
+// initializeSchedules loads schedules and starts the ticker loop.
+func (a *App) initializeSchedules() {
+	if a.settings == nil {
+		return
+	}
+	mgr, err := schedule.NewManager(a.settings.GetConfigPath())
+	if err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Failed to load schedules: %v", err))
+		return
+	}
+	a.schedules = mgr
+	a.schedules.SetOnChange(func(s schedule.Schedule) {
+		a.emitScheduleEvent("schedules:changed", s, map[string]any{"action": "ran"})
+	})
+	a.schedules.Start(a.fireSchedule)
+}
+
+// fireSchedule sends a schedule's prompt on the scheduled spawn lane. Schedules
+// only fire while their workspace is the current one. Emits schedule:fired once
+// the target session is known and schedule:failed if anything goes wrong.
+func (a *App) fireSchedule(s schedule.Schedule) (string, error) {
+	sessionID, err := a.sendScheduledPrompt(s)
+	if err != nil {
+		logger.Warnf("Schedule %q failed: %v", s.Name, err)
+		a.emitScheduleEvent("schedule:failed", s, map[string]any{
+			"sessionId": sessionID,
+			"error":     err.Error(),
+		})
+	}
+	return sessionID, err
+}
+
+func (a *App) sendScheduledPrompt(s schedule.Schedule) (string, error) {
+	if a.currentWorkspace == nil || a.currentWorkspace.ID != s.WorkspaceID {
+		return "", fmt.Errorf("workspace is not open")
+	}
+	agent := a.getAgentByID(s.AgentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", s.AgentID)
+	}
+
+	sessionID := s.SessionID
+	if sessionID == "" {
+		var err error
+		if sessionID, err = a.NewSession(agent.ID); err != nil {
+			return "", fmt.Errorf("failed to create session: %w", err)
+		}
+		if a.sessions != nil {
+			_ = a.sessions.SetSessionName(agent.Folder, sessionID, s.Name)
+		}
+	}
+
+	a.emitScheduleEvent("schedule:fired", s, map[string]any{"sessionId": sessionID})
+	_, err := a.sendMessageWithContext(agent.ID, sessionID, "", s.Prompt, nil, false, s.Model, "", providers.PriorityScheduled)
+	return sessionID, err
+}
+
+// scheduleWorkspaceID returns the current workspace ID for schedule operations.
+func (a *App) scheduleWorkspaceID() (string, error) {
+	if a.schedules == nil {
+		return "", fmt.Errorf("scheduler not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return "", fmt.Errorf("no workspace loaded")
+	}
+	return a.currentWorkspace.ID, nil
+}
+
+// emitScheduleEvent emits a schedule event with the schedule merged into payload.
+func (a *App) emitScheduleEvent(eventType string, s schedule.Schedule, payload map[string]any) {
+	if a.ctx == nil {
+		return
+	}
+	payload["schedule"] = s
+	sessionID, _ := payload["sessionId"].(string)
+	a.emitAppEvent(types.EventEnvelope{
+		WorkspaceID: s.WorkspaceID,
+		AgentID:     s.AgentID,
+		SessionID:   sessionID,
+		EventType:   eventType,
+		Payload:     payload,
+	})
+}
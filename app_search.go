@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+
+	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"claudefu/internal/search"
+)
+
+// =============================================================================
+// SESSION SEARCH METHODS (Bound to frontend)
+// =============================================================================
+
+// SessionSearchHit is a search match resolved to a workspace agent.
+type SessionSearchHit struct {
+	search.Hit
+	AgentID     string `json:"agentId"`
+	AgentSlug   string `json:"agentSlug"`
+	SessionName string `json:"sessionName,omitempty"`
+}
+
+// SearchSessions full-text searches message content across the current workspace's
+// agents, or only agentID's sessions if set. Every word must match; "refactor*"
+// matches prefixes. Returns up to 50 hits, best first.
+func (a *App) SearchSessions(query, agentID string) ([]SessionSearchHit, error) {
+	if a.search == nil {
+		return nil, fmt.Errorf("search index not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+
+	// folder -> first agent owning it (agents can share a folder)
+	owners := make(map[string]int)
+	var folders []string
+	for i, agent := range a.currentWorkspace.Agents {
+		if agentID != "" && agent.ID != agentID {
+			continue
+		}
+		if _, ok := owners[agent.Folder]; !ok {
+			owners[agent.Folder] = i
+			folders = append(folders, agent.Folder)
+		}
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	// Catch up on writes the watcher did not see (only selected sessions are file-watched).
+	// Incremental: unchanged files cost one stat.
+	for _, folder := range folders {
+		if _, err := a.search.IndexFolder(folder); err != nil {
+			logger.Warnf("SearchSessions: failed to index %s: %v", folder, err)
+		}
+	}
+
+	hits, err := a.search.Search(query, folders, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SessionSearchHit, 0, len(hits))
+	for _, h := range hits {
+		agent := a.currentWorkspace.Agents[owners[h.Folder]]
+		hit := SessionSearchHit{Hit: h, AgentID: agent.ID, AgentSlug: agent.GetSlug()}
+		if a.sessions != nil {
+			hit.SessionName = a.sessions.GetSessionName(h.Folder, h.SessionID)
+		}
+		result = append(result, hit)
+	}
+	return result, nil
+}
+
+// =============================================================================
+// SESSION SEARCH INDEX LIFECYCLE
+// =============================================================================
+
+// initializeSearch opens the search index. Watcher events keep it current (see onSessionFileChanged).
+func (a *App) initializeSearch() {
+	if a.settings == nil {
+		return
+	}
+	index, err := search.Open(a.settings.GetConfigPath())
+	if err != nil {
+		wailsrt.LogWarning(a.ctx, fmt.Sprintf("Session search unavailable: %v", err))
+		return
+	}
+	a.search = index
+}
+
+// indexWorkspaceSessions indexes all agents' sessions in the background
+// (incremental, so only new content is read after the first run).
+func (a *App) indexWorkspaceSessions() {
+	if a.search == nil || a.currentWorkspace == nil {
+		return
+	}
+	folders := make([]string, 0, len(a.currentWorkspace.Agents))
+	for _, agent := range a.currentWorkspace.Agents {
+		folders = append(folders, agent.Folder)
+	}
+	index := a.search
+	go func() {
+		for _, folder := range folders {
+			if _, err := index.IndexFolder(folder); err != nil {
+				logger.Warnf("search: failed to index %s: %v", folder, err)
+			}
+		}
+	}()
+}
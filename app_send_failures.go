@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+)
+
+// failedSend is what RetryFailedSend needs to repeat a send.
+type failedSend struct {
+	agentID      string
+	contextBlock string
+	message      string
+	attachments  []types.Attachment
+	planMode     bool
+	model        string
+	effort       string
+	priority     providers.SpawnPriority
+}
+
+// =============================================================================
+// SEND FAILURE METHODS (Bound to frontend)
+// =============================================================================
+
+// RetryFailedSend re-sends a session's last failed message with the same
+// attachments and options. Emits response_complete like SendMessage.
+func (a *App) RetryFailedSend(agentID, sessionID string) error {
+	f, ok := a.takeFailedSend(sessionID)
+	if !ok {
+		return fmt.Errorf("no failed send to retry for session %s", sessionID)
+	}
+	if f.agentID != agentID {
+		a.recordFailedSend(sessionID, f)
+		return fmt.Errorf("failed send belongs to a different agent")
+	}
+	_, err := a.sendMessageWithContext(agentID, sessionID, f.contextBlock, f.message, f.attachments, f.planMode, f.model, f.effort, f.priority)
+	return err
+}
+
+// =============================================================================
+// SEND FAILURE HELPERS
+// =============================================================================
+
+// emitSendFailed emits session:send-failed with the exit code, classified
+// cause, stderr tail, and reproduce command of a failed send.
+func (a *App) emitSendFailed(agentID, sessionID string, err error) {
+	if a.rt == nil {
+		return
+	}
+	status, _, _ := parseClaudeCLIError(err.Error())
+	payload := map[string]any{
+		"error":     err.Error(),
+		"status":    status,
+		"exitCode":  -1,
+		"cause":     providers.ClassifySendFailure(err.Error(), status),
+		"retryable": a.hasFailedSend(sessionID),
+	}
+	var sendErr *providers.SendError
+	if errors.As(err, &sendErr) {
+		payload["exitCode"] = sendErr.ExitCode
+		payload["stderrTail"] = sendErr.StderrTail()
+		payload["command"] = sendErr.Command
+	}
+	a.rt.Emit("session:send-failed", agentID, sessionID, payload)
+}
+
+func (a *App) recordFailedSend(sessionID string, f failedSend) {
+	a.failedSendsMu.Lock()
+	defer a.failedSendsMu.Unlock()
+	if a.failedSends == nil {
+		a.failedSends = make(map[string]failedSend)
+	}
+	a.failedSends[sessionID] = f
+}
+
+// takeFailedSend removes and returns a session's last failed send.
+func (a *App) takeFailedSend(sessionID string) (failedSend, bool) {
+	a.failedSendsMu.Lock()
+	defer a.failedSendsMu.Unlock()
+	f, ok := a.failedSends[sessionID]
+	delete(a.failedSends, sessionID)
+	return f, ok
+}
+
+func (a *App) hasFailedSend(sessionID string) bool {
+	a.failedSendsMu.Lock()
+	defer a.failedSendsMu.Unlock()
+	_, ok := a.failedSends[sessionID]
+	return ok
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"fmt"
+	goruntime "runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"claudefu/internal/runtime"
+	"claudefu/internal/settings"
 	"claudefu/internal/types"
+	"claudefu/internal/watcher"
 	"claudefu/internal/workspace"
 )
 
@@ -18,28 +24,46 @@ func (a *App) GetSessions(agentID string) ([]types.Session, error) {
 		return nil, fmt.Errorf("runtime not initialized")
 	}
 
-	sessions := a.rt.GetSessionsForAgent(agentID)
+	sessions := a.rt.GetSessionSummaries(agentID)
+	showQueries := a.settings != nil && a.settings.GetSettings().ShowQuerySessions
 
 	result := make([]types.Session, 0, len(sessions))
 	for _, s := range sessions {
 		// Skip subagent sessions (format: agent-{short-id})
 		// These are quick task executions, not main conversations
-		if strings.HasPrefix(s.SessionID, "agent-") {
+		if strings.HasPrefix(s.ID, "agent-") {
 			continue
 		}
-
-		result = append(result, types.Session{
-			ID:           s.SessionID,
-			AgentID:      s.AgentID,
-			Preview:      s.Preview,
-			MessageCount: len(s.Messages),
-			CreatedAt:    s.CreatedAt,
-			UpdatedAt:    s.UpdatedAt,
-		})
+		// AgentQuery/SelfQuery sessions are hidden unless enabled in settings
+		if s.IsQuery && !showQueries {
+			continue
+		}
+		result = append(result, s)
+	}
+	if agent := a.getAgentByID(agentID); agent != nil {
+		a.applySessionLabels(agent.Folder, result)
 	}
 	return result, nil
 }
 
+// GetSessionsWithTags returns an agent's sessions that have every one of tags
+// (case-insensitive). Pinned sessions are included only if they match.
+func (a *App) GetSessionsWithTags(agentID string, tags []string) ([]types.Session, error) {
+	sessions, err := a.GetSessions(agentID)
+	if err != nil {
+		return nil, err
+	}
+	tags = settings.NormalizeSessionTags(tags)
+	return slices.DeleteFunc(sessions, func(s types.Session) bool {
+		for _, tag := range tags {
+			if !slices.ContainsFunc(s.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
 // RefreshSessions re-scans the filesystem for new sessions and returns updated list.
 // This is called by the "Refresh" button in SessionsDialog.
 func (a *App) RefreshSessions(agentID string) ([]types.Session, error) {
@@ -69,13 +93,80 @@ func (a *App) RefreshSessions(agentID string) ([]types.Session, error) {
 	}
 
 	if newCount > 0 {
-		fmt.Printf("[DEBUG] RefreshSessions: discovered %d new sessions for agent=%s\n", newCount, agentID[:8])
+		logger.Debugf("RefreshSessions: discovered %d new sessions for agent=%s", newCount, agentID[:8])
 	}
 
 	// Return updated session list (same as GetSessions)
 	return a.GetSessions(agentID)
 }
 
+// PruneQuerySessions applies the query session retention policy to all agents
+// in the current workspace now. Returns the number of sessions deleted.
+func (a *App) PruneQuerySessions() (int, error) {
+	if a.settings == nil {
+		return 0, fmt.Errorf("settings not initialized")
+	}
+	days := a.settings.GetSettings().QuerySessionRetentionDays
+	if days <= 0 {
+		return 0, fmt.Errorf("query session retention is disabled (set querySessionRetentionDays)")
+	}
+	return a.pruneQuerySessions(days), nil
+}
+
+// ArchiveSession moves a session's files out of ~/.claude/projects into the
+// ClaudeFu session archive (restorable with RestoreArchivedSession).
+func (a *App) ArchiveSession(agentID, sessionID string) error {
+	return a.removeSession(agentID, sessionID, true)
+}
+
+// DeleteSession permanently deletes a session's JSONL file and sidecar directory.
+func (a *App) DeleteSession(agentID, sessionID string) error {
+	return a.removeSession(agentID, sessionID, false)
+}
+
+// ListArchivedSessions returns an agent's archived sessions, most recently archived first.
+func (a *App) ListArchivedSessions(agentID string) ([]workspace.ArchivedSession, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.workspace.ListArchivedSessions(agent.Folder)
+}
+
+// RestoreArchivedSession moves an archived session back and reloads the agent's session list.
+func (a *App) RestoreArchivedSession(agentID, sessionID string) error {
+	if a.workspace == nil {
+		return fmt.Errorf("workspace manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	if err := a.workspace.RestoreArchivedSession(agent.Folder, sessionID); err != nil {
+		return err
+	}
+
+	// Rescan picks up the restored file for every agent on the folder
+	if a.watcher != nil && a.rt != nil && a.currentWorkspace != nil {
+		for _, other := range a.currentWorkspace.Agents {
+			if other.Folder != agent.Folder {
+				continue
+			}
+			var lastViewedMap map[string]int64
+			if a.sessions != nil {
+				lastViewedMap = a.sessions.GetAllLastViewed(other.Folder)
+			}
+			if _, err := a.watcher.RescanSessions(other.ID, other.Folder, lastViewedMap); err != nil {
+				logger.Warnf("RestoreArchivedSession: rescan failed for agent=%s: %v", other.ID[:8], err)
+			}
+		}
+	}
+	return nil
+}
+
 // GetConversation returns messages for a session
 func (a *App) GetConversation(agentID, sessionID string) ([]types.Message, error) {
 	if a.rt == nil {
@@ -125,6 +216,121 @@ func (a *App) GetConversationPaged(agentID, sessionID string, limit, offset int)
 	}, nil
 }
 
+// GetPreCompactionMessages pages through the messages a session had before its
+// last compaction, which initial load leaves on disk. Paging works like
+// GetConversationPaged (offset counts display messages from the end).
+func (a *App) GetPreCompactionMessages(agentID, sessionID string, limit, offset int) (*ConversationResult, error) {
+	if a.rt == nil || a.watcher == nil {
+		return nil, fmt.Errorf("runtime not initialized")
+	}
+
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	history, err := a.watcher.ReadHistory(agent.Folder, sessionID, a.rt.GetHistoryEnd(agentID, sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	displayMessages := []types.Message{}
+	carrierMessages := []types.Message{}
+	for _, msg := range history {
+		if msg.Type == "tool_result_carrier" {
+			carrierMessages = append(carrierMessages, msg)
+		} else {
+			displayMessages = append(displayMessages, msg)
+		}
+	}
+
+	totalCount := len(displayMessages)
+	endIdx := max(totalCount-offset, 0)
+	startIdx := 0
+	if limit > 0 {
+		startIdx = max(endIdx-limit, 0)
+	}
+	messages := append(slices.Clone(displayMessages[startIdx:endIdx]), carrierMessages...)
+
+	return &ConversationResult{
+		SessionID:    sessionID,
+		Messages:     messages,
+		TotalCount:   totalCount,
+		HasMore:      startIdx > 0,
+		DisplayCount: endIdx - startIdx,
+	}, nil
+}
+
+// GetConversationPage reads up to limit display messages preceding beforeUUID
+// straight from the session's JSONL file, for scrolling back past what the
+// runtime buffer holds. An empty beforeUUID starts at the oldest buffered message.
+// Tool result carriers for the returned messages are appended.
+func (a *App) GetConversationPage(agentID, sessionID, beforeUUID string, limit int) (*ConversationResult, error) {
+	if a.rt == nil || a.watcher == nil {
+		return nil, fmt.Errorf("runtime not initialized")
+	}
+
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	var end int64
+	var ok bool
+	if beforeUUID == "" {
+		end, ok = a.rt.GetBufferStart(agentID, sessionID)
+	} else {
+		end, ok = a.messageOffset(agentID, sessionID, beforeUUID)
+	}
+	if !ok && beforeUUID != "" {
+		return nil, fmt.Errorf("message not found: %s", beforeUUID)
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// The offset index says where the page starts; without it read from the top
+	from, indexedOlder, _ := a.watcher.PageStart(sessionID, end, limit)
+	history, err := a.watcher.ReadRange(agent.Folder, sessionID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk back to the limit-th display message; carriers after it stay with the page
+	start := len(history)
+	displayCount := 0
+	for start > 0 && displayCount < limit {
+		start--
+		if history[start].Type != "tool_result_carrier" {
+			displayCount++
+		}
+	}
+	olderCount := indexedOlder
+	for _, msg := range history[:start] {
+		if msg.Type != "tool_result_carrier" {
+			olderCount++
+		}
+	}
+
+	messages := make([]types.Message, 0, len(history)-start)
+	var carriers []types.Message
+	for _, msg := range history[start:] {
+		if msg.Type == "tool_result_carrier" {
+			carriers = append(carriers, msg)
+		} else {
+			messages = append(messages, msg)
+		}
+	}
+
+	return &ConversationResult{
+		SessionID:    sessionID,
+		Messages:     append(messages, carriers...),
+		TotalCount:   olderCount + displayCount, // Display messages before beforeUUID
+		HasMore:      olderCount > 0,
+		DisplayCount: displayCount,
+	}, nil
+}
+
 // GetSubagentConversation returns messages from a subagent JSONL file
 func (a *App) GetSubagentConversation(agentID, sessionID, subagentID string) ([]types.Message, error) {
 	if a.workspace == nil {
@@ -207,7 +413,7 @@ func (a *App) SetActiveSession(agentID, sessionID string) error {
 
 		// Save to local/workspace-state/ (fast, no sync conflict)
 		if err := a.workspace.SaveWorkspaceState(a.currentWorkspace.ID, a.workspaceState); err != nil {
-			fmt.Printf("[WARN] Failed to save workspace state after SetActiveSession: %v\n", err)
+			logger.Warnf("Failed to save workspace state after SetActiveSession: %v", err)
 			// Don't return error - selection still works in memory
 		}
 	}
@@ -228,7 +434,7 @@ func (a *App) ClearActiveSession() {
 
 // MarkSessionViewed marks a session as viewed
 func (a *App) MarkSessionViewed(agentID, sessionID string) error {
-	fmt.Printf("[DEBUG] MarkSessionViewed called: agentID=%s sessionID=%s\n", agentID, sessionID[:8])
+	logger.Debugf("MarkSessionViewed called: agentID=%s sessionID=%s", agentID, sessionID[:8])
 
 	// Get folder from agent
 	agent := a.getAgentByID(agentID)
@@ -249,10 +455,72 @@ func (a *App) MarkSessionViewed(agentID, sessionID string) error {
 		a.rt.EmitUnreadChanged(agentID, sessionID)
 	}
 
-	fmt.Printf("[DEBUG] MarkSessionViewed complete: agentID=%s sessionID=%s\n", agentID, sessionID[:8])
+	logger.Debugf("MarkSessionViewed complete: agentID=%s sessionID=%s", agentID, sessionID[:8])
 	return nil
 }
 
+// =============================================================================
+// RUNTIME MEMORY METHODS (Bound to frontend)
+// =============================================================================
+
+// RuntimeStatsResult is the session buffer usage plus the process heap.
+type RuntimeStatsResult struct {
+	runtime.RuntimeStats
+	HeapAllocBytes uint64 `json:"heapAllocBytes"` // Live Go heap
+	HeapSysBytes   uint64 `json:"heapSysBytes"`   // Heap memory obtained from the OS
+}
+
+// GetRuntimeStats reports per-session message buffer memory for the current
+// workspace, largest first, against the configured memory budget.
+func (a *App) GetRuntimeStats() (RuntimeStatsResult, error) {
+	if a.rt == nil {
+		return RuntimeStatsResult{}, fmt.Errorf("runtime not initialized")
+	}
+	var mem goruntime.MemStats
+	goruntime.ReadMemStats(&mem)
+	return RuntimeStatsResult{
+		RuntimeStats:   a.rt.GetStats(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+	}, nil
+}
+
+// =============================================================================
+// SESSION STATUS METHODS (Bound to frontend)
+// =============================================================================
+
+// GetSessionStatus returns a session's activity status (idle, thinking,
+// responding, tool_running or waiting_on_user). Status changes are also
+// emitted as session:status; Ready is true once a new prompt can be sent.
+func (a *App) GetSessionStatus(agentID, sessionID string) (runtime.SessionStatus, error) {
+	if a.rt == nil {
+		return runtime.SessionStatus{}, fmt.Errorf("runtime not initialized")
+	}
+	status, ok := a.rt.GetSessionStatus(agentID, sessionID)
+	if !ok {
+		return runtime.SessionStatus{}, fmt.Errorf("session %s not loaded for agent %s", sessionID, agentID)
+	}
+	return status, nil
+}
+
+// =============================================================================
+// WATCH STATUS METHODS (Bound to frontend)
+// =============================================================================
+
+// GetAgentWatchStatuses reports, per agent ID, whether its sessions are watched
+// live (fsnotify), polled by configuration, or polled because the OS watch
+// limit was hit ("degraded").
+func (a *App) GetAgentWatchStatuses() map[string]watcher.WatchStatus {
+	statuses := make(map[string]watcher.WatchStatus)
+	if a.watcher == nil || a.currentWorkspace == nil {
+		return statuses
+	}
+	for _, agent := range a.currentWorkspace.Agents {
+		statuses[agent.ID] = a.watcher.FolderWatchStatus(agent.Folder)
+	}
+	return statuses
+}
+
 // =============================================================================
 // UNREAD METHODS (Bound to frontend)
 // =============================================================================
@@ -273,6 +541,57 @@ func (a *App) GetAgentTotalUnread(agentID string) int {
 	return a.rt.GetAgentTotalUnread(agentID)
 }
 
+// RecalculateUnread recomputes unread counts for all loaded sessions of an agent from
+// the persisted lastViewed timestamps (reloaded from disk), e.g. after session-views.json
+// was restored from a sync or backup. Emits unread:changed per session and returns the new counts.
+func (a *App) RecalculateUnread(agentID string) (map[string]int, error) {
+	if a.rt == nil {
+		return nil, fmt.Errorf("runtime not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	var lastViewedMap map[string]int64
+	if a.sessions != nil {
+		if err := a.sessions.ReloadViews(); err != nil {
+			return nil, fmt.Errorf("failed to reload session views: %w", err)
+		}
+		lastViewedMap = a.sessions.GetAllLastViewed(agent.Folder)
+	}
+
+	for _, session := range a.rt.GetSessionsForAgent(agentID) {
+		a.rt.InitializeSessionViewed(agentID, session.SessionID, lastViewedMap[session.SessionID])
+		a.rt.EmitUnreadChanged(agentID, session.SessionID)
+	}
+
+	return a.rt.GetAllUnreadCounts(agentID), nil
+}
+
+// =============================================================================
+// EVENT SUBSCRIPTION METHODS (Bound to frontend)
+// =============================================================================
+
+// Subscribe tells the backend the frontend is rendering an agent (empty sessionID)
+// or a single session. Once anything is subscribed, session:messages and unread:changed
+// are only emitted for subscribed scopes. Subscriptions reset on workspace switch.
+func (a *App) Subscribe(agentID, sessionID string) {
+	if a.rt == nil {
+		return
+	}
+	a.rt.Subscribe(agentID, sessionID)
+}
+
+// Unsubscribe stops event emission for a scope. State keeps updating in the backend,
+// so the frontend should refetch (GetConversation/GetUnreadCounts) when resubscribing.
+func (a *App) Unsubscribe(agentID, sessionID string) {
+	if a.rt == nil {
+		return
+	}
+	a.rt.Unsubscribe(agentID, sessionID)
+}
+
 // =============================================================================
 // SESSION NAMING METHODS (Bound to frontend)
 // =============================================================================
@@ -298,7 +617,11 @@ func (a *App) SetSessionName(agentID, sessionID, name string) error {
 	if agent == nil {
 		return fmt.Errorf("agent not found: %s", agentID)
 	}
-	return a.sessions.SetSessionName(agent.Folder, sessionID, name)
+	if err := a.sessions.SetSessionName(agent.Folder, sessionID, name); err != nil {
+		return err
+	}
+	a.emitSessionRenamed(agentID, sessionID, name, false)
+	return nil
 }
 
 // DeleteFromMessage truncates a session from the specified message UUID downward.
@@ -318,13 +641,54 @@ func (a *App) DeleteFromMessage(agentID, sessionID, messageUUID string) (int, er
 	// Reload session to refresh frontend state
 	if a.watcher != nil {
 		if reloadErr := a.watcher.ReloadSession(agentID, agent.Folder, sessionID); reloadErr != nil {
-			fmt.Printf("[WARN] DeleteFromMessage: reload failed: %v\n", reloadErr)
+			logger.Warnf("DeleteFromMessage: reload failed: %v", reloadErr)
 		}
 	}
 
 	return removed, nil
 }
 
+// RewindResult reports what RewindSession removed.
+type RewindResult struct {
+	Removed    int    `json:"removed"`              // JSONL lines removed
+	BackupPath string `json:"backupPath,omitempty"` // Copy of the session before rewinding
+}
+
+// RewindSession truncates a session after the specified message UUID (keeping
+// it) so a corrected prompt can be sent from that point. The original JSONL is
+// backed up first. Fails while a message to the session is running or queued.
+func (a *App) RewindSession(agentID, sessionID, messageUUID string) (RewindResult, error) {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return RewindResult{}, fmt.Errorf("agent not found: %s", agentID)
+	}
+	if a.claude != nil && a.claude.SessionBusy(sessionID) {
+		return RewindResult{}, fmt.Errorf("session is busy; cancel the running message before rewinding")
+	}
+
+	// With a known offset the file is truncated in place instead of rewritten
+	var removed int
+	var backupPath string
+	var err error
+	if offset, ok := a.messageOffset(agentID, sessionID, messageUUID); ok {
+		removed, backupPath, err = workspace.RewindSessionAt(agent.Folder, sessionID, messageUUID, offset)
+	} else {
+		removed, backupPath, err = workspace.RewindSession(agent.Folder, sessionID, messageUUID)
+	}
+	if err != nil {
+		return RewindResult{}, err
+	}
+
+	// Reload session to refresh frontend state
+	if a.watcher != nil && removed > 0 {
+		if reloadErr := a.watcher.ReloadSession(agentID, agent.Folder, sessionID); reloadErr != nil {
+			logger.Warnf("RewindSession: reload failed: %v", reloadErr)
+		}
+	}
+
+	return RewindResult{Removed: removed, BackupPath: backupPath}, nil
+}
+
 // DuplicateSession copies a session JSONL to a new file with " copy" appended to the name.
 // Returns the new session ID.
 func (a *App) DuplicateSession(agentID, sessionID string) (string, error) {
@@ -342,12 +706,15 @@ func (a *App) DuplicateSession(agentID, sessionID string) (string, error) {
 		return "", err
 	}
 
-	// Copy session name with " copy" suffix
+	// Copy session name with " copy" suffix, and its tags (not the pin)
 	if a.sessions != nil {
 		sourceName := a.sessions.GetSessionName(agent.Folder, sessionID)
 		if sourceName != "" {
 			_ = a.sessions.SetSessionName(agent.Folder, newID, sourceName+" copy")
 		}
+		if tags := a.sessions.GetSessionLabel(agent.Folder, sessionID).Tags; len(tags) > 0 {
+			_, _ = a.sessions.SetSessionTags(agent.Folder, newID, tags)
+		}
 	}
 
 	return newID, nil
@@ -364,3 +731,250 @@ func (a *App) GetAllSessionNames(agentID string) map[string]string {
 	}
 	return a.sessions.GetAllSessionNames(agent.Folder)
 }
+
+// SetSessionTags replaces a session's tags (e.g. "release-planning", "bug-hunt").
+// Tags are trimmed and de-duplicated; the stored tags are returned.
+func (a *App) SetSessionTags(agentID, sessionID string, tags []string) ([]string, error) {
+	if a.sessions == nil {
+		return nil, fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	stored, err := a.sessions.SetSessionTags(agent.Folder, sessionID, tags)
+	if stored == nil {
+		stored = []string{}
+	}
+	return stored, err
+}
+
+// PinSession pins (or unpins) a session to the top of the agent's session list
+func (a *App) PinSession(agentID, sessionID string, pinned bool) error {
+	if a.sessions == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.sessions.SetSessionPinned(agent.Folder, sessionID, pinned)
+}
+
+// GetSessionTags returns every tag used by an agent's sessions, for filtering
+func (a *App) GetSessionTags(agentID string) []string {
+	if a.sessions == nil {
+		return []string{}
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return []string{}
+	}
+	if tags := a.sessions.GetSessionTagNames(agent.Folder); tags != nil {
+		return tags
+	}
+	return []string{}
+}
+
+// applySessionLabels fills in the tags and pinned flag of a folder's sessions
+func (a *App) applySessionLabels(folder string, sessions []types.Session) {
+	if a.sessions == nil {
+		return
+	}
+	labels := a.sessions.GetAllSessionLabels(folder)
+	for i := range sessions {
+		if label, ok := labels[sessions[i].ID]; ok {
+			sessions[i].Tags = label.Tags
+			sessions[i].Pinned = label.Pinned
+		}
+	}
+}
+
+// =============================================================================
+// PROMPT HISTORY METHODS (Bound to frontend)
+// =============================================================================
+
+// GetPromptHistory returns prompts previously sent in a session, newest first, for
+// up-arrow recall in the composer. limit <= 0 returns the full history.
+func (a *App) GetPromptHistory(agentID, sessionID string, limit int) []settings.PromptHistoryEntry {
+	return a.SearchSessionPromptHistory(agentID, sessionID, "", limit)
+}
+
+// SearchSessionPromptHistory filters a session's prompt history by a case-insensitive substring.
+func (a *App) SearchSessionPromptHistory(agentID, sessionID, query string, limit int) []settings.PromptHistoryEntry {
+	if a.sessions == nil {
+		return []settings.PromptHistoryEntry{}
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return []settings.PromptHistoryEntry{}
+	}
+	return a.sessions.GetPromptHistory(agent.Folder, sessionID, query, limit)
+}
+
+// SearchPromptHistory searches prompts across all of an agent's sessions, newest first.
+// Each result carries the sessionId it was sent in.
+func (a *App) SearchPromptHistory(agentID, query string, limit int) []settings.PromptHistoryEntry {
+	if a.sessions == nil {
+		return []settings.PromptHistoryEntry{}
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return []settings.PromptHistoryEntry{}
+	}
+	return a.sessions.SearchPromptHistory(agent.Folder, query, limit)
+}
+
+// ClearPromptHistory forgets a session's sent prompts.
+func (a *App) ClearPromptHistory(agentID, sessionID string) error {
+	if a.sessions == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	return a.sessions.ClearPromptHistory(agent.Folder, sessionID)
+}
+
+// applyQuerySessionRetention prunes query sessions if a retention period is configured.
+func (a *App) applyQuerySessionRetention() {
+	if a.settings == nil {
+		return
+	}
+	if days := a.settings.GetSettings().QuerySessionRetentionDays; days > 0 {
+		a.pruneQuerySessions(days)
+	}
+}
+
+// pruneQuerySessions deletes AgentQuery/SelfQuery sessions idle for more than
+// days in every workspace agent folder and drops them from the runtime.
+func (a *App) pruneQuerySessions(days int) int {
+	if a.currentWorkspace == nil {
+		return 0
+	}
+	maxAge := time.Duration(days) * 24 * time.Hour
+	seen := make(map[string]bool)
+	total := 0
+	for _, agent := range a.currentWorkspace.Agents {
+		if seen[agent.Folder] {
+			continue
+		}
+		seen[agent.Folder] = true
+
+		deleted, err := workspace.PruneQuerySessions(agent.Folder, maxAge)
+		if err != nil {
+			logger.Warnf("Failed to prune query sessions in %s: %v", agent.Folder, err)
+			continue
+		}
+		for _, sessionID := range deleted {
+			if a.rt != nil {
+				for _, other := range a.currentWorkspace.Agents {
+					if other.Folder == agent.Folder {
+						a.rt.RemoveSession(other.ID, sessionID)
+					}
+				}
+			}
+			if a.search != nil {
+				a.search.RemoveSession(agent.Folder, sessionID)
+			}
+			if a.usage != nil {
+				a.usage.RemoveSession(agent.Folder, sessionID)
+			}
+		}
+		total += len(deleted)
+	}
+	if total > 0 {
+		logger.Infof("Pruned %d query sessions older than %d days", total, days)
+	}
+	return total
+}
+
+// removeSession archives or deletes a session, then drops it from runtime state,
+// derived indexes, and any agent that had it selected, emitting session:removed.
+func (a *App) removeSession(agentID, sessionID string, archive bool) error {
+	if a.workspace == nil {
+		return fmt.Errorf("workspace manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	folder := agent.Folder
+	if a.claude != nil && slices.Contains(a.claude.ActiveSessionsInFolder(folder), sessionID) {
+		return fmt.Errorf("session %s is running - cancel it first", sessionID)
+	}
+
+	var err error
+	if archive {
+		err = a.workspace.ArchiveSession(folder, sessionID)
+	} else {
+		err = a.workspace.DeleteSession(folder, sessionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.search != nil {
+		a.search.RemoveSession(folder, sessionID)
+	}
+	if a.usage != nil {
+		a.usage.RemoveSession(folder, sessionID)
+	}
+
+	stateChanged := false
+	for i := range a.currentWorkspace.Agents {
+		other := &a.currentWorkspace.Agents[i]
+		if other.Folder != folder {
+			continue
+		}
+		if other.SelectedSessionID == sessionID {
+			other.SelectedSessionID = ""
+			if a.watcher != nil {
+				a.watcher.ClearActiveSessionWatch(other.ID)
+			}
+			if a.workspaceState != nil {
+				delete(a.workspaceState.AgentSessions, other.ID)
+				stateChanged = true
+			}
+		}
+		if a.rt != nil {
+			if activeAgent, activeSession := a.rt.GetActiveSession(); activeAgent == other.ID && activeSession == sessionID {
+				a.rt.ClearActiveSession()
+			}
+			a.rt.RemoveSession(other.ID, sessionID)
+			a.rt.Emit("session:removed", other.ID, sessionID, map[string]any{
+				"sessionId": sessionID,
+				"archived":  archive,
+			})
+		}
+	}
+
+	if sel := a.currentWorkspace.SelectedSession; sel != nil && sel.Folder == folder && sel.SessionID == sessionID {
+		a.currentWorkspace.SelectedSession = nil
+		if a.workspaceState != nil {
+			a.workspaceState.SelectedSession = nil
+			stateChanged = true
+		}
+	}
+	if stateChanged {
+		if err := a.workspace.SaveWorkspaceState(a.currentWorkspace.ID, a.workspaceState); err != nil {
+			logger.Warnf("Failed to save workspace state after removing session: %v", err)
+		}
+	}
+	return nil
+}
+
+// messageOffset returns the byte offset of a message's JSONL line, from the
+// runtime if the session is loaded, else from the watcher's offset index.
+func (a *App) messageOffset(agentID, sessionID, uuid string) (int64, bool) {
+	if a.rt != nil {
+		if offset, ok := a.rt.GetMessageOffset(agentID, sessionID, uuid); ok {
+			return offset, true
+		}
+	}
+	if a.watcher != nil {
+		return a.watcher.MessageOffset(sessionID, uuid)
+	}
+	return 0, false
+}
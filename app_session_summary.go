@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"claudefu/internal/providers"
+	"claudefu/internal/summary"
+)
+
+// defaultSummaryModel is the model used for summaries when Settings.SummaryModel is empty
+const defaultSummaryModel = "haiku"
+
+// =============================================================================
+// SESSION SUMMARY METHODS (Bound to frontend)
+// =============================================================================
+
+// SummarizeSession returns a structured summary of a session (overview,
+// decisions, open questions, files touched). The summary is cached until the
+// session gets new messages, then rolled forward from the new ones. Emits
+// session:summarized when a summary is generated.
+func (a *App) SummarizeSession(agentID, sessionID string) (*summary.Summary, error) {
+	if a.summaries == nil {
+		return nil, fmt.Errorf("summary service not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	cached, fresh := a.summaries.Cached(agent.Folder, sessionID)
+	if fresh {
+		return cached, nil
+	}
+	sum, err := a.summaries.Summarize(context.Background(), agent.Folder, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	a.emitSessionSummarized(agentID, sum)
+	return sum, nil
+}
+
+// CachedSessionSummary is a session's cached summary and whether the session
+// has new messages since it was generated.
+type CachedSessionSummary struct {
+	Summary *summary.Summary `json:"summary"`
+	Stale   bool             `json:"stale"`
+}
+
+// GetCachedSessionSummary returns a session's cached summary without
+// generating one (Summary is nil if the session was never summarized).
+func (a *App) GetCachedSessionSummary(agentID, sessionID string) CachedSessionSummary {
+	if a.summaries == nil {
+		return CachedSessionSummary{}
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return CachedSessionSummary{}
+	}
+	cached, fresh := a.summaries.Cached(agent.Folder, sessionID)
+	return CachedSessionSummary{Summary: cached, Stale: cached != nil && !fresh}
+}
+
+// =============================================================================
+// SESSION SUMMARY HELPERS
+// =============================================================================
+
+// runSummaryPrompt runs a summary prompt with the configured summary model,
+// without persisting the one-shot session.
+func (a *App) runSummaryPrompt(ctx context.Context, folder, prompt string) (string, error) {
+	if a.claude == nil {
+		return "", fmt.Errorf("claude service not initialized")
+	}
+	model := defaultSummaryModel
+	if a.settings != nil {
+		if m := a.settings.GetSettings().SummaryModel; m != "" {
+			model = m
+		}
+	}
+	return a.claude.RunPrintWith(folder, prompt, providers.PrintOptions{Model: model, Ephemeral: true, Nested: providers.IsNestedSpawn(ctx)})
+}
+
+// emitSessionSummarized emits session:summarized with the new summary.
+func (a *App) emitSessionSummarized(agentID string, sum *summary.Summary) {
+	if a.rt == nil {
+		return
+	}
+	a.rt.Emit("session:summarized", agentID, sum.SessionID, map[string]any{
+		"summary": sum,
+	})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"claudefu/internal/providers"
+	"claudefu/internal/workspace"
+)
+
+// defaultAutoTitleModel is the model used for titles when Settings.AutoTitleModel is empty
+const defaultAutoTitleModel = "haiku"
+
+// maxSessionTitleRunes caps generated titles
+const maxSessionTitleRunes = 60
+
+// sessionTitlePrompt asks for a short title given the session's first exchange.
+const sessionTitlePrompt = `Write a title of 3 to 7 words for the conversation below, ` +
+	`naming its task or topic (e.g. "Fix OAuth token refresh race"). ` +
+	`Output ONLY the title: no quotes, no trailing period, no preamble.
+
+<user>
+%s
+</user>
+
+<assistant>
+%s
+</assistant>`
+
+// =============================================================================
+// SESSION TITLE METHODS (Bound to frontend)
+// =============================================================================
+
+// GenerateSessionTitle names a session from its first exchange with a one-shot
+// claude --print call, replacing any existing name. Emits session:renamed.
+func (a *App) GenerateSessionTitle(agentID, sessionID string) (string, error) {
+	if a.sessions == nil {
+		return "", fmt.Errorf("session manager not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	ex, err := workspace.ReadFirstExchange(agent.Folder, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session: %w", err)
+	}
+	if ex.UserPrompt == "" {
+		return "", fmt.Errorf("session has no prompt to title")
+	}
+	title, err := a.generateSessionTitle(agent.Folder, ex)
+	if err != nil {
+		return "", err
+	}
+	if err := a.sessions.SetSessionName(agent.Folder, sessionID, title); err != nil {
+		return "", err
+	}
+	a.emitSessionRenamed(agentID, sessionID, title, true)
+	return title, nil
+}
+
+// =============================================================================
+// SESSION TITLE HELPERS
+// =============================================================================
+
+// autoTitleSession names an untitled session once its first turn has a reply,
+// when Settings.AutoTitleSessions is on. Runs after each successful send;
+// sessions that are named or past their first turn are left alone.
+func (a *App) autoTitleSession(agentID, folder, sessionID string) {
+	if a.settings == nil || a.sessions == nil || a.claude == nil || !a.settings.GetSettings().AutoTitleSessions {
+		return
+	}
+	if a.sessions.GetSessionName(folder, sessionID) != "" {
+		return
+	}
+
+	key := folder + "/" + sessionID
+	a.autoTitlingMu.Lock()
+	if a.autoTitling[key] {
+		a.autoTitlingMu.Unlock()
+		return
+	}
+	if a.autoTitling == nil {
+		a.autoTitling = make(map[string]bool)
+	}
+	a.autoTitling[key] = true
+	a.autoTitlingMu.Unlock()
+	defer func() {
+		a.autoTitlingMu.Lock()
+		delete(a.autoTitling, key)
+		a.autoTitlingMu.Unlock()
+	}()
+
+	ex, err := workspace.ReadFirstExchange(folder, sessionID)
+	if err != nil || ex.UserTurns != 1 || ex.UserPrompt == "" || ex.AssistantText == "" {
+		return
+	}
+	title, err := a.generateSessionTitle(folder, ex)
+	if err != nil {
+		logger.Warnf("Auto-title for session %s failed: %v", sessionID[:8], err)
+		return
+	}
+
+	// The user may have named the session while the title was generated
+	if a.sessions.GetSessionName(folder, sessionID) != "" {
+		return
+	}
+	if err := a.sessions.SetSessionName(folder, sessionID, title); err != nil {
+		logger.Warnf("Auto-title for session %s: %v", sessionID[:8], err)
+		return
+	}
+	a.emitSessionRenamed(agentID, sessionID, title, true)
+}
+
+// generateSessionTitle asks the configured title model for a title of ex.
+func (a *App) generateSessionTitle(folder string, ex workspace.SessionExchange) (string, error) {
+	if a.claude == nil {
+		return "", fmt.Errorf("claude service not initialized")
+	}
+	model := defaultAutoTitleModel
+	if a.settings != nil {
+		if m := a.settings.GetSettings().AutoTitleModel; m != "" {
+			model = m
+		}
+	}
+
+	prompt := fmt.Sprintf(sessionTitlePrompt, ex.UserPrompt, ex.AssistantText)
+	output, err := a.claude.RunPrintWith(folder, prompt, providers.PrintOptions{Model: model, Ephemeral: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate title: %w", err)
+	}
+	title := cleanSessionTitle(output)
+	if title == "" {
+		return "", fmt.Errorf("claude returned an empty title")
+	}
+	return title, nil
+}
+
+// cleanSessionTitle keeps the first line of CLI output, strips quotes and a
+// trailing period, and caps the length.
+func cleanSessionTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "Title:")
+	s = strings.Trim(strings.TrimSpace(s), "\"'`*#")
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+	if runes := []rune(s); len(runes) > maxSessionTitleRunes {
+		s = strings.TrimSpace(string(runes[:maxSessionTitleRunes])) + "…"
+	}
+	return s
+}
+
+// emitSessionRenamed emits session:renamed (auto = generated, not typed by the user).
+func (a *App) emitSessionRenamed(agentID, sessionID, name string, auto bool) {
+	if a.rt == nil {
+		return
+	}
+	a.rt.Emit("session:renamed", agentID, sessionID, map[string]any{
+		"name": name,
+		"auto": auto,
+	})
+}
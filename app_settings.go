@@ -5,10 +5,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+
+	"claudefu/internal/logging"
+	"claudefu/internal/metrics"
 	"claudefu/internal/permissions"
 	"claudefu/internal/providers"
 	"claudefu/internal/proxy"
+	"claudefu/internal/runtime"
 	"claudefu/internal/settings"
 )
 
@@ -31,6 +37,15 @@ func (a *App) SaveSettings(s settings.Settings) error {
 		return fmt.Errorf("settings manager not initialized")
 	}
 
+	for i := range s.Hooks {
+		if err := s.Hooks[i].Validate(); err != nil {
+			return err
+		}
+		if s.Hooks[i].ID == "" {
+			s.Hooks[i].ID = uuid.New().String()
+		}
+	}
+
 	// Save to disk
 	if err := a.settings.SaveSettings(s); err != nil {
 		return err
@@ -38,11 +53,38 @@ func (a *App) SaveSettings(s settings.Settings) error {
 
 	// Apply runtime changes: update Claude CLI environment variables and command
 	providers.SetClaudeCommand(s.ClaudeCodeCommand)
+	providers.SetDefaultCLIArgs(s.ClaudeExtraArgs)
+	providers.SetDangerousAcknowledgements(s.AcknowledgedDangerousPermissions)
+	providers.Spawns().SetMaxConcurrent(s.MaxConcurrentSpawns)
 
 	// Apply proxy changes (reads machine-specific settings)
 	mps := a.settings.GetMachineProxySettings()
 	a.applyMachineProxySettings(mps)
 
+	// Start, restart, or stop the metrics endpoint
+	a.applyMetricsSettings(s)
+
+	// Start, restart, or stop automatic config backups
+	a.applyBackupSettings(s)
+
+	// Start, restart, or stop checking other workspaces for unread activity
+	a.applyWorkspaceScanSettings(s)
+
+	if a.watcher != nil {
+		a.watcher.SetPollInterval(time.Duration(s.WatchPollIntervalMs) * time.Millisecond)
+		if err := a.watcher.SetPlanWatching(s.WatchPlanFiles); err != nil {
+			logger.Warnf("%v", err)
+		}
+		a.watcher.SetLoadFullHistory(s.LoadFullHistory)
+	}
+
+	a.applyBufferSettings(s)
+	a.applyLogSettings(s)
+	a.applyBrowserBridgeSettings(s)
+	if a.hooks != nil {
+		a.hooks.SetHooks(s.Hooks)
+	}
+
 	return nil
 }
 
@@ -110,12 +152,12 @@ func (a *App) applyMachineProxySettings(mps settings.MachineProxySettings) {
 
 		if a.proxy != nil && a.proxy.IsRunning() {
 			if err := a.proxy.Restart(config); err != nil {
-				fmt.Printf("[proxy] Failed to restart proxy: %v\n", err)
+				logger.Warnf("[proxy] Failed to restart proxy: %v", err)
 			}
 		} else {
 			a.proxy = proxy.NewService(config)
 			if err := a.proxy.Start(); err != nil {
-				fmt.Printf("[proxy] Failed to start proxy: %v\n", err)
+				logger.Warnf("[proxy] Failed to start proxy: %v", err)
 			}
 		}
 
@@ -142,6 +184,60 @@ func (a *App) applyMachineProxySettings(mps settings.MachineProxySettings) {
 	}
 }
 
+// applyBufferSettings sets the runtime's default buffer limits and memory budget.
+func (a *App) applyBufferSettings(s settings.Settings) {
+	if a.rt == nil {
+		return
+	}
+	a.rt.SetBufferDefaults(runtime.BufferLimits{MaxMessages: s.BufferMaxMessages, MaxBytes: s.BufferMaxBytes})
+	a.rt.SetMemoryBudget(int64(s.MemoryBudgetMB) * 1024 * 1024)
+}
+
+// applyLogSettings sets log levels and file output.
+func (a *App) applyLogSettings(s settings.Settings) {
+	cfg := logging.Config{Level: s.LogLevel, Subsystems: s.LogSubsystems}
+	if s.LogToFile {
+		// Under local/ so rotating log files are neither synced nor backed up
+		cfg.FileDir = filepath.Join(a.settings.GetConfigPath(), "local", "logs")
+	}
+	if err := logging.Configure(cfg); err != nil {
+		logger.Warnf("Invalid log settings: %v", err)
+	}
+}
+
+// applyMetricsSettings manages the /metrics endpoint lifecycle based on settings.
+func (a *App) applyMetricsSettings(s settings.Settings) {
+	if !s.MetricsEnabled {
+		if a.metrics != nil && a.metrics.IsRunning() {
+			a.metrics.Stop()
+		}
+		return
+	}
+
+	host, port := s.MetricsHost, s.MetricsPort
+	if host == "" {
+		host = metrics.DefaultHost
+	}
+	if port == 0 {
+		port = metrics.DefaultPort
+	}
+
+	if a.metrics != nil && a.metrics.IsRunning() {
+		if a.metrics.GetHost() == host && a.metrics.GetPort() == port {
+			return
+		}
+		if err := a.metrics.Restart(host, port); err != nil {
+			logger.Warnf("[metrics] Failed to restart metrics endpoint: %v", err)
+		}
+		return
+	}
+
+	a.metrics = metrics.NewService(host, port)
+	if err := a.metrics.Start(); err != nil {
+		logger.Warnf("[metrics] Failed to start metrics endpoint: %v", err)
+	}
+}
+
 // GetConfigPath returns the path to the config directory (~/.claudefu)
 func (a *App) GetConfigPath() string {
 	if a.settings == nil {
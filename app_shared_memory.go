@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// SHARED MEMORY METHODS (Bound to frontend)
+// =============================================================================
+
+// GetSharedMemory lists the current workspace's shared memory entries (the
+// MemorySet/MemoryGet/MemoryList store). An empty namespace lists all of them.
+func (a *App) GetSharedMemory(namespace, prefix string) ([]mcpserver.MemoryEntry, error) {
+	store, wsID, err := a.sharedMemory()
+	if err != nil {
+		return nil, err
+	}
+	return store.List(wsID, namespace, prefix)
+}
+
+// GetSharedMemoryNamespaces returns the namespaces in the current workspace's shared memory
+func (a *App) GetSharedMemoryNamespaces() ([]string, error) {
+	store, wsID, err := a.sharedMemory()
+	if err != nil {
+		return nil, err
+	}
+	return store.Namespaces(wsID)
+}
+
+// SetSharedMemory creates or replaces a shared memory entry as the user.
+// ttlSeconds of 0 keeps the entry until deleted.
+func (a *App) SetSharedMemory(namespace, key, value string, ttlSeconds int) (mcpserver.MemoryEntry, error) {
+	store, wsID, err := a.sharedMemory()
+	if err != nil {
+		return mcpserver.MemoryEntry{}, err
+	}
+	entry, err := store.Set(wsID, namespace, key, value, "user", time.Duration(ttlSeconds)*time.Second)
+	if err != nil {
+		return entry, err
+	}
+	a.emitMemoryChanged(entry.Namespace, key, "set")
+	return entry, nil
+}
+
+// DeleteSharedMemory removes a shared memory entry
+func (a *App) DeleteSharedMemory(namespace, key string) (bool, error) {
+	store, wsID, err := a.sharedMemory()
+	if err != nil {
+		return false, err
+	}
+	deleted, err := store.Delete(wsID, namespace, key)
+	if deleted {
+		a.emitMemoryChanged(namespace, key, "deleted")
+	}
+	return deleted, err
+}
+
+// =============================================================================
+// SHARED MEMORY HELPERS
+// =============================================================================
+
+// sharedMemory returns the shared memory store and the current workspace ID
+func (a *App) sharedMemory() (*mcpserver.MemoryStore, string, error) {
+	if a.mcpServer == nil || a.mcpServer.GetMemory() == nil {
+		return nil, "", fmt.Errorf("shared memory not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, "", fmt.Errorf("no workspace loaded")
+	}
+	return a.mcpServer.GetMemory(), a.currentWorkspace.ID, nil
+}
+
+// emitMemoryChanged emits memory:changed after a UI edit
+func (a *App) emitMemoryChanged(namespace, key, action string) {
+	a.emitAppEvent(types.EventEnvelope{
+		WorkspaceID: a.currentWorkspace.ID,
+		EventType:   "memory:changed",
+		Payload: map[string]any{
+			"namespace": namespace,
+			"key":       key,
+			"action":    action,
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// STREAM WATCH MODE
+// Agents with WatchMode "stream" get their sends' stream-json stdout parsed
+// as it arrives, so assistant output reaches the runtime without waiting for
+// the JSONL write and fsnotify round trip. The file watcher keeps running as
+// reconciliation: stream and JSONL events share UUIDs, so its later read of
+// the same events is deduplicated, and anything the stream missed (subagent
+// output, events after a crash) still arrives from the file.
+// =============================================================================
+
+// onStreamLine feeds one stdout line of a send into the runtime if the
+// sending agent uses stream watch mode.
+func (a *App) onStreamLine(folder, sessionID, line string) {
+	rt := a.rt
+	if rt == nil {
+		return
+	}
+	agentID, ok := rt.GetAgentIDByFolder(folder)
+	if !ok {
+		return
+	}
+	if agent := a.getAgentByID(agentID); agent == nil || agent.GetWatchMode() != types.WatchModeStream {
+		return
+	}
+
+	msg := types.ConvertStreamingLine(line, time.Now().UTC().Format(time.RFC3339Nano))
+	if msg == nil {
+		return
+	}
+	added := rt.AppendMessages(agentID, sessionID, []types.Message{*msg})
+	if len(added) == 0 {
+		return
+	}
+	rt.EmitUnreadChanged(agentID, sessionID)
+	rt.EmitSessionMessages(agentID, sessionID, added)
+}
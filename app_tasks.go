@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/tasks"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// TASK GRAPH METHODS (Bound to frontend)
+// =============================================================================
+
+// GetTaskGraph returns the current workspace's task DAG with derived per-task state.
+func (a *App) GetTaskGraph() (*tasks.Graph, error) {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	return a.tasks.Graph(wsID)
+}
+
+// CreateTask adds a task to the current workspace's graph.
+func (a *App) CreateTask(input tasks.CreateInput) (*tasks.Task, error) {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	if input.AssignedAgentID != "" && a.getAgentByID(input.AssignedAgentID) == nil {
+		return nil, fmt.Errorf("agent not found: %s", input.AssignedAgentID)
+	}
+	if input.CreatedBy == "" {
+		input.CreatedBy = "user"
+	}
+	task, err := a.tasks.Create(wsID, input)
+	if err != nil {
+		return nil, err
+	}
+	a.emitTasksChanged(*task, "created")
+	return task, nil
+}
+
+// AssignTask assigns a task to an agent, optionally pinning the session the work happens in.
+func (a *App) AssignTask(taskID, agentID, sessionID string) (*tasks.Task, error) {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	if a.getAgentByID(agentID) == nil {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	task, err := a.tasks.Assign(wsID, taskID, agentID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	a.emitTasksChanged(*task, "assigned")
+	return task, nil
+}
+
+// CompleteTask marks a task done with an optional result summary.
+func (a *App) CompleteTask(taskID, result string) (*tasks.Task, error) {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	task, err := a.tasks.Complete(wsID, taskID, result)
+	if err != nil {
+		return nil, err
+	}
+	a.emitTasksChanged(*task, "completed")
+	return task, nil
+}
+
+// SetTaskStatus sets a task's status (pending, in_progress, done, cancelled).
+func (a *App) SetTaskStatus(taskID, status string) (*tasks.Task, error) {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	task, err := a.tasks.SetStatus(wsID, taskID, status)
+	if err != nil {
+		return nil, err
+	}
+	a.emitTasksChanged(*task, "updated")
+	return task, nil
+}
+
+// DeleteTask removes a task that no other task depends on.
+func (a *App) DeleteTask(taskID string) error {
+	wsID, err := a.taskWorkspaceID()
+	if err != nil {
+		return err
+	}
+	task, err := a.tasks.Get(wsID, taskID)
+	if err != nil {
+		return err
+	}
+	if err := a.tasks.Delete(wsID, taskID); err != nil {
+		return err
+	}
+	a.emitTasksChanged(*task, "deleted")
+	return nil
+}
+
+// taskWorkspaceID returns the current workspace ID for task operations.
+func (a *App) taskWorkspaceID() (string, error) {
+	if a.tasks == nil {
+		return "", fmt.Errorf("task manager not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return "", fmt.Errorf("no workspace loaded")
+	}
+	return a.currentWorkspace.ID, nil
+}
+
+// taskSessionActivity reports whether a task's session is streaming, for tasks.Graph.
+func (a *App) taskSessionActivity(agentID, sessionID string) string {
+	if a.rt == nil {
+		return ""
+	}
+	session := a.rt.GetSessionSummary(agentID, sessionID)
+	if session == nil {
+		return ""
+	}
+	if session.IsStreaming {
+		return "streaming"
+	}
+	return "idle"
+}
+
+// emitTasksChanged emits a tasks:changed event (same shape as the MCP Task* tools emit).
+func (a *App) emitTasksChanged(task tasks.Task, action string) {
+	if a.ctx == nil {
+		return
+	}
+	envelope := types.EventEnvelope{
+		AgentID:   task.AssignedAgentID,
+		EventType: "tasks:changed",
+		Payload: map[string]any{
+			"action": action,
+			"task":   task,
+		},
+	}
+	if a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	a.emitAppEvent(envelope)
+}
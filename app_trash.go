@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// TRASH METHODS (Bound to frontend)
+// =============================================================================
+
+// GetTrash returns all trashed agents and workspaces, most recent first.
+func (a *App) GetTrash() ([]workspace.TrashEntry, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.ListTrash(), nil
+}
+
+// RestoreAgent puts a trashed agent back into its workspace at its original position.
+// If the agent belonged to the current workspace, watching resumes immediately.
+func (a *App) RestoreAgent(entryID string) (*workspace.Agent, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+
+	entry, err := a.workspace.GetTrashEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Kind != workspace.TrashKindAgent || entry.Agent == nil {
+		return nil, fmt.Errorf("trash entry %s is not an agent", entryID)
+	}
+	agent := *entry.Agent
+
+	isCurrent := a.currentWorkspace != nil && a.currentWorkspace.ID == entry.WorkspaceID
+	ws := a.currentWorkspace
+	if !isCurrent {
+		ws, err = a.workspace.LoadWorkspace(entry.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot restore agent, workspace unavailable: %w", err)
+		}
+	}
+
+	if workspace.HasAgentWithFolder(ws, agent.Folder) {
+		return nil, fmt.Errorf("folder already exists in this workspace: %s", agent.Folder)
+	}
+
+	// Re-insert at the original index (clamped — other agents may have been removed since)
+	index := min(max(entry.AgentIndex, 0), len(ws.Agents))
+	ws.Agents = append(ws.Agents[:index], append([]workspace.Agent{agent}, ws.Agents[index:]...)...)
+
+	if err := a.workspace.SaveWorkspace(ws); err != nil {
+		return nil, fmt.Errorf("failed to save workspace after restoring agent %s: %w", agent.ID, err)
+	}
+	a.workspace.RemoveTrashEntry(entryID)
+
+	if isCurrent {
+		if a.rt != nil {
+			a.rt.AddAgent(agent)
+		}
+
+		// Restore watcher state (same as AddAgent)
+		if a.watcher != nil && a.rt != nil {
+			a.startWatchingAgent(&agent)
+		}
+
+		if a.rt != nil {
+			a.rt.Emit("agent:added", agent.ID, "", map[string]any{
+				"agent": agent,
+			})
+		}
+
+		a.RefreshSifuPermissions()
+		a.RefreshWhosWho()
+	}
+
+	a.emitTrashEvent("trash:restored", entry)
+	return &agent, nil
+}
+
+// RestoreWorkspace brings back a trashed workspace. It does not switch to it.
+func (a *App) RestoreWorkspace(entryID string) (*workspace.Workspace, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+
+	entry, err := a.workspace.GetTrashEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := a.workspace.RestoreWorkspace(entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.emitTrashEvent("trash:restored", entry)
+	a.RefreshMenu()
+	return ws, nil
+}
+
+// PurgeTrashEntry permanently deletes a single trash entry.
+func (a *App) PurgeTrashEntry(entryID string) error {
+	if a.workspace == nil {
+		return fmt.Errorf("workspace manager not initialized")
+	}
+	entry, err := a.workspace.GetTrashEntry(entryID)
+	if err != nil {
+		return err
+	}
+	a.workspace.RemoveTrashEntry(entryID)
+	a.emitTrashEvent("trash:purged", entry)
+	return nil
+}
+
+// =============================================================================
+// TRASH HELPERS
+// =============================================================================
+
+// purgeExpiredTrash drops trash entries past their retention period (called on startup).
+func (a *App) purgeExpiredTrash() {
+	if a.workspace == nil {
+		return
+	}
+	if purged := a.workspace.PurgeExpiredTrash(); purged > 0 {
+		logger.Infof("Purged %d expired trash entries", purged)
+	}
+}
+
+// emitTrashEvent emits a trash lifecycle event (trash:added, trash:restored, trash:purged).
+// Uses the raw Wails emitter because deleting the current workspace swaps the runtime.
+func (a *App) emitTrashEvent(eventType string, entry *workspace.TrashEntry) {
+	if a.ctx == nil || entry == nil {
+		return
+	}
+	envelope := types.EventEnvelope{
+		EventType: eventType,
+		Payload: map[string]any{
+			"entry": entry,
+		},
+	}
+	if a.currentWorkspace != nil {
+		envelope.WorkspaceID = a.currentWorkspace.ID
+	}
+	if entry.Agent != nil {
+		envelope.AgentID = entry.Agent.ID
+	}
+	a.emitAppEvent(envelope)
+}
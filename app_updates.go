@@ -144,7 +144,7 @@ func (a *App) DownloadUpdate(version string) error {
 	zipName := fmt.Sprintf("ClaudeFu-v%s-darwin-universal.zip", version)
 	downloadURL := fmt.Sprintf("https://github.com/%s/releases/download/v%s/%s", githubRepo, version, zipName)
 
-	fmt.Printf("[Update] Downloading %s\n", downloadURL)
+	logger.Infof("[Update] Downloading %s", downloadURL)
 
 	// Download ZIP
 	zipPath := filepath.Join(updatesDir, zipName)
@@ -165,9 +165,9 @@ func (a *App) DownloadUpdate(version string) error {
 			os.RemoveAll(updatesDir)
 			return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA, actualSHA)
 		}
-		fmt.Printf("[Update] SHA256 verified: %s\n", actualSHA)
+		logger.Infof("[Update] SHA256 verified: %s", actualSHA)
 	} else {
-		fmt.Printf("[Update] No checksums.json found, skipping SHA256 verification\n")
+		logger.Infof("[Update] No checksums.json found, skipping SHA256 verification")
 	}
 
 	// Extract ZIP
@@ -176,7 +176,7 @@ func (a *App) DownloadUpdate(version string) error {
 		return fmt.Errorf("failed to create staging directory: %w", err)
 	}
 
-	fmt.Printf("[Update] Extracting to %s\n", stagingDir)
+	logger.Infof("[Update] Extracting to %s", stagingDir)
 	if err := extractZip(zipPath, stagingDir); err != nil {
 		os.RemoveAll(updatesDir)
 		return fmt.Errorf("extraction failed: %w", err)
@@ -198,7 +198,7 @@ func (a *App) DownloadUpdate(version string) error {
 	a.updateVersion = version
 	a.updateMu.Unlock()
 
-	fmt.Printf("[Update] v%s staged and ready to apply\n", version)
+	logger.Infof("[Update] v%s staged and ready to apply", version)
 
 	// Update the menu to show "Restart to Update..."
 	a.RefreshMenu()
@@ -255,7 +255,7 @@ func (a *App) ApplyUpdateAndRestart() error {
 		return fmt.Errorf("no write permission to %s: %w", appParent, err)
 	}
 
-	fmt.Printf("[Update] Replacing %s with v%s\n", currentApp, version)
+	logger.Infof("[Update] Replacing %s with v%s", currentApp, version)
 
 	// Atomic swap: rename current → .old, rename staged → current
 	backupApp := currentApp + ".old"
@@ -279,7 +279,7 @@ func (a *App) ApplyUpdateAndRestart() error {
 	os.RemoveAll(backupApp)
 	os.RemoveAll(filepath.Join(configPath, updatesDirName))
 
-	fmt.Printf("[Update] v%s installed successfully, restarting...\n", version)
+	logger.Infof("[Update] v%s installed successfully, restarting...", version)
 
 	// Step 4: Launch new binary and exit
 	newExe := filepath.Join(currentApp, "Contents", "MacOS", "ClaudeFu")
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"claudefu/internal/usage"
+)
+
+// =============================================================================
+// USAGE METHODS (Bound to frontend)
+// =============================================================================
+
+// UsageStats is token usage for the current workspace or one agent.
+// Costs are list-price estimates.
+type UsageStats struct {
+	Total     usage.Totals            `json:"total"`
+	ByDay     map[string]usage.Totals `json:"byDay"`     // "2006-01-02" (local) -> totals
+	ByModel   map[string]usage.Totals `json:"byModel"`   // model -> totals
+	ByAgent   map[string]usage.Totals `json:"byAgent"`   // agentID -> totals
+	BySession map[string]usage.Totals `json:"bySession"` // sessionID -> totals
+}
+
+// GetUsageStats returns token usage for agentID, or for all agents in the current
+// workspace if empty. days limits it to the last N days including today (0 = all time).
+// Agents sharing a folder share sessions, so a folder's usage is attributed to its first agent.
+func (a *App) GetUsageStats(agentID string, days int) (*UsageStats, error) {
+	if a.usage == nil {
+		return nil, fmt.Errorf("usage tracking not initialized")
+	}
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+
+	var since time.Time
+	if days > 0 {
+		now := time.Now()
+		since = time.Date(now.Year(), now.Month(), now.Day()-(days-1), 0, 0, 0, 0, time.Local)
+	}
+
+	result := &UsageStats{
+		ByDay:     make(map[string]usage.Totals),
+		ByModel:   make(map[string]usage.Totals),
+		ByAgent:   make(map[string]usage.Totals),
+		BySession: make(map[string]usage.Totals),
+	}
+	seen := make(map[string]bool)
+	found := false
+	for _, agent := range a.currentWorkspace.Agents {
+		if agentID != "" && agent.ID != agentID {
+			continue
+		}
+		found = true
+		if seen[agent.Folder] {
+			continue
+		}
+		seen[agent.Folder] = true
+
+		// Catch up on sessions the watcher is not watching (incremental)
+		if err := a.usage.UpdateFolder(agent.Folder); err != nil {
+			logger.Warnf("GetUsageStats: failed to read %s: %v", agent.Folder, err)
+		}
+
+		stats := a.usage.Stats(agent.Folder, since)
+		result.Total.Add(stats.Total)
+		result.ByAgent[agent.ID] = stats.Total
+		mergeTotals(result.ByDay, stats.ByDay)
+		mergeTotals(result.ByModel, stats.ByModel)
+		mergeTotals(result.BySession, stats.BySession)
+	}
+	if !found {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	return result, nil
+}
+
+// GetSessionUsage returns a session's accumulated token usage.
+func (a *App) GetSessionUsage(agentID, sessionID string) (usage.Totals, error) {
+	if a.usage == nil {
+		return usage.Totals{}, fmt.Errorf("usage tracking not initialized")
+	}
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return usage.Totals{}, fmt.Errorf("agent not found: %s", agentID)
+	}
+	a.usage.UpdateSession(agent.Folder, sessionID)
+	return a.usage.SessionTotals(agent.Folder, sessionID), nil
+}
+
+// =============================================================================
+// USAGE TRACKING LIFECYCLE
+// =============================================================================
+
+// initializeUsage creates the usage tracker and reads existing sessions in the background.
+func (a *App) initializeUsage() {
+	a.usage = usage.NewTracker()
+	a.scanWorkspaceUsage()
+}
+
+// scanWorkspaceUsage reads usage for all agents' sessions in the background.
+func (a *App) scanWorkspaceUsage() {
+	if a.usage == nil || a.currentWorkspace == nil {
+		return
+	}
+	folders := make([]string, 0, len(a.currentWorkspace.Agents))
+	for _, agent := range a.currentWorkspace.Agents {
+		folders = append(folders, agent.Folder)
+	}
+	tracker := a.usage
+	go func() {
+		for _, folder := range folders {
+			if err := tracker.UpdateFolder(folder); err != nil {
+				logger.Warnf("usage: failed to read %s: %v", folder, err)
+			}
+		}
+	}()
+}
+
+// updateSessionUsage reads new usage for a session and emits usage:updated
+// to each agent on the folder if it changed.
+func (a *App) updateSessionUsage(folder, sessionID string) {
+	if a.usage == nil || !a.usage.UpdateSession(folder, sessionID) {
+		return
+	}
+	if a.rt == nil || a.currentWorkspace == nil {
+		return
+	}
+	totals := a.usage.SessionTotals(folder, sessionID)
+	for _, agent := range a.currentWorkspace.Agents {
+		if agent.Folder == folder {
+			a.rt.Emit("usage:updated", agent.ID, sessionID, map[string]any{
+				"usage": totals,
+			})
+		}
+	}
+}
+
+func mergeTotals(dst, src map[string]usage.Totals) {
+	for key, totals := range src {
+		current := dst[key]
+		current.Add(totals)
+		dst[key] = current
+	}
+}
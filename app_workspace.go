@@ -8,6 +8,7 @@ import (
 	wailsrt "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"claudefu/internal/mcpserver"
+	"claudefu/internal/types"
 	"claudefu/internal/workspace"
 )
 
@@ -37,6 +38,35 @@ func (a *App) GetCurrentWorkspace() *workspace.Workspace {
 	return a.currentWorkspace
 }
 
+// WorkspaceSnapshot is the full in-memory state of the current workspace.
+type WorkspaceSnapshot struct {
+	Workspace    *workspace.Workspace       `json:"workspace"`
+	Agents       []workspace.Agent          `json:"agents"`
+	Sessions     map[string][]types.Session `json:"sessions"`     // agentID -> sessions
+	UnreadCounts map[string]map[string]int  `json:"unreadCounts"` // agentID -> sessionID -> unread
+}
+
+// GetWorkspaceSnapshot returns the full workspace state in one call.
+// Startup emits this incrementally (see emitInitialState); use this to resync on demand.
+func (a *App) GetWorkspaceSnapshot() (*WorkspaceSnapshot, error) {
+	if a.currentWorkspace == nil || a.rt == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+
+	snapshot := &WorkspaceSnapshot{
+		Workspace:    a.currentWorkspace,
+		Agents:       a.currentWorkspace.Agents,
+		Sessions:     make(map[string][]types.Session),
+		UnreadCounts: make(map[string]map[string]int),
+	}
+	for _, agent := range a.currentWorkspace.Agents {
+		sessions, unread := a.buildAgentSessionState(agent.ID)
+		snapshot.Sessions[agent.ID] = sessions
+		snapshot.UnreadCounts[agent.ID] = unread
+	}
+	return snapshot, nil
+}
+
 // ReloadCurrentWorkspace reloads the current workspace from disk with fresh registry data.
 // Called by frontend after saving to registries (meta dialog, MCP settings) to ensure
 // all UIs see updated agent identity (name, slug, description from PopulateAgentsFromRegistry).
@@ -51,16 +81,22 @@ func (a *App) ReloadCurrentWorkspace() (*workspace.Workspace, error) {
 	// Re-apply runtime state (selected sessions, last opened) from workspace state file
 	populateWorkspaceFromState(ws, a.workspaceState)
 	a.currentWorkspace = ws
+	a.applyAgentCLIOverrides()
+	a.applyEnvProfile()
+	a.applyIntegrations()
 	return ws, nil
 }
 
 // SwitchWorkspace performs a clean workspace switch with full state teardown
 func (a *App) SwitchWorkspace(workspaceID string) (*workspace.Workspace, error) {
-	fmt.Printf("[DEBUG] SwitchWorkspace called: workspaceID=%s\n", workspaceID)
+	logger.Debugf("SwitchWorkspace called: workspaceID=%s", workspaceID)
 	if a.workspace == nil {
 		return nil, fmt.Errorf("workspace manager not initialized")
 	}
 
+	// A workspace running in the background becomes the active one
+	a.closeBackgroundWorkspace(workspaceID)
+
 	// Step 1: Emit workspace:changed (triggers frontend splash)
 	a.emitLoadingStatus("Switching workspace...")
 	if a.rt != nil {
@@ -90,7 +126,7 @@ func (a *App) SwitchWorkspace(workspaceID string) (*workspace.Workspace, error)
 	ws = a.workspace.UpgradeWorkspaceSchema(ws)
 	a.reconciledIDs = a.workspace.SyncAgentIDsFromRegistry(ws)
 	if len(a.reconciledIDs) > 0 {
-		fmt.Printf("[INFO] SwitchWorkspace: Reconciled %d agent IDs\n", len(a.reconciledIDs))
+		logger.Infof("SwitchWorkspace: Reconciled %d agent IDs", len(a.reconciledIDs))
 	}
 
 	// Step 5b: Migrate runtime fields from workspace JSON to local/ (one-time)
@@ -122,20 +158,36 @@ func (a *App) SwitchWorkspace(workspaceID string) (*workspace.Workspace, error)
 	}
 	a.currentWorkspace = ws
 	a.workspaceState = wsState
+	a.applyAgentCLIOverrides()
+	a.applyEnvProfile()
+	a.applyIntegrations()
+	if a.gitStatus != nil {
+		a.gitStatus.Forget(a.agentFolders())
+	}
+	if a.workspaceScanner != nil {
+		a.workspaceScanner.Forget(workspaceID)
+	}
 
 	// Step 7: Re-initialize runtime
 	a.emitLoadingStatus("Setting up file watchers...")
 	a.initializeRuntime()
 
+	// Step 7b: Apply query session retention before sessions are loaded
+	a.applyQuerySessionRetention()
+
 	// Step 8: Start watching all agents (emits per-agent status internally)
 	a.startWatchingAllAgents()
 
 	// Step 8b: Restore per-agent session file watches from persisted SelectedSessionID
 	a.restoreAgentSessionWatches()
+	a.indexWorkspaceSessions()
+	a.scanWorkspaceUsage()
 
 	// Step 9: Restart MCP server and load inbox/backlog for new workspace
 	if a.mcpServer != nil {
+		a.ensureMCPAuthToken()
 		a.mcpServer.Restart()
+		a.configureMCPClients()
 
 		agentIDs := make([]string, len(ws.Agents))
 		for i, agent := range ws.Agents {
@@ -169,9 +221,10 @@ func (a *App) SwitchWorkspace(workspaceID string) (*workspace.Workspace, error)
 	if a.settings != nil {
 		settings := a.settings.GetSettings()
 		if err := a.workspace.EnsureSifuAgent(ws, settings.SifuEnabled, settings.SifuRootFolder); err != nil {
-			fmt.Printf("[WARN] EnsureSifuAgent on switch: %v\n", err)
+			logger.Warnf("EnsureSifuAgent on switch: %v", err)
 		}
 	}
+	a.RefreshWhosWho()
 
 	// Step 11: Emit initial state
 	a.emitInitialState()
@@ -205,7 +258,7 @@ func (a *App) RenameWorkspace(workspaceID string, newName string) error {
 
 // DeleteWorkspace removes a workspace by ID.
 // If deleting the current workspace, switches to another first.
-// Also cleans up the MCP inbox database for the deleted workspace.
+// The workspace is moved to trash and can be brought back with RestoreWorkspace.
 func (a *App) DeleteWorkspace(workspaceID string) error {
 	if a.workspace == nil {
 		return fmt.Errorf("workspace manager not initialized")
@@ -238,16 +291,21 @@ func (a *App) DeleteWorkspace(workspaceID string) error {
 		}
 	}
 
-	// Delete the workspace
-	if err := a.workspace.DeleteWorkspace(workspaceID); err != nil {
+	// Stop it if it runs in the background
+	if a.closeBackgroundWorkspace(workspaceID) {
+		a.applyAgentCLIOverrides()
+	}
+
+	// Move the workspace to trash (snapshots file, registry meta and local state, then deletes)
+	entry, err := a.workspace.TrashWorkspace(workspaceID)
+	if err != nil {
 		return err
 	}
 
 	// Note: inbox is now per-agent (not per-workspace), so no inbox cleanup needed here.
 	// Agent inbox DBs persist at ~/.claudefu/inbox/agents/{agent_id}.db
 
-	// Clean up local workspace state file
-	a.workspace.DeleteWorkspaceState(workspaceID)
+	a.emitTrashEvent("trash:added", entry)
 
 	return nil
 }
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"claudefu/internal/events"
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
+	"claudefu/internal/runtime"
+	"claudefu/internal/types"
+	"claudefu/internal/watcher"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// BACKGROUND WORKSPACE METHODS (Bound to frontend)
+// A workspace other than the active one can keep running in the background:
+// it gets its own runtime and file watcher, and its own MCP server on a free
+// port that its agents' --mcp-config points at. Its events are emitted under
+// types.WorkspaceEvent names, so a second window (or the active one, for
+// monitoring) can subscribe to exactly one workspace.
+// =============================================================================
+
+// backgroundWorkspace is a non-active workspace kept running.
+type backgroundWorkspace struct {
+	ws        *workspace.Workspace
+	state     *workspace.WorkspaceState
+	rt        *runtime.WorkspaceRuntime
+	watcher   *watcher.FileWatcher
+	mcp       *mcpserver.MCPService // nil if it failed to start (agents use the active server)
+	mcpConfig string                // --mcp-config JSON for its agents
+}
+
+// BackgroundWorkspaceInfo describes a workspace running in the background.
+type BackgroundWorkspaceInfo struct {
+	WorkspaceID string `json:"workspaceId"`
+	Name        string `json:"name"`
+	AgentCount  int    `json:"agentCount"`
+	MCPPort     int    `json:"mcpPort,omitempty"` // 0 = no MCP server of its own
+	EventPrefix string `json:"eventPrefix"`       // Its events are named EventPrefix + eventType
+}
+
+// OpenBackgroundWorkspace starts a non-active workspace in the background:
+// its sessions are loaded and watched, and MCP tools of its agents are served
+// on a port of its own. Opening an already open workspace is a no-op.
+func (a *App) OpenBackgroundWorkspace(workspaceID string) (*BackgroundWorkspaceInfo, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	if a.currentWorkspace != nil && a.currentWorkspace.ID == workspaceID {
+		return nil, fmt.Errorf("workspace %s is the active workspace", workspaceID)
+	}
+
+	a.backgroundMu.Lock()
+	if bg, ok := a.background[workspaceID]; ok {
+		a.backgroundMu.Unlock()
+		info := bg.info()
+		return &info, nil
+	}
+	a.backgroundMu.Unlock()
+
+	ws, err := a.workspace.LoadWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ws = a.workspace.UpgradeWorkspaceSchema(ws)
+	state := a.workspace.LoadWorkspaceState(workspaceID)
+	populateWorkspaceFromState(ws, state)
+
+	fw, err := watcher.NewFileWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	fw.SetSessionChangeHook(a.onSessionFileChanged)
+	fw.SetSessionRemoveHook(a.onSessionFileRemoved)
+	if a.settings != nil {
+		s := a.settings.GetSettings()
+		fw.SetPollInterval(time.Duration(s.WatchPollIntervalMs) * time.Millisecond)
+		fw.SetLoadFullHistory(s.LoadFullHistory)
+	}
+
+	bg := &backgroundWorkspace{ws: ws, state: state, watcher: fw}
+	bg.rt = runtime.NewWorkspaceRuntime(ws, a.events.BackgroundPublisher(events.SourceRuntime, ws.ID))
+	if a.settings != nil {
+		s := a.settings.GetSettings()
+		bg.rt.SetBufferDefaults(runtime.BufferLimits{MaxMessages: s.BufferMaxMessages, MaxBytes: s.BufferMaxBytes})
+		bg.rt.SetMemoryBudget(int64(s.MemoryBudgetMB) * 1024 * 1024)
+	}
+	fw.SetRuntime(bg.rt)
+
+	for _, agent := range ws.Agents {
+		var lastViewedMap map[string]int64
+		if a.sessions != nil {
+			lastViewedMap = a.sessions.GetAllLastViewed(agent.Folder)
+		}
+		fw.SetFolderPolling(agent.Folder, agent.GetWatchMode() == types.WatchModePoll)
+		if err := fw.StartWatchingAgent(agent.ID, agent.Folder, lastViewedMap); err != nil {
+			logger.Warnf("Background workspace %s: failed to watch agent %s: %v", ws.Name, agent.GetSlug(), err)
+		}
+		if sessionID := state.AgentSessions[agent.ID]; sessionID != "" {
+			fw.SetActiveSessionWatch(agent.ID, sessionID)
+		}
+	}
+
+	a.startBackgroundMCP(bg)
+
+	a.backgroundMu.Lock()
+	if a.background == nil {
+		a.background = make(map[string]*backgroundWorkspace)
+	}
+	if existing, ok := a.background[workspaceID]; ok {
+		// Opened concurrently; keep the first
+		a.backgroundMu.Unlock()
+		bg.stop()
+		info := existing.info()
+		return &info, nil
+	}
+	a.background[workspaceID] = bg
+	a.backgroundMu.Unlock()
+	a.applyAgentCLIOverrides()
+	if a.workspaceScanner != nil {
+		a.workspaceScanner.Forget(workspaceID)
+	}
+
+	info := bg.info()
+	logger.Infof("Background workspace %s opened (%d agents, MCP port %d)", ws.Name, len(ws.Agents), info.MCPPort)
+	a.emitAppEvent(types.EventEnvelope{
+		WorkspaceID: workspaceID,
+		EventType:   "workspace:background-opened",
+		Payload:     info,
+	})
+	return &info, nil
+}
+
+// CloseBackgroundWorkspace stops a background workspace's watcher and MCP
+// server and drops its runtime state.
+func (a *App) CloseBackgroundWorkspace(workspaceID string) error {
+	if !a.closeBackgroundWorkspace(workspaceID) {
+		return fmt.Errorf("workspace %s is not open in the background", workspaceID)
+	}
+	a.applyAgentCLIOverrides()
+	a.emitAppEvent(types.EventEnvelope{
+		WorkspaceID: workspaceID,
+		EventType:   "workspace:background-closed",
+		Payload:     map[string]any{"workspaceId": workspaceID},
+	})
+	return nil
+}
+
+// GetBackgroundWorkspaces returns the workspaces running in the background
+func (a *App) GetBackgroundWorkspaces() []BackgroundWorkspaceInfo {
+	a.backgroundMu.Lock()
+	defer a.backgroundMu.Unlock()
+	result := make([]BackgroundWorkspaceInfo, 0, len(a.background))
+	for _, bg := range a.background {
+		result = append(result, bg.info())
+	}
+	return result
+}
+
+// GetBackgroundAgentSessions returns the session list of an agent in a
+// background workspace (the runtime's summaries, newest activity included)
+func (a *App) GetBackgroundAgentSessions(workspaceID, agentID string) ([]types.Session, error) {
+	bg := a.getBackgroundWorkspace(workspaceID)
+	if bg == nil {
+		return nil, fmt.Errorf("workspace %s is not open in the background", workspaceID)
+	}
+	sessions := bg.rt.GetSessionSummaries(agentID)
+	for _, agent := range bg.ws.Agents {
+		if agent.ID == agentID {
+			a.applySessionLabels(agent.Folder, sessions)
+		}
+	}
+	return sessions, nil
+}
+
+// info describes bg for the frontend.
+func (bg *backgroundWorkspace) info() BackgroundWorkspaceInfo {
+	info := BackgroundWorkspaceInfo{
+		WorkspaceID: bg.ws.ID,
+		Name:        bg.ws.Name,
+		AgentCount:  len(bg.ws.Agents),
+		EventPrefix: types.WorkspaceEvent(bg.ws.ID, ""),
+	}
+	if bg.mcp != nil {
+		info.MCPPort = bg.mcp.GetPort()
+	}
+	return info
+}
+
+// findAgent returns one of bg's agents, or nil.
+func (bg *backgroundWorkspace) findAgent(agentID string) *workspace.Agent {
+	for i := range bg.ws.Agents {
+		if bg.ws.Agents[i].ID == agentID {
+			return &bg.ws.Agents[i]
+		}
+	}
+	return nil
+}
+
+// runtimeAgentActivity reports an agent's activity in rt for AgentStatus.
+// sessionID is the agent's selected session.
+func runtimeAgentActivity(rt *runtime.WorkspaceRuntime, agentID, sessionID string) mcpserver.AgentActivity {
+	activity := mcpserver.AgentActivity{
+		SessionID:   sessionID,
+		UnreadCount: rt.GetAgentTotalUnread(agentID),
+	}
+	for _, session := range rt.GetSessionSummaries(agentID) {
+		if session.UpdatedAt.After(activity.LastActivity) {
+			activity.LastActivity = session.UpdatedAt
+		}
+	}
+	return activity
+}
+
+// getBackgroundWorkspace returns a background workspace, or nil if it is not open.
+func (a *App) getBackgroundWorkspace(workspaceID string) *backgroundWorkspace {
+	a.backgroundMu.Lock()
+	defer a.backgroundMu.Unlock()
+	return a.background[workspaceID]
+}
+
+// startBackgroundMCP starts bg's own MCP server on a free port. On failure
+// bg's agents keep using the active workspace's server.
+func (a *App) startBackgroundMCP(bg *backgroundWorkspace) {
+	if a.settings == nil {
+		return
+	}
+	port := a.freeMCPPort(bg.ws.MCPConfig.GetPort())
+	if port == 0 {
+		logger.Warnf("Background workspace %s: no free MCP port", bg.ws.Name)
+		return
+	}
+	configPath := a.settings.GetConfigPath()
+	server := mcpserver.NewMCPService(port, configPath, filepath.Join(configPath, "inbox"), filepath.Join(configPath, "backlog"))
+	server.SetClaudeService(a.claude)
+	server.SetSessionService(a.sessionService)
+	server.SetTaskManager(a.tasks)
+	server.SetSummaryService(a.summaries)
+	server.SetManager(a.workspace)
+	server.SetWorkspaceGetter(func() *workspace.Workspace { return bg.ws })
+	server.SetActiveSessionGetter(func(agentSlug string) (agentID, sessionID, folder, slug string) {
+		for _, agent := range bg.ws.Agents {
+			if agent.GetSlug() != agentSlug {
+				continue
+			}
+			sessionID := bg.state.AgentSessions[agent.ID]
+			if session := bg.rt.GetSessionState(agent.ID, sessionID); session != nil {
+				return agent.ID, sessionID, agent.Folder, session.Slug
+			}
+			return agent.ID, sessionID, agent.Folder, ""
+		}
+		return "", "", "", ""
+	})
+	server.SetAgentActivityGetter(func(agentID string) mcpserver.AgentActivity {
+		return runtimeAgentActivity(bg.rt, agentID, bg.state.AgentSessions[agentID])
+	})
+	server.SetBrowserBridgeDefaults(browserBridgeDefaults(a.settings.GetSettings()))
+	server.SetHandoffFunc(a.backgroundHandoffFunc(bg))
+	server.SetEmitFunc(a.events.BackgroundPublisher(events.SourceMCP, bg.ws.ID))
+
+	a.ensureWorkspaceMCPAuthToken(bg.ws)
+	if err := server.Start(); err != nil {
+		logger.Warnf("Background workspace %s: failed to start MCP server: %v", bg.ws.Name, err)
+		return
+	}
+	agentIDs := make([]string, len(bg.ws.Agents))
+	for i, agent := range bg.ws.Agents {
+		agentIDs[i] = agent.ID
+	}
+	if err := server.LoadInbox(agentIDs); err != nil {
+		logger.Warnf("Background workspace %s: failed to load inbox: %v", bg.ws.Name, err)
+	}
+	if err := server.LoadBacklog(agentIDs); err != nil {
+		logger.Warnf("Background workspace %s: failed to load backlog: %v", bg.ws.Name, err)
+	}
+
+	token := ""
+	if cfg := bg.ws.MCPConfig; cfg.AuthRequired() && cfg != nil {
+		token = cfg.AuthToken
+	}
+	bg.mcp = server
+	bg.mcpConfig = providers.MCPConfigJSON(bg.ws.MCPConfig.ClientHost(), port, token)
+}
+
+// freeMCPPort returns the first port from preferred on that no MCP server of
+// ClaudeFu uses and that can be bound, or 0 if none is found nearby.
+func (a *App) freeMCPPort(preferred int) int {
+	used := map[int]bool{}
+	if a.mcpServer != nil {
+		used[a.mcpServer.GetPort()] = true
+	}
+	a.backgroundMu.Lock()
+	for _, bg := range a.background {
+		if bg.mcp != nil {
+			used[bg.mcp.GetPort()] = true
+		}
+	}
+	a.backgroundMu.Unlock()
+
+	for port := preferred; port < preferred+100; port++ {
+		if used[port] {
+			continue
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			continue
+		}
+		ln.Close()
+		return port
+	}
+	return 0
+}
+
+// applyBackgroundCLIOverrides sets the CLI overrides of background workspaces'
+// agents, pointing their --mcp-config at their own workspace's MCP server.
+func (a *App) applyBackgroundCLIOverrides() {
+	a.backgroundMu.Lock()
+	defer a.backgroundMu.Unlock()
+	for _, bg := range a.background {
+		for _, agent := range bg.ws.Agents {
+			override := agentCLIOverride(agent)
+			override.MCPConfig = bg.mcpConfig
+			providers.SetFolderCLIOverride(agent.Folder, override)
+		}
+	}
+}
+
+// closeBackgroundWorkspace stops and forgets a background workspace.
+// Returns false if it was not open.
+func (a *App) closeBackgroundWorkspace(workspaceID string) bool {
+	a.backgroundMu.Lock()
+	bg, ok := a.background[workspaceID]
+	delete(a.background, workspaceID)
+	a.backgroundMu.Unlock()
+	if !ok {
+		return false
+	}
+	bg.stop()
+	logger.Infof("Background workspace %s closed", bg.ws.Name)
+	return true
+}
+
+// stop stops bg's watcher and MCP server and clears its runtime.
+func (bg *backgroundWorkspace) stop() {
+	bg.watcher.StopAllWatchers()
+	bg.watcher.Close()
+	bg.rt.Clear()
+	if bg.mcp != nil {
+		bg.mcp.Stop()
+		bg.mcp.CloseStores()
+	}
+}
+
+// closeAllBackgroundWorkspaces stops every background workspace (on shutdown).
+func (a *App) closeAllBackgroundWorkspaces() {
+	a.backgroundMu.Lock()
+	ids := make([]string, 0, len(a.background))
+	for id := range a.background {
+		ids = append(ids, id)
+	}
+	a.backgroundMu.Unlock()
+	for _, id := range ids {
+		a.closeBackgroundWorkspace(id)
+	}
+}
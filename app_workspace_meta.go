@@ -68,7 +68,7 @@ func (a *App) UpdateWorkspaceMeta(workspaceID string, meta map[string]string) er
 	// Sync name change to workspace JSON
 	if nameChanged {
 		if err := a.workspace.RenameWorkspace(workspaceID, newName); err != nil {
-			fmt.Printf("[WARN] Failed to sync workspace name to JSON: %v\n", err)
+			logger.Warnf("Failed to sync workspace name to JSON: %v", err)
 		}
 	}
 
@@ -166,7 +166,18 @@ func (a *App) RefreshSifuPermissions() {
 	}
 	sifuFolder := filepath.Join(root, sifuSlug)
 	if err := a.workspace.GenerateSifuPermissions(a.currentWorkspace, sifuFolder); err != nil {
-		fmt.Printf("[WARN] RefreshSifuPermissions: %v\n", err)
+		logger.Warnf("RefreshSifuPermissions: %v", err)
+	}
+}
+
+// RefreshWhosWho regenerates .claude/claudefu.agents.md in every agent folder of the
+// current workspace. Called automatically after agent add/remove/update and workspace load.
+func (a *App) RefreshWhosWho() {
+	if a.workspace == nil || a.currentWorkspace == nil {
+		return
+	}
+	if err := a.workspace.GenerateWhosWho(a.currentWorkspace); err != nil {
+		logger.Warnf("RefreshWhosWho: %v", err)
 	}
 }
 
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+
+	"claudefu/internal/permissions"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// WORKSPACE TEMPLATE METHODS (Bound to frontend)
+// =============================================================================
+
+// DuplicateWorkspace copies a workspace's agents and config into a new workspace.
+// An empty newName uses "<name> (copy)". Does not switch to the copy.
+func (a *App) DuplicateWorkspace(workspaceID, newName string) (*workspace.Workspace, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	if a.currentWorkspace != nil && a.currentWorkspace.ID == workspaceID {
+		// Flush in-memory edits so the copy matches what the user sees
+		if err := a.workspace.SaveWorkspace(a.currentWorkspace); err != nil {
+			return nil, err
+		}
+	}
+	dup, err := a.workspace.DuplicateWorkspace(workspaceID, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The copy gets its own remote answer token; its agent auth token is
+	// generated when its MCP server first starts
+	if cfg := dup.MCPConfig; cfg != nil && cfg.RemoteEnabled {
+		if cfg.RemoteToken, err = newRemoteToken(); err != nil {
+			return nil, err
+		}
+		if err := a.workspace.SaveWorkspace(dup); err != nil {
+			return nil, err
+		}
+	}
+	return dup, nil
+}
+
+// SaveWorkspaceAsTemplate saves a workspace's agents, MCP config, env profiles,
+// and each agent's own ClaudeFu permissions as a reusable template.
+func (a *App) SaveWorkspaceAsTemplate(workspaceID, name, description string) (*workspace.WorkspaceTemplate, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	ws := a.currentWorkspace
+	if ws == nil || ws.ID != workspaceID {
+		var err error
+		if ws, err = a.workspace.LoadWorkspace(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl := workspace.NewTemplateFromWorkspace(ws, name, description)
+	if mgr, err := permissions.NewManager(); err == nil {
+		for i := range tmpl.Agents {
+			perms, err := mgr.LoadAgentPermissions(tmpl.Agents[i].Folder)
+			if err != nil {
+				logger.Warnf("SaveWorkspaceAsTemplate: permissions for %s: %v", tmpl.Agents[i].Slug, err)
+				continue
+			}
+			tmpl.Agents[i].Permissions = perms
+		}
+	} else {
+		logger.Warnf("Failed to create permissions manager: %v", err)
+	}
+
+	if err := a.workspace.SaveWorkspaceTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	logger.Infof("Saved workspace %q as template %q (%d agents)", ws.Name, name, len(tmpl.Agents))
+	return tmpl, nil
+}
+
+// GetWorkspaceTemplates lists saved workspace templates.
+func (a *App) GetWorkspaceTemplates() ([]workspace.WorkspaceTemplateSummary, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.ListWorkspaceTemplates()
+}
+
+// GetWorkspaceTemplate returns a template, including the folders the frontend
+// prompts the user to map before CreateWorkspaceFromTemplate.
+func (a *App) GetWorkspaceTemplate(name string) (*workspace.WorkspaceTemplate, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.GetWorkspaceTemplate(name)
+}
+
+// DeleteWorkspaceTemplate removes a saved template.
+func (a *App) DeleteWorkspaceTemplate(name string) error {
+	if a.workspace == nil {
+		return fmt.Errorf("workspace manager not initialized")
+	}
+	return a.workspace.DeleteWorkspaceTemplate(name)
+}
+
+// CreateWorkspaceFromTemplate creates a workspace from a template. folderMap maps
+// template folders (or parent directories of them) to new paths; unmapped
+// folders are reused as-is. Permission presets are copied to agents that don't
+// have their own yet. Like CreateWorkspace, the frontend switches to the result.
+func (a *App) CreateWorkspaceFromTemplate(templateName, workspaceName string, folderMap map[string]string) (*workspace.Workspace, error) {
+	if a.workspace == nil {
+		return nil, fmt.Errorf("workspace manager not initialized")
+	}
+	tmpl, err := a.workspace.GetWorkspaceTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := a.workspace.CreateWorkspaceFromTemplate(tmpl, workspaceName, folderMap)
+	if err != nil {
+		return nil, err
+	}
+	a.applyTemplatePermissions(tmpl, folderMap)
+	logger.Infof("Created workspace %q from template %q (%d agents)", ws.Name, tmpl.Name, len(ws.Agents))
+	return ws, nil
+}
+
+// =============================================================================
+// WORKSPACE TEMPLATE HELPERS
+// =============================================================================
+
+// applyTemplatePermissions writes each template agent's permission preset to its
+// mapped folder, leaving folders that already have ClaudeFu permissions alone.
+func (a *App) applyTemplatePermissions(tmpl *workspace.WorkspaceTemplate, folderMap map[string]string) {
+	mgr, err := permissions.NewManager()
+	if err != nil {
+		logger.Warnf("Failed to create permissions manager: %v", err)
+		return
+	}
+	for _, ta := range tmpl.Agents {
+		if ta.Permissions == nil {
+			continue
+		}
+		folder := workspace.MapTemplateFolder(ta.Folder, folderMap)
+		if existing, _ := mgr.LoadAgentPermissions(folder); existing != nil {
+			continue
+		}
+		if err := mgr.SaveAgentPermissions(folder, ta.Permissions); err != nil {
+			logger.Warnf("Failed to apply template permissions to %s: %v", folder, err)
+		}
+	}
+}
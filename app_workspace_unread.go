@@ -0,0 +1,100 @@
+package main
+
+import (
+	"time"
+
+	"claudefu/internal/monitor"
+	"claudefu/internal/settings"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// WORKSPACE UNREAD METHODS (Bound to frontend)
+// =============================================================================
+
+// GetWorkspaceUnread returns the latest unread counts of every workspace other
+// than the active one (see workspace:unread)
+func (a *App) GetWorkspaceUnread() []monitor.WorkspaceUnread {
+	if a.workspaceScanner == nil {
+		return []monitor.WorkspaceUnread{}
+	}
+	return a.workspaceScanner.Get()
+}
+
+// =============================================================================
+// WORKSPACE UNREAD LIFECYCLE
+// =============================================================================
+
+// initializeWorkspaceScanner starts checking other workspaces' session files for
+// activity since they were last viewed.
+func (a *App) initializeWorkspaceScanner() {
+	lastViewed := func(folder string) map[string]int64 {
+		if a.sessions == nil {
+			return nil
+		}
+		return a.sessions.GetAllLastViewed(folder)
+	}
+	a.workspaceScanner = monitor.NewUnreadScanner(a.scannedWorkspaces, lastViewed, a.emitWorkspaceUnread)
+	if a.settings != nil {
+		a.applyWorkspaceScanSettings(a.settings.GetSettings())
+	}
+}
+
+// applyWorkspaceScanSettings (re)starts the scanner with the configured
+// interval, or stops it.
+func (a *App) applyWorkspaceScanSettings(s settings.Settings) {
+	if a.workspaceScanner == nil {
+		return
+	}
+	a.workspaceScanner.Stop()
+	if s.WorkspaceScanIntervalSec < 0 {
+		return
+	}
+	a.workspaceScanner.Start(time.Duration(s.WorkspaceScanIntervalSec) * time.Second)
+}
+
+// scannedWorkspaces returns every workspace except the active one and those
+// open in the background (their watchers track unread state), with their
+// agent folders.
+func (a *App) scannedWorkspaces() []monitor.Workspace {
+	if a.workspace == nil {
+		return nil
+	}
+	summaries, err := a.workspace.GetAllWorkspaces()
+	if err != nil {
+		logger.Warnf("Workspace unread scan: %v", err)
+		return nil
+	}
+	currentID := ""
+	if ws := a.currentWorkspace; ws != nil {
+		currentID = ws.ID
+	}
+
+	var result []monitor.Workspace
+	for _, summary := range summaries {
+		if summary.ID == currentID || a.getBackgroundWorkspace(summary.ID) != nil {
+			continue
+		}
+		ws, err := a.workspace.LoadWorkspace(summary.ID)
+		if err != nil {
+			continue
+		}
+		scanned := monitor.Workspace{ID: ws.ID, Name: ws.Name}
+		for _, agent := range ws.Agents {
+			if agent.Folder != "" {
+				scanned.Agents = append(scanned.Agents, monitor.Agent{ID: agent.ID, Folder: agent.Folder})
+			}
+		}
+		result = append(result, scanned)
+	}
+	return result
+}
+
+// emitWorkspaceUnread emits workspace:unread when a workspace's counts change.
+func (a *App) emitWorkspaceUnread(unread monitor.WorkspaceUnread) {
+	a.emitAppEvent(types.EventEnvelope{
+		WorkspaceID: unread.WorkspaceID,
+		EventType:   "workspace:unread",
+		Payload:     unread,
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"claudefu/internal/git"
+	"claudefu/internal/permissions"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// WORKTREE AGENT METHODS (Bound to frontend)
+// =============================================================================
+
+// CreateWorktreeAgent checks out branch into a new git worktree next to the
+// repository at repoPath and adds it to the current workspace as an agent.
+// The worktree inherits the repository agent's ClaudeFu permissions.
+func (a *App) CreateWorktreeAgent(repoPath, branch string) (*workspace.Agent, error) {
+	if a.currentWorkspace == nil {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+
+	folder, err := git.AddWorktree(repoPath, branch)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("CreateWorktreeAgent: created worktree %s (branch %s)", folder, branch)
+
+	a.inheritWorktreePermissions(repoPath, folder)
+
+	agent, err := a.AddAgent(filepath.Base(folder), folder)
+	if err != nil {
+		// Don't leave an orphaned checkout behind
+		if rmErr := git.RemoveWorktree(folder, true); rmErr != nil {
+			logger.Warnf("CreateWorktreeAgent: failed to clean up worktree %s: %v", folder, rmErr)
+		}
+		return nil, err
+	}
+	return agent, nil
+}
+
+// RemoveWorktreeAgent removes a worktree-backed agent from the workspace and
+// deletes its worktree. Without force, a worktree with uncommitted changes is
+// left untouched and an error is returned. The branch is kept.
+func (a *App) RemoveWorktreeAgent(agentID string, force bool) error {
+	agent := a.getAgentByID(agentID)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	folder := agent.Folder
+	if !git.IsLinkedWorktree(folder) {
+		return fmt.Errorf("agent %s is not backed by a git worktree", agent.GetSlug())
+	}
+
+	if a.watcher != nil {
+		a.watcher.StopWatchingAgent(agentID, folder)
+	}
+	if err := git.RemoveWorktree(folder, force); err != nil {
+		if a.watcher != nil && a.rt != nil {
+			a.startWatchingAgent(agent)
+		}
+		return err
+	}
+	logger.Infof("RemoveWorktreeAgent: removed worktree %s", folder)
+
+	return a.RemoveAgent(agentID)
+}
+
+// =============================================================================
+// WORKTREE HELPERS
+// =============================================================================
+
+// inheritWorktreePermissions copies the repository agent's ClaudeFu permissions
+// (or the global ones) into a new worktree that doesn't track its own.
+func (a *App) inheritWorktreePermissions(repoPath, folder string) {
+	mgr, err := permissions.NewManager()
+	if err != nil {
+		logger.Warnf("Failed to create permissions manager: %v", err)
+		return
+	}
+	if existing, _ := mgr.LoadAgentPermissions(folder); existing != nil {
+		return
+	}
+	perms, err := mgr.GetAgentPermissionsOrGlobal(repoPath)
+	if err != nil {
+		logger.Warnf("Failed to load permissions for %s: %v", repoPath, err)
+		return
+	}
+	if err := mgr.SaveAgentPermissions(folder, perms); err != nil {
+		logger.Warnf("Failed to copy permissions to worktree %s: %v", folder, err)
+	}
+}
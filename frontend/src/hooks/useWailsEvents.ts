@@ -332,7 +332,10 @@ export function useWailsEvents() {
 
   // Subscribe to debug:cli-command events (emitted by Claude CLI service)
   useEffect(() => {
-    const handleDebugCliCommand = (data: { command?: string; sessionId?: string }) => {
+    const handleDebugCliCommand = (envelope: {
+      payload?: { command?: string; sessionId?: string };
+    }) => {
+      const data = envelope?.payload;
       if (data?.command) {
         // Forward to custom event for DebugStatsOverlay
         window.dispatchEvent(new CustomEvent('claudefu:debug-cli-command', {
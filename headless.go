@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"claudefu/internal/control"
+	"claudefu/internal/diagnostics"
+	"claudefu/internal/logging"
+	"claudefu/internal/mcpserver"
+	"claudefu/internal/providers"
+	"claudefu/internal/session"
+	"claudefu/internal/settings"
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// headlessCommands are the `claudefu <command>` subcommands that run without the GUI.
+// Each talks to a running instance over the control socket when one is up, so the
+// UI sees the activity; otherwise it falls back to the workspace/providers packages.
+var headlessCommands = map[string]func(args []string) error{
+	"agents":    runAgentsCommand,
+	"sessions":  runSessionsCommand,
+	"send":      runSendCommand,
+	"mcp-stdio": runMCPStdioCommand,
+	"doctor":    runDoctorCommand,
+}
+
+// headlessOut receives command output. Logging and any stray fmt.Printf go to
+// stderr while a command runs to keep stdout scriptable.
+var headlessOut io.Writer = os.Stdout
+
+// runHeadlessCommand runs os.Args[1] if it is a headless subcommand.
+// Returns false when the args are not a subcommand (start the GUI instead).
+// An existing directory wins over a subcommand of the same name, so
+// `claudefu agents` still adds ./agents as an agent folder.
+func runHeadlessCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		return false
+	}
+	cmd, ok := headlessCommands[args[0]]
+	if !ok {
+		return false
+	}
+	headlessOut = os.Stdout
+	os.Stdout = os.Stderr
+	logging.SetConsole(os.Stderr)
+	if err := cmd(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// headlessEnv resolves the config dir and, if ClaudeFu is running, a control client.
+type headlessEnv struct {
+	settings *settings.Manager
+	client   *control.Client // nil = direct mode
+}
+
+func newHeadlessEnv() (*headlessEnv, error) {
+	sm, err := settings.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("loading settings: %w", err)
+	}
+	env := &headlessEnv{settings: sm}
+	if client, err := control.Dial(control.SocketPath(sm.GetConfigPath())); err == nil {
+		env.client = client
+	}
+	return env, nil
+}
+
+// currentWorkspace loads the current workspace from disk (direct mode).
+func (e *headlessEnv) currentWorkspace() (*workspace.Workspace, error) {
+	wm := workspace.NewManager(e.settings.GetConfigPath())
+	wsID, err := wm.GetCurrentWorkspaceID()
+	if err != nil || wsID == "" {
+		return nil, fmt.Errorf("no current workspace — open ClaudeFu once to create one")
+	}
+	return wm.LoadWorkspace(wsID)
+}
+
+// currentAgents loads the current workspace's agents from disk (direct mode).
+func (e *headlessEnv) currentAgents() ([]workspace.Agent, error) {
+	ws, err := e.currentWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	return ws.Agents, nil
+}
+
+// resolveFolder finds an agent's folder by slug (direct mode): the current
+// workspace first, then the global agent registry.
+func (e *headlessEnv) resolveFolder(slug string) (string, error) {
+	if agents, err := e.currentAgents(); err == nil {
+		for _, agent := range agents {
+			if strings.EqualFold(agent.GetSlug(), slug) {
+				return agent.Folder, nil
+			}
+		}
+	}
+	wm := workspace.NewManager(e.settings.GetConfigPath())
+	if info, folder := wm.FindAgentBySlug(slug); info != nil {
+		return folder, nil
+	}
+	return "", fmt.Errorf("agent %q not found", slug)
+}
+
+// runAgentsCommand: claudefu agents
+func runAgentsCommand(args []string) error {
+	fs := flag.NewFlagSet("agents", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, "Usage: claudefu agents") }
+	_ = fs.Parse(args)
+
+	env, err := newHeadlessEnv()
+	if err != nil {
+		return err
+	}
+
+	var agents []control.Agent
+	if env.client != nil {
+		if agents, err = env.client.Agents(); err != nil {
+			return err
+		}
+	} else {
+		wsAgents, err := env.currentAgents()
+		if err != nil {
+			return err
+		}
+		for _, agent := range wsAgents {
+			agents = append(agents, control.Agent{ID: agent.ID, Slug: agent.GetSlug(), Folder: agent.Folder})
+		}
+	}
+
+	tw := tabwriter.NewWriter(headlessOut, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SLUG\tFOLDER")
+	for _, agent := range agents {
+		fmt.Fprintf(tw, "%s\t%s\n", agent.Slug, agent.Folder)
+	}
+	return tw.Flush()
+}
+
+// runSessionsCommand: claudefu sessions --agent api [--limit 20]
+func runSessionsCommand(args []string) error {
+	fs := flag.NewFlagSet("sessions", flag.ExitOnError)
+	agentSlug := fs.String("agent", "", "Agent slug (required)")
+	limit := fs.Int("limit", 20, "Maximum sessions to list (0 = all)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claudefu sessions --agent <slug> [--limit N]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *agentSlug == "" {
+		fs.Usage()
+		return fmt.Errorf("--agent is required")
+	}
+
+	env, err := newHeadlessEnv()
+	if err != nil {
+		return err
+	}
+
+	var sessions []types.Session
+	if env.client != nil {
+		if sessions, err = env.client.Sessions(*agentSlug); err != nil {
+			return err
+		}
+	} else {
+		folder, err := env.resolveFolder(*agentSlug)
+		if err != nil {
+			return err
+		}
+		wsSessions, err := workspace.NewManager(env.settings.GetConfigPath()).GetSessions(folder)
+		if err != nil {
+			return err
+		}
+		for _, s := range wsSessions {
+			sessions = append(sessions, types.Session{
+				ID:           s.SessionID,
+				Preview:      s.Preview,
+				MessageCount: s.MessageCount,
+				UpdatedAt:    s.LastModified,
+			})
+		}
+	}
+
+	if *limit > 0 && len(sessions) > *limit {
+		sessions = sessions[:*limit]
+	}
+	tw := tabwriter.NewWriter(headlessOut, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION\tUPDATED\tMESSAGES\tPREVIEW")
+	for _, s := range sessions {
+		preview := strings.Join(strings.Fields(s.Preview), " ")
+		if len(preview) > 60 {
+			preview = preview[:57] + "..."
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", s.ID, s.UpdatedAt.Local().Format("2006-01-02 15:04"), s.MessageCount, preview)
+	}
+	return tw.Flush()
+}
+
+// runSendCommand: claudefu send --agent api [--session <id>] "message"
+// The message may also be piped on stdin ("-" or no message argument).
+func runSendCommand(args []string) error {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	agentSlug := fs.String("agent", "", "Agent slug (required)")
+	sessionID := fs.String("session", "", "Session ID to continue (default: start a new session)")
+	planMode := fs.Bool("plan", false, "Send in plan mode")
+	model := fs.String("model", "", "Model alias or ID passed to --model")
+	effort := fs.String("effort", "", "Effort level passed to --effort")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claudefu send --agent <slug> [--session <id>] [--plan] \"message\"")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *agentSlug == "" {
+		fs.Usage()
+		return fmt.Errorf("--agent is required")
+	}
+
+	message := strings.Join(fs.Args(), " ")
+	if message == "" || message == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading message from stdin: %w", err)
+		}
+		message = string(data)
+	}
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("message is required")
+	}
+
+	env, err := newHeadlessEnv()
+	if err != nil {
+		return err
+	}
+
+	req := control.SendRequest{
+		Agent:     *agentSlug,
+		SessionID: *sessionID,
+		Message:   message,
+		PlanMode:  *planMode,
+		Model:     *model,
+		Effort:    *effort,
+	}
+
+	var resp *control.SendResponse
+	if env.client != nil {
+		resp, err = env.client.Send(req)
+	} else {
+		resp, err = env.sendDirect(req)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "session: %s\n", resp.SessionID)
+	fmt.Fprintln(headlessOut, resp.Result)
+	return nil
+}
+
+// sendDirect spawns the CLI without a running instance (no MCP server, so no inter-agent tools).
+func (e *headlessEnv) sendDirect(req control.SendRequest) (*control.SendResponse, error) {
+	folder, err := e.resolveFolder(req.Agent)
+	if err != nil {
+		return nil, err
+	}
+
+	s := e.settings.GetSettings()
+	providers.SetClaudeCommand(s.ClaudeCodeCommand)
+	providers.SetDefaultCLIArgs(s.ClaudeExtraArgs)
+	providers.SetDangerousAcknowledgements(s.AcknowledgedDangerousPermissions)
+	if agents, err := e.currentAgents(); err == nil {
+		for _, agent := range agents {
+			providers.SetFolderCLIOverride(agent.Folder, agentCLIOverride(agent))
+		}
+	}
+	if providers.ProviderFor(folder) == providers.ProviderClaudeCode && providers.ClaudePathFor(folder) == "" {
+		return nil, fmt.Errorf("claude CLI not installed - please install Claude Code first")
+	}
+	claude := providers.NewClaudeCodeService(context.Background())
+	claude.SetEnvironment(s.ClaudeEnvVars)
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		if sessionID, err = session.NewService().CreateSession(folder); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := claude.SendMessageWithResult(folder, sessionID, req.Message, nil, req.PlanMode, req.Model, req.Effort)
+	if err != nil {
+		return nil, err
+	}
+	return &control.SendResponse{SessionID: sessionID, Result: result}, nil
+}
+
+// runMCPStdioCommand: claudefu mcp-stdio
+// Serves the ClaudeFu MCP tools over stdin/stdout for clients without SSE support.
+// Needs a running instance: messages are relayed to its MCP server.
+func runMCPStdioCommand(args []string) error {
+	fs := flag.NewFlagSet("mcp-stdio", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claudefu mcp-stdio")
+		fmt.Fprintln(os.Stderr, "Relays MCP JSON-RPC on stdin/stdout to the running ClaudeFu instance.")
+	}
+	_ = fs.Parse(args)
+
+	env, err := newHeadlessEnv()
+	if err != nil {
+		return err
+	}
+	if env.client == nil {
+		return fmt.Errorf("%w - start ClaudeFu with MCP enabled first", control.ErrNotRunning)
+	}
+
+	fmt.Fprintln(os.Stderr, "[mcp-stdio] Relaying MCP messages to the running ClaudeFu instance")
+	return mcpserver.ServeStdio(context.Background(), os.Stdin, headlessOut, func(_ context.Context, message json.RawMessage) (json.RawMessage, error) {
+		return env.client.MCP(message)
+	})
+}
+
+// runDoctorCommand: claudefu doctor [--json]
+// Prints the same self-diagnostics report as App.RunDiagnostics. Exits non-zero
+// if any check failed.
+func runDoctorCommand(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claudefu doctor [--json]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	env, err := newHeadlessEnv()
+	if err != nil {
+		return err
+	}
+	ws, err := env.currentWorkspace()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v - skipping agent checks\n", err)
+	}
+	opts := diagnosticsOptions(ws)
+	opts.MCPRunning = env.client != nil
+	report := diagnostics.Run(opts)
+
+	if *asJSON {
+		enc := json.NewEncoder(headlessOut)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if err := report.WriteText(headlessOut); err != nil {
+		return err
+	}
+	if !report.OK {
+		return fmt.Errorf("%d diagnostic check(s) failed", report.Failures)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+// Package audit keeps a chronological activity timeline of the workspace:
+// messages sent, sessions created, MCP tool calls, permission decisions, and
+// inter-agent messages and broadcasts. Entries are derived from the same event
+// envelopes the frontend receives and are stored in SQLite at
+// {configPath}/local/audit.db (per-machine data).
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"claudefu/internal/types"
+)
+
+// DBFile is the timeline database name under {configPath}/local/.
+const DBFile = "audit.db"
+
+// Activity types
+const (
+	TypeMessageSent    = "message_sent"    // User sent a message to a session
+	TypeSessionCreated = "session_created" // New session file appeared
+	TypeToolCall       = "tool_call"       // MCP tool called by an agent
+	TypeAgentMessage   = "agent_message"   // AgentMessage to another agent's inbox
+	TypeBroadcast      = "broadcast"       // AgentBroadcast to all agents
+	TypePermission     = "permission"      // Permission request answered
+	TypeQuestion       = "question"        // AskUserQuestion asked
+	TypeNotification   = "notification"    // NotifyUser
+	TypeAutoDispatch   = "auto_dispatch"   // Inbox messages sent to an idle agent automatically
+	TypeEnvProfile     = "env_profile"     // Workspace environment profile switched
+)
+
+// retention is how long entries are kept; older ones are pruned on Open.
+const retention = 90 * 24 * time.Hour
+
+// maxSummary bounds the stored summary text (in runes).
+const maxSummary = 500
+
+// Activity is one timeline entry.
+type Activity struct {
+	ID          int64          `json:"id"`
+	Time        time.Time      `json:"time"`
+	Type        string         `json:"type"`
+	WorkspaceID string         `json:"workspaceId,omitempty"`
+	AgentID     string         `json:"agentId,omitempty"`
+	AgentSlug   string         `json:"agentSlug,omitempty"`
+	SessionID   string         `json:"sessionId,omitempty"`
+	Summary     string         `json:"summary"`
+	Details     map[string]any `json:"details,omitempty"`
+}
+
+// Filter selects timeline entries. Zero values match everything.
+type Filter struct {
+	AgentID     string    `json:"agentId,omitempty"`
+	AgentSlug   string    `json:"agentSlug,omitempty"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	SessionID   string    `json:"sessionId,omitempty"`
+	Types       []string  `json:"types,omitempty"`
+	Since       time.Time `json:"since,omitempty"`
+	Until       time.Time `json:"until,omitempty"`
+	Offset      int       `json:"offset,omitempty"`
+	Limit       int       `json:"limit,omitempty"` // 0 = 100
+}
+
+// Page is a newest-first slice of the timeline.
+type Page struct {
+	Items []Activity `json:"items"`
+	Total int        `json:"total"` // Matching the filter
+}
+
+// Log is the activity timeline store. Safe for concurrent use.
+type Log struct {
+	db *sql.DB
+	mu sync.Mutex // Serializes writers (SQLite allows one at a time)
+}
+
+// Open opens or creates the timeline at {configPath}/local/audit.db and prunes
+// entries past the retention period.
+func Open(configPath string) (*Log, error) {
+	path := filepath.Join(configPath, "local", DBFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	schema := `
+		CREATE TABLE IF NOT EXISTS activity (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			workspace_id TEXT NOT NULL DEFAULT '',
+			agent_id TEXT NOT NULL DEFAULT '',
+			agent_slug TEXT NOT NULL DEFAULT '',
+			session_id TEXT NOT NULL DEFAULT '',
+			summary TEXT NOT NULL DEFAULT '',
+			details TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_activity_time ON activity(time);
+		CREATE INDEX IF NOT EXISTS idx_activity_agent ON activity(agent_id, time);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit schema: %w", err)
+	}
+	l := &Log{db: db}
+	l.prune(time.Now().Add(-retention))
+	return l, nil
+}
+
+// Close closes the database.
+func (l *Log) Close() error {
+	return l.db.Close()
+}
+
+// Add records an activity (Time defaults to now).
+func (l *Log) Add(a Activity) error {
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	details := ""
+	if len(a.Details) > 0 {
+		data, err := json.Marshal(a.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode activity details: %w", err)
+		}
+		details = string(data)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.db.Exec(`INSERT INTO activity (time, type, workspace_id, agent_id, agent_slug, session_id, summary, details)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Time.UnixMilli(), a.Type, a.WorkspaceID, a.AgentID, a.AgentSlug, a.SessionID, truncate(a.Summary), details)
+	if err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+	return nil
+}
+
+// Query returns the page of entries matching f, newest first.
+func (l *Log) Query(f Filter) (Page, error) {
+	var where []string
+	var args []any
+	add := func(clause string, values ...any) {
+		where = append(where, clause)
+		args = append(args, values...)
+	}
+	if f.AgentID != "" {
+		add("agent_id = ?", f.AgentID)
+	}
+	if f.AgentSlug != "" {
+		add("agent_slug = ?", f.AgentSlug)
+	}
+	if f.WorkspaceID != "" {
+		add("workspace_id = ?", f.WorkspaceID)
+	}
+	if f.SessionID != "" {
+		add("session_id = ?", f.SessionID)
+	}
+	if len(f.Types) > 0 {
+		marks := strings.TrimSuffix(strings.Repeat("?,", len(f.Types)), ",")
+		values := make([]any, len(f.Types))
+		for i, t := range f.Types {
+			values[i] = t
+		}
+		add("type IN ("+marks+")", values...)
+	}
+	if !f.Since.IsZero() {
+		add("time >= ?", f.Since.UnixMilli())
+	}
+	if !f.Until.IsZero() {
+		add("time < ?", f.Until.UnixMilli())
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	page := Page{Items: []Activity{}}
+	if err := l.db.QueryRow(`SELECT COUNT(*) FROM activity`+cond, args...).Scan(&page.Total); err != nil {
+		return page, fmt.Errorf("failed to query activity: %w", err)
+	}
+	rows, err := l.db.Query(`SELECT id, time, type, workspace_id, agent_id, agent_slug, session_id, summary, details
+		FROM activity`+cond+` ORDER BY time DESC, id DESC LIMIT ? OFFSET ?`, append(args, limit, max(f.Offset, 0))...)
+	if err != nil {
+		return page, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Activity
+		var millis int64
+		var details string
+		if err := rows.Scan(&a.ID, &millis, &a.Type, &a.WorkspaceID, &a.AgentID, &a.AgentSlug, &a.SessionID, &a.Summary, &details); err != nil {
+			return page, fmt.Errorf("failed to read activity: %w", err)
+		}
+		a.Time = time.UnixMilli(millis)
+		if details != "" {
+			json.Unmarshal([]byte(details), &a.Details)
+		}
+		page.Items = append(page.Items, a)
+	}
+	return page, rows.Err()
+}
+
+// prune deletes entries older than cutoff.
+func (l *Log) prune(cutoff time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.db.Exec(`DELETE FROM activity WHERE time < ?`, cutoff.UnixMilli())
+}
+
+// FromEnvelope derives a timeline entry from an emitted event. ok is false for
+// events that aren't part of the timeline. The caller fills in whichever of
+// AgentID and AgentSlug the event didn't carry.
+func FromEnvelope(env types.EventEnvelope) (a Activity, ok bool) {
+	payload, _ := env.Payload.(map[string]any)
+	str := func(key string) string {
+		s, _ := payload[key].(string)
+		return s
+	}
+
+	a = Activity{
+		Time:        time.Now(),
+		WorkspaceID: env.WorkspaceID,
+		AgentID:     env.AgentID,
+		SessionID:   env.SessionID,
+	}
+	switch env.EventType {
+	case "session:message-sent":
+		a.Type = TypeMessageSent
+		a.Summary = str("message")
+		a.Details = pick(payload, "attachments", "planMode", "model")
+	case "session:discovered":
+		a.Type = TypeSessionCreated
+		a.Summary = "Session created"
+	case "mcp:tool-call":
+		a.AgentSlug = str("fromAgent")
+		switch str("tool") {
+		case "AgentMessage":
+			a.Type = TypeAgentMessage
+			a.Summary = fmt.Sprintf("To %s: %s", str("targetAgent"), str("preview"))
+		case "AgentBroadcast":
+			a.Type = TypeBroadcast
+			a.Summary = str("preview")
+		default:
+			a.Type = TypeToolCall
+			a.Summary = strings.TrimSpace(str("tool") + " " + str("preview"))
+		}
+		a.Details = pick(payload, "tool", "targetAgent", "durationMs", "failed")
+	case "mcp:permission-request:answered":
+		a.Type = TypePermission
+		a.AgentSlug = str("agentSlug")
+		if granted, _ := payload["granted"].(bool); granted {
+			a.Summary = fmt.Sprintf("Granted %s (%s)", joinStrings(payload["permissions"]), str("scope"))
+		} else {
+			a.Summary = "Denied " + joinStrings(payload["requested"])
+			if reason := str("denyReason"); reason != "" {
+				a.Summary += ": " + reason
+			}
+		}
+		a.Details = pick(payload, "requestId", "requested", "permissions", "granted", "scope")
+	case "mcp:askuser":
+		a.Type = TypeQuestion
+		a.AgentSlug = str("agentSlug")
+		a.Summary = "Asked a question"
+	case "mcp:notification":
+		a.Type = TypeNotification
+		a.AgentSlug = str("from_agent")
+		a.Summary = strings.TrimSpace(str("title") + " " + str("message"))
+	case "inbox:auto-dispatch":
+		a.Type = TypeAutoDispatch
+		a.Summary = "Auto-responding to inbox messages from " + joinStrings(payload["from"])
+		a.Details = pick(payload, "messageIds", "from")
+	case "workspace:env-profile":
+		a.Type = TypeEnvProfile
+		previous, active := str("previousProfile"), str("activeProfile")
+		if previous == active {
+			a.Summary = fmt.Sprintf("Environment profile %s updated", profileName(active))
+		} else {
+			a.Summary = fmt.Sprintf("Environment profile: %s -> %s", profileName(previous), profileName(active))
+		}
+		a.Details = pick(payload, "previousProfile", "activeProfile")
+	default:
+		return Activity{}, false
+	}
+	return a, true
+}
+
+// profileName names an environment profile for a summary ("" = no profile).
+func profileName(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return name
+}
+
+// pick copies the given keys of a payload (nil if none are present).
+func pick(payload map[string]any, keys ...string) map[string]any {
+	var out map[string]any
+	for _, k := range keys {
+		if v, ok := payload[k]; ok {
+			if out == nil {
+				out = make(map[string]any, len(keys))
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// joinStrings joins a []string or []any payload value.
+func joinStrings(v any) string {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, ", ")
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
+}
+
+// truncate shortens a summary to maxSummary runes.
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxSummary {
+		return string(r[:maxSummary]) + "..."
+	}
+	return s
+}
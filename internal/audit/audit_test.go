@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"testing"
+
+	"claudefu/internal/types"
+)
+
+func TestFromEnvelope(t *testing.T) {
+	tests := []struct {
+		name        string
+		eventType   string
+		payload     map[string]any
+		wantOK      bool
+		wantType    string
+		wantSummary string
+	}{
+		{
+			name:        "message sent",
+			eventType:   "session:message-sent",
+			payload:     map[string]any{"message": "fix the build"},
+			wantOK:      true,
+			wantType:    TypeMessageSent,
+			wantSummary: "fix the build",
+		},
+		{
+			name:        "agent message",
+			eventType:   "mcp:tool-call",
+			payload:     map[string]any{"tool": "AgentMessage", "fromAgent": "api", "targetAgent": "web", "preview": "ready"},
+			wantOK:      true,
+			wantType:    TypeAgentMessage,
+			wantSummary: "To web: ready",
+		},
+		{
+			name:        "env profile switched",
+			eventType:   "workspace:env-profile",
+			payload:     map[string]any{"previousProfile": "dev", "activeProfile": "prod"},
+			wantOK:      true,
+			wantType:    TypeEnvProfile,
+			wantSummary: "Environment profile: dev -> prod",
+		},
+		{
+			name:        "env profile cleared",
+			eventType:   "workspace:env-profile",
+			payload:     map[string]any{"previousProfile": "prod", "activeProfile": ""},
+			wantOK:      true,
+			wantType:    TypeEnvProfile,
+			wantSummary: "Environment profile: prod -> (none)",
+		},
+		{
+			name:        "active env profile edited",
+			eventType:   "workspace:env-profile",
+			payload:     map[string]any{"previousProfile": "prod", "activeProfile": "prod"},
+			wantOK:      true,
+			wantType:    TypeEnvProfile,
+			wantSummary: "Environment profile prod updated",
+		},
+		{
+			name:      "untracked event",
+			eventType: "unread:changed",
+			payload:   map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, ok := FromEnvelope(types.EventEnvelope{WorkspaceID: "ws-1", EventType: tt.eventType, Payload: tt.payload})
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if a.Type != tt.wantType || a.Summary != tt.wantSummary {
+				t.Errorf("got (%q, %q), want (%q, %q)", a.Type, a.Summary, tt.wantType, tt.wantSummary)
+			}
+			if a.WorkspaceID != "ws-1" {
+				t.Errorf("WorkspaceID = %q, want ws-1", a.WorkspaceID)
+			}
+		})
+	}
+}
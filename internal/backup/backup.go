@@ -0,0 +1,396 @@
+// Package backup versions the ClaudeFu config directory (~/.claudefu) in a local
+// git repository. The repository lives in local/backup.git (per-machine, never
+// synced) and uses the config directory as its work tree, so ~/.claudefu itself
+// is not turned into a git checkout. Secrets and binary databases are excluded;
+// backlog databases are captured through JSON exports written before each snapshot.
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+)
+
+var logger = logging.For(logging.App)
+
+// DefaultInterval is how often the config directory is checked for changes.
+const DefaultInterval = 5 * time.Minute
+
+// ExportsDir is the config-relative directory exporters write into before a snapshot.
+const ExportsDir = "backup-exports"
+
+// excludes are written to the repository's info/exclude (gitignore syntax).
+var excludes = []string{
+	"/auth.json",    // Credentials
+	"/local/",       // Per-machine state, including this repository
+	"/proxy-logs/",  // Request logs (large, may contain prompts)
+	"/control.sock", // Headless control socket
+	"*.db",          // SQLite databases (exported as JSON instead)
+	"*.db-journal",
+	"*.db-wal",
+	"*.db-shm",
+	"*.sync-conflict-*",
+	".stfolder",
+	".stversions/",
+	".DS_Store",
+}
+
+// Exporter writes derived files (e.g. backlog JSON) into dir before a snapshot.
+type Exporter func(dir string) error
+
+// RestorePoint is one backup commit.
+type RestorePoint struct {
+	Hash    string   `json:"hash"`
+	Time    int64    `json:"time"` // Unix ms
+	Message string   `json:"message"`
+	Files   []string `json:"files"` // Config-relative paths changed in this commit
+}
+
+// Service snapshots the config directory into the backup repository.
+type Service struct {
+	configPath string
+	gitDir     string
+	exporter   Exporter
+	onSnapshot func(RestorePoint)
+
+	mu      sync.Mutex // Serializes git operations
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewService creates a backup service for configPath (~/.claudefu).
+func NewService(configPath string) *Service {
+	return &Service{
+		configPath: configPath,
+		gitDir:     filepath.Join(configPath, "local", "backup.git"),
+	}
+}
+
+// SetExporter sets the function that writes exports before each snapshot.
+func (s *Service) SetExporter(fn Exporter) {
+	s.exporter = fn
+}
+
+// SetOnSnapshot sets a callback invoked after each new restore point.
+func (s *Service) SetOnSnapshot(fn func(RestorePoint)) {
+	s.onSnapshot = fn
+}
+
+// IsAvailable reports whether the git binary can be found.
+func IsAvailable() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// Start initializes the repository if needed and snapshots every interval
+// (0 = DefaultInterval), taking an initial snapshot immediately.
+func (s *Service) Start(interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.running = true
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.Snapshot(""); err != nil {
+				logger.Warnf("Backup snapshot failed: %v", err)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops automatic snapshots and waits for an in-flight snapshot to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	stop, done := s.stop, s.done
+	s.running = false
+	s.mu.Unlock()
+
+	close(stop)
+	<-done
+}
+
+// IsRunning returns whether automatic snapshots are active.
+func (s *Service) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Snapshot commits the current state of the config directory. Returns nil
+// (and no error) when nothing changed since the last restore point.
+// An empty message uses a timestamped default.
+func (s *Service) Snapshot(message string) (*RestorePoint, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotLocked(message)
+}
+
+func (s *Service) snapshotLocked(message string) (*RestorePoint, error) {
+	if s.exporter != nil {
+		dir := filepath.Join(s.configPath, ExportsDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		if err := s.exporter(dir); err != nil {
+			logger.Warnf("Backup export failed: %v", err)
+		}
+	}
+
+	if _, err := s.git("add", "-A"); err != nil {
+		return nil, err
+	}
+	status, err := s.git("status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(status) == "" {
+		return nil, nil
+	}
+
+	if message == "" {
+		message = "Automatic backup " + time.Now().Format("2006-01-02 15:04:05")
+	}
+	if _, err := s.git("commit", "-q", "-m", message); err != nil {
+		return nil, err
+	}
+
+	points, err := s.list("HEAD", 1)
+	if err != nil || len(points) == 0 {
+		return nil, err
+	}
+	point := points[0]
+	if s.onSnapshot != nil {
+		s.onSnapshot(point)
+	}
+	return &point, nil
+}
+
+// ListRestorePoints returns restore points newest first. limit <= 0 returns all.
+func (s *Service) ListRestorePoints(limit int) ([]RestorePoint, error) {
+	if !s.initialized() {
+		return []RestorePoint{}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.git("rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return []RestorePoint{}, nil // No commits yet
+	}
+	return s.list("HEAD", limit)
+}
+
+// GetRestorePointDiff returns the patch a restore point introduced, optionally
+// limited to one config-relative file path.
+func (s *Service) GetRestorePointDiff(hash, path string) (string, error) {
+	if err := validHash(hash); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	args := []string{"show", "--format=", "--patch", hash}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	return s.git(args...)
+}
+
+// GetFileAt returns a config-relative file's content at a restore point.
+func (s *Service) GetFileAt(hash, path string) (string, error) {
+	if err := validHash(hash); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.git("show", hash+":"+filepath.ToSlash(path))
+}
+
+// Restore rewrites the config directory to match a restore point. The current
+// state is snapshotted first, and the restore itself is recorded as a new
+// restore point, so a restore can always be undone.
+func (s *Service) Restore(hash string) (*RestorePoint, error) {
+	if err := validHash(hash); err != nil {
+		return nil, err
+	}
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := s.git("rev-parse", "--verify", hash+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("restore point not found: %s", hash)
+	}
+	target = strings.TrimSpace(target)
+
+	if _, err := s.snapshotLocked("Before restore to " + shortHash(target)); err != nil {
+		return nil, err
+	}
+
+	// Files added since the target would survive a checkout; remove them first
+	added, err := s.git("diff", "--name-only", "--diff-filter=A", target, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range strings.Split(strings.TrimSpace(added), "\n") {
+		if rel == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.configPath, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if _, err := s.git("checkout", target, "--", "."); err != nil {
+		return nil, err
+	}
+	return s.snapshotLocked("Restore to " + shortHash(target))
+}
+
+// initialized reports whether the backup repository exists.
+func (s *Service) initialized() bool {
+	_, err := os.Stat(filepath.Join(s.gitDir, "HEAD"))
+	return err == nil
+}
+
+// init creates the backup repository and refreshes its exclude list.
+func (s *Service) init() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.initialized() {
+		if err := os.MkdirAll(filepath.Dir(s.gitDir), 0755); err != nil {
+			return err
+		}
+		if out, err := exec.Command("git", "init", "-q", "--bare", s.gitDir).CombinedOutput(); err != nil {
+			return fmt.Errorf("git init failed: %s", strings.TrimSpace(string(out)))
+		}
+		// A bare repo refuses work-tree commands; the work tree is passed per call
+		if _, err := s.git("config", "core.bare", "false"); err != nil {
+			return err
+		}
+		for _, kv := range [][2]string{{"user.name", "ClaudeFu Backup"}, {"user.email", "backup@claudefu.local"}, {"commit.gpgsign", "false"}} {
+			if _, err := s.git("config", kv[0], kv[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	excludePath := filepath.Join(s.gitDir, "info", "exclude")
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(excludePath, []byte(strings.Join(excludes, "\n")+"\n"), 0644)
+}
+
+// list returns up to limit commits reachable from rev, with changed files.
+func (s *Service) list(rev string, limit int) ([]RestorePoint, error) {
+	const sep = "\x1e" // Record separator between commits
+	args := []string{"log", "--format=" + sep + "%H%x00%ct%x00%s", "--name-only"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := s.git(append(args, rev)...)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []RestorePoint{}
+	for _, record := range strings.Split(out, sep) {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.SplitN(lines[0], "\x00", 3)
+		if len(header) != 3 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(header[1], 10, 64)
+		point := RestorePoint{Hash: header[0], Time: secs * 1000, Message: header[2], Files: []string{}}
+		for _, file := range lines[1:] {
+			if file = strings.TrimSpace(file); file != "" {
+				point.Files = append(point.Files, file)
+			}
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// git runs a git command against the backup repository with the config
+// directory as work tree. Caller must hold s.mu.
+func (s *Service) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"--git-dir=" + s.gitDir, "--work-tree=" + s.configPath}, args...)...)
+	cmd.Dir = s.configPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// validHash rejects anything that is not a plain hex commit hash, so user input
+// can never be interpreted as a git option or revision expression.
+func validHash(hash string) error {
+	if len(hash) < 4 || len(hash) > 64 {
+		return fmt.Errorf("invalid restore point: %q", hash)
+	}
+	for _, c := range hash {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return fmt.Errorf("invalid restore point: %q", hash)
+		}
+	}
+	return nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,113 @@
+// Package claudehome resolves Claude Code's on-disk layout (~/.claude) on every
+// platform. All session, project, and plan paths are built here so the
+// encoding rules live in one place.
+//
+//	{Dir}/projects/{EncodeProjectPath(folder)}/{sessionID}.jsonl
+//	{Dir}/projects/{EncodeProjectPath(folder)}/{sessionID}/subagents/{agentID}.jsonl
+//	{Dir}/plans/{slug}.md
+package claudehome
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ConfigDirEnv overrides the Claude config directory, as it does for the CLI itself.
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// HomeDir returns the user's home directory: $HOME on Unix, %USERPROFILE% on Windows.
+func HomeDir() string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		return home
+	}
+	if runtime.GOOS == "windows" {
+		if drive, path := os.Getenv("HOMEDRIVE"), os.Getenv("HOMEPATH"); drive != "" && path != "" {
+			return drive + path
+		}
+	}
+	return os.Getenv("HOME")
+}
+
+// Dir returns Claude Code's config directory ($CLAUDE_CONFIG_DIR, default ~/.claude).
+func Dir() string {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir
+	}
+	return filepath.Join(HomeDir(), ".claude")
+}
+
+// ProjectsDir returns the directory holding every project's session files.
+func ProjectsDir() string {
+	return filepath.Join(Dir(), "projects")
+}
+
+// PlansDir returns the directory where plan mode writes {slug}.md plans.
+func PlansDir() string {
+	return filepath.Join(Dir(), "plans")
+}
+
+// PlanPath returns the plan file for a session slug.
+func PlanPath(slug string) string {
+	return filepath.Join(PlansDir(), slug+".md")
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// EncodeProjectPath encodes a folder path like Claude Code does: every
+// non-alphanumeric character becomes "-". "/Users/foo/my.app" encodes to
+// "-Users-foo-my-app" and "C:\Users\foo" to "C--Users-foo". Windows drive
+// letters are upper-cased first to match the CLI's process.cwd().
+func EncodeProjectPath(folder string) string {
+	return nonAlphanumeric.ReplaceAllString(normalizeFolder(folder), "-")
+}
+
+// ProjectDir returns the session directory for an agent folder.
+func ProjectDir(folder string) string {
+	return filepath.Join(ProjectsDir(), EncodeProjectPath(folder))
+}
+
+// SessionPath returns the JSONL path for a session in an agent folder.
+func SessionPath(folder, sessionID string) string {
+	return filepath.Join(ProjectDir(folder), sessionID+".jsonl")
+}
+
+// ValidateSessionID rejects session IDs that could escape the project
+// directory ("../x", "a/b", ".hidden"), for IDs that come from callers such as
+// MCP tool arguments.
+func ValidateSessionID(sessionID string) error {
+	if sessionID == "" || sessionID != filepath.Base(sessionID) || strings.HasPrefix(sessionID, ".") {
+		return fmt.Errorf("invalid session ID: %q", sessionID)
+	}
+	return nil
+}
+
+// SubagentsDir returns the directory holding a session's subagent transcripts.
+func SubagentsDir(folder, sessionID string) string {
+	return filepath.Join(ProjectDir(folder), sessionID, "subagents")
+}
+
+// SameDir reports whether two directory paths refer to the same location,
+// ignoring case on Windows and macOS (case-insensitive by default).
+func SameDir(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// normalizeFolder cleans a folder path and upper-cases a Windows drive letter.
+func normalizeFolder(folder string) string {
+	if folder == "" {
+		return ""
+	}
+	folder = filepath.Clean(folder)
+	if len(folder) >= 2 && folder[1] == ':' {
+		folder = strings.ToUpper(folder[:1]) + folder[1:]
+	}
+	return folder
+}
@@ -0,0 +1,25 @@
+package claudehome
+
+import "testing"
+
+func TestValidateSessionID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{"agent-a1b2c3", true},
+		{"", false},
+		{"..", false},
+		{".hidden", false},
+		{"../other-project/session", false},
+		{"a/b", false},
+		{"/etc/passwd", false},
+	}
+	for _, tt := range tests {
+		err := ValidateSessionID(tt.id)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateSessionID(%q) error = %v, want valid=%v", tt.id, err, tt.valid)
+		}
+	}
+}
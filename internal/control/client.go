@@ -0,0 +1,108 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"claudefu/internal/types"
+)
+
+// ErrNotRunning means no ClaudeFu instance is listening on the control socket.
+var ErrNotRunning = errors.New("claudefu is not running")
+
+// Client talks to a running instance over the control socket.
+type Client struct {
+	http *http.Client
+}
+
+// Dial connects to the control socket at path, returning ErrNotRunning if
+// nothing is listening there.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	conn.Close()
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}
+	// No timeout: sends block until the CLI finishes responding
+	return &Client{http: &http.Client{Transport: transport}}, nil
+}
+
+// Agents lists the agents in the instance's current workspace.
+func (c *Client) Agents() ([]Agent, error) {
+	var agents []Agent
+	err := c.do(http.MethodGet, "/agents", nil, &agents)
+	return agents, err
+}
+
+// Sessions lists an agent's sessions, most recent first.
+func (c *Client) Sessions(agent string) ([]types.Session, error) {
+	var sessions []types.Session
+	err := c.do(http.MethodGet, "/sessions?agent="+url.QueryEscape(agent), nil, &sessions)
+	return sessions, err
+}
+
+// Send sends a message and waits for the response to complete.
+func (c *Client) Send(req SendRequest) (*SendResponse, error) {
+	var resp SendResponse
+	if err := c.do(http.MethodPost, "/send", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MCP forwards one MCP JSON-RPC message to the instance's MCP server and
+// returns its response ("null" for notifications).
+func (c *Client) MCP(message json.RawMessage) (json.RawMessage, error) {
+	var resp json.RawMessage
+	if err := c.do(http.MethodPost, "/mcp", message, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) do(method, path string, body, out any) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, "http://claudefu"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("control request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var eb errorBody
+		if err := json.NewDecoder(resp.Body).Decode(&eb); err == nil && eb.Error != "" {
+			return errors.New(eb.Error)
+		}
+		return fmt.Errorf("control request failed: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
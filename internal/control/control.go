@@ -0,0 +1,176 @@
+// Package control exposes a running ClaudeFu instance to the headless CLI
+// (`claudefu send`, `claudefu sessions`, ...) over a local unix socket.
+// The protocol is plain JSON over HTTP; only the local user can reach the socket.
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+	"claudefu/internal/types"
+)
+
+var logger = logging.For(logging.App)
+
+// SocketFileName is the control socket created in the config dir (~/.claudefu/).
+const SocketFileName = "control.sock"
+
+// SocketPath returns the control socket path for a config directory.
+func SocketPath(configPath string) string {
+	return filepath.Join(configPath, SocketFileName)
+}
+
+// Agent identifies an agent addressable from the CLI.
+type Agent struct {
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	Folder string `json:"folder"`
+}
+
+// SendRequest asks the instance to send a message to an agent's session.
+// An empty SessionID starts a new session.
+type SendRequest struct {
+	Agent     string `json:"agent"` // Slug (case-insensitive)
+	SessionID string `json:"sessionId,omitempty"`
+	Message   string `json:"message"`
+	PlanMode  bool   `json:"planMode,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Effort    string `json:"effort,omitempty"`
+}
+
+// SendResponse is the outcome of a completed send.
+type SendResponse struct {
+	SessionID string `json:"sessionId"`
+	Result    string `json:"result"` // Final response text from the CLI
+}
+
+// Handler is implemented by the App to serve control requests against the loaded workspace.
+type Handler interface {
+	ControlAgents() ([]Agent, error)
+	ControlSessions(agent string) ([]types.Session, error)
+	ControlSend(req SendRequest) (*SendResponse, error)
+	// ControlMCP handles one MCP JSON-RPC message for `claudefu mcp-stdio`.
+	// Returns nil for notifications.
+	ControlMCP(ctx context.Context, message json.RawMessage) (json.RawMessage, error)
+}
+
+// Service serves a Handler on the control socket.
+type Service struct {
+	path     string
+	handler  Handler
+	server   *http.Server
+	listener net.Listener
+	mu       sync.Mutex
+}
+
+// NewService creates a control service for the socket at path.
+func NewService(path string, handler Handler) *Service {
+	return &Service{path: path, handler: handler}
+}
+
+// Start listens on the control socket. A stale socket left by a crashed
+// instance is removed; a socket with a live listener means another instance
+// owns it and Start fails.
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return nil
+	}
+
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+			conn.Close()
+			return fmt.Errorf("control socket %s is in use by another instance", s.path)
+		}
+		_ = os.Remove(s.path)
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	_ = os.Chmod(s.path, 0600)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents", func(w http.ResponseWriter, r *http.Request) {
+		agents, err := s.handler.ControlAgents()
+		writeResponse(w, agents, err)
+	})
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := s.handler.ControlSessions(r.URL.Query().Get("agent"))
+		writeResponse(w, sessions, err)
+	})
+	mux.HandleFunc("POST /send", func(w http.ResponseWriter, r *http.Request) {
+		var req SendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(w, nil, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+		resp, err := s.handler.ControlSend(req)
+		writeResponse(w, resp, err)
+	})
+	mux.HandleFunc("POST /mcp", func(w http.ResponseWriter, r *http.Request) {
+		var message json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			writeResponse(w, nil, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+		// The request context ends if the stdio bridge goes away mid-call
+		resp, err := s.handler.ControlMCP(r.Context(), message)
+		writeResponse(w, resp, err)
+	})
+
+	s.server = &http.Server{Handler: mux}
+	s.listener = listener
+	server := s.server
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("Control server error: %v", err)
+		}
+	}()
+
+	logger.Infof("Control server listening on %s", s.path)
+	return nil
+}
+
+// Stop closes the control socket and removes the socket file.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+	_ = os.Remove(s.path)
+	s.server = nil
+	s.listener = nil
+}
+
+// errorBody is the JSON body of a failed request.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeResponse(w http.ResponseWriter, v any, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(errorBody{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
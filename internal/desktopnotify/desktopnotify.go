@@ -0,0 +1,64 @@
+// Package desktopnotify shows native OS notifications from the Go side, so
+// they appear even while the ClaudeFu window is minimized or hidden. It shells
+// out to the platform's own tool: osascript on macOS, notify-send on Linux and
+// a PowerShell toast on Windows.
+package desktopnotify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// AppName is shown as the notification source where the platform supports it.
+const AppName = "ClaudeFu"
+
+// Send shows a notification with title and message.
+func Send(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		path, err := exec.LookPath("notify-send")
+		if err != nil {
+			return fmt.Errorf("notify-send not found (install libnotify)")
+		}
+		// "--" so a title or message starting with "-" isn't read as an option
+		cmd = exec.Command(path, "--app-name="+AppName, "--", title, message)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript(title, message))
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop notification failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell literal.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// windowsToastScript builds a PowerShell script that shows a toast through
+// the WinRT ToastNotificationManager (no modules required).
+func windowsToastScript(title, message string) string {
+	return `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode(` + powerShellString(title) + `)) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode(` + powerShellString(message) + `)) | Out-Null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(` + powerShellString(AppName) + `).Show($toast)`
+}
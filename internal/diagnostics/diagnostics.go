@@ -0,0 +1,308 @@
+// Package diagnostics runs ClaudeFu's self-checks: the claude CLI, the MCP
+// server, each agent's sessions directory and permission files, file watch
+// usage against the OS limit, and the size of ~/.claude/projects. The GUI
+// exposes it as App.RunDiagnostics and the CLI as `claudefu doctor`.
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/permissions"
+	"claudefu/internal/providers"
+)
+
+// Check statuses
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+const (
+	mcpDialTimeout    = 2 * time.Second
+	watchWarnFraction = 0.8                     // Warn when watches reach this share of the OS limit
+	projectsWarnBytes = 10 * 1024 * 1024 * 1024 // Warn when ~/.claude/projects grows past 10 GiB
+)
+
+// Agent is an agent whose folder is checked.
+type Agent struct {
+	Slug   string
+	Folder string
+}
+
+// Options describes what to check.
+type Options struct {
+	MCPAddr     string // host:port of the MCP server ("" = MCP disabled)
+	MCPRunning  bool   // Whether this process started the MCP server (informational)
+	Agents      []Agent
+	WatchCount  int // Watches held by this process's session watcher (-1 = no watcher)
+	Permissions *permissions.Manager
+}
+
+// Check is the outcome of one check.
+type Check struct {
+	ID     string `json:"id"` // e.g. "claude_cli", "sessions_dir"
+	Name   string `json:"name"`
+	Agent  string `json:"agent,omitempty"` // Agent slug for per-agent checks
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the result of Run.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Checks      []Check   `json:"checks"`
+	OK          bool      `json:"ok"` // No check failed
+	Warnings    int       `json:"warnings"`
+	Failures    int       `json:"failures"`
+}
+
+func (r *Report) add(c Check) {
+	switch c.Status {
+	case StatusWarn:
+		r.Warnings++
+	case StatusFail:
+		r.Failures++
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+// Run performs every check.
+func Run(opts Options) Report {
+	report := Report{GeneratedAt: time.Now()}
+	report.add(checkClaudeCLI())
+	report.add(checkMCPServer(opts.MCPAddr, opts.MCPRunning))
+	for _, agent := range opts.Agents {
+		report.add(checkSessionsDir(agent))
+	}
+	report.add(checkWatches(opts.WatchCount))
+	for _, c := range checkPermissions(opts.Permissions, opts.Agents) {
+		report.add(c)
+	}
+	report.add(checkProjectsDiskUsage())
+	report.OK = report.Failures == 0
+	return report
+}
+
+// WriteText prints the report as a table followed by a summary line.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tCHECK\tDETAIL")
+	for _, c := range r.Checks {
+		name := c.Name
+		if c.Agent != "" {
+			name += " (" + c.Agent + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(c.Status), name, c.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d checks, %d warnings, %d failures\n", len(r.Checks), r.Warnings, r.Failures)
+	return err
+}
+
+func checkClaudeCLI() Check {
+	c := Check{ID: "claude_cli", Name: "Claude CLI"}
+	if !providers.IsClaudeInstalled() {
+		c.Status, c.Detail = StatusFail, "claude not found in PATH or common install locations"
+		return c
+	}
+	version, err := providers.GetClaudeVersion()
+	if err != nil {
+		c.Status, c.Detail = StatusWarn, fmt.Sprintf("found, but `claude --version` failed: %v", err)
+		return c
+	}
+	c.Status, c.Detail = StatusOK, version
+	return c
+}
+
+func checkMCPServer(addr string, running bool) Check {
+	c := Check{ID: "mcp_server", Name: "MCP server"}
+	if addr == "" {
+		c.Status, c.Detail = StatusSkip, "MCP is disabled for this workspace"
+		return c
+	}
+	conn, err := net.DialTimeout("tcp", addr, mcpDialTimeout)
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("nothing listening on %s", addr)
+		if !running {
+			c.Status = StatusWarn
+			c.Detail += " (is ClaudeFu running?)"
+		}
+		return c
+	}
+	conn.Close()
+	c.Status, c.Detail = StatusOK, "listening on "+addr
+	return c
+}
+
+func checkSessionsDir(agent Agent) Check {
+	c := Check{ID: "sessions_dir", Name: "Sessions directory", Agent: agent.Slug}
+	if _, err := os.Stat(agent.Folder); err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("agent folder %s is not accessible: %v", agent.Folder, err)
+		return c
+	}
+	dir := claudehome.ProjectDir(agent.Folder)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		c.Status, c.Detail = StatusWarn, dir+" does not exist yet (no sessions)"
+		return c
+	}
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("cannot read %s: %v", dir, err)
+		return c
+	}
+	sessions := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			sessions++
+		}
+	}
+	c.Status, c.Detail = StatusOK, fmt.Sprintf("%s (%d sessions)", dir, sessions)
+	return c
+}
+
+func checkWatches(own int) Check {
+	c := Check{ID: "file_watches", Name: "File watches"}
+	usage := osWatchUsage()
+	var parts []string
+	if own >= 0 {
+		parts = append(parts, fmt.Sprintf("%d held by ClaudeFu", own))
+	}
+	if usage.Used >= 0 {
+		parts = append(parts, fmt.Sprintf("%d used by this user", usage.Used))
+	}
+	if usage.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit %d (%s)", usage.Limit, usage.Source))
+	}
+	c.Detail = strings.Join(parts, ", ")
+
+	used := usage.Used
+	if used < 0 {
+		used = own
+	}
+	switch {
+	case usage.Limit <= 0 || used < 0:
+		c.Status = StatusSkip
+		if c.Detail == "" {
+			c.Detail = "watch usage is not available on this platform"
+		}
+	case used >= usage.Limit:
+		c.Status = StatusFail
+		c.Detail += " — limit reached, new sessions will not update live"
+	case float64(used) >= watchWarnFraction*float64(usage.Limit):
+		c.Status = StatusWarn
+		c.Detail += " — close to the limit"
+	default:
+		c.Status = StatusOK
+	}
+	return c
+}
+
+func checkPermissions(pm *permissions.Manager, agents []Agent) []Check {
+	var checks []Check
+	if pm != nil {
+		c := Check{ID: "permissions", Name: "Global permissions", Status: StatusOK, Detail: permissions.GlobalPermissionsFile}
+		if _, err := pm.LoadGlobalPermissions(); err != nil {
+			c.Status, c.Detail = StatusFail, err.Error()
+		}
+		checks = append(checks, c)
+	}
+	for _, agent := range agents {
+		c := Check{ID: "permissions", Name: "Permission files", Agent: agent.Slug, Status: StatusOK}
+		var problems, found []string
+		if pm != nil {
+			perms, err := pm.LoadAgentPermissions(agent.Folder)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", permissions.AgentPermissionsFile, err))
+			} else if perms != nil {
+				found = append(found, permissions.AgentPermissionsFile)
+			}
+		}
+		for _, name := range []string{"settings.json", "settings.local.json"} {
+			data, err := os.ReadFile(filepath.Join(agent.Folder, permissions.ClaudeSettingsDir, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err == nil && !json.Valid(data) {
+				err = fmt.Errorf("invalid JSON")
+			}
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			found = append(found, name)
+		}
+		switch {
+		case len(problems) > 0:
+			c.Status, c.Detail = StatusFail, strings.Join(problems, "; ")
+		case len(found) == 0:
+			c.Detail = "none (global permissions apply)"
+		default:
+			c.Detail = strings.Join(found, ", ") + " valid"
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func checkProjectsDiskUsage() Check {
+	c := Check{ID: "projects_disk_usage", Name: "~/.claude/projects size"}
+	dir := claudehome.ProjectsDir()
+	var total int64
+	files := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Unreadable subtree: count what we can
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+				files++
+			}
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		c.Status, c.Detail = StatusSkip, dir+" does not exist"
+		return c
+	}
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("cannot read %s: %v", dir, err)
+		return c
+	}
+	c.Status, c.Detail = StatusOK, fmt.Sprintf("%s in %d files", formatBytes(total), files)
+	if total > projectsWarnBytes {
+		c.Status = StatusWarn
+		c.Detail += " — consider archiving old sessions"
+	}
+	return c
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,109 @@
+package diagnostics
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// watchUsage is the OS-level file watch usage and limit.
+type watchUsage struct {
+	Used   int    // Watches in use by the current user (-1 = unknown)
+	Limit  int    // OS limit (0 = unknown)
+	Source string // Where Limit came from
+}
+
+// osWatchUsage reads watch usage for the platform's fsnotify backend:
+// inotify on Linux (per-user watch limit), kqueue on macOS (one file
+// descriptor per watch, bounded by the per-process file limit).
+func osWatchUsage() watchUsage {
+	switch runtime.GOOS {
+	case "linux":
+		return watchUsage{
+			Used:   countInotifyWatches(),
+			Limit:  readIntFile("/proc/sys/fs/inotify/max_user_watches"),
+			Source: "fs.inotify.max_user_watches",
+		}
+	case "darwin":
+		out, err := exec.Command("sysctl", "-n", "kern.maxfilesperproc").Output()
+		if err != nil {
+			return watchUsage{Used: -1}
+		}
+		limit, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+		return watchUsage{Used: -1, Limit: limit, Source: "kern.maxfilesperproc"}
+	}
+	return watchUsage{Used: -1}
+}
+
+// countInotifyWatches sums the inotify watches of the current user's
+// processes from /proc/<pid>/fdinfo. Processes we cannot inspect are skipped.
+func countInotifyWatches() int {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return -1
+	}
+	uid := os.Getuid()
+	total := 0
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil || !ownedBy(filepath.Join("/proc", proc.Name()), uid) {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || target != "anon_inode:inotify" {
+				continue
+			}
+			total += countFdinfoWatches(filepath.Join("/proc", proc.Name(), "fdinfo", fd.Name()))
+		}
+	}
+	return total
+}
+
+// ownedBy reports whether the /proc/<pid> status lists uid as the real user.
+func ownedBy(procDir string, uid int) bool {
+	data, err := os.ReadFile(filepath.Join(procDir, "status"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "Uid:" {
+			return fields[1] == strconv.Itoa(uid)
+		}
+	}
+	return false
+}
+
+// countFdinfoWatches counts the "inotify wd:" lines of one inotify instance.
+func countFdinfoWatches(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "inotify wd:") {
+			count++
+		}
+	}
+	return count
+}
+
+func readIntFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
@@ -0,0 +1,135 @@
+// Package events is ClaudeFu's in-process event bus. The workspace runtime,
+// file watcher, MCP server, Claude CLI service and app publish event envelopes
+// to it; the frontend (Wails), activity timeline, hooks, notifications,
+// integrations and metrics are subscribers, so a new consumer needs no changes
+// on the publishing side.
+package events
+
+import (
+	"slices"
+	"sync"
+
+	"claudefu/internal/logging"
+	"claudefu/internal/types"
+)
+
+var logger = logging.For(logging.App)
+
+// Event sources
+const (
+	SourceRuntime = "runtime" // Workspace runtime (session messages, status, unread, watcher-driven updates)
+	SourceWatcher = "watcher" // File watcher failures
+	SourceMCP     = "mcp"     // MCP server (tool calls, inbox, questions, permissions)
+	SourceClaude  = "claude"  // Claude CLI service (send queue, CLI commands, permission guardrails)
+	SourceApp     = "app"     // App bindings and services outside the runtime
+)
+
+// Event is an envelope published on the bus.
+type Event struct {
+	types.EventEnvelope
+	Source     string // One of the Source* constants
+	Background bool   // From a non-active workspace kept running in the background
+}
+
+// Handler receives published events.
+type Handler func(Event)
+
+// Filter selects the events a subscription receives.
+type Filter func(Event) bool
+
+// Foreground selects events of the active workspace.
+func Foreground() Filter {
+	return func(e Event) bool { return !e.Background }
+}
+
+// EventTypes selects events with one of the given types.
+func EventTypes(eventTypes ...string) Filter {
+	return func(e Event) bool { return slices.Contains(eventTypes, e.EventType) }
+}
+
+type subscription struct {
+	id      int
+	name    string
+	handler Handler
+	filters []Filter
+}
+
+// Bus delivers published events to its subscribers. Safe for concurrent use.
+type Bus struct {
+	mu     sync.Mutex
+	subs   []*subscription // Replaced, never modified, so Publish can iterate without the lock
+	nextID int
+}
+
+// NewBus creates an empty bus.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler for the events matching all filters (none = every
+// event). Handlers run synchronously on the publisher's goroutine in
+// subscription order, so they must not block; a handler that panics is logged
+// and skipped. name identifies the subscriber in logs. Returns a function
+// that removes the subscription.
+func (b *Bus) Subscribe(name string, handler Handler, filters ...Filter) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &subscription{id: b.nextID, name: name, handler: handler, filters: filters}
+	b.subs = append(slices.Clone(b.subs), sub)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(slices.Clone(b.subs), func(s *subscription) bool { return s.id == sub.id })
+	}
+}
+
+// Publish delivers an event to every matching subscriber.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	subs := b.subs
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		if sub.matches(e) {
+			sub.deliver(e)
+		}
+	}
+}
+
+// Publisher returns an emit function publishing envelopes under source, for
+// the packages that take one (runtime, MCP server).
+func (b *Bus) Publisher(source string) func(types.EventEnvelope) {
+	return func(envelope types.EventEnvelope) {
+		b.Publish(Event{EventEnvelope: envelope, Source: source})
+	}
+}
+
+// BackgroundPublisher is Publisher for a non-active workspace: envelopes are
+// stamped with its ID and published as Background events.
+func (b *Bus) BackgroundPublisher(source, workspaceID string) func(types.EventEnvelope) {
+	return func(envelope types.EventEnvelope) {
+		envelope.WorkspaceID = workspaceID
+		b.Publish(Event{EventEnvelope: envelope, Source: source, Background: true})
+	}
+}
+
+func (s *subscription) matches(e Event) bool {
+	for _, filter := range s.filters {
+		if !filter(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *subscription) deliver(e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Event subscriber %s panicked on %s: %v", s.name, e.EventType, r)
+		}
+	}()
+	s.handler(e)
+}
@@ -0,0 +1,255 @@
+// Package export renders Claude Code sessions as Markdown, HTML, or JSON.
+// tool_use blocks are paired with their tool_result (which Claude Code stores in
+// a later user line) so each tool call reads as one block.
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/types"
+)
+
+// Export formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// maxToolOutput caps each tool result in Markdown/HTML output (JSON keeps everything).
+const maxToolOutput = 20000
+
+// Meta describes the exported session.
+type Meta struct {
+	Title      string    `json:"title"`
+	AgentName  string    `json:"agentName,omitempty"`
+	Folder     string    `json:"folder"`
+	SessionID  string    `json:"sessionId"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// ToolCall is a tool_use block with its result.
+type ToolCall struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Input   any    `json:"input,omitempty"`
+	Result  string `json:"result,omitempty"`
+	IsError bool   `json:"isError,omitempty"`
+}
+
+// Entry is one rendered conversation turn.
+type Entry struct {
+	Role      string     `json:"role"` // user, assistant, compaction
+	Timestamp string     `json:"timestamp,omitempty"`
+	Text      string     `json:"text,omitempty"`
+	Thinking  []string   `json:"thinking,omitempty"`
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+	Images    int        `json:"images,omitempty"` // Image attachments (not embedded)
+}
+
+// Document is a session ready to render.
+type Document struct {
+	Meta    Meta    `json:"meta"`
+	Entries []Entry `json:"entries"`
+}
+
+// IsValidFormat reports whether format is supported.
+func IsValidFormat(format string) bool {
+	return format == FormatMarkdown || format == FormatHTML || format == FormatJSON
+}
+
+// FileExtension returns the file extension for a format (with the dot).
+func FileExtension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatJSON:
+		return ".json"
+	default:
+		return ".md"
+	}
+}
+
+// LoadMessages reads a session's messages from its JSONL file, including
+// tool_result carrier messages.
+func LoadMessages(folder, sessionID string) ([]types.Message, error) {
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(claudehome.SessionPath(folder, sessionID))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var messages []types.Message
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		classified, err := types.ClassifyJSONLEvent(line)
+		if err != nil {
+			continue
+		}
+		if msg := types.ConvertToMessage(classified); msg != nil {
+			messages = append(messages, *msg)
+		}
+	}
+	return messages, scanner.Err()
+}
+
+// BuildDocument pairs tool calls with their results and drops carrier messages.
+func BuildDocument(meta Meta, messages []types.Message) Document {
+	// Tool results live in later user/carrier messages; index them by tool_use ID
+	results := make(map[string]types.ContentBlock)
+	for _, msg := range messages {
+		for _, block := range msg.ContentBlocks {
+			if block.Type == "tool_result" && block.ToolUseID != "" {
+				results[block.ToolUseID] = block
+			}
+		}
+	}
+
+	doc := Document{Meta: meta, Entries: []Entry{}}
+	for _, msg := range messages {
+		if msg.Type == "tool_result_carrier" || msg.IsSynthetic {
+			continue
+		}
+		entry := Entry{Role: msg.Type, Timestamp: msg.Timestamp}
+		if msg.IsCompaction {
+			entry.Role = "compaction"
+		}
+
+		if len(msg.ContentBlocks) == 0 {
+			entry.Text = msg.Content
+		}
+		var text []string
+		for _, block := range msg.ContentBlocks {
+			switch block.Type {
+			case "text":
+				text = append(text, block.Text)
+			case "thinking":
+				if block.Thinking != "" {
+					entry.Thinking = append(entry.Thinking, block.Thinking)
+				}
+			case "image":
+				entry.Images++
+			case "tool_use":
+				call := ToolCall{ID: block.ID, Name: block.Name, Input: block.Input}
+				if result, ok := results[block.ID]; ok {
+					call.Result = ResultText(result.Content)
+					call.IsError = result.IsError
+				}
+				entry.ToolCalls = append(entry.ToolCalls, call)
+			}
+		}
+		if len(text) > 0 {
+			entry.Text = strings.Join(text, "\n\n")
+		}
+
+		if entry.Text == "" && len(entry.ToolCalls) == 0 && len(entry.Thinking) == 0 && entry.Images == 0 {
+			continue
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return doc
+}
+
+// Render renders a document in the given format.
+func Render(doc Document, format string) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return []byte(renderMarkdown(doc)), nil
+	case FormatHTML:
+		return []byte(renderHTML(doc)), nil
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q (use markdown, html, or json)", format)
+	}
+}
+
+// ExportSession loads a session and renders it.
+func ExportSession(meta Meta, format string) ([]byte, error) {
+	if !IsValidFormat(format) {
+		return nil, fmt.Errorf("unsupported export format: %q (use markdown, html, or json)", format)
+	}
+	messages, err := LoadMessages(meta.Folder, meta.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	if meta.ExportedAt.IsZero() {
+		meta.ExportedAt = time.Now()
+	}
+	return Render(BuildDocument(meta, messages), format)
+}
+
+// ResultText flattens tool_result content (a string or a list of text blocks).
+func ResultText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []any:
+		var parts []string
+		for _, item := range c {
+			block, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, ok := block["text"].(string); ok {
+				parts = append(parts, text)
+			} else if t, _ := block["type"].(string); t == "image" {
+				parts = append(parts, "[image]")
+			}
+		}
+		return strings.Join(parts, "\n")
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(c)
+		return string(data)
+	}
+}
+
+// inputJSON pretty-prints tool input.
+func inputJSON(input any) string {
+	if input == nil {
+		return ""
+	}
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return fmt.Sprint(input)
+	}
+	return string(data)
+}
+
+// truncate shortens long tool output for readable exports.
+func truncate(s string) string {
+	if len(s) <= maxToolOutput {
+		return s
+	}
+	return s[:maxToolOutput] + fmt.Sprintf("\n… (%d more bytes)", len(s)-maxToolOutput)
+}
+
+// roleLabel returns a heading for an entry's role.
+func roleLabel(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "compaction":
+		return "Context Compaction"
+	default:
+		return role
+	}
+}
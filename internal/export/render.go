@@ -0,0 +1,129 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// =============================================================================
+// MARKDOWN
+// =============================================================================
+
+func renderMarkdown(doc Document) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", doc.Meta.Title)
+	if doc.Meta.AgentName != "" {
+		fmt.Fprintf(&b, "- **Agent:** %s\n", doc.Meta.AgentName)
+	}
+	fmt.Fprintf(&b, "- **Folder:** `%s`\n", doc.Meta.Folder)
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", doc.Meta.SessionID)
+	fmt.Fprintf(&b, "- **Exported:** %s\n\n", doc.Meta.ExportedAt.Format("2006-01-02 15:04"))
+
+	for _, e := range doc.Entries {
+		fmt.Fprintf(&b, "---\n\n## %s", roleLabel(e.Role))
+		if e.Timestamp != "" {
+			fmt.Fprintf(&b, " · %s", e.Timestamp)
+		}
+		b.WriteString("\n\n")
+
+		for _, t := range e.Thinking {
+			fmt.Fprintf(&b, "<details><summary>Thinking</summary>\n\n%s\n\n</details>\n\n", t)
+		}
+		if e.Text != "" {
+			b.WriteString(e.Text)
+			b.WriteString("\n\n")
+		}
+		if e.Images > 0 {
+			fmt.Fprintf(&b, "_[%d image(s) attached]_\n\n", e.Images)
+		}
+		for _, call := range e.ToolCalls {
+			fmt.Fprintf(&b, "**Tool: %s**\n\n", call.Name)
+			if input := inputJSON(call.Input); input != "" {
+				fmt.Fprintf(&b, "%s\n\n", fence("json", input))
+			}
+			if call.Result != "" {
+				label := "Result"
+				if call.IsError {
+					label = "Error"
+				}
+				fmt.Fprintf(&b, "<details><summary>%s</summary>\n\n%s\n\n</details>\n\n", label, fence("", truncate(call.Result)))
+			}
+		}
+	}
+	return b.String()
+}
+
+// fence wraps text in a code fence longer than any backtick run inside it.
+func fence(lang, text string) string {
+	ticks := "```"
+	for strings.Contains(text, ticks) {
+		ticks += "`"
+	}
+	return ticks + lang + "\n" + text + "\n" + ticks
+}
+
+// =============================================================================
+// HTML
+// =============================================================================
+
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;max-width:960px;margin:2rem auto;padding:0 1rem;line-height:1.5;color:#222}
+.meta{color:#666;font-size:.9em}
+.entry{border-top:1px solid #ddd;padding:1rem 0}
+.role{font-weight:600;margin-bottom:.5rem}.role time{font-weight:400;color:#888;font-size:.85em;margin-left:.5rem}
+.user .role{color:#1a5fb4}.assistant .role{color:#26a269}.compaction .role{color:#986a44}
+.text{white-space:pre-wrap}
+pre{background:#f6f8fa;padding:.75rem;overflow-x:auto;font-size:.85em}
+details{margin:.5rem 0}summary{cursor:pointer;color:#555}
+.tool{border-left:3px solid #ccc;padding-left:.75rem;margin:.75rem 0}.tool.error{border-color:#c01c28}`
+
+func renderHTML(doc Document) string {
+	var b strings.Builder
+	esc := html.EscapeString
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title><style>%s</style></head><body>\n", esc(doc.Meta.Title), htmlStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<div class=\"meta\">", esc(doc.Meta.Title))
+	if doc.Meta.AgentName != "" {
+		fmt.Fprintf(&b, "Agent: %s · ", esc(doc.Meta.AgentName))
+	}
+	fmt.Fprintf(&b, "<code>%s</code> · Session <code>%s</code> · Exported %s</div>\n",
+		esc(doc.Meta.Folder), esc(doc.Meta.SessionID), doc.Meta.ExportedAt.Format("2006-01-02 15:04"))
+
+	for _, e := range doc.Entries {
+		fmt.Fprintf(&b, "<div class=\"entry %s\"><div class=\"role\">%s", esc(e.Role), esc(roleLabel(e.Role)))
+		if e.Timestamp != "" {
+			fmt.Fprintf(&b, "<time>%s</time>", esc(e.Timestamp))
+		}
+		b.WriteString("</div>\n")
+
+		for _, t := range e.Thinking {
+			fmt.Fprintf(&b, "<details><summary>Thinking</summary><div class=\"text\">%s</div></details>\n", esc(t))
+		}
+		if e.Text != "" {
+			fmt.Fprintf(&b, "<div class=\"text\">%s</div>\n", esc(e.Text))
+		}
+		if e.Images > 0 {
+			fmt.Fprintf(&b, "<p><em>[%d image(s) attached]</em></p>\n", e.Images)
+		}
+		for _, call := range e.ToolCalls {
+			class := "tool"
+			if call.IsError {
+				class += " error"
+			}
+			fmt.Fprintf(&b, "<div class=\"%s\"><strong>Tool: %s</strong>\n", class, esc(call.Name))
+			if input := inputJSON(call.Input); input != "" {
+				fmt.Fprintf(&b, "<pre>%s</pre>\n", esc(input))
+			}
+			if call.Result != "" {
+				label := "Result"
+				if call.IsError {
+					label = "Error"
+				}
+				fmt.Fprintf(&b, "<details><summary>%s</summary><pre>%s</pre></details>\n", label, esc(truncate(call.Result)))
+			}
+			b.WriteString("</div>\n")
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
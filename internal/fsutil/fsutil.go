@@ -0,0 +1,148 @@
+// Package fsutil provides crash-safe file writes for ClaudeFu's configuration
+// files. A write goes to a temp file in the same directory, is fsynced, and is
+// renamed over the target, so a crash leaves either the old or the new content.
+// Writers that keep a backup also preserve the previous content as {path}.bak,
+// which readers fall back to when the file no longer parses. ReadLinesFrom
+// tails append-only files such as session JSONL.
+package fsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"claudefu/internal/logging"
+)
+
+var logger = logging.For(logging.App)
+
+// BackupSuffix is appended to a file's path for its previous version.
+const BackupSuffix = ".bak"
+
+// WriteFileAtomic replaces path with data (temp file + fsync + rename).
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeAtomic(path, data, perm, false)
+}
+
+// WriteFileWithBackup is WriteFileAtomic that first keeps the current content
+// of path as path.bak.
+func WriteFileWithBackup(path string, data []byte, perm os.FileMode) error {
+	return writeAtomic(path, data, perm, true)
+}
+
+// ReadJSON unmarshals path into v, recovering from path.bak if path doesn't
+// parse (see ReadRecover). Read errors, including not-exist, are returned as is.
+func ReadJSON(path string, v any) error {
+	return ReadRecover(path, func(data []byte) error {
+		return json.Unmarshal(data, v)
+	})
+}
+
+// ReadRecover reads path and passes its content to parse. If parse fails and
+// path.bak parses, the backup is restored over path and nil is returned;
+// otherwise the original parse error is returned.
+func ReadRecover(path string, parse func(data []byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parseErr := parse(data)
+	if parseErr == nil {
+		return nil
+	}
+
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err != nil || parse(backup) != nil {
+		return parseErr
+	}
+	logger.Warnf("Recovered %s from backup (%v)", path, parseErr)
+	if err := writeAtomic(path, backup, fileMode(path), false); err != nil {
+		logger.Warnf("Failed to restore %s from backup: %v", path, err)
+	}
+	return nil
+}
+
+// writeAtomic writes data to a temp file next to path and renames it over path.
+func writeAtomic(path string, data []byte, perm os.FileMode, backup bool) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if backup {
+		if err := backupFile(path); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// backupFile makes path.bak a copy of path (a hard link where supported). A
+// missing path is not an error.
+func backupFile(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	bak := path + BackupSuffix
+	os.Remove(bak)
+	if err := os.Link(path, bak); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(bak, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// syncDir flushes a directory entry update (the rename). Unsupported on some
+// platforms, so errors are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// fileMode returns path's permission bits (0644 if it can't be read).
+func fileMode(path string) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
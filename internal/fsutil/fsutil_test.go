@@ -0,0 +1,76 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadJSONRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // "" = file missing
+		backup   string // "" = no backup
+		want     string
+		wantErr  bool
+		wantFile string // Content of the file afterwards
+	}{
+		{name: "valid", content: `{"name":"current"}`, backup: `{"name":"old"}`, want: "current", wantFile: `{"name":"current"}`},
+		{name: "corrupt with backup", content: `{"name":`, backup: `{"name":"old"}`, want: "old", wantFile: `{"name":"old"}`},
+		{name: "corrupt without backup", content: `{"name":`, wantErr: true, wantFile: `{"name":`},
+		{name: "corrupt backup", content: `{"name":`, backup: `not json`, wantErr: true, wantFile: `{"name":`},
+		{name: "missing file ignores backup", backup: `{"name":"old"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.backup != "" {
+				if err := os.WriteFile(path+BackupSuffix, []byte(tt.backup), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var v struct{ Name string }
+			err := ReadJSON(path, &v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadJSON error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && v.Name != tt.want {
+				t.Errorf("Name = %q, want %q", v.Name, tt.want)
+			}
+			if tt.content == "" {
+				if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("file was created (stat err %v)", err)
+				}
+				return
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantFile {
+				t.Errorf("file = %q, want %q", data, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestWriteFileWithBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workspace.json")
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileWithBackup(path, []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFileWithBackup(%q): %v", content, err)
+		}
+	}
+	for p, want := range map[string]string{path: "second", path + BackupSuffix: "first"} {
+		if data, err := os.ReadFile(p); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(p), data, err, want)
+		}
+	}
+}
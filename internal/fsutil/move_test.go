@@ -0,0 +1,66 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "session")
+	os.MkdirAll(filepath.Join(src, "subagents"), 0755)
+	files := map[string]string{
+		"tool-results.json":       `{"ok":true}`,
+		"subagents/agent-a.jsonl": "{}\n",
+		"subagents/agent-b.jsonl": "{}\n{}\n",
+	}
+	mtime := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mtime, mtime)
+	}
+	if err := os.Symlink("tool-results.json", filepath.Join(src, "latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "archived")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dst, name)
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, content)
+			continue
+		}
+		info, _ := os.Stat(path)
+		if !info.ModTime().Equal(mtime) || info.Mode().Perm() != 0600 {
+			t.Errorf("%s mtime %v mode %v; want %v 0600", name, info.ModTime(), info.Mode().Perm(), mtime)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "latest")); err != nil || link != "tool-results.json" {
+		t.Errorf("symlink = %q, %v", link, err)
+	}
+}
+
+func TestMoveSameFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")
+	if err := os.WriteFile(src, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Move(src, dst); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source still exists: %v", err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Errorf("destination missing: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package fsutil
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadLinesFrom calls fn for each complete line of path after offset (without
+// its line ending) and returns the offset just past the last complete line.
+// A partial trailing line (still being written) is left for the next call.
+// If fn returns an error, reading stops and the offset of that line's start
+// is returned with the error.
+func ReadLinesFrom(path string, offset int64, fn func(line string) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return offset, nil // EOF or a partial trailing line
+		}
+		if err := fn(strings.TrimRight(line, "\r\n")); err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+	}
+}
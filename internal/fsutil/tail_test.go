@@ -0,0 +1,54 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadLinesFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	content := "one\ntwo\r\nthree\npartial"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New("stop")
+
+	tests := []struct {
+		name       string
+		offset     int64
+		stopAt     string // fn returns an error on this line
+		wantLines  []string
+		wantOffset int64
+		wantErr    error
+	}{
+		{"from start", 0, "", []string{"one", "two", "three"}, 15, nil},
+		{"from middle", 4, "", []string{"two", "three"}, 15, nil},
+		{"nothing new", 15, "", nil, 15, nil},
+		{"callback error", 0, "two", []string{"one", "two"}, 4, stop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			offset, err := ReadLinesFrom(path, tt.offset, func(line string) error {
+				lines = append(lines, line)
+				if line == tt.stopAt {
+					return stop
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(lines, tt.wantLines) || offset != tt.wantOffset {
+				t.Errorf("lines = %q, offset %d; want %q, %d", lines, offset, tt.wantLines, tt.wantOffset)
+			}
+		})
+	}
+
+	if _, err := ReadLinesFrom(filepath.Join(t.TempDir(), "missing"), 0, func(string) error { return nil }); !os.IsNotExist(err) {
+		t.Errorf("missing file err = %v, want not-exist", err)
+	}
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+)
+
+var logger = logging.For(logging.App)
+
+const (
+	// DefaultPollInterval is how often Start re-reads every folder's status.
+	DefaultPollInterval = 30 * time.Second
+
+	// refreshDebounce coalesces bursts of RequestRefresh calls for one folder.
+	refreshDebounce = 750 * time.Millisecond
+
+	// recentCommits is how many commits each Status carries.
+	recentCommits = 5
+)
+
+// Service caches git status per folder, polls it periodically, and reports
+// folders whose status changed. Safe for concurrent use.
+type Service struct {
+	mu      sync.Mutex
+	cache   map[string]*Status
+	pending map[string]*time.Timer // folder -> debounced refresh
+
+	onChange func(*Status)
+
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewService creates a status service. onChange is called (from a background
+// goroutine) whenever a refreshed folder's status differs from the cached one.
+func NewService(onChange func(*Status)) *Service {
+	return &Service{
+		cache:    make(map[string]*Status),
+		pending:  make(map[string]*time.Timer),
+		onChange: onChange,
+	}
+}
+
+// Get returns the cached status for folder, reading it if the cache entry is
+// missing or older than maxAge.
+func (s *Service) Get(folder string, maxAge time.Duration) (*Status, error) {
+	s.mu.Lock()
+	cached := s.cache[folder]
+	s.mu.Unlock()
+	if cached != nil && time.Since(cached.UpdatedAt) < maxAge {
+		return cached, nil
+	}
+	return s.Refresh(folder)
+}
+
+// Refresh re-reads folder's status, updates the cache, and calls onChange if
+// it differs from the previous reading.
+func (s *Service) Refresh(folder string) (*Status, error) {
+	st, err := ReadStatus(folder, recentCommits)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	prev := s.cache[folder]
+	s.cache[folder] = st
+	s.mu.Unlock()
+
+	if s.onChange != nil && !st.equal(prev) {
+		s.onChange(st)
+	}
+	return st, nil
+}
+
+// RequestRefresh schedules a refresh of folder shortly, folding repeated
+// requests (e.g. several turns finishing at once) into a single git call.
+func (s *Service) RequestRefresh(folder string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.pending[folder]; ok {
+		t.Reset(refreshDebounce)
+		return
+	}
+	s.pending[folder] = time.AfterFunc(refreshDebounce, func() {
+		s.mu.Lock()
+		delete(s.pending, folder)
+		s.mu.Unlock()
+		if _, err := s.Refresh(folder); err != nil {
+			logger.Warnf("git status refresh failed for %s: %v", folder, err)
+		}
+	})
+}
+
+// Forget drops cached status for folders not in keep (e.g. after a workspace switch).
+func (s *Service) Forget(keep []string) {
+	keepSet := make(map[string]bool, len(keep))
+	for _, f := range keep {
+		keepSet[f] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for folder := range s.cache {
+		if !keepSet[folder] {
+			delete(s.cache, folder)
+		}
+	}
+}
+
+// Start polls the folders returned by folders every interval
+// (0 = DefaultPollInterval), starting immediately.
+func (s *Service) Start(interval time.Duration, folders func() []string) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.running = true
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for _, folder := range folders() {
+				if _, err := s.Refresh(folder); err != nil {
+					logger.Warnf("git status poll failed for %s: %v", folder, err)
+				}
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling, cancels pending refreshes, and waits for an in-flight poll to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	for folder, t := range s.pending {
+		t.Stop()
+		delete(s.pending, folder)
+	}
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	stop, done := s.stop, s.done
+	s.running = false
+	s.mu.Unlock()
+
+	close(stop)
+	<-done
+}
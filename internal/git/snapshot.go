@@ -0,0 +1,147 @@
+package git
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxPatchBytes caps a single file's patch in a FileDiff.
+const maxPatchBytes = 200 * 1024
+
+// FileDiff is one file changed between two snapshots.
+type FileDiff struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"oldPath,omitempty"` // Source path of a rename
+	Status    string `json:"status"`            // "A", "M", "D", "R", or "T"
+	Patch     string `json:"patch"`             // Unified diff ("" for binary files)
+	Binary    bool   `json:"binary,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Snapshot records the full working tree of the repository containing folder
+// (tracked and untracked files, honoring .gitignore) as a git tree object and
+// returns its hash. The real index, HEAD, and refs are untouched: a copy of the
+// index is used so unchanged files are not re-hashed.
+func Snapshot(folder string) (string, error) {
+	root, err := run(folder, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	indexPath, err := run(root, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp("", "claudefu-snapshot-index-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if src, err := os.Open(indexPath); err == nil {
+		_, err = io.Copy(tmp, src)
+		src.Close()
+		if err != nil {
+			tmp.Close()
+			return "", err
+		}
+	}
+	tmp.Close()
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpPath)
+	if _, err := runWithEnv(root, env, "add", "--all"); err != nil {
+		return "", err
+	}
+	tree, err := runWithEnv(root, env, "write-tree")
+	return strings.TrimSpace(tree), err
+}
+
+// ListFiles returns the files under dir that git would see (tracked and
+// untracked, honoring .gitignore), as paths relative to dir. Fails if dir is
+// not inside a repository.
+func ListFiles(dir string) ([]string, error) {
+	out, err := runRaw(dir, "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, filepath.FromSlash(f))
+		}
+	}
+	return files, nil
+}
+
+// DiffTrees returns per-file diffs between two snapshot trees, with renames
+// detected. Paths are relative to the repository root.
+func DiffTrees(folder, from, to string) ([]FileDiff, error) {
+	root, err := run(folder, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	out, err := runRaw(root, "diff", "--name-status", "-z", "-M", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDiff
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		d := FileDiff{Status: status[:1]}
+		if d.Status == "R" || d.Status == "C" {
+			if i+2 >= len(fields) {
+				break
+			}
+			d.OldPath, d.Path = fields[i+1], fields[i+2]
+			i += 2
+		} else {
+			d.Path = fields[i+1]
+			i++
+		}
+
+		paths := []string{d.Path}
+		if d.OldPath != "" {
+			paths = append(paths, d.OldPath)
+		}
+		patch, err := runRaw(root, append([]string{"diff", "--no-color", "--no-ext-diff", "-M", from, to, "--"}, paths...)...)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(patch, "\nBinary files ") {
+			d.Binary = true
+			patch = ""
+		}
+		if len(patch) > maxPatchBytes {
+			patch = patch[:maxPatchBytes]
+			d.Truncated = true
+		}
+		d.Patch = patch
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// RestorePath makes path (relative to the repository root) in the working tree
+// match tree: its content is written back, or the file is removed if tree does
+// not contain it. The index is untouched.
+func RestorePath(folder, tree, path string) error {
+	root, err := run(folder, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	if _, err := run(root, "cat-file", "-e", tree+":"+path); err != nil {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(path))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	_, err = run(root, "restore", "--source="+tree, "--worktree", "--", path)
+	return err
+}
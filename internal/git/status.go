@@ -0,0 +1,163 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileChange is one entry of `git status`: a path with its index and work tree
+// status codes (porcelain v1, e.g. "M", "A", "D", "R", "?").
+type FileChange struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"origPath,omitempty"` // Source path of a rename or copy
+	Index    string `json:"index"`              // Staged status ("" = unchanged)
+	WorkTree string `json:"workTree"`           // Unstaged status ("" = unchanged)
+}
+
+// Commit is a summary of one commit for the recent history list.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// Status is the git state of an agent folder.
+type Status struct {
+	Folder    string       `json:"folder"`
+	IsRepo    bool         `json:"isRepo"`
+	Branch    string       `json:"branch,omitempty"`   // Empty when HEAD is detached
+	Upstream  string       `json:"upstream,omitempty"` // e.g. "origin/main"
+	Ahead     int          `json:"ahead"`
+	Behind    int          `json:"behind"`
+	Dirty     bool         `json:"dirty"`
+	Files     []FileChange `json:"files,omitempty"`
+	Commits   []Commit     `json:"commits,omitempty"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// ReadStatus runs git in folder and returns its branch, changed files,
+// ahead/behind counts, and up to commitLimit recent commits.
+// A folder outside any repository returns a Status with IsRepo false.
+func ReadStatus(folder string, commitLimit int) (*Status, error) {
+	st := &Status{Folder: folder, UpdatedAt: time.Now()}
+
+	out, err := runRaw(folder, "status", "--porcelain=v1", "--branch", "-z", "--untracked-files=normal")
+	if err != nil {
+		if _, repoErr := run(folder, "rev-parse", "--git-dir"); repoErr != nil {
+			return st, nil
+		}
+		return nil, err
+	}
+	st.IsRepo = true
+	parseStatus(out, st)
+
+	if commitLimit > 0 {
+		// Fails on a repository without commits; the list just stays empty
+		if log, err := run(folder, "log", "-n", strconv.Itoa(commitLimit), "--format=%H%x1f%s%x1f%an%x1f%aI%x1e"); err == nil {
+			st.Commits = parseLog(log)
+		}
+	}
+	return st, nil
+}
+
+// parseStatus fills st from `git status --porcelain=v1 --branch -z` output.
+func parseStatus(out string, st *Status) {
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if strings.HasPrefix(entry, "## ") {
+			parseBranchHeader(strings.TrimPrefix(entry, "## "), st)
+			continue
+		}
+		if len(entry) < 4 {
+			continue
+		}
+		change := FileChange{
+			Path:     entry[3:],
+			Index:    strings.TrimSpace(entry[:1]),
+			WorkTree: strings.TrimSpace(entry[1:2]),
+		}
+		// Renames and copies are followed by their source path
+		if (entry[0] == 'R' || entry[0] == 'C') && i+1 < len(entries) {
+			i++
+			change.OrigPath = entries[i]
+		}
+		st.Files = append(st.Files, change)
+	}
+	st.Dirty = len(st.Files) > 0
+}
+
+// parseBranchHeader parses "main...origin/main [ahead 1, behind 2]",
+// "No commits yet on main", or "HEAD (no branch)".
+func parseBranchHeader(header string, st *Status) {
+	if rest, ok := strings.CutPrefix(header, "No commits yet on "); ok {
+		st.Branch = rest
+		return
+	}
+	if strings.HasPrefix(header, "HEAD (no branch)") {
+		return
+	}
+
+	head, counts, _ := strings.Cut(header, " [")
+	branch, upstream, _ := strings.Cut(head, "...")
+	st.Branch = branch
+	st.Upstream = upstream
+
+	for part := range strings.SplitSeq(strings.TrimSuffix(counts, "]"), ", ") {
+		key, value, ok := strings.Cut(part, " ")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(value)
+		switch key {
+		case "ahead":
+			st.Ahead = n
+		case "behind":
+			st.Behind = n
+		}
+	}
+}
+
+// parseLog parses `git log` output formatted as unit/record-separated fields.
+func parseLog(out string) []Commit {
+	var commits []Commit
+	for record := range strings.SplitSeq(out, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Subject: fields[1],
+			Author:  fields[2],
+			Date:    date,
+		})
+	}
+	return commits
+}
+
+// equal reports whether two statuses describe the same state (ignoring UpdatedAt).
+func (s *Status) equal(o *Status) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	if s.IsRepo != o.IsRepo || s.Branch != o.Branch || s.Upstream != o.Upstream ||
+		s.Ahead != o.Ahead || s.Behind != o.Behind ||
+		len(s.Files) != len(o.Files) || len(s.Commits) != len(o.Commits) {
+		return false
+	}
+	for i := range s.Files {
+		if s.Files[i] != o.Files[i] {
+			return false
+		}
+	}
+	for i := range s.Commits {
+		if s.Commits[i].Hash != o.Commits[i].Hash {
+			return false
+		}
+	}
+	return true
+}
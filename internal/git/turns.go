@@ -0,0 +1,212 @@
+package git
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Review states of a file in a turn diff.
+const (
+	ReviewPending  = "pending"
+	ReviewAccepted = "accepted"
+	ReviewReverted = "reverted"
+)
+
+// TurnFile is a file changed during a turn, with its review state.
+type TurnFile struct {
+	FileDiff
+	Review string `json:"review"`
+}
+
+// TurnDiff is what changed in an agent's repository during a session's last Claude turn.
+// Edits made by anyone else in the same repository during the turn are included.
+type TurnDiff struct {
+	SessionID  string     `json:"sessionId"`
+	StartedAt  time.Time  `json:"startedAt"`
+	EndedAt    time.Time  `json:"endedAt,omitzero"`
+	InProgress bool       `json:"inProgress"` // Diff is against the live working tree
+	Files      []TurnFile `json:"files"`
+}
+
+// turn holds the snapshots bracketing one Claude turn.
+type turn struct {
+	ready     chan struct{} // Closed once the start snapshot is taken (or failed)
+	startTree string
+	startErr  error
+	endTree   string
+	startedAt time.Time
+	endedAt   time.Time
+	review    map[string]string // path -> accepted/reverted
+}
+
+// TurnTracker snapshots an agent's working tree around each Claude turn and
+// keeps the last turn per session for review. State is in memory only.
+type TurnTracker struct {
+	mu    sync.Mutex
+	turns map[string]*turn // folder + "\x00" + sessionID
+}
+
+// NewTurnTracker creates an empty tracker.
+func NewTurnTracker() *TurnTracker {
+	return &TurnTracker{turns: make(map[string]*turn)}
+}
+
+func turnKey(folder, sessionID string) string {
+	return folder + "\x00" + sessionID
+}
+
+// Begin starts a turn, replacing the session's previous one. The start
+// snapshot runs in the background so a large repository doesn't hold up the
+// send (the CLI takes longer to start than the snapshot); End and Diff wait
+// for it. Folders outside a git repository record no turn.
+func (t *TurnTracker) Begin(folder, sessionID string) {
+	key := turnKey(folder, sessionID)
+	tr := &turn{
+		ready:     make(chan struct{}),
+		startedAt: time.Now(),
+		review:    make(map[string]string),
+	}
+	t.mu.Lock()
+	t.turns[key] = tr
+	t.mu.Unlock()
+
+	go func() {
+		defer close(tr.ready)
+		tree, err := Snapshot(folder)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		tr.startTree, tr.startErr = tree, err
+		if err != nil && t.turns[key] == tr {
+			delete(t.turns, key)
+		}
+	}()
+}
+
+// End snapshots folder's repository when the turn started by Begin finishes.
+func (t *TurnTracker) End(folder, sessionID string) error {
+	t.mu.Lock()
+	tr := t.turns[turnKey(folder, sessionID)]
+	t.mu.Unlock()
+	if tr == nil {
+		return nil
+	}
+	<-tr.ready
+	if tr.startErr != nil {
+		return nil // No start snapshot, nothing to compare against
+	}
+	tree, err := Snapshot(folder)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr.endTree = tree
+	tr.endedAt = time.Now()
+	return nil
+}
+
+// Diff returns the per-file changes of the session's last turn. While the turn
+// is still running it is compared against the current working tree.
+func (t *TurnTracker) Diff(folder, sessionID string) (*TurnDiff, error) {
+	t.mu.Lock()
+	tr := t.turns[turnKey(folder, sessionID)]
+	t.mu.Unlock()
+	if tr == nil {
+		return nil, fmt.Errorf("no recorded turn for session %s", sessionID)
+	}
+	<-tr.ready
+	t.mu.Lock()
+	startTree, endTree, startErr := tr.startTree, tr.endTree, tr.startErr
+	t.mu.Unlock()
+	if startErr != nil {
+		return nil, startErr
+	}
+
+	inProgress := endTree == ""
+	if inProgress {
+		var err error
+		if endTree, err = Snapshot(folder); err != nil {
+			return nil, err
+		}
+	}
+	diffs, err := DiffTrees(folder, startTree, endTree)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := &TurnDiff{
+		SessionID:  sessionID,
+		StartedAt:  tr.startedAt,
+		EndedAt:    tr.endedAt,
+		InProgress: inProgress,
+		Files:      make([]TurnFile, 0, len(diffs)),
+	}
+	for _, d := range diffs {
+		review := tr.review[d.Path]
+		if review == "" {
+			review = ReviewPending
+		}
+		result.Files = append(result.Files, TurnFile{FileDiff: d, Review: review})
+	}
+	return result, nil
+}
+
+// Accept marks a changed file as reviewed and kept. The working tree is untouched.
+func (t *TurnTracker) Accept(folder, sessionID, path string) error {
+	if _, err := t.findFile(folder, sessionID, path); err != nil {
+		return err
+	}
+	return t.setReview(folder, sessionID, path, ReviewAccepted)
+}
+
+// Revert restores a changed file to its content at the start of the turn
+// (removing it if the turn created it). For a rename the original path is
+// restored as well. Later edits to the file are lost.
+func (t *TurnTracker) Revert(folder, sessionID, path string) error {
+	file, err := t.findFile(folder, sessionID, path)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	startTree := t.turns[turnKey(folder, sessionID)].startTree
+	t.mu.Unlock()
+
+	if err := RestorePath(folder, startTree, file.Path); err != nil {
+		return err
+	}
+	if file.OldPath != "" {
+		if err := RestorePath(folder, startTree, file.OldPath); err != nil {
+			return err
+		}
+	}
+	return t.setReview(folder, sessionID, path, ReviewReverted)
+}
+
+// findFile returns path's entry in the session's turn diff.
+func (t *TurnTracker) findFile(folder, sessionID, path string) (*TurnFile, error) {
+	diff, err := t.Diff(folder, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range diff.Files {
+		if diff.Files[i].Path == path {
+			return &diff.Files[i], nil
+		}
+	}
+	return nil, fmt.Errorf("file not changed in last turn: %s", path)
+}
+
+func (t *TurnTracker) setReview(folder, sessionID, path, review string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr := t.turns[turnKey(folder, sessionID)]
+	if tr == nil {
+		return fmt.Errorf("no recorded turn for session %s", sessionID)
+	}
+	tr.review[path] = review
+	return nil
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestTurnTracker(t *testing.T) {
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Skipf("git init: %v %s", err, out)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	if out, err := exec.Command("git", "-C", repo, "add", "main.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v %s", err, out)
+	}
+
+	tracker := NewTurnTracker()
+	tracker.Begin(repo, "s1")
+	// Wait for the background start snapshot before the "turn" edits files
+	if _, err := tracker.Diff(repo, "s1"); err != nil {
+		t.Fatalf("Diff while in progress: %v", err)
+	}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("new.go", "package main\n")
+	if err := tracker.End(repo, "s1"); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+
+	diff, err := tracker.Diff(repo, "s1")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if diff.InProgress || len(diff.Files) != 2 {
+		t.Errorf("diff = inProgress %v, %d files; want finished, 2 files", diff.InProgress, len(diff.Files))
+	}
+
+	outside := t.TempDir()
+	tracker.Begin(outside, "s2")
+	if err := tracker.End(outside, "s2"); err != nil {
+		t.Errorf("End outside a repository: %v", err)
+	}
+	if _, err := tracker.Diff(outside, "s2"); err == nil {
+		t.Error("Diff outside a repository succeeded")
+	}
+}
@@ -0,0 +1,129 @@
+// Package git wraps the git CLI for agent folders: worktree management and status.
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// run executes git with args in dir and returns trimmed stdout.
+func run(dir string, args ...string) (string, error) {
+	out, err := runRaw(dir, args...)
+	return strings.TrimSpace(out), err
+}
+
+// runRaw executes git with args in dir and returns stdout unmodified.
+func runRaw(dir string, args ...string) (string, error) {
+	return runWithEnv(dir, nil, args...)
+}
+
+// runWithEnv executes git with args in dir and environment env (nil = inherit)
+// and returns stdout unmodified. On failure the error includes git's stderr.
+func runWithEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// MainWorktree returns the root of the main work tree that path's repository
+// belongs to. For a linked worktree this is the original checkout.
+func MainWorktree(path string) (string, error) {
+	commonDir, err := run(path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(filepath.Clean(commonDir)), nil
+}
+
+// RepoRoot returns the top level of the work tree containing path. It fails
+// if path is not inside a git repository.
+func RepoRoot(path string) (string, error) {
+	return run(path, "rev-parse", "--show-toplevel")
+}
+
+// IsLinkedWorktree reports whether path is inside a linked worktree
+// (created with `git worktree add`) rather than the main checkout.
+func IsLinkedWorktree(path string) bool {
+	gitDir, err := run(path, "rev-parse", "--path-format=absolute", "--git-dir")
+	if err != nil {
+		return false
+	}
+	commonDir, err := run(path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return false
+	}
+	return filepath.Clean(gitDir) != filepath.Clean(commonDir)
+}
+
+var unsafeBranchChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// WorktreePath returns the default folder for a branch's worktree: a sibling of
+// the repository named "<repo>-<branch>" (slashes in the branch become dashes).
+func WorktreePath(repoRoot, branch string) string {
+	name := strings.Trim(unsafeBranchChars.ReplaceAllString(branch, "-"), "-")
+	return filepath.Join(filepath.Dir(repoRoot), filepath.Base(repoRoot)+"-"+name)
+}
+
+// AddWorktree checks out branch into a new worktree of the repository at
+// repoPath and returns the worktree folder. An existing local branch is checked
+// out as is; otherwise a new branch is created from HEAD.
+func AddWorktree(repoPath, branch string) (string, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", fmt.Errorf("branch is required")
+	}
+	if _, err := run(repoPath, "check-ref-format", "--branch", branch); err != nil {
+		return "", fmt.Errorf("invalid branch name %q", branch)
+	}
+	root, err := MainWorktree(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	path := WorktreePath(root, branch)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("worktree folder already exists: %s", path)
+	}
+
+	args := []string{"worktree", "add", path, branch}
+	if _, err := run(root, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"worktree", "add", "-b", branch, path}
+	}
+	if _, err := run(root, args...); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RemoveWorktree deletes a linked worktree and prunes its administrative files.
+// Without force, git refuses when the worktree has uncommitted changes.
+// The branch itself is kept.
+func RemoveWorktree(path string, force bool) error {
+	if !IsLinkedWorktree(path) {
+		return fmt.Errorf("not a linked git worktree: %s", path)
+	}
+	root, err := MainWorktree(path)
+	if err != nil {
+		return err
+	}
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = []string{"worktree", "remove", "--force", path}
+	}
+	if _, err := run(root, args...); err != nil {
+		return err
+	}
+	_, err = run(root, "worktree", "prune")
+	return err
+}
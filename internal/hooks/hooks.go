@@ -0,0 +1,250 @@
+// Package hooks runs user-configured shell commands when ClaudeFu emits
+// events (assistant turns, MCP notifications, send failures, backlog status
+// changes, ...). Each matching hook gets the event envelope as JSON on stdin,
+// so desktop notifications, chat pings or CI triggers can be scripted without
+// modifying ClaudeFu. Hooks are configured in settings (Settings.Hooks).
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+	"claudefu/internal/types"
+)
+
+var logger = logging.For(logging.App)
+
+const (
+	defaultTimeout = 30 * time.Second
+	maxConcurrent  = 4    // Hook processes running at once; later events wait
+	maxQueued      = 256  // Hook runs waiting for a slot; beyond this they are dropped
+	outputLimit    = 4096 // Bytes of stdout/stderr kept in a Result
+)
+
+// Hook runs Command when an event matching one of Events is emitted.
+type Hook struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name,omitempty"`
+	Events         []string `json:"events"`                   // Event types; "*" matches all, "session:*" a prefix
+	Command        string   `json:"command"`                  // Run with /bin/sh -c (cmd /C on Windows) in the user's home dir; envelope JSON on stdin
+	Agents         []string `json:"agents,omitempty"`         // Agent IDs or slugs to limit to (empty = all)
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"` // Kill the command after this long (default: 30)
+	Disabled       bool     `json:"disabled,omitempty"`
+}
+
+// Matches reports whether the hook fires for eventType from the given agent.
+func (h Hook) Matches(eventType, agentID, agentSlug string) bool {
+	if h.Disabled || h.Command == "" {
+		return false
+	}
+	if len(h.Agents) > 0 && !slices.Contains(h.Agents, agentID) && (agentSlug == "" || !slices.Contains(h.Agents, agentSlug)) {
+		return false
+	}
+	for _, pattern := range h.Events {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that a hook can run.
+func (h Hook) Validate() error {
+	if strings.TrimSpace(h.Command) == "" {
+		return fmt.Errorf("hook %q has no command", h.label())
+	}
+	if len(h.Events) == 0 {
+		return fmt.Errorf("hook %q has no events", h.label())
+	}
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("hook %q has a negative timeout", h.label())
+	}
+	return nil
+}
+
+func (h Hook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.ID
+}
+
+// Result is the outcome of one hook run.
+type Result struct {
+	HookID     string    `json:"hookId"`
+	EventType  string    `json:"eventType"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	ExitCode   int       `json:"exitCode"` // -1 if the command could not start or was killed
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Runner dispatches events to the configured hooks.
+type Runner struct {
+	mu    sync.RWMutex
+	hooks []Hook
+	env   func() []string // Environment for hook processes (nil = inherit)
+	queue chan job        // Drained by maxConcurrent workers
+}
+
+// job is one queued hook run.
+type job struct {
+	hook      Hook
+	envelope  types.EventEnvelope
+	agentSlug string
+	input     []byte
+}
+
+// NewRunner creates a runner and starts its workers. env supplies the base
+// environment of hook processes (e.g. providers.BuildShellEnv, for the
+// user's full PATH).
+func NewRunner(env func() []string) *Runner {
+	r := &Runner{env: env, queue: make(chan job, maxQueued)}
+	for range maxConcurrent {
+		go r.worker()
+	}
+	return r
+}
+
+// worker runs queued hooks one at a time.
+func (r *Runner) worker() {
+	for j := range r.queue {
+		result := r.run(j.hook, j.envelope, j.agentSlug, j.input)
+		if result.Error != "" {
+			logger.Warnf("hooks: %q failed on %s: %s %s", j.hook.label(), j.envelope.EventType, result.Error, result.Stderr)
+		} else {
+			logger.Debugf("hooks: %q ran on %s in %dms", j.hook.label(), j.envelope.EventType, result.DurationMs)
+		}
+	}
+}
+
+// SetHooks replaces the configured hooks.
+func (r *Runner) SetHooks(hooks []Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = slices.Clone(hooks)
+}
+
+// Dispatch queues every hook matching envelope to run in the background.
+// agentSlug lets hooks filter by slug as well as agent ID. When the queue is
+// full (hooks slower than the events feeding them) the run is dropped.
+func (r *Runner) Dispatch(envelope types.EventEnvelope, agentSlug string) {
+	r.mu.RLock()
+	var matched []Hook
+	for _, hook := range r.hooks {
+		if hook.Matches(envelope.EventType, envelope.AgentID, agentSlug) {
+			matched = append(matched, hook)
+		}
+	}
+	r.mu.RUnlock()
+	if len(matched) == 0 {
+		return
+	}
+
+	input, err := json.Marshal(envelope)
+	if err != nil {
+		logger.Warnf("hooks: failed to marshal %s event: %v", envelope.EventType, err)
+		return
+	}
+	for _, hook := range matched {
+		select {
+		case r.queue <- job{hook: hook, envelope: envelope, agentSlug: agentSlug, input: input}:
+		default:
+			logger.Warnf("hooks: queue full, dropping %q for %s", hook.label(), envelope.EventType)
+		}
+	}
+}
+
+// Run executes one hook for envelope synchronously (used to test a hook).
+func (r *Runner) Run(hook Hook, envelope types.EventEnvelope, agentSlug string) (Result, error) {
+	if err := hook.Validate(); err != nil {
+		return Result{}, err
+	}
+	input, err := json.Marshal(envelope)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return r.run(hook, envelope, agentSlug, input), nil
+}
+
+// run executes hook with input on stdin. The event is also described in
+// CLAUDEFU_* env vars so simple scripts need not parse JSON.
+func (r *Runner) run(hook Hook, envelope types.EventEnvelope, agentSlug string, input []byte) Result {
+	timeout := defaultTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, hook.Command)
+	if home, err := os.UserHomeDir(); err == nil {
+		cmd.Dir = home
+	}
+	var env []string
+	if r.env != nil {
+		env = r.env()
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env,
+		"CLAUDEFU_EVENT="+envelope.EventType,
+		"CLAUDEFU_WORKSPACE_ID="+envelope.WorkspaceID,
+		"CLAUDEFU_AGENT_ID="+envelope.AgentID,
+		"CLAUDEFU_AGENT_SLUG="+agentSlug,
+		"CLAUDEFU_SESSION_ID="+envelope.SessionID,
+		"CLAUDEFU_HOOK_ID="+hook.ID,
+	)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	result := Result{HookID: hook.ID, EventType: envelope.EventType, StartedAt: time.Now(), ExitCode: -1}
+	err := cmd.Run()
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+	result.Stdout = tail(stdout.String())
+	result.Stderr = tail(stderr.String())
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// shellCommand runs command through the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// tail keeps the last outputLimit bytes of s.
+func tail(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > outputLimit {
+		return "..." + s[len(s)-outputLimit:]
+	}
+	return s
+}
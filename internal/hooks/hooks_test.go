@@ -0,0 +1,34 @@
+package hooks
+
+import "testing"
+
+func TestHookMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		hook      Hook
+		eventType string
+		agentID   string
+		agentSlug string
+		want      bool
+	}{
+		{"exact event", Hook{Command: "true", Events: []string{"session:turn"}}, "session:turn", "a1", "api", true},
+		{"other event", Hook{Command: "true", Events: []string{"session:turn"}}, "session:error", "a1", "api", false},
+		{"wildcard", Hook{Command: "true", Events: []string{"*"}}, "mcp:inbox", "a1", "api", true},
+		{"prefix", Hook{Command: "true", Events: []string{"session:*"}}, "session:turn", "a1", "api", true},
+		{"prefix mismatch", Hook{Command: "true", Events: []string{"session:*"}}, "mcp:inbox", "a1", "api", false},
+		{"any of several", Hook{Command: "true", Events: []string{"mcp:inbox", "session:turn"}}, "session:turn", "a1", "api", true},
+		{"agent by ID", Hook{Command: "true", Events: []string{"*"}, Agents: []string{"a1"}}, "session:turn", "a1", "api", true},
+		{"agent by slug", Hook{Command: "true", Events: []string{"*"}, Agents: []string{"api"}}, "session:turn", "a1", "api", true},
+		{"other agent", Hook{Command: "true", Events: []string{"*"}, Agents: []string{"web"}}, "session:turn", "a1", "api", false},
+		{"empty slug never matches", Hook{Command: "true", Events: []string{"*"}, Agents: []string{""}}, "session:turn", "a1", "", false},
+		{"disabled", Hook{Command: "true", Events: []string{"*"}, Disabled: true}, "session:turn", "a1", "api", false},
+		{"no command", Hook{Events: []string{"*"}}, "session:turn", "a1", "api", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hook.Matches(tt.eventType, tt.agentID, tt.agentSlug); got != tt.want {
+				t.Errorf("Matches(%q, %q, %q) = %v, want %v", tt.eventType, tt.agentID, tt.agentSlug, got, tt.want)
+			}
+		})
+	}
+}
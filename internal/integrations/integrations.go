@@ -0,0 +1,405 @@
+// Package integrations posts events that need the user (NotifyUser messages,
+// AskUserQuestion timeouts, plan review requests) to a workspace's Slack or
+// Discord channel via incoming webhooks. With a Slack bot token and signing
+// secret, simple yes/no questions are also posted through the Slack API and
+// can be answered by reacting to the message (see SlackEventsHandler).
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+	"claudefu/internal/types"
+)
+
+var logger = logging.For(logging.App)
+
+// Event kinds (values of SlackConfig.Events / DiscordConfig.Events)
+const (
+	EventNotification    = "notification"     // NotifyUser
+	EventQuestionTimeout = "question_timeout" // AskUserQuestion went unanswered
+	EventPlanReview      = "plan_review"      // ExitPlanMode is waiting for a review
+	EventQuestion        = "question"         // Yes/no AskUserQuestion, answerable by reaction (Slack bot only)
+)
+
+// DefaultEvents are posted when a target lists no events.
+var DefaultEvents = []string{EventNotification, EventQuestionTimeout, EventPlanReview, EventQuestion}
+
+const postTimeout = 10 * time.Second
+
+// Config is a workspace's integrations (Workspace.Integrations).
+type Config struct {
+	Slack   *SlackConfig   `json:"slack,omitempty"`
+	Discord *DiscordConfig `json:"discord,omitempty"`
+}
+
+// SlackConfig posts to Slack. WebhookURL alone is enough for one-way posts;
+// BotToken + Channel + SigningSecret additionally enable answering yes/no
+// questions with a ✅ or ❌ reaction.
+type SlackConfig struct {
+	WebhookURL    string   `json:"webhookUrl,omitempty"`
+	BotToken      string   `json:"botToken,omitempty"`      // xoxb- token with chat:write and reactions:read
+	Channel       string   `json:"channel,omitempty"`       // Channel ID the bot posts to
+	SigningSecret string   `json:"signingSecret,omitempty"` // Verifies requests to the events endpoint
+	Events        []string `json:"events,omitempty"`        // Event kinds to post (empty = DefaultEvents)
+	Disabled      bool     `json:"disabled,omitempty"`
+}
+
+// DiscordConfig posts to a Discord channel webhook.
+type DiscordConfig struct {
+	WebhookURL string   `json:"webhookUrl,omitempty"`
+	Events     []string `json:"events,omitempty"` // Event kinds to post (empty = DefaultEvents)
+	Disabled   bool     `json:"disabled,omitempty"`
+}
+
+// Validate checks the configured URLs and event kinds.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if s := c.Slack; s != nil {
+		if s.WebhookURL != "" && !strings.HasPrefix(s.WebhookURL, "https://") {
+			return fmt.Errorf("slack webhook URL must start with https://")
+		}
+		if s.BotToken != "" && s.Channel == "" {
+			return fmt.Errorf("slack bot token requires a channel")
+		}
+		if err := validateEvents(s.Events); err != nil {
+			return err
+		}
+	}
+	if d := c.Discord; d != nil {
+		if d.WebhookURL != "" && !strings.HasPrefix(d.WebhookURL, "https://") {
+			return fmt.Errorf("discord webhook URL must start with https://")
+		}
+		if err := validateEvents(d.Events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(DefaultEvents, event) {
+			return fmt.Errorf("unknown integration event %q", event)
+		}
+	}
+	return nil
+}
+
+func wants(events []string, kind string) bool {
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	return slices.Contains(events, kind)
+}
+
+// slackBot reports whether the bot API (and reaction answers) is configured.
+func (s *SlackConfig) slackBot() bool {
+	return s.BotToken != "" && s.Channel != ""
+}
+
+// postedQuestion is a yes/no question posted to Slack, keyed by channel/ts.
+type postedQuestion struct {
+	questionID string
+	question   string // Question text (the key of the answers map)
+	yes, no    string // Option labels
+}
+
+// Service posts events for the current workspace and answers questions from
+// Slack reactions.
+type Service struct {
+	mu        sync.Mutex
+	workspace string // Workspace name, shown in posts
+	cfg       Config
+	posted    map[string]postedQuestion // channel + "/" + ts → question
+	posting   map[string]bool           // Question IDs whose post is in flight
+	early     map[string]string         // channel + "/" + ts → reaction that beat its post being recorded
+	answer    func(questionID string, answers map[string]string) error
+	client    *http.Client
+}
+
+// NewService creates a service. answer resolves a pending AskUserQuestion
+// (e.g. the MCP server's PendingQuestionManager.Answer).
+func NewService(answer func(questionID string, answers map[string]string) error) *Service {
+	return &Service{
+		posted:  make(map[string]postedQuestion),
+		posting: make(map[string]bool),
+		early:   make(map[string]string),
+		answer:  answer,
+		client:  &http.Client{Timeout: postTimeout},
+	}
+}
+
+// SetConfig replaces the workspace name and integrations (nil = none).
+func (s *Service) SetConfig(workspaceName string, cfg *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workspace = workspaceName
+	s.cfg = Config{}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	clear(s.posted)
+	clear(s.posting)
+	clear(s.early)
+}
+
+func (s *Service) config() (string, Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.workspace, s.cfg
+}
+
+// HandleEvent posts an emitted event if a target wants it. Posting happens in
+// the background. Called for every envelope the MCP server emits.
+func (s *Service) HandleEvent(envelope types.EventEnvelope) {
+	payload, _ := envelope.Payload.(map[string]any)
+	str := func(key string) string {
+		v, _ := payload[key].(string)
+		return v
+	}
+
+	var kind, text string
+	switch envelope.EventType {
+	case "mcp:notification":
+		kind = EventNotification
+		from := str("from_agent")
+		if from == "" {
+			from = "Agent"
+		}
+		text = fmt.Sprintf("%s [%s]", from, str("type"))
+		if title := str("title"); title != "" {
+			text += " " + title
+		}
+		text += ": " + str("message")
+	case "mcp:askuser:timeout":
+		kind = EventQuestionTimeout
+		text = fmt.Sprintf("%s's question went unanswered after %s: %s", str("agentSlug"), str("timeout"), questionText(payload["questions"]))
+	case "mcp:planreview":
+		kind = EventPlanReview
+		text = fmt.Sprintf("%s is waiting for a plan review", str("agentSlug"))
+	case "mcp:askuser":
+		s.postQuestion(str("id"), str("agentSlug"), payload["questions"])
+		return
+	case "mcp:askuser:dismissed":
+		s.forgetQuestion(str("questionId"))
+		return
+	default:
+		return
+	}
+
+	workspaceName, cfg := s.config()
+	if workspaceName != "" {
+		text = "[" + workspaceName + "] " + text
+	}
+	if slack := cfg.Slack; slack != nil && !slack.Disabled && wants(slack.Events, kind) {
+		go s.logErr("slack", s.postSlack(*slack, text))
+	}
+	if discord := cfg.Discord; discord != nil && !discord.Disabled && discord.WebhookURL != "" && wants(discord.Events, kind) {
+		go s.logErr("discord", s.postJSON(discord.WebhookURL, "", map[string]any{"content": text}, nil))
+	}
+}
+
+// Test posts a sample message to target ("slack" or "discord") using cfg,
+// so a webhook can be checked before saving it.
+func (s *Service) Test(target string, cfg Config) error {
+	text := "Test message from ClaudeFu"
+	switch target {
+	case "slack":
+		if cfg.Slack == nil {
+			return fmt.Errorf("slack is not configured")
+		}
+		return s.postSlack(*cfg.Slack, text)
+	case "discord":
+		if cfg.Discord == nil || cfg.Discord.WebhookURL == "" {
+			return fmt.Errorf("discord webhook URL is not set")
+		}
+		return s.postJSON(cfg.Discord.WebhookURL, "", map[string]any{"content": text}, nil)
+	}
+	return fmt.Errorf("unknown integration %q", target)
+}
+
+func (s *Service) logErr(target string, err error) {
+	if err != nil {
+		logger.Warnf("integrations: %s post failed: %v", target, err)
+	}
+}
+
+// postSlack posts text via the bot API if configured, else the webhook.
+func (s *Service) postSlack(cfg SlackConfig, text string) error {
+	if cfg.slackBot() {
+		_, err := s.slackPostMessage(cfg, text, "")
+		return err
+	}
+	if cfg.WebhookURL == "" {
+		return fmt.Errorf("slack has neither a webhook URL nor a bot token")
+	}
+	return s.postJSON(cfg.WebhookURL, "", map[string]any{"text": text}, nil)
+}
+
+// postQuestion posts a yes/no question through the Slack bot so it can be
+// answered by reaction. Other questions are left to the app.
+func (s *Service) postQuestion(questionID, agentSlug string, questions any) {
+	workspaceName, cfg := s.config()
+	slack := cfg.Slack
+	if slack == nil || slack.Disabled || !slack.slackBot() || slack.SigningSecret == "" || !wants(slack.Events, EventQuestion) {
+		return
+	}
+	q, ok := yesNoQuestion(questions)
+	if !ok {
+		return
+	}
+	q.questionID = questionID
+	text := fmt.Sprintf("%s asks: %s\nReact with :white_check_mark: for *%s* or :x: for *%s*", agentSlug, q.question, q.yes, q.no)
+	if workspaceName != "" {
+		text = "[" + workspaceName + "] " + text
+	}
+
+	// Registered before posting so an answer arriving meanwhile (forgetQuestion)
+	// is not followed by a stale entry
+	s.mu.Lock()
+	s.posting[questionID] = true
+	s.mu.Unlock()
+
+	go func() {
+		ts, err := s.slackPostMessage(*slack, text, "")
+		key := slack.Channel + "/" + ts
+		s.mu.Lock()
+		open := s.posting[questionID]
+		delete(s.posting, questionID)
+		reaction, reacted := s.early[key]
+		delete(s.early, key)
+		if err == nil && open {
+			s.posted[key] = q
+		}
+		if len(s.posting) == 0 {
+			clear(s.early)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			s.logErr("slack", err)
+			return
+		}
+		if open && reacted {
+			s.handleReaction(*slack, slack.Channel, ts, reaction)
+		}
+	}()
+}
+
+// forgetQuestion stops tracking a question answered or dismissed elsewhere.
+func (s *Service) forgetQuestion(questionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.posting, questionID)
+	for key, q := range s.posted {
+		if q.questionID == questionID {
+			delete(s.posted, key)
+		}
+	}
+}
+
+// yesNoQuestion returns the question if questions is a single question whose
+// two options are yes and no (in either order, any case).
+func yesNoQuestion(questions any) (postedQuestion, bool) {
+	list, _ := questions.([]map[string]any)
+	if len(list) != 1 {
+		return postedQuestion{}, false
+	}
+	if multi, _ := list[0]["multiSelect"].(bool); multi {
+		return postedQuestion{}, false
+	}
+	text, _ := list[0]["question"].(string)
+	options, _ := list[0]["options"].([]any)
+	if text == "" || len(options) != 2 {
+		return postedQuestion{}, false
+	}
+	q := postedQuestion{question: text}
+	for _, option := range options {
+		opt, _ := option.(map[string]any)
+		label, _ := opt["label"].(string)
+		switch strings.ToLower(strings.TrimSpace(label)) {
+		case "yes":
+			q.yes = label
+		case "no":
+			q.no = label
+		}
+	}
+	return q, q.yes != "" && q.no != ""
+}
+
+// questionText returns the first question's text.
+func questionText(questions any) string {
+	list, _ := questions.([]map[string]any)
+	if len(list) == 0 {
+		return ""
+	}
+	text, _ := list[0]["question"].(string)
+	return text
+}
+
+// slackPostMessage posts text (in thread threadTS, if set) with chat.postMessage
+// and returns the message timestamp.
+func (s *Service) slackPostMessage(cfg SlackConfig, text, threadTS string) (string, error) {
+	body := map[string]any{"channel": cfg.Channel, "text": text}
+	if threadTS != "" {
+		body["thread_ts"] = threadTS
+	}
+	var reply struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := s.postJSON("https://slack.com/api/chat.postMessage", cfg.BotToken, body, &reply); err != nil {
+		return "", err
+	}
+	if !reply.OK {
+		return "", fmt.Errorf("chat.postMessage: %s", reply.Error)
+	}
+	return reply.TS, nil
+}
+
+// postJSON POSTs body to url (with a bearer token if set) and decodes the
+// response into reply if non-nil.
+func (s *Service) postJSON(url, token string, body any, reply any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), postTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if reply != nil {
+		if err := json.Unmarshal(respBody, reply); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
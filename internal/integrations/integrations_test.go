@@ -0,0 +1,120 @@
+package integrations
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingTransport answers Slack API calls, holding the first one until
+// release is closed.
+type blockingTransport struct {
+	started chan struct{}
+	release chan struct{}
+	reply   string
+	calls   int
+}
+
+func (b *blockingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	b.calls++
+	if b.calls == 1 {
+		close(b.started)
+		<-b.release
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(b.reply)),
+		Request:    r,
+	}, nil
+}
+
+// TestPostQuestionRace covers answers and reactions that arrive while a
+// question's Slack post is still in flight.
+func TestPostQuestionRace(t *testing.T) {
+	questions := []map[string]any{{
+		"question": "Deploy?",
+		"options":  []any{map[string]any{"label": "Yes"}, map[string]any{"label": "No"}},
+	}}
+	slack := &SlackConfig{BotToken: "xoxb-1", Channel: "C1", SigningSecret: "s"}
+
+	tests := []struct {
+		name       string
+		reply      string
+		duringPost func(s *Service)
+		wantAnswer string // "" = question not answered from Slack
+		wantPosted int
+	}{
+		{
+			name:       "posted",
+			reply:      `{"ok":true,"ts":"1.1"}`,
+			duringPost: func(s *Service) {},
+			wantPosted: 1,
+		},
+		{
+			name:       "answered elsewhere while posting",
+			reply:      `{"ok":true,"ts":"1.1"}`,
+			duringPost: func(s *Service) { s.forgetQuestion("q1") },
+		},
+		{
+			name:       "reaction before the post is recorded",
+			reply:      `{"ok":true,"ts":"1.1"}`,
+			duringPost: func(s *Service) { s.handleReaction(*slack, "C1", "1.1", "white_check_mark") },
+			wantAnswer: "Yes",
+		},
+		{
+			name:       "post fails",
+			reply:      `{"ok":false,"error":"channel_not_found"}`,
+			duringPost: func(s *Service) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answered := make(chan string, 1)
+			s := NewService(func(questionID string, answers map[string]string) error {
+				answered <- answers["Deploy?"]
+				return nil
+			})
+			transport := &blockingTransport{started: make(chan struct{}), release: make(chan struct{}), reply: tt.reply}
+			s.client.Transport = transport
+			s.SetConfig("", &Config{Slack: slack})
+
+			s.postQuestion("q1", "api", questions)
+			<-transport.started
+			tt.duringPost(s)
+			close(transport.release)
+
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				s.mu.Lock()
+				inFlight := len(s.posting)
+				s.mu.Unlock()
+				if inFlight == 0 || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			var got string
+			if tt.wantAnswer != "" {
+				select {
+				case got = <-answered:
+				case <-time.After(2 * time.Second):
+				}
+			}
+			if got != tt.wantAnswer {
+				t.Errorf("answer = %q, want %q", got, tt.wantAnswer)
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if len(s.posting) != 0 || len(s.early) != 0 {
+				t.Errorf("left posting=%v early=%v", s.posting, s.early)
+			}
+			if len(s.posted) != tt.wantPosted {
+				t.Errorf("posted = %v, want %d entries", s.posted, tt.wantPosted)
+			}
+		})
+	}
+}
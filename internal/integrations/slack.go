@@ -0,0 +1,139 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SlackEventsPath is where the MCP server serves the Slack Events API
+// endpoint. Slack must reach it, so the MCP port has to be exposed (e.g.
+// through a tunnel) and its URL set as the app's Event Subscriptions
+// request URL, subscribed to reaction_added.
+const SlackEventsPath = "/integrations/slack/events"
+
+// slackMaxClockSkew rejects replayed requests (Slack recommends 5 minutes).
+const slackMaxClockSkew = 5 * time.Minute
+
+// Reactions that answer a yes/no question
+var (
+	slackYesReactions = map[string]bool{"white_check_mark": true, "heavy_check_mark": true, "+1": true, "thumbsup": true}
+	slackNoReactions  = map[string]bool{"x": true, "negative_squared_cross_mark": true, "-1": true, "thumbsdown": true}
+)
+
+// slackEvent is the body of an Events API request.
+type slackEvent struct {
+	Type      string `json:"type"` // url_verification or event_callback
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type     string `json:"type"` // reaction_added
+		User     string `json:"user"`
+		Reaction string `json:"reaction"`
+		Item     struct {
+			Type    string `json:"type"`
+			Channel string `json:"channel"`
+			TS      string `json:"ts"`
+		} `json:"item"`
+	} `json:"event"`
+}
+
+// SlackEventsHandler serves the Slack Events API: it answers the URL
+// verification challenge and resolves posted yes/no questions from
+// reaction_added events. Requests must carry a valid Slack signature.
+func (s *Service) SlackEventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		_, cfg := s.config()
+		if cfg.Slack == nil || cfg.Slack.Disabled || cfg.Slack.SigningSecret == "" {
+			http.Error(w, "slack integration not configured", http.StatusNotFound)
+			return
+		}
+		if !verifySlackSignature(cfg.Slack.SigningSecret, r.Header, body, time.Now()) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var event slackEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if event.Type == "url_verification" {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(event.Challenge))
+			return
+		}
+		// Acknowledge first: Slack retries anything slower than 3 seconds
+		w.WriteHeader(http.StatusOK)
+		if event.Type == "event_callback" && event.Event.Type == "reaction_added" && event.Event.Item.Type == "message" {
+			go s.handleReaction(*cfg.Slack, event.Event.Item.Channel, event.Event.Item.TS, event.Event.Reaction)
+		}
+	})
+}
+
+// handleReaction answers the question posted as channel/ts if reaction is a yes or no.
+func (s *Service) handleReaction(cfg SlackConfig, channel, ts, reaction string) {
+	var answer string
+	key := channel + "/" + ts
+	s.mu.Lock()
+	q, ok := s.posted[key]
+	if ok {
+		switch {
+		case slackYesReactions[reaction]:
+			answer = q.yes
+		case slackNoReactions[reaction]:
+			answer = q.no
+		}
+		if answer != "" {
+			delete(s.posted, key)
+		}
+	} else if len(s.posting) > 0 && (slackYesReactions[reaction] || slackNoReactions[reaction]) {
+		// The message may be a question whose post hasn't been recorded yet;
+		// postQuestion replays the reaction once it is
+		s.early[key] = reaction
+	}
+	s.mu.Unlock()
+	if answer == "" {
+		return
+	}
+
+	if err := s.answer(q.questionID, map[string]string{q.question: answer}); err != nil {
+		logger.Warnf("integrations: failed to answer question %s from Slack: %v", q.questionID, err)
+		return
+	}
+	logger.Infof("integrations: question %s answered %q from Slack", q.questionID, answer)
+	if _, err := s.slackPostMessage(cfg, "Answered: "+answer, ts); err != nil {
+		s.logErr("slack", err)
+	}
+}
+
+// verifySlackSignature checks the X-Slack-Signature HMAC of a request body.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) bool {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > slackMaxClockSkew || skew < -slackMaxClockSkew {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature")))
+}
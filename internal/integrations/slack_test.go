@@ -0,0 +1,56 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// slackSign returns Slack's v0 signature of body at timestamp.
+func slackSign(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	const secret, body = "8f742231b10e8888abcd99yyyzzz85a5", `{"type":"event_callback"}`
+	now := time.Unix(1_800_000_000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	stale := strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10)
+	recent := strconv.FormatInt(now.Add(-4*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		want      bool
+	}{
+		{"valid", ts, slackSign(secret, ts, body), body, true},
+		{"within clock skew", recent, slackSign(secret, recent, body), body, true},
+		{"replayed", stale, slackSign(secret, stale, body), body, false},
+		{"from the future", future, slackSign(secret, future, body), body, false},
+		{"wrong secret", ts, slackSign("other", ts, body), body, false},
+		{"tampered body", ts, slackSign(secret, ts, body), `{"type":"url_verification"}`, false},
+		{"signed with another timestamp", ts, slackSign(secret, recent, body), body, false},
+		{"missing signature", ts, "", body, false},
+		{"missing timestamp", "", slackSign(secret, "", body), body, false},
+		{"non-numeric timestamp", "now", slackSign(secret, "now", body), body, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+			header.Set("X-Slack-Signature", tt.signature)
+			if got := verifySlackSignature(secret, header, []byte(tt.body), now); got != tt.want {
+				t.Errorf("verifySlackSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
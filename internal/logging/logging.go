@@ -0,0 +1,348 @@
+// Package logging is ClaudeFu's leveled logger. It is built on log/slog: every
+// subsystem gets a Logger whose records carry a "subsystem" attribute, are
+// filtered by a global level with per-subsystem overrides, kept in an in-memory
+// ring buffer (for the in-app log viewer), and written to stdout and optionally
+// to {configPath}/local/logs/claudefu.log.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subsystems
+const (
+	App       = "app"
+	Watcher   = "watcher"
+	Runtime   = "runtime"
+	Providers = "providers"
+	MCP       = "mcp"
+)
+
+// Subsystems lists the subsystems that have their own toggle in settings.
+var Subsystems = []string{App, Watcher, Runtime, Providers, MCP}
+
+// LevelOff disables a subsystem entirely.
+const LevelOff = "off"
+
+const (
+	ringSize       = 2000             // Entries kept for GetRecent
+	maxLogFileSize = 10 * 1024 * 1024 // Rotate claudefu.log to claudefu.log.1 beyond this
+)
+
+// Config controls what is logged and where.
+type Config struct {
+	Level      string            // debug, info, warn, error (default: info)
+	Subsystems map[string]string // Per-subsystem level overrides, or "off"
+	FileDir    string            // Also write to {FileDir}/claudefu.log ("" = stdout only)
+}
+
+// Entry is one record in the ring buffer.
+type Entry struct {
+	Time      time.Time         `json:"time"`
+	Level     string            `json:"level"`
+	Subsystem string            `json:"subsystem"`
+	Message   string            `json:"message"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+}
+
+// Filter selects recent entries. Zero values match everything.
+type Filter struct {
+	Level     string `json:"level,omitempty"` // Minimum level
+	Subsystem string `json:"subsystem,omitempty"`
+	Contains  string `json:"contains,omitempty"` // Case-insensitive message substring
+	Limit     int    `json:"limit,omitempty"`    // 0 = 200
+}
+
+// state is the process-wide logging configuration and outputs.
+type state struct {
+	mu         sync.RWMutex
+	level      slog.Level
+	subsystems map[string]slog.Level // Overrides; levelOff disables
+	console    io.Writer
+	file       *os.File
+	filePath   string
+	fileSize   int64
+	ring       []Entry
+	ringNext   int
+	ringFull   bool
+}
+
+// levelOff is above every real level.
+const levelOff = slog.Level(100)
+
+var std = &state{level: slog.LevelInfo, console: os.Stdout, ring: make([]Entry, ringSize)}
+
+// Configure applies cfg. It also routes the standard log package (and
+// slog.Default) through the app subsystem.
+func Configure(cfg Config) error {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]slog.Level, len(cfg.Subsystems))
+	for subsystem, name := range cfg.Subsystems {
+		l, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("subsystem %s: %w", subsystem, err)
+		}
+		overrides[subsystem] = l
+	}
+
+	std.mu.Lock()
+	std.level = level
+	std.subsystems = overrides
+	err = std.setFileLocked(cfg.FileDir)
+	std.mu.Unlock()
+
+	slog.SetDefault(slog.New(&handler{subsystem: App}))
+	return err
+}
+
+// SetConsole replaces the console writer (stdout by default). Headless
+// commands point it at stderr so their stdout stays scriptable.
+func SetConsole(w io.Writer) {
+	std.mu.Lock()
+	std.console = w
+	std.mu.Unlock()
+}
+
+// ParseLevel parses debug, info, warn, error or off ("" = info).
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case LevelOff:
+		return levelOff, nil
+	}
+	return 0, fmt.Errorf("unknown log level: %q", name)
+}
+
+// GetRecent returns the newest buffered entries matching f, oldest first.
+func GetRecent(f Filter) []Entry {
+	minLevel := slog.LevelDebug
+	if f.Level != "" {
+		if l, err := ParseLevel(f.Level); err == nil {
+			minLevel = l
+		}
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+	contains := strings.ToLower(f.Contains)
+
+	std.mu.RLock()
+	defer std.mu.RUnlock()
+
+	count := std.ringNext
+	if std.ringFull {
+		count = ringSize
+	}
+	var matched []Entry
+	for i := 1; i <= count && len(matched) < limit; i++ {
+		e := std.ring[(std.ringNext-i+ringSize)%ringSize]
+		if l, _ := ParseLevel(e.Level); l < minLevel {
+			continue
+		}
+		if f.Subsystem != "" && e.Subsystem != f.Subsystem {
+			continue
+		}
+		if contains != "" && !strings.Contains(strings.ToLower(e.Message), contains) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	// Collected newest first
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	if matched == nil {
+		matched = []Entry{}
+	}
+	return matched
+}
+
+// enabled reports whether a subsystem logs at level.
+func (s *state) enabled(subsystem string, level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	threshold, ok := s.subsystems[subsystem]
+	if !ok {
+		threshold = s.level
+	}
+	return level >= threshold && threshold != levelOff
+}
+
+// write records an entry and writes it to the outputs.
+func (s *state) write(subsystem string, r slog.Record, attrs []slog.Attr) {
+	e := Entry{Time: r.Time, Level: r.Level.String(), Subsystem: subsystem, Message: r.Message}
+	addAttr := func(a slog.Attr) bool {
+		if e.Attrs == nil {
+			e.Attrs = make(map[string]string)
+		}
+		e.Attrs[a.Key] = a.Value.String()
+		return true
+	}
+	for _, a := range attrs {
+		addAttr(a)
+	}
+	r.Attrs(addAttr)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%s] [%s] %s", e.Time.Format("15:04:05.000"), e.Level, subsystem, e.Message)
+	for k, v := range e.Attrs {
+		fmt.Fprintf(&b, " %s=%q", k, v)
+	}
+	b.WriteByte('\n')
+	line := b.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ring[s.ringNext] = e
+	s.ringNext = (s.ringNext + 1) % ringSize
+	if s.ringNext == 0 {
+		s.ringFull = true
+	}
+	io.WriteString(s.console, line)
+	if s.file != nil {
+		s.rotateLocked(int64(len(line)))
+		if n, err := io.WriteString(s.file, line); err == nil {
+			s.fileSize += int64(n)
+		}
+	}
+}
+
+// setFileLocked opens (or closes, for dir "") the log file. Caller must hold s.mu.
+func (s *state) setFileLocked(dir string) error {
+	path := ""
+	if dir != "" {
+		path = filepath.Join(dir, "claudefu.log")
+	}
+	if path == s.filePath {
+		return nil
+	}
+	if s.file != nil {
+		s.file.Close()
+		s.file, s.filePath, s.fileSize = nil, "", 0
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, _ := f.Stat()
+	if info != nil {
+		s.fileSize = info.Size()
+	}
+	s.file, s.filePath = f, path
+	return nil
+}
+
+// rotateLocked moves the log file to .1 if writing n more bytes would exceed
+// the size limit. Caller must hold s.mu.
+func (s *state) rotateLocked(n int64) {
+	if s.fileSize+n <= maxLogFileSize {
+		return
+	}
+	s.file.Close()
+	os.Rename(s.filePath, s.filePath+".1")
+	f, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		s.file, s.filePath = nil, ""
+		return
+	}
+	s.file, s.fileSize = f, 0
+}
+
+// =============================================================================
+// LOGGER
+// =============================================================================
+
+// Logger logs for one subsystem. Besides the slog key/value methods it has
+// printf-style variants that skip formatting when the level is disabled.
+type Logger struct {
+	*slog.Logger
+	subsystem string
+}
+
+// For returns the logger of a subsystem.
+func For(subsystem string) *Logger {
+	return &Logger{Logger: slog.New(&handler{subsystem: subsystem}), subsystem: subsystem}
+}
+
+// Debugf logs a formatted message at debug level.
+func (l *Logger) Debugf(format string, args ...any) { l.logf(slog.LevelDebug, format, args) }
+
+// Infof logs a formatted message at info level.
+func (l *Logger) Infof(format string, args ...any) { l.logf(slog.LevelInfo, format, args) }
+
+// Warnf logs a formatted message at warn level.
+func (l *Logger) Warnf(format string, args ...any) { l.logf(slog.LevelWarn, format, args) }
+
+// Errorf logs a formatted message at error level.
+func (l *Logger) Errorf(format string, args ...any) { l.logf(slog.LevelError, format, args) }
+
+// DebugEnabled reports whether debug messages are logged, for guarding
+// expensive debug-only work.
+func (l *Logger) DebugEnabled() bool {
+	return std.enabled(l.subsystem, slog.LevelDebug)
+}
+
+func (l *Logger) logf(level slog.Level, format string, args []any) {
+	if !std.enabled(l.subsystem, level) {
+		return
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	l.Logger.Log(context.Background(), level, msg)
+}
+
+// handler is the slog.Handler behind every Logger.
+type handler struct {
+	subsystem string
+	attrs     []slog.Attr
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return std.enabled(h.subsystem, level)
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	std.write(h.subsystem, r, h.attrs)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := &handler{subsystem: h.subsystem, attrs: append([]slog.Attr{}, h.attrs...)}
+	for _, a := range attrs {
+		if a.Key == "subsystem" {
+			next.subsystem = a.Value.String()
+		} else {
+			next.attrs = append(next.attrs, a)
+		}
+	}
+	return next
+}
+
+// WithGroup is a no-op: attributes are flattened.
+func (h *handler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -53,7 +53,7 @@ func (m *PendingQuestionManager) Create(agentSlug string, questions []map[string
 	}
 
 	m.pending[id] = pq
-	fmt.Printf("[MCP:AskUser] Created pending question %s from agent %s\n", id[:8], agentSlug)
+	logger.Infof("AskUser: Created pending question %s from agent %s", id[:8], agentSlug)
 	return pq
 }
 
@@ -82,7 +82,7 @@ func (m *PendingQuestionManager) Answer(id string, answers map[string]string) er
 	pq, exists := m.pending[id]
 	if !exists {
 		m.mu.Unlock()
-		fmt.Printf("[MCP:AskUser] Answer for question %s: NOT FOUND (likely already timed out or cancelled)\n", id[:8])
+		logger.Infof("AskUser: Answer for question %s: NOT FOUND (likely already timed out or cancelled)", id[:8])
 		return fmt.Errorf("question %s not found — it may have timed out before your answer was submitted", id)
 	}
 	delete(m.pending, id)
@@ -91,9 +91,9 @@ func (m *PendingQuestionManager) Answer(id string, answers map[string]string) er
 	// Send answer (non-blocking with buffer)
 	select {
 	case pq.ResponseCh <- &UserAnswer{Answers: answers, Skipped: false}:
-		fmt.Printf("[MCP:AskUser] Answered question %s successfully\n", id[:8])
+		logger.Infof("AskUser: Answered question %s successfully", id[:8])
 	default:
-		fmt.Printf("[MCP:AskUser] Warning: question %s response channel full (handler may have already returned)\n", id[:8])
+		logger.Warnf("AskUser: Warning: question %s response channel full (handler may have already returned)", id[:8])
 	}
 
 	return nil
@@ -113,9 +113,9 @@ func (m *PendingQuestionManager) Skip(id string) error {
 	// Send skip (non-blocking with buffer)
 	select {
 	case pq.ResponseCh <- &UserAnswer{Skipped: true}:
-		fmt.Printf("[MCP:AskUser] Skipped question %s\n", id[:8])
+		logger.Infof("AskUser: Skipped question %s", id[:8])
 	default:
-		fmt.Printf("[MCP:AskUser] Warning: question %s response channel full\n", id[:8])
+		logger.Warnf("AskUser: Warning: question %s response channel full", id[:8])
 	}
 
 	return nil
@@ -134,7 +134,7 @@ func (m *PendingQuestionManager) Cancel(id string) {
 
 	// Close the channel to unblock any waiting goroutine
 	close(pq.ResponseCh)
-	fmt.Printf("[MCP:AskUser] Cancelled question %s\n", id[:8])
+	logger.Infof("AskUser: Cancelled question %s", id[:8])
 }
 
 // CancelAll cancels all pending questions (e.g., on workspace switch)
@@ -144,7 +144,7 @@ func (m *PendingQuestionManager) CancelAll() {
 
 	for id, pq := range m.pending {
 		close(pq.ResponseCh)
-		fmt.Printf("[MCP:AskUser] Cancelled question %s\n", id[:8])
+		logger.Infof("AskUser: Cancelled question %s", id[:8])
 	}
 	m.pending = make(map[string]*PendingUserQuestion)
 }
@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// AGENT AUTHENTICATION
+// The SSE and message endpoints accept only clients presenting the current
+// workspace's MCP auth token as "Authorization: Bearer <token>". ClaudeFu
+// injects the header into every --mcp-config it passes to the CLI, so other
+// local processes can no longer impersonate agents or read broadcasts.
+// =============================================================================
+
+// mcpConfig returns the current workspace's MCP config (nil if none)
+func (s *MCPService) mcpConfig() *workspace.MCPConfig {
+	if s.workspace == nil {
+		return nil
+	}
+	if ws := s.workspace(); ws != nil {
+		return ws.MCPConfig
+	}
+	return nil
+}
+
+// listenAddr returns the host:port the SSE server binds to
+func (s *MCPService) listenAddr() string {
+	return net.JoinHostPort(s.mcpConfig().GetBindAddress(), strconv.Itoa(s.port))
+}
+
+// baseURL returns the URL clients reach the SSE server at
+func (s *MCPService) baseURL() string {
+	return "http://" + net.JoinHostPort(s.mcpConfig().ClientHost(), strconv.Itoa(s.port))
+}
+
+// requireAgentAuth rejects requests without the workspace's MCP auth token.
+// The token is read per request, so regenerating it takes effect immediately.
+func (s *MCPService) requireAgentAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.agentAuthorized(r) {
+			logger.Warnf("Rejected unauthenticated MCP request from %s to %s", r.RemoteAddr, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="claudefu"`)
+			http.Error(w, "missing or invalid MCP auth token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// agentAuthorized checks the request's bearer token against the current workspace's
+func (s *MCPService) agentAuthorized(r *http.Request) bool {
+	if s.workspace == nil || s.workspace() == nil {
+		return false
+	}
+	cfg := s.mcpConfig()
+	if !cfg.AuthRequired() {
+		return true
+	}
+	if cfg == nil || cfg.AuthToken == "" {
+		return false
+	}
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(cfg.AuthToken)) == 1
+}
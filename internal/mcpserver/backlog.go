@@ -1,8 +1,10 @@
 package mcpserver
 
 import (
-	"log"
+	"encoding/xml"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,11 +47,11 @@ func (bm *BacklogManager) LoadAgents(agentIDs []string) error {
 		dbPath := filepath.Join(bm.configPath, "agents", agentID+".db")
 		store, err := NewBacklogStore(dbPath)
 		if err != nil {
-			log.Printf("Failed to open backlog DB for agent %s: %v", agentID, err)
+			logger.Errorf("Failed to open backlog DB for agent %s: %v", agentID, err)
 			continue
 		}
 		bm.stores[agentID] = store
-		log.Printf("Backlog loaded for agent %s", agentID)
+		logger.Debugf("Backlog loaded for agent %s", agentID)
 	}
 
 	return nil
@@ -66,11 +68,11 @@ func (bm *BacklogManager) getStoreOrOpen(agentID string) *BacklogStore {
 	dbPath := filepath.Join(bm.configPath, "agents", agentID+".db")
 	store, err := NewBacklogStore(dbPath)
 	if err != nil {
-		log.Printf("Failed to lazy-open backlog DB for agent %s: %v", agentID, err)
+		logger.Errorf("Failed to lazy-open backlog DB for agent %s: %v", agentID, err)
 		return nil
 	}
 	bm.stores[agentID] = store
-	log.Printf("Backlog lazy-loaded for agent %s", agentID)
+	logger.Debugf("Backlog lazy-loaded for agent %s", agentID)
 	return store
 }
 
@@ -110,7 +112,7 @@ func (bm *BacklogManager) AddItem(agentID, title, context, status, itemType, tag
 	if store != nil {
 		maxOrder, err := store.GetMaxSortOrder(agentID, parentID)
 		if err != nil {
-			log.Printf("Failed to get max sort order: %v", err)
+			logger.Errorf("Failed to get max sort order: %v", err)
 		} else {
 			sortOrder = maxOrder + 1000
 		}
@@ -133,7 +135,7 @@ func (bm *BacklogManager) AddItem(agentID, title, context, status, itemType, tag
 
 	if store != nil {
 		if err := store.AddItem(item); err != nil {
-			log.Printf("Failed to save backlog item: %v", err)
+			logger.Errorf("Failed to save backlog item: %v", err)
 		}
 	}
 
@@ -149,7 +151,7 @@ func (bm *BacklogManager) GetItem(id string) *BacklogItem {
 	for _, store := range bm.stores {
 		item, err := store.GetItem(id)
 		if err != nil {
-			log.Printf("Failed to get backlog item: %v", err)
+			logger.Errorf("Failed to get backlog item: %v", err)
 			continue
 		}
 		if item != nil {
@@ -171,7 +173,7 @@ func (bm *BacklogManager) UpdateItem(item BacklogItem) bool {
 
 	item.UpdatedAt = time.Now().Unix()
 	if err := store.UpdateItem(item); err != nil {
-		log.Printf("Failed to update backlog item: %v", err)
+		logger.Errorf("Failed to update backlog item: %v", err)
 		return false
 	}
 	return true
@@ -187,7 +189,7 @@ func (bm *BacklogManager) DeleteItem(id string) bool {
 		item, _ := store.GetItem(id)
 		if item != nil {
 			if err := store.DeleteItem(id); err != nil {
-				log.Printf("Failed to delete backlog item: %v", err)
+				logger.Errorf("Failed to delete backlog item: %v", err)
 				return false
 			}
 			return true
@@ -206,7 +208,7 @@ func (bm *BacklogManager) DeleteWithChildren(id string) bool {
 		item, _ := store.GetItem(id)
 		if item != nil {
 			if err := store.DeleteWithChildren(id); err != nil {
-				log.Printf("Failed to delete backlog item with children: %v", err)
+				logger.Errorf("Failed to delete backlog item with children: %v", err)
 				return false
 			}
 			return true
@@ -215,6 +217,67 @@ func (bm *BacklogManager) DeleteWithChildren(id string) bool {
 	return false
 }
 
+// LinkSession links a session to an item for status tracking, returns true if the item exists
+func (bm *BacklogManager) LinkSession(itemID, sessionID string) bool {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	for _, store := range bm.stores {
+		item, _ := store.GetItem(itemID)
+		if item != nil {
+			if err := store.LinkSession(itemID, sessionID, time.Now().Unix()); err != nil {
+				logger.Errorf("Failed to link session to backlog item: %v", err)
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// GetItemSessions returns the session IDs linked to an item
+func (bm *BacklogManager) GetItemSessions(itemID string) []string {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+
+	for _, store := range bm.stores {
+		item, _ := store.GetItem(itemID)
+		if item != nil {
+			sessions, err := store.GetItemSessions(itemID)
+			if err != nil {
+				logger.Errorf("Failed to get backlog item sessions: %v", err)
+				return []string{}
+			}
+			return sessions
+		}
+	}
+	return []string{}
+}
+
+// GetSessionItems returns an agent's backlog items linked to a session
+func (bm *BacklogManager) GetSessionItems(agentID, sessionID string) []BacklogItem {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	store := bm.getStoreOrOpen(agentID)
+	if store == nil {
+		return []BacklogItem{}
+	}
+	ids, err := store.GetSessionItemIDs(sessionID)
+	if err != nil {
+		logger.Errorf("Failed to get backlog items for session %s: %v", sessionID, err)
+		return []BacklogItem{}
+	}
+
+	items := make([]BacklogItem, 0, len(ids))
+	for _, id := range ids {
+		if item, _ := store.GetItem(id); item != nil {
+			items = append(items, *item)
+		}
+	}
+	return items
+}
+
 // GetItemsByAgent returns all backlog items for a specific agent
 func (bm *BacklogManager) GetItemsByAgent(agentID string) []BacklogItem {
 	bm.mu.Lock()
@@ -227,7 +290,7 @@ func (bm *BacklogManager) GetItemsByAgent(agentID string) []BacklogItem {
 
 	items, err := store.GetItemsByAgent(agentID)
 	if err != nil {
-		log.Printf("Failed to get backlog items for agent %s: %v", agentID, err)
+		logger.Errorf("Failed to get backlog items for agent %s: %v", agentID, err)
 		return []BacklogItem{}
 	}
 	return items
@@ -251,7 +314,7 @@ func (bm *BacklogManager) MoveItem(id, newParentID, afterID string) bool {
 		}
 	}
 	if store == nil || item == nil {
-		log.Printf("Failed to get item for move: not found")
+		logger.Errorf("Failed to get item for move: not found")
 		return false
 	}
 
@@ -260,7 +323,7 @@ func (bm *BacklogManager) MoveItem(id, newParentID, afterID string) bool {
 	// Get siblings in the target parent
 	siblings, err := store.GetItemsByParent(agentID, newParentID)
 	if err != nil {
-		log.Printf("Failed to get siblings for move: %v", err)
+		logger.Errorf("Failed to get siblings for move: %v", err)
 		return false
 	}
 
@@ -313,7 +376,7 @@ func (bm *BacklogManager) MoveItem(id, newParentID, afterID string) bool {
 	// If gap is too small (< 2), reindex siblings first
 	if newSortOrder <= 0 {
 		if err := store.ReindexSortOrder(agentID, newParentID); err != nil {
-			log.Printf("Failed to reindex sort order: %v", err)
+			logger.Errorf("Failed to reindex sort order: %v", err)
 			return false
 		}
 		// Retry with fresh ordering
@@ -326,7 +389,7 @@ func (bm *BacklogManager) MoveItem(id, newParentID, afterID string) bool {
 	item.SortOrder = newSortOrder
 	item.UpdatedAt = time.Now().Unix()
 	if err := store.UpdateItem(*item); err != nil {
-		log.Printf("Failed to update moved item: %v", err)
+		logger.Errorf("Failed to update moved item: %v", err)
 		return false
 	}
 
@@ -345,7 +408,7 @@ func (bm *BacklogManager) GetTotalCount(agentID string) int {
 
 	count, err := store.GetTotalCount(agentID)
 	if err != nil {
-		log.Printf("Failed to get total count: %v", err)
+		logger.Errorf("Failed to get total count: %v", err)
 		return 0
 	}
 	return count
@@ -363,7 +426,7 @@ func (bm *BacklogManager) GetNonDoneCount(agentID string) int {
 
 	count, err := store.GetNonDoneCount(agentID)
 	if err != nil {
-		log.Printf("Failed to get non-done count: %v", err)
+		logger.Errorf("Failed to get non-done count: %v", err)
 		return 0
 	}
 	return count
@@ -379,3 +442,32 @@ func (bm *BacklogManager) GetOldWorkspaceDBPath(workspaceID string) string {
 func (bm *BacklogManager) GetConfigPath() string {
 	return bm.configPath
 }
+
+// FormatBacklogContext renders items as a <backlog_context> XML block to prepend
+// to a prompt, so the agent gets each item's title, context, and tags verbatim.
+func FormatBacklogContext(items []BacklogItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<backlog_context>\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "<item id=%q status=%q type=%q>\n", item.ID, item.Status, item.Type)
+		writeXMLElement(&b, "title", item.Title)
+		writeXMLElement(&b, "tags", item.Tags)
+		writeXMLElement(&b, "context", item.Context)
+		b.WriteString("</item>\n")
+	}
+	b.WriteString("</backlog_context>")
+	return b.String()
+}
+
+// writeXMLElement writes <name>escaped value</name> on its own line, skipping empty values
+func writeXMLElement(b *strings.Builder, name, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	fmt.Fprintf(b, "<%s>", name)
+	xml.EscapeText(b, []byte(value))
+	fmt.Fprintf(b, "</%s>\n", name)
+}
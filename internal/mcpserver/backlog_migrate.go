@@ -3,7 +3,6 @@ package mcpserver
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 
@@ -29,7 +28,7 @@ func MigrateFromWorkspaceDB(backlogPath, workspaceID string, oldToNew map[string
 		return nil // Nothing to migrate
 	}
 
-	log.Printf("Backlog migration: found old workspace DB at %s", oldDBPath)
+	logger.Infof("Backlog migration: found old workspace DB at %s", oldDBPath)
 
 	// Open old database
 	oldDB, err := sql.Open("sqlite", oldDBPath)
@@ -75,7 +74,7 @@ func MigrateFromWorkspaceDB(backlogPath, workspaceID string, oldToNew map[string
 	}
 
 	if totalItems == 0 {
-		log.Printf("Backlog migration: old DB is empty, renaming")
+		logger.Infof("Backlog migration: old DB is empty, renaming")
 		return os.Rename(oldDBPath, oldDBPath+".migrated")
 	}
 
@@ -85,7 +84,7 @@ func MigrateFromWorkspaceDB(backlogPath, workspaceID string, oldToNew map[string
 		agentDBPath := filepath.Join(agentsDir, agentID+".db")
 		store, err := NewBacklogStore(agentDBPath)
 		if err != nil {
-			log.Printf("Backlog migration: failed to open agent DB %s: %v", agentID, err)
+			logger.Warnf("Backlog migration: failed to open agent DB %s: %v", agentID, err)
 			continue
 		}
 
@@ -97,22 +96,22 @@ func MigrateFromWorkspaceDB(backlogPath, workspaceID string, oldToNew map[string
 				continue
 			}
 			if err := store.AddItem(item); err != nil {
-				log.Printf("Backlog migration: failed to insert item %s: %v", item.ID, err)
+				logger.Warnf("Backlog migration: failed to insert item %s: %v", item.ID, err)
 				continue
 			}
 			migrated++
 		}
 
 		store.Close()
-		log.Printf("Backlog migration: migrated %d items to agent %s", migrated, agentID)
+		logger.Infof("Backlog migration: migrated %d items to agent %s", migrated, agentID)
 	}
 
 	// Rename old DB to .migrated
 	if err := os.Rename(oldDBPath, oldDBPath+".migrated"); err != nil {
-		log.Printf("Backlog migration: failed to rename old DB: %v", err)
+		logger.Warnf("Backlog migration: failed to rename old DB: %v", err)
 		// Non-fatal — items are already copied
 	}
 
-	log.Printf("Backlog migration complete: %d items across %d agents", totalItems, len(itemsByAgent))
+	logger.Infof("Backlog migration complete: %d items across %d agents", totalItems, len(itemsByAgent))
 	return nil
 }
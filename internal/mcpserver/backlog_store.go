@@ -76,6 +76,13 @@ func createBacklogSchema(db *sql.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_status ON backlog_items(agent_id, status);
 		CREATE INDEX IF NOT EXISTS idx_type ON backlog_items(agent_id, type);
 		CREATE INDEX IF NOT EXISTS idx_sort ON backlog_items(agent_id, parent_id, sort_order);
+		CREATE TABLE IF NOT EXISTS backlog_sessions (
+			item_id TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			linked_at INTEGER NOT NULL,
+			PRIMARY KEY (item_id, session_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_session ON backlog_sessions(session_id);
 	`
 	if _, err := db.Exec(schema); err != nil {
 		return err
@@ -148,7 +155,10 @@ func (s *BacklogStore) UpdateItem(item BacklogItem) error {
 
 // DeleteItem removes a single backlog item (not its children)
 func (s *BacklogStore) DeleteItem(id string) error {
-	_, err := s.db.Exec(`DELETE FROM backlog_items WHERE id = ?`, id)
+	if _, err := s.db.Exec(`DELETE FROM backlog_items WHERE id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM backlog_sessions WHERE item_id = ?`, id)
 	return err
 }
 
@@ -237,6 +247,9 @@ func (s *BacklogStore) DeleteWithChildren(id string) error {
 		if _, err := tx.Exec(`DELETE FROM backlog_items WHERE id = ?`, deleteID); err != nil {
 			return err
 		}
+		if _, err := tx.Exec(`DELETE FROM backlog_sessions WHERE item_id = ?`, deleteID); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit()
@@ -310,6 +323,43 @@ func (s *BacklogStore) GetNonDoneCount(agentID string) (int, error) {
 	return count, err
 }
 
+// LinkSession records that a session worked on an item (no-op if already linked)
+func (s *BacklogStore) LinkSession(itemID, sessionID string, linkedAt int64) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO backlog_sessions (item_id, session_id, linked_at) VALUES (?, ?, ?)
+	`, itemID, sessionID, linkedAt)
+	return err
+}
+
+// GetItemSessions returns the session IDs linked to an item, oldest link first
+func (s *BacklogStore) GetItemSessions(itemID string) ([]string, error) {
+	return s.queryStrings(`SELECT session_id FROM backlog_sessions WHERE item_id = ? ORDER BY linked_at`, itemID)
+}
+
+// GetSessionItemIDs returns the item IDs linked to a session, oldest link first
+func (s *BacklogStore) GetSessionItemIDs(sessionID string) ([]string, error) {
+	return s.queryStrings(`SELECT item_id FROM backlog_sessions WHERE session_id = ? ORDER BY linked_at`, sessionID)
+}
+
+// queryStrings runs a single-column query, returning empty slice (not nil) when no rows
+func (s *BacklogStore) queryStrings(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
 // scanBacklogItems scans rows into a BacklogItem slice, returning empty slice (not nil) when no rows
 func scanBacklogItems(rows *sql.Rows) ([]BacklogItem, error) {
 	var items []BacklogItem
@@ -0,0 +1,265 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// BACKLOG EXPORT / IMPORT / CROSS-AGENT MOVE
+// =============================================================================
+
+// Backlog export formats
+const (
+	BacklogFormatJSON     = "json"
+	BacklogFormatMarkdown = "markdown"
+)
+
+// backlogExportVersion is bumped if the JSON export layout changes incompatibly
+const backlogExportVersion = 1
+
+// BacklogExport is the JSON export of one agent's backlog. ImportAgent reads it back.
+type BacklogExport struct {
+	Version    int                 `json:"version"`
+	AgentID    string              `json:"agentId"`
+	ExportedAt int64               `json:"exportedAt"`
+	Items      []BacklogExportItem `json:"items"`
+}
+
+// BacklogExportItem is a backlog item plus the sessions that worked on it
+type BacklogExportItem struct {
+	BacklogItem
+	Sessions []string `json:"sessions,omitempty"`
+}
+
+// ExportAgent renders an agent's backlog as JSON (BacklogExport) or Markdown
+func (bm *BacklogManager) ExportAgent(agentID, format string) ([]byte, error) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	store := bm.getStoreOrOpen(agentID)
+	if store == nil {
+		return nil, fmt.Errorf("backlog not available for agent %s", agentID)
+	}
+	items, err := store.GetItemsByAgent(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", BacklogFormatJSON:
+		export := BacklogExport{
+			Version:    backlogExportVersion,
+			AgentID:    agentID,
+			ExportedAt: time.Now().Unix(),
+			Items:      make([]BacklogExportItem, 0, len(items)),
+		}
+		for _, item := range items {
+			sessions, err := store.GetItemSessions(item.ID)
+			if err != nil {
+				logger.Errorf("Failed to get sessions for backlog item %s: %v", item.ID, err)
+			}
+			export.Items = append(export.Items, BacklogExportItem{BacklogItem: item, Sessions: sessions})
+		}
+		return json.MarshalIndent(export, "", "  ")
+	case BacklogFormatMarkdown:
+		return []byte(formatBacklogMarkdown(items)), nil
+	default:
+		return nil, fmt.Errorf("unsupported backlog export format: %q", format)
+	}
+}
+
+// ImportAgent adds the items of a JSON export to an agent's backlog and returns
+// how many were imported. Items get new IDs (so the same export can be imported
+// twice, or into several agents); the tree structure is kept and imported
+// top-level items are placed after the agent's existing ones.
+func (bm *BacklogManager) ImportAgent(agentID string, data []byte) (int, error) {
+	var export BacklogExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return 0, fmt.Errorf("invalid backlog export: %w", err)
+	}
+	if export.Version > backlogExportVersion {
+		return 0, fmt.Errorf("backlog export version %d is newer than supported (%d)", export.Version, backlogExportVersion)
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	store := bm.getStoreOrOpen(agentID)
+	if store == nil {
+		return 0, fmt.Errorf("backlog not available for agent %s", agentID)
+	}
+
+	newIDs := make(map[string]string, len(export.Items))
+	for _, item := range export.Items {
+		newIDs[item.ID] = uuid.New().String()
+	}
+
+	rootOrder, err := store.GetMaxSortOrder(agentID, "")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	imported := 0
+	for _, ei := range export.Items {
+		item := ei.BacklogItem
+		item.ID = newIDs[ei.ID]
+		item.AgentID = agentID
+		if parentID, ok := newIDs[item.ParentID]; ok {
+			item.ParentID = parentID
+		} else {
+			// Parent not part of the export: import as a top-level item
+			rootOrder += 1000
+			item.ParentID = ""
+			item.SortOrder = rootOrder
+		}
+		if item.CreatedAt == 0 {
+			item.CreatedAt = now
+		}
+		item.UpdatedAt = now
+
+		if err := store.AddItem(item); err != nil {
+			return imported, fmt.Errorf("failed to import %q: %w", item.Title, err)
+		}
+		for _, sessionID := range ei.Sessions {
+			if err := store.LinkSession(item.ID, sessionID, now); err != nil {
+				logger.Errorf("Failed to link session to imported backlog item: %v", err)
+			}
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// MoveItemToAgent moves an item and its descendants into another agent's
+// backlog (as a top-level item), keeping IDs and session links. Returns the
+// agent the item was moved from.
+func (bm *BacklogManager) MoveItemToAgent(id, targetAgentID string) (string, error) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	var source *BacklogStore
+	var item *BacklogItem
+	for _, s := range bm.stores {
+		if it, _ := s.GetItem(id); it != nil {
+			source, item = s, it
+			break
+		}
+	}
+	if item == nil {
+		return "", fmt.Errorf("backlog item not found: %s", id)
+	}
+	sourceAgentID := item.AgentID
+	if sourceAgentID == targetAgentID {
+		return "", fmt.Errorf("item already belongs to this agent")
+	}
+	target := bm.getStoreOrOpen(targetAgentID)
+	if target == nil {
+		return "", fmt.Errorf("backlog not available for agent %s", targetAgentID)
+	}
+
+	all, err := source.GetItemsByAgent(sourceAgentID)
+	if err != nil {
+		return "", err
+	}
+	subtree := backlogSubtree(all, id)
+
+	rootOrder, err := target.GetMaxSortOrder(targetAgentID, "")
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	for _, it := range subtree {
+		sessions, err := source.GetItemSessions(it.ID)
+		if err != nil {
+			return "", err
+		}
+		it.AgentID = targetAgentID
+		it.UpdatedAt = now
+		if it.ID == id {
+			it.ParentID = ""
+			it.SortOrder = rootOrder + 1000
+		}
+		if err := target.AddItem(it); err != nil {
+			return "", fmt.Errorf("failed to move %q: %w", it.Title, err)
+		}
+		for _, sessionID := range sessions {
+			if err := target.LinkSession(it.ID, sessionID, now); err != nil {
+				logger.Errorf("Failed to carry session link for moved backlog item: %v", err)
+			}
+		}
+	}
+
+	if err := source.DeleteWithChildren(id); err != nil {
+		return "", fmt.Errorf("moved item but failed to remove it from the source backlog: %w", err)
+	}
+	return sourceAgentID, nil
+}
+
+// backlogSubtree returns the item with the given ID followed by all its descendants
+func backlogSubtree(items []BacklogItem, rootID string) []BacklogItem {
+	children := make(map[string][]BacklogItem)
+	var root *BacklogItem
+	for i := range items {
+		if items[i].ID == rootID {
+			root = &items[i]
+		}
+		children[items[i].ParentID] = append(children[items[i].ParentID], items[i])
+	}
+	if root == nil {
+		return nil
+	}
+
+	result := []BacklogItem{*root}
+	for i := 0; i < len(result); i++ {
+		result = append(result, children[result[i].ID]...)
+	}
+	return result
+}
+
+// formatBacklogMarkdown renders items as a nested checklist with context as
+// indented text. Done items are checked.
+func formatBacklogMarkdown(items []BacklogItem) string {
+	children := make(map[string][]BacklogItem)
+	ids := make(map[string]bool, len(items))
+	for _, item := range items {
+		ids[item.ID] = true
+	}
+	for _, item := range items {
+		parent := item.ParentID
+		if !ids[parent] {
+			parent = "" // Orphans render at the top level
+		}
+		children[parent] = append(children[parent], item)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Backlog\n\n")
+	var write func(parentID string, depth int)
+	write = func(parentID string, depth int) {
+		indent := strings.Repeat("  ", depth)
+		for _, item := range children[parentID] {
+			check := " "
+			if item.Status == "done" {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "%s- [%s] **%s** `%s` `%s`", indent, check, item.Title, item.Status, item.Type)
+			if item.Tags != "" {
+				fmt.Fprintf(&b, " _%s_", item.Tags)
+			}
+			b.WriteString("\n")
+			if ctx := strings.TrimSpace(item.Context); ctx != "" {
+				for _, line := range strings.Split(ctx, "\n") {
+					fmt.Fprintf(&b, "%s  %s\n", indent, line)
+				}
+			}
+			write(item.ID, depth+1)
+		}
+	}
+	write("", 0)
+	return b.String()
+}
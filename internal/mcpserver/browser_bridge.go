@@ -0,0 +1,180 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// BROWSER AGENT BRIDGE
+// BrowserAgent talks to Claude in Browser through a Chrome extension bridge
+// (health check over HTTP, requests over a WebSocket). The endpoint comes from
+// the workspace's MCP config, else the app settings, else localhost:9320.
+// While BrowserAgent is enabled the bridge is health-checked periodically and
+// the result emitted as mcp:browser-bridge.
+// =============================================================================
+
+// browserBridgeCheckInterval is how often the bridge is health-checked
+const browserBridgeCheckInterval = 30 * time.Second
+
+// BrowserBridgeStatus is the result of the latest bridge health check.
+type BrowserBridgeStatus struct {
+	URL        string    `json:"url"`
+	Connected  bool      `json:"connected"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	SocketOpen bool      `json:"socketOpen"` // Persistent request WebSocket is open
+	Active     int       `json:"active"`     // Requests the browser is working on
+	Queued     int       `json:"queued"`     // Requests waiting for the browser
+}
+
+// browserBridgeState holds the app-wide bridge endpoint and the latest status.
+type browserBridgeState struct {
+	defaults workspace.BrowserBridgeConfig
+	status   BrowserBridgeStatus
+	mu       sync.RWMutex
+}
+
+// SetBrowserBridgeDefaults sets the app-wide bridge endpoint (from settings),
+// used by workspaces that don't configure their own
+func (s *MCPService) SetBrowserBridgeDefaults(cfg workspace.BrowserBridgeConfig) {
+	s.bridge.mu.Lock()
+	s.bridge.defaults = cfg
+	s.bridge.mu.Unlock()
+}
+
+// GetBrowserBridgeStatus returns the latest bridge health check result with
+// the current request queue
+func (s *MCPService) GetBrowserBridgeStatus() BrowserBridgeStatus {
+	s.bridge.mu.RLock()
+	status := s.bridge.status
+	s.bridge.mu.RUnlock()
+	s.fillBridgeQueue(&status)
+	return status
+}
+
+// fillBridgeQueue adds the live connection and queue state to a status
+func (s *MCPService) fillBridgeQueue(status *BrowserBridgeStatus) {
+	status.SocketOpen = s.bridgeConn.connected()
+	status.Active, status.Queued = s.browserQueue.Stats()
+}
+
+// browserBridgeConfig returns the bridge endpoint for the current workspace
+func (s *MCPService) browserBridgeConfig() workspace.BrowserBridgeConfig {
+	if cfg := s.mcpConfig(); cfg != nil && cfg.BrowserBridge != nil {
+		return *cfg.BrowserBridge
+	}
+	s.bridge.mu.RLock()
+	defer s.bridge.mu.RUnlock()
+	return s.bridge.defaults
+}
+
+// bridgeHeader returns the request headers carrying the bridge token (nil if none)
+func bridgeHeader(cfg workspace.BrowserBridgeConfig) http.Header {
+	if cfg.Token == "" {
+		return nil
+	}
+	return http.Header{"Authorization": []string{"Bearer " + cfg.Token}}
+}
+
+// bridgeTLSConfig returns the TLS settings for the bridge (nil = defaults)
+func bridgeTLSConfig(cfg workspace.BrowserBridgeConfig) *tls.Config {
+	if !cfg.TLS || !cfg.InsecureSkipVerify {
+		return nil
+	}
+	return &tls.Config{InsecureSkipVerify: true}
+}
+
+// pingBrowserBridge calls the bridge's /health endpoint
+func pingBrowserBridge(ctx context.Context, cfg workspace.BrowserBridgeConfig) error {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: bridgeTLSConfig(cfg), DisableKeepAlives: true},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL("http", "/health"), nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range bridgeHeader(cfg) {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bridge not reachable at %s", cfg.URL("http", ""))
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bridge health check failed: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dialBrowserBridge opens the bridge WebSocket
+func dialBrowserBridge(ctx context.Context, cfg workspace.BrowserBridgeConfig) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:  bridgeTLSConfig(cfg),
+	}
+	conn, _, err := dialer.DialContext(ctx, cfg.URL("ws", "/ws"), bridgeHeader(cfg))
+	return conn, err
+}
+
+// monitorBrowserBridge health-checks the bridge until ctx is done, emitting
+// mcp:browser-bridge after each check. Skips checks while BrowserAgent is disabled.
+func (s *MCPService) monitorBrowserBridge(ctx context.Context) {
+	ticker := time.NewTicker(browserBridgeCheckInterval)
+	defer ticker.Stop()
+	for {
+		if s.toolAvailability.IsEnabled("BrowserAgent") {
+			s.CheckBrowserBridge(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckBrowserBridge runs one bridge health check and emits the result
+func (s *MCPService) CheckBrowserBridge(ctx context.Context) BrowserBridgeStatus {
+	cfg := s.browserBridgeConfig()
+	status := BrowserBridgeStatus{URL: cfg.URL("http", ""), CheckedAt: time.Now()}
+	if err := pingBrowserBridge(ctx, cfg); err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Connected = true
+	}
+	if ctx.Err() != nil {
+		return status // Shutting down; the failure is ours, not the bridge's
+	}
+	s.fillBridgeQueue(&status)
+
+	s.bridge.mu.Lock()
+	changed := s.bridge.status.Connected != status.Connected || s.bridge.status.URL != status.URL
+	s.bridge.status = status
+	s.bridge.mu.Unlock()
+	if changed {
+		logger.Infof("BrowserAgent bridge %s: connected=%v %s", status.URL, status.Connected, status.Error)
+	}
+
+	if s.emitFunc != nil {
+		s.emitFunc(types.EventEnvelope{
+			EventType: "mcp:browser-bridge",
+			Payload: map[string]any{
+				"status":  status,
+				"changed": changed,
+			},
+		})
+	}
+	return status
+}
@@ -0,0 +1,202 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// =============================================================================
+// PERSISTENT BRIDGE CONNECTION
+// One WebSocket to the bridge is kept open and shared by all BrowserAgent
+// calls. Requests carry a request_id and a reader goroutine routes each reply
+// to the call waiting for it. The browser investigates one prompt at a time,
+// so calls beyond browserBridgeMaxActive wait in the bridge queue.
+// =============================================================================
+
+// browserBridgeMaxActive is how many BrowserAgent requests the browser works on at once
+const browserBridgeMaxActive = 1
+
+// bridgeResponse is a reply from the bridge.
+type bridgeResponse struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id"`
+	Content   string `json:"content"`
+	IsError   bool   `json:"is_error"`
+}
+
+// bridgeConn is the shared bridge WebSocket and the requests awaiting replies.
+type bridgeConn struct {
+	conn    *websocket.Conn
+	url     string // Endpoint conn was dialed with; a config change redials
+	pending map[string]chan bridgeResponse
+	mu      sync.Mutex
+	writeMu sync.Mutex // gorilla/websocket allows one concurrent writer
+}
+
+// sendBrowserBridgeRequest sends request on the shared connection (dialing it
+// if needed) and returns the channel its reply arrives on. The channel is
+// closed without a value if the connection drops first.
+func (s *MCPService) sendBrowserBridgeRequest(ctx context.Context, cfg workspace.BrowserBridgeConfig, requestID string, request map[string]any) (<-chan bridgeResponse, error) {
+	b := &s.bridgeConn
+	url := cfg.URL("ws", "/ws")
+
+	b.mu.Lock()
+	if b.conn != nil && b.url != url {
+		b.closeLocked()
+	}
+	conn := b.conn
+	b.mu.Unlock()
+
+	if conn == nil {
+		// Dial without holding b.mu, so an unreachable bridge doesn't stall
+		// reply routing and cancellations for the whole dial timeout
+		dialed, err := dialBrowserBridge(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		b.mu.Lock()
+		if b.conn == nil {
+			b.conn = dialed
+			b.url = url
+			b.pending = make(map[string]chan bridgeResponse)
+			go s.readBrowserBridge(dialed)
+			logger.Infof("BrowserAgent: Connected to bridge at %s", url)
+		} else {
+			dialed.Close() // Another call connected first
+		}
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	conn = b.conn
+	if conn == nil {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("browser bridge connection closed")
+	}
+	ch := make(chan bridgeResponse, 1)
+	b.pending[requestID] = ch
+	b.mu.Unlock()
+
+	b.writeMu.Lock()
+	err := conn.WriteJSON(request)
+	b.writeMu.Unlock()
+	if err != nil {
+		s.cancelBrowserBridgeRequest(requestID)
+		s.dropBrowserBridgeConn(conn)
+		return nil, err
+	}
+	return ch, nil
+}
+
+// acquireBrowserSlot waits until the browser is free, telling the UI
+// (mcp:browser-queued) and the caller (progress notification, if requested)
+// its queue position when it has to wait
+func (s *MCPService) acquireBrowserSlot(ctx context.Context, req mcp.CallToolRequest, fromAgent string) (*QueryTicket, error) {
+	return s.browserQueue.Acquire(ctx, browserBridgeMaxActive, func(position int) {
+		logger.Infof("BrowserAgent: Request from %s queued at position %d", fromAgent, position)
+		if s.emitFunc != nil {
+			s.emitFunc(types.EventEnvelope{
+				EventType: "mcp:browser-queued",
+				Payload: map[string]any{
+					"fromAgent": fromAgent,
+					"position":  position,
+				},
+			})
+		}
+		if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+			return
+		}
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": req.Params.Meta.ProgressToken,
+				"progress":      0,
+				"message":       fmt.Sprintf("Browser is busy; queued at position %d", position),
+			})
+		}
+	})
+}
+
+// cancelBrowserBridgeRequest stops waiting for a request's reply; a late reply is discarded
+func (s *MCPService) cancelBrowserBridgeRequest(requestID string) {
+	b := &s.bridgeConn
+	b.mu.Lock()
+	delete(b.pending, requestID)
+	b.mu.Unlock()
+}
+
+// readBrowserBridge routes replies on conn to their waiting requests until the
+// connection fails
+func (s *MCPService) readBrowserBridge(conn *websocket.Conn) {
+	for {
+		var response bridgeResponse
+		if err := conn.ReadJSON(&response); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Debugf("BrowserAgent: Bridge read ended: %v", err)
+			}
+			s.dropBrowserBridgeConn(conn)
+			return
+		}
+		if response.RequestID == "" {
+			continue // Bridge status messages
+		}
+
+		b := &s.bridgeConn
+		b.mu.Lock()
+		ch, ok := b.pending[response.RequestID]
+		delete(b.pending, response.RequestID)
+		b.mu.Unlock()
+		if ok {
+			ch <- response
+		} else {
+			logger.Debugf("BrowserAgent: Discarding reply for abandoned request %s", response.RequestID)
+		}
+	}
+}
+
+// dropBrowserBridgeConn closes conn if it is still the shared connection,
+// failing the requests waiting on it
+func (s *MCPService) dropBrowserBridgeConn(conn *websocket.Conn) {
+	b := &s.bridgeConn
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == conn {
+		b.closeLocked()
+	}
+}
+
+// closeBrowserBridge closes the shared connection (server stop)
+func (s *MCPService) closeBrowserBridge() {
+	b := &s.bridgeConn
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked()
+}
+
+// closeLocked closes the connection and closes every pending reply channel.
+// Caller must hold b.mu.
+func (b *bridgeConn) closeLocked() {
+	if b.conn == nil {
+		return
+	}
+	b.conn.Close()
+	b.conn = nil
+	for id, ch := range b.pending {
+		close(ch)
+		delete(b.pending, id)
+	}
+}
+
+// connected reports whether the shared connection is open
+func (b *bridgeConn) connected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.conn != nil
+}
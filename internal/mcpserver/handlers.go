@@ -10,16 +10,22 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"claudefu/internal/claudehome"
+	"claudefu/internal/export"
+	"claudefu/internal/metrics"
+	"claudefu/internal/permissions"
 	"claudefu/internal/providers"
+	"claudefu/internal/summary"
+	"claudefu/internal/tasks"
 	"claudefu/internal/types"
 	"claudefu/internal/workspace"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -27,7 +33,7 @@ import (
 func (s *MCPService) findMCPEnabledAgent(identifier string) *workspace.Agent {
 	ws := s.workspace()
 	if ws == nil {
-		fmt.Printf("[MCP:findAgent] No workspace loaded\n")
+		logger.Infof("findAgent: No workspace loaded")
 		return nil
 	}
 
@@ -35,13 +41,13 @@ func (s *MCPService) findMCPEnabledAgent(identifier string) *workspace.Agent {
 	for i := range ws.Agents {
 		agent := &ws.Agents[i]
 		if !agent.GetMCPEnabled() {
-			fmt.Printf("[MCP:findAgent] Skipping '%s' (slug: %s) — MCP disabled\n", agent.GetSlug(), agent.GetSlug())
+			logger.Infof("findAgent: Skipping '%s' (slug: %s) — MCP disabled", agent.GetSlug(), agent.GetSlug())
 			continue // Skip agents with MCP disabled
 		}
 		// Match by slug (case-insensitive)
 		agentSlug := strings.ToLower(agent.GetSlug())
 		if agentSlug == identifier {
-			fmt.Printf("[MCP:findAgent] Found '%s' -> %s (ID: %s)\n", identifier, agentSlug, agent.ID)
+			logger.Infof("findAgent: Found '%s' -> %s (ID: %s)", identifier, agentSlug, agent.ID)
 			return agent
 		}
 	}
@@ -55,10 +61,38 @@ func (s *MCPService) findMCPEnabledAgent(identifier string) *workspace.Agent {
 		}
 		available = append(available, fmt.Sprintf("%s (slug:%s, %s)", agent.GetSlug(), agent.GetSlug(), mcpStatus))
 	}
-	fmt.Printf("[MCP:findAgent] No match for '%s'. Available agents: [%s]\n", identifier, strings.Join(available, ", "))
+	logger.Infof("findAgent: No match for '%s'. Available agents: [%s]", identifier, strings.Join(available, ", "))
 	return nil
 }
 
+// findAgentsByTarget returns the MCP-enabled workspace agents matching a specialization
+// and/or any of the comma-separated tags (both case-insensitive). Empty filters match all.
+func (s *MCPService) findAgentsByTarget(specialization, tags string) []*workspace.Agent {
+	ws := s.workspace()
+	if ws == nil {
+		return nil
+	}
+
+	wantTags := workspace.NormalizeTags(strings.Split(tags, ","))
+	var matched []*workspace.Agent
+	for i := range ws.Agents {
+		agent := &ws.Agents[i]
+		if !agent.GetMCPEnabled() {
+			continue
+		}
+		if specialization != "" && !strings.EqualFold(agent.Specialization, strings.TrimSpace(specialization)) {
+			continue
+		}
+		if len(wantTags) > 0 && !slices.ContainsFunc(wantTags, func(tag string) bool {
+			return slices.Contains(workspace.NormalizeTags(agent.Tags), tag)
+		}) {
+			continue
+		}
+		matched = append(matched, agent)
+	}
+	return matched
+}
+
 // getAvailableAgentSlugs returns slugs of all MCP-enabled agents, plus cross-workspace agents
 func (s *MCPService) getAvailableAgentSlugs() []string {
 	ws := s.workspace()
@@ -114,12 +148,23 @@ func (s *MCPService) handleAgentQuery(ctx context.Context, req mcp.CallToolReque
 		)), nil
 	}
 
-	// Get claude binary path
-	claudePath := providers.GetClaudePath()
+	// Get claude binary path (per-agent override or global)
+	claudePath := providers.ClaudePathFor(agent.Folder)
 	if claudePath == "" {
 		return mcp.NewToolResultError("claude CLI not found"), nil
 	}
 
+	// Give the target the caller's session context as a summary, not a transcript
+	if getOptionalString(req, "include_session_summary") == "true" {
+		if block := s.requestingSessionSummary(ctx, getOptionalString(req, "from_agent")); block != "" {
+			query = block + "\n\n" + query
+		}
+	}
+
+	if cached := s.cachedQueryResult(req, "AgentQuery", agent.ID, query); cached != nil {
+		return cached, nil
+	}
+
 	// Get system prompt from configurable instructions
 	systemPrompt := s.toolInstructions.GetInstructions().AgentQuerySystemPrompt
 
@@ -130,28 +175,62 @@ func (s *MCPService) handleAgentQuery(ctx context.Context, req mcp.CallToolReque
 	// The child is a stateless query - it doesn't need inter-agent tools.
 	// We also disallow Task to prevent spawning subagents (causes concurrent API conflicts).
 	// Prepend "AgentQuery: " so session list shows these as queries, not regular sessions.
-	args := []string{
-		"--print",
-		"--disallowed-tools", "Task",
-		"-p", "AgentQuery: " + query,
-	}
+	args := providers.AppendSupportedFlag(append(providers.ExtraCLIArgsFor(agent.Folder), "--print"), "--disallowed-tools", "Task")
+	args = append(args, "-p", types.AgentQueryPrefix+query)
 
 	// Only append system prompt if configured
 	if systemPrompt != "" {
-		args = append(args, "--append-system-prompt", systemPrompt)
+		args = providers.AppendSupportedFlag(args, "--append-system-prompt", systemPrompt)
+	}
+
+	// Wait for a free query slot (bounded per workspace to avoid API concurrency errors)
+	ticket, err := s.acquireQuerySlot(ctx, req, "AgentQuery", agent.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("AgentQuery cancelled while queued: %v", err)), nil
+	}
+	defer ticket.Release()
+
+	// Then for a global spawn slot (background lane: user sends go first)
+	slot, err := providers.Spawns().Acquire(ctx, providers.SpawnRequest{Priority: providers.PriorityNested, Kind: "agent-query", Folder: agent.Folder})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("AgentQuery cancelled while queued: %v", err)), nil
 	}
+	defer slot.Release()
 
 	// Retry logic for transient API concurrency errors
 	var output []byte
 	var cmdErr error
 	maxRetries := 3
 
+	// Streaming mode: parse stream-json incrementally and report progress as it happens
+	var progress *queryProgress
+	if getOptionalString(req, "stream") == "true" {
+		progress = &queryProgress{
+			s:           s,
+			ctx:         ctx,
+			queryID:     uuid.New().String(),
+			agentID:     agent.ID,
+			targetAgent: agent.GetSlug(),
+			fromAgent:   getOptionalString(req, "from_agent"),
+		}
+		if req.Params.Meta != nil {
+			progress.progressToken = req.Params.Meta.ProgressToken
+		}
+	}
+
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		cmd := exec.CommandContext(ctx, claudePath, args...)
-		cmd.Dir = agent.Folder
-		cmd.Env = providers.BuildShellEnv()
+		if progress != nil {
+			output, cmdErr = s.runStreamingQuery(ctx, claudePath, args, agent.Folder, progress)
+		} else {
+			cmd := exec.CommandContext(ctx, claudePath, args...)
+			cmd.Dir = agent.Folder
+			cmd.Env = providers.BuildShellEnvFor(agent.Folder)
 
-		output, cmdErr = cmd.CombinedOutput()
+			procStart := metrics.ProcessStarted(metrics.ProcessQuery)
+			output, cmdErr = cmd.CombinedOutput()
+			metrics.ProcessExited(metrics.ProcessQuery, procStart)
+		}
+		providers.RateLimits().Observe(string(output), cmdErr)
 		if cmdErr == nil {
 			break // Success
 		}
@@ -163,20 +242,21 @@ func (s *MCPService) handleAgentQuery(ctx context.Context, req mcp.CallToolReque
 
 		if !isTransient || attempt == maxRetries {
 			// Log full command for debugging on final failure
-			fmt.Printf("[MCP:AgentQuery] FAILED (attempt %d/%d) - reproduce with:\n  cd %q && %s %s\n",
+			logger.Warnf("AgentQuery: FAILED (attempt %d/%d) - reproduce with:\n  cd %q && %s %s",
 				attempt, maxRetries, agent.Folder, claudePath, strings.Join(args, " "))
-			fmt.Printf("[MCP:AgentQuery] Error: %v\n", cmdErr)
-			fmt.Printf("[MCP:AgentQuery] Output: %s\n", outputStr)
+			logger.Warnf("AgentQuery: Error: %v", cmdErr)
+			logger.Infof("AgentQuery: Output: %s", outputStr)
 			return mcp.NewToolResultError(fmt.Sprintf("Query failed: %v\nOutput: %s", cmdErr, outputStr)), nil
 		}
 
 		// Transient error - wait and retry
-		fmt.Printf("[MCP:AgentQuery] Transient API error (attempt %d/%d), retrying in %dms...\n",
+		logger.Infof("AgentQuery: Transient API error (attempt %d/%d), retrying in %dms...",
 			attempt, maxRetries, attempt*500)
 		time.Sleep(time.Duration(attempt*500) * time.Millisecond)
 	}
 
-	return mcp.NewToolResultText(string(output)), nil
+	s.cacheQueryResult("AgentQuery", agent.ID, agent.GetSlug(), query, string(output))
+	return mcp.NewToolResultText(queueNote(ticket) + string(output)), nil
 }
 
 // handleSelfQuery handles the SelfQuery tool call
@@ -209,12 +289,16 @@ func (s *MCPService) handleSelfQuery(ctx context.Context, req mcp.CallToolReques
 		)), nil
 	}
 
-	// Get claude binary path
-	claudePath := providers.GetClaudePath()
+	// Get claude binary path (per-agent override or global)
+	claudePath := providers.ClaudePathFor(agent.Folder)
 	if claudePath == "" {
 		return mcp.NewToolResultError("claude CLI not found"), nil
 	}
 
+	if cached := s.cachedQueryResult(req, "SelfQuery", agent.ID, query); cached != nil {
+		return cached, nil
+	}
+
 	// Get system prompt from configurable instructions (SelfQuery has its own)
 	systemPrompt := s.toolInstructions.GetInstructions().SelfQuerySystemPrompt
 
@@ -225,16 +309,27 @@ func (s *MCPService) handleSelfQuery(ctx context.Context, req mcp.CallToolReques
 	// The child is a stateless query - it doesn't need inter-agent tools.
 	// We also disallow Task to prevent spawning subagents (causes concurrent API conflicts).
 	// Prepend "SelfQuery: " so session list shows these as self-queries, not regular sessions.
-	args := []string{
-		"--print",
-		"--disallowed-tools", "Task",
-		"-p", "SelfQuery: " + query,
-	}
+	args := providers.AppendSupportedFlag(append(providers.ExtraCLIArgsFor(agent.Folder), "--print"), "--disallowed-tools", "Task")
+	args = append(args, "-p", types.SelfQueryPrefix+query)
 
 	// Only append system prompt if configured
 	if systemPrompt != "" {
-		args = append(args, "--append-system-prompt", systemPrompt)
+		args = providers.AppendSupportedFlag(args, "--append-system-prompt", systemPrompt)
+	}
+
+	// Wait for a free query slot (bounded per workspace to avoid API concurrency errors)
+	ticket, err := s.acquireQuerySlot(ctx, req, "SelfQuery", agent.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("SelfQuery cancelled while queued: %v", err)), nil
 	}
+	defer ticket.Release()
+
+	// Then for a global spawn slot (background lane: user sends go first)
+	slot, err := providers.Spawns().Acquire(ctx, providers.SpawnRequest{Priority: providers.PriorityNested, Kind: "self-query", Folder: agent.Folder})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("SelfQuery cancelled while queued: %v", err)), nil
+	}
+	defer slot.Release()
 
 	// Retry logic for transient API concurrency errors
 	var output []byte
@@ -244,9 +339,12 @@ func (s *MCPService) handleSelfQuery(ctx context.Context, req mcp.CallToolReques
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		cmd := exec.CommandContext(ctx, claudePath, args...)
 		cmd.Dir = agent.Folder // Run in CALLER'S folder (key difference from AgentQuery)
-		cmd.Env = providers.BuildShellEnv()
+		cmd.Env = providers.BuildShellEnvFor(agent.Folder)
 
+		procStart := metrics.ProcessStarted(metrics.ProcessQuery)
 		output, cmdErr = cmd.CombinedOutput()
+		metrics.ProcessExited(metrics.ProcessQuery, procStart)
+		providers.RateLimits().Observe(string(output), cmdErr)
 		if cmdErr == nil {
 			break // Success
 		}
@@ -258,46 +356,320 @@ func (s *MCPService) handleSelfQuery(ctx context.Context, req mcp.CallToolReques
 
 		if !isTransient || attempt == maxRetries {
 			// Log full command for debugging on final failure
-			fmt.Printf("[MCP:SelfQuery] FAILED (attempt %d/%d) - reproduce with:\n  cd %q && %s %s\n",
+			logger.Warnf("SelfQuery: FAILED (attempt %d/%d) - reproduce with:\n  cd %q && %s %s",
 				attempt, maxRetries, agent.Folder, claudePath, strings.Join(args, " "))
-			fmt.Printf("[MCP:SelfQuery] Error: %v\n", cmdErr)
-			fmt.Printf("[MCP:SelfQuery] Output: %s\n", outputStr)
+			logger.Warnf("SelfQuery: Error: %v", cmdErr)
+			logger.Infof("SelfQuery: Output: %s", outputStr)
 			return mcp.NewToolResultError(fmt.Sprintf("SelfQuery failed: %v\nOutput: %s", cmdErr, outputStr)), nil
 		}
 
 		// Transient error - wait and retry
-		fmt.Printf("[MCP:SelfQuery] Transient API error (attempt %d/%d), retrying in %dms...\n",
+		logger.Infof("SelfQuery: Transient API error (attempt %d/%d), retrying in %dms...",
 			attempt, maxRetries, attempt*500)
 		time.Sleep(time.Duration(attempt*500) * time.Millisecond)
 	}
 
+	s.cacheQueryResult("SelfQuery", agent.ID, agent.GetSlug(), query, string(output))
+	return mcp.NewToolResultText(queueNote(ticket) + string(output)), nil
+}
+
+// maxDiffBytes caps AgentDiffRequest output so a huge diff doesn't blow the caller's context
+const maxDiffBytes = 100 * 1024
+
+// handleAgentDiffRequest handles the AgentDiffRequest tool call
+// Runs git diff in the target agent's folder, gated on the target's own git diff permission
+func (s *MCPService) handleAgentDiffRequest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentDiffRequest") {
+		return mcp.NewToolResultError("AgentDiffRequest tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	targetAgent, err := req.RequireString("target_agent")
+	if err != nil {
+		return mcp.NewToolResultError("target_agent is required"), nil
+	}
+	pathspec := getOptionalString(req, "pathspec")
+	staged := getOptionalString(req, "staged") == "true"
+	fromAgent := getOptionalString(req, "from_agent")
+
+	agent := s.findMCPEnabledAgent(targetAgent)
+	if agent == nil {
+		available := s.getAvailableAgentSlugs()
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Agent '%s' not found or MCP disabled. Available agents: %s",
+			targetAgent, strings.Join(available, ", "),
+		)), nil
+	}
+
+	// Respect the target's permissions: only diff if that agent could run git diff itself
+	if !s.agentAllowsGitDiff(agent.Folder) {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Agent '%s' does not allow Bash(git diff:*). Enable the Git permission set for that agent first (the active environment profile must not deny it).",
+			agent.GetSlug(),
+		)), nil
+	}
+
+	args := []string{"-C", agent.Folder, "diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	args = append(args, "--")
+	for _, p := range strings.Fields(pathspec) {
+		// Keep pathspecs inside the target folder
+		if filepath.IsAbs(p) || strings.HasPrefix(p, ":") || strings.Contains(filepath.ToSlash(filepath.Clean(p)), "..") {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid pathspec %q: must be relative to the agent's folder", p)), nil
+		}
+		args = append(args, p)
+	}
+
+	logger.Infof("AgentDiffRequest: %s -> %s (staged=%v, pathspec=%q)", fromAgent, agent.GetSlug(), staged, pathspec)
+
+	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(cmdCtx, "git", args...).CombinedOutput()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("git diff failed: %v\nOutput: %s", err, string(output))), nil
+	}
+
+	if len(output) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No uncommitted changes in %s.", agent.GetSlug())), nil
+	}
+	if len(output) > maxDiffBytes {
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\n[truncated: diff is %d bytes, showing first %d — narrow with pathspec]",
+			string(output[:maxDiffBytes]), len(output), maxDiffBytes)), nil
+	}
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// gitDiffPatterns are the allow-list entries that let an agent run git diff.
+var gitDiffPatterns = []string{"Bash", "Bash(git:*)", "Bash(git diff:*)"}
+
+// agentAllowsGitDiff reports whether an agent could run git diff itself. It
+// goes through the same effective permissions its Claude processes get, so the
+// active environment profile's deny list and withheld (unacknowledged) YOLO
+// patterns apply here too.
+func (s *MCPService) agentAllowsGitDiff(folder string) bool {
+	if s.claude == nil {
+		return false
+	}
+	eff := s.claude.EffectivePermissions(folder, "")
+	denied := strings.Split(eff.DisallowedTools, ",")
+	if slices.ContainsFunc(gitDiffPatterns, func(p string) bool { return slices.Contains(denied, p) }) {
+		return false
+	}
+	return slices.ContainsFunc(strings.Split(eff.AllowedTools, ","), func(t string) bool {
+		return slices.Contains(gitDiffPatterns, t)
+	})
+}
+
+// handleAgentNewSession handles the AgentNewSession tool call
+// Creates an instant session in the target agent's folder and announces it to the UI
+func (s *MCPService) handleAgentNewSession(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentNewSession") {
+		return mcp.NewToolResultError("AgentNewSession tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	targetAgent, err := req.RequireString("target_agent")
+	if err != nil {
+		return mcp.NewToolResultError("target_agent is required"), nil
+	}
+	fromAgent := getOptionalString(req, "from_agent")
+
+	agent := s.findMCPEnabledAgent(targetAgent)
+	if agent == nil {
+		available := s.getAvailableAgentSlugs()
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Agent '%s' not found or MCP disabled. Available agents: %s",
+			targetAgent, strings.Join(available, ", "),
+		)), nil
+	}
+
+	if s.sessions == nil {
+		return mcp.NewToolResultError("session service not initialized"), nil
+	}
+
+	sessionID, err := s.sessions.CreateSession(agent.Folder)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create session: %v", err)), nil
+	}
+	logger.Infof("AgentNewSession: %s created session %s for %s", fromAgent, sessionID, agent.GetSlug())
+	s.emitSessionDiscovered(agent, sessionID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created new session for %s.\nsession_id: %s", agent.GetSlug(), sessionID)), nil
+}
+
+// emitSessionDiscovered announces a session created by a tool right away — the
+// file watcher would also discover it, but only after debounce
+func (s *MCPService) emitSessionDiscovered(agent *workspace.Agent, sessionID string) {
+	if s.emitFunc == nil {
+		return
+	}
+	now := time.Now()
+	s.emitFunc(types.EventEnvelope{
+		AgentID:   agent.ID,
+		SessionID: sessionID,
+		EventType: "session:discovered",
+		Payload: map[string]any{
+			"agentId": agent.ID,
+			"session": types.Session{
+				ID:        sessionID,
+				AgentID:   agent.ID,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		},
+	})
+}
+
+// handleAgentHandoff handles the AgentHandoff tool call
+// Opens (or reuses) a session on the target agent, links it to the caller's
+// session and sends the handoff packet as its opening message
+func (s *MCPService) handleAgentHandoff(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentHandoff") {
+		return mcp.NewToolResultError("AgentHandoff tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	targetAgent, err := req.RequireString("target_agent")
+	if err != nil {
+		return mcp.NewToolResultError("target_agent is required"), nil
+	}
+	summary, err := req.RequireString("summary")
+	if err != nil || strings.TrimSpace(summary) == "" {
+		return mcp.NewToolResultError("summary is required"), nil
+	}
+	fromAgent := getOptionalString(req, "from_agent")
+	sessionID := getOptionalString(req, "session_id")
+
+	agent := s.findMCPEnabledAgent(targetAgent)
+	if agent == nil {
+		available := s.getAvailableAgentSlugs()
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Agent '%s' not found or MCP disabled. Available agents: %s",
+			targetAgent, strings.Join(available, ", "),
+		)), nil
+	}
+	if s.handoffFunc == nil || s.sessions == nil {
+		return mcp.NewToolResultError("handoff is not available (session service not initialized)"), nil
+	}
+
+	handoff := Handoff{FromAgentSlug: fromAgent, Summary: strings.TrimSpace(summary)}
+	baseFolder := ""
+	if source := s.findMCPEnabledAgent(fromAgent); source != nil {
+		if source.ID == agent.ID {
+			return mcp.NewToolResultError("cannot hand off to yourself; use AgentNewSession and AgentMessage instead"), nil
+		}
+		handoff.FromAgentID = source.ID
+		handoff.FromAgentSlug = source.GetSlug()
+		handoff.FromFolder = source.Folder
+		handoff.FromSessionID = s.requestingSession(fromAgent)
+		baseFolder = source.Folder
+	}
+	handoff.Files = parseHandoffFiles(getOptionalString(req, "files"), baseFolder)
+	if getOptionalString(req, "include_session_summary") == "true" {
+		handoff.SessionSummary = s.requestingSessionSummary(ctx, fromAgent)
+	}
+
+	created := false
+	if sessionID == "" {
+		sessionID, err = s.sessions.CreateSession(agent.Folder)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create session: %v", err)), nil
+		}
+		created = true
+	} else if _, err := os.Stat(claudehome.SessionPath(agent.Folder, sessionID)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Session '%s' not found for agent '%s'", sessionID, agent.GetSlug())), nil
+	}
+
+	handoff.Message = formatHandoffPacket(handoff)
+	if err := s.handoffFunc(agent.ID, sessionID, handoff); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to hand off to %s: %v", agent.GetSlug(), err)), nil
+	}
+	if created {
+		s.emitSessionDiscovered(agent, sessionID)
+	}
+	logger.Infof("AgentHandoff: %s handed off to %s (session %s, %d files)", handoff.FromAgentSlug, agent.GetSlug(), sessionID, len(handoff.Files))
+
+	return mcp.NewToolResultText(fmt.Sprintf(
+		"Handed off to %s. The agent is now working on it in the background.\nsession_id: %s",
+		agent.GetSlug(), sessionID,
+	)), nil
+}
+
+// parseHandoffFiles splits a comma/newline separated file list, resolving
+// relative paths against the handing-off agent's folder
+func parseHandoffFiles(raw, baseFolder string) []string {
+	var files []string
+	for _, f := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !filepath.IsAbs(f) && baseFolder != "" {
+			f = filepath.Join(baseFolder, f)
+		}
+		if !slices.Contains(files, f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// formatHandoffPacket renders the opening message of a handoff session
+func formatHandoffPacket(h Handoff) string {
+	from := h.FromAgentSlug
+	if from == "" {
+		from = "another agent"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<handoff from=\"%s\"", from)
+	if h.FromSessionID != "" {
+		fmt.Fprintf(&b, " session=\"%s\"", h.FromSessionID)
+	}
+	b.WriteString(">\n")
+	if h.FromFolder != "" {
+		fmt.Fprintf(&b, "<source_folder>%s</source_folder>\n", h.FromFolder)
+	}
+	fmt.Fprintf(&b, "<summary>\n%s\n</summary>\n", h.Summary)
+	if h.SessionSummary != "" {
+		b.WriteString(h.SessionSummary + "\n")
+	}
+	if len(h.Files) > 0 {
+		b.WriteString("<files>\n")
+		for _, f := range h.Files {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+		b.WriteString("</files>\n")
+	}
+	b.WriteString("</handoff>\n\n")
+	fmt.Fprintf(&b, "%s handed this work over to you. Read the files above as needed, then continue from the summary.", from)
+	return b.String()
+}
+
 // handleAgentMessage handles the AgentMessage tool call
 // Sends a message to one or more specific agents' inboxes
 func (s *MCPService) handleAgentMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Check tool availability
 	if !s.toolAvailability.IsEnabled("AgentMessage") {
-		fmt.Println("[MCP:AgentMessage] Tool is disabled")
+		logger.Debugf("AgentMessage: tool is disabled")
 		return mcp.NewToolResultError("AgentMessage tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
 
 	// Accept BOTH target_agent (singular) and target_agents (plural) for flexibility
 	// Claude sometimes uses singular even though schema says plural
+	targetSpecialization := getOptionalString(req, "target_specialization")
+	targetTags := getOptionalString(req, "target_tags")
 	targetAgents, err := req.RequireString("target_agents")
 	if err != nil {
 		// Try singular form as fallback
 		targetAgents, err = req.RequireString("target_agent")
-		if err != nil {
-			fmt.Println("[MCP:AgentMessage] Error: neither target_agents nor target_agent provided")
-			return mcp.NewToolResultError("target_agents is required (target_agent also accepted)"), nil
+		if err != nil && targetSpecialization == "" && targetTags == "" {
+			logger.Warnf("AgentMessage: neither target_agents nor target_agent provided")
+			return mcp.NewToolResultError("target_agents is required (target_agent also accepted) unless target_specialization or target_tags is given"), nil
 		}
 	}
 
 	message, err := req.RequireString("message")
 	if err != nil {
-		fmt.Println("[MCP:AgentMessage] Error: message is required")
+		logger.Warnf("AgentMessage: message is required")
 		return mcp.NewToolResultError("message is required"), nil
 	}
 
@@ -307,20 +679,40 @@ func (s *MCPService) handleAgentMessage(ctx context.Context, req mcp.CallToolReq
 	if priority == "" {
 		priority = "normal"
 	}
+	threadID := getOptionalString(req, "thread_id")
 
-	fmt.Printf("[MCP:AgentMessage] From: %s, To: %s, Priority: %s\n", fromAgent, targetAgents, priority)
+	logger.Infof("AgentMessage: From: %s, To: %s, Priority: %s", fromAgent, targetAgents, priority)
 
 	// Reload agent registry from disk in case Syncthing updated it externally.
 	// Without this, newly-enabled AGENT_CROSS_WORKSPACE flags won't be visible
 	// until ClaudeFu restarts.
 	if s.manager != nil {
 		if err := s.manager.ReloadAgentRegistryIfChanged(); err != nil {
-			fmt.Printf("[MCP:AgentMessage] Warning: failed to reload agents.json: %v\n", err)
+			logger.Warnf("AgentMessage: Warning: failed to reload agents.json: %v", err)
 		}
 	}
 
-	// Parse comma-separated agent list
+	// Parse comma-separated agent list. All recipients share one thread so replies
+	// from any of them land in the same conversation.
+	if threadID == "" {
+		threadID = uuid.New().String()
+	}
 	agentIdentifiers := strings.Split(targetAgents, ",")
+	if targetSpecialization != "" || targetTags != "" {
+		matched := s.findAgentsByTarget(targetSpecialization, targetTags)
+		if len(matched) == 0 {
+			errMsg := fmt.Sprintf("No MCP-enabled agents match specialization %q / tags %q", targetSpecialization, targetTags)
+			logger.Warnf("AgentMessage: Error: %s", errMsg)
+			return mcp.NewToolResultError(errMsg), nil
+		}
+		for _, agent := range matched {
+			if !slices.ContainsFunc(agentIdentifiers, func(id string) bool {
+				return strings.EqualFold(strings.TrimSpace(id), agent.GetSlug())
+			}) {
+				agentIdentifiers = append(agentIdentifiers, agent.GetSlug())
+			}
+		}
+	}
 	var sentTo []string
 	var notFound []string
 
@@ -329,59 +721,11 @@ func (s *MCPService) handleAgentMessage(ctx context.Context, req mcp.CallToolReq
 		if identifier == "" {
 			continue
 		}
-
-		// Find specific agent (must be MCP-enabled in current workspace)
-		agent := s.findMCPEnabledAgent(identifier)
-		if agent == nil {
-			// Cross-workspace fallback: check global registry for AGENT_CROSS_WORKSPACE=true
-			if s.manager != nil {
-				if info, folder := s.manager.FindAgentBySlug(identifier); info != nil {
-					flagVal := info.Meta["AGENT_CROSS_WORKSPACE"]
-					fmt.Printf("[MCP:AgentMessage] Registry lookup '%s': id=%s folder=%s AGENT_CROSS_WORKSPACE=%q\n",
-						identifier, info.ID[:8], folder, flagVal)
-					if strings.ToLower(flagVal) == "true" {
-						// Cross-workspace message: write to spool (JSON file)
-						// instead of direct SQLite. Syncthing replicates the
-						// spool file, the receiver's SpoolManager imports it.
-						if s.spool == nil {
-							fmt.Printf("[MCP:AgentMessage] ERROR: spool manager not initialized\n")
-							notFound = append(notFound, identifier)
-							continue
-						}
-						spoolMsg := InboxMessage{
-							ID:            uuid.New().String(),
-							FromAgentID:   "",
-							FromAgentName: fromAgent,
-							ToAgentID:     info.ID,
-							Message:       message,
-							Priority:      priority,
-							Timestamp:     time.Now(),
-							Read:          false,
-						}
-						if err := s.spool.WriteMessage(info.ID, spoolMsg); err != nil {
-							fmt.Printf("[MCP:AgentMessage] Failed to write spool file: %v\n", err)
-							notFound = append(notFound, identifier)
-							continue
-						}
-						fmt.Printf("[MCP:AgentMessage] Cross-workspace spool write: %s -> %s\n", identifier, info.ID[:8])
-						sentTo = append(sentTo, info.GetSlug())
-						continue
-					}
-					fmt.Printf("[MCP:AgentMessage] AGENT_CROSS_WORKSPACE not enabled for '%s' — enable in Workspaces & Agents > Cross-Workspace tab\n", identifier)
-				} else {
-					fmt.Printf("[MCP:AgentMessage] Registry lookup '%s': NOT FOUND in agents.json\n", identifier)
-				}
-			}
-			fmt.Printf("[MCP:AgentMessage] Agent not found: %s\n", identifier)
+		if slug, ok := s.deliverMessage(identifier, fromAgent, message, priority, threadID, ""); ok {
+			sentTo = append(sentTo, slug)
+		} else {
 			notFound = append(notFound, identifier)
-			continue
 		}
-
-		// Add to inbox
-		fmt.Printf("[MCP:AgentMessage] Adding message to inbox for agent: %s (ID: %s)\n", agent.GetSlug(), agent.ID)
-		s.inbox.AddMessage(agent.ID, "", fromAgent, message, priority)
-		s.emitInboxUpdate(agent.ID)
-		sentTo = append(sentTo, agent.GetSlug())
 	}
 
 	// Build response
@@ -389,7 +733,7 @@ func (s *MCPService) handleAgentMessage(ctx context.Context, req mcp.CallToolReq
 		available := s.getAvailableAgentSlugs()
 		errMsg := fmt.Sprintf("No valid agents found. Requested: %s. Available agents: %s",
 			targetAgents, strings.Join(available, ", "))
-		fmt.Printf("[MCP:AgentMessage] Error: %s\n", errMsg)
+		logger.Warnf("AgentMessage: Error: %s", errMsg)
 		return mcp.NewToolResultError(errMsg), nil
 	}
 
@@ -397,23 +741,81 @@ func (s *MCPService) handleAgentMessage(ctx context.Context, req mcp.CallToolReq
 	if len(notFound) > 0 {
 		response += fmt.Sprintf(" (not found: %s)", strings.Join(notFound, ", "))
 	}
+	response += fmt.Sprintf("\nthread_id: %s", threadID)
 
-	fmt.Printf("[MCP:AgentMessage] Success: %s\n", response)
+	logger.Infof("AgentMessage: Success: %s", response)
 	return mcp.NewToolResultText(response), nil
 }
 
+// deliverMessage puts a message in one agent's inbox: directly for MCP-enabled agents in
+// the current workspace, or via the spool for AGENT_CROSS_WORKSPACE agents elsewhere.
+// Returns the recipient's slug, or false if the agent could not be reached.
+func (s *MCPService) deliverMessage(identifier, fromAgent, message, priority, threadID, replyToID string) (string, bool) {
+	// Find specific agent (must be MCP-enabled in current workspace)
+	agent := s.findMCPEnabledAgent(identifier)
+	if agent == nil {
+		// Cross-workspace fallback: check global registry for AGENT_CROSS_WORKSPACE=true
+		if s.manager != nil {
+			if info, folder := s.manager.FindAgentBySlug(identifier); info != nil {
+				flagVal := info.Meta["AGENT_CROSS_WORKSPACE"]
+				logger.Infof("AgentMessage: Registry lookup '%s': id=%s folder=%s AGENT_CROSS_WORKSPACE=%q",
+					identifier, info.ID[:8], folder, flagVal)
+				if strings.ToLower(flagVal) == "true" {
+					// Cross-workspace message: write to spool (JSON file)
+					// instead of direct SQLite. Syncthing replicates the
+					// spool file, the receiver's SpoolManager imports it.
+					if s.spool == nil {
+						logger.Warnf("AgentMessage: ERROR: spool manager not initialized")
+						return "", false
+					}
+					spoolMsg := InboxMessage{
+						ID:            uuid.New().String(),
+						FromAgentID:   "",
+						FromAgentName: fromAgent,
+						ToAgentID:     info.ID,
+						Message:       message,
+						Priority:      priority,
+						Timestamp:     time.Now(),
+						Read:          false,
+						ThreadID:      threadID,
+						ReplyToID:     replyToID,
+					}
+					if err := s.spool.WriteMessage(info.ID, spoolMsg); err != nil {
+						logger.Warnf("AgentMessage: Failed to write spool file: %v", err)
+						return "", false
+					}
+					logger.Infof("AgentMessage: Cross-workspace spool write: %s -> %s", identifier, info.ID[:8])
+					return info.GetSlug(), true
+				}
+				logger.Infof("AgentMessage: AGENT_CROSS_WORKSPACE not enabled for '%s' — enable in Workspaces & Agents > Cross-Workspace tab", identifier)
+			} else {
+				logger.Infof("AgentMessage: Registry lookup '%s': NOT FOUND in agents.json", identifier)
+			}
+		}
+		logger.Infof("AgentMessage: Agent not found: %s", identifier)
+		return "", false
+	}
+
+	// Add to inbox
+	logger.Infof("AgentMessage: Adding message to inbox for agent: %s (ID: %s)", agent.GetSlug(), agent.ID)
+	s.inbox.AddMessage(agent.ID, "", fromAgent, message, priority, threadID, replyToID)
+	s.emitInboxUpdate(agent.ID)
+	return agent.GetSlug(), true
+
+}
+
 // handleAgentBroadcast handles the AgentBroadcast tool call
 // Broadcasts a message to ALL agents' inboxes in the workspace
 func (s *MCPService) handleAgentBroadcast(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Check tool availability
 	if !s.toolAvailability.IsEnabled("AgentBroadcast") {
-		fmt.Println("[MCP:AgentBroadcast] Tool is disabled")
+		logger.Debugf("AgentBroadcast: tool is disabled")
 		return mcp.NewToolResultError("AgentBroadcast tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
 
 	message, err := req.RequireString("message")
 	if err != nil {
-		fmt.Println("[MCP:AgentBroadcast] Error: message is required")
+		logger.Warnf("AgentBroadcast: message is required")
 		return mcp.NewToolResultError("message is required"), nil
 	}
 
@@ -424,82 +826,233 @@ func (s *MCPService) handleAgentBroadcast(ctx context.Context, req mcp.CallToolR
 		priority = "normal"
 	}
 
-	fmt.Printf("[MCP:AgentBroadcast] From: %s, Priority: %s\n", fromAgent, priority)
+	targetSpecialization := getOptionalString(req, "target_specialization")
+	targetTags := getOptionalString(req, "target_tags")
+
+	logger.Infof("AgentBroadcast: From: %s, Priority: %s, Specialization: %q, Tags: %q", fromAgent, priority, targetSpecialization, targetTags)
 
 	// Get workspace
 	ws := s.workspace()
 	if ws == nil {
-		fmt.Println("[MCP:AgentBroadcast] Error: no workspace loaded")
+		logger.Warnf("AgentBroadcast: no workspace loaded")
 		return mcp.NewToolResultError("no workspace loaded"), nil
 	}
 
-	// Broadcast to ALL MCP-enabled agents
+	// Broadcast to all MCP-enabled agents, scoped by the optional filters
 	count := 0
 	var sentTo []string
-	for _, agent := range ws.Agents {
-		if !agent.GetMCPEnabled() {
-			continue // Skip agents with MCP disabled
-		}
-		s.inbox.AddMessage(agent.ID, "", fromAgent, message, priority)
+	for _, agent := range s.findAgentsByTarget(targetSpecialization, targetTags) {
+		s.inbox.AddMessage(agent.ID, "", fromAgent, message, priority, "", "")
 		s.emitInboxUpdate(agent.ID)
 		sentTo = append(sentTo, agent.GetSlug())
 		count++
 	}
 
 	if count == 0 {
-		fmt.Println("[MCP:AgentBroadcast] Error: No MCP-enabled agents found")
+		if targetSpecialization != "" || targetTags != "" {
+			errMsg := fmt.Sprintf("No MCP-enabled agents match specialization %q / tags %q", targetSpecialization, targetTags)
+			logger.Warnf("AgentBroadcast: Error: %s", errMsg)
+			return mcp.NewToolResultError(errMsg), nil
+		}
+		logger.Warnf("AgentBroadcast: no MCP-enabled agents found")
 		return mcp.NewToolResultError("No MCP-enabled agents found in workspace"), nil
 	}
 
 	response := fmt.Sprintf("Broadcast sent to %d agents: %s", count, strings.Join(sentTo, ", "))
-	fmt.Printf("[MCP:AgentBroadcast] Success: %s\n", response)
+	logger.Infof("AgentBroadcast: Success: %s", response)
 	return mcp.NewToolResultText(response), nil
 }
 
-// handleNotifyUser handles the NotifyUser tool call
-// Emits an event to show a notification in the ClaudeFu UI
-func (s *MCPService) handleNotifyUser(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Check tool availability
-	if !s.toolAvailability.IsEnabled("NotifyUser") {
-		return mcp.NewToolResultError("NotifyUser tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+// handleAgentInboxList handles the AgentInboxList tool call
+// Lists the calling agent's inbox (or one thread) and marks listed messages read
+func (s *MCPService) handleAgentInboxList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentInboxList") {
+		return mcp.NewToolResultError("AgentInboxList tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
 
-	message, err := req.RequireString("message")
+	agentID, err := s.resolveAgentID(getOptionalString(req, "from_agent"))
 	if err != nil {
-		return mcp.NewToolResultError("message is required"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	unreadOnly := getOptionalString(req, "unread_only") == "true"
+	markRead := getOptionalString(req, "mark_read") != "false"
+	threadID := getOptionalString(req, "thread_id")
 
-	notifType, err := req.RequireString("type")
-	if err != nil {
-		return mcp.NewToolResultError("type is required"), nil
+	limit := 20
+	if args, ok := req.Params.Arguments.(map[string]any); ok {
+		if f, ok := args["limit"].(float64); ok && f > 0 {
+			limit = int(f)
+		}
 	}
 
-	// Validate type
-	validTypes := map[string]bool{"info": true, "success": true, "warning": true, "question": true}
-	if !validTypes[notifType] {
-		return mcp.NewToolResultError("type must be one of: info, success, warning, question"), nil
+	var messages []InboxMessage
+	if threadID != "" {
+		// Thread view spans every local inbox so both sides of the conversation
+		// show, limited to threads the caller is part of
+		messages = s.inbox.GetThreadForAgent(threadID, agentID)
+	} else {
+		messages = s.inbox.GetMessages(agentID)
+	}
+	if unreadOnly {
+		filtered := make([]InboxMessage, 0)
+		for _, msg := range messages {
+			if !msg.Read {
+				filtered = append(filtered, msg)
+			}
+		}
+		messages = filtered
+	}
+	if len(messages) > limit {
+		if threadID != "" {
+			messages = messages[len(messages)-limit:] // Thread is oldest-first: keep the latest
+		} else {
+			messages = messages[:limit] // Inbox is newest-first
+		}
 	}
 
-	// Optional fields
-	title, _ := req.RequireString("title")
-	fromAgent, _ := req.RequireString("from_agent")
+	if len(messages) == 0 {
+		if threadID != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("No messages found in thread %s.", threadID)), nil
+		}
+		return mcp.NewToolResultText("No inbox messages found."), nil
+	}
 
-	// Emit notification event
-	s.emitFunc(types.EventEnvelope{
-		EventType: "mcp:notification",
-		Payload: map[string]any{
-			"type":       notifType,
-			"message":    message,
-			"title":      title,
-			"from_agent": fromAgent,
-		},
-	})
+	// Format results as XML for clean parsing by Claude
+	var sb strings.Builder
+	if threadID != "" {
+		sb.WriteString(fmt.Sprintf("<thread id=\"%s\" count=\"%d\">\n", threadID, len(messages)))
+	} else {
+		sb.WriteString(fmt.Sprintf("<inbox count=\"%d\" unread=\"%d\">\n", len(messages), s.inbox.GetUnreadCount(agentID)))
+	}
+	marked := 0
+	for _, msg := range messages {
+		attrs := fmt.Sprintf("id=\"%s\" thread_id=\"%s\" from=\"%s\" priority=\"%s\" time=\"%s\" read=\"%t\"",
+			msg.ID, msg.GetThreadID(), msg.FromAgentName, msg.Priority, msg.Timestamp.Format(time.RFC3339), msg.Read)
+		if msg.ReplyToID != "" {
+			attrs += fmt.Sprintf(" reply_to=\"%s\"", msg.ReplyToID)
+		}
+		if threadID != "" && msg.ToAgentID != agentID {
+			attrs += fmt.Sprintf(" to=\"%s\"", msg.ToAgentID)
+		}
+		sb.WriteString(fmt.Sprintf("<message %s>\n%s\n</message>\n", attrs, msg.Message))
 
-	return mcp.NewToolResultText("Notification sent"), nil
+		if markRead && !msg.Read && msg.ToAgentID == agentID {
+			if s.inbox.MarkRead(agentID, msg.ID) {
+				marked++
+			}
+		}
+	}
+	if threadID != "" {
+		sb.WriteString("</thread>")
+	} else {
+		sb.WriteString("</inbox>")
+	}
+
+	if marked > 0 {
+		s.emitInboxUpdate(agentID)
+	}
+	return mcp.NewToolResultText(sb.String()), nil
 }
 
-// emitQuestionDismissed tells the frontend to clear the pending question dialog.
-// This fires when the question is answered, skipped, timed out, or cancelled —
+// handleAgentInboxReply handles the AgentInboxReply tool call
+// Replies to an inbox message in the same thread, delivered to the original sender
+func (s *MCPService) handleAgentInboxReply(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentInboxReply") {
+		return mcp.NewToolResultError("AgentInboxReply tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	messageID, err := req.RequireString("message_id")
+	if err != nil {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+	message, err := req.RequireString("message")
+	if err != nil {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+	priority := getOptionalString(req, "priority")
+	if priority == "" {
+		priority = "normal"
+	}
+	fromAgent := getOptionalString(req, "from_agent")
+	agentID, err := s.resolveAgentID(fromAgent)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	original := s.inbox.GetMessage(agentID, messageID)
+	if original == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("message %s not found in your inbox — use AgentInboxList to get message IDs", messageID)), nil
+	}
+	if original.FromAgentName == "" {
+		return mcp.NewToolResultError("this message has no sender to reply to"), nil
+	}
+
+	// Sign the reply with our slug so the recipient can reply back
+	senderName := fromAgent
+	if s.manager != nil {
+		if info, _ := s.manager.FindAgentByID(agentID); info != nil && info.GetSlug() != "" {
+			senderName = info.GetSlug()
+		}
+	}
+
+	threadID := original.GetThreadID()
+	logger.Infof("AgentInboxReply: From: %s, To: %s, Thread: %s", senderName, original.FromAgentName, threadID)
+	slug, ok := s.deliverMessage(original.FromAgentName, senderName, message, priority, threadID, original.ID)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("could not deliver reply: agent '%s' is not reachable", original.FromAgentName)), nil
+	}
+
+	if !original.Read && s.inbox.MarkRead(agentID, original.ID) {
+		s.emitInboxUpdate(agentID)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Reply sent to: %s\nthread_id: %s", slug, threadID)), nil
+}
+
+// handleNotifyUser handles the NotifyUser tool call
+// Emits an event to show a notification in the ClaudeFu UI
+func (s *MCPService) handleNotifyUser(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Check tool availability
+	if !s.toolAvailability.IsEnabled("NotifyUser") {
+		return mcp.NewToolResultError("NotifyUser tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	message, err := req.RequireString("message")
+	if err != nil {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+
+	notifType, err := req.RequireString("type")
+	if err != nil {
+		return mcp.NewToolResultError("type is required"), nil
+	}
+
+	// Validate type
+	validTypes := map[string]bool{"info": true, "success": true, "warning": true, "question": true}
+	if !validTypes[notifType] {
+		return mcp.NewToolResultError("type must be one of: info, success, warning, question"), nil
+	}
+
+	// Optional fields
+	title, _ := req.RequireString("title")
+	fromAgent, _ := req.RequireString("from_agent")
+
+	// Emit notification event
+	s.emitFunc(types.EventEnvelope{
+		EventType: "mcp:notification",
+		Payload: map[string]any{
+			"type":       notifType,
+			"message":    message,
+			"title":      title,
+			"from_agent": fromAgent,
+		},
+	})
+
+	return mcp.NewToolResultText("Notification sent"), nil
+}
+
+// emitQuestionDismissed tells the frontend to clear the pending question dialog.
+// This fires when the question is answered, skipped, timed out, or cancelled —
 // ensuring the UI never gets stuck showing a stale question dialog.
 func (s *MCPService) emitQuestionDismissed(questionID string) {
 	s.emitFunc(types.EventEnvelope{
@@ -520,6 +1073,24 @@ func (s *MCPService) emitPermissionDismissed(requestID string) {
 	})
 }
 
+// emitPermissionAnswered reports the user's decision on a permission request
+// (for the activity timeline; the dialog is cleared by emitPermissionDismissed).
+func (s *MCPService) emitPermissionAnswered(pr *PendingPermissionRequest, response *PermissionResponse) {
+	s.emitFunc(types.EventEnvelope{
+		SessionID: pr.SessionID,
+		EventType: "mcp:permission-request:answered",
+		Payload: map[string]any{
+			"requestId":   pr.ID,
+			"agentSlug":   pr.AgentSlug,
+			"requested":   pr.Permissions,
+			"granted":     response.Granted,
+			"permissions": response.Permissions,
+			"scope":       response.Scope,
+			"denyReason":  response.DenyReason,
+		},
+	})
+}
+
 // emitPlanReviewDismissed tells the frontend to clear the pending plan review dialog.
 func (s *MCPService) emitPlanReviewDismissed(reviewID string) {
 	s.emitFunc(types.EventEnvelope{
@@ -534,7 +1105,7 @@ func (s *MCPService) emitPlanReviewDismissed(reviewID string) {
 func (s *MCPService) emitInboxUpdate(agentID string) {
 	unread := s.inbox.GetUnreadCount(agentID)
 	total := s.inbox.GetTotalCount(agentID)
-	fmt.Printf("[MCP:Inbox] Emitting update for agent %s: unread=%d, total=%d\n", agentID, unread, total)
+	logger.Infof("Inbox: Emitting update for agent %s: unread=%d, total=%d", agentID, unread, total)
 	s.emitFunc(types.EventEnvelope{
 		AgentID:   agentID,
 		EventType: "mcp:inbox",
@@ -584,7 +1155,7 @@ func (s *MCPService) handleAskUserQuestion(ctx context.Context, req mcp.CallTool
 		fromAgent = "unknown"
 	}
 
-	fmt.Printf("[MCP:AskUser] Received question from agent %s with %d questions\n", fromAgent, len(questions))
+	logger.Infof("AskUser: Received question from agent %s with %d questions", fromAgent, len(questions))
 
 	// Create pending question with response channel
 	pq := s.pendingQuestions.Create(fromAgent, questions)
@@ -606,12 +1177,12 @@ func (s *MCPService) handleAskUserQuestion(ctx context.Context, req mcp.CallTool
 	case answer, ok := <-pq.ResponseCh:
 		if !ok {
 			// Channel was closed (cancelled)
-			fmt.Printf("[MCP:AskUser] Question %s channel closed (cancelled)\n", pq.ID[:8])
+			logger.Infof("AskUser: Question %s channel closed (cancelled)", pq.ID[:8])
 			s.emitQuestionDismissed(pq.ID)
 			return mcp.NewToolResultError("Question was cancelled"), nil
 		}
 		if answer.Skipped {
-			fmt.Printf("[MCP:AskUser] Question %s skipped by user\n", pq.ID[:8])
+			logger.Infof("AskUser: Question %s skipped by user", pq.ID[:8])
 			s.emitQuestionDismissed(pq.ID)
 			return mcp.NewToolResultError("User skipped the question"), nil
 		}
@@ -621,28 +1192,37 @@ func (s *MCPService) handleAskUserQuestion(ctx context.Context, req mcp.CallTool
 			"answers":   answer.Answers,
 		}
 		resultJSON, _ := json.Marshal(result)
-		fmt.Printf("[MCP:AskUser] Returning answer for question %s\n", pq.ID[:8])
+		logger.Infof("AskUser: Returning answer for question %s", pq.ID[:8])
 		s.emitQuestionDismissed(pq.ID)
 		return mcp.NewToolResultText(string(resultJSON)), nil
 
 	case <-ctx.Done():
 		// Context cancelled (e.g., Claude disconnected from MCP SSE)
-		fmt.Printf("[MCP:AskUser] Question %s: context cancelled (Claude disconnected)\n", pq.ID[:8])
+		logger.Infof("AskUser: Question %s: context cancelled (Claude disconnected)", pq.ID[:8])
 		s.pendingQuestions.Cancel(pq.ID)
 		s.emitQuestionDismissed(pq.ID)
 		return mcp.NewToolResultError("Request cancelled"), nil
 
 	case <-s.ctx.Done():
 		// Server shutting down
-		fmt.Printf("[MCP:AskUser] Question %s: server shutting down\n", pq.ID[:8])
+		logger.Infof("AskUser: Question %s: server shutting down", pq.ID[:8])
 		s.pendingQuestions.Cancel(pq.ID)
 		s.emitQuestionDismissed(pq.ID)
 		return mcp.NewToolResultError("Server shutting down"), nil
 
 	case <-time.After(timeout):
 		// Timeout — user didn't answer in time
-		fmt.Printf("[MCP:AskUser] Question %s: TIMED OUT after %v\n", pq.ID[:8], timeout)
+		logger.Warnf("AskUser: Question %s: TIMED OUT after %v", pq.ID[:8], timeout)
 		s.pendingQuestions.Cancel(pq.ID)
+		s.emitFunc(types.EventEnvelope{
+			EventType: "mcp:askuser:timeout",
+			Payload: map[string]any{
+				"id":        pq.ID,
+				"agentSlug": pq.AgentSlug,
+				"questions": pq.Questions,
+				"timeout":   timeout.String(),
+			},
+		})
 		s.emitQuestionDismissed(pq.ID)
 		return mcp.NewToolResultError(fmt.Sprintf("Question timed out after %v. The user did not respond in time.", timeout)), nil
 	}
@@ -680,34 +1260,26 @@ func (s *MCPService) handleBrowserAgent(ctx context.Context, req mcp.CallToolReq
 		fromAgent = fa
 	}
 
-	fmt.Printf("[MCP:BrowserAgent] Request from %s, timeout: %ds\n", fromAgent, timeout)
+	logger.Infof("BrowserAgent: Request from %s, timeout: %ds", fromAgent, timeout)
 
 	// Check bridge health
-	healthClient := &http.Client{Timeout: 5 * time.Second}
-	resp, err := healthClient.Get("http://localhost:9320/health")
-	if err != nil {
-		return mcp.NewToolResultError("Browser bridge not available. Ensure Chrome extension bridge is running on localhost:9320."), nil
-	}
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return mcp.NewToolResultError(fmt.Sprintf("Browser bridge health check failed: %d", resp.StatusCode)), nil
+	bridge := s.browserBridgeConfig()
+	if err := pingBrowserBridge(ctx, bridge); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Browser bridge not available (%v). Ensure the Chrome extension bridge is running, or set its address in MCP settings.", err)), nil
 	}
 
-	// Connect to WebSocket
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-	conn, _, err := dialer.DialContext(ctx, "ws://localhost:9320/ws", nil)
+	// Wait for the browser if it is working on another request
+	ticket, err := s.acquireBrowserSlot(ctx, req, fromAgent)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to connect to browser bridge: %v", err)), nil
+		return mcp.NewToolResultError("BrowserAgent request cancelled while queued"), nil
 	}
-	defer conn.Close()
+	defer ticket.Release()
 
 	// Generate unique request ID
 	requestID := fmt.Sprintf("claudefu-%d", time.Now().UnixNano())
 
 	// Build report instructions that tell Claude in Browser where to submit findings
-	reportURL := fmt.Sprintf("http://localhost:9320/report/%s", requestID)
+	reportURL := bridge.URL("http", "/report/"+requestID)
 	reportInstructions := fmt.Sprintf(`
 
 ---
@@ -726,36 +1298,39 @@ Navigate to %s and submit your findings in the form.
 		},
 	}
 
-	if err := conn.WriteJSON(request); err != nil {
+	replies, err := s.sendBrowserBridgeRequest(ctx, bridge, requestID, request)
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to send request to bridge: %v", err)), nil
 	}
 
-	fmt.Printf("[MCP:BrowserAgent] Request %s sent, waiting for response...\n", requestID[:16])
+	logger.Infof("BrowserAgent: Request %s sent, waiting for response...", requestID[:16])
 
 	// Wait for response with timeout (+30 seconds buffer for response transmission)
-	conn.SetReadDeadline(time.Now().Add(time.Duration(timeout+30) * time.Second))
-
-	var response struct {
-		Type      string `json:"type"`
-		RequestID string `json:"request_id"`
-		Content   string `json:"content"`
-		IsError   bool   `json:"is_error"`
-	}
+	timer := time.NewTimer(time.Duration(timeout+30) * time.Second)
+	defer timer.Stop()
 
-	if err := conn.ReadJSON(&response); err != nil {
-		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+	var response bridgeResponse
+	select {
+	case r, ok := <-replies:
+		if !ok {
 			return mcp.NewToolResultError("Browser bridge connection closed"), nil
 		}
+		response = r
+	case <-timer.C:
+		s.cancelBrowserBridgeRequest(requestID)
 		return mcp.NewToolResultError(fmt.Sprintf("Timeout waiting for browser findings (waited %ds)", timeout)), nil
+	case <-ctx.Done():
+		s.cancelBrowserBridgeRequest(requestID)
+		return mcp.NewToolResultError("BrowserAgent request cancelled"), nil
 	}
 
-	fmt.Printf("[MCP:BrowserAgent] Received response for %s (is_error: %v)\n", requestID[:16], response.IsError)
+	logger.Infof("BrowserAgent: Received response for %s (is_error: %v)", requestID[:16], response.IsError)
 
 	if response.IsError {
-		return mcp.NewToolResultError(response.Content), nil
+		return mcp.NewToolResultError(queueNote(ticket) + response.Content), nil
 	}
 
-	return mcp.NewToolResultText(response.Content), nil
+	return mcp.NewToolResultText(queueNote(ticket) + response.Content), nil
 }
 
 // handleRequestToolPermission handles the RequestToolPermission tool call
@@ -766,9 +1341,21 @@ func (s *MCPService) handleRequestToolPermission(ctx context.Context, req mcp.Ca
 		return mcp.NewToolResultError("RequestToolPermission tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
 
-	permission, err := req.RequireString("permission")
-	if err != nil {
-		return mcp.NewToolResultError("permission is required"), nil
+	// A single permission and/or a batch of related ones
+	args, _ := req.Params.Arguments.(map[string]any)
+	var requested []string
+	if permission := getOptionalString(req, "permission"); permission != "" {
+		requested = append(requested, permission)
+	}
+	if batch, ok := args["permissions"].([]any); ok {
+		for _, item := range batch {
+			if p, ok := item.(string); ok && p != "" && !slices.Contains(requested, p) {
+				requested = append(requested, p)
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return mcp.NewToolResultError("permission or permissions is required"), nil
 	}
 
 	reason, err := req.RequireString("reason")
@@ -776,27 +1363,40 @@ func (s *MCPService) handleRequestToolPermission(ctx context.Context, req mcp.Ca
 		return mcp.NewToolResultError("reason is required"), nil
 	}
 
-	// Optional: get from_agent for logging
-	args, _ := req.Params.Arguments.(map[string]any)
+	// Optional: get from_agent for logging and session attribution
 	fromAgent, _ := args["from_agent"].(string)
 	if fromAgent == "" {
 		fromAgent = "unknown"
 	}
+	sessionID := s.requestingSession(fromAgent)
 
-	fmt.Printf("[MCP:RequestToolPermission] Request from %s for '%s': %s\n", fromAgent, permission, reason)
+	logger.Infof("RequestToolPermission: Request from %s (session %q) for %q: %s", fromAgent, sessionID, requested, reason)
 
 	// Create pending permission request with response channel
-	pr := s.pendingPermissions.Create(fromAgent, permission, reason)
+	pr := s.pendingPermissions.Create(fromAgent, sessionID, requested, reason)
+	if err := s.permissionLog.Record(PermissionLogEntry{
+		RequestID:   pr.ID,
+		Time:        pr.CreatedAt,
+		AgentSlug:   pr.AgentSlug,
+		SessionID:   pr.SessionID,
+		Permissions: pr.Permissions,
+		Reason:      pr.Reason,
+		Decision:    PermissionDecisionPending,
+	}); err != nil {
+		logger.Warnf("RequestToolPermission: Failed to log request %s: %v", pr.ID[:8], err)
+	}
 
 	// Emit event to frontend to show dialog
 	s.emitFunc(types.EventEnvelope{
 		EventType: "mcp:permission-request",
 		Payload: map[string]any{
-			"id":         pr.ID,
-			"agentSlug":  pr.AgentSlug,
-			"permission": pr.Permission,
-			"reason":     pr.Reason,
-			"createdAt":  pr.CreatedAt.Format(time.RFC3339),
+			"id":          pr.ID,
+			"agentSlug":   pr.AgentSlug,
+			"permission":  pr.Permission,
+			"permissions": pr.Permissions,
+			"reason":      pr.Reason,
+			"sessionId":   pr.SessionID,
+			"createdAt":   pr.CreatedAt.Format(time.RFC3339),
 		},
 	})
 
@@ -805,54 +1405,144 @@ func (s *MCPService) handleRequestToolPermission(ctx context.Context, req mcp.Ca
 	select {
 	case response, ok := <-pr.ResponseCh:
 		s.emitPermissionDismissed(pr.ID)
+		if ok {
+			s.emitPermissionAnswered(pr, response)
+		}
 		if !ok {
 			// Channel was closed (cancelled)
-			fmt.Printf("[MCP:RequestToolPermission] Request %s channel closed (cancelled)\n", pr.ID[:8])
+			logger.Infof("RequestToolPermission: Request %s channel closed (cancelled)", pr.ID[:8])
+			s.logPermissionDecision(pr, PermissionDecisionCancelled, nil)
 			return mcp.NewToolResultError("Permission request was cancelled"), nil
 		}
 		if !response.Granted {
+			s.logPermissionDecision(pr, PermissionDecisionDenied, response)
 			msg := "Permission denied by user"
 			if response.DenyReason != "" {
 				msg += ": " + response.DenyReason
 			}
 			return mcp.NewToolResultError(msg), nil
 		}
-		// Permission granted
+		var saveErr error
+		switch response.Scope {
+		case PermissionScopeSession:
+			providers.AddSessionGrants(pr.SessionID, response.Permissions)
+		case PermissionScopePermanent:
+			saveErr = s.savePermanentGrants(fromAgent, response.Permissions)
+		}
+		logged := *response
+		logged.Permanent = logged.Permanent && saveErr == nil
+		s.logPermissionDecision(pr, PermissionDecisionGranted, &logged)
+		// Permission granted (possibly a subset of a batch)
+		var denied []string
+		for _, p := range pr.Permissions {
+			if !slices.Contains(response.Permissions, p) {
+				denied = append(denied, p)
+			}
+		}
 		result := map[string]any{
-			"granted":   true,
-			"permanent": response.Permanent,
-			"message":   fmt.Sprintf("Permission '%s' granted", permission),
+			"granted":     true,
+			"permanent":   response.Permanent,
+			"scope":       response.Scope,
+			"permissions": response.Permissions,
+			"message":     fmt.Sprintf("Permission %s granted", strings.Join(response.Permissions, ", ")),
+		}
+		switch response.Scope {
+		case PermissionScopePermanent:
+			if saveErr != nil {
+				result["permanent"] = false
+				result["message"] = fmt.Sprintf("Permission %s granted for this request only (could not save to allow list: %v)", strings.Join(response.Permissions, ", "), saveErr)
+			} else {
+				result["message"] = fmt.Sprintf("Permission %s granted and added to allow list", strings.Join(response.Permissions, ", "))
+			}
+		case PermissionScopeSession:
+			result["message"] = fmt.Sprintf("Permission %s granted for the rest of this session", strings.Join(response.Permissions, ", "))
 		}
-		if response.Permanent {
-			result["message"] = fmt.Sprintf("Permission '%s' granted and added to allow list", permission)
+		if len(denied) > 0 {
+			result["denied"] = denied
+			if response.DenyReason != "" {
+				result["denyReason"] = response.DenyReason
+			}
 		}
 		resultJSON, _ := json.Marshal(result)
-		fmt.Printf("[MCP:RequestToolPermission] Permission granted for %s: permanent=%v\n", permission, response.Permanent)
+		logger.Infof("RequestToolPermission: Permission granted for %q: scope=%s", response.Permissions, response.Scope)
 		return mcp.NewToolResultText(string(resultJSON)), nil
 
 	case <-ctx.Done():
 		// Context cancelled (e.g., Claude disconnected)
-		fmt.Printf("[MCP:RequestToolPermission] Request %s: context cancelled (Claude disconnected)\n", pr.ID[:8])
+		logger.Infof("RequestToolPermission: Request %s: context cancelled (Claude disconnected)", pr.ID[:8])
 		s.pendingPermissions.Cancel(pr.ID)
+		s.logPermissionDecision(pr, PermissionDecisionCancelled, nil)
 		s.emitPermissionDismissed(pr.ID)
 		return mcp.NewToolResultError("Request cancelled"), nil
 
 	case <-s.ctx.Done():
 		// Server shutting down
-		fmt.Printf("[MCP:RequestToolPermission] Request %s: server shutting down\n", pr.ID[:8])
+		logger.Infof("RequestToolPermission: Request %s: server shutting down", pr.ID[:8])
 		s.pendingPermissions.Cancel(pr.ID)
+		s.logPermissionDecision(pr, PermissionDecisionCancelled, nil)
 		s.emitPermissionDismissed(pr.ID)
 		return mcp.NewToolResultError("Server shutting down"), nil
 
 	case <-time.After(timeout):
 		// Timeout
-		fmt.Printf("[MCP:RequestToolPermission] Request %s: TIMED OUT after %v\n", pr.ID[:8], timeout)
+		logger.Warnf("RequestToolPermission: Request %s: TIMED OUT after %v", pr.ID[:8], timeout)
 		s.pendingPermissions.Cancel(pr.ID)
+		s.logPermissionDecision(pr, PermissionDecisionTimedOut, nil)
 		s.emitPermissionDismissed(pr.ID)
 		return mcp.NewToolResultError(fmt.Sprintf("Permission request timed out after %v", timeout)), nil
 	}
 }
 
+// savePermanentGrants adds permanently granted patterns to the requesting agent's
+// claudefu.permissions.json so future spawns include them in --allowedTools.
+func (s *MCPService) savePermanentGrants(fromAgent string, patterns []string) error {
+	agent := s.findMCPEnabledAgent(fromAgent)
+	if agent == nil {
+		return fmt.Errorf("unknown agent %q", fromAgent)
+	}
+	mgr, err := permissions.NewManager()
+	if err != nil {
+		return err
+	}
+	added, err := mgr.GrantAgentPermissions(agent.Folder, patterns)
+	if err != nil {
+		logger.Warnf("RequestToolPermission: Failed to save permanent grant for %s: %v", agent.Folder, err)
+		return err
+	}
+	if len(added) > 0 {
+		logger.Infof("RequestToolPermission: Added %q to %s allow list", added, agent.GetSlug())
+		s.emitFunc(types.EventEnvelope{
+			AgentID:   agent.ID,
+			EventType: "permissions:changed",
+			Payload: map[string]any{
+				"folder": agent.Folder,
+				"added":  added,
+			},
+		})
+	}
+	return nil
+}
+
+// requestingSession guesses which session a tool call came from: the only running
+// CLI process in the agent's folder, else the agent's active session in the UI.
+// Returns "" when it cannot tell.
+func (s *MCPService) requestingSession(fromAgent string) string {
+	agent := s.findMCPEnabledAgent(fromAgent)
+	if agent == nil {
+		return ""
+	}
+	if s.claude != nil {
+		if running := s.claude.ActiveSessionsInFolder(agent.Folder); len(running) == 1 {
+			return running[0]
+		}
+	}
+	if s.activeSessionGetter != nil {
+		_, sessionID, _, _ := s.activeSessionGetter(agent.GetSlug())
+		return sessionID
+	}
+	return ""
+}
+
 // handleExitPlanMode handles the ExitPlanMode tool call
 // This replaces Claude's built-in ExitPlanMode which fails in non-interactive CLI mode
 func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -872,10 +1562,16 @@ func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolReq
 		}
 	}
 
-	fmt.Printf("[MCP:ExitPlanMode] Received plan review request from agent %s\n", fromAgent)
+	logger.Infof("ExitPlanMode: Received plan review request from agent %s", fromAgent)
 
 	// Create pending plan review with response channel
-	pr := s.pendingPlanReviews.Create(fromAgent)
+	plan := s.activePlan(fromAgent)
+	planText := ""
+	if plan != nil {
+		planText = plan.Content
+	}
+	pr := s.pendingPlanReviews.Create(fromAgent, planText)
+	revisionSession := s.recordPlanRevision(pr.ID, plan)
 
 	// Emit event to frontend to show plan review UI
 	s.emitFunc(types.EventEnvelope{
@@ -894,11 +1590,13 @@ func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolReq
 		s.emitPlanReviewDismissed(pr.ID)
 		if !ok {
 			// Channel was closed (cancelled)
-			fmt.Printf("[MCP:ExitPlanMode] Review %s channel closed (cancelled)\n", pr.ID[:8])
+			logger.Infof("ExitPlanMode: Review %s channel closed (cancelled)", pr.ID[:8])
+			s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeCancelled, "")
 			return mcp.NewToolResultError("Plan review was cancelled"), nil
 		}
 		if answer.Skipped {
-			fmt.Printf("[MCP:ExitPlanMode] Review %s skipped by user\n", pr.ID[:8])
+			logger.Infof("ExitPlanMode: Review %s skipped by user", pr.ID[:8])
+			s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeSkipped, "")
 			return mcp.NewToolResultError("User skipped the plan review"), nil
 		}
 
@@ -914,7 +1612,8 @@ func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolReq
 		}
 
 		if answer.Accepted {
-			fmt.Printf("[MCP:ExitPlanMode] Plan accepted for review %s\n", pr.ID[:8])
+			logger.Infof("ExitPlanMode: Plan accepted for review %s", pr.ID[:8])
+			s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeAccepted, answer.Feedback)
 			msg := "Plan approved by user. You can now proceed with implementation."
 			if answer.Feedback != "" {
 				msg += "\n\nADDITIONAL ALIGNMENT FEEDBACK: " + answer.Feedback
@@ -926,27 +1625,31 @@ func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolReq
 		if feedback == "" {
 			feedback = "User rejected the plan without specific feedback."
 		}
-		fmt.Printf("[MCP:ExitPlanMode] Plan rejected for review %s: %s\n", pr.ID[:8], feedback)
+		logger.Infof("ExitPlanMode: Plan rejected for review %s: %s", pr.ID[:8], feedback)
+		s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeRejected, answer.Feedback)
 		return mcp.NewToolResultText(fmt.Sprintf("Plan rejected by user.\nUSER REJECTION FEEDBACK: %s\n\nPlease revise your plan based on this feedback and try ExitPlanMode again when ready.", feedback)), nil
 
 	case <-ctx.Done():
 		// Context cancelled (e.g., Claude disconnected)
-		fmt.Printf("[MCP:ExitPlanMode] Review %s: context cancelled (Claude disconnected)\n", pr.ID[:8])
+		logger.Infof("ExitPlanMode: Review %s: context cancelled (Claude disconnected)", pr.ID[:8])
 		s.pendingPlanReviews.Cancel(pr.ID)
+		s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeCancelled, "")
 		s.emitPlanReviewDismissed(pr.ID)
 		return mcp.NewToolResultError("Request cancelled"), nil
 
 	case <-s.ctx.Done():
 		// Server shutting down
-		fmt.Printf("[MCP:ExitPlanMode] Review %s: server shutting down\n", pr.ID[:8])
+		logger.Infof("ExitPlanMode: Review %s: server shutting down", pr.ID[:8])
 		s.pendingPlanReviews.Cancel(pr.ID)
+		s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeCancelled, "")
 		s.emitPlanReviewDismissed(pr.ID)
 		return mcp.NewToolResultError("Server shutting down"), nil
 
 	case <-time.After(timeout):
 		// Timeout
-		fmt.Printf("[MCP:ExitPlanMode] Review %s: TIMED OUT after %v\n", pr.ID[:8], timeout)
+		logger.Warnf("ExitPlanMode: Review %s: TIMED OUT after %v", pr.ID[:8], timeout)
 		s.pendingPlanReviews.Cancel(pr.ID)
+		s.setPlanRevisionOutcome(revisionSession, pr.ID, PlanOutcomeTimedOut, "")
 		s.emitPlanReviewDismissed(pr.ID)
 		return mcp.NewToolResultError(fmt.Sprintf("Plan review timed out after %v", timeout)), nil
 	}
@@ -956,14 +1659,14 @@ func (s *MCPService) handleExitPlanMode(ctx context.Context, req mcp.CallToolReq
 // Returns true if the caller was a subagent (different handling needed).
 func (s *MCPService) writePlanReviewJSONL(fromAgent string, answer *PlanReviewAnswer) bool {
 	if s.activeSessionGetter == nil {
-		fmt.Printf("[MCP:ExitPlanMode] No activeSessionGetter configured, skipping JSONL write\n")
+		logger.Infof("ExitPlanMode: No activeSessionGetter configured, skipping JSONL write")
 		return false
 	}
 
 	// Resolve agent slug to session context
 	agentID, sessionID, folder, slug := s.activeSessionGetter(fromAgent)
 	if sessionID == "" || folder == "" {
-		fmt.Printf("[MCP:ExitPlanMode] Could not resolve active session for agent %s (agentID=%s, sessionID=%s, folder=%s)\n",
+		logger.Warnf("ExitPlanMode: Could not resolve active session for agent %s (agentID=%s, sessionID=%s, folder=%s)",
 			fromAgent, agentID, sessionID, folder)
 		return false
 	}
@@ -976,32 +1679,31 @@ func (s *MCPService) writePlanReviewJSONL(fromAgent string, answer *PlanReviewAn
 	if err != nil {
 		// Fallback: ExitPlanMode was called from a Plan subagent, not the main session.
 		// Scan subagent JSONLs for the tool_use block and any plan content.
-		fmt.Printf("[MCP:ExitPlanMode] Not found in parent JSONL, checking subagents...\n")
+		logger.Infof("ExitPlanMode: Not found in parent JSONL, checking subagents...")
 		subToolID, subUUID, subPath, subPlan, subErr := workspace.FindToolUseInSubagents(folder, sessionID, "mcp__claudefu__ExitPlanMode")
 		if subErr != nil {
-			fmt.Printf("[MCP:ExitPlanMode] Could not find tool_use_id in parent or subagents: %v\n", subErr)
+			logger.Warnf("ExitPlanMode: Could not find tool_use_id in parent or subagents: %v", subErr)
 			return false
 		}
 		toolUseID = subToolID
 		assistantUUID = subUUID
 		isSubagent = true
 		subagentPlanContent = subPlan
-		fmt.Printf("[MCP:ExitPlanMode] Found in subagent %s (toolUseID=%s)\n", filepath.Base(subPath), toolUseID[:12])
+		logger.Infof("ExitPlanMode: Found in subagent %s (toolUseID=%s)", filepath.Base(subPath), toolUseID[:12])
 	}
 
 	// Get the plan file path and content
 	planFilePath := ""
 	planContent := ""
 	if slug != "" {
-		homeDir, _ := os.UserHomeDir()
-		planFilePath = filepath.Join(homeDir, ".claude", "plans", slug+".md")
+		planFilePath = claudehome.PlanPath(slug)
 		data, err := os.ReadFile(planFilePath)
 		if err != nil {
-			fmt.Printf("[MCP:ExitPlanMode] Could not read plan file %s: %v\n", planFilePath, err)
+			logger.Warnf("ExitPlanMode: Could not read plan file %s: %v", planFilePath, err)
 			// Subagent fallback: use plan content extracted from assistant message
 			if subagentPlanContent != "" {
 				planContent = subagentPlanContent
-				fmt.Printf("[MCP:ExitPlanMode] Using plan content from subagent assistant message (%d chars)\n", len(planContent))
+				logger.Infof("ExitPlanMode: Using plan content from subagent assistant message (%d chars)", len(planContent))
 			}
 		} else {
 			planContent = string(data)
@@ -1014,212 +1716,807 @@ func (s *MCPService) writePlanReviewJSONL(fromAgent string, answer *PlanReviewAn
 		// For subagent callers, skip the synthetic JSONL write to the parent session.
 		// The subagent manages its own JSONL and plan state. Writing to the parent
 		// would create orphaned entries since the tool_use_id belongs to the subagent.
-		fmt.Printf("[MCP:ExitPlanMode] Subagent caller — skipping parent JSONL write\n")
+		logger.Infof("ExitPlanMode: Subagent caller — skipping parent JSONL write")
 		return true
 	}
 
-	// Write the synthetic JSONL entry to the parent session
-	if err := workspace.WritePlanReviewResult(folder, sessionID, toolUseID, assistantUUID, slug, answer.Accepted, planContent, planFilePath, answer.Feedback); err != nil {
-		fmt.Printf("[MCP:ExitPlanMode] Failed to write synthetic JSONL: %v\n", err)
+	// Write the synthetic JSONL entry to the parent session
+	if err := workspace.WritePlanReviewResult(folder, sessionID, toolUseID, assistantUUID, slug, answer.Accepted, planContent, planFilePath, answer.Feedback); err != nil {
+		logger.Warnf("ExitPlanMode: Failed to write synthetic JSONL: %v", err)
+	}
+	return false
+}
+
+// =============================================================================
+// BACKLOG TOOL HANDLERS
+// =============================================================================
+
+// handleBacklogAdd handles the BacklogAdd tool call
+func (s *MCPService) handleBacklogAdd(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("BacklogAdd") {
+		return mcp.NewToolResultError("BacklogAdd tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	title, err := req.RequireString("title")
+	if err != nil {
+		return mcp.NewToolResultError("title is required"), nil
+	}
+
+	// Optional parameters
+	contextStr := getOptionalString(req, "context")
+	status := getOptionalString(req, "status")
+	itemType := getOptionalString(req, "type")
+	tags := getOptionalString(req, "tags")
+	parentID := getOptionalString(req, "parent_id")
+	fromAgent := getOptionalString(req, "from_agent")
+
+	// Resolve agent ID from from_agent (UUID, slug, or name)
+	agentID, err := s.resolveAgentID(fromAgent)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if status == "" {
+		status = "idea"
+	}
+	if itemType == "" {
+		itemType = "feature_expansion"
+	}
+
+	createdBy := fromAgent
+	if createdBy == "" {
+		createdBy = "agent"
+	}
+
+	item := s.backlog.AddItem(agentID, title, contextStr, status, itemType, tags, createdBy, parentID)
+
+	// Emit change event
+	s.emitBacklogChanged(agentID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created backlog item: %s (id: %s, status: %s, type: %s)", item.Title, item.ID, item.Status, item.Type)), nil
+}
+
+// handleBacklogUpdate handles the BacklogUpdate tool call
+func (s *MCPService) handleBacklogUpdate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("BacklogUpdate") {
+		return mcp.NewToolResultError("BacklogUpdate tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	id, err := req.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError("id is required"), nil
+	}
+
+	item := s.backlog.GetItem(id)
+	if item == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Backlog item not found: %s", id)), nil
+	}
+
+	previousStatus := item.Status
+
+	// Apply updates only for provided fields
+	if title := getOptionalString(req, "title"); title != "" {
+		item.Title = title
+	}
+	if status := getOptionalString(req, "status"); status != "" {
+		item.Status = status
+	}
+	if itemType := getOptionalString(req, "type"); itemType != "" {
+		item.Type = itemType
+	}
+	if tags := getOptionalString(req, "tags"); tags != "" {
+		item.Tags = tags
+	}
+
+	// Context: support "append:" prefix
+	if contextStr := getOptionalString(req, "context"); contextStr != "" {
+		if strings.HasPrefix(contextStr, "append:") {
+			appendContent := strings.TrimPrefix(contextStr, "append:")
+			if item.Context != "" {
+				item.Context = item.Context + "\n" + appendContent
+			} else {
+				item.Context = appendContent
+			}
+		} else {
+			item.Context = contextStr
+		}
+	}
+
+	ok := s.backlog.UpdateItem(*item)
+	if !ok {
+		return mcp.NewToolResultError("Failed to update backlog item"), nil
+	}
+
+	// Emit change event (use item's agentID)
+	s.emitBacklogChanged(item.AgentID)
+	if item.Status != previousStatus && s.emitFunc != nil {
+		s.emitFunc(BacklogStatusChangedEvent(*item, previousStatus))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Updated backlog item: %s (id: %s, status: %s, type: %s)", item.Title, item.ID, item.Status, item.Type)), nil
+}
+
+// handleBacklogList handles the BacklogList tool call
+func (s *MCPService) handleBacklogList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("BacklogList") {
+		return mcp.NewToolResultError("BacklogList tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	statusFilter := getOptionalString(req, "status")
+	typeFilter := getOptionalString(req, "type")
+	tagFilter := getOptionalString(req, "tag")
+	includeContext := getOptionalString(req, "include_context") == "true"
+	fromAgent := getOptionalString(req, "from_agent")
+
+	// Resolve agent ID from from_agent (UUID, slug, or name)
+	agentID, err := s.resolveAgentID(fromAgent)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	items := s.backlog.GetItemsByAgent(agentID)
+
+	// Filter by status if specified
+	if statusFilter != "" {
+		filtered := make([]BacklogItem, 0)
+		for _, item := range items {
+			if item.Status == statusFilter {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	// Filter by type if specified
+	if typeFilter != "" {
+		filtered := make([]BacklogItem, 0)
+		for _, item := range items {
+			if item.Type == typeFilter {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	// Filter by tag if specified
+	if tagFilter != "" {
+		filtered := make([]BacklogItem, 0)
+		for _, item := range items {
+			if strings.Contains(strings.ToLower(item.Tags), strings.ToLower(tagFilter)) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	if len(items) == 0 {
+		return mcp.NewToolResultText("No backlog items found."), nil
+	}
+
+	// Format results as XML for clean parsing by Claude
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<backlog count=\"%d\">\n", len(items)))
+
+	for _, item := range items {
+		// Build item attributes
+		attrs := fmt.Sprintf("id=\"%s\" status=\"%s\" type=\"%s\"", item.ID, item.Status, item.Type)
+		if item.ParentID != "" {
+			attrs += fmt.Sprintf(" parent_id=\"%s\"", item.ParentID)
+		}
+		if item.Tags != "" {
+			attrs += fmt.Sprintf(" tags=\"%s\"", item.Tags)
+		}
+		if item.CreatedBy != "" {
+			attrs += fmt.Sprintf(" created_by=\"%s\"", item.CreatedBy)
+		}
+		sb.WriteString(fmt.Sprintf("<item %s>\n", attrs))
+		sb.WriteString(fmt.Sprintf("  <title>%s</title>\n", item.Title))
+
+		if includeContext && item.Context != "" {
+			sb.WriteString(fmt.Sprintf("  <context>\n%s\n  </context>\n", item.Context))
+		} else if !includeContext && item.Context != "" {
+			// Truncate to 100 chars
+			ctxStr := item.Context
+			if len(ctxStr) > 100 {
+				ctxStr = ctxStr[:100] + "..."
+			}
+			sb.WriteString(fmt.Sprintf("  <context>%s</context>\n", ctxStr))
+		}
+		sb.WriteString("</item>\n")
+	}
+
+	sb.WriteString("</backlog>")
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// handleBacklogMove handles the BacklogMove tool call
+func (s *MCPService) handleBacklogMove(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("BacklogMove") {
+		return mcp.NewToolResultError("BacklogMove tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	id, err := req.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError("id is required"), nil
+	}
+	targetAgent, err := req.RequireString("target_agent")
+	if err != nil {
+		return mcp.NewToolResultError("target_agent is required"), nil
+	}
+	targetID, err := s.resolveAgentID(targetAgent)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sourceID, err := s.backlog.MoveItemToAgent(id, targetID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.Infof("BacklogMove: %s moved %s to %s", getOptionalString(req, "from_agent"), id, targetAgent)
+
+	s.emitBacklogChanged(sourceID)
+	s.emitBacklogChanged(targetID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Moved backlog item %s (with subtasks) to %s", id, targetAgent)), nil
+}
+
+// handleBacklogExport handles the BacklogExport tool call
+func (s *MCPService) handleBacklogExport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("BacklogExport") {
+		return mcp.NewToolResultError("BacklogExport tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+
+	identifier := getOptionalString(req, "target_agent")
+	if identifier == "" {
+		identifier = getOptionalString(req, "from_agent")
+	}
+	agentID, err := s.resolveAgentID(identifier)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	format := getOptionalString(req, "format")
+	if format == "" {
+		format = BacklogFormatMarkdown
+	}
+
+	data, err := s.backlog.ExportAgent(agentID, format)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// =============================================================================
+// TASK GRAPH TOOL HANDLERS
+// =============================================================================
+
+// handleTaskCreate handles the TaskCreate tool call
+func (s *MCPService) handleTaskCreate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("TaskCreate") {
+		return mcp.NewToolResultError("TaskCreate tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+	ws, errResult := s.taskWorkspace()
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	title, err := req.RequireString("title")
+	if err != nil {
+		return mcp.NewToolResultError("title is required"), nil
+	}
+	input := tasks.CreateInput{
+		Title:       title,
+		Description: getOptionalString(req, "description"),
+		SessionID:   getOptionalString(req, "session_id"),
+		CreatedBy:   getOptionalString(req, "from_agent"),
+	}
+	for _, dep := range strings.Split(getOptionalString(req, "depends_on"), ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			input.DependsOn = append(input.DependsOn, dep)
+		}
+	}
+	if assignTo := getOptionalString(req, "assign_to"); assignTo != "" {
+		agent := s.findMCPEnabledAgent(assignTo)
+		if agent == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' not found. Available agents: %s",
+				assignTo, strings.Join(s.getAvailableAgentSlugs(), ", "))), nil
+		}
+		input.AssignedAgentID = agent.ID
+	}
+
+	task, err := s.tasks.Create(ws.ID, input)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.Infof("TaskCreate: Created task %s: %s", task.ID[:8], task.Title)
+	s.emitTasksChanged(*task, "created")
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created task %s (%s)", task.ID, task.Title)), nil
+}
+
+// handleTaskAssign handles the TaskAssign tool call
+func (s *MCPService) handleTaskAssign(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("TaskAssign") {
+		return mcp.NewToolResultError("TaskAssign tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+	ws, errResult := s.taskWorkspace()
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	taskID, err := req.RequireString("task_id")
+	if err != nil {
+		return mcp.NewToolResultError("task_id is required"), nil
+	}
+	targetAgent, err := req.RequireString("target_agent")
+	if err != nil {
+		return mcp.NewToolResultError("target_agent is required"), nil
+	}
+	agent := s.findMCPEnabledAgent(targetAgent)
+	if agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' not found. Available agents: %s",
+			targetAgent, strings.Join(s.getAvailableAgentSlugs(), ", "))), nil
+	}
+
+	task, err := s.tasks.Assign(ws.ID, taskID, agent.ID, getOptionalString(req, "session_id"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.Infof("TaskAssign: Assigned task %s to %s", task.ID[:8], agent.GetSlug())
+	s.emitTasksChanged(*task, "assigned")
+
+	return mcp.NewToolResultText(fmt.Sprintf("Assigned task %s (%s) to %s", task.ID, task.Title, agent.GetSlug())), nil
+}
+
+// handleTaskComplete handles the TaskComplete tool call
+func (s *MCPService) handleTaskComplete(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("TaskComplete") {
+		return mcp.NewToolResultError("TaskComplete tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+	ws, errResult := s.taskWorkspace()
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	taskID, err := req.RequireString("task_id")
+	if err != nil {
+		return mcp.NewToolResultError("task_id is required"), nil
+	}
+
+	task, err := s.tasks.Complete(ws.ID, taskID, getOptionalString(req, "result"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.Infof("TaskComplete: Completed task %s", task.ID[:8])
+	s.emitTasksChanged(*task, "completed")
+
+	// Tell the caller what just became unblocked
+	response := fmt.Sprintf("Completed task %s (%s)", task.ID, task.Title)
+	if graph, err := s.tasks.Graph(ws.ID); err == nil {
+		var unblocked []string
+		for _, node := range graph.Nodes {
+			if node.State == tasks.StateReady && slices.Contains(node.DependsOn, task.ID) {
+				unblocked = append(unblocked, fmt.Sprintf("%s (%s)", node.ID, node.Title))
+			}
+		}
+		if len(unblocked) > 0 {
+			response += "\nNow ready: " + strings.Join(unblocked, ", ")
+		}
+	}
+	return mcp.NewToolResultText(response), nil
+}
+
+// handleTaskGraph handles the TaskGraph tool call
+func (s *MCPService) handleTaskGraph(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("TaskGraph") {
+		return mcp.NewToolResultError("TaskGraph tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+	}
+	ws, errResult := s.taskWorkspace()
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	graph, err := s.tasks.Graph(ws.ID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	stateFilter := getOptionalString(req, "state")
+
+	slugs := make(map[string]string, len(ws.Agents))
+	for _, agent := range ws.Agents {
+		slugs[agent.ID] = agent.GetSlug()
+	}
+
+	// Format results as XML for clean parsing by Claude
+	var sb strings.Builder
+	count := 0
+	for _, node := range graph.Nodes {
+		if stateFilter != "" && node.State != stateFilter {
+			continue
+		}
+		count++
+		attrs := fmt.Sprintf("id=\"%s\" state=\"%s\"", node.ID, node.State)
+		if node.AssignedAgentID != "" {
+			attrs += fmt.Sprintf(" agent=\"%s\"", slugs[node.AssignedAgentID])
+		}
+		if node.Activity != "" {
+			attrs += fmt.Sprintf(" activity=\"%s\"", node.Activity)
+		}
+		if len(node.DependsOn) > 0 {
+			attrs += fmt.Sprintf(" depends_on=\"%s\"", strings.Join(node.DependsOn, ","))
+		}
+		if len(node.BlockedBy) > 0 {
+			attrs += fmt.Sprintf(" blocked_by=\"%s\"", strings.Join(node.BlockedBy, ","))
+		}
+		sb.WriteString(fmt.Sprintf("<task %s>\n", attrs))
+		sb.WriteString(fmt.Sprintf("  <title>%s</title>\n", node.Title))
+		if node.Description != "" {
+			sb.WriteString(fmt.Sprintf("  <description>%s</description>\n", node.Description))
+		}
+		if node.Result != "" {
+			sb.WriteString(fmt.Sprintf("  <result>%s</result>\n", node.Result))
+		}
+		sb.WriteString("</task>\n")
+	}
+
+	if count == 0 {
+		return mcp.NewToolResultText("No tasks found."), nil
 	}
-	return false
+	return mcp.NewToolResultText(fmt.Sprintf("<tasks count=\"%d\">\n%s</tasks>", count, sb.String())), nil
 }
 
-// =============================================================================
-// BACKLOG TOOL HANDLERS
-// =============================================================================
-
-// handleBacklogAdd handles the BacklogAdd tool call
-func (s *MCPService) handleBacklogAdd(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if !s.toolAvailability.IsEnabled("BacklogAdd") {
-		return mcp.NewToolResultError("BacklogAdd tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+// handleExportSession handles the ExportSession tool call
+func (s *MCPService) handleExportSession(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("ExportSession") {
+		return mcp.NewToolResultError("ExportSession tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
-
-	title, err := req.RequireString("title")
+	sessionID, err := req.RequireString("session_id")
 	if err != nil {
-		return mcp.NewToolResultError("title is required"), nil
+		return mcp.NewToolResultError("session_id is required"), nil
+	}
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	targetSlug := getOptionalString(req, "target_agent")
+	if targetSlug == "" {
+		targetSlug = getOptionalString(req, "from_agent")
+	}
+	if targetSlug == "" {
+		return mcp.NewToolResultError("target_agent (or from_agent) is required"), nil
+	}
+	format := getOptionalString(req, "format")
+	if format == "" {
+		format = export.FormatMarkdown
 	}
 
-	// Optional parameters
-	contextStr := getOptionalString(req, "context")
-	status := getOptionalString(req, "status")
-	itemType := getOptionalString(req, "type")
-	tags := getOptionalString(req, "tags")
-	parentID := getOptionalString(req, "parent_id")
-	fromAgent := getOptionalString(req, "from_agent")
+	agent := s.findMCPEnabledAgent(targetSlug)
+	if agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' not found or MCP disabled", targetSlug)), nil
+	}
 
-	// Resolve agent ID from from_agent (UUID, slug, or name)
-	agentID, err := s.resolveAgentID(fromAgent)
+	meta := export.Meta{
+		Title:     "Session " + sessionID,
+		AgentName: agent.GetSlug(),
+		Folder:    agent.Folder,
+		SessionID: sessionID,
+	}
+	data, err := export.ExportSession(meta, format)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	return mcp.NewToolResultText(string(data)), nil
+}
 
-	if status == "" {
-		status = "idea"
+// handleSessionSummary handles the SessionSummary tool call
+// Returns the cached summary of a session, regenerating it if the session has new messages
+func (s *MCPService) handleSessionSummary(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("SessionSummary") {
+		return mcp.NewToolResultError("SessionSummary tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
-	if itemType == "" {
-		itemType = "feature_expansion"
+	if s.summaries == nil {
+		return mcp.NewToolResultError("session summaries are not available"), nil
 	}
-
-	createdBy := fromAgent
-	if createdBy == "" {
-		createdBy = "agent"
+	fromAgent := getOptionalString(req, "from_agent")
+	targetSlug := getOptionalString(req, "target_agent")
+	if targetSlug == "" {
+		targetSlug = fromAgent
+	}
+	if targetSlug == "" {
+		return mcp.NewToolResultError("target_agent (or from_agent) is required"), nil
 	}
 
-	item := s.backlog.AddItem(agentID, title, contextStr, status, itemType, tags, createdBy, parentID)
+	agent := s.findMCPEnabledAgent(targetSlug)
+	if agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' not found or MCP disabled", targetSlug)), nil
+	}
+	sessionID := getOptionalString(req, "session_id")
+	if sessionID == "" {
+		if source := s.findMCPEnabledAgent(fromAgent); source == nil || source.ID != agent.ID {
+			return mcp.NewToolResultError("session_id is required when summarizing another agent's session"), nil
+		}
+		if sessionID = s.requestingSession(fromAgent); sessionID == "" {
+			return mcp.NewToolResultError("could not determine your current session; pass session_id"), nil
+		}
+	}
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Emit change event
-	s.emitBacklogChanged(agentID)
+	sum, err := s.summaries.Summarize(providers.WithNestedSpawn(ctx), agent.Folder, sessionID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to summarize session: %v", err)), nil
+	}
+	s.emitSessionSummarized(agent.ID, sum)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Created backlog item: %s (id: %s, status: %s, type: %s)", item.Title, item.ID, item.Status, item.Type)), nil
+	if getOptionalString(req, "format") == "json" {
+		data, err := json.MarshalIndent(sum, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+	return mcp.NewToolResultText(sum.Format()), nil
 }
 
-// handleBacklogUpdate handles the BacklogUpdate tool call
-func (s *MCPService) handleBacklogUpdate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if !s.toolAvailability.IsEnabled("BacklogUpdate") {
-		return mcp.NewToolResultError("BacklogUpdate tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+// requestingSessionSummary summarizes the session a tool call came from, for
+// AgentHandoff and AgentQuery. Returns "" when the session is unknown or the
+// summary fails; callers go ahead without it.
+func (s *MCPService) requestingSessionSummary(ctx context.Context, fromAgent string) string {
+	source := s.findMCPEnabledAgent(fromAgent)
+	if s.summaries == nil || source == nil {
+		return ""
 	}
-
-	id, err := req.RequireString("id")
+	sessionID := s.requestingSession(fromAgent)
+	if sessionID == "" {
+		return ""
+	}
+	sum, err := s.summaries.Summarize(providers.WithNestedSpawn(ctx), source.Folder, sessionID)
 	if err != nil {
-		return mcp.NewToolResultError("id is required"), nil
+		logger.Warnf("Session summary for %s (%s) failed: %v", source.GetSlug(), sessionID, err)
+		return ""
 	}
+	s.emitSessionSummarized(source.ID, sum)
+	return sum.Format()
+}
 
-	item := s.backlog.GetItem(id)
-	if item == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Backlog item not found: %s", id)), nil
+// emitSessionSummarized tells the frontend a session's cached summary changed
+func (s *MCPService) emitSessionSummarized(agentID string, sum *summary.Summary) {
+	if s.emitFunc == nil {
+		return
 	}
+	s.emitFunc(types.EventEnvelope{
+		AgentID:   agentID,
+		SessionID: sum.SessionID,
+		EventType: "session:summarized",
+		Payload:   map[string]any{"summary": sum},
+	})
+}
 
-	// Apply updates only for provided fields
-	if title := getOptionalString(req, "title"); title != "" {
-		item.Title = title
+// handleAgentStatus handles the AgentStatus tool call
+func (s *MCPService) handleAgentStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.toolAvailability.IsEnabled("AgentStatus") {
+		return mcp.NewToolResultError("AgentStatus tool is disabled. Enable in MCP Settings > Tool Availability."), nil
 	}
-	if status := getOptionalString(req, "status"); status != "" {
-		item.Status = status
-	}
-	if itemType := getOptionalString(req, "type"); itemType != "" {
-		item.Type = itemType
-	}
-	if tags := getOptionalString(req, "tags"); tags != "" {
-		item.Tags = tags
+	ws := s.workspace()
+	if ws == nil {
+		return mcp.NewToolResultError("no workspace loaded"), nil
 	}
 
-	// Context: support "append:" prefix
-	if contextStr := getOptionalString(req, "context"); contextStr != "" {
-		if strings.HasPrefix(contextStr, "append:") {
-			appendContent := strings.TrimPrefix(contextStr, "append:")
-			if item.Context != "" {
-				item.Context = item.Context + "\n" + appendContent
-			} else {
-				item.Context = appendContent
+	var agents []workspace.Agent
+	if targetSlug := getOptionalString(req, "target_agent"); targetSlug != "" {
+		agent := s.findMCPEnabledAgent(targetSlug)
+		if agent == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' not found or MCP disabled", targetSlug)), nil
+		}
+		agents = append(agents, *agent)
+	} else {
+		for _, agent := range ws.Agents {
+			if agent.GetMCPEnabled() {
+				agents = append(agents, agent)
 			}
-		} else {
-			item.Context = contextStr
 		}
 	}
 
-	ok := s.backlog.UpdateItem(*item)
-	if !ok {
-		return mcp.NewToolResultError("Failed to update backlog item"), nil
+	// Format results as XML for clean parsing by Claude
+	var sb strings.Builder
+	for _, agent := range agents {
+		var running []string
+		if s.claude != nil {
+			running = s.claude.ActiveSessionsInFolder(agent.Folder)
+		}
+		var activity AgentActivity
+		if s.agentActivityGetter != nil {
+			activity = s.agentActivityGetter(agent.ID)
+		}
+
+		attrs := fmt.Sprintf("slug=\"%s\" busy=\"%t\"", agent.GetSlug(), len(running) > 0)
+		if len(running) > 0 {
+			attrs += fmt.Sprintf(" running_sessions=\"%s\"", strings.Join(running, ","))
+		}
+		if activity.SessionID != "" {
+			attrs += fmt.Sprintf(" session=\"%s\"", activity.SessionID)
+		}
+		attrs += fmt.Sprintf(" unread=\"%d\"", activity.UnreadCount)
+		if !activity.LastActivity.IsZero() {
+			attrs += fmt.Sprintf(" since_last_activity=\"%s\"", time.Since(activity.LastActivity).Round(time.Second))
+		}
+		sb.WriteString(fmt.Sprintf("<agent %s/>\n", attrs))
 	}
 
-	// Emit change event (use item's agentID)
-	s.emitBacklogChanged(item.AgentID)
+	if len(agents) == 0 {
+		return mcp.NewToolResultText("No MCP-enabled agents in this workspace."), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("<agents count=\"%d\">\n%s</agents>", len(agents), sb.String())), nil
+}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Updated backlog item: %s (id: %s, status: %s, type: %s)", item.Title, item.ID, item.Status, item.Type)), nil
+// taskWorkspace returns the current workspace for task tools, or an error result.
+func (s *MCPService) taskWorkspace() (*workspace.Workspace, *mcp.CallToolResult) {
+	if s.tasks == nil {
+		return nil, mcp.NewToolResultError("task graph not initialized")
+	}
+	ws := s.workspace()
+	if ws == nil {
+		return nil, mcp.NewToolResultError("no workspace loaded")
+	}
+	return ws, nil
 }
 
-// handleBacklogList handles the BacklogList tool call
-func (s *MCPService) handleBacklogList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if !s.toolAvailability.IsEnabled("BacklogList") {
-		return mcp.NewToolResultError("BacklogList tool is disabled. Enable in MCP Settings > Tool Availability."), nil
+// emitTasksChanged emits a tasks:changed event so the UI re-renders the task graph
+func (s *MCPService) emitTasksChanged(task tasks.Task, action string) {
+	if s.emitFunc == nil {
+		return
 	}
+	s.emitFunc(types.EventEnvelope{
+		AgentID:   task.AssignedAgentID,
+		EventType: "tasks:changed",
+		Payload: map[string]any{
+			"action": action,
+			"task":   task,
+		},
+	})
+}
 
-	statusFilter := getOptionalString(req, "status")
-	typeFilter := getOptionalString(req, "type")
-	tagFilter := getOptionalString(req, "tag")
-	includeContext := getOptionalString(req, "include_context") == "true"
-	fromAgent := getOptionalString(req, "from_agent")
+// =============================================================================
+// SHARED MEMORY TOOLS
+// =============================================================================
 
-	// Resolve agent ID from from_agent (UUID, slug, or name)
-	agentID, err := s.resolveAgentID(fromAgent)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// memoryWorkspace returns the current workspace for Memory* tools, or an error result.
+func (s *MCPService) memoryWorkspace(tool string) (*workspace.Workspace, *mcp.CallToolResult) {
+	if !s.toolAvailability.IsEnabled(tool) {
+		return nil, mcp.NewToolResultError(tool + " tool is disabled. Enable in MCP Settings > Tool Availability.")
+	}
+	if s.memory == nil {
+		return nil, mcp.NewToolResultError("shared memory not initialized")
+	}
+	ws := s.workspace()
+	if ws == nil {
+		return nil, mcp.NewToolResultError("no workspace loaded")
 	}
+	return ws, nil
+}
 
-	items := s.backlog.GetItemsByAgent(agentID)
+// handleMemorySet handles the MemorySet tool call
+func (s *MCPService) handleMemorySet(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, errResult := s.memoryWorkspace("MemorySet")
+	if errResult != nil {
+		return errResult, nil
+	}
+	key, err := req.RequireString("key")
+	if err != nil || key == "" {
+		return mcp.NewToolResultError("key is required"), nil
+	}
+	value := getOptionalString(req, "value")
+	namespace := normalizeNamespace(getOptionalString(req, "namespace"))
+	fromAgent := getOptionalString(req, "from_agent")
 
-	// Filter by status if specified
-	if statusFilter != "" {
-		filtered := make([]BacklogItem, 0)
-		for _, item := range items {
-			if item.Status == statusFilter {
-				filtered = append(filtered, item)
-			}
+	if value == "" {
+		deleted, err := s.memory.Delete(ws.ID, namespace, key)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		items = filtered
+		if !deleted {
+			return mcp.NewToolResultText(fmt.Sprintf("No entry %s/%s to delete", namespace, key)), nil
+		}
+		logger.Infof("MemorySet: %s deleted %s/%s", fromAgent, namespace, key)
+		s.emitMemoryChanged(namespace, key, "deleted")
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted %s/%s", namespace, key)), nil
 	}
 
-	// Filter by type if specified
-	if typeFilter != "" {
-		filtered := make([]BacklogItem, 0)
-		for _, item := range items {
-			if item.Type == typeFilter {
-				filtered = append(filtered, item)
-			}
+	var ttl time.Duration
+	if args, ok := req.Params.Arguments.(map[string]any); ok {
+		if f, ok := args["ttl_seconds"].(float64); ok && f > 0 {
+			ttl = time.Duration(f * float64(time.Second))
 		}
-		items = filtered
 	}
+	entry, err := s.memory.Set(ws.ID, namespace, key, value, fromAgent, ttl)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.Infof("MemorySet: %s set %s/%s (%d bytes, ttl=%s)", fromAgent, namespace, key, len(value), ttl)
+	s.emitMemoryChanged(namespace, key, "set")
 
-	// Filter by tag if specified
-	if tagFilter != "" {
-		filtered := make([]BacklogItem, 0)
-		for _, item := range items {
-			if strings.Contains(strings.ToLower(item.Tags), strings.ToLower(tagFilter)) {
-				filtered = append(filtered, item)
-			}
-		}
-		items = filtered
+	msg := fmt.Sprintf("Stored %s/%s", namespace, key)
+	if entry.ExpiresAt != nil {
+		msg += fmt.Sprintf(" (expires %s)", entry.ExpiresAt.Format(time.RFC3339))
 	}
+	return mcp.NewToolResultText(msg), nil
+}
 
-	if len(items) == 0 {
-		return mcp.NewToolResultText("No backlog items found."), nil
+// handleMemoryGet handles the MemoryGet tool call
+func (s *MCPService) handleMemoryGet(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, errResult := s.memoryWorkspace("MemoryGet")
+	if errResult != nil {
+		return errResult, nil
+	}
+	key, err := req.RequireString("key")
+	if err != nil || key == "" {
+		return mcp.NewToolResultError("key is required"), nil
 	}
+	namespace := normalizeNamespace(getOptionalString(req, "namespace"))
 
-	// Format results as XML for clean parsing by Claude
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("<backlog count=\"%d\">\n", len(items)))
+	entry, err := s.memory.Get(ws.ID, namespace, key)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if entry == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("No entry %s/%s", namespace, key)), nil
+	}
+	return mcp.NewToolResultText(formatMemoryEntry(*entry, true)), nil
+}
 
-	for _, item := range items {
-		// Build item attributes
-		attrs := fmt.Sprintf("id=\"%s\" status=\"%s\" type=\"%s\"", item.ID, item.Status, item.Type)
-		if item.ParentID != "" {
-			attrs += fmt.Sprintf(" parent_id=\"%s\"", item.ParentID)
-		}
-		if item.Tags != "" {
-			attrs += fmt.Sprintf(" tags=\"%s\"", item.Tags)
-		}
-		if item.CreatedBy != "" {
-			attrs += fmt.Sprintf(" created_by=\"%s\"", item.CreatedBy)
-		}
-		sb.WriteString(fmt.Sprintf("<item %s>\n", attrs))
-		sb.WriteString(fmt.Sprintf("  <title>%s</title>\n", item.Title))
+// handleMemoryList handles the MemoryList tool call
+func (s *MCPService) handleMemoryList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, errResult := s.memoryWorkspace("MemoryList")
+	if errResult != nil {
+		return errResult, nil
+	}
+	namespace := strings.TrimSpace(getOptionalString(req, "namespace"))
+	includeValues := getOptionalString(req, "include_values") != "false"
 
-		if includeContext && item.Context != "" {
-			sb.WriteString(fmt.Sprintf("  <context>\n%s\n  </context>\n", item.Context))
-		} else if !includeContext && item.Context != "" {
-			// Truncate to 100 chars
-			ctxStr := item.Context
-			if len(ctxStr) > 100 {
-				ctxStr = ctxStr[:100] + "..."
-			}
-			sb.WriteString(fmt.Sprintf("  <context>%s</context>\n", ctxStr))
-		}
-		sb.WriteString("</item>\n")
+	entries, err := s.memory.List(ws.ID, namespace, getOptionalString(req, "prefix"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText("Shared memory has no matching entries."), nil
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(formatMemoryEntry(entry, includeValues))
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("<memory count=\"%d\">\n%s</memory>", len(entries), sb.String())), nil
+}
 
-	sb.WriteString("</backlog>")
-	return mcp.NewToolResultText(sb.String()), nil
+// formatMemoryEntry formats an entry as XML for clean parsing by Claude.
+func formatMemoryEntry(entry MemoryEntry, includeValue bool) string {
+	attrs := fmt.Sprintf("namespace=\"%s\" key=\"%s\" updated_at=\"%s\"",
+		entry.Namespace, entry.Key, entry.UpdatedAt.Format(time.RFC3339))
+	if entry.UpdatedBy != "" {
+		attrs += fmt.Sprintf(" updated_by=\"%s\"", entry.UpdatedBy)
+	}
+	if entry.ExpiresAt != nil {
+		attrs += fmt.Sprintf(" expires_at=\"%s\"", entry.ExpiresAt.Format(time.RFC3339))
+	}
+	if !includeValue {
+		return fmt.Sprintf("<entry %s/>", attrs)
+	}
+	return fmt.Sprintf("<entry %s>%s</entry>", attrs, entry.Value)
+}
+
+// emitMemoryChanged emits memory:changed so an open memory inspector refreshes
+func (s *MCPService) emitMemoryChanged(namespace, key, action string) {
+	if s.emitFunc == nil {
+		return
+	}
+	s.emitFunc(types.EventEnvelope{
+		EventType: "memory:changed",
+		Payload: map[string]any{
+			"namespace": namespace,
+			"key":       key,
+			"action":    action,
+		},
+	})
 }
 
 // =============================================================================
@@ -1621,12 +2918,12 @@ func (s *MCPService) resolveAgentID(identifier string) (string, error) {
 		// Valid UUID format — verify it exists in the global registry
 		if s.manager != nil {
 			if info, _ := s.manager.FindAgentByID(identifier); info != nil {
-				fmt.Printf("[MCP:resolveAgent] UUID '%s' found in registry (slug: %s, name: %s)\n", identifier[:8], info.GetSlug(), info.GetSlug())
+				logger.Infof("resolveAgent: UUID '%s' found in registry (slug: %s, name: %s)", identifier[:8], info.GetSlug(), info.GetSlug())
 				return identifier, nil
 			}
 		}
 		// UUID format but not in registry — still accept it (might be newly created)
-		fmt.Printf("[MCP:resolveAgent] UUID '%s' accepted (not in registry yet)\n", identifier[:8])
+		logger.Infof("resolveAgent: UUID '%s' accepted (not in registry yet)", identifier[:8])
 		return identifier, nil
 	}
 
@@ -1639,7 +2936,7 @@ func (s *MCPService) resolveAgentID(identifier string) (string, error) {
 	// Step 3: Try global registry (cross-workspace resolution)
 	if s.manager != nil {
 		if info, folder := s.manager.FindAgentBySlug(identifier); info != nil {
-			fmt.Printf("[MCP:resolveAgent] Cross-workspace match: '%s' → %s (folder: %s)\n", identifier, info.ID[:8], folder)
+			logger.Infof("resolveAgent: Cross-workspace match: '%s' → %s (folder: %s)", identifier, info.ID[:8], folder)
 			return info.ID, nil
 		}
 	}
@@ -1713,6 +3010,22 @@ func (s *MCPService) emitBacklogChanged(agentID string) {
 	})
 }
 
+// BacklogStatusChangedEvent builds the backlog:status-changed event for an
+// item whose status moved from previousStatus to item.Status.
+func BacklogStatusChangedEvent(item BacklogItem, previousStatus string) types.EventEnvelope {
+	return types.EventEnvelope{
+		AgentID:   item.AgentID,
+		EventType: "backlog:status-changed",
+		Payload: map[string]any{
+			"itemId":         item.ID,
+			"title":          item.Title,
+			"type":           item.Type,
+			"status":         item.Status,
+			"previousStatus": previousStatus,
+		},
+	}
+}
+
 // getOptionalString extracts an optional string parameter from a tool request
 func getOptionalString(req mcp.CallToolRequest, key string) string {
 	args, ok := req.Params.Arguments.(map[string]any)
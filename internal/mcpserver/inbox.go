@@ -2,9 +2,9 @@ package mcpserver
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,14 +14,26 @@ import (
 
 // InboxMessage represents a message sent to an agent's inbox
 type InboxMessage struct {
-	ID            string    `json:"id"`
-	FromAgentID   string    `json:"fromAgentId,omitempty"` // May be empty if from unknown/external
-	FromAgentName string    `json:"fromAgentName"`         // Display name or slug
-	ToAgentID     string    `json:"toAgentId"`
-	Message       string    `json:"message"`
-	Priority      string    `json:"priority"` // "normal" or "high"
-	Timestamp     time.Time `json:"timestamp"`
-	Read          bool      `json:"read"`
+	ID            string     `json:"id"`
+	FromAgentID   string     `json:"fromAgentId,omitempty"` // May be empty if from unknown/external
+	FromAgentName string     `json:"fromAgentName"`         // Display name or slug
+	ToAgentID     string     `json:"toAgentId"`
+	Message       string     `json:"message"`
+	Priority      string     `json:"priority"` // "normal" or "high"
+	Timestamp     time.Time  `json:"timestamp"`
+	Read          bool       `json:"read"`
+	ThreadID      string     `json:"threadId,omitempty"`    // Conversation thread (first message's thread; empty on legacy rows)
+	ReplyToID     string     `json:"replyToId,omitempty"`   // Message this one replies to
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"` // When it was auto-injected into a session (nil = not yet)
+}
+
+// GetThreadID returns the message's thread ID. Legacy messages stored before
+// threading are treated as the root of their own thread.
+func (m *InboxMessage) GetThreadID() string {
+	if m.ThreadID != "" {
+		return m.ThreadID
+	}
+	return m.ID
 }
 
 // InboxManager manages inbox state with per-agent SQLite persistence.
@@ -29,6 +41,7 @@ type InboxMessage struct {
 type InboxManager struct {
 	stores     map[string]*InboxStore // agentID → store
 	configPath string                 // ~/.claudefu/inbox
+	onAdd      func(InboxMessage)     // Called (in its own goroutine) for each stored message
 	mu         sync.RWMutex
 }
 
@@ -78,26 +91,26 @@ func (im *InboxManager) RecoverFromConflictFiles() int {
 		// Open the conflict DB read-only
 		conflictStore, err := NewInboxStore(conflictPath)
 		if err != nil {
-			fmt.Printf("[MCP:Inbox] Could not open conflict DB %s: %v\n", name, err)
+			logger.Warnf("Inbox: Could not open conflict DB %s: %v", name, err)
 			continue
 		}
 		conflictMsgs, err := conflictStore.GetMessages(agentID)
 		conflictStore.Close()
 		if err != nil {
-			fmt.Printf("[MCP:Inbox] Could not read conflict DB %s: %v\n", name, err)
+			logger.Warnf("Inbox: Could not read conflict DB %s: %v", name, err)
 			continue
 		}
 
 		// Open the main DB and insert each message idempotently
 		mainStore := im.getStoreOrOpen(agentID)
 		if mainStore == nil {
-			fmt.Printf("[MCP:Inbox] Cannot open main store for agent %s\n", agentID)
+			logger.Infof("Inbox: Cannot open main store for agent %s", agentID)
 			continue
 		}
 		recovered := 0
 		for _, msg := range conflictMsgs {
 			if err := mainStore.AddMessageIdempotent(msg); err != nil {
-				fmt.Printf("[MCP:Inbox] Insert failed during recovery: %v\n", err)
+				logger.Warnf("Inbox: Insert failed during recovery: %v", err)
 				continue
 			}
 			recovered++
@@ -106,9 +119,9 @@ func (im *InboxManager) RecoverFromConflictFiles() int {
 		// Rename conflict file so we don't process it again
 		renamed := conflictPath + ".recovered"
 		if err := os.Rename(conflictPath, renamed); err != nil {
-			fmt.Printf("[MCP:Inbox] Could not rename conflict file: %v\n", err)
+			logger.Warnf("Inbox: Could not rename conflict file: %v", err)
 		}
-		fmt.Printf("[MCP:Inbox] Recovered %d messages from %s\n", recovered, name)
+		logger.Infof("Inbox: Recovered %d messages from %s", recovered, name)
 		totalRecovered += recovered
 	}
 	return totalRecovered
@@ -133,7 +146,7 @@ func (im *InboxManager) LoadAgents(agentIDs []string) error {
 		dbPath := filepath.Join(im.configPath, "agents", agentID+".db")
 		store, err := NewInboxStore(dbPath)
 		if err != nil {
-			log.Printf("Failed to open inbox DB for agent %s: %v", agentID, err)
+			logger.Errorf("Failed to open inbox DB for agent %s: %v", agentID, err)
 			continue
 		}
 		im.stores[agentID] = store
@@ -153,7 +166,7 @@ func (im *InboxManager) getStoreOrOpen(agentID string) *InboxStore {
 	dbPath := filepath.Join(im.configPath, "agents", agentID+".db")
 	store, err := NewInboxStore(dbPath)
 	if err != nil {
-		log.Printf("Failed to lazy-open inbox DB for agent %s: %v", agentID, err)
+		logger.Errorf("Failed to lazy-open inbox DB for agent %s: %v", agentID, err)
 		return nil
 	}
 	im.stores[agentID] = store
@@ -172,8 +185,24 @@ func (im *InboxManager) Close() error {
 	return nil
 }
 
-// AddMessage adds a message to an agent's inbox
-func (im *InboxManager) AddMessage(toAgentID string, fromAgentID, fromAgentName, message, priority string) InboxMessage {
+// SetOnMessage sets a callback run for every message stored in an inbox,
+// including cross-workspace imports. It runs in its own goroutine.
+func (im *InboxManager) SetOnMessage(fn func(InboxMessage)) {
+	im.mu.Lock()
+	im.onAdd = fn
+	im.mu.Unlock()
+}
+
+// notifyAdded runs the onAdd callback for msg. Caller must hold im.mu.
+func (im *InboxManager) notifyAdded(msg InboxMessage) {
+	if im.onAdd != nil {
+		go im.onAdd(msg)
+	}
+}
+
+// AddMessage adds a message to an agent's inbox. An empty threadID starts a new
+// thread rooted at this message; replyToID is optional.
+func (im *InboxManager) AddMessage(toAgentID string, fromAgentID, fromAgentName, message, priority, threadID, replyToID string) InboxMessage {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
@@ -190,17 +219,23 @@ func (im *InboxManager) AddMessage(toAgentID string, fromAgentID, fromAgentName,
 		Priority:      priority,
 		Timestamp:     time.Now(),
 		Read:          false,
+		ThreadID:      threadID,
+		ReplyToID:     replyToID,
+	}
+	if msg.ThreadID == "" {
+		msg.ThreadID = msg.ID
 	}
 
 	store := im.getStoreOrOpen(toAgentID)
 	if store != nil {
 		if err := store.AddMessage(msg); err != nil {
-			fmt.Printf("[MCP:Inbox] FAILED to persist message %s to agent %s: %v\n", msg.ID, toAgentID, err)
+			logger.Warnf("Inbox: FAILED to persist message %s to agent %s: %v", msg.ID, toAgentID, err)
 		} else {
-			fmt.Printf("[MCP:Inbox] Persisted message %s from '%s' to agent %s\n", msg.ID, fromAgentName, toAgentID)
+			logger.Infof("Inbox: Persisted message %s from '%s' to agent %s", msg.ID, fromAgentName, toAgentID)
+			im.notifyAdded(msg)
 		}
 	} else {
-		fmt.Printf("[MCP:Inbox] WARNING: Could not open store for agent %s, message %s NOT persisted\n", toAgentID, msg.ID)
+		logger.Warnf("Inbox: WARNING: Could not open store for agent %s, message %s NOT persisted", toAgentID, msg.ID)
 	}
 
 	return msg
@@ -234,6 +269,7 @@ func (im *InboxManager) AddMessageRaw(msg InboxMessage) error {
 	if err := store.AddMessageIdempotent(msg); err != nil {
 		return fmt.Errorf("AddMessageRaw: insert failed: %w", err)
 	}
+	im.notifyAdded(msg)
 	return nil
 }
 
@@ -249,12 +285,45 @@ func (im *InboxManager) GetMessages(agentID string) []InboxMessage {
 
 	msgs, err := store.GetMessages(agentID)
 	if err != nil {
-		fmt.Printf("[MCP:Inbox] FAILED to get messages for agent %s: %v\n", agentID, err)
+		logger.Warnf("Inbox: FAILED to get messages for agent %s: %v", agentID, err)
 		return []InboxMessage{}
 	}
 	return msgs
 }
 
+// GetThread returns all messages in a thread across every loaded agent inbox,
+// oldest first — i.e. both sides of a conversation between local agents.
+func (im *InboxManager) GetThread(threadID string) []InboxMessage {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	messages := []InboxMessage{}
+	for agentID, store := range im.stores {
+		msgs, err := store.GetThread(threadID)
+		if err != nil {
+			logger.Warnf("Inbox: FAILED to get thread %s for agent %s: %v", threadID, agentID, err)
+			continue
+		}
+		messages = append(messages, msgs...)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+	return messages
+}
+
+// GetThreadForAgent returns a thread like GetThread, but only if agentID sent
+// or received one of its messages; other agents' threads come back empty.
+func (im *InboxManager) GetThreadForAgent(threadID, agentID string) []InboxMessage {
+	messages := im.GetThread(threadID)
+	for _, msg := range messages {
+		if msg.ToAgentID == agentID || msg.FromAgentID == agentID {
+			return messages
+		}
+	}
+	return []InboxMessage{}
+}
+
 // GetUnreadCount returns the number of unread messages for an agent
 func (im *InboxManager) GetUnreadCount(agentID string) int {
 	im.mu.Lock()
@@ -267,7 +336,7 @@ func (im *InboxManager) GetUnreadCount(agentID string) int {
 
 	count, err := store.GetUnreadCount(agentID)
 	if err != nil {
-		log.Printf("Failed to get unread count: %v", err)
+		logger.Errorf("Failed to get unread count: %v", err)
 		return 0
 	}
 	return count
@@ -285,7 +354,7 @@ func (im *InboxManager) GetTotalCount(agentID string) int {
 
 	count, err := store.GetTotalCount(agentID)
 	if err != nil {
-		log.Printf("Failed to get total count: %v", err)
+		logger.Errorf("Failed to get total count: %v", err)
 		return 0
 	}
 	return count
@@ -303,12 +372,45 @@ func (im *InboxManager) MarkRead(agentID, messageID string) bool {
 
 	marked, err := store.MarkRead(agentID, messageID)
 	if err != nil {
-		log.Printf("Failed to mark message as read: %v", err)
+		logger.Errorf("Failed to mark message as read: %v", err)
 		return false
 	}
 	return marked
 }
 
+// GetUndelivered returns an agent's unread messages not yet delivered into a session, oldest first
+func (im *InboxManager) GetUndelivered(agentID string) []InboxMessage {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	store := im.getStoreOrOpen(agentID)
+	if store == nil {
+		return nil
+	}
+
+	messages, err := store.GetUndelivered(agentID)
+	if err != nil {
+		logger.Errorf("Failed to get undelivered messages: %v", err)
+		return nil
+	}
+	return messages
+}
+
+// MarkDelivered marks messages as delivered into a session (and read)
+func (im *InboxManager) MarkDelivered(agentID string, messageIDs []string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	store := im.getStoreOrOpen(agentID)
+	if store == nil {
+		return
+	}
+
+	if err := store.MarkDelivered(agentID, messageIDs, time.Now()); err != nil {
+		logger.Errorf("Failed to mark messages as delivered: %v", err)
+	}
+}
+
 // MarkAllRead marks all messages for an agent as read
 func (im *InboxManager) MarkAllRead(agentID string) {
 	im.mu.Lock()
@@ -320,7 +422,7 @@ func (im *InboxManager) MarkAllRead(agentID string) {
 	}
 
 	if err := store.MarkAllRead(agentID); err != nil {
-		log.Printf("Failed to mark all messages as read: %v", err)
+		logger.Errorf("Failed to mark all messages as read: %v", err)
 	}
 }
 
@@ -336,7 +438,7 @@ func (im *InboxManager) DeleteMessage(agentID, messageID string) bool {
 
 	deleted, err := store.DeleteMessage(agentID, messageID)
 	if err != nil {
-		log.Printf("Failed to delete message: %v", err)
+		logger.Errorf("Failed to delete message: %v", err)
 		return false
 	}
 	return deleted
@@ -353,7 +455,7 @@ func (im *InboxManager) Clear(agentID string) {
 	}
 
 	if err := store.Clear(agentID); err != nil {
-		log.Printf("Failed to clear agent inbox: %v", err)
+		logger.Errorf("Failed to clear agent inbox: %v", err)
 	}
 }
 
@@ -364,7 +466,7 @@ func (im *InboxManager) ClearAll() {
 
 	for _, store := range im.stores {
 		if err := store.ClearAll(); err != nil {
-			log.Printf("Failed to clear inbox store: %v", err)
+			logger.Errorf("Failed to clear inbox store: %v", err)
 		}
 	}
 }
@@ -381,8 +483,27 @@ func (im *InboxManager) GetMessage(agentID, messageID string) *InboxMessage {
 
 	msg, err := store.GetMessage(agentID, messageID)
 	if err != nil {
-		log.Printf("Failed to get message: %v", err)
+		logger.Errorf("Failed to get message: %v", err)
 		return nil
 	}
 	return msg
 }
+
+// FormatInboxContext renders inbox messages as a <claudefu-inbox> block that is
+// prepended to the next message sent to the receiving agent (see Agent.InboxAutoInject)
+func FormatInboxContext(messages []InboxMessage) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<claudefu-inbox>\n")
+	b.WriteString("Messages from other agents arrived since your last turn. Reply with AgentInboxReply if they need an answer.\n")
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "<message id=%q from=%q priority=%q thread=%q sent=%q>\n",
+			msg.ID, msg.FromAgentName, msg.Priority, msg.GetThreadID(), msg.Timestamp.Format(time.RFC3339))
+		writeXMLElement(&b, "body", msg.Message)
+		b.WriteString("</message>\n")
+	}
+	b.WriteString("</claudefu-inbox>")
+	return b.String()
+}
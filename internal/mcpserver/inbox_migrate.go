@@ -3,7 +3,6 @@ package mcpserver
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 
@@ -29,7 +28,7 @@ func MigrateInboxFromWorkspaceDB(inboxPath, workspaceID string, oldToNew map[str
 		return nil // Nothing to migrate
 	}
 
-	log.Printf("Inbox migration: found old workspace DB at %s", oldDBPath)
+	logger.Infof("Inbox migration: found old workspace DB at %s", oldDBPath)
 
 	// Open old database
 	oldDB, err := sql.Open("sqlite", oldDBPath)
@@ -81,7 +80,7 @@ func MigrateInboxFromWorkspaceDB(inboxPath, workspaceID string, oldToNew map[str
 	}
 
 	if totalMsgs == 0 {
-		log.Printf("Inbox migration: old DB is empty, renaming")
+		logger.Infof("Inbox migration: old DB is empty, renaming")
 		return os.Rename(oldDBPath, oldDBPath+".migrated")
 	}
 
@@ -91,7 +90,7 @@ func MigrateInboxFromWorkspaceDB(inboxPath, workspaceID string, oldToNew map[str
 		agentDBPath := filepath.Join(agentsDir, agentID+".db")
 		store, err := NewInboxStore(agentDBPath)
 		if err != nil {
-			log.Printf("Inbox migration: failed to open agent DB %s: %v", agentID, err)
+			logger.Warnf("Inbox migration: failed to open agent DB %s: %v", agentID, err)
 			continue
 		}
 
@@ -103,22 +102,22 @@ func MigrateInboxFromWorkspaceDB(inboxPath, workspaceID string, oldToNew map[str
 				continue
 			}
 			if err := store.AddMessage(msg); err != nil {
-				log.Printf("Inbox migration: failed to insert message %s: %v", msg.ID, err)
+				logger.Warnf("Inbox migration: failed to insert message %s: %v", msg.ID, err)
 				continue
 			}
 			migrated++
 		}
 
 		store.Close()
-		log.Printf("Inbox migration: migrated %d messages to agent %s", migrated, agentID)
+		logger.Infof("Inbox migration: migrated %d messages to agent %s", migrated, agentID)
 	}
 
 	// Rename old DB to .migrated
 	if err := os.Rename(oldDBPath, oldDBPath+".migrated"); err != nil {
-		log.Printf("Inbox migration: failed to rename old DB: %v", err)
+		logger.Warnf("Inbox migration: failed to rename old DB: %v", err)
 		// Non-fatal — messages are already copied
 	}
 
-	log.Printf("Inbox migration complete: %d messages across %d agents", totalMsgs, len(msgsByAgent))
+	logger.Infof("Inbox migration complete: %d messages across %d agents", totalMsgs, len(msgsByAgent))
 	return nil
 }
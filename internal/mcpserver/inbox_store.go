@@ -51,15 +51,75 @@ func createSchema(db *sql.DB) error {
 			message TEXT NOT NULL,
 			priority TEXT DEFAULT 'normal',
 			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			read INTEGER DEFAULT 0
+			read INTEGER DEFAULT 0,
+			thread_id TEXT DEFAULT '',
+			reply_to_id TEXT DEFAULT '',
+			delivered_at INTEGER DEFAULT 0
 		);
 		CREATE INDEX IF NOT EXISTS idx_to_agent ON messages(to_agent_id);
 		CREATE INDEX IF NOT EXISTS idx_unread ON messages(to_agent_id, read);
 	`
-	_, err := db.Exec(schema)
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Migrate existing databases: add threading columns if missing
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name = 'thread_id'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN thread_id TEXT DEFAULT ''`); err != nil {
+			return err
+		}
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN reply_to_id TEXT DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+	// Migrate: add delivered_at (auto-inject into the receiving session) if missing
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name = 'delivered_at'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN delivered_at INTEGER DEFAULT 0`); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_thread ON messages(thread_id)`)
 	return err
 }
 
+// inboxColumns is the column list shared by every SELECT (order matches scanInboxMessage).
+const inboxColumns = `id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read, thread_id, reply_to_id, delivered_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInboxMessage scans one row selected with inboxColumns.
+func scanInboxMessage(row rowScanner) (InboxMessage, error) {
+	var msg InboxMessage
+	var readInt int
+	var timestampRaw any
+	var threadID, replyToID sql.NullString
+	var deliveredAt sql.NullInt64
+	if err := row.Scan(&msg.ID, &msg.FromAgentID, &msg.FromAgentName, &msg.ToAgentID, &msg.Message, &msg.Priority, &timestampRaw, &readInt, &threadID, &replyToID, &deliveredAt); err != nil {
+		return msg, err
+	}
+	msg.Read = readInt != 0
+	msg.Timestamp = parseTimestamp(timestampRaw)
+	msg.ThreadID = threadID.String
+	msg.ReplyToID = replyToID.String
+	if deliveredAt.Int64 != 0 {
+		t := time.UnixMilli(deliveredAt.Int64)
+		msg.DeliveredAt = &t
+	}
+	return msg, nil
+}
+
 // Close closes the database connection
 func (s *InboxStore) Close() error {
 	if s.db != nil {
@@ -71,9 +131,9 @@ func (s *InboxStore) Close() error {
 // AddMessage inserts a new message into the database
 func (s *InboxStore) AddMessage(msg InboxMessage) error {
 	_, err := s.db.Exec(`
-		INSERT INTO messages (id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, msg.ID, msg.FromAgentID, msg.FromAgentName, msg.ToAgentID, msg.Message, msg.Priority, msg.Timestamp.Unix(), boolToInt(msg.Read))
+		INSERT INTO messages (id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read, thread_id, reply_to_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, msg.ID, msg.FromAgentID, msg.FromAgentName, msg.ToAgentID, msg.Message, msg.Priority, msg.Timestamp.Unix(), boolToInt(msg.Read), msg.ThreadID, msg.ReplyToID)
 	return err
 }
 
@@ -82,16 +142,16 @@ func (s *InboxStore) AddMessage(msg InboxMessage) error {
 // might be processed twice due to Syncthing re-delivery or restart-scan overlap.
 func (s *InboxStore) AddMessageIdempotent(msg InboxMessage) error {
 	_, err := s.db.Exec(`
-		INSERT OR IGNORE INTO messages (id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, msg.ID, msg.FromAgentID, msg.FromAgentName, msg.ToAgentID, msg.Message, msg.Priority, msg.Timestamp.Unix(), boolToInt(msg.Read))
+		INSERT OR IGNORE INTO messages (id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read, thread_id, reply_to_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, msg.ID, msg.FromAgentID, msg.FromAgentName, msg.ToAgentID, msg.Message, msg.Priority, msg.Timestamp.Unix(), boolToInt(msg.Read), msg.ThreadID, msg.ReplyToID)
 	return err
 }
 
 // GetMessages returns all messages for an agent, ordered by timestamp descending
 func (s *InboxStore) GetMessages(agentID string) ([]InboxMessage, error) {
 	rows, err := s.db.Query(`
-		SELECT id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read
+		SELECT `+inboxColumns+`
 		FROM messages
 		WHERE to_agent_id = ?
 		ORDER BY timestamp DESC
@@ -103,14 +163,10 @@ func (s *InboxStore) GetMessages(agentID string) ([]InboxMessage, error) {
 
 	var messages []InboxMessage
 	for rows.Next() {
-		var msg InboxMessage
-		var readInt int
-		var timestampRaw any
-		if err := rows.Scan(&msg.ID, &msg.FromAgentID, &msg.FromAgentName, &msg.ToAgentID, &msg.Message, &msg.Priority, &timestampRaw, &readInt); err != nil {
+		msg, err := scanInboxMessage(rows)
+		if err != nil {
 			return nil, err
 		}
-		msg.Read = readInt != 0
-		msg.Timestamp = parseTimestamp(timestampRaw)
 		messages = append(messages, msg)
 	}
 
@@ -122,15 +178,11 @@ func (s *InboxStore) GetMessages(agentID string) ([]InboxMessage, error) {
 
 // GetMessage returns a specific message by ID
 func (s *InboxStore) GetMessage(agentID, messageID string) (*InboxMessage, error) {
-	var msg InboxMessage
-	var readInt int
-	var timestampRaw any
-
-	err := s.db.QueryRow(`
-		SELECT id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read
+	msg, err := scanInboxMessage(s.db.QueryRow(`
+		SELECT `+inboxColumns+`
 		FROM messages
 		WHERE to_agent_id = ? AND id = ?
-	`, agentID, messageID).Scan(&msg.ID, &msg.FromAgentID, &msg.FromAgentName, &msg.ToAgentID, &msg.Message, &msg.Priority, &timestampRaw, &readInt)
+	`, agentID, messageID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -138,12 +190,34 @@ func (s *InboxStore) GetMessage(agentID, messageID string) (*InboxMessage, error
 	if err != nil {
 		return nil, err
 	}
-
-	msg.Read = readInt != 0
-	msg.Timestamp = parseTimestamp(timestampRaw)
 	return &msg, nil
 }
 
+// GetThread returns the messages in this database belonging to a thread, oldest first.
+// Messages stored before threading existed are their own thread (thread ID = message ID).
+func (s *InboxStore) GetThread(threadID string) ([]InboxMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT `+inboxColumns+`
+		FROM messages
+		WHERE thread_id = ? OR (id = ? AND (thread_id IS NULL OR thread_id = ''))
+		ORDER BY timestamp ASC
+	`, threadID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []InboxMessage
+	for rows.Next() {
+		msg, err := scanInboxMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
 // MarkRead marks a specific message as read
 func (s *InboxStore) MarkRead(agentID, messageID string) (bool, error) {
 	result, err := s.db.Exec(`
@@ -156,6 +230,43 @@ func (s *InboxStore) MarkRead(agentID, messageID string) (bool, error) {
 	return affected > 0, nil
 }
 
+// GetUndelivered returns an agent's unread messages not yet delivered into a
+// session, oldest first
+func (s *InboxStore) GetUndelivered(agentID string) ([]InboxMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT `+inboxColumns+`
+		FROM messages
+		WHERE to_agent_id = ? AND read = 0 AND (delivered_at IS NULL OR delivered_at = 0)
+		ORDER BY timestamp ASC
+	`, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []InboxMessage
+	for rows.Next() {
+		msg, err := scanInboxMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// MarkDelivered marks messages as delivered into a session (and read)
+func (s *InboxStore) MarkDelivered(agentID string, messageIDs []string, at time.Time) error {
+	for _, id := range messageIDs {
+		if _, err := s.db.Exec(`
+			UPDATE messages SET read = 1, delivered_at = ? WHERE to_agent_id = ? AND id = ?
+		`, at.UnixMilli(), agentID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // MarkAllRead marks all messages for an agent as read
 func (s *InboxStore) MarkAllRead(agentID string) error {
 	_, err := s.db.Exec(`UPDATE messages SET read = 1 WHERE to_agent_id = ?`, agentID)
@@ -202,7 +313,7 @@ func (s *InboxStore) GetTotalCount(agentID string) (int, error) {
 // Used only by migration to read old workspace-scoped DBs.
 func (s *InboxStore) GetAllMessages() ([]InboxMessage, error) {
 	rows, err := s.db.Query(`
-		SELECT id, from_agent_id, from_agent_name, to_agent_id, message, priority, timestamp, read
+		SELECT ` + inboxColumns + `
 		FROM messages
 		ORDER BY timestamp ASC
 	`)
@@ -213,14 +324,10 @@ func (s *InboxStore) GetAllMessages() ([]InboxMessage, error) {
 
 	var messages []InboxMessage
 	for rows.Next() {
-		var msg InboxMessage
-		var readInt int
-		var timestampRaw any
-		if err := rows.Scan(&msg.ID, &msg.FromAgentID, &msg.FromAgentName, &msg.ToAgentID, &msg.Message, &msg.Priority, &timestampRaw, &readInt); err != nil {
+		msg, err := scanInboxMessage(rows)
+		if err != nil {
 			return nil, err
 		}
-		msg.Read = readInt != 0
-		msg.Timestamp = parseTimestamp(timestampRaw)
 		messages = append(messages, msg)
 	}
 
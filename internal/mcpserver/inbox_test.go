@@ -0,0 +1,27 @@
+package mcpserver
+
+import "testing"
+
+func TestInboxGetThreadForAgent(t *testing.T) {
+	im := NewInboxManager(t.TempDir())
+	defer im.Close()
+	if err := im.LoadAgents([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("LoadAgents: %v", err)
+	}
+	first := im.AddMessage("b", "a", "alpha", "hello", "normal", "", "")
+	im.AddMessage("a", "b", "beta", "hi back", "normal", first.GetThreadID(), first.ID)
+
+	tests := []struct {
+		agentID string
+		want    int
+	}{
+		{"a", 2}, // Sender of the first message
+		{"b", 2}, // Recipient
+		{"c", 0}, // Not part of the thread
+	}
+	for _, tt := range tests {
+		if got := im.GetThreadForAgent(first.GetThreadID(), tt.agentID); len(got) != tt.want {
+			t.Errorf("GetThreadForAgent(%s) returned %d messages, want %d", tt.agentID, len(got), tt.want)
+		}
+	}
+}
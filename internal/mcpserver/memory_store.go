@@ -0,0 +1,218 @@
+package mcpserver
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// MemoryDBFile is the shared memory database under the config path.
+const MemoryDBFile = "memory.db"
+
+// DefaultMemoryNamespace is used when a tool call gives no namespace.
+const DefaultMemoryNamespace = "default"
+
+// maxMemoryValueBytes bounds a single value so the store stays a place for
+// structured state rather than documents.
+const maxMemoryValueBytes = 64 * 1024
+
+// MemoryEntry is one value in the shared memory store.
+type MemoryEntry struct {
+	Namespace string     `json:"namespace"`
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	UpdatedBy string     `json:"updatedBy,omitempty"` // Agent slug, or "user"
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // nil = never
+}
+
+// MemoryStore is the workspace-scoped key-value store behind the Memory* tools
+// (SQLite at ~/.claudefu/memory.db). Expired entries are invisible and are
+// purged on write.
+type MemoryStore struct {
+	db   *sql.DB
+	path string
+}
+
+// NewMemoryStore opens or creates the shared memory database at dbPath.
+func NewMemoryStore(dbPath string) (*MemoryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	schema := `
+		CREATE TABLE IF NOT EXISTS memory (
+			workspace_id TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			key TEXT NOT NULL,
+			value TEXT NOT NULL,
+			updated_by TEXT NOT NULL DEFAULT '',
+			updated_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (workspace_id, namespace, key)
+		);
+		CREATE INDEX IF NOT EXISTS idx_memory_expires ON memory(expires_at);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create memory schema: %w", err)
+	}
+	return &MemoryStore{db: db, path: dbPath}, nil
+}
+
+// Close closes the database connection
+func (s *MemoryStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Set creates or replaces an entry. A ttl of 0 keeps it until deleted.
+func (s *MemoryStore) Set(workspaceID, namespace, key, value, updatedBy string, ttl time.Duration) (MemoryEntry, error) {
+	namespace = normalizeNamespace(namespace)
+	if key == "" {
+		return MemoryEntry{}, fmt.Errorf("key is required")
+	}
+	if len(value) > maxMemoryValueBytes {
+		return MemoryEntry{}, fmt.Errorf("value is %d bytes; the limit is %d", len(value), maxMemoryValueBytes)
+	}
+	now := time.Now()
+	entry := MemoryEntry{Namespace: namespace, Key: key, Value: value, UpdatedBy: updatedBy, UpdatedAt: now}
+	var expires int64
+	if ttl > 0 {
+		t := now.Add(ttl)
+		entry.ExpiresAt = &t
+		expires = t.UnixMilli()
+	}
+
+	s.purgeExpired(now)
+	_, err := s.db.Exec(`
+		INSERT INTO memory (workspace_id, namespace, key, value, updated_by, updated_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (workspace_id, namespace, key) DO UPDATE SET
+			value = excluded.value, updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at, expires_at = excluded.expires_at
+	`, workspaceID, namespace, key, value, updatedBy, now.UnixMilli(), expires)
+	if err != nil {
+		return MemoryEntry{}, fmt.Errorf("failed to save memory entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Get returns an entry, or nil if it doesn't exist or has expired.
+func (s *MemoryStore) Get(workspaceID, namespace, key string) (*MemoryEntry, error) {
+	row := s.db.QueryRow(`
+		SELECT namespace, key, value, updated_by, updated_at, expires_at FROM memory
+		WHERE workspace_id = ? AND namespace = ? AND key = ? AND (expires_at = 0 OR expires_at > ?)
+	`, workspaceID, normalizeNamespace(namespace), key, time.Now().UnixMilli())
+	entry, err := scanMemoryEntry(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// List returns the live entries of a namespace whose keys start with prefix,
+// sorted by key. An empty namespace lists every namespace.
+func (s *MemoryStore) List(workspaceID, namespace, prefix string) ([]MemoryEntry, error) {
+	query := `SELECT namespace, key, value, updated_by, updated_at, expires_at FROM memory
+		WHERE workspace_id = ? AND (expires_at = 0 OR expires_at > ?)`
+	args := []any{workspaceID, time.Now().UnixMilli()}
+	if namespace != "" {
+		query += ` AND namespace = ?`
+		args = append(args, namespace)
+	}
+	if prefix != "" {
+		// length() counts characters, as substr does; len(prefix) would be bytes
+		query += ` AND substr(key, 1, length(?)) = ?`
+		args = append(args, prefix, prefix)
+	}
+	rows, err := s.db.Query(query+` ORDER BY namespace, key`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []MemoryEntry{}
+	for rows.Next() {
+		entry, err := scanMemoryEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list memory: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Namespaces returns the namespaces that hold live entries.
+func (s *MemoryStore) Namespaces(workspaceID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT namespace FROM memory
+		WHERE workspace_id = ? AND (expires_at = 0 OR expires_at > ?) ORDER BY namespace`,
+		workspaceID, time.Now().UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	namespaces := []string{}
+	for rows.Next() {
+		var ns string
+		if err := rows.Scan(&ns); err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, rows.Err()
+}
+
+// Delete removes an entry. Returns false if it didn't exist.
+func (s *MemoryStore) Delete(workspaceID, namespace, key string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM memory WHERE workspace_id = ? AND namespace = ? AND key = ?`,
+		workspaceID, normalizeNamespace(namespace), key)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete memory entry: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// purgeExpired deletes entries whose TTL has passed.
+func (s *MemoryStore) purgeExpired(now time.Time) {
+	s.db.Exec(`DELETE FROM memory WHERE expires_at != 0 AND expires_at <= ?`, now.UnixMilli())
+}
+
+// scanMemoryEntry scans one memory row.
+func scanMemoryEntry(row rowScanner) (MemoryEntry, error) {
+	var entry MemoryEntry
+	var updatedAt, expiresAt int64
+	if err := row.Scan(&entry.Namespace, &entry.Key, &entry.Value, &entry.UpdatedBy, &updatedAt, &expiresAt); err != nil {
+		return entry, err
+	}
+	entry.UpdatedAt = time.UnixMilli(updatedAt)
+	if expiresAt != 0 {
+		t := time.UnixMilli(expiresAt)
+		entry.ExpiresAt = &t
+	}
+	return entry, nil
+}
+
+func normalizeNamespace(namespace string) string {
+	if namespace = strings.TrimSpace(namespace); namespace == "" {
+		return DefaultMemoryNamespace
+	}
+	return namespace
+}
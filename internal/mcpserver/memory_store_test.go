@@ -0,0 +1,59 @@
+package mcpserver
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreList(t *testing.T) {
+	store, err := NewMemoryStore(filepath.Join(t.TempDir(), MemoryDBFile))
+	if err != nil {
+		t.Fatalf("NewMemoryStore: %v", err)
+	}
+	defer store.Close()
+
+	set := func(ws, namespace, key string, ttl time.Duration) {
+		t.Helper()
+		if _, err := store.Set(ws, namespace, key, "v", "test", ttl); err != nil {
+			t.Fatalf("Set(%s/%s): %v", namespace, key, err)
+		}
+	}
+	set("ws1", "build", "status", 0)
+	set("ws1", "build", "status:api", 0)
+	set("ws1", "build", "stale", time.Millisecond)
+	set("ws1", "deploy", "status", 0)
+	set("ws1", "notes", "café:menu", 0)
+	set("ws1", "notes", "cafe:menu", 0)
+	set("ws2", "build", "status", 0)
+	time.Sleep(5 * time.Millisecond)
+
+	tests := []struct {
+		name      string
+		namespace string
+		prefix    string
+		want      []string
+	}{
+		{"namespace", "build", "", []string{"build/status", "build/status:api"}},
+		{"prefix", "build", "status:", []string{"build/status:api"}},
+		{"all namespaces", "", "status", []string{"build/status", "build/status:api", "deploy/status"}},
+		{"multibyte prefix", "notes", "café", []string{"notes/café:menu"}},
+		{"no match", "build", "zzz", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.List("ws1", tt.namespace, tt.prefix)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Namespace+"/"+e.Key)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("List(%q, %q) = %v, want %v", tt.namespace, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
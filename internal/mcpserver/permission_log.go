@@ -0,0 +1,262 @@
+package mcpserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/providers"
+)
+
+// =============================================================================
+// PERMISSION LOG
+// Every RequestToolPermission request and its outcome is appended to
+// {configPath}/permission-log.jsonl, along with revocations of session grants.
+// The log is the audit trail behind GetPermissionHistory, and on startup it is
+// replayed to restore session-scoped grants that would otherwise be lost.
+// =============================================================================
+
+// PermissionLogFile is the audit log file name under the config path
+const PermissionLogFile = "permission-log.jsonl"
+
+// Permission log decisions
+const (
+	PermissionDecisionPending   = "pending"
+	PermissionDecisionGranted   = "granted"
+	PermissionDecisionDenied    = "denied"
+	PermissionDecisionCancelled = "cancelled"
+	PermissionDecisionTimedOut  = "timed_out"
+	PermissionDecisionRevoked   = "revoked" // Session grants revoked by the user
+)
+
+// PermissionLogEntry is one line of the permission log. A request is logged
+// as pending when it is created and again with its decision; History merges
+// the two by RequestID.
+type PermissionLogEntry struct {
+	RequestID   string    `json:"requestId,omitempty"` // Empty for revocations
+	Time        time.Time `json:"time"`
+	AgentSlug   string    `json:"agentSlug,omitempty"`
+	SessionID   string    `json:"sessionId,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"` // Requested (or revoked) patterns
+	Reason      string    `json:"reason,omitempty"`
+	Decision    string    `json:"decision"`
+	Scope       string    `json:"scope,omitempty"`   // once, session, permanent (granted only)
+	Permanent   bool      `json:"permanent"`         // Added to the agent's allow list
+	Granted     []string  `json:"granted,omitempty"` // Granted subset of a batch request
+	DenyReason  string    `json:"denyReason,omitempty"`
+	RequestedAt time.Time `json:"requestedAt,omitzero"` // Set by History for decided requests
+}
+
+// PermissionLogFilter narrows GetPermissionHistory. Zero fields match everything.
+type PermissionLogFilter struct {
+	AgentSlug string    `json:"agentSlug,omitempty"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Decision  string    `json:"decision,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"` // Substring of any requested pattern (case-insensitive)
+	Since     time.Time `json:"since,omitzero"`
+	Until     time.Time `json:"until,omitzero"`
+	Limit     int       `json:"limit,omitempty"` // Max entries (0 = all)
+}
+
+// matches reports whether entry passes the filter (Limit aside).
+func (f PermissionLogFilter) matches(entry PermissionLogEntry) bool {
+	if f.AgentSlug != "" && entry.AgentSlug != f.AgentSlug {
+		return false
+	}
+	if f.SessionID != "" && entry.SessionID != f.SessionID {
+		return false
+	}
+	if f.Decision != "" && entry.Decision != f.Decision {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if f.Pattern != "" {
+		pattern := strings.ToLower(f.Pattern)
+		return slices.ContainsFunc(entry.Permissions, func(p string) bool {
+			return strings.Contains(strings.ToLower(p), pattern)
+		})
+	}
+	return true
+}
+
+// PermissionLog appends permission decisions to a JSONL file.
+type PermissionLog struct {
+	path string // ~/.claudefu/permission-log.jsonl
+	mu   sync.Mutex
+}
+
+// NewPermissionLog creates a log under configPath.
+func NewPermissionLog(configPath string) *PermissionLog {
+	return &PermissionLog{path: filepath.Join(configPath, PermissionLogFile)}
+}
+
+// Record appends an entry, stamping Time if it is unset.
+func (l *PermissionLog) Record(entry PermissionLogEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// History returns logged requests and revocations matching filter, newest
+// first. Each request appears once with its latest decision; requests still
+// pending when ClaudeFu exited stay pending.
+func (l *PermissionLog) History(filter PermissionLogFilter) ([]PermissionLogEntry, error) {
+	entries, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []PermissionLogEntry
+	byRequest := make(map[string]int)
+	for _, entry := range entries {
+		if entry.RequestID == "" {
+			merged = append(merged, entry)
+			continue
+		}
+		i, seen := byRequest[entry.RequestID]
+		if !seen {
+			byRequest[entry.RequestID] = len(merged)
+			merged = append(merged, entry)
+			continue
+		}
+		requested := merged[i]
+		entry.RequestedAt = requested.Time
+		if !requested.RequestedAt.IsZero() {
+			entry.RequestedAt = requested.RequestedAt
+		}
+		if entry.Reason == "" {
+			entry.Reason = requested.Reason
+		}
+		if len(entry.Permissions) == 0 {
+			entry.Permissions = requested.Permissions
+		}
+		merged[i] = entry
+	}
+
+	result := []PermissionLogEntry{}
+	for i := len(merged) - 1; i >= 0; i-- {
+		if !filter.matches(merged[i]) {
+			continue
+		}
+		result = append(result, merged[i])
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// RestoreSessionGrants replays session-scoped grants and revocations so
+// grants made before a restart apply again. Returns how many sessions have
+// grants.
+func (l *PermissionLog) RestoreSessionGrants() (int, error) {
+	entries, err := l.load()
+	if err != nil {
+		return 0, err
+	}
+	grants := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.SessionID == "" {
+			continue
+		}
+		switch {
+		case entry.Decision == PermissionDecisionGranted && entry.Scope == PermissionScopeSession:
+			for _, p := range entry.Granted {
+				if !slices.Contains(grants[entry.SessionID], p) {
+					grants[entry.SessionID] = append(grants[entry.SessionID], p)
+				}
+			}
+		case entry.Decision == PermissionDecisionRevoked:
+			if len(entry.Permissions) == 0 {
+				delete(grants, entry.SessionID)
+				continue
+			}
+			grants[entry.SessionID] = slices.DeleteFunc(grants[entry.SessionID], func(p string) bool {
+				return slices.Contains(entry.Permissions, p)
+			})
+		}
+	}
+
+	restored := 0
+	for sessionID, patterns := range grants {
+		if len(patterns) > 0 {
+			providers.AddSessionGrants(sessionID, patterns)
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// load reads every entry, skipping lines that don't parse.
+func (l *PermissionLog) load() ([]PermissionLogEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []PermissionLogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry PermissionLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// logPermissionDecision records how a permission request ended. response is
+// nil unless the user answered.
+func (s *MCPService) logPermissionDecision(pr *PendingPermissionRequest, decision string, response *PermissionResponse) {
+	entry := PermissionLogEntry{
+		RequestID: pr.ID,
+		AgentSlug: pr.AgentSlug,
+		SessionID: pr.SessionID,
+		Decision:  decision,
+	}
+	if response != nil {
+		entry.Scope = response.Scope
+		entry.Permanent = response.Permanent
+		entry.Granted = response.Permissions
+		entry.DenyReason = response.DenyReason
+	}
+	if err := s.permissionLog.Record(entry); err != nil {
+		logger.Warnf("RequestToolPermission: Failed to log %s for request %s: %v", decision, pr.ID[:8], err)
+	}
+}
@@ -2,27 +2,39 @@ package mcpserver
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Permission grant scopes
+const (
+	PermissionScopeOnce      = "once"      // This request only
+	PermissionScopeSession   = "session"   // Allowed for later turns of the requesting session
+	PermissionScopePermanent = "permanent" // Added to the allow list
+)
+
 // PermissionResponse represents the user's response to a permission request
 type PermissionResponse struct {
-	Granted    bool   `json:"granted"`
-	Permanent  bool   `json:"permanent"`  // Add to allow list permanently
-	DenyReason string `json:"denyReason"` // Optional reason if denied
+	Granted     bool     `json:"granted"`
+	Permanent   bool     `json:"permanent"`             // Add to allow list permanently
+	Scope       string   `json:"scope"`                 // once, session, permanent
+	Permissions []string `json:"permissions,omitempty"` // Granted subset of a batch request
+	DenyReason  string   `json:"denyReason"`            // Optional reason if denied
 }
 
 // PendingPermissionRequest represents a permission request waiting for user response
 type PendingPermissionRequest struct {
-	ID         string                    `json:"id"`
-	AgentSlug  string                    `json:"agentSlug"`  // Which agent is requesting
-	Permission string                    `json:"permission"` // The permission being requested (e.g., "Bash(git push:*)")
-	Reason     string                    `json:"reason"`     // Why the agent needs this permission
-	ResponseCh chan *PermissionResponse  `json:"-"`          // Channel for response
-	CreatedAt  time.Time                 `json:"createdAt"`
+	ID          string                   `json:"id"`
+	AgentSlug   string                   `json:"agentSlug"`           // Which agent is requesting
+	Permission  string                   `json:"permission"`          // The permission being requested (e.g., "Bash(git push:*)"); first of a batch
+	Permissions []string                 `json:"permissions"`         // All requested permissions (batch requests have several)
+	Reason      string                   `json:"reason"`              // Why the agent needs this permission
+	SessionID   string                   `json:"sessionId,omitempty"` // Requesting session, if known (enables session-scoped grants)
+	ResponseCh  chan *PermissionResponse `json:"-"`                   // Channel for response
+	CreatedAt   time.Time                `json:"createdAt"`
 }
 
 // PendingPermissionRequestManager manages permission requests waiting for user responses
@@ -40,23 +52,27 @@ func NewPendingPermissionRequestManager() *PendingPermissionRequestManager {
 	}
 }
 
-// Create creates a new pending permission request and returns it
-func (m *PendingPermissionRequestManager) Create(agentSlug, permission, reason string) *PendingPermissionRequest {
+// Create creates a new pending permission request for one or more permissions and returns it
+func (m *PendingPermissionRequestManager) Create(agentSlug, sessionID string, permissions []string, reason string) *PendingPermissionRequest {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	id := uuid.New().String()
 	pr := &PendingPermissionRequest{
-		ID:         id,
-		AgentSlug:  agentSlug,
-		Permission: permission,
-		Reason:     reason,
-		ResponseCh: make(chan *PermissionResponse, 1),
-		CreatedAt:  time.Now(),
+		ID:          id,
+		AgentSlug:   agentSlug,
+		Permissions: permissions,
+		Reason:      reason,
+		SessionID:   sessionID,
+		ResponseCh:  make(chan *PermissionResponse, 1),
+		CreatedAt:   time.Now(),
+	}
+	if len(permissions) > 0 {
+		pr.Permission = permissions[0]
 	}
 
 	m.pending[id] = pr
-	fmt.Printf("[MCP:PermissionRequest] Created pending request %s from agent %s for '%s'\n", id[:8], agentSlug, permission)
+	logger.Infof("PermissionRequest: Created pending request %s from agent %s for %q", id[:8], agentSlug, permissions)
 	return pr
 }
 
@@ -79,23 +95,68 @@ func (m *PendingPermissionRequestManager) GetAll() []*PendingPermissionRequest {
 	return result
 }
 
-// Respond sends a response to a pending permission request
+// Respond sends an all-or-nothing response to a pending permission request
 func (m *PendingPermissionRequestManager) Respond(id string, granted, permanent bool, denyReason string) error {
+	pr := m.Get(id)
+	if pr == nil {
+		return fmt.Errorf("permission request %s not found", id)
+	}
+	var permissions []string
+	if granted {
+		permissions = pr.Permissions
+	}
+	scope := PermissionScopeOnce
+	if permanent {
+		scope = PermissionScopePermanent
+	}
+	return m.RespondWithScope(id, permissions, scope, denyReason)
+}
+
+// RespondWithScope answers a (batch) permission request: permissions is the granted
+// subset (empty = deny all) and scope is once, session, or permanent.
+// Session scope requires the request to know its session.
+func (m *PendingPermissionRequestManager) RespondWithScope(id string, permissions []string, scope, denyReason string) error {
+	switch scope {
+	case "":
+		scope = PermissionScopeOnce
+	case PermissionScopeOnce, PermissionScopeSession, PermissionScopePermanent:
+	default:
+		return fmt.Errorf("invalid permission scope: %s", scope)
+	}
+
 	m.mu.Lock()
 	pr, exists := m.pending[id]
 	if !exists {
 		m.mu.Unlock()
 		return fmt.Errorf("permission request %s not found", id)
 	}
+	for _, p := range permissions {
+		if !slices.Contains(pr.Permissions, p) {
+			m.mu.Unlock()
+			return fmt.Errorf("permission %q was not requested", p)
+		}
+	}
+	if scope == PermissionScopeSession && pr.SessionID == "" {
+		m.mu.Unlock()
+		return fmt.Errorf("requesting session is unknown; grant once or permanently instead")
+	}
 	delete(m.pending, id)
 	m.mu.Unlock()
 
+	response := &PermissionResponse{
+		Granted:     len(permissions) > 0,
+		Permanent:   scope == PermissionScopePermanent,
+		Scope:       scope,
+		Permissions: permissions,
+		DenyReason:  denyReason,
+	}
+
 	// Send response (non-blocking with buffer)
 	select {
-	case pr.ResponseCh <- &PermissionResponse{Granted: granted, Permanent: permanent, DenyReason: denyReason}:
-		fmt.Printf("[MCP:PermissionRequest] Responded to request %s: granted=%v, permanent=%v\n", id[:8], granted, permanent)
+	case pr.ResponseCh <- response:
+		logger.Infof("PermissionRequest: Responded to request %s: granted=%d/%d, scope=%s", id[:8], len(permissions), len(pr.Permissions), scope)
 	default:
-		fmt.Printf("[MCP:PermissionRequest] Warning: request %s response channel full\n", id[:8])
+		logger.Warnf("PermissionRequest: Warning: request %s response channel full", id[:8])
 	}
 
 	return nil
@@ -114,7 +175,7 @@ func (m *PendingPermissionRequestManager) Cancel(id string) {
 
 	// Close the channel to unblock any waiting goroutine
 	close(pr.ResponseCh)
-	fmt.Printf("[MCP:PermissionRequest] Cancelled request %s\n", id[:8])
+	logger.Infof("PermissionRequest: Cancelled request %s", id[:8])
 }
 
 // CancelAll cancels all pending permission requests (e.g., on workspace switch)
@@ -124,7 +185,7 @@ func (m *PendingPermissionRequestManager) CancelAll() {
 
 	for id, pr := range m.pending {
 		close(pr.ResponseCh)
-		fmt.Printf("[MCP:PermissionRequest] Cancelled request %s\n", id[:8])
+		logger.Infof("PermissionRequest: Cancelled request %s", id[:8])
 	}
 	m.pending = make(map[string]*PendingPermissionRequest)
 }
@@ -0,0 +1,248 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/claudehome"
+)
+
+// =============================================================================
+// PLAN REVISIONS
+// Each ExitPlanMode review snapshots the plan file, so after a rejection the
+// reviewer can see what Claude changed in the next submission. Revisions are
+// stored per session at {configPath}/plan_revisions/{sessionID}.json.
+// =============================================================================
+
+// Plan review outcomes
+const (
+	PlanOutcomePending   = "pending"
+	PlanOutcomeAccepted  = "accepted"
+	PlanOutcomeRejected  = "rejected"
+	PlanOutcomeSkipped   = "skipped"
+	PlanOutcomeCancelled = "cancelled"
+	PlanOutcomeTimedOut  = "timed_out"
+)
+
+// PlanRevision is the plan as submitted for one review.
+type PlanRevision struct {
+	ReviewID    string    `json:"reviewId"`
+	AgentID     string    `json:"agentId"`
+	AgentSlug   string    `json:"agentSlug"`
+	SessionID   string    `json:"sessionId"`
+	PlanPath    string    `json:"planPath"`
+	Content     string    `json:"content"`
+	SubmittedAt time.Time `json:"submittedAt"`
+	Outcome     string    `json:"outcome"`
+	Feedback    string    `json:"feedback,omitempty"` // Reviewer feedback sent back with the outcome
+}
+
+// PlanDiffLine is one line of a diff between plan revisions.
+type PlanDiffLine struct {
+	Op   string `json:"op"` // equal, add, remove
+	Text string `json:"text"`
+}
+
+// PlanRevisionStore persists plan revisions per session.
+type PlanRevisionStore struct {
+	dir string // ~/.claudefu/plan_revisions
+	mu  sync.Mutex
+}
+
+// NewPlanRevisionStore creates a store under configPath.
+func NewPlanRevisionStore(configPath string) *PlanRevisionStore {
+	return &PlanRevisionStore{dir: filepath.Join(configPath, "plan_revisions")}
+}
+
+// Record appends a revision to its session's history.
+func (p *PlanRevisionStore) Record(rev PlanRevision) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	revisions, err := p.load(rev.SessionID)
+	if err != nil {
+		return err
+	}
+	return p.save(rev.SessionID, append(revisions, rev))
+}
+
+// SetOutcome records how a review ended.
+func (p *PlanRevisionStore) SetOutcome(sessionID, reviewID, outcome, feedback string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	revisions, err := p.load(sessionID)
+	if err != nil {
+		return err
+	}
+	for i := range revisions {
+		if revisions[i].ReviewID == reviewID {
+			revisions[i].Outcome = outcome
+			revisions[i].Feedback = feedback
+			return p.save(sessionID, revisions)
+		}
+	}
+	return fmt.Errorf("plan revision not found: %s", reviewID)
+}
+
+// List returns a session's revisions, oldest first.
+func (p *PlanRevisionStore) List(sessionID string) ([]PlanRevision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.load(sessionID)
+}
+
+func (p *PlanRevisionStore) path(sessionID string) string {
+	return filepath.Join(p.dir, filepath.Base(sessionID)+".json")
+}
+
+// load reads a session's revisions. Caller must hold p.mu.
+func (p *PlanRevisionStore) load(sessionID string) ([]PlanRevision, error) {
+	data, err := os.ReadFile(p.path(sessionID))
+	if os.IsNotExist(err) {
+		return []PlanRevision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revisions []PlanRevision
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		return nil, fmt.Errorf("failed to parse plan revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// save writes a session's revisions. Caller must hold p.mu.
+func (p *PlanRevisionStore) save(sessionID string, revisions []PlanRevision) error {
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(revisions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path(sessionID), data, 0644)
+}
+
+// activePlan snapshots the plan file of the agent's active session for a new
+// review (nil if there is none). The review ID is filled in by recordPlanRevision.
+func (s *MCPService) activePlan(fromAgent string) *PlanRevision {
+	if s.activeSessionGetter == nil {
+		return nil
+	}
+	agentID, sessionID, _, slug := s.activeSessionGetter(fromAgent)
+	if sessionID == "" || slug == "" {
+		return nil
+	}
+	planPath := claudehome.PlanPath(slug)
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		logger.Infof("ExitPlanMode: Not recording plan revision: %v", err)
+		return nil
+	}
+	return &PlanRevision{
+		AgentID:     agentID,
+		AgentSlug:   fromAgent,
+		SessionID:   sessionID,
+		PlanPath:    planPath,
+		Content:     string(data),
+		SubmittedAt: time.Now(),
+		Outcome:     PlanOutcomePending,
+	}
+}
+
+// recordPlanRevision stores plan (from activePlan) as a review's revision.
+// Returns the session ID the revision was stored under ("" if none).
+func (s *MCPService) recordPlanRevision(reviewID string, plan *PlanRevision) string {
+	if plan == nil {
+		return ""
+	}
+	rev := *plan
+	rev.ReviewID = reviewID
+	if err := s.planRevisions.Record(rev); err != nil {
+		logger.Warnf("Failed to record plan revision: %v", err)
+		return ""
+	}
+	return rev.SessionID
+}
+
+// setPlanRevisionOutcome records a review's outcome if its plan was recorded.
+func (s *MCPService) setPlanRevisionOutcome(sessionID, reviewID, outcome, feedback string) {
+	if sessionID == "" {
+		return
+	}
+	if err := s.planRevisions.SetOutcome(sessionID, reviewID, outcome, feedback); err != nil {
+		logger.Warnf("Failed to update plan revision: %v", err)
+	}
+}
+
+// maxPlanDiffLines bounds the LCS table; longer plans diff as a full replacement.
+const maxPlanDiffLines = 1500
+
+// DiffPlans returns a line diff turning from into to.
+func DiffPlans(from, to string) []PlanDiffLine {
+	a := splitPlanLines(from)
+	b := splitPlanLines(to)
+
+	if len(a) > maxPlanDiffLines || len(b) > maxPlanDiffLines {
+		diff := make([]PlanDiffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			diff = append(diff, PlanDiffLine{Op: "remove", Text: line})
+		}
+		for _, line := range b {
+			diff = append(diff, PlanDiffLine{Op: "add", Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] = length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]PlanDiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, PlanDiffLine{Op: "equal", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, PlanDiffLine{Op: "remove", Text: a[i]})
+			i++
+		default:
+			diff = append(diff, PlanDiffLine{Op: "add", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, PlanDiffLine{Op: "remove", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, PlanDiffLine{Op: "add", Text: b[j]})
+	}
+	return diff
+}
+
+func splitPlanLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
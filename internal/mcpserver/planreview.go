@@ -19,6 +19,7 @@ type PlanReviewAnswer struct {
 type PendingPlanReview struct {
 	ID         string               `json:"id"`
 	AgentSlug  string               `json:"agentSlug"`
+	Plan       string               `json:"plan,omitempty"` // Plan file content when the review was requested (for the remote page)
 	ResponseCh chan *PlanReviewAnswer `json:"-"`
 	CreatedAt  time.Time            `json:"createdAt"`
 }
@@ -39,7 +40,7 @@ func NewPendingPlanReviewManager() *PendingPlanReviewManager {
 }
 
 // Create creates a new pending plan review and returns it
-func (m *PendingPlanReviewManager) Create(agentSlug string) *PendingPlanReview {
+func (m *PendingPlanReviewManager) Create(agentSlug, plan string) *PendingPlanReview {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -47,12 +48,13 @@ func (m *PendingPlanReviewManager) Create(agentSlug string) *PendingPlanReview {
 	pr := &PendingPlanReview{
 		ID:         id,
 		AgentSlug:  agentSlug,
+		Plan:       plan,
 		ResponseCh: make(chan *PlanReviewAnswer, 1),
 		CreatedAt:  time.Now(),
 	}
 
 	m.pending[id] = pr
-	fmt.Printf("[MCP:ExitPlanMode] Created pending plan review %s from agent %s\n", id[:8], agentSlug)
+	logger.Infof("ExitPlanMode: Created pending plan review %s from agent %s", id[:8], agentSlug)
 	return pr
 }
 
@@ -88,9 +90,9 @@ func (m *PendingPlanReviewManager) Accept(id string, feedback string) error {
 
 	select {
 	case pr.ResponseCh <- &PlanReviewAnswer{Accepted: true, Feedback: feedback}:
-		fmt.Printf("[MCP:ExitPlanMode] Accepted plan review %s\n", id[:8])
+		logger.Infof("ExitPlanMode: Accepted plan review %s", id[:8])
 	default:
-		fmt.Printf("[MCP:ExitPlanMode] Warning: plan review %s response channel full\n", id[:8])
+		logger.Warnf("ExitPlanMode: Warning: plan review %s response channel full", id[:8])
 	}
 
 	return nil
@@ -109,9 +111,9 @@ func (m *PendingPlanReviewManager) Reject(id string, feedback string) error {
 
 	select {
 	case pr.ResponseCh <- &PlanReviewAnswer{Accepted: false, Feedback: feedback}:
-		fmt.Printf("[MCP:ExitPlanMode] Rejected plan review %s\n", id[:8])
+		logger.Infof("ExitPlanMode: Rejected plan review %s", id[:8])
 	default:
-		fmt.Printf("[MCP:ExitPlanMode] Warning: plan review %s response channel full\n", id[:8])
+		logger.Warnf("ExitPlanMode: Warning: plan review %s response channel full", id[:8])
 	}
 
 	return nil
@@ -130,9 +132,9 @@ func (m *PendingPlanReviewManager) Skip(id string) error {
 
 	select {
 	case pr.ResponseCh <- &PlanReviewAnswer{Skipped: true}:
-		fmt.Printf("[MCP:ExitPlanMode] Skipped plan review %s\n", id[:8])
+		logger.Infof("ExitPlanMode: Skipped plan review %s", id[:8])
 	default:
-		fmt.Printf("[MCP:ExitPlanMode] Warning: plan review %s response channel full\n", id[:8])
+		logger.Warnf("ExitPlanMode: Warning: plan review %s response channel full", id[:8])
 	}
 
 	return nil
@@ -150,7 +152,7 @@ func (m *PendingPlanReviewManager) Cancel(id string) {
 	m.mu.Unlock()
 
 	close(pr.ResponseCh)
-	fmt.Printf("[MCP:ExitPlanMode] Cancelled plan review %s\n", id[:8])
+	logger.Infof("ExitPlanMode: Cancelled plan review %s", id[:8])
 }
 
 // CancelAll cancels all pending plan reviews
@@ -160,7 +162,7 @@ func (m *PendingPlanReviewManager) CancelAll() {
 
 	for id, pr := range m.pending {
 		close(pr.ResponseCh)
-		fmt.Printf("[MCP:ExitPlanMode] Cancelled plan review %s\n", id[:8])
+		logger.Infof("ExitPlanMode: Cancelled plan review %s", id[:8])
 	}
 	m.pending = make(map[string]*PendingPlanReview)
 }
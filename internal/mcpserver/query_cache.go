@@ -0,0 +1,217 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// =============================================================================
+// QUERY RESULT CACHE
+// =============================================================================
+
+// QueryCacheFile is the cache's file name under the config path.
+const QueryCacheFile = "query_cache.json"
+
+// QueryCacheEntry is a cached AgentQuery/SelfQuery answer.
+type QueryCacheEntry struct {
+	Tool      string    `json:"tool"` // AgentQuery or SelfQuery
+	AgentID   string    `json:"agentId"`
+	AgentSlug string    `json:"agentSlug"`
+	Query     string    `json:"query"` // As first asked (the key uses the normalized form)
+	Result    string    `json:"result"`
+	CachedAt  time.Time `json:"cachedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Hits      int       `json:"hits"`
+}
+
+// QueryCache keeps AgentQuery/SelfQuery answers for a TTL so repeated questions
+// ("where is auth handled") skip spawning a child claude. Entries are keyed by
+// tool, agent, and normalized query text, and persisted to {configPath}/query_cache.json.
+type QueryCache struct {
+	path    string
+	entries map[string]*QueryCacheEntry
+	mu      sync.Mutex
+}
+
+// NewQueryCache loads the cache from configPath, dropping expired entries.
+func NewQueryCache(configPath string) *QueryCache {
+	c := &QueryCache{
+		path:    filepath.Join(configPath, QueryCacheFile),
+		entries: make(map[string]*QueryCacheEntry),
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var entries []*QueryCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		logger.Warnf("QueryCache: ignoring unreadable %s: %v", c.path, err)
+		return c
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if now.Before(e.ExpiresAt) {
+			c.entries[queryCacheKey(e.Tool, e.AgentID, e.Query)] = e
+		}
+	}
+	return c
+}
+
+// normalizeQuery makes trivially different phrasings of a query share a key:
+// case, whitespace, and trailing punctuation are ignored.
+func normalizeQuery(query string) string {
+	q := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return strings.TrimRight(q, "?.! ")
+}
+
+func queryCacheKey(tool, agentID, query string) string {
+	return tool + "\x00" + agentID + "\x00" + normalizeQuery(query)
+}
+
+// Get returns an unexpired answer and counts the hit.
+func (c *QueryCache) Get(tool, agentID, query string) (*QueryCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := queryCacheKey(tool, agentID, query)
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(e.ExpiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	e.Hits++
+	result := *e
+	return &result, true
+}
+
+// Put stores an answer for ttl and writes the cache to disk.
+func (c *QueryCache) Put(tool, agentID, agentSlug, query, result string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[queryCacheKey(tool, agentID, query)] = &QueryCacheEntry{
+		Tool:      tool,
+		AgentID:   agentID,
+		AgentSlug: agentSlug,
+		Query:     query,
+		Result:    result,
+		CachedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := c.save(); err != nil {
+		logger.Warnf("QueryCache: failed to save: %v", err)
+	}
+}
+
+// List returns unexpired entries, newest first.
+func (c *QueryCache) List() []QueryCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	result := make([]QueryCacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		if now.Before(e.ExpiresAt) {
+			result = append(result, *e)
+		}
+	}
+	slices.SortFunc(result, func(a, b QueryCacheEntry) int {
+		return b.CachedAt.Compare(a.CachedAt)
+	})
+	return result
+}
+
+// Clear removes an agent's entries (all entries if agentID is empty) and
+// returns how many were removed.
+func (c *QueryCache) Clear(agentID string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, e := range c.entries {
+		if agentID == "" || e.AgentID == agentID {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, c.save()
+}
+
+// save writes unexpired entries to disk. Caller must hold c.mu.
+func (c *QueryCache) save() error {
+	now := time.Now()
+	entries := make([]*QueryCacheEntry, 0, len(c.entries))
+	for key, e := range c.entries {
+		if !now.Before(e.ExpiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// queryCacheTTL returns the current workspace's query cache TTL (0 = caching off).
+func (s *MCPService) queryCacheTTL() time.Duration {
+	if s.workspace == nil || s.queryCache == nil {
+		return 0
+	}
+	ws := s.workspace()
+	if ws == nil {
+		return 0
+	}
+	return ws.MCPConfig.GetQueryCacheTTL()
+}
+
+// cachedQueryResult returns a cached answer for a query as a tool result, or nil
+// on a miss, when caching is off, or when the caller passed cache_bypass.
+func (s *MCPService) cachedQueryResult(req mcp.CallToolRequest, tool, agentID, query string) *mcp.CallToolResult {
+	if s.queryCacheTTL() <= 0 || getOptionalString(req, "cache_bypass") == "true" {
+		return nil
+	}
+	entry, ok := s.queryCache.Get(tool, agentID, query)
+	if !ok {
+		return nil
+	}
+	logger.Infof("%s: Cache hit for %s (cached %s ago)", tool, entry.AgentSlug, time.Since(entry.CachedAt).Round(time.Second))
+	note := fmt.Sprintf("[Cached answer from %s ago; pass cache_bypass='true' to re-run]\n\n", time.Since(entry.CachedAt).Round(time.Second))
+	return mcp.NewToolResultText(note + entry.Result)
+}
+
+// cacheQueryResult stores a successful answer when caching is on.
+func (s *MCPService) cacheQueryResult(tool, agentID, agentSlug, query, result string) {
+	if ttl := s.queryCacheTTL(); ttl > 0 {
+		s.queryCache.Put(tool, agentID, agentSlug, query, result, ttl)
+	}
+}
@@ -0,0 +1,166 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"claudefu/internal/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// =============================================================================
+// QUERY CONCURRENCY LIMITER
+// =============================================================================
+
+// QueryLimiter bounds how many child claude processes AgentQuery/SelfQuery run at once.
+// Excess queries wait in a FIFO queue. Running several --print children in parallel is
+// what triggers the "tool_use ids must be unique" API errors, so this is the first line
+// of defence; the retry loop in the handlers is the second.
+type QueryLimiter struct {
+	mu      sync.Mutex
+	running int
+	waiting []*queryWaiter
+}
+
+type queryWaiter struct {
+	ready chan struct{}
+}
+
+// QueryTicket is the result of a successful Acquire.
+type QueryTicket struct {
+	Position int           // Queue position on arrival (0 = ran immediately)
+	Waited   time.Duration // Time spent queued
+	release  func()
+}
+
+// Release frees the slot. Safe to call more than once.
+func (t *QueryTicket) Release() {
+	if t.release != nil {
+		t.release()
+		t.release = nil
+	}
+}
+
+// NewQueryLimiter creates an empty limiter.
+func NewQueryLimiter() *QueryLimiter {
+	return &QueryLimiter{}
+}
+
+// Acquire blocks until fewer than limit queries are running (limit <= 0 means unbounded).
+// onQueued is called with the 1-based queue position if the query has to wait.
+func (l *QueryLimiter) Acquire(ctx context.Context, limit int, onQueued func(position int)) (*QueryTicket, error) {
+	start := time.Now()
+
+	l.mu.Lock()
+	if limit <= 0 || (l.running < limit && len(l.waiting) == 0) {
+		l.running++
+		l.mu.Unlock()
+		return &QueryTicket{release: l.release(limit)}, nil
+	}
+	w := &queryWaiter{ready: make(chan struct{})}
+	l.waiting = append(l.waiting, w)
+	position := len(l.waiting)
+	l.mu.Unlock()
+
+	if onQueued != nil {
+		onQueued(position)
+	}
+
+	select {
+	case <-w.ready:
+		// Slot was handed over by release (running already incremented)
+		return &QueryTicket{Position: position, Waited: time.Since(start), release: l.release(limit)}, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for i, other := range l.waiting {
+			if other == w {
+				l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+				break
+			}
+		}
+		l.mu.Unlock()
+		// Lost the race: a slot was handed over just as we gave up — pass it on
+		select {
+		case <-w.ready:
+			l.release(limit)()
+		default:
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release returns a func that frees one slot and hands it to the next waiter.
+func (l *QueryLimiter) release(limit int) func() {
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.running--
+		for len(l.waiting) > 0 && (limit <= 0 || l.running < limit) {
+			next := l.waiting[0]
+			l.waiting = l.waiting[1:]
+			l.running++
+			close(next.ready)
+		}
+	}
+}
+
+// Stats returns the number of running and queued queries.
+func (l *QueryLimiter) Stats() (running, queued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running, len(l.waiting)
+}
+
+// acquireQuerySlot waits for a query slot using the current workspace's limit, telling
+// the UI (mcp:query-queued) and the caller (progress notification, if requested) when queued.
+func (s *MCPService) acquireQuerySlot(ctx context.Context, req mcp.CallToolRequest, tool, agentID string) (*QueryTicket, error) {
+	limit := s.queryLimit()
+	return s.queryLimiter.Acquire(ctx, limit, func(position int) {
+		logger.Infof("%s: Queued at position %d (limit %d concurrent)", tool, position, limit)
+		if s.emitFunc != nil {
+			s.emitFunc(types.EventEnvelope{
+				AgentID:   agentID,
+				EventType: "mcp:query-queued",
+				Payload: map[string]any{
+					"tool":     tool,
+					"position": position,
+					"limit":    limit,
+				},
+			})
+		}
+		if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+			return
+		}
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": req.Params.Meta.ProgressToken,
+				"progress":      0,
+				"message":       fmt.Sprintf("Queued at position %d (max %d concurrent queries)", position, limit),
+			})
+		}
+	})
+}
+
+// queryLimit returns the configured max concurrent queries for the current workspace.
+func (s *MCPService) queryLimit() int {
+	if s.workspace == nil {
+		return 0
+	}
+	ws := s.workspace()
+	if ws == nil {
+		return 0
+	}
+	return ws.MCPConfig.GetMaxConcurrentQueries()
+}
+
+// queueNote returns a short prefix describing how long a query was queued, or "" if it ran immediately.
+func queueNote(ticket *QueryTicket) string {
+	if ticket == nil || ticket.Position == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[Queued at position %d for %s before running]\n\n", ticket.Position, ticket.Waited.Round(time.Second))
+}
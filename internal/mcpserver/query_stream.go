@@ -0,0 +1,167 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"claudefu/internal/metrics"
+	"claudefu/internal/providers"
+	"claudefu/internal/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// =============================================================================
+// STREAMING AGENT QUERY
+// =============================================================================
+
+// queryProgress reports the progress of a streaming AgentQuery to both the UI
+// (mcp:query-progress events) and the calling agent (MCP progress notifications).
+type queryProgress struct {
+	s             *MCPService
+	ctx           context.Context
+	progressToken mcp.ProgressToken
+	queryID       string
+	agentID       string
+	targetAgent   string
+	fromAgent     string
+	step          int
+}
+
+// report emits one progress update. kind is "tool", "text", "done", or "error".
+func (p *queryProgress) report(kind, message string) {
+	p.step++
+
+	if p.s.emitFunc != nil {
+		p.s.emitFunc(types.EventEnvelope{
+			AgentID:   p.agentID,
+			EventType: "mcp:query-progress",
+			Payload: map[string]any{
+				"queryId":     p.queryID,
+				"targetAgent": p.targetAgent,
+				"fromAgent":   p.fromAgent,
+				"step":        p.step,
+				"kind":        kind,
+				"message":     message,
+			},
+		})
+	}
+
+	// Only notify the caller if it asked for progress (MCP spec: _meta.progressToken)
+	if p.progressToken == nil {
+		return
+	}
+	if srv := server.ServerFromContext(p.ctx); srv != nil {
+		if err := srv.SendNotificationToClient(p.ctx, "notifications/progress", map[string]any{
+			"progressToken": p.progressToken,
+			"progress":      p.step,
+			"message":       message,
+		}); err != nil {
+			logger.Warnf("AgentQuery: Failed to send progress notification: %v", err)
+		}
+	}
+}
+
+// runStreamingQuery runs claude --print with --output-format stream-json, reporting
+// tool calls and partial text as they arrive. Returns the final result text, or the
+// stderr/result output alongside the error (so transient-error detection still works).
+func (s *MCPService) runStreamingQuery(ctx context.Context, claudePath string, args []string, folder string, progress *queryProgress) ([]byte, error) {
+	streamArgs := append(append([]string{}, args...), "--output-format", "stream-json", "--verbose")
+	cmd := exec.CommandContext(ctx, claudePath, streamArgs...)
+	cmd.Dir = folder
+	cmd.Env = providers.BuildShellEnvFor(folder)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	procStart := metrics.ProcessStarted(metrics.ProcessQuery)
+	defer metrics.ProcessExited(metrics.ProcessQuery, procStart)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var result *types.ResultEvent
+	var partial strings.Builder // Assistant text seen so far (fallback if no result event)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024) // 10MB max line
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		event, err := types.ClassifyStreamingEvent(line)
+		if err != nil {
+			continue
+		}
+
+		switch event.EventType {
+		case types.StreamingEventAssistant:
+			if event.Assistant.ParentToolUseID != "" {
+				continue // Subagent chatter
+			}
+			for _, block := range event.Assistant.Message.Content {
+				switch block.Type {
+				case "tool_use":
+					progress.report("tool", "Using "+block.Name)
+				case "text":
+					if strings.TrimSpace(block.Text) == "" {
+						continue
+					}
+					partial.WriteString(block.Text)
+					partial.WriteString("\n")
+					progress.report("text", truncateProgress(block.Text, 200))
+				}
+			}
+		case types.StreamingEventResultSuccess, types.StreamingEventResultError:
+			result = event.Result
+		}
+	}
+
+	// A scan error (e.g. an over-long line) stops reading; kill the process so it
+	// doesn't block on a full stdout pipe and Wait can return
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	if scanErr != nil {
+		waitErr = fmt.Errorf("failed to read query output: %w", scanErr)
+	}
+
+	if result != nil && !result.IsError && waitErr == nil {
+		progress.report("done", fmt.Sprintf("Completed in %d turns", result.NumTurns))
+		return []byte(result.Result), nil
+	}
+
+	// Failure: surface stderr + whatever result text we got for diagnostics/retry detection
+	output := stderr.String()
+	if result != nil && result.Result != "" {
+		output += "\n" + result.Result
+	} else if partial.Len() > 0 {
+		output += "\n" + partial.String()
+	}
+	if waitErr == nil {
+		waitErr = fmt.Errorf("query returned an error result")
+	}
+	progress.report("error", truncateProgress(waitErr.Error(), 200))
+	return []byte(output), waitErr
+}
+
+// truncateProgress shortens a progress message to a single line of at most n runes.
+func truncateProgress(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,307 @@
+package mcpserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// REMOTE ANSWERS
+// Questions, permission requests, and plan reviews block an agent until the
+// user responds, but only render in the desktop window. When the workspace
+// enables remote answers, /remote/ on the MCP port serves a small page (and
+// JSON API) for answering them from a phone browser. Every request must carry
+// the workspace's remote token: ?token= on first visit (then a cookie), or an
+// Authorization: Bearer header.
+// =============================================================================
+
+const remoteCookieName = "claudefu_remote"
+
+// RemotePending is everything currently waiting for the user.
+type RemotePending struct {
+	Questions   []RemoteQuestion   `json:"questions"`
+	Permissions []RemotePermission `json:"permissions"`
+	PlanReviews []RemotePlanReview `json:"planReviews"`
+}
+
+// RemoteQuestion is a pending AskUserQuestion.
+type RemoteQuestion struct {
+	ID        string           `json:"id"`
+	AgentSlug string           `json:"agentSlug"`
+	Questions []map[string]any `json:"questions"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// RemotePermission is a pending RequestToolPermission.
+type RemotePermission struct {
+	ID          string    `json:"id"`
+	AgentSlug   string    `json:"agentSlug"`
+	Permissions []string  `json:"permissions"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// RemotePlanReview is a pending ExitPlanMode review.
+type RemotePlanReview struct {
+	ID        string    `json:"id"`
+	AgentSlug string    `json:"agentSlug"`
+	Plan      string    `json:"plan"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// remoteHandler serves /remote/ (page) and /remote/api/ (JSON).
+func (s *MCPService) remoteHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /remote/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(remotePage))
+	})
+	mux.HandleFunc("GET /remote/api/pending", func(w http.ResponseWriter, r *http.Request) {
+		writeRemoteJSON(w, s.remotePending(), nil)
+	})
+	mux.HandleFunc("POST /remote/api/questions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Answers map[string]string `json:"answers"`
+			Skip    bool              `json:"skip"`
+		}
+		id, err := decodeRemoteRequest(w, r, &body)
+		if err == nil {
+			if body.Skip {
+				err = s.pendingQuestions.Skip(id)
+			} else {
+				err = s.pendingQuestions.Answer(id, body.Answers)
+			}
+		}
+		writeRemoteJSON(w, map[string]bool{"ok": err == nil}, err)
+	})
+	mux.HandleFunc("POST /remote/api/permissions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Permissions []string `json:"permissions"` // Granted subset; empty = deny
+			Scope       string   `json:"scope"`
+			DenyReason  string   `json:"denyReason"`
+		}
+		id, err := decodeRemoteRequest(w, r, &body)
+		if err == nil {
+			if body.Scope == "" {
+				body.Scope = PermissionScopeOnce
+			}
+			err = s.pendingPermissions.RespondWithScope(id, body.Permissions, body.Scope, body.DenyReason)
+		}
+		writeRemoteJSON(w, map[string]bool{"ok": err == nil}, err)
+	})
+	mux.HandleFunc("POST /remote/api/plan-reviews/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Action   string `json:"action"` // accept, reject, skip
+			Feedback string `json:"feedback"`
+		}
+		id, err := decodeRemoteRequest(w, r, &body)
+		if err == nil {
+			switch body.Action {
+			case "accept":
+				err = s.pendingPlanReviews.Accept(id, body.Feedback)
+			case "reject":
+				err = s.pendingPlanReviews.Reject(id, body.Feedback)
+			case "skip":
+				err = s.pendingPlanReviews.Skip(id)
+			default:
+				err = fmt.Errorf("action must be accept, reject, or skip")
+			}
+		}
+		writeRemoteJSON(w, map[string]bool{"ok": err == nil}, err)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.remoteAuthorized(w, r) {
+			http.Error(w, "remote answers are disabled or the token is wrong", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// remoteAuthorized checks the request's token against the current workspace's.
+// A valid ?token= sets a cookie so the page's API calls are authorized too.
+func (s *MCPService) remoteAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.workspace == nil {
+		return false
+	}
+	ws := s.workspace()
+	if ws == nil {
+		return false
+	}
+	want := ws.MCPConfig.RemoteAccessToken()
+	if want == "" {
+		return false
+	}
+	matches := func(got string) bool {
+		return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+	}
+
+	if token := r.URL.Query().Get("token"); matches(token) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     remoteCookieName,
+			Value:    token,
+			Path:     "/remote/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		return true
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && matches(bearer) {
+		return true
+	}
+	if cookie, err := r.Cookie(remoteCookieName); err == nil && matches(cookie.Value) {
+		return true
+	}
+	return false
+}
+
+// remotePending collects pending questions, permission requests, and plan reviews.
+func (s *MCPService) remotePending() RemotePending {
+	pending := RemotePending{
+		Questions:   []RemoteQuestion{},
+		Permissions: []RemotePermission{},
+		PlanReviews: []RemotePlanReview{},
+	}
+	for _, pq := range s.pendingQuestions.GetAll() {
+		pending.Questions = append(pending.Questions, RemoteQuestion{
+			ID: pq.ID, AgentSlug: pq.AgentSlug, Questions: pq.Questions, CreatedAt: pq.CreatedAt,
+		})
+	}
+	for _, pr := range s.pendingPermissions.GetAll() {
+		pending.Permissions = append(pending.Permissions, RemotePermission{
+			ID: pr.ID, AgentSlug: pr.AgentSlug, Permissions: pr.Permissions, Reason: pr.Reason, CreatedAt: pr.CreatedAt,
+		})
+	}
+	for _, rv := range s.pendingPlanReviews.GetAll() {
+		pending.PlanReviews = append(pending.PlanReviews, RemotePlanReview{
+			ID: rv.ID, AgentSlug: rv.AgentSlug, Plan: rv.Plan, CreatedAt: rv.CreatedAt,
+		})
+	}
+	return pending
+}
+
+// decodeRemoteRequest validates the {id} path value and decodes the JSON body.
+func decodeRemoteRequest(w http.ResponseWriter, r *http.Request, body any) (string, error) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid id: %s", id)
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(body); err != nil {
+		return "", fmt.Errorf("invalid request: %w", err)
+	}
+	return id, nil
+}
+
+func writeRemoteJSON(w http.ResponseWriter, v any, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// remotePage is the phone-sized answer page. It polls /remote/api/pending.
+const remotePage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>ClaudeFu</title>
+<style>
+body{font-family:-apple-system,system-ui,sans-serif;background:#111;color:#eee;margin:0;padding:12px}
+h1{font-size:18px;margin:4px 0 12px}
+.card{background:#1d1d1d;border:1px solid #333;border-radius:8px;padding:12px;margin-bottom:12px}
+.agent{color:#d97757;font-weight:600;font-size:13px}
+.q{margin:10px 0 6px}
+label{display:block;padding:6px 0}
+textarea,input[type=text]{width:100%;box-sizing:border-box;background:#111;color:#eee;border:1px solid #444;border-radius:6px;padding:8px}
+button{background:#d97757;color:#fff;border:0;border-radius:6px;padding:10px 14px;margin:8px 8px 0 0;font-size:15px}
+button.alt{background:#444}
+code{background:#111;padding:2px 4px;border-radius:4px}
+pre.plan{background:#111;padding:8px;border-radius:6px;white-space:pre-wrap;word-break:break-word;max-height:60vh;overflow:auto;font-size:13px}
+.empty{color:#888}
+</style></head><body>
+<h1>ClaudeFu &mdash; waiting for you</h1>
+<div id="list"><p class="empty">Loading&hellip;</p></div>
+<script>
+const list = document.getElementById('list');
+let busy = false;
+function el(tag, attrs, ...kids) {
+  const e = document.createElement(tag);
+  Object.assign(e, attrs || {});
+  kids.forEach(k => e.append(k));
+  return e;
+}
+async function post(path, body) {
+  busy = true;
+  try {
+    const r = await fetch('api/' + path, {method: 'POST', headers: {'Content-Type': 'application/json'}, body: JSON.stringify(body)});
+    if (!r.ok) alert((await r.json()).error || r.statusText);
+  } finally { busy = false; document.activeElement && document.activeElement.blur(); refresh(); }
+}
+function questionCard(pq) {
+  const card = el('div', {className: 'card'}, el('div', {className: 'agent', textContent: pq.agentSlug}));
+  const inputs = [];
+  pq.questions.forEach((q, i) => {
+    card.append(el('div', {className: 'q', textContent: q.question || ''}));
+    const name = 'q' + pq.id + i;
+    (q.options || []).forEach(o => {
+      const input = el('input', {type: q.multiSelect ? 'checkbox' : 'radio', name: name, value: o.label});
+      card.append(el('label', {}, input, ' ' + o.label));
+    });
+    const other = el('input', {type: 'text', placeholder: 'Other answer'});
+    card.append(other);
+    inputs.push({q, name, other});
+  });
+  const answers = () => {
+    const out = {};
+    inputs.forEach(({q, name, other}) => {
+      const picked = [...card.querySelectorAll('input[name="' + name + '"]:checked')].map(e => e.value);
+      if (other.value.trim()) picked.push(other.value.trim());
+      out[q.question] = picked.join(', ');
+    });
+    return out;
+  };
+  card.append(el('button', {textContent: 'Answer', onclick: () => post('questions/' + pq.id, {answers: answers()})}),
+              el('button', {className: 'alt', textContent: 'Skip', onclick: () => post('questions/' + pq.id, {skip: true})}));
+  return card;
+}
+function permissionCard(pr) {
+  const card = el('div', {className: 'card'}, el('div', {className: 'agent', textContent: pr.agentSlug}),
+    el('div', {className: 'q', textContent: pr.reason}));
+  pr.permissions.forEach(p => card.append(el('div', {}, el('code', {textContent: p}))));
+  const reason = el('input', {type: 'text', placeholder: 'Reason (if denying)'});
+  card.append(reason,
+    el('button', {textContent: 'Allow once', onclick: () => post('permissions/' + pr.id, {permissions: pr.permissions, scope: 'once'})}),
+    el('button', {textContent: 'Allow for session', onclick: () => post('permissions/' + pr.id, {permissions: pr.permissions, scope: 'session'})}),
+    el('button', {className: 'alt', textContent: 'Deny', onclick: () => post('permissions/' + pr.id, {permissions: [], denyReason: reason.value})}));
+  return card;
+}
+function planCard(rv) {
+  const feedback = el('textarea', {rows: 3, placeholder: 'Feedback (optional)'});
+  return el('div', {className: 'card'}, el('div', {className: 'agent', textContent: rv.agentSlug}),
+    el('div', {className: 'q', textContent: 'Plan ready for review'}),
+    rv.plan ? el('pre', {className: 'plan', textContent: rv.plan}) : el('p', {className: 'empty', textContent: 'Plan text unavailable (open ClaudeFu to read it)'}),
+    feedback,
+    el('button', {textContent: 'Accept', onclick: () => post('plan-reviews/' + rv.id, {action: 'accept', feedback: feedback.value})}),
+    el('button', {className: 'alt', textContent: 'Reject', onclick: () => post('plan-reviews/' + rv.id, {action: 'reject', feedback: feedback.value})}));
+}
+async function refresh() {
+  if (busy || document.activeElement && document.activeElement.closest('.card')) return;
+  const r = await fetch('api/pending');
+  if (!r.ok) { list.replaceChildren(el('p', {className: 'empty', textContent: 'Not authorized. Open the link shown in ClaudeFu.'})); return; }
+  const p = await r.json();
+  const cards = [...p.questions.map(questionCard), ...p.permissions.map(permissionCard), ...p.planReviews.map(planCard)];
+  list.replaceChildren(...(cards.length ? cards : [el('p', {className: 'empty', textContent: 'Nothing is waiting for you.'})]));
+}
+refresh();
+setInterval(refresh, 3000);
+</script></body></html>
+`
@@ -0,0 +1,126 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// =============================================================================
+// MCP RESOURCES
+// Read-only views of an agent's CLAUDE.md and backlog, so a connected Claude
+// can load them directly instead of paying for an AgentQuery round-trip.
+// =============================================================================
+
+// agentResourcePrefix is the URI prefix shared by all per-agent resources.
+const agentResourcePrefix = "claudefu://agents/"
+
+// Per-agent resource kinds (the last URI segment).
+const (
+	resourceClaudeMd = "claude-md"
+	resourceBacklog  = "backlog"
+)
+
+// registerResources adds the agent resource templates plus a concrete resource
+// per MCP-enabled agent (so they show up in resources/list).
+func (s *MCPService) registerResources(mcpServer *server.MCPServer, agents []AgentInfo) {
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(agentResourcePrefix+"{slug}/"+resourceClaudeMd, "Agent CLAUDE.md",
+			mcp.WithTemplateDescription("The CLAUDE.md project instructions of a ClaudeFu agent"),
+			mcp.WithTemplateMIMEType("text/markdown"),
+		),
+		s.handleClaudeMdResource,
+	)
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(agentResourcePrefix+"{slug}/"+resourceBacklog, "Agent backlog",
+			mcp.WithTemplateDescription("All backlog items of a ClaudeFu agent, as JSON"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.handleBacklogResource,
+	)
+
+	for _, agent := range agents {
+		mcpServer.AddResource(
+			mcp.NewResource(agentResourcePrefix+agent.Slug+"/"+resourceClaudeMd, agent.Slug+" CLAUDE.md",
+				mcp.WithResourceDescription(fmt.Sprintf("CLAUDE.md of agent %s", agent.Slug)),
+				mcp.WithMIMEType("text/markdown"),
+			),
+			s.handleClaudeMdResource,
+		)
+		mcpServer.AddResource(
+			mcp.NewResource(agentResourcePrefix+agent.Slug+"/"+resourceBacklog, agent.Slug+" backlog",
+				mcp.WithResourceDescription(fmt.Sprintf("Backlog items of agent %s", agent.Slug)),
+				mcp.WithMIMEType("application/json"),
+			),
+			s.handleBacklogResource,
+		)
+	}
+}
+
+// handleClaudeMdResource serves claudefu://agents/{slug}/claude-md
+func (s *MCPService) handleClaudeMdResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if !s.toolAvailability.IsEnabled("ClaudeMdResource") {
+		return nil, fmt.Errorf("CLAUDE.md resources are disabled. Enable in MCP Settings > Tool Availability")
+	}
+	slug, err := resourceAgentSlug(req.Params.URI, resourceClaudeMd)
+	if err != nil {
+		return nil, err
+	}
+	agent := s.findMCPEnabledAgent(slug)
+	if agent == nil {
+		return nil, fmt.Errorf("agent '%s' not found or MCP disabled", slug)
+	}
+
+	data, err := os.ReadFile(filepath.Join(agent.Folder, "CLAUDE.md"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("agent '%s' has no CLAUDE.md", slug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CLAUDE.md: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/markdown", Text: string(data)},
+	}, nil
+}
+
+// handleBacklogResource serves claudefu://agents/{slug}/backlog
+func (s *MCPService) handleBacklogResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if !s.toolAvailability.IsEnabled("BacklogResource") {
+		return nil, fmt.Errorf("backlog resources are disabled. Enable in MCP Settings > Tool Availability")
+	}
+	slug, err := resourceAgentSlug(req.Params.URI, resourceBacklog)
+	if err != nil {
+		return nil, err
+	}
+	agent := s.findMCPEnabledAgent(slug)
+	if agent == nil {
+		return nil, fmt.Errorf("agent '%s' not found or MCP disabled", slug)
+	}
+
+	data, err := json.MarshalIndent(s.backlog.GetItemsByAgent(agent.ID), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backlog: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
+
+// resourceAgentSlug extracts {slug} from claudefu://agents/{slug}/{kind}.
+func resourceAgentSlug(uri, kind string) (string, error) {
+	rest, ok := strings.CutPrefix(uri, agentResourcePrefix)
+	if !ok {
+		return "", fmt.Errorf("unknown resource: %s", uri)
+	}
+	slug, ok := strings.CutSuffix(rest, "/"+kind)
+	if !ok || slug == "" || strings.Contains(slug, "/") {
+		return "", fmt.Errorf("unknown resource: %s", uri)
+	}
+	return slug, nil
+}
@@ -2,22 +2,35 @@ package mcpserver
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"claudefu/internal/logging"
+	"claudefu/internal/metrics"
 	"claudefu/internal/providers"
+	"claudefu/internal/session"
+	"claudefu/internal/summary"
+	"claudefu/internal/tasks"
 	"claudefu/internal/types"
 	"claudefu/internal/workspace"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// logger is the MCP subsystem logger (see internal/logging).
+var logger = logging.For(logging.MCP)
+
 // MCPService provides an MCP server for inter-agent communication
 type MCPService struct {
 	server             *server.MCPServer
 	claude             *providers.ClaudeCodeService
+	sessions           *session.Service
+	tasks              *tasks.Manager
+	summaries          *summary.Service
 	workspace          func() *workspace.Workspace
 	manager            *workspace.Manager
 	emitFunc           func(types.EventEnvelope)
@@ -29,7 +42,18 @@ type MCPService struct {
 	pendingQuestions   *PendingQuestionManager
 	pendingPermissions *PendingPermissionRequestManager
 	pendingPlanReviews *PendingPlanReviewManager
+	queryLimiter       *QueryLimiter
+	queryCache         *QueryCache
+	planRevisions      *PlanRevisionStore
+	permissionLog      *PermissionLog
+	memory             *MemoryStore // Shared memory for the Memory* tools (nil if it failed to open)
+	bridge             browserBridgeState
+	bridgeConn         bridgeConn    // Persistent BrowserAgent bridge WebSocket
+	browserQueue       *QueryLimiter // Queues BrowserAgent calls while the browser is busy
 	activeSessionGetter func(agentSlug string) (agentID, sessionID, folder, slug string)
+	agentActivityGetter func(agentID string) AgentActivity
+	handoffFunc        func(agentID, sessionID string, handoff Handoff) error
+	httpHandlers       map[string]http.Handler // Extra endpoints on the MCP port (see HandleHTTP)
 	port               int
 	inboxPath          string // e.g., ~/.claudefu/inbox
 	ctx                context.Context
@@ -44,6 +68,10 @@ type MCPService struct {
 // backlogConfigPath is the path to store backlog databases (e.g., ~/.claudefu/backlog)
 func NewMCPService(port int, configPath string, inboxConfigPath string, backlogConfigPath string) *MCPService {
 	inbox := NewInboxManager(inboxConfigPath)
+	memory, err := NewMemoryStore(filepath.Join(configPath, MemoryDBFile))
+	if err != nil {
+		logger.Warnf("Shared memory unavailable: %v", err)
+	}
 	return &MCPService{
 		port:               port,
 		inboxPath:          inboxConfigPath,
@@ -54,6 +82,12 @@ func NewMCPService(port int, configPath string, inboxConfigPath string, backlogC
 		pendingQuestions:   NewPendingQuestionManager(),
 		pendingPermissions: NewPendingPermissionRequestManager(),
 		pendingPlanReviews: NewPendingPlanReviewManager(),
+		queryLimiter:       NewQueryLimiter(),
+		browserQueue:       NewQueryLimiter(),
+		queryCache:         NewQueryCache(configPath),
+		planRevisions:      NewPlanRevisionStore(configPath),
+		permissionLog:      NewPermissionLog(configPath),
+		memory:             memory,
 	}
 }
 
@@ -62,6 +96,22 @@ func (s *MCPService) SetClaudeService(claude *providers.ClaudeCodeService) {
 	s.claude = claude
 }
 
+// SetSessionService sets the session service used by AgentNewSession for instant session creation
+func (s *MCPService) SetSessionService(sessions *session.Service) {
+	s.sessions = sessions
+}
+
+// SetTaskManager sets the workspace task graph used by the Task* tools
+func (s *MCPService) SetTaskManager(manager *tasks.Manager) {
+	s.tasks = manager
+}
+
+// SetSummaryService sets the session summary cache used by SessionSummary,
+// AgentHandoff and AgentQuery
+func (s *MCPService) SetSummaryService(summaries *summary.Service) {
+	s.summaries = summaries
+}
+
 // SetWorkspaceGetter sets the function to get the current workspace
 func (s *MCPService) SetWorkspaceGetter(getter func() *workspace.Workspace) {
 	s.workspace = getter
@@ -86,6 +136,48 @@ func (s *MCPService) SetActiveSessionGetter(getter func(agentSlug string) (agent
 	s.activeSessionGetter = getter
 }
 
+// HandleHTTP serves handler at pattern on the MCP port without the agent auth
+// token (handler does its own authentication, e.g. Slack request signatures).
+// Takes effect on the next Start.
+func (s *MCPService) HandleHTTP(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.httpHandlers == nil {
+		s.httpHandlers = make(map[string]http.Handler)
+	}
+	s.httpHandlers[pattern] = handler
+}
+
+// AgentActivity is the runtime view of an agent reported by the AgentStatus tool.
+type AgentActivity struct {
+	SessionID    string    // Session selected in the UI ("" if none)
+	UnreadCount  int       // Unread messages across the agent's sessions
+	LastActivity time.Time // Most recent message in any of the agent's sessions
+}
+
+// SetAgentActivityGetter sets the function that reports an agent's runtime activity.
+func (s *MCPService) SetAgentActivityGetter(getter func(agentID string) AgentActivity) {
+	s.agentActivityGetter = getter
+}
+
+// Handoff is the context AgentHandoff carries from one agent's session to another.
+type Handoff struct {
+	FromAgentID    string
+	FromAgentSlug  string
+	FromFolder     string
+	FromSessionID  string // "" if the requesting session couldn't be determined
+	Summary        string
+	Files          []string // Absolute paths
+	SessionSummary string   // Rendered summary of the source session ("" unless requested)
+	Message        string   // Rendered handoff packet sent as the opening message
+}
+
+// SetHandoffFunc sets the function that links the target session to its source
+// and sends it the handoff packet. It must not block on the target's response.
+func (s *MCPService) SetHandoffFunc(fn func(agentID, sessionID string, handoff Handoff) error) {
+	s.handoffFunc = fn
+}
+
 // GetInbox returns the inbox manager for accessing messages
 func (s *MCPService) GetInbox() *InboxManager {
 	return s.inbox
@@ -121,11 +213,36 @@ func (s *MCPService) GetPendingPermissions() *PendingPermissionRequestManager {
 	return s.pendingPermissions
 }
 
+// GetQueryCache returns the AgentQuery/SelfQuery result cache
+func (s *MCPService) GetQueryCache() *QueryCache {
+	return s.queryCache
+}
+
+// GetQueryLimiter returns the AgentQuery/SelfQuery concurrency limiter
+func (s *MCPService) GetQueryLimiter() *QueryLimiter {
+	return s.queryLimiter
+}
+
 // GetPendingPlanReviews returns the pending plan reviews manager
 func (s *MCPService) GetPendingPlanReviews() *PendingPlanReviewManager {
 	return s.pendingPlanReviews
 }
 
+// GetMemory returns the shared memory store (nil if it failed to open)
+func (s *MCPService) GetMemory() *MemoryStore {
+	return s.memory
+}
+
+// GetPlanRevisions returns the ExitPlanMode plan revision history
+func (s *MCPService) GetPlanRevisions() *PlanRevisionStore {
+	return s.planRevisions
+}
+
+// GetPermissionLog returns the RequestToolPermission audit log
+func (s *MCPService) GetPermissionLog() *PermissionLog {
+	return s.permissionLog
+}
+
 // Start starts the MCP server
 func (s *MCPService) Start() error {
 	s.mu.Lock()
@@ -149,13 +266,13 @@ func (s *MCPService) Start() error {
 	// its own writes before Syncthing replicates them.
 	s.spool.SetWorkspaceGetter(s.workspace)
 	if err := s.spool.Start(s.ctx); err != nil {
-		fmt.Printf("[MCP:Spool] Failed to start spool manager: %v\n", err)
+		logger.Warnf("Spool: Failed to start spool manager: %v", err)
 	}
 
 	// Also recover any messages from .sync-conflict-*.db files left over
 	// from the pre-spool SQLite+Syncthing approach.
 	if recovered := s.inbox.RecoverFromConflictFiles(); recovered > 0 {
-		fmt.Printf("[MCP:Inbox] Recovered %d messages from .sync-conflict-*.db files\n", recovered)
+		logger.Infof("Inbox: Recovered %d messages from .sync-conflict-*.db files", recovered)
 	}
 
 	// Gather MCP-enabled agents for dynamic tool descriptions
@@ -172,6 +289,7 @@ func (s *MCPService) Start() error {
 		server.WithResourceCapabilities(true, true),
 		server.WithPromptCapabilities(true),
 		server.WithToolCapabilities(true),
+		server.WithToolHandlerMiddleware(s.instrumentToolCall),
 	)
 
 	// Register tools with dynamic agent list and configurable instructions
@@ -187,46 +305,123 @@ func (s *MCPService) Start() error {
 	mcpServer.AddTool(CreateBacklogAddTool(instructions.BacklogAdd), s.handleBacklogAdd)
 	mcpServer.AddTool(CreateBacklogUpdateTool(instructions.BacklogUpdate), s.handleBacklogUpdate)
 	mcpServer.AddTool(CreateBacklogListTool(instructions.BacklogList), s.handleBacklogList)
+	mcpServer.AddTool(CreateBacklogMoveTool(instructions.BacklogMove, agents), s.handleBacklogMove)
+	mcpServer.AddTool(CreateBacklogExportTool(instructions.BacklogExport), s.handleBacklogExport)
 	mcpServer.AddTool(CreateMetaserverQueryTool(instructions.MetaserverQuery), s.handleMetaserverQuery)
 	mcpServer.AddTool(CreateMetaserverServicesTool(instructions.MetaserverServices), s.handleMetaserverServices)
 	mcpServer.AddTool(CreateMetaserverStartTool(instructions.MetaserverStart), s.handleMetaserverStart)
 	mcpServer.AddTool(CreateMetaserverStopTool(instructions.MetaserverStop), s.handleMetaserverStop)
 	mcpServer.AddTool(CreateMetaserverRestartTool(instructions.MetaserverRestart), s.handleMetaserverRestart)
+	mcpServer.AddTool(CreateAgentDiffRequestTool(instructions.AgentDiffRequest, agents), s.handleAgentDiffRequest)
+	mcpServer.AddTool(CreateAgentNewSessionTool(instructions.AgentNewSession, agents), s.handleAgentNewSession)
+	mcpServer.AddTool(CreateAgentInboxListTool(instructions.AgentInboxList), s.handleAgentInboxList)
+	mcpServer.AddTool(CreateAgentInboxReplyTool(instructions.AgentInboxReply), s.handleAgentInboxReply)
+	mcpServer.AddTool(CreateTaskCreateTool(instructions.TaskCreate, agents), s.handleTaskCreate)
+	mcpServer.AddTool(CreateTaskAssignTool(instructions.TaskAssign, agents), s.handleTaskAssign)
+	mcpServer.AddTool(CreateTaskCompleteTool(instructions.TaskComplete), s.handleTaskComplete)
+	mcpServer.AddTool(CreateTaskGraphTool(instructions.TaskGraph), s.handleTaskGraph)
+	mcpServer.AddTool(CreateExportSessionTool(instructions.ExportSession), s.handleExportSession)
+	mcpServer.AddTool(CreateAgentStatusTool(instructions.AgentStatus, agents), s.handleAgentStatus)
+	mcpServer.AddTool(CreateMemorySetTool(instructions.MemorySet), s.handleMemorySet)
+	mcpServer.AddTool(CreateMemoryGetTool(instructions.MemoryGet), s.handleMemoryGet)
+	mcpServer.AddTool(CreateMemoryListTool(instructions.MemoryList), s.handleMemoryList)
+	mcpServer.AddTool(CreateAgentHandoffTool(instructions.AgentHandoff, agents), s.handleAgentHandoff)
+	mcpServer.AddTool(CreateSessionSummaryTool(instructions.SessionSummary), s.handleSessionSummary)
+
+	// Register read-only resources (CLAUDE.md, backlog) per agent
+	s.registerResources(mcpServer, agents)
+
+	go s.monitorBrowserBridge(s.ctx)
 
 	s.server = mcpServer
 
 	// Start SSE server in goroutine
 	go func() {
 		sseServer := server.NewSSEServer(mcpServer,
-			server.WithBaseURL(fmt.Sprintf("http://localhost:%d", s.port)),
+			server.WithBaseURL(s.baseURL()),
 		)
 
-		addr := fmt.Sprintf(":%d", s.port)
-		fmt.Printf("[MCP] Starting SSE server on %s\n", addr)
+		addr := s.listenAddr()
+		logger.Infof("Starting SSE server on %s", addr)
+
+		// /remote/ answers pending questions from a phone (its own token); HandleHTTP
+		// endpoints authenticate themselves; everything else is MCP and requires the
+		// agent auth token
+		mux := http.NewServeMux()
+		mux.Handle("/remote/", s.remoteHandler())
+		s.mu.RLock()
+		for pattern, handler := range s.httpHandlers {
+			mux.Handle(pattern, handler)
+		}
+		s.mu.RUnlock()
+		mux.Handle("/", s.requireAgentAuth(sseServer))
 
 		httpServer := &http.Server{
 			Addr:    addr,
-			Handler: sseServer,
+			Handler: mux,
 		}
 
 		// Run server in a goroutine
 		go func() {
 			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				fmt.Printf("[MCP] Server error: %v\n", err)
+				logger.Infof("Server error: %v", err)
 			}
 		}()
 
 		// Wait for context cancellation
 		<-s.ctx.Done()
-		fmt.Println("[MCP] Shutting down SSE server...")
+		logger.Infof("Shutting down SSE server...")
 		httpServer.Close()
 	}()
 
 	s.running = true
-	fmt.Printf("[MCP] MCP server started on port %d\n", s.port)
+	logger.Infof("MCP server started on port %d", s.port)
 	return nil
 }
 
+// instrumentToolCall records call counts and latency for every MCP tool and
+// emits mcp:tool-call. Tool-level failures are reported via IsError results, so
+// both are counted as errors.
+func (s *MCPService) instrumentToolCall(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, req)
+		failed := err != nil || (result != nil && result.IsError)
+		metrics.ObserveToolCall(req.Params.Name, time.Since(start), failed)
+		s.emitToolCall(req, time.Since(start), failed)
+		return result, err
+	}
+}
+
+// emitToolCall reports a finished tool call with its caller and a preview of
+// its arguments (for the activity timeline).
+func (s *MCPService) emitToolCall(req mcp.CallToolRequest, duration time.Duration, failed bool) {
+	if s.emitFunc == nil {
+		return
+	}
+	args, _ := req.Params.Arguments.(map[string]any)
+	fromAgent, _ := args["from_agent"].(string)
+	targetAgent, _ := args["target_agent"].(string)
+	preview := ""
+	for _, key := range []string{"message", "query", "question", "title"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			preview = truncateProgress(v, 200)
+			break
+		}
+	}
+	s.emitFunc(types.EventEnvelope{
+		EventType: "mcp:tool-call",
+		Payload: map[string]any{
+			"tool":        req.Params.Name,
+			"fromAgent":   fromAgent,
+			"targetAgent": targetAgent,
+			"preview":     preview,
+			"durationMs":  duration.Milliseconds(),
+			"failed":      failed,
+		},
+	})
+}
+
 // Stop stops the MCP server
 func (s *MCPService) Stop() {
 	s.mu.Lock()
@@ -245,6 +440,9 @@ func (s *MCPService) Stop() {
 		s.spool.Stop()
 	}
 
+	// Close the BrowserAgent bridge connection (fails in-flight browser requests)
+	s.closeBrowserBridge()
+
 	// Cancel all pending questions
 	if s.pendingQuestions != nil {
 		s.pendingQuestions.CancelAll()
@@ -267,7 +465,7 @@ func (s *MCPService) Stop() {
 	// to a new workspace, or by the app's shutdown handler.
 
 	s.running = false
-	fmt.Println("[MCP] MCP server stopped")
+	logger.Infof("MCP server stopped")
 }
 
 // Restart stops and starts the MCP server (useful for workspace switches)
@@ -276,7 +474,7 @@ func (s *MCPService) Restart() error {
 	return s.Start()
 }
 
-// CloseStores closes the inbox and backlog databases. Called on app shutdown.
+// CloseStores closes the inbox, backlog and shared memory databases. Called on app shutdown.
 func (s *MCPService) CloseStores() {
 	if s.inbox != nil {
 		s.inbox.Close()
@@ -284,6 +482,9 @@ func (s *MCPService) CloseStores() {
 	if s.backlog != nil {
 		s.backlog.Close()
 	}
+	if s.memory != nil {
+		s.memory.Close()
+	}
 }
 
 // LoadInbox opens per-agent inbox databases for the given agent IDs.
@@ -315,13 +516,22 @@ func (s *MCPService) getMCPEnabledAgentInfo() []AgentInfo {
 	}
 
 	var agents []AgentInfo
-	for _, agent := range ws.Agents {
-		if agent.GetMCPEnabled() {
-			agents = append(agents, AgentInfo{
-				Slug:        agent.GetSlug(),
-				Name:        agent.GetSlug(),
-				Description: agent.Description,
-			})
+	for _, section := range ws.GroupedAgents() {
+		group := ""
+		if section.Group != nil {
+			group = section.Group.Name
+		}
+		for _, agent := range section.Agents {
+			if agent.GetMCPEnabled() {
+				agents = append(agents, AgentInfo{
+					Slug:           agent.GetSlug(),
+					Name:           agent.GetSlug(),
+					Description:    agent.Description,
+					Specialization: agent.Specialization,
+					Tags:           agent.Tags,
+					Group:          group,
+				})
+			}
 		}
 	}
 	return agents
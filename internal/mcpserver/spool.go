@@ -152,7 +152,7 @@ func (sm *SpoolManager) WriteMessage(toAgentID string, msg InboxMessage) error {
 		return fmt.Errorf("rename spool: %w", err)
 	}
 
-	fmt.Printf("[MCP:Spool] Wrote spool file for agent %s: %s\n", toAgentID[:8], filename)
+	logger.Infof("Spool: Wrote spool file for agent %s: %s", toAgentID[:8], filename)
 	return nil
 }
 
@@ -206,7 +206,7 @@ func (sm *SpoolManager) Start(ctx context.Context) error {
 			if entry.IsDir() {
 				subdir := filepath.Join(sm.configPath, entry.Name())
 				if err := sm.watcher.Add(subdir); err != nil {
-					fmt.Printf("[MCP:Spool] Warning: watch subdir %s: %v\n", subdir, err)
+					logger.Warnf("Spool: Warning: watch subdir %s: %v", subdir, err)
 				}
 			}
 		}
@@ -219,7 +219,7 @@ func (sm *SpoolManager) Start(ctx context.Context) error {
 	// while ClaudeFu was stopped)
 	imported := sm.ScanAndImport()
 	if imported > 0 {
-		fmt.Printf("[MCP:Spool] Startup scan imported %d pending messages\n", imported)
+		logger.Infof("Spool: Startup scan imported %d pending messages", imported)
 	}
 
 	return nil
@@ -265,7 +265,7 @@ func (sm *SpoolManager) runLoop() {
 			if !ok {
 				return
 			}
-			fmt.Printf("[MCP:Spool] Watcher error: %v\n", err)
+			logger.Infof("Spool: Watcher error: %v", err)
 		}
 	}
 }
@@ -276,7 +276,7 @@ func (sm *SpoolManager) handleEvent(event fsnotify.Event) {
 	if event.Op&fsnotify.Create != 0 {
 		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 			if err := sm.watcher.Add(event.Name); err != nil {
-				fmt.Printf("[MCP:Spool] Warning: watch new subdir %s: %v\n", event.Name, err)
+				logger.Warnf("Spool: Warning: watch new subdir %s: %v", event.Name, err)
 			}
 			// Also scan it immediately in case files arrived with the dir
 			sm.scanAndImportDir(event.Name)
@@ -383,13 +383,13 @@ func (sm *SpoolManager) importFile(path string) bool {
 
 	var msg InboxMessage
 	if err := json.Unmarshal(raw, &msg); err != nil {
-		fmt.Printf("[MCP:Spool] Corrupt spool file %s: %v — deleting\n", path, err)
+		logger.Infof("Spool: Corrupt spool file %s: %v — deleting", path, err)
 		_ = os.Remove(path)
 		return false
 	}
 
 	if msg.ToAgentID == "" || msg.Message == "" {
-		fmt.Printf("[MCP:Spool] Invalid spool file %s (missing fields) — deleting\n", path)
+		logger.Infof("Spool: Invalid spool file %s (missing fields) — deleting", path)
 		_ = os.Remove(path)
 		return false
 	}
@@ -397,7 +397,7 @@ func (sm *SpoolManager) importFile(path string) bool {
 	// Insert into local SQLite via the inbox manager. AddMessageRaw preserves
 	// the original ID so duplicate imports are idempotent (SQLite PRIMARY KEY).
 	if err := sm.inbox.AddMessageRaw(msg); err != nil {
-		fmt.Printf("[MCP:Spool] Failed to insert spool message from %s: %v\n", path, err)
+		logger.Warnf("Spool: Failed to insert spool message from %s: %v", path, err)
 		// Leave file in place so a retry can pick it up
 		return false
 	}
@@ -419,10 +419,10 @@ func (sm *SpoolManager) importFile(path string) bool {
 
 	// Remove the spool file — Syncthing will propagate the deletion
 	if err := os.Remove(path); err != nil {
-		fmt.Printf("[MCP:Spool] Warning: failed to remove imported spool file %s: %v\n", path, err)
+		logger.Warnf("Spool: Warning: failed to remove imported spool file %s: %v", path, err)
 	}
 
-	fmt.Printf("[MCP:Spool] Imported message from %q for agent %s\n", msg.FromAgentName, msg.ToAgentID[:8])
+	logger.Infof("Spool: Imported message from %q for agent %s", msg.FromAgentName, msg.ToAgentID[:8])
 	return true
 }
 
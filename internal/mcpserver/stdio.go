@@ -0,0 +1,91 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// =============================================================================
+// STDIO TRANSPORT
+// `claudefu mcp-stdio` serves the MCP tool set to clients that only speak stdio.
+// It does not run its own MCPService: every JSON-RPC message is forwarded to the
+// running instance (over the control socket) and handled by the same server as
+// SSE clients, so inbox, backlog, and pending-question state are shared.
+// =============================================================================
+
+// HandleMessage handles one raw JSON-RPC message with the running MCP server and
+// returns the encoded response, or nil for notifications.
+func (s *MCPService) HandleMessage(ctx context.Context, message json.RawMessage) (json.RawMessage, error) {
+	s.mu.RLock()
+	srv := s.server
+	running := s.running
+	s.mu.RUnlock()
+	if !running || srv == nil {
+		return nil, fmt.Errorf("MCP server is not running")
+	}
+
+	resp := srv.HandleMessage(ctx, message)
+	if resp == nil {
+		return nil, nil
+	}
+	return json.Marshal(resp)
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from in, passes each to
+// forward, and writes responses to out, one per line. Messages are handled
+// concurrently so a blocking tool (e.g. AskUserQuestion) doesn't stall the
+// others. Returns when in is exhausted and in-flight messages have finished.
+func ServeStdio(ctx context.Context, in io.Reader, out io.Writer, forward func(ctx context.Context, message json.RawMessage) (json.RawMessage, error)) error {
+	var (
+		wg      sync.WaitGroup
+		writeMu sync.Mutex
+	)
+	write := func(data []byte) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		out.Write(append(data, '\n'))
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		message := json.RawMessage(append([]byte(nil), line...))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := forward(ctx, message)
+			if err != nil {
+				resp = stdioErrorResponse(message, err)
+			}
+			if len(resp) > 0 && string(resp) != "null" {
+				write(resp)
+			}
+		}()
+	}
+	wg.Wait()
+	return scanner.Err()
+}
+
+// stdioErrorResponse builds a JSON-RPC error for a request that could not be
+// forwarded. Notifications (no id) get no response.
+func stdioErrorResponse(message json.RawMessage, err error) json.RawMessage {
+	var req struct {
+		ID *mcp.RequestId `json:"id"`
+	}
+	if json.Unmarshal(message, &req) != nil || req.ID == nil {
+		return nil
+	}
+	data, _ := json.Marshal(mcp.NewJSONRPCError(*req.ID, mcp.INTERNAL_ERROR, err.Error(), nil))
+	return data
+}
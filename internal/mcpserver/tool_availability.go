@@ -25,11 +25,30 @@ type ToolAvailability struct {
 	BacklogAdd            bool `json:"backlogAdd"`            // Enabled by default
 	BacklogUpdate         bool `json:"backlogUpdate"`         // Enabled by default
 	BacklogList           bool `json:"backlogList"`           // Enabled by default
+	BacklogMove           bool `json:"backlogMove"`           // Enabled by default
+	BacklogExport         bool `json:"backlogExport"`         // Enabled by default
 	MetaserverQuery       bool `json:"metaserverQuery"`       // Disabled by default - requires metaserver on :9990
 	MetaserverServices    bool `json:"metaserverServices"`    // Disabled by default - requires metaserver on :9990
 	MetaserverStart       bool `json:"metaserverStart"`       // Disabled by default - requires metaserver on :9990
 	MetaserverStop        bool `json:"metaserverStop"`        // Disabled by default - requires metaserver on :9990
 	MetaserverRestart     bool `json:"metaserverRestart"`     // Disabled by default - requires metaserver on :9990
+	AgentDiffRequest      bool `json:"agentDiffRequest"`      // Enabled by default
+	AgentNewSession       bool `json:"agentNewSession"`       // Enabled by default
+	AgentInboxList        bool `json:"agentInboxList"`        // Enabled by default
+	AgentInboxReply       bool `json:"agentInboxReply"`       // Enabled by default
+	TaskCreate            bool `json:"taskCreate"`            // Enabled by default
+	TaskAssign            bool `json:"taskAssign"`            // Enabled by default
+	TaskComplete          bool `json:"taskComplete"`          // Enabled by default
+	TaskGraph             bool `json:"taskGraph"`             // Enabled by default
+	ExportSession         bool `json:"exportSession"`         // Enabled by default
+	AgentStatus           bool `json:"agentStatus"`           // Enabled by default
+	MemorySet             bool `json:"memorySet"`             // Enabled by default
+	MemoryGet             bool `json:"memoryGet"`             // Enabled by default
+	MemoryList            bool `json:"memoryList"`            // Enabled by default
+	AgentHandoff          bool `json:"agentHandoff"`          // Enabled by default
+	SessionSummary        bool `json:"sessionSummary"`        // Enabled by default
+	ClaudeMdResource      bool `json:"claudeMdResource"`      // claudefu://agents/{slug}/claude-md - Enabled by default
+	BacklogResource       bool `json:"backlogResource"`       // claudefu://agents/{slug}/backlog - Enabled by default
 }
 
 // ToolAvailabilityManager handles loading and saving tool availability settings
@@ -65,11 +84,30 @@ func DefaultToolAvailability() *ToolAvailability {
 		BacklogAdd:            true,  // Enabled by default
 		BacklogUpdate:         true,  // Enabled by default
 		BacklogList:           true,  // Enabled by default
+		BacklogMove:           true,  // Enabled by default
+		BacklogExport:         true,  // Enabled by default
 		MetaserverQuery:       false, // Disabled by default - requires metaserver on :9990
 		MetaserverServices:    false, // Disabled by default - requires metaserver on :9990
 		MetaserverStart:       false, // Disabled by default - requires metaserver on :9990
 		MetaserverStop:        false, // Disabled by default - requires metaserver on :9990
 		MetaserverRestart:     false, // Disabled by default - requires metaserver on :9990
+		AgentDiffRequest:      true,  // Enabled by default
+		AgentNewSession:       true,  // Enabled by default
+		AgentInboxList:        true,  // Enabled by default
+		AgentInboxReply:       true,  // Enabled by default
+		TaskCreate:            true,  // Enabled by default
+		TaskAssign:            true,  // Enabled by default
+		TaskComplete:          true,  // Enabled by default
+		TaskGraph:             true,  // Enabled by default
+		ExportSession:         true,  // Enabled by default
+		AgentStatus:           true,  // Enabled by default
+		MemorySet:             true,  // Enabled by default
+		MemoryGet:             true,  // Enabled by default
+		MemoryList:            true,  // Enabled by default
+		AgentHandoff:          true,  // Enabled by default
+		SessionSummary:        true,  // Enabled by default
+		ClaudeMdResource:      true,  // Enabled by default
+		BacklogResource:       true,  // Enabled by default
 	}
 }
 
@@ -110,6 +148,10 @@ func (m *ToolAvailabilityManager) IsEnabled(toolName string) bool {
 		return m.availability.BacklogUpdate
 	case "BacklogList":
 		return m.availability.BacklogList
+	case "BacklogMove":
+		return m.availability.BacklogMove
+	case "BacklogExport":
+		return m.availability.BacklogExport
 	case "MetaserverQuery":
 		return m.availability.MetaserverQuery
 	case "MetaserverServices":
@@ -120,6 +162,40 @@ func (m *ToolAvailabilityManager) IsEnabled(toolName string) bool {
 		return m.availability.MetaserverStop
 	case "MetaserverRestart":
 		return m.availability.MetaserverRestart
+	case "AgentDiffRequest":
+		return m.availability.AgentDiffRequest
+	case "AgentNewSession":
+		return m.availability.AgentNewSession
+	case "AgentInboxList":
+		return m.availability.AgentInboxList
+	case "AgentInboxReply":
+		return m.availability.AgentInboxReply
+	case "TaskCreate":
+		return m.availability.TaskCreate
+	case "TaskAssign":
+		return m.availability.TaskAssign
+	case "TaskComplete":
+		return m.availability.TaskComplete
+	case "TaskGraph":
+		return m.availability.TaskGraph
+	case "ExportSession":
+		return m.availability.ExportSession
+	case "AgentStatus":
+		return m.availability.AgentStatus
+	case "MemorySet":
+		return m.availability.MemorySet
+	case "MemoryGet":
+		return m.availability.MemoryGet
+	case "MemoryList":
+		return m.availability.MemoryList
+	case "AgentHandoff":
+		return m.availability.AgentHandoff
+	case "SessionSummary":
+		return m.availability.SessionSummary
+	case "ClaudeMdResource":
+		return m.availability.ClaudeMdResource
+	case "BacklogResource":
+		return m.availability.BacklogResource
 	default:
 		return false
 	}
@@ -31,11 +31,28 @@ type ToolInstructions struct {
 	BacklogAdd              string `json:"backlogAdd"`              // BacklogAdd tool description
 	BacklogUpdate           string `json:"backlogUpdate"`           // BacklogUpdate tool description
 	BacklogList             string `json:"backlogList"`             // BacklogList tool description
+	BacklogMove             string `json:"backlogMove"`             // BacklogMove tool description
+	BacklogExport           string `json:"backlogExport"`           // BacklogExport tool description
 	MetaserverQuery         string `json:"metaserverQuery"`         // MetaserverQuery tool description
 	MetaserverServices      string `json:"metaserverServices"`      // MetaserverServices tool description
 	MetaserverStart         string `json:"metaserverStart"`         // MetaserverStart tool description
 	MetaserverStop          string `json:"metaserverStop"`          // MetaserverStop tool description
 	MetaserverRestart       string `json:"metaserverRestart"`       // MetaserverRestart tool description
+	AgentDiffRequest        string `json:"agentDiffRequest"`        // AgentDiffRequest tool description
+	AgentNewSession         string `json:"agentNewSession"`         // AgentNewSession tool description
+	AgentInboxList          string `json:"agentInboxList"`          // AgentInboxList tool description
+	AgentInboxReply         string `json:"agentInboxReply"`         // AgentInboxReply tool description
+	TaskCreate              string `json:"taskCreate"`              // TaskCreate tool description
+	TaskAssign              string `json:"taskAssign"`              // TaskAssign tool description
+	TaskComplete            string `json:"taskComplete"`            // TaskComplete tool description
+	TaskGraph               string `json:"taskGraph"`               // TaskGraph tool description
+	ExportSession           string `json:"exportSession"`           // ExportSession tool description
+	AgentStatus             string `json:"agentStatus"`             // AgentStatus tool description
+	MemorySet               string `json:"memorySet"`               // MemorySet tool description
+	MemoryGet               string `json:"memoryGet"`               // MemoryGet tool description
+	MemoryList              string `json:"memoryList"`              // MemoryList tool description
+	AgentHandoff            string `json:"agentHandoff"`            // AgentHandoff tool description
+	SessionSummary          string `json:"sessionSummary"`          // SessionSummary tool description
 }
 
 // ToolInstructionsManager handles loading and saving tool instructions
@@ -184,6 +201,14 @@ func (m *ToolInstructionsManager) load() error {
 		ti.BacklogList = defaults.BacklogList
 		needsSave = true
 	}
+	if ti.BacklogMove == "" {
+		ti.BacklogMove = defaults.BacklogMove
+		needsSave = true
+	}
+	if ti.BacklogExport == "" {
+		ti.BacklogExport = defaults.BacklogExport
+		needsSave = true
+	}
 	if ti.MetaserverQuery == "" {
 		ti.MetaserverQuery = defaults.MetaserverQuery
 		needsSave = true
@@ -204,6 +229,66 @@ func (m *ToolInstructionsManager) load() error {
 		ti.MetaserverRestart = defaults.MetaserverRestart
 		needsSave = true
 	}
+	if ti.AgentDiffRequest == "" {
+		ti.AgentDiffRequest = defaults.AgentDiffRequest
+		needsSave = true
+	}
+	if ti.AgentNewSession == "" {
+		ti.AgentNewSession = defaults.AgentNewSession
+		needsSave = true
+	}
+	if ti.AgentInboxList == "" {
+		ti.AgentInboxList = defaults.AgentInboxList
+		needsSave = true
+	}
+	if ti.AgentInboxReply == "" {
+		ti.AgentInboxReply = defaults.AgentInboxReply
+		needsSave = true
+	}
+	if ti.TaskCreate == "" {
+		ti.TaskCreate = defaults.TaskCreate
+		needsSave = true
+	}
+	if ti.TaskAssign == "" {
+		ti.TaskAssign = defaults.TaskAssign
+		needsSave = true
+	}
+	if ti.TaskComplete == "" {
+		ti.TaskComplete = defaults.TaskComplete
+		needsSave = true
+	}
+	if ti.TaskGraph == "" {
+		ti.TaskGraph = defaults.TaskGraph
+		needsSave = true
+	}
+	if ti.ExportSession == "" {
+		ti.ExportSession = defaults.ExportSession
+		needsSave = true
+	}
+	if ti.AgentStatus == "" {
+		ti.AgentStatus = defaults.AgentStatus
+		needsSave = true
+	}
+	if ti.MemorySet == "" {
+		ti.MemorySet = defaults.MemorySet
+		needsSave = true
+	}
+	if ti.MemoryGet == "" {
+		ti.MemoryGet = defaults.MemoryGet
+		needsSave = true
+	}
+	if ti.MemoryList == "" {
+		ti.MemoryList = defaults.MemoryList
+		needsSave = true
+	}
+	if ti.AgentHandoff == "" {
+		ti.AgentHandoff = defaults.AgentHandoff
+		needsSave = true
+	}
+	if ti.SessionSummary == "" {
+		ti.SessionSummary = defaults.SessionSummary
+		needsSave = true
+	}
 
 	m.instructions = &ti
 
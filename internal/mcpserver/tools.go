@@ -1,7 +1,6 @@
 package mcpserver
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -9,9 +8,31 @@ import (
 
 // AgentInfo holds the information needed to describe an agent in tool descriptions
 type AgentInfo struct {
-	Slug        string
-	Name        string
-	Description string
+	Slug           string
+	Name           string
+	Description    string
+	Specialization string
+	Tags           []string
+	Group          string // Agent group name (empty = ungrouped)
+}
+
+// formatAgentLine renders one agent entry, e.g. "- api [backend; tags: db, auth]: REST API".
+func formatAgentLine(agent AgentInfo) string {
+	line := "\n- " + agent.Slug
+	var labels []string
+	if agent.Specialization != "" {
+		labels = append(labels, agent.Specialization)
+	}
+	if len(agent.Tags) > 0 {
+		labels = append(labels, "tags: "+strings.Join(agent.Tags, ", "))
+	}
+	if len(labels) > 0 {
+		line += " [" + strings.Join(labels, "; ") + "]"
+	}
+	if agent.Description != "" {
+		line += ": " + agent.Description
+	}
+	return line
 }
 
 // buildAgentListDescription builds a formatted list of available agents for tool descriptions.
@@ -24,27 +45,39 @@ func buildAgentListDescription(agents []AgentInfo, crossWorkspaceAgents []AgentI
 	var sb strings.Builder
 	if len(agents) > 0 {
 		sb.WriteString("\n\nAvailable agents:")
-		for _, agent := range agents {
-			if agent.Description != "" {
-				sb.WriteString(fmt.Sprintf("\n- %s: %s", agent.Slug, agent.Description))
-			} else {
-				sb.WriteString(fmt.Sprintf("\n- %s", agent.Slug))
-			}
-		}
+		writeGroupedAgentLines(&sb, agents)
 	}
 	if len(crossWorkspaceAgents) > 0 {
 		sb.WriteString("\n\nCross-workspace agents:")
 		for _, agent := range crossWorkspaceAgents {
-			if agent.Description != "" {
-				sb.WriteString(fmt.Sprintf("\n- %s: %s", agent.Slug, agent.Description))
-			} else {
-				sb.WriteString(fmt.Sprintf("\n- %s", agent.Slug))
-			}
+			sb.WriteString(formatAgentLine(agent))
 		}
 	}
 	return sb.String()
 }
 
+// writeGroupedAgentLines writes agents in order, with a "Group:" heading before
+// each run of agents in the same group. Without any groups the list is flat.
+func writeGroupedAgentLines(sb *strings.Builder, agents []AgentInfo) {
+	grouped := false
+	for _, agent := range agents {
+		if agent.Group != "" {
+			grouped = true
+			break
+		}
+	}
+	for i, agent := range agents {
+		if grouped && (i == 0 || agents[i-1].Group != agent.Group) {
+			heading := agent.Group
+			if heading == "" {
+				heading = "Other"
+			}
+			sb.WriteString("\n" + heading + ":")
+		}
+		sb.WriteString(formatAgentLine(agent))
+	}
+}
+
 // CreateAgentQueryTool creates the AgentQuery tool definition with dynamic agent list
 func CreateAgentQueryTool(instruction string, agents []AgentInfo) mcp.Tool {
 	description := instruction
@@ -63,6 +96,18 @@ func CreateAgentQueryTool(instruction string, agents []AgentInfo) mcp.Tool {
 		mcp.WithString("from_agent",
 			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
 		),
+		mcp.WithString("stream",
+			mcp.Description("Report progress (tool calls, partial answers) while the query runs ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
+		mcp.WithString("cache_bypass",
+			mcp.Description("Ignore any cached answer and re-run the query ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
+		mcp.WithString("include_session_summary",
+			mcp.Description("Send a summary of your current session as context with the query ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
 	)
 }
 
@@ -74,8 +119,13 @@ func CreateAgentMessageTool(instruction string, agents []AgentInfo, crossWorkspa
 	return mcp.NewTool("AgentMessage",
 		mcp.WithDescription(description),
 		mcp.WithString("target_agents",
-			mcp.Required(),
-			mcp.Description("Comma-separated list of agent names or slugs to message (e.g., 'api,frontend' or just 'api')"),
+			mcp.Description("Comma-separated list of agent names or slugs to message (e.g., 'api,frontend' or just 'api'). Required unless target_specialization or target_tags is given."),
+		),
+		mcp.WithString("target_specialization",
+			mcp.Description("Message every workspace agent with this specialization (e.g., 'backend')"),
+		),
+		mcp.WithString("target_tags",
+			mcp.Description("Comma-separated tags; message every workspace agent that has any of them (e.g., 'api,db')"),
 		),
 		mcp.WithString("message",
 			mcp.Required(),
@@ -88,6 +138,9 @@ func CreateAgentMessageTool(instruction string, agents []AgentInfo, crossWorkspa
 			mcp.Description("Message priority: 'normal' (default) or 'high'"),
 			mcp.Enum("normal", "high"),
 		),
+		mcp.WithString("thread_id",
+			mcp.Description("Continue an existing conversation thread (optional; omit to start a new thread)"),
+		),
 	)
 }
 
@@ -105,6 +158,12 @@ func CreateAgentBroadcastTool(instruction string, agents []AgentInfo) mcp.Tool {
 		mcp.WithString("from_agent",
 			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
 		),
+		mcp.WithString("target_specialization",
+			mcp.Description("Only broadcast to agents with this specialization (e.g., 'frontend')"),
+		),
+		mcp.WithString("target_tags",
+			mcp.Description("Comma-separated tags; only broadcast to agents that have any of them"),
+		),
 		mcp.WithString("priority",
 			mcp.Description("Message priority: 'normal' (default) or 'high'"),
 			mcp.Enum("normal", "high"),
@@ -162,6 +221,10 @@ func CreateSelfQueryTool(instruction string) mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Your agent name/slug - REQUIRED to identify your folder"),
 		),
+		mcp.WithString("cache_bypass",
+			mcp.Description("Ignore any cached answer and re-run the query ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
 	)
 }
 
@@ -200,15 +263,18 @@ func CreateRequestToolPermissionTool(instruction string) mcp.Tool {
 	return mcp.NewTool("RequestToolPermission",
 		mcp.WithDescription(instruction),
 		mcp.WithString("permission",
-			mcp.Required(),
-			mcp.Description("The permission pattern being requested (e.g., 'Bash(git push:*)' or 'Bash(npm publish:*)')"),
+			mcp.Description("The permission pattern being requested (e.g., 'Bash(git push:*)' or 'Bash(npm publish:*)'). Use permissions for several related patterns."),
+		),
+		mcp.WithArray("permissions",
+			mcp.Description("Several related permission patterns to request together in one prompt (e.g., ['Bash(git add:*)', 'Bash(git commit:*)', 'Bash(git push:*)']). The user may grant a subset."),
+			mcp.WithStringItems(),
 		),
 		mcp.WithString("reason",
 			mcp.Required(),
 			mcp.Description("Why this permission is needed - explain what you want to do and why"),
 		),
 		mcp.WithString("from_agent",
-			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
+			mcp.Description("Your agent name/slug for identification (optional but recommended; needed for session-scoped grants)"),
 		),
 	)
 }
@@ -410,3 +476,352 @@ func CreateBacklogListTool(instruction string) mcp.Tool {
 		),
 	)
 }
+
+// CreateBacklogMoveTool creates the BacklogMove tool definition
+func CreateBacklogMoveTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("BacklogMove",
+		mcp.WithDescription(description),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("UUID of the backlog item to move (its subtasks move with it)"),
+		),
+		mcp.WithString("target_agent",
+			mcp.Required(),
+			mcp.Description("Name or slug of the agent whose backlog should receive the item"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent name/slug for attribution (used for logging)"),
+		),
+	)
+}
+
+// CreateBacklogExportTool creates the BacklogExport tool definition
+func CreateBacklogExportTool(instruction string) mcp.Tool {
+	return mcp.NewTool("BacklogExport",
+		mcp.WithDescription(instruction),
+		mcp.WithString("format",
+			mcp.Description("Export format (default: 'markdown')"),
+			mcp.Enum("markdown", "json"),
+		),
+		mcp.WithString("target_agent",
+			mcp.Description("Slug of the agent whose backlog to export (defaults to your own)"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Required(),
+			mcp.Description("CRITICAL: Your OWN agent slug or AGENT_ID from your CLAUDE.md."),
+		),
+	)
+}
+
+// CreateAgentDiffRequestTool creates the AgentDiffRequest tool definition with dynamic agent list
+func CreateAgentDiffRequestTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("AgentDiffRequest",
+		mcp.WithDescription(description),
+		mcp.WithString("target_agent",
+			mcp.Required(),
+			mcp.Description("Name or slug of the agent whose uncommitted changes you want to see"),
+		),
+		mcp.WithString("pathspec",
+			mcp.Description("Space-separated paths relative to the target agent's folder (omit for the whole repo)"),
+		),
+		mcp.WithString("staged",
+			mcp.Description("Show staged changes only ('true'/'false', default: false = working tree vs index)"),
+			mcp.Enum("true", "false"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateAgentNewSessionTool creates the AgentNewSession tool definition with dynamic agent list
+func CreateAgentNewSessionTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("AgentNewSession",
+		mcp.WithDescription(description),
+		mcp.WithString("target_agent",
+			mcp.Required(),
+			mcp.Description("Name or slug of the agent to create a new session for"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateAgentHandoffTool creates the AgentHandoff tool definition with dynamic agent list
+func CreateAgentHandoffTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("AgentHandoff",
+		mcp.WithDescription(description),
+		mcp.WithString("target_agent",
+			mcp.Required(),
+			mcp.Description("Name or slug of the agent that should continue the work"),
+		),
+		mcp.WithString("summary",
+			mcp.Required(),
+			mcp.Description("What was done, what remains, key decisions and open questions"),
+		),
+		mcp.WithString("files",
+			mcp.Description("Relevant file paths, comma or newline separated"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("Existing session of the target agent to continue in (omit for a new session)"),
+		),
+		mcp.WithString("include_session_summary",
+			mcp.Description("Attach a generated summary of your current session to the packet ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent name/slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateAgentInboxListTool creates the AgentInboxList tool definition
+func CreateAgentInboxListTool(instruction string) mcp.Tool {
+	return mcp.NewTool("AgentInboxList",
+		mcp.WithDescription(instruction),
+		mcp.WithString("from_agent",
+			mcp.Required(),
+			mcp.Description("CRITICAL: Your OWN agent slug or AGENT_ID from your CLAUDE.md. This determines whose inbox you read."),
+		),
+		mcp.WithString("unread_only",
+			mcp.Description("Only list unread messages ('true'/'false', default: false)"),
+			mcp.Enum("true", "false"),
+		),
+		mcp.WithString("thread_id",
+			mcp.Description("Show the full conversation for one thread instead of the inbox"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of messages to return (default: 20)"),
+		),
+		mcp.WithString("mark_read",
+			mcp.Description("Mark listed messages as read ('true'/'false', default: true)"),
+			mcp.Enum("true", "false"),
+		),
+	)
+}
+
+// CreateAgentInboxReplyTool creates the AgentInboxReply tool definition
+func CreateAgentInboxReplyTool(instruction string) mcp.Tool {
+	return mcp.NewTool("AgentInboxReply",
+		mcp.WithDescription(instruction),
+		mcp.WithString("message_id",
+			mcp.Required(),
+			mcp.Description("ID of the inbox message you are replying to (from AgentInboxList)"),
+		),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("Your reply"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Required(),
+			mcp.Description("CRITICAL: Your OWN agent slug or AGENT_ID from your CLAUDE.md (the inbox that holds message_id)."),
+		),
+		mcp.WithString("priority",
+			mcp.Description("Message priority: 'normal' (default) or 'high'"),
+			mcp.Enum("normal", "high"),
+		),
+	)
+}
+
+// CreateTaskCreateTool creates the TaskCreate tool definition with dynamic agent list
+func CreateTaskCreateTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("TaskCreate",
+		mcp.WithDescription(description),
+		mcp.WithString("title",
+			mcp.Required(),
+			mcp.Description("Short task title"),
+		),
+		mcp.WithString("description",
+			mcp.Description("What needs to be done and how to tell it is finished"),
+		),
+		mcp.WithString("depends_on",
+			mcp.Description("Comma-separated IDs of tasks that must be completed first"),
+		),
+		mcp.WithString("assign_to",
+			mcp.Description("Slug of the agent responsible for the task"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("Session where the work happens (optional; lets the UI show live activity)"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateTaskAssignTool creates the TaskAssign tool definition with dynamic agent list
+func CreateTaskAssignTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("TaskAssign",
+		mcp.WithDescription(description),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task to assign"),
+		),
+		mcp.WithString("target_agent",
+			mcp.Required(),
+			mcp.Description("Slug of the agent responsible for the task"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("Session where the work happens (optional)"),
+		),
+	)
+}
+
+// CreateTaskCompleteTool creates the TaskComplete tool definition
+func CreateTaskCompleteTool(instruction string) mcp.Tool {
+	return mcp.NewTool("TaskComplete",
+		mcp.WithDescription(instruction),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task you finished"),
+		),
+		mcp.WithString("result",
+			mcp.Description("Short summary of the outcome"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateTaskGraphTool creates the TaskGraph tool definition
+func CreateTaskGraphTool(instruction string) mcp.Tool {
+	return mcp.NewTool("TaskGraph",
+		mcp.WithDescription(instruction),
+		mcp.WithString("state",
+			mcp.Description("Only show tasks in this state (omit for all)"),
+			mcp.Enum("ready", "blocked", "in_progress", "done", "cancelled"),
+		),
+	)
+}
+
+// CreateExportSessionTool creates the ExportSession tool definition
+func CreateExportSessionTool(instruction string) mcp.Tool {
+	return mcp.NewTool("ExportSession",
+		mcp.WithDescription(instruction),
+		mcp.WithString("session_id",
+			mcp.Required(),
+			mcp.Description("The session ID to export"),
+		),
+		mcp.WithString("target_agent",
+			mcp.Description("Slug of the agent that owns the session (defaults to from_agent)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: markdown)"),
+			mcp.Enum("markdown", "html", "json"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateAgentStatusTool creates the AgentStatus tool definition with dynamic agent list
+func CreateAgentStatusTool(instruction string, agents []AgentInfo) mcp.Tool {
+	description := instruction
+	description += buildAgentListDescription(agents, nil)
+
+	return mcp.NewTool("AgentStatus",
+		mcp.WithDescription(description),
+		mcp.WithString("target_agent",
+			mcp.Description("Slug of the agent to check (omit for all agents)"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for identification (optional but recommended)"),
+		),
+	)
+}
+
+// CreateMemorySetTool creates the MemorySet tool definition
+func CreateMemorySetTool(instruction string) mcp.Tool {
+	return mcp.NewTool("MemorySet",
+		mcp.WithDescription(instruction),
+		mcp.WithString("key",
+			mcp.Required(),
+			mcp.Description("The entry's key"),
+		),
+		mcp.WithString("value",
+			mcp.Required(),
+			mcp.Description("The value (plain text or JSON); empty deletes the entry"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace grouping related keys (default: 'default')"),
+		),
+		mcp.WithNumber("ttl_seconds",
+			mcp.Description("Expire the entry after this many seconds (omit to keep it)"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for attribution (optional but recommended)"),
+		),
+	)
+}
+
+// CreateMemoryGetTool creates the MemoryGet tool definition
+func CreateMemoryGetTool(instruction string) mcp.Tool {
+	return mcp.NewTool("MemoryGet",
+		mcp.WithDescription(instruction),
+		mcp.WithString("key",
+			mcp.Required(),
+			mcp.Description("The entry's key"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("The key's namespace (default: 'default')"),
+		),
+	)
+}
+
+// CreateMemoryListTool creates the MemoryList tool definition
+func CreateMemoryListTool(instruction string) mcp.Tool {
+	return mcp.NewTool("MemoryList",
+		mcp.WithDescription(instruction),
+		mcp.WithString("namespace",
+			mcp.Description("Only list this namespace (omit for all namespaces)"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Only list keys starting with this prefix"),
+		),
+		mcp.WithString("include_values",
+			mcp.Description("Include values ('true'/'false', default: true)"),
+			mcp.Enum("true", "false"),
+		),
+	)
+}
+
+// CreateSessionSummaryTool creates the SessionSummary tool definition
+func CreateSessionSummaryTool(instruction string) mcp.Tool {
+	return mcp.NewTool("SessionSummary",
+		mcp.WithDescription(instruction),
+		mcp.WithString("target_agent",
+			mcp.Description("Slug of the agent that owns the session (defaults to from_agent)"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("The session to summarize (defaults to your current session)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		mcp.WithString("from_agent",
+			mcp.Description("Your agent slug for identification (optional but recommended)"),
+		),
+	)
+}
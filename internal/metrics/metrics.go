@@ -0,0 +1,293 @@
+// Package metrics collects process-wide counters and gauges for ClaudeFu and
+// renders them in the Prometheus text exposition format.
+// Collection is always on (atomic counters are cheap); the HTTP endpoint that
+// exposes them is optional and started by the app when enabled in settings.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// CONSTANTS
+// =============================================================================
+
+// Process kinds used as the "kind" label on process metrics.
+const (
+	ProcessSend    = "send"    // Interactive message send (stream-json stdin)
+	ProcessNew     = "new"     // New session bootstrap
+	ProcessSlash   = "slash"   // Slash command passthrough
+	ProcessQuery   = "query"   // AgentQuery / SelfQuery (--print)
+	ProcessOneShot = "oneshot" // Any other short-lived helper invocation
+)
+
+// toolDurationBuckets are the histogram upper bounds (seconds) for MCP tool latency.
+// AgentQuery calls routinely take minutes, so the tail goes well past the usual defaults.
+var toolDurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600}
+
+// processDurationBuckets are the histogram upper bounds (seconds) for Claude CLI
+// process lifetimes. A send lives for the whole turn, which can run for an hour.
+var processDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600}
+
+// =============================================================================
+// REGISTRY
+// =============================================================================
+
+// GaugeFunc samples a gauge value at scrape time.
+type GaugeFunc func() float64
+
+type gaugeFuncEntry struct {
+	help string
+	fn   GaugeFunc
+}
+
+// histogram accumulates observations against fixed upper bounds.
+type histogram struct {
+	bounds  []float64
+	count   int64
+	sum     float64
+	buckets []int64 // cumulative counts per bounds entry
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, buckets: make([]int64, len(bounds))}
+}
+
+func (h *histogram) observe(secs float64) {
+	h.count++
+	h.sum += secs
+	for i, le := range h.bounds {
+		if secs <= le {
+			h.buckets[i]++
+		}
+	}
+}
+
+// write renders the histogram's series; label is the label pair identifying it (e.g. `tool="x"`).
+func (h *histogram) write(b *strings.Builder, name, label string) {
+	for i, le := range h.bounds {
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"%g\"} %d\n", name, label, le, h.buckets[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, label, h.count)
+	fmt.Fprintf(b, "%s_sum{%s} %g\n", name, label, h.sum)
+	fmt.Fprintf(b, "%s_count{%s} %d\n", name, label, h.count)
+}
+
+// toolStats accumulates call counts and latency for a single MCP tool.
+type toolStats struct {
+	success  int64
+	errors   int64
+	duration *histogram
+}
+
+var (
+	messagesParsed atomic.Int64
+	rateLimitHits  atomic.Int64
+
+	procMu       sync.Mutex
+	procSpawned  = make(map[string]int64)      // kind -> total spawned
+	procRunning  = make(map[string]int64)      // kind -> currently running
+	procDuration = make(map[string]*histogram) // kind -> lifetime
+	countersMu   sync.Mutex
+	messagesSent = make(map[string]int64) // agent slug -> messages sent
+	watchEvents  = make(map[string]int64) // fsnotify op -> events received
+	busEvents    = make(map[string]int64) // event bus source -> events published
+	toolMu       sync.Mutex
+	tools        = make(map[string]*toolStats) // tool name -> stats
+	gaugeMu      sync.RWMutex
+	gaugeFuncs   = make(map[string]gaugeFuncEntry) // metric name -> sampler
+	startTime    = time.Now()
+)
+
+// AddMessagesParsed records n JSONL lines successfully parsed into messages.
+func AddMessagesParsed(n int) {
+	messagesParsed.Add(int64(n))
+}
+
+// MessageSent records a user message sent to an agent (by slug).
+func MessageSent(agent string) {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	messagesSent[agent]++
+}
+
+// WatcherEvent records a file system event received by the session watcher.
+// op is the event kind ("write", "create", "remove").
+func WatcherEvent(op string) {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	watchEvents[op]++
+}
+
+// EventPublished records an event published on the event bus by source
+// ("runtime", "mcp", ...).
+func EventPublished(source string) {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	busEvents[source]++
+}
+
+// RateLimitHit records a Claude run that failed with a 429 or overloaded error.
+func RateLimitHit() {
+	rateLimitHits.Add(1)
+}
+
+// ProcessStarted records a spawned Claude CLI process of the given kind and
+// returns its start time. Every call must be paired with ProcessExited once
+// the process is reaped.
+func ProcessStarted(kind string) time.Time {
+	procMu.Lock()
+	defer procMu.Unlock()
+	procSpawned[kind]++
+	procRunning[kind]++
+	return time.Now()
+}
+
+// ProcessExited records that a process of the given kind, started at started, has exited.
+func ProcessExited(kind string, started time.Time) {
+	procMu.Lock()
+	defer procMu.Unlock()
+	if procRunning[kind] > 0 {
+		procRunning[kind]--
+	}
+	h, ok := procDuration[kind]
+	if !ok {
+		h = newHistogram(processDurationBuckets)
+		procDuration[kind] = h
+	}
+	h.observe(time.Since(started).Seconds())
+}
+
+// ObserveToolCall records one MCP tool invocation and its latency.
+func ObserveToolCall(tool string, d time.Duration, failed bool) {
+	toolMu.Lock()
+	defer toolMu.Unlock()
+
+	ts, ok := tools[tool]
+	if !ok {
+		ts = &toolStats{duration: newHistogram(toolDurationBuckets)}
+		tools[tool] = ts
+	}
+	if failed {
+		ts.errors++
+	} else {
+		ts.success++
+	}
+	ts.duration.observe(d.Seconds())
+}
+
+// RegisterGauge registers (or replaces) a gauge sampled at scrape time.
+// name must be a full metric name, e.g. "claudefu_active_sessions".
+func RegisterGauge(name, help string, fn GaugeFunc) {
+	gaugeMu.Lock()
+	defer gaugeMu.Unlock()
+	gaugeFuncs[name] = gaugeFuncEntry{help: help, fn: fn}
+}
+
+// =============================================================================
+// EXPOSITION
+// =============================================================================
+
+// WriteText writes all metrics to w in the Prometheus text format (version 0.0.4).
+func WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	writeHeader(&b, "claudefu_uptime_seconds", "gauge", "Seconds since the ClaudeFu process started.")
+	fmt.Fprintf(&b, "claudefu_uptime_seconds %g\n", time.Since(startTime).Seconds())
+
+	writeHeader(&b, "claudefu_messages_parsed_total", "counter", "JSONL lines parsed into session messages.")
+	fmt.Fprintf(&b, "claudefu_messages_parsed_total %d\n", messagesParsed.Load())
+
+	writeHeader(&b, "claudefu_rate_limit_hits_total", "counter", "Claude runs that failed with a rate-limit or overloaded error.")
+	fmt.Fprintf(&b, "claudefu_rate_limit_hits_total %d\n", rateLimitHits.Load())
+
+	// Per-agent sends, watcher and bus events
+	countersMu.Lock()
+	writeHeader(&b, "claudefu_messages_sent_total", "counter", "User messages sent, by agent.")
+	for _, agent := range sortedKeys(messagesSent) {
+		fmt.Fprintf(&b, "claudefu_messages_sent_total{agent=%q} %d\n", agent, messagesSent[agent])
+	}
+	writeHeader(&b, "claudefu_watcher_events_total", "counter", "File system events received by the session watcher, by op.")
+	for _, op := range sortedKeys(watchEvents) {
+		fmt.Fprintf(&b, "claudefu_watcher_events_total{op=%q} %d\n", op, watchEvents[op])
+	}
+	writeHeader(&b, "claudefu_events_published_total", "counter", "Events published on the internal event bus, by source.")
+	for _, source := range sortedKeys(busEvents) {
+		fmt.Fprintf(&b, "claudefu_events_published_total{source=%q} %d\n", source, busEvents[source])
+	}
+	countersMu.Unlock()
+
+	// Scrape-time gauges (active sessions, loaded sessions, ...)
+	gaugeMu.RLock()
+	names := make([]string, 0, len(gaugeFuncs))
+	for name := range gaugeFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := gaugeFuncs[name]
+		writeHeader(&b, name, "gauge", g.help)
+		fmt.Fprintf(&b, "%s %g\n", name, g.fn())
+	}
+	gaugeMu.RUnlock()
+
+	// Process counters
+	procMu.Lock()
+	kinds := sortedKeys(procSpawned)
+	writeHeader(&b, "claudefu_processes_spawned_total", "counter", "Claude CLI processes spawned, by kind.")
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "claudefu_processes_spawned_total{kind=%q} %d\n", kind, procSpawned[kind])
+	}
+	writeHeader(&b, "claudefu_processes_running", "gauge", "Claude CLI processes currently running, by kind.")
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "claudefu_processes_running{kind=%q} %d\n", kind, procRunning[kind])
+	}
+	writeHeader(&b, "claudefu_process_duration_seconds", "histogram", "Claude CLI process lifetime in seconds, by kind.")
+	for _, kind := range kinds {
+		if h, ok := procDuration[kind]; ok {
+			h.write(&b, "claudefu_process_duration_seconds", fmt.Sprintf("kind=%q", kind))
+		}
+	}
+	procMu.Unlock()
+
+	// MCP tool calls
+	toolMu.Lock()
+	toolNames := make([]string, 0, len(tools))
+	for name := range tools {
+		toolNames = append(toolNames, name)
+	}
+	sort.Strings(toolNames)
+	writeHeader(&b, "claudefu_mcp_tool_calls_total", "counter", "MCP tool calls handled, by tool and outcome.")
+	for _, name := range toolNames {
+		ts := tools[name]
+		fmt.Fprintf(&b, "claudefu_mcp_tool_calls_total{tool=%q,status=\"ok\"} %d\n", name, ts.success)
+		fmt.Fprintf(&b, "claudefu_mcp_tool_calls_total{tool=%q,status=\"error\"} %d\n", name, ts.errors)
+	}
+	writeHeader(&b, "claudefu_mcp_tool_call_duration_seconds", "histogram", "MCP tool call latency in seconds.")
+	for _, name := range toolNames {
+		tools[name].duration.write(&b, "claudefu_mcp_tool_call_duration_seconds", fmt.Sprintf("tool=%q", name))
+	}
+	toolMu.Unlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"claudefu/internal/logging"
+)
+
+var logger = logging.For(logging.App)
+
+// DefaultPort is the default port for the /metrics endpoint.
+const DefaultPort = 9360
+
+// DefaultHost keeps the endpoint local unless a host is configured.
+const DefaultHost = "127.0.0.1"
+
+// Service serves the collected metrics on http://{host}:{port}/metrics.
+type Service struct {
+	host    string
+	port    int
+	server  *http.Server
+	done    chan struct{} // closed when server is fully stopped
+	mu      sync.RWMutex
+	running bool
+}
+
+// NewService creates a new metrics endpoint service. An empty host binds
+// DefaultHost; use "0.0.0.0" to let other machines scrape it.
+func NewService(host string, port int) *Service {
+	if host == "" {
+		host = DefaultHost
+	}
+	if port == 0 {
+		port = DefaultPort
+	}
+	return &Service{host: host, port: port}
+}
+
+// Start starts the metrics HTTP server
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WriteText(w); err != nil {
+			logger.Warnf("metrics: write error: %v", err)
+		}
+	})
+
+	s.server = &http.Server{
+		Addr:    net.JoinHostPort(s.host, strconv.Itoa(s.port)),
+		Handler: mux,
+	}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("metrics address %s unavailable: %w", s.server.Addr, err)
+	}
+
+	s.done = make(chan struct{})
+	server, done := s.server, s.done
+	go func() {
+		defer close(done)
+		logger.Infof("metrics: serving /metrics on %s", server.Addr)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("metrics: server error: %v", err)
+		}
+	}()
+
+	s.running = true
+	return nil
+}
+
+// Stop stops the metrics server and waits for the port to be released.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	server, done := s.server, s.done
+	s.running = false
+	s.mu.Unlock()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+	<-done
+	logger.Infof("metrics: endpoint stopped")
+}
+
+// Restart stops the server and starts it again on the given host and port.
+func (s *Service) Restart(host string, port int) error {
+	s.Stop()
+	if host == "" {
+		host = DefaultHost
+	}
+	if port == 0 {
+		port = DefaultPort
+	}
+	s.mu.Lock()
+	s.host, s.port = host, port
+	s.mu.Unlock()
+	return s.Start()
+}
+
+// IsRunning returns whether the metrics endpoint is being served
+func (s *Service) IsRunning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.running
+}
+
+// GetHost returns the configured bind host
+func (s *Service) GetHost() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.host
+}
+
+// GetPort returns the configured port
+func (s *Service) GetPort() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.port
+}
@@ -0,0 +1,239 @@
+// Package monitor watches workspaces other than the active one for new session
+// activity, cheaply: it only stats the agents' session files, so a workspace
+// can show an unread badge when one of its agents finished a turn.
+package monitor
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/claudehome"
+)
+
+// DefaultScanInterval is how often Start re-checks every workspace.
+const DefaultScanInterval = 60 * time.Second
+
+// Agent is an agent folder to check.
+type Agent struct {
+	ID     string
+	Folder string
+}
+
+// Workspace is a workspace to check.
+type Workspace struct {
+	ID     string
+	Name   string
+	Agents []Agent
+}
+
+// WorkspaceUnread is a workspace's sessions with activity since they were last viewed.
+type WorkspaceUnread struct {
+	WorkspaceID    string         `json:"workspaceId"`
+	Name           string         `json:"name"`
+	UnreadSessions int            `json:"unreadSessions"`
+	Agents         map[string]int `json:"agents"` // agentID -> unread sessions (only agents with any)
+	LastActivity   time.Time      `json:"lastActivity,omitzero"`
+	ScannedAt      time.Time      `json:"scannedAt"`
+}
+
+func (u *WorkspaceUnread) equal(other *WorkspaceUnread) bool {
+	return other != nil && u.UnreadSessions == other.UnreadSessions &&
+		u.LastActivity.Equal(other.LastActivity) && maps.Equal(u.Agents, other.Agents)
+}
+
+// UnreadScanner periodically counts, per workspace, the sessions whose file
+// changed after the session was last viewed. Sessions never viewed count from
+// when the workspace was first scanned, so old history isn't reported as
+// unread. Safe for concurrent use.
+type UnreadScanner struct {
+	mu       sync.Mutex
+	cache    map[string]*WorkspaceUnread
+	baseline map[string]time.Time // workspaceID -> first scan
+
+	workspaces func() []Workspace
+	lastViewed func(folder string) map[string]int64 // sessionID -> Unix ms
+	onChange   func(WorkspaceUnread)
+
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewUnreadScanner creates a scanner. workspaces returns the workspaces to
+// check on each scan, lastViewed a folder's last viewed times, and onChange
+// is called (from a background goroutine) when a workspace's counts change.
+func NewUnreadScanner(workspaces func() []Workspace, lastViewed func(folder string) map[string]int64, onChange func(WorkspaceUnread)) *UnreadScanner {
+	return &UnreadScanner{
+		cache:      make(map[string]*WorkspaceUnread),
+		baseline:   make(map[string]time.Time),
+		workspaces: workspaces,
+		lastViewed: lastViewed,
+		onChange:   onChange,
+	}
+}
+
+// Get returns the latest counts of every scanned workspace.
+func (s *UnreadScanner) Get() []WorkspaceUnread {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]WorkspaceUnread, 0, len(s.cache))
+	for _, u := range s.cache {
+		result = append(result, *u)
+	}
+	return result
+}
+
+// Scan checks every workspace now, drops workspaces no longer returned, and
+// reports changed counts.
+func (s *UnreadScanner) Scan() {
+	workspaces := s.workspaces()
+	keep := make(map[string]bool, len(workspaces))
+	for _, ws := range workspaces {
+		keep[ws.ID] = true
+		s.scanWorkspace(ws)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.cache {
+		if !keep[id] {
+			delete(s.cache, id)
+			delete(s.baseline, id)
+		}
+	}
+}
+
+// Forget drops a workspace's cached counts (e.g. when it becomes the active
+// workspace, whose unread state the runtime tracks instead).
+func (s *UnreadScanner) Forget(workspaceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, workspaceID)
+	delete(s.baseline, workspaceID)
+}
+
+// scanWorkspace counts ws's unread sessions and reports a change.
+func (s *UnreadScanner) scanWorkspace(ws Workspace) {
+	now := time.Now()
+	s.mu.Lock()
+	baseline, ok := s.baseline[ws.ID]
+	if !ok {
+		baseline = now
+		s.baseline[ws.ID] = now
+	}
+	s.mu.Unlock()
+
+	unread := &WorkspaceUnread{
+		WorkspaceID: ws.ID,
+		Name:        ws.Name,
+		Agents:      map[string]int{},
+		ScannedAt:   now,
+	}
+	for _, agent := range ws.Agents {
+		var viewed map[string]int64
+		if s.lastViewed != nil {
+			viewed = s.lastViewed(agent.Folder)
+		}
+		count, last := countUnread(agent.Folder, viewed, baseline)
+		if count > 0 {
+			unread.Agents[agent.ID] = count
+			unread.UnreadSessions += count
+		}
+		if last.After(unread.LastActivity) {
+			unread.LastActivity = last
+		}
+	}
+
+	s.mu.Lock()
+	prev := s.cache[ws.ID]
+	s.cache[ws.ID] = unread
+	s.mu.Unlock()
+
+	if s.onChange != nil && !unread.equal(prev) {
+		s.onChange(*unread)
+	}
+}
+
+// countUnread returns how many of folder's sessions changed after they were
+// last viewed (or after baseline, if never viewed), and the latest change.
+func countUnread(folder string, viewed map[string]int64, baseline time.Time) (int, time.Time) {
+	entries, err := os.ReadDir(claudehome.ProjectDir(folder))
+	if err != nil {
+		return 0, time.Time{}
+	}
+	count := 0
+	var last time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".jsonl" || strings.HasPrefix(name, "agent-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		modTime := info.ModTime()
+		if modTime.After(last) {
+			last = modTime
+		}
+		since := baseline
+		if ms := viewed[strings.TrimSuffix(name, ".jsonl")]; ms > 0 {
+			since = time.UnixMilli(ms)
+		}
+		if modTime.After(since) {
+			count++
+		}
+	}
+	return count, last
+}
+
+// Start scans every interval (0 = DefaultScanInterval), starting immediately.
+func (s *UnreadScanner) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultScanInterval
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.running = true
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.Scan()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops scanning and waits for an in-flight scan to finish.
+func (s *UnreadScanner) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	stop, done := s.stop, s.done
+	s.running = false
+	s.mu.Unlock()
+
+	close(stop)
+	<-done
+}
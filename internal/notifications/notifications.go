@@ -0,0 +1,237 @@
+// Package notifications keeps a persistent history of things that wanted the
+// user's attention: NotifyUser messages, AskUserQuestion prompts and timeouts,
+// and permission requests. Unlike runtime unread counts it survives restarts.
+// Notifications are stored as JSON at {configPath}/notifications.json.
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification kinds
+const (
+	KindNotification      = "notification"       // NotifyUser
+	KindQuestion          = "question"           // AskUserQuestion asked
+	KindQuestionTimeout   = "question_timeout"   // AskUserQuestion went unanswered
+	KindPermissionRequest = "permission_request" // RequestToolPermission
+)
+
+// maxNotifications bounds the history; the oldest are dropped first.
+const maxNotifications = 1000
+
+// Notification is one entry in the notification center.
+type Notification struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Level       string    `json:"level,omitempty"` // info, success, warning, question
+	Title       string    `json:"title,omitempty"`
+	Message     string    `json:"message"`
+	AgentSlug   string    `json:"agentSlug,omitempty"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	SessionID   string    `json:"sessionId,omitempty"`
+	RefID       string    `json:"refId,omitempty"` // Question or permission request ID
+	CreatedAt   time.Time `json:"createdAt"`
+	Read        bool      `json:"read"`
+}
+
+// Filter selects a page of notifications. Zero values match everything.
+type Filter struct {
+	Kinds       []string `json:"kinds,omitempty"`
+	AgentSlug   string   `json:"agentSlug,omitempty"`
+	WorkspaceID string   `json:"workspaceId,omitempty"`
+	UnreadOnly  bool     `json:"unreadOnly,omitempty"`
+	Offset      int      `json:"offset,omitempty"`
+	Limit       int      `json:"limit,omitempty"` // 0 = 50
+}
+
+// Page is a filtered, newest-first slice of notifications.
+type Page struct {
+	Items  []Notification `json:"items"`
+	Total  int            `json:"total"`  // Matching the filter
+	Unread int            `json:"unread"` // Unread overall (badge count)
+}
+
+type notificationsFile struct {
+	Version       int            `json:"version"`
+	Notifications []Notification `json:"notifications"`
+}
+
+const notificationsFileVersion = 1
+
+// Center stores notifications. Safe for concurrent use.
+type Center struct {
+	path  string         // ~/.claudefu/notifications.json
+	items []Notification // Oldest first
+	mu    sync.Mutex
+}
+
+// New loads {configPath}/notifications.json.
+func New(configPath string) (*Center, error) {
+	c := &Center{path: filepath.Join(configPath, "notifications.json")}
+	data, err := os.ReadFile(c.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read notifications: %w", err)
+	}
+	if err == nil {
+		var file notificationsFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse notifications: %w", err)
+		}
+		c.items = file.Notifications
+	}
+	return c, nil
+}
+
+// Add records a notification and returns it with ID and timestamp filled in.
+func (c *Center) Add(n Notification) (Notification, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n.ID = uuid.New().String()
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	items := append(slices.Clone(c.items), n)
+	if len(items) > maxNotifications {
+		items = items[len(items)-maxNotifications:]
+	}
+	return n, c.save(items)
+}
+
+// List returns the page of notifications matching f, newest first.
+func (c *Center) List(f Filter) Page {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	page := Page{Items: []Notification{}, Unread: c.unreadLocked()}
+	for i := len(c.items) - 1; i >= 0; i-- {
+		n := c.items[i]
+		if !f.matches(n) {
+			continue
+		}
+		if page.Total >= f.Offset && len(page.Items) < limit {
+			page.Items = append(page.Items, n)
+		}
+		page.Total++
+	}
+	return page
+}
+
+func (f Filter) matches(n Notification) bool {
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, n.Kind) {
+		return false
+	}
+	if f.AgentSlug != "" && n.AgentSlug != f.AgentSlug {
+		return false
+	}
+	if f.WorkspaceID != "" && n.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	return !f.UnreadOnly || !n.Read
+}
+
+// MarkRead marks notifications read by ID (all if ids is empty) and returns how
+// many changed.
+func (c *Center) MarkRead(ids []string) (int, error) {
+	return c.markRead(func(n Notification) bool {
+		return len(ids) == 0 || slices.Contains(ids, n.ID)
+	})
+}
+
+// MarkRefRead marks the notifications of a kind about a question or permission
+// request read, e.g. the question notification once it has been answered.
+func (c *Center) MarkRefRead(refID, kind string) (int, error) {
+	if refID == "" {
+		return 0, nil
+	}
+	return c.markRead(func(n Notification) bool { return n.RefID == refID && n.Kind == kind })
+}
+
+func (c *Center) markRead(match func(Notification) bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := slices.Clone(c.items)
+	changed := 0
+	for i := range items {
+		if !items[i].Read && match(items[i]) {
+			items[i].Read = true
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, c.save(items)
+}
+
+// Clear deletes all notifications (only read ones if readOnly) and returns how
+// many were removed.
+func (c *Center) Clear(readOnly bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kept := []Notification{}
+	if readOnly {
+		for _, n := range c.items {
+			if !n.Read {
+				kept = append(kept, n)
+			}
+		}
+	}
+	removed := len(c.items) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, c.save(kept)
+}
+
+// UnreadCount returns the number of unread notifications.
+func (c *Center) UnreadCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unreadLocked()
+}
+
+func (c *Center) unreadLocked() int {
+	count := 0
+	for _, n := range c.items {
+		if !n.Read {
+			count++
+		}
+	}
+	return count
+}
+
+// save writes items to disk and makes them current. Caller must hold c.mu.
+func (c *Center) save(items []Notification) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(notificationsFile{Version: notificationsFileVersion, Notifications: items}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notifications: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write notifications: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write notifications: %w", err)
+	}
+	c.items = items
+	return nil
+}
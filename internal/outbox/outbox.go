@@ -0,0 +1,201 @@
+// Package outbox records in-flight sends so a turn lost to an app quit can be
+// found and re-dispatched on the next start. Entries are persisted as JSON at
+// {configPath}/outbox.json and removed once the Claude process exits.
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/types"
+)
+
+// Entry is a message handed to the Claude CLI whose turn has not finished.
+type Entry struct {
+	ID             string    `json:"id"`
+	WorkspaceID    string    `json:"workspaceId"`
+	AgentID        string    `json:"agentId"`
+	Folder         string    `json:"folder"`
+	SessionID      string    `json:"sessionId"`
+	Context        string    `json:"context,omitempty"` // Context block sent ahead of Message
+	Message        string    `json:"message"`
+	PlanMode       bool      `json:"planMode,omitempty"`
+	Model          string    `json:"model,omitempty"`
+	Effort         string    `json:"effort,omitempty"`
+	HadAttachments bool      `json:"hadAttachments,omitempty"` // Attachments are not persisted
+	SentAt         time.Time `json:"sentAt"`
+	Interrupted    bool      `json:"interrupted,omitempty"` // Left over from a previous run
+}
+
+// outboxFile is the on-disk format.
+type outboxFile struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+const outboxFileVersion = 1
+
+// Outbox persists in-flight sends. Safe for concurrent use.
+type Outbox struct {
+	path    string // ~/.claudefu/outbox.json
+	entries []Entry
+	mu      sync.Mutex
+}
+
+// New loads {configPath}/outbox.json. Entries found there belong to a previous
+// run and are marked Interrupted.
+func New(configPath string) (*Outbox, error) {
+	o := &Outbox{path: filepath.Join(configPath, "outbox.json")}
+	data, err := os.ReadFile(o.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	if err == nil {
+		var file outboxFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse outbox: %w", err)
+		}
+		o.entries = file.Entries
+	}
+	for i := range o.entries {
+		o.entries[i].Interrupted = true
+	}
+	return o, nil
+}
+
+// Add records a send that is about to start and returns its entry ID.
+func (o *Outbox) Add(e Entry) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e.ID = uuid.New().String()
+	e.Interrupted = false
+	if e.SentAt.IsZero() {
+		e.SentAt = time.Now()
+	}
+	if err := o.save(append(slices.Clone(o.entries), e)); err != nil {
+		return "", err
+	}
+	return e.ID, nil
+}
+
+// Remove drops an entry (its turn finished, was re-sent, or was dismissed).
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := slices.IndexFunc(o.entries, func(e Entry) bool { return e.ID == id })
+	if i < 0 {
+		return fmt.Errorf("outbox entry not found: %s", id)
+	}
+	return o.save(slices.Delete(slices.Clone(o.entries), i, i+1))
+}
+
+// Get returns an entry by ID.
+func (o *Outbox) Get(id string) (*Entry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := slices.IndexFunc(o.entries, func(e Entry) bool { return e.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("outbox entry not found: %s", id)
+	}
+	e := o.entries[i]
+	return &e, nil
+}
+
+// Interrupted returns entries left over from a previous run for a workspace
+// (all workspaces if workspaceID is empty), oldest first.
+func (o *Outbox) Interrupted(workspaceID string) []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := []Entry{}
+	for _, e := range o.entries {
+		if e.Interrupted && (workspaceID == "" || e.WorkspaceID == workspaceID) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// PruneCompleted removes interrupted entries whose turn actually finished
+// (Claude wrote an assistant reply after the send) and returns how many it removed.
+func (o *Outbox) PruneCompleted() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	kept := make([]Entry, 0, len(o.entries))
+	for _, e := range o.entries {
+		if e.Interrupted && TurnCompleted(e.Folder, e.SessionID, e.SentAt) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	removed := len(o.entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, o.save(kept)
+}
+
+// TurnCompleted reports whether a session's JSONL has an assistant message
+// written after since, i.e. the turn started at since produced a reply.
+func TurnCompleted(folder, sessionID string, since time.Time) bool {
+	f, err := os.Open(claudehome.SessionPath(folder, sessionID))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		classified, err := types.ClassifyJSONLEvent(line)
+		if err != nil {
+			continue
+		}
+		msg := types.ConvertToMessage(classified)
+		if msg == nil || msg.Type != "assistant" || msg.IsSynthetic {
+			continue
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, msg.Timestamp); err == nil && ts.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes entries to disk and makes them current. Caller must hold o.mu.
+func (o *Outbox) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(outboxFile{Version: outboxFileVersion, Entries: entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox: %w", err)
+	}
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	o.entries = entries
+	return nil
+}
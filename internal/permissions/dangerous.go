@@ -0,0 +1,64 @@
+package permissions
+
+import (
+	"slices"
+	"strings"
+)
+
+// dangerousCommands are Bash command prefixes treated as YOLO-tier wherever
+// they are enabled (custom sets, lower tiers, session grants): irreversible
+// or remote operations like force pushes and blanket deletes.
+var dangerousCommands = []string{
+	"rm",
+	"sudo",
+	"git push --force",
+	"git push -f",
+	"git reset --hard",
+	"git clean",
+	"chmod -R",
+	"chown -R",
+	"dd",
+	"mkfs",
+}
+
+// IsDangerousPattern reports whether an --allowedTools pattern auto-approves
+// a dangerous Bash command, e.g. "Bash(git push --force:*)" or "Bash(rm:*)".
+// Wildcard patterns like "Bash(*)" count as blanket Bash.
+func IsDangerousPattern(pattern string) bool {
+	command, ok := strings.CutPrefix(pattern, "Bash(")
+	if !ok {
+		return false
+	}
+	command = strings.TrimSuffix(command, ")")
+	command = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(command, "*"), ":"))
+	if command == "" {
+		return true
+	}
+	for _, prefix := range dangerousCommands {
+		if command == prefix || strings.HasPrefix(command, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// FindDangerous returns the entries of allow that are YOLO-tier: enabled in
+// the YOLO tier of any of perms' sets, or matched by IsDangerousPattern.
+// perms may be nil (only the pattern check applies).
+func FindDangerous(perms *ClaudeFuPermissions, allow []string) []string {
+	yolo := make(map[string]bool)
+	if perms != nil {
+		for _, toolPerm := range perms.ToolPermissions {
+			for _, t := range toolPerm.YOLO {
+				yolo[t] = true
+			}
+		}
+	}
+	var dangerous []string
+	for _, t := range allow {
+		if (yolo[t] || IsDangerousPattern(t)) && !slices.Contains(dangerous, t) {
+			dangerous = append(dangerous, t)
+		}
+	}
+	return dangerous
+}
@@ -0,0 +1,50 @@
+package permissions
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsDangerousPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"Bash(rm:*)", true},
+		{"Bash(rm -rf:*)", true},
+		{"Bash(sudo apt install:*)", true},
+		{"Bash(git push --force:*)", true},
+		{"Bash(git push -f)", true},
+		{"Bash(git reset --hard:*)", true},
+		{"Bash(*)", true},
+		{"Bash()", true},
+		{"Bash", false},
+		{"Bash(rmdir:*)", false},
+		{"Bash(git push:*)", false},
+		{"Bash(go test:*)", false},
+		{"Bash(ddgr:*)", false},
+		{"Read", false},
+		{"mcp__claudefu__AgentQuery", false},
+	}
+	for _, tt := range tests {
+		if got := IsDangerousPattern(tt.pattern); got != tt.want {
+			t.Errorf("IsDangerousPattern(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFindDangerous(t *testing.T) {
+	perms := &ClaudeFuPermissions{
+		ToolPermissions: map[string]ToolPermission{
+			"deploy": {Common: []string{"Bash(make build:*)"}, YOLO: []string{"Bash(make deploy:*)"}},
+		},
+	}
+	allow := []string{"Read", "Bash(make build:*)", "Bash(make deploy:*)", "Bash(rm:*)", "Bash(rm:*)"}
+
+	if got, want := FindDangerous(perms, allow), []string{"Bash(make deploy:*)", "Bash(rm:*)"}; !slices.Equal(got, want) {
+		t.Errorf("FindDangerous = %v, want %v", got, want)
+	}
+	if got, want := FindDangerous(nil, allow), []string{"Bash(rm:*)"}; !slices.Equal(got, want) {
+		t.Errorf("FindDangerous(nil) = %v, want %v", got, want)
+	}
+}
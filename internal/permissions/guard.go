@@ -0,0 +1,98 @@
+package permissions
+
+import (
+	"slices"
+	"strings"
+)
+
+// Guard restricts what spawned Claude processes may do, on top of the agent's
+// (or global) permission sets. Denied tools are removed from --allowedTools and
+// passed in --disallowedTools, so a runtime grant cannot re-enable them.
+type Guard struct {
+	DenyTiers      []RiskLevel // Tiers denied across every built-in set (e.g. yolo)
+	DenySets       []string    // Built-in set IDs denied at every tier (e.g. "deploy")
+	DenyPatterns   []string    // Extra tool patterns to deny (e.g. "Bash(kubectl apply:*)")
+	ProtectedPaths []string    // Write, Edit, and NotebookEdit are denied under these paths
+}
+
+// pathTools are the file-modifying tools denied under protected paths.
+var pathTools = []string{"Edit", "Write", "NotebookEdit"}
+
+// IsEmpty reports whether the guard restricts nothing.
+func (g *Guard) IsEmpty() bool {
+	return g == nil || (len(g.DenyTiers) == 0 && len(g.DenySets) == 0 &&
+		len(g.DenyPatterns) == 0 && len(g.ProtectedPaths) == 0)
+}
+
+// DenyList returns the tool patterns to pass in --disallowedTools.
+// Blanket "Bash" is never denied (it would disable every Bash pattern);
+// it is only stripped from the allow list.
+func (g *Guard) DenyList() []string {
+	if g.IsEmpty() {
+		return nil
+	}
+	var deny []string
+	add := func(tools []string) {
+		for _, t := range tools {
+			if t != "Bash" && !slices.Contains(deny, t) {
+				deny = append(deny, t)
+			}
+		}
+	}
+
+	for _, id := range GetOrderedSetIDs() {
+		set := GetSetByID(id)
+		if set == nil {
+			continue
+		}
+		if slices.Contains(g.DenySets, id) {
+			add(set.Permissions.Common)
+			add(set.Permissions.Permissive)
+			add(set.Permissions.YOLO)
+			continue
+		}
+		for _, tier := range g.DenyTiers {
+			add(set.tier(tier))
+		}
+	}
+	add(g.DenyPatterns)
+
+	for _, raw := range g.ProtectedPaths {
+		path, err := ExpandPath(raw)
+		if err != nil {
+			continue
+		}
+		rule := strings.TrimSuffix(ToClaudeSettingsPath(path), "/") + "/**"
+		for _, tool := range pathTools {
+			add([]string{tool + "(" + rule + ")"})
+		}
+	}
+	return deny
+}
+
+// FilterAllowList removes denied tools (including blanket Bash when its tier
+// is denied) from an --allowedTools list.
+func (g *Guard) FilterAllowList(allow []string) []string {
+	if g.IsEmpty() {
+		return allow
+	}
+	denied := toSet(g.DenyList())
+	if slices.Contains(g.DenyTiers, RiskYOLO) {
+		denied["Bash"] = true
+	}
+	return filterDenied(allow, denied)
+}
+
+// tier returns a set's tools at exactly one risk level.
+func (s *PermissionSet) tier(level RiskLevel) []string {
+	switch level {
+	case RiskCommon:
+		return s.Permissions.Common
+	case RiskPermissive:
+		return s.Permissions.Permissive
+	case RiskYOLO:
+		return s.Permissions.YOLO
+	default:
+		return nil
+	}
+}
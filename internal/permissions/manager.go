@@ -7,8 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+
+	"claudefu/internal/fsutil"
+	"claudefu/internal/logging"
 )
 
+var logger = logging.For(logging.App)
+
 const (
 	// ClaudeFu's own permission files (not Claude's settings.local.json)
 	GlobalPermissionsFile = "global.permissions.json"
@@ -247,6 +252,145 @@ func (m *Manager) CopyGlobalToAgent(agentFolder string) error {
 	return m.SaveAgentPermissions(agentFolder, &permsCopy)
 }
 
+// GrantAgentPermissions permanently allows patterns for an agent, as granted by the
+// user through RequestToolPermission. Patterns are added to the custom set's
+// permissive tier; ones that belong to a built-in set are then moved there by
+// MigrateCustomToBuiltIn. An agent without its own permissions file gets a copy of
+// global first. Returns the patterns that were not already enabled.
+func (m *Manager) GrantAgentPermissions(agentFolder string, patterns []string) ([]string, error) {
+	perms, err := m.loadAgentOrCopyGlobal(agentFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := toSet(m.collectAllTools(perms))
+	custom := perms.ToolPermissions["custom"]
+	var added []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || enabled[p] {
+			continue
+		}
+		enabled[p] = true
+		custom.Permissive = append(custom.Permissive, p)
+		added = append(added, p)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if custom.Common == nil {
+		custom.Common = []string{}
+	}
+	if custom.YOLO == nil {
+		custom.YOLO = []string{}
+	}
+	perms.ToolPermissions["custom"] = custom
+	MigrateCustomToBuiltIn(perms)
+
+	if err := m.SaveAgentPermissions(agentFolder, perms); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// RevokeAgentPermissions disables patterns in every tier of an agent's permission
+// sets (copying global first, like GrantAgentPermissions). Returns the patterns
+// that were enabled.
+func (m *Manager) RevokeAgentPermissions(agentFolder string, patterns []string) ([]string, error) {
+	perms, err := m.loadAgentOrCopyGlobal(agentFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	revoke := toSet(patterns)
+	var removed []string
+	drop := func(tier []string) []string {
+		kept := []string{}
+		for _, t := range tier {
+			if !revoke[t] {
+				kept = append(kept, t)
+			} else if !containsString(removed, t) {
+				removed = append(removed, t)
+			}
+		}
+		return kept
+	}
+	for id, tp := range perms.ToolPermissions {
+		tp.Common = drop(tp.Common)
+		tp.Permissive = drop(tp.Permissive)
+		tp.YOLO = drop(tp.YOLO)
+		perms.ToolPermissions[id] = tp
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	if err := m.SaveAgentPermissions(agentFolder, perms); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// UpdateAgentDirectories adds and removes agent-specific additional directories
+// (in any form NormalizePath accepts). Global directories cannot be removed here.
+func (m *Manager) UpdateAgentDirectories(agentFolder string, add, remove []string) error {
+	global, err := m.LoadGlobalPermissions()
+	if err != nil {
+		return err
+	}
+	for _, d := range NormalizeDirectories(remove) {
+		if containsString(global.AdditionalDirectories, d) {
+			return fmt.Errorf("%s is a global directory; remove it from global permissions", d)
+		}
+	}
+
+	perms, err := m.loadAgentOrCopyGlobal(agentFolder)
+	if err != nil {
+		return err
+	}
+	removeSet := toSet(NormalizeDirectories(remove))
+	dirs := []string{}
+	for _, d := range NormalizeDirectories(perms.AdditionalDirectories) {
+		if !removeSet[d] {
+			dirs = append(dirs, d)
+		}
+	}
+	for _, d := range NormalizeDirectories(add) {
+		if !containsString(dirs, d) && !containsString(global.AdditionalDirectories, d) {
+			dirs = append(dirs, d)
+		}
+	}
+	perms.AdditionalDirectories = dirs
+	return m.SaveAgentPermissions(agentFolder, perms)
+}
+
+// loadAgentOrCopyGlobal returns an agent's permissions for modification, or a
+// copy of global (without its directories) if the agent has no file yet.
+func (m *Manager) loadAgentOrCopyGlobal(agentFolder string) (*ClaudeFuPermissions, error) {
+	perms, err := m.LoadAgentPermissions(agentFolder)
+	if err != nil {
+		return nil, err
+	}
+	if perms == nil {
+		global, err := m.LoadGlobalPermissions()
+		if err != nil {
+			return nil, err
+		}
+		copied := *global
+		copied.InheritFromGlobal = false
+		copied.AdditionalDirectories = []string{}
+		copied.ToolPermissions = make(map[string]ToolPermission, len(global.ToolPermissions))
+		for id, tp := range global.ToolPermissions {
+			copied.ToolPermissions[id] = tp
+		}
+		perms = &copied
+	}
+	if perms.ToolPermissions == nil {
+		perms.ToolPermissions = make(map[string]ToolPermission)
+	}
+	return perms, nil
+}
+
 // RevertAgentToGlobal resets agent permissions to match global template (tools only)
 // Deprecated: Use RevertToolsToGlobal for clarity
 func (m *Manager) RevertAgentToGlobal(agentFolder string) error {
@@ -372,11 +516,20 @@ func (m *Manager) agentPermissionsPath(agentFolder string) string {
 // readPermissionsFile reads and parses a permissions JSON file
 // Handles both v1 (level-based) and v2 (explicit arrays) formats
 func (m *Manager) readPermissionsFile(path string) (*ClaudeFuPermissions, error) {
-	data, err := os.ReadFile(path)
+	var perms *ClaudeFuPermissions
+	err := fsutil.ReadRecover(path, func(data []byte) error {
+		var err error
+		perms, err = m.parsePermissions(data)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return perms, nil
+}
 
+// parsePermissions parses the content of a permissions file.
+func (m *Manager) parsePermissions(data []byte) (*ClaudeFuPermissions, error) {
 	// First, detect version
 	var versionCheck struct {
 		Version int `json:"version"`
@@ -837,7 +990,7 @@ func MigrateCustomToBuiltIn(perms *ClaudeFuPermissions) {
 			perms.ToolPermissions[loc.setID] = setPerm
 			migrated++
 
-			fmt.Printf("[PERMS] Migrated %q from custom.%s → %s.%s\n", entry, customTierName, loc.setID, loc.tier)
+			logger.Infof("Migrated %q from custom.%s → %s.%s", entry, customTierName, loc.setID, loc.tier)
 		}
 		if remaining == nil {
 			remaining = []string{}
@@ -852,7 +1005,7 @@ func MigrateCustomToBuiltIn(perms *ClaudeFuPermissions) {
 	perms.ToolPermissions["custom"] = customPerm
 
 	if migrated > 0 {
-		fmt.Printf("[PERMS] MigrateCustomToBuiltIn: moved %d entries from custom to built-in sets\n", migrated)
+		logger.Infof("MigrateCustomToBuiltIn: moved %d entries from custom to built-in sets", migrated)
 	}
 }
 
@@ -873,5 +1026,5 @@ func (m *Manager) writePermissionsFile(path string, perms *ClaudeFuPermissions)
 		return err
 	}
 
-	return os.WriteFile(path, data, 0600)
+	return fsutil.WriteFileWithBackup(path, data, 0600)
 }
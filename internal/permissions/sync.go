@@ -0,0 +1,204 @@
+package permissions
+
+import (
+	"fmt"
+	"slices"
+)
+
+// =============================================================================
+// SETTINGS.LOCAL.JSON RECONCILIATION
+// ClaudeFu's compiled allow list and directories are diffed against Claude's
+// settings.local.json. Each difference is reported as an entry the user
+// resolves in one direction: make settings.local.json match ClaudeFu, or make
+// ClaudeFu match settings.local.json.
+// =============================================================================
+
+// Sync entry kinds
+const (
+	SyncKindTool      = "tool"
+	SyncKindDirectory = "directory"
+)
+
+// Sync entry statuses
+const (
+	SyncClaudeFuOnly = "claudefu_only" // Enabled in ClaudeFu, missing from settings.local.json
+	SyncClaudeOnly   = "claude_only"   // In settings.local.json, not enabled in ClaudeFu
+	SyncDenyConflict = "deny_conflict" // In settings.local.json's deny list, yet allowed on either side
+)
+
+// Sync directions
+const (
+	SyncToClaude   = "to_claude"   // Make settings.local.json match ClaudeFu
+	SyncToClaudeFu = "to_claudefu" // Make ClaudeFu match settings.local.json
+)
+
+// SyncEntry is one pattern or directory that differs between the two sides.
+type SyncEntry struct {
+	Kind          string `json:"kind"`    // tool, directory
+	Pattern       string `json:"pattern"` // Tool pattern, or directory in ClaudeFu's stored form (~/svml)
+	Status        string `json:"status"`
+	InClaudeFu    bool   `json:"inClaudeFu"`
+	InClaudeAllow bool   `json:"inClaudeAllow"` // For directories: in additionalDirectories
+	InClaudeDeny  bool   `json:"inClaudeDeny"`
+}
+
+// SyncReport lists every difference between ClaudeFu and settings.local.json.
+type SyncReport struct {
+	Entries []SyncEntry `json:"entries"`
+	InSync  bool        `json:"inSync"`
+}
+
+// HasClaudeOnly reports whether settings.local.json holds entries that a
+// one-way sync from ClaudeFu would drop (claude-only or deny conflicts).
+func (r *SyncReport) HasClaudeOnly() bool {
+	return slices.ContainsFunc(r.Entries, func(e SyncEntry) bool {
+		return e.Status == SyncClaudeOnly || e.Status == SyncDenyConflict
+	})
+}
+
+// SyncResolution picks a direction for one entry of a SyncReport.
+type SyncResolution struct {
+	Kind      string `json:"kind"`
+	Pattern   string `json:"pattern"`
+	Direction string `json:"direction"` // to_claude, to_claudefu
+}
+
+// SyncPlan is what a set of resolutions changes on each side.
+type SyncPlan struct {
+	ClaudeAllow   []string // Resulting settings.local.json lists
+	ClaudeDeny    []string
+	ClaudeDirs    []string
+	ClaudeChanged bool
+	GrantTools    []string // ClaudeFu changes
+	RevokeTools   []string
+	AddDirs       []string
+	RemoveDirs    []string
+}
+
+// DiffSettings compares ClaudeFu's compiled allow list and directories with
+// settings.local.json's allow, deny, and additionalDirectories lists.
+// Directories on either side may be in any form NormalizePath accepts.
+func DiffSettings(fuAllow, fuDirs, claudeAllow, claudeDeny, claudeDirs []string) *SyncReport {
+	report := &SyncReport{Entries: []SyncEntry{}}
+	fu, allow, deny := toSet(fuAllow), toSet(claudeAllow), toSet(claudeDeny)
+
+	var patterns []string
+	for _, list := range [][]string{fuAllow, claudeAllow, claudeDeny} {
+		for _, p := range list {
+			if !slices.Contains(patterns, p) {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	for _, p := range patterns {
+		entry := SyncEntry{
+			Kind:          SyncKindTool,
+			Pattern:       p,
+			InClaudeFu:    fu[p],
+			InClaudeAllow: allow[p],
+			InClaudeDeny:  deny[p],
+		}
+		switch {
+		case entry.InClaudeDeny && (entry.InClaudeFu || entry.InClaudeAllow):
+			entry.Status = SyncDenyConflict
+		case entry.InClaudeFu && !entry.InClaudeAllow:
+			entry.Status = SyncClaudeFuOnly
+		case entry.InClaudeAllow && !entry.InClaudeFu:
+			entry.Status = SyncClaudeOnly
+		default:
+			continue // In sync (including deny-only entries)
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	fuDirSet, claudeDirSet := toSet(NormalizeDirectories(fuDirs)), toSet(NormalizeDirectories(claudeDirs))
+	for _, d := range NormalizeDirectories(fuDirs) {
+		if !claudeDirSet[d] {
+			report.Entries = append(report.Entries, SyncEntry{Kind: SyncKindDirectory, Pattern: d, Status: SyncClaudeFuOnly, InClaudeFu: true})
+		}
+	}
+	for _, d := range NormalizeDirectories(claudeDirs) {
+		if !fuDirSet[d] {
+			report.Entries = append(report.Entries, SyncEntry{Kind: SyncKindDirectory, Pattern: d, Status: SyncClaudeOnly, InClaudeAllow: true})
+			fuDirSet[d] = true // Report duplicates once
+		}
+	}
+
+	report.InSync = len(report.Entries) == 0
+	return report
+}
+
+// PlanSync works out the changes resolutions make. Every resolution must name
+// an entry of report; entries without a resolution are left as they are.
+// A deny conflict resolved toward ClaudeFu revokes the pattern in ClaudeFu,
+// since Claude's deny list wins over its allow list.
+func PlanSync(report *SyncReport, claudeAllow, claudeDeny, claudeDirs []string, resolutions []SyncResolution) (*SyncPlan, error) {
+	plan := &SyncPlan{
+		ClaudeAllow: slices.Clone(claudeAllow),
+		ClaudeDeny:  slices.Clone(claudeDeny),
+		ClaudeDirs:  slices.Clone(claudeDirs),
+	}
+	for _, res := range resolutions {
+		i := slices.IndexFunc(report.Entries, func(e SyncEntry) bool {
+			return e.Kind == res.Kind && e.Pattern == res.Pattern
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%s %q is already in sync", res.Kind, res.Pattern)
+		}
+		entry := report.Entries[i]
+
+		switch res.Direction {
+		case SyncToClaude:
+			plan.toClaude(entry)
+		case SyncToClaudeFu:
+			plan.toClaudeFu(entry)
+		default:
+			return nil, fmt.Errorf("invalid sync direction: %s", res.Direction)
+		}
+	}
+	return plan, nil
+}
+
+// toClaude makes settings.local.json match ClaudeFu for entry.
+func (p *SyncPlan) toClaude(entry SyncEntry) {
+	p.ClaudeChanged = true
+	if entry.Kind == SyncKindDirectory {
+		if entry.InClaudeFu {
+			if expanded, err := ExpandPath(entry.Pattern); err == nil {
+				p.ClaudeDirs = append(p.ClaudeDirs, ToClaudeSettingsPath(expanded))
+			}
+			return
+		}
+		p.ClaudeDirs = slices.DeleteFunc(p.ClaudeDirs, func(d string) bool {
+			normalized, err := NormalizePath(d)
+			return err == nil && normalized == entry.Pattern
+		})
+		return
+	}
+
+	remove := func(list []string) []string {
+		return slices.DeleteFunc(list, func(t string) bool { return t == entry.Pattern })
+	}
+	if entry.InClaudeFu {
+		p.ClaudeDeny = remove(p.ClaudeDeny)
+		if !slices.Contains(p.ClaudeAllow, entry.Pattern) {
+			p.ClaudeAllow = append(p.ClaudeAllow, entry.Pattern)
+		}
+		return
+	}
+	p.ClaudeAllow = remove(p.ClaudeAllow)
+}
+
+// toClaudeFu makes ClaudeFu match settings.local.json for entry.
+func (p *SyncPlan) toClaudeFu(entry SyncEntry) {
+	switch {
+	case entry.Kind == SyncKindDirectory && entry.InClaudeFu:
+		p.RemoveDirs = append(p.RemoveDirs, entry.Pattern)
+	case entry.Kind == SyncKindDirectory:
+		p.AddDirs = append(p.AddDirs, entry.Pattern)
+	case entry.InClaudeFu && (entry.InClaudeDeny || !entry.InClaudeAllow):
+		p.RevokeTools = append(p.RevokeTools, entry.Pattern)
+	case !entry.InClaudeFu && entry.InClaudeAllow && !entry.InClaudeDeny:
+		p.GrantTools = append(p.GrantTools, entry.Pattern)
+	}
+}
@@ -0,0 +1,163 @@
+package permissions
+
+import (
+	"slices"
+	"testing"
+)
+
+// entryKeys flattens a report to "kind status pattern" strings.
+func entryKeys(report *SyncReport) []string {
+	var keys []string
+	for _, e := range report.Entries {
+		keys = append(keys, e.Kind+" "+e.Status+" "+e.Pattern)
+	}
+	return keys
+}
+
+func TestDiffSettings(t *testing.T) {
+	tests := []struct {
+		name                                           string
+		fuAllow, fuDirs, claudeAllow, claudeDeny, dirs []string
+		want                                           []string
+	}{
+		{
+			name:    "in sync",
+			fuAllow: []string{"Read"}, claudeAllow: []string{"Read"},
+			fuDirs: []string{"/opt/shared/"}, dirs: []string{"//opt/shared"},
+		},
+		{
+			name:    "deny-only pattern is in sync",
+			fuAllow: []string{"Read"}, claudeAllow: []string{"Read"}, claudeDeny: []string{"Bash(curl:*)"},
+		},
+		{
+			name:    "claudefu only",
+			fuAllow: []string{"Read", "Bash(go test:*)"}, claudeAllow: []string{"Read"},
+			want: []string{"tool claudefu_only Bash(go test:*)"},
+		},
+		{
+			name:    "claude only",
+			fuAllow: []string{"Read"}, claudeAllow: []string{"Read", "WebFetch"},
+			want: []string{"tool claude_only WebFetch"},
+		},
+		{
+			name:    "denied but allowed",
+			fuAllow: []string{"Bash(rm:*)"}, claudeAllow: []string{"Bash(git:*)"}, claudeDeny: []string{"Bash(rm:*)", "Bash(git:*)"},
+			want: []string{"tool deny_conflict Bash(rm:*)", "tool deny_conflict Bash(git:*)"},
+		},
+		{
+			name:   "directories",
+			fuDirs: []string{"/opt/fu", "/opt/both"}, dirs: []string{"//opt/both", "//opt/claude", "/opt/claude/"},
+			want: []string{"directory claudefu_only /opt/fu", "directory claude_only /opt/claude"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := DiffSettings(tt.fuAllow, tt.fuDirs, tt.claudeAllow, tt.claudeDeny, tt.dirs)
+			if got := entryKeys(report); !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+			if report.InSync != (len(tt.want) == 0) {
+				t.Errorf("InSync = %v with %d entries", report.InSync, len(report.Entries))
+			}
+		})
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	claudeAllow := []string{"Read", "WebFetch"}
+	claudeDeny := []string{"Bash(rm:*)", "Bash(curl:*)"}
+	claudeDirs := []string{"//opt/claude"}
+	report := DiffSettings(
+		[]string{"Read", "Bash(go test:*)", "Bash(rm:*)"}, []string{"/opt/fu"},
+		claudeAllow, claudeDeny, claudeDirs,
+	)
+
+	tests := []struct {
+		name      string
+		kind      string
+		pattern   string
+		direction string
+		want      SyncPlan
+		wantErr   bool
+	}{
+		{
+			name: "grant in claude", kind: SyncKindTool, pattern: "Bash(go test:*)", direction: SyncToClaude,
+			want: SyncPlan{ClaudeAllow: []string{"Read", "WebFetch", "Bash(go test:*)"}, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, ClaudeChanged: true},
+		},
+		{
+			name: "revoke in claudefu", kind: SyncKindTool, pattern: "Bash(go test:*)", direction: SyncToClaudeFu,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, RevokeTools: []string{"Bash(go test:*)"}},
+		},
+		{
+			name: "deny conflict toward claude lifts the deny", kind: SyncKindTool, pattern: "Bash(rm:*)", direction: SyncToClaude,
+			want: SyncPlan{ClaudeAllow: []string{"Read", "WebFetch", "Bash(rm:*)"}, ClaudeDeny: []string{"Bash(curl:*)"}, ClaudeDirs: claudeDirs, ClaudeChanged: true},
+		},
+		{
+			name: "deny conflict toward claudefu revokes", kind: SyncKindTool, pattern: "Bash(rm:*)", direction: SyncToClaudeFu,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, RevokeTools: []string{"Bash(rm:*)"}},
+		},
+		{
+			name: "remove from claude", kind: SyncKindTool, pattern: "WebFetch", direction: SyncToClaude,
+			want: SyncPlan{ClaudeAllow: []string{"Read"}, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, ClaudeChanged: true},
+		},
+		{
+			name: "grant in claudefu", kind: SyncKindTool, pattern: "WebFetch", direction: SyncToClaudeFu,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, GrantTools: []string{"WebFetch"}},
+		},
+		{
+			name: "add directory to claude", kind: SyncKindDirectory, pattern: "/opt/fu", direction: SyncToClaude,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: []string{"//opt/claude", "//opt/fu"}, ClaudeChanged: true},
+		},
+		{
+			name: "remove directory from claude", kind: SyncKindDirectory, pattern: "/opt/claude", direction: SyncToClaude,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeChanged: true},
+		},
+		{
+			name: "remove directory from claudefu", kind: SyncKindDirectory, pattern: "/opt/fu", direction: SyncToClaudeFu,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, RemoveDirs: []string{"/opt/fu"}},
+		},
+		{
+			name: "add directory to claudefu", kind: SyncKindDirectory, pattern: "/opt/claude", direction: SyncToClaudeFu,
+			want: SyncPlan{ClaudeAllow: claudeAllow, ClaudeDeny: claudeDeny, ClaudeDirs: claudeDirs, AddDirs: []string{"/opt/claude"}},
+		},
+		{name: "already in sync", kind: SyncKindTool, pattern: "Read", direction: SyncToClaude, wantErr: true},
+		{name: "bad direction", kind: SyncKindTool, pattern: "WebFetch", direction: "sideways", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanSync(report, claudeAllow, claudeDeny, claudeDirs, []SyncResolution{{Kind: tt.kind, Pattern: tt.pattern, Direction: tt.direction}})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanSync: %v", err)
+			}
+			lists := []struct {
+				field     string
+				got, want []string
+			}{
+				{"ClaudeAllow", plan.ClaudeAllow, tt.want.ClaudeAllow},
+				{"ClaudeDeny", plan.ClaudeDeny, tt.want.ClaudeDeny},
+				{"ClaudeDirs", plan.ClaudeDirs, tt.want.ClaudeDirs},
+				{"GrantTools", plan.GrantTools, tt.want.GrantTools},
+				{"RevokeTools", plan.RevokeTools, tt.want.RevokeTools},
+				{"AddDirs", plan.AddDirs, tt.want.AddDirs},
+				{"RemoveDirs", plan.RemoveDirs, tt.want.RemoveDirs},
+			}
+			for _, l := range lists {
+				if !slices.Equal(l.got, l.want) {
+					t.Errorf("%s = %q, want %q", l.field, l.got, l.want)
+				}
+			}
+			if plan.ClaudeChanged != tt.want.ClaudeChanged {
+				t.Errorf("ClaudeChanged = %v, want %v", plan.ClaudeChanged, tt.want.ClaudeChanged)
+			}
+			if !slices.Equal(claudeAllow, []string{"Read", "WebFetch"}) {
+				t.Fatalf("PlanSync modified its input: %q", claudeAllow)
+			}
+		})
+	}
+}
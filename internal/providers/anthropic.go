@@ -0,0 +1,263 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"claudefu/internal/claudehome"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicAPIVersion     = "2023-06-01"
+	anthropicDefaultModel   = "claude-sonnet-4-5"
+	anthropicMaxTokens      = 8192
+	anthropicRequestTimeout = 10 * time.Minute
+
+	// anthropicEntryVersion fills the JSONL "version" field (the CLI writes its own version there)
+	anthropicEntryVersion = "claudefu-anthropic"
+)
+
+// anthropicModelAliases maps the CLI's model aliases to API model IDs.
+var anthropicModelAliases = map[string]string{
+	"sonnet": "claude-sonnet-4-5",
+	"opus":   "claude-opus-4-1",
+	"haiku":  "claude-haiku-4-5",
+}
+
+// anthropicBackend calls the Anthropic Messages API directly. Credentials and
+// endpoint come from the agent's environment, the same vars the CLI reads:
+// ANTHROPIC_API_KEY (or ANTHROPIC_AUTH_TOKEN), ANTHROPIC_BASE_URL and ANTHROPIC_MODEL.
+type anthropicBackend struct{}
+
+// NewSession creates a session file and sends req.Message as its first turn.
+func (b *anthropicBackend) NewSession(ctx context.Context, req BackendRequest) (string, error) {
+	req.SessionID = uuid.New().String()
+	if err := os.MkdirAll(filepath.Dir(claudehome.SessionPath(req.Folder, req.SessionID)), 0755); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if _, err := b.Send(ctx, req); err != nil {
+		return "", err
+	}
+	return req.SessionID, nil
+}
+
+// Send replays the session transcript plus the new message to the API and
+// appends the user message, the response and a turn_duration line to the JSONL.
+func (b *anthropicBackend) Send(ctx context.Context, req BackendRequest) (string, error) {
+	env := req.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	apiKey := lookupEnv(env, "ANTHROPIC_API_KEY")
+	authToken := lookupEnv(env, "ANTHROPIC_AUTH_TOKEN")
+	if apiKey == "" && authToken == "" {
+		return "", fmt.Errorf("anthropic provider requires ANTHROPIC_API_KEY or ANTHROPIC_AUTH_TOKEN in the agent's env")
+	}
+	baseURL := strings.TrimRight(lookupEnv(env, "ANTHROPIC_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	model := resolveAnthropicModel(req.Model, lookupEnv(env, "ANTHROPIC_MODEL"))
+
+	content, err := buildContentBlocks(req.Message, req.Attachments)
+	if err != nil {
+		return "", err
+	}
+
+	path := claudehome.SessionPath(req.Folder, req.SessionID)
+	history, lastUUID, err := readAnthropicTranscript(path)
+	if err != nil {
+		return "", err
+	}
+	messages := append(history, anthropicMessage{Role: "user", Content: content})
+
+	start := time.Now()
+	userUUID := uuid.New().String()
+	if err := appendSessionEntry(path, req, anthropicEntryVersion, lastUUID, userUUID, map[string]any{
+		"type":    "user",
+		"message": map[string]any{"role": "user", "content": content},
+	}); err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model":      model,
+		"max_tokens": anthropicMaxTokens,
+		"messages":   messages,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	logger.Debugf("anthropic: POST %s/v1/messages model=%s session=%s messages=%d", baseURL, model, req.SessionID, len(messages))
+
+	ctx, cancel := context.WithTimeout(ctx, anthropicRequestTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+	if apiKey != "" {
+		httpReq.Header.Set("x-api-key", apiKey)
+	} else {
+		httpReq.Header.Set("authorization", "Bearer "+authToken)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("anthropic request cancelled: %w", ctx.Err())
+		}
+		return "", &SendError{ExitCode: -1, Stderr: err.Error(), Err: err}
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &SendError{
+			ExitCode: -1,
+			Stderr:   fmt.Sprintf("API Error: %d %s", resp.StatusCode, strings.TrimSpace(string(respBody))),
+			Err:      fmt.Errorf("anthropic API returned %s", resp.Status),
+		}
+	}
+
+	var reply struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &reply); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	assistantUUID := uuid.New().String()
+	entry := map[string]any{"type": "assistant", "message": json.RawMessage(respBody)}
+	if requestID := resp.Header.Get("request-id"); requestID != "" {
+		entry["requestId"] = requestID
+	}
+	if err := appendSessionEntry(path, req, anthropicEntryVersion, userUUID, assistantUUID, entry); err != nil {
+		return "", err
+	}
+	if err := appendSessionEntry(path, req, anthropicEntryVersion, assistantUUID, uuid.New().String(), map[string]any{
+		"type":       "system",
+		"subtype":    "turn_duration",
+		"durationMs": time.Since(start).Milliseconds(),
+		"isMeta":     false,
+	}); err != nil {
+		return "", err
+	}
+
+	var text []string
+	for _, block := range reply.Content {
+		if block.Type == "text" {
+			text = append(text, block.Text)
+		}
+	}
+	return strings.Join(text, "\n"), nil
+}
+
+// resolveAnthropicModel maps a CLI-style model (alias, "[1m]" suffix) to an
+// API model ID, falling back to envModel and then the backend default.
+func resolveAnthropicModel(model, envModel string) string {
+	if model == "" {
+		model = envModel
+	}
+	model = strings.TrimSuffix(model, "[1m]")
+	if id, ok := anthropicModelAliases[model]; ok {
+		return id
+	}
+	if model == "" {
+		return anthropicDefaultModel
+	}
+	return model
+}
+
+// anthropicMessage is one entry of a Messages API request.
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []map[string]any `json:"content"`
+}
+
+// readAnthropicTranscript rebuilds the API message history from a session
+// JSONL and returns it with the UUID of the last entry ("" for a new session).
+// Only text, image and document blocks are replayed: tool and thinking blocks
+// need the CLI's tool definitions and signatures. Consecutive messages of the
+// same role are merged so the history alternates.
+func readAnthropicTranscript(path string) ([]anthropicMessage, string, error) {
+	var messages []anthropicMessage
+	lastUUID := ""
+	err := readSessionEntries(path, func(entry sessionEntry) {
+		if entry.UUID != "" {
+			lastUUID = entry.UUID
+		}
+		if (entry.Type == "user" || entry.Type == "assistant") && !entry.IsSidechain {
+			messages = appendTranscriptMessage(messages, entry.Message.Role, entry.Message.Content)
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The API requires the conversation to start with a user turn
+	for len(messages) > 0 && messages[0].Role != "user" {
+		messages = messages[1:]
+	}
+	return messages, lastUUID, nil
+}
+
+// appendTranscriptMessage adds the replayable blocks of one JSONL message to messages.
+func appendTranscriptMessage(messages []anthropicMessage, role string, raw json.RawMessage) []anthropicMessage {
+	if role != "user" && role != "assistant" {
+		return messages
+	}
+
+	var blocks []map[string]any
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		if text != "" {
+			blocks = append(blocks, map[string]any{"type": "text", "text": text})
+		}
+	} else {
+		var all []map[string]any
+		if json.Unmarshal(raw, &all) != nil {
+			return messages
+		}
+		for _, block := range all {
+			switch block["type"] {
+			case "text":
+				if s, _ := block["text"].(string); s != "" {
+					blocks = append(blocks, map[string]any{"type": "text", "text": s})
+				}
+			case "image", "document":
+				if role == "user" {
+					blocks = append(blocks, block)
+				}
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return messages
+	}
+
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
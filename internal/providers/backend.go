@@ -0,0 +1,191 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"claudefu/internal/types"
+)
+
+// Agent.Provider values. An empty provider means ProviderClaudeCode.
+const (
+	ProviderClaudeCode = "claude_code" // Claude Code CLI (default)
+	ProviderAnthropic  = "anthropic"   // Anthropic Messages API, no CLI
+	ProviderCodex      = "codex"       // OpenAI Codex CLI (codex exec)
+)
+
+// Names lists every provider an agent can select.
+var Names = []string{ProviderClaudeCode, ProviderAnthropic, ProviderCodex}
+
+// BackendRequest is one new-session or send call for a non-CLI backend.
+type BackendRequest struct {
+	Folder      string
+	SessionID   string // Empty for NewSession
+	Message     string
+	Attachments []types.Attachment
+	Model       string   // Alias or full model ID (empty = backend default)
+	Env         []string // Merged process environment (nil = os.Environ())
+}
+
+// Backend drives the sessions of agents whose provider is not claude_code.
+// Backends record every turn in the folder's Claude session JSONL (see
+// claudehome.SessionPath) in the CLI's format, so the watcher and runtime
+// pick their messages up exactly like a CLI session.
+type Backend interface {
+	// NewSession creates a session, sends req.Message as its first turn and returns its ID.
+	NewSession(ctx context.Context, req BackendRequest) (string, error)
+	// Send appends req.Message to req.SessionID and returns the response text.
+	Send(ctx context.Context, req BackendRequest) (string, error)
+}
+
+// backends maps a provider name to its backend. claude_code has none:
+// ClaudeCodeService spawns the CLI itself.
+var backends = map[string]Backend{
+	ProviderAnthropic: &anthropicBackend{},
+	ProviderCodex:     &codexBackend{},
+}
+
+// ProviderCapabilities describes which ClaudeFu features an agent's provider
+// supports, so the UI can flag agents running with reduced tool support.
+type ProviderCapabilities struct {
+	Provider      string   `json:"provider"`
+	Tools         bool     `json:"tools"`         // Runs tools (shell, edits) in the agent folder
+	MCP           bool     `json:"mcp"`           // ClaudeFu MCP tools (AgentMessage, inbox, backlog, ...)
+	Permissions   bool     `json:"permissions"`   // ClaudeFu permission sets restrict its tools
+	SlashCommands bool     `json:"slashCommands"` // RunSlashCommand (/compact, /context, ...)
+	PlanMode      bool     `json:"planMode"`
+	Images        bool     `json:"images"` // Image and PDF attachments
+	Limitations   []string `json:"limitations,omitempty"`
+}
+
+// CapabilitiesFor returns the capabilities of a provider ("" = claude_code).
+func CapabilitiesFor(provider string) ProviderCapabilities {
+	switch provider {
+	case ProviderAnthropic:
+		return ProviderCapabilities{
+			Provider: provider,
+			Images:   true,
+			Limitations: []string{
+				"No tools: the model only replies with text",
+				"ClaudeFu MCP tools, permissions, slash commands and plan mode are unavailable",
+			},
+		}
+	case ProviderCodex:
+		return ProviderCapabilities{
+			Provider: provider,
+			Tools:    true,
+			Limitations: []string{
+				"Tools run under codex's own sandbox and approval settings, not ClaudeFu permissions",
+				"ClaudeFu MCP tools, slash commands and plan mode are unavailable",
+				"Only file attachments are supported",
+			},
+		}
+	}
+	return ProviderCapabilities{
+		Provider:      ProviderClaudeCode,
+		Tools:         true,
+		MCP:           true,
+		Permissions:   true,
+		SlashCommands: true,
+		PlanMode:      true,
+		Images:        true,
+	}
+}
+
+// IsSupportedProvider reports whether name can drive an agent ("" = claude_code).
+func IsSupportedProvider(name string) bool {
+	return name == "" || slices.Contains(Names, name)
+}
+
+// ProviderFor returns the provider selected for an agent folder.
+func ProviderFor(folder string) string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+	if provider := folderOverrides[filepath.Clean(folder)].Provider; provider != "" {
+		return provider
+	}
+	return ProviderClaudeCode
+}
+
+// backendFor returns the backend for an agent folder, or nil if the folder
+// uses the Claude Code CLI.
+func backendFor(folder string) Backend {
+	return backends[ProviderFor(folder)]
+}
+
+// backendCall is an in-flight backend request, tracked like a CLI process so
+// CancelSession and the active-session queries cover it.
+type backendCall struct {
+	folder string
+	cancel context.CancelFunc
+}
+
+// runBackend runs fn for sessionID under a cancellable context and a spawn slot.
+func (s *ClaudeCodeService) runBackend(kind, folder, sessionID string, priority SpawnPriority, fn func(ctx context.Context) (string, error)) (string, error) {
+	slot, err := spawnScheduler.Acquire(s.ctx, SpawnRequest{Priority: priority, Kind: kind, Folder: folder, SessionID: sessionID})
+	if err != nil {
+		return "", fmt.Errorf("%s cancelled: %w", kind, err)
+	}
+	defer slot.Release()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	if sessionID != "" {
+		s.activeProcsMu.Lock()
+		s.activeCalls[sessionID] = backendCall{folder: folder, cancel: cancel}
+		s.activeProcsMu.Unlock()
+		defer func() {
+			s.activeProcsMu.Lock()
+			delete(s.activeCalls, sessionID)
+			s.activeProcsMu.Unlock()
+		}()
+	}
+
+	if s.emitFunc != nil {
+		s.emitFunc("debug:cli-command", map[string]any{"command": ProviderFor(folder) + " API (" + kind + ")", "sessionId": sessionID, "envProfile": s.EnvProfile()})
+	}
+
+	result, err := fn(ctx)
+	if s.ctx.Err() == nil {
+		output := ""
+		var sendErr *SendError
+		if errors.As(err, &sendErr) {
+			output = sendErr.Stderr
+		}
+		rateLimiter.Observe(output, err)
+	}
+	return result, err
+}
+
+// sendViaBackend sends a message through a non-CLI backend.
+func (s *ClaudeCodeService) sendViaBackend(backend Backend, folder, sessionId, message string, attachments []types.Attachment, model string, priority SpawnPriority) (string, error) {
+	kind := "send"
+	if priority == PriorityScheduled {
+		kind = "scheduled-send"
+	}
+	return s.runBackend(kind, folder, sessionId, priority, func(ctx context.Context) (string, error) {
+		return backend.Send(ctx, BackendRequest{
+			Folder:      folder,
+			SessionID:   sessionId,
+			Message:     message,
+			Attachments: attachments,
+			Model:       model,
+			Env:         s.buildEnvironment(folder),
+		})
+	})
+}
+
+// newSessionViaBackend creates a session through a non-CLI backend.
+func (s *ClaudeCodeService) newSessionViaBackend(backend Backend, folder, model string) (string, error) {
+	return s.runBackend("new-session", folder, "", PriorityInteractive, func(ctx context.Context) (string, error) {
+		return backend.NewSession(ctx, BackendRequest{
+			Folder:  folder,
+			Message: newSessionPrompt,
+			Model:   model,
+			Env:     s.buildEnvironment(folder),
+		})
+	})
+}
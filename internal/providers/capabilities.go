@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flags ClaudeFu passes to the CLI. Required flags are needed for sends to work at all;
+// optional ones are dropped (with a logged warning) when the installed CLI lacks them.
+var (
+	requiredCLIFlags = []string{"--print", "--verbose", "--resume", "--input-format", "--output-format"}
+	optionalCLIFlags = []string{
+		"--model", "--effort", "--permission-mode", "--tools", "--allowedTools",
+		"--disallowedTools", "--disallowed-tools", "--add-dir", "--mcp-config", "--append-system-prompt",
+		"--no-session-persistence",
+	}
+)
+
+var (
+	cliFlagPattern      = regexp.MustCompile(`--[A-Za-z][A-Za-z-]*`)
+	cliChoicesPattern   = regexp.MustCompile(`choices:\s*([^)]*)`)
+	cliQuotedPattern    = regexp.MustCompile(`"([^"]+)"`)
+	cliCapabilitiesMu   sync.RWMutex
+	cliCapabilities     *CLICapabilities
+	cliHelpProbeTimeout = 15 * time.Second
+)
+
+// CLICapabilities describes which flags the installed claude CLI supports,
+// parsed from `claude --help`. When Probed is false (help failed or was
+// unparseable), every flag is assumed supported — the pre-probing behavior.
+type CLICapabilities struct {
+	Path            string          `json:"path"`
+	Version         string          `json:"version"`
+	Probed          bool            `json:"probed"`
+	ProbedAt        time.Time       `json:"probedAt"`
+	Flags           map[string]bool `json:"flags"`                     // Every flag listed by --help
+	PermissionModes []string        `json:"permissionModes,omitempty"` // --permission-mode choices
+	Unsupported     []string        `json:"unsupported,omitempty"`     // Optional flags ClaudeFu will omit
+	MissingRequired []string        `json:"missingRequired,omitempty"` // Required flags the CLI lacks — sends will fail
+	Error           string          `json:"error,omitempty"`
+}
+
+// SupportsFlag reports whether the CLI accepts flag. Unprobed CLIs support everything.
+func (c *CLICapabilities) SupportsFlag(flag string) bool {
+	if c == nil || !c.Probed {
+		return true
+	}
+	return c.Flags[flag]
+}
+
+// SupportsPermissionMode reports whether mode is a valid --permission-mode choice.
+func (c *CLICapabilities) SupportsPermissionMode(mode string) bool {
+	if !c.SupportsFlag("--permission-mode") {
+		return false
+	}
+	if c == nil || len(c.PermissionModes) == 0 {
+		return true // Choices not listed — assume the mode is accepted
+	}
+	return slices.Contains(c.PermissionModes, mode)
+}
+
+// GetCLICapabilities returns the cached capability report, probing the CLI on first use.
+func GetCLICapabilities() *CLICapabilities {
+	cliCapabilitiesMu.RLock()
+	caps := cliCapabilities
+	cliCapabilitiesMu.RUnlock()
+	if caps != nil {
+		return caps
+	}
+	return ProbeCLICapabilities()
+}
+
+// ProbeCLICapabilities runs `claude --help`, parses the supported flags and
+// permission modes, and caches the result. Safe to call again after the CLI
+// is upgraded; SetClaudeCommand also invalidates the cache.
+func ProbeCLICapabilities() *CLICapabilities {
+	caps := &CLICapabilities{Path: GetClaudePath(), Flags: make(map[string]bool)}
+
+	if caps.Path == "" {
+		caps.Error = "claude CLI not found"
+	} else {
+		if version, err := GetClaudeVersion(); err == nil {
+			caps.Version = version
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cliHelpProbeTimeout)
+		cmd := exec.CommandContext(ctx, caps.Path, "--help")
+		cmd.Env = BuildShellEnv()
+		output, err := cmd.Output()
+		cancel()
+
+		if err != nil {
+			caps.Error = fmt.Sprintf("claude --help failed: %v", err)
+		} else {
+			parseCLIHelp(caps, string(output))
+		}
+	}
+
+	if caps.Error != "" {
+		logger.Warnf("CLI capability probe: %s — assuming all flags are supported", caps.Error)
+	} else if len(caps.Unsupported) > 0 || len(caps.MissingRequired) > 0 {
+		logger.Warnf("CLI capability probe (%s): unsupported=%v missingRequired=%v",
+			caps.Version, caps.Unsupported, caps.MissingRequired)
+	} else {
+		logger.Infof("CLI capability probe (%s): all %d flags supported",
+			caps.Version, len(requiredCLIFlags)+len(optionalCLIFlags))
+	}
+
+	cliCapabilitiesMu.Lock()
+	cliCapabilities = caps
+	cliCapabilitiesMu.Unlock()
+	return caps
+}
+
+// resetCLICapabilities drops the cached report so the next use re-probes.
+func resetCLICapabilities() {
+	cliCapabilitiesMu.Lock()
+	cliCapabilities = nil
+	cliCapabilitiesMu.Unlock()
+}
+
+// parseCLIHelp fills caps from --help output. Output with no recognizable flags
+// leaves caps unprobed rather than marking every flag unsupported.
+func parseCLIHelp(caps *CLICapabilities, help string) {
+	for _, flag := range cliFlagPattern.FindAllString(help, -1) {
+		caps.Flags[flag] = true
+	}
+	if !caps.Flags["--print"] && !caps.Flags["--help"] {
+		caps.Error = "could not parse claude --help output"
+		return
+	}
+	caps.Probed = true
+	caps.ProbedAt = time.Now()
+
+	// The --permission-mode entry ends at the next option line
+	if idx := strings.Index(help, "--permission-mode"); idx >= 0 {
+		entry := help[idx:]
+		if end := strings.Index(entry, "\n  -"); end >= 0 {
+			entry = entry[:end]
+		}
+		if m := cliChoicesPattern.FindStringSubmatch(entry); m != nil {
+			for _, q := range cliQuotedPattern.FindAllStringSubmatch(m[1], -1) {
+				caps.PermissionModes = append(caps.PermissionModes, q[1])
+			}
+		}
+	}
+
+	for _, flag := range optionalCLIFlags {
+		if !caps.Flags[flag] {
+			caps.Unsupported = append(caps.Unsupported, flag)
+		}
+	}
+	for _, flag := range requiredCLIFlags {
+		if !caps.Flags[flag] {
+			caps.MissingRequired = append(caps.MissingRequired, flag)
+		}
+	}
+}
+
+// AppendSupportedFlag appends flag and its values to args if the installed CLI
+// supports it; otherwise the flag is omitted and a warning is logged.
+func AppendSupportedFlag(args []string, flag string, values ...string) []string {
+	if !GetCLICapabilities().SupportsFlag(flag) {
+		logger.Warnf("Omitting %s: not supported by the installed claude CLI", flag)
+		return args
+	}
+	return append(append(args, flag), values...)
+}
+
+// AppendPermissionModeArg appends --permission-mode mode when the CLI supports
+// both the flag and the mode; otherwise the CLI's default mode applies.
+func AppendPermissionModeArg(args []string, mode string) []string {
+	if !GetCLICapabilities().SupportsPermissionMode(mode) {
+		logger.Warnf("Omitting --permission-mode %s: not supported by the installed claude CLI", mode)
+		return args
+	}
+	return append(args, "--permission-mode", mode)
+}
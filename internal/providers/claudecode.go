@@ -7,16 +7,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
+	"claudefu/internal/logging"
+	"claudefu/internal/metrics"
 	"claudefu/internal/permissions"
 	"claudefu/internal/types"
 )
 
+// logger is the providers subsystem logger (see internal/logging).
+var logger = logging.For(logging.Providers)
+
 var (
 	// Claude binary path — resettable cache (double-checked locking with RWMutex).
 	// Use SetClaudeCommand() to override the command name/path and invalidate the cache.
@@ -41,18 +48,29 @@ func SetClaudeCommand(command string) {
 	claudeCommand = command
 	claudePathResolved = false
 	claudePath = ""
+	resetCLICapabilities()
 }
 
 // findClaudeBinary searches for the claude binary in common locations.
 // command is the configured binary name/path (empty = use "claude").
-// macOS GUI apps have a limited PATH, so we check common install locations.
+// macOS GUI apps have a limited PATH, so we also search the user's shell PATH
+// and common install locations.
 func findClaudeBinary(command string) string {
+	// Get home directory for ~ expansion
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+
 	// Determine the bare name to search for
 	name := "claude"
 	if command != "" {
+		if strings.HasPrefix(command, "~/") {
+			command = filepath.Join(home, command[2:])
+		}
 		// If it contains a path separator, treat as an explicit path
-		if strings.Contains(command, "/") {
-			if info, err := os.Stat(command); err == nil && info.Mode()&0111 != 0 {
+		if strings.ContainsRune(command, '/') || strings.ContainsRune(command, filepath.Separator) {
+			if info, err := os.Stat(command); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
 				return command
 			}
 			return ""
@@ -65,10 +83,15 @@ func findClaudeBinary(command string) string {
 		return path
 	}
 
-	// Get home directory for ~ expansion
-	home, err := os.UserHomeDir()
-	if err != nil {
-		home = os.Getenv("HOME")
+	// Then the user's login-shell PATH (nvm, asdf, custom bin dirs)
+	for _, dir := range filepath.SplitList(GetShellPATH()) {
+		if dir == "" {
+			continue
+		}
+		loc := filepath.Join(dir, name)
+		if info, err := os.Stat(loc); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return loc
+		}
 	}
 
 	// Common installation locations on macOS (with configurable name)
@@ -165,6 +188,23 @@ func BuildShellEnv() []string {
 	return replaceOrAppendEnv(env, "PATH", resolvedPATH)
 }
 
+// BuildShellEnvFor is BuildShellEnv for a process run in an agent folder: the
+// agent's own env vars (Agent.Env) are applied over the shell environment.
+func BuildShellEnvFor(folder string) []string {
+	agentEnvVars := folderEnv(folder)
+	env := BuildShellEnv()
+	if len(agentEnvVars) == 0 {
+		return env
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	for key, value := range agentEnvVars {
+		env = replaceOrAppendEnv(env, key, value)
+	}
+	return env
+}
+
 // GetClaudePath returns the path to the claude binary (cached, resettable via SetClaudeCommand).
 func GetClaudePath() string {
 	// Fast path: already resolved
@@ -188,23 +228,39 @@ func GetClaudePath() string {
 
 // ClaudeCodeService provides interaction with the Claude Code CLI
 type ClaudeCodeService struct {
-	ctx       context.Context
-	mcpConfig string // JSON config for --mcp-config flag (empty = disabled)
+	ctx         context.Context
+	mcpConfig   string       // JSON config for --mcp-config (passed as a file, see mcp_config_file.go; empty = disabled)
+	mcpConfigMu sync.RWMutex // Config changes on workspace switch (new auth token)
 
 	// Custom environment variables for Claude CLI (e.g., ANTHROPIC_BASE_URL for proxies)
 	envVars   map[string]string
 	envVarsMu sync.RWMutex
 
+	// Active workspace environment profile (guarded by envVarsMu)
+	profileName    string
+	profileEnvVars map[string]string
+	profileGuard   *permissions.Guard
+
 	// Process tracking for cancellation support
-	activeProcs   map[string]*exec.Cmd // sessionID -> running command
+	activeProcs   map[string]*exec.Cmd   // sessionID -> running command
+	activeCalls   map[string]backendCall // sessionID -> in-flight non-CLI backend request
 	activeProcsMu sync.RWMutex
 
 	// Cancellation tracking - distinguishes user cancellation from errors
 	cancelledSessions   map[string]bool
 	cancelledSessionsMu sync.RWMutex
 
+	// Per-session send serialization (see send_queue.go)
+	busySessions map[string]bool
+	sendQueues   map[string][]*sendWaiter
+	sendQueueMu  sync.Mutex
+
 	// Event emission for debug info (CLI command, etc.)
 	emitFunc func(eventType string, data map[string]any)
+
+	// Live stdout of sends (see stream_sink.go)
+	streamSink   StreamSink
+	streamSinkMu sync.RWMutex
 }
 
 // NewClaudeCodeService creates a new Claude Code service
@@ -212,7 +268,10 @@ func NewClaudeCodeService(ctx context.Context) *ClaudeCodeService {
 	return &ClaudeCodeService{
 		ctx:               ctx,
 		activeProcs:       make(map[string]*exec.Cmd),
+		activeCalls:       make(map[string]backendCall),
 		cancelledSessions: make(map[string]bool),
+		busySessions:      make(map[string]bool),
+		sendQueues:        make(map[string][]*sendWaiter),
 	}
 }
 
@@ -221,23 +280,58 @@ func (s *ClaudeCodeService) SetContext(ctx context.Context) {
 	s.ctx = ctx
 }
 
-// SetMCPServerPort configures the MCP server URL for inter-agent communication
-// When set, all spawned Claude processes will include --mcp-config with this server
-func (s *ClaudeCodeService) SetMCPServerPort(port int) {
+// SetMCPServer configures the MCP server URL for inter-agent communication
+// When set, all spawned Claude processes will include --mcp-config with this server.
+// A non-empty token is sent as a bearer Authorization header.
+func (s *ClaudeCodeService) SetMCPServer(host string, port int, token string) {
+	config := MCPConfigJSON(host, port, token)
+	s.mcpConfigMu.Lock()
+	s.mcpConfig = config
+	s.mcpConfigMu.Unlock()
+}
+
+// MCPConfigJSON returns the inline --mcp-config JSON for the ClaudeFu MCP server
+// at host:port ("" if port is unset). A non-empty token is sent as a bearer
+// Authorization header.
+func MCPConfigJSON(host string, port int, token string) string {
 	if port <= 0 {
-		s.mcpConfig = ""
-		return
+		return ""
 	}
 	// Generate inline JSON config for SSE transport
-	// Format: {"mcpServers":{"name":{"type":"sse","url":"..."}}}
-	s.mcpConfig = fmt.Sprintf(`{"mcpServers":{"claudefu":{"type":"sse","url":"http://localhost:%d/sse"}}}`, port)
+	// Format: {"mcpServers":{"name":{"type":"sse","url":"...","headers":{...}}}}
+	server := map[string]any{
+		"type": "sse",
+		"url":  fmt.Sprintf("http://%s/sse", net.JoinHostPort(host, strconv.Itoa(port))),
+	}
+	if token != "" {
+		server["headers"] = map[string]string{"Authorization": "Bearer " + token}
+	}
+	data, err := json.Marshal(map[string]any{"mcpServers": map[string]any{"claudefu": server}})
+	if err != nil {
+		return ""
+	}
+	return string(data)
 }
 
 // ClearMCPConfig disables MCP config injection
 func (s *ClaudeCodeService) ClearMCPConfig() {
+	s.mcpConfigMu.Lock()
+	defer s.mcpConfigMu.Unlock()
 	s.mcpConfig = ""
 }
 
+// getMCPConfig returns the --mcp-config JSON for an agent folder ("" = MCP
+// disabled): its override (agents of background workspaces use their own
+// workspace's server), else the active workspace's server
+func (s *ClaudeCodeService) getMCPConfig(folder string) string {
+	if config := MCPConfigFor(folder); config != "" {
+		return config
+	}
+	s.mcpConfigMu.RLock()
+	defer s.mcpConfigMu.RUnlock()
+	return s.mcpConfig
+}
+
 // SetEnvironment sets custom environment variables to be passed to Claude CLI processes.
 // These are merged with the parent process environment (custom vars take precedence).
 // Use this for proxies (ANTHROPIC_BASE_URL), custom API keys, or other env-based config.
@@ -247,23 +341,50 @@ func (s *ClaudeCodeService) SetEnvironment(vars map[string]string) {
 	s.envVars = vars
 }
 
+// SetEnvProfile sets the active workspace environment profile. Its env vars are
+// applied over the custom vars, and guard restricts the permission args of every
+// spawned process. An empty name clears the profile.
+func (s *ClaudeCodeService) SetEnvProfile(name string, vars map[string]string, guard *permissions.Guard) {
+	s.envVarsMu.Lock()
+	defer s.envVarsMu.Unlock()
+	s.profileName = name
+	s.profileEnvVars = vars
+	s.profileGuard = guard
+}
+
+// EnvProfile returns the name of the active environment profile ("" if none).
+func (s *ClaudeCodeService) EnvProfile() string {
+	s.envVarsMu.RLock()
+	defer s.envVarsMu.RUnlock()
+	return s.profileName
+}
+
+// envGuard returns the active profile's permission guard (nil if none).
+func (s *ClaudeCodeService) envGuard() *permissions.Guard {
+	s.envVarsMu.RLock()
+	defer s.envVarsMu.RUnlock()
+	return s.profileGuard
+}
+
 // SetEmitFunc sets the function to emit events (for debug info like CLI commands)
 func (s *ClaudeCodeService) SetEmitFunc(emitFunc func(eventType string, data map[string]any)) {
 	s.emitFunc = emitFunc
 }
 
-// buildEnvironment creates the environment slice for exec.Cmd.
-// It merges the parent process environment with the user's shell PATH and custom vars.
+// buildEnvironment creates the environment slice for exec.Cmd in folder.
+// It merges the parent process environment with the user's shell PATH, the custom
+// vars, the active profile's vars, and finally the folder's agent vars.
 // On macOS, GUI apps inherit a minimal PATH from launchd — this ensures spawned Claude
 // processes get the full PATH from the user's login shell (Homebrew, Go, nvm, cargo, etc.).
-func (s *ClaudeCodeService) buildEnvironment() []string {
+func (s *ClaudeCodeService) buildEnvironment(folder string) []string {
 	s.envVarsMu.RLock()
 	defer s.envVarsMu.RUnlock()
 
 	resolvedPATH := GetShellPATH()
+	agentEnvVars := folderEnv(folder)
 
 	// If no shell PATH and no custom vars, inherit parent env as-is
-	if resolvedPATH == "" && len(s.envVars) == 0 {
+	if resolvedPATH == "" && len(s.envVars) == 0 && len(s.profileEnvVars) == 0 && len(agentEnvVars) == 0 {
 		return nil
 	}
 
@@ -277,18 +398,34 @@ func (s *ClaudeCodeService) buildEnvironment() []string {
 
 	// Append custom vars (these override existing vars with same name)
 	if len(s.envVars) > 0 {
-		fmt.Printf("[DEBUG] buildEnvironment: injecting %d custom env vars:\n", len(s.envVars))
+		logger.Debugf("buildEnvironment: injecting %d custom env vars:", len(s.envVars))
 		for key, value := range s.envVars {
 			// Truncate value for security (don't log full API keys)
 			displayVal := value
 			if len(displayVal) > 60 {
 				displayVal = displayVal[:60] + "..."
 			}
-			fmt.Printf("[DEBUG]   %s=%s\n", key, displayVal)
+			logger.Debugf("  %s=%s", key, displayVal)
 			env = replaceOrAppendEnv(env, key, value)
 		}
 	} else {
-		fmt.Printf("[DEBUG] buildEnvironment: no custom env vars to inject\n")
+		logger.Debugf("buildEnvironment: no custom env vars to inject")
+	}
+
+	// Environment profile vars win over the global custom vars
+	if len(s.profileEnvVars) > 0 {
+		logger.Debugf("buildEnvironment: applying %d env vars from profile %q", len(s.profileEnvVars), s.profileName)
+		for key, value := range s.profileEnvVars {
+			env = replaceOrAppendEnv(env, key, value)
+		}
+	}
+
+	// The agent's own vars win over both
+	if len(agentEnvVars) > 0 {
+		logger.Debugf("buildEnvironment: applying %d agent env vars for %s", len(agentEnvVars), folder)
+		for key, value := range agentEnvVars {
+			env = replaceOrAppendEnv(env, key, value)
+		}
 	}
 
 	return env
@@ -321,13 +458,56 @@ func (s *ClaudeCodeService) untrackProcess(sessionID string) {
 	delete(s.activeProcs, sessionID)
 }
 
+// ActiveSessionCount returns the number of sessions with a running Claude process
+func (s *ClaudeCodeService) ActiveSessionCount() int {
+	s.activeProcsMu.RLock()
+	defer s.activeProcsMu.RUnlock()
+	return len(s.activeProcs) + len(s.activeCalls)
+}
+
+// ActiveSessionsInFolder returns the sessions with a running Claude process in folder
+func (s *ClaudeCodeService) ActiveSessionsInFolder(folder string) []string {
+	s.activeProcsMu.RLock()
+	defer s.activeProcsMu.RUnlock()
+	var sessions []string
+	for sessionID, cmd := range s.activeProcs {
+		if cmd.Dir == folder {
+			sessions = append(sessions, sessionID)
+		}
+	}
+	for sessionID, call := range s.activeCalls {
+		if call.folder == folder {
+			sessions = append(sessions, sessionID)
+		}
+	}
+	return sessions
+}
+
 // CancelSession sends SIGINT to the running Claude process for a session
 // Returns nil if no process is running for that session (already finished)
 func (s *ClaudeCodeService) CancelSession(sessionID string) error {
+	// Still waiting for a spawn slot - drop it from the queue
+	if spawnScheduler.CancelQueued(sessionID) {
+		s.cancelledSessionsMu.Lock()
+		s.cancelledSessions[sessionID] = true
+		s.cancelledSessionsMu.Unlock()
+		return nil
+	}
+
 	s.activeProcsMu.RLock()
 	cmd, ok := s.activeProcs[sessionID]
+	call, isCall := s.activeCalls[sessionID]
 	s.activeProcsMu.RUnlock()
 
+	// Non-CLI backend request - cancel its context
+	if isCall {
+		s.cancelledSessionsMu.Lock()
+		s.cancelledSessions[sessionID] = true
+		s.cancelledSessionsMu.Unlock()
+		call.cancel()
+		return nil
+	}
+
 	if !ok {
 		// No running process - already finished or never started
 		return nil
@@ -344,10 +524,10 @@ func (s *ClaudeCodeService) CancelSession(sessionID string) error {
 
 	// Send SIGINT for graceful termination (like Ctrl+C in terminal)
 	// This allows Claude CLI to clean up properly
-	fmt.Printf("[DEBUG] CancelSession: sending SIGINT to session %s (PID %d)\n", sessionID, cmd.Process.Pid)
+	logger.Debugf("CancelSession: sending SIGINT to session %s (PID %d)", sessionID, cmd.Process.Pid)
 	if err := cmd.Process.Signal(os.Interrupt); err != nil {
 		// Process may have already exited
-		fmt.Printf("[DEBUG] CancelSession: signal error (process may have exited): %v\n", err)
+		logger.Debugf("CancelSession: signal error (process may have exited): %v", err)
 		return nil
 	}
 
@@ -367,17 +547,22 @@ func (s *ClaudeCodeService) WasCancelled(sessionID string) bool {
 
 // getMCPArgs returns ONLY the --mcp-config arg if MCP is configured.
 // MCP tool allow/disallow is handled by buildPermissionArgs to avoid duplicate flags.
-func (s *ClaudeCodeService) getMCPArgs() []string {
-	if s.mcpConfig == "" {
-		return nil
+// The config is passed as a file path so its bearer token stays out of argv.
+func (s *ClaudeCodeService) getMCPArgs(folder string) ([]string, error) {
+	config := s.getMCPConfig(folder)
+	if config == "" {
+		return nil, nil
 	}
-	return []string{
-		"--mcp-config", s.mcpConfig,
+	path, err := mcpConfigPath(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write MCP config: %w", err)
 	}
+	return AppendSupportedFlag(nil, "--mcp-config", path), nil
 }
 
 // buildPermissionArgs compiles ClaudeFu permissions into CLI flags for spawning Claude.
 // This is the key integration point where our permission system controls Claude's behavior.
+// sessionID adds that session's RequestToolPermission grants (empty for new sessions).
 //
 // Flag semantics:
 //   - --tools: Which built-in tools are AVAILABLE (the pool/universe)
@@ -387,42 +572,75 @@ func (s *ClaudeCodeService) getMCPArgs() []string {
 //
 // Note: We intentionally omit --setting-sources to allow Claude's global settings
 // to still apply. Our explicit flags take precedence.
-func (s *ClaudeCodeService) buildPermissionArgs(folder string) []string {
+//
+// YOLO-tier patterns the user hasn't acknowledged are withheld from
+// --allowedTools, with a one-time permissions:dangerous warning (see
+// permission_guardrails.go).
+func (s *ClaudeCodeService) buildPermissionArgs(folder, sessionID string) []string {
+	eff := s.EffectivePermissions(folder, sessionID)
+	s.warnDangerous(folder, eff.Withheld)
+	if len(eff.Args) > 0 {
+		logger.Debugf("buildPermissionArgs: generated %d permission args", len(eff.Args))
+	}
+	return eff.Args
+}
+
+// EffectivePermissions compiles the permission flags buildPermissionArgs passes
+// for folder (and sessionID's grants, if any), and the permission mode sends
+// use outside plan mode.
+func (s *ClaudeCodeService) EffectivePermissions(folder, sessionID string) EffectivePermissions {
+	eff := s.compilePermissions(folder, sessionID)
 	if folder == "" {
-		return nil
+		return eff
+	}
+	mode, withheld := guardPermissionMode(folder, PermissionModeFor(folder))
+	eff.PermissionMode = mode
+	if withheld {
+		eff.Withheld = append(eff.Withheld, BypassPermissionsAck)
+	}
+	return eff
+}
+
+// compilePermissions compiles the permission flags of EffectivePermissions.
+func (s *ClaudeCodeService) compilePermissions(folder, sessionID string) EffectivePermissions {
+	var eff EffectivePermissions
+	if folder == "" {
+		return eff
 	}
 
 	mgr, err := permissions.NewManager()
 	if err != nil {
-		fmt.Printf("[DEBUG] buildPermissionArgs: failed to create permissions manager: %v\n", err)
-		return nil
+		logger.Debugf("buildPermissionArgs: failed to create permissions manager: %v", err)
+		return eff
 	}
 
 	perms, err := mgr.GetAgentPermissionsOrGlobal(folder)
 	if err != nil {
-		fmt.Printf("[DEBUG] buildPermissionArgs: failed to load permissions: %v\n", err)
-		return nil
+		logger.Debugf("buildPermissionArgs: failed to load permissions: %v", err)
+		return eff
 	}
 
+	guard := s.envGuard()
+
 	if perms == nil {
-		fmt.Printf("[DEBUG] buildPermissionArgs: no permissions found, using defaults\n")
-		return nil
+		logger.Debugf("buildPermissionArgs: no permissions found, using defaults")
+		grants := guard.FilterAllowList(GetSessionGrants(sessionID))
+		grants, eff.Withheld = withholdDangerous(folder, nil, grants)
+		eff.AllowedTools = strings.Join(grants, ",")
+		eff.DisallowedTools = strings.Join(guard.DenyList(), ",")
+		eff.Args = eff.args()
+		return eff
 	}
 
-	var args []string
-
 	// 1. --tools: Set which built-in tools are AVAILABLE (the pool)
-	availableTools := mgr.CompileAvailableTools(perms)
-	if len(availableTools) > 0 {
-		args = append(args, "--tools", strings.Join(availableTools, ","))
-	}
+	eff.Tools = strings.Join(mgr.CompileAvailableTools(perms), ",")
 
 	// 2. --allowedTools: Auto-approve these (no permission prompt)
 	// This includes enabled built-in tools + Bash patterns from sets
 	allowedPatterns := mgr.CompileAllowList(perms)
 
 	// Add MCP tools to allowed list if MCP is configured
-	if s.mcpConfig != "" {
+	if s.getMCPConfig(folder) != "" {
 		mcpTools := []string{
 			"mcp__claudefu__AgentBroadcast",
 			"mcp__claudefu__AgentMessage",
@@ -436,18 +654,44 @@ func (s *ClaudeCodeService) buildPermissionArgs(folder string) []string {
 			"mcp__claudefu__BacklogAdd",
 			"mcp__claudefu__BacklogUpdate",
 			"mcp__claudefu__BacklogList",
+			"mcp__claudefu__BacklogMove",
+			"mcp__claudefu__BacklogExport",
 			"mcp__claudefu__MetaserverQuery",
 			"mcp__claudefu__MetaserverServices",
 			"mcp__claudefu__MetaserverStart",
 			"mcp__claudefu__MetaserverStop",
 			"mcp__claudefu__MetaserverRestart",
+			"mcp__claudefu__AgentDiffRequest",
+			"mcp__claudefu__AgentNewSession",
+			"mcp__claudefu__AgentInboxList",
+			"mcp__claudefu__AgentInboxReply",
+			"mcp__claudefu__TaskCreate",
+			"mcp__claudefu__TaskAssign",
+			"mcp__claudefu__TaskComplete",
+			"mcp__claudefu__TaskGraph",
+			"mcp__claudefu__ExportSession",
+			"mcp__claudefu__AgentStatus",
+			"mcp__claudefu__MemorySet",
+			"mcp__claudefu__MemoryGet",
+			"mcp__claudefu__MemoryList",
+			"mcp__claudefu__AgentHandoff",
+			"mcp__claudefu__SessionSummary",
+			// Built-in tools for reading ClaudeFu's MCP resources (CLAUDE.md, backlog)
+			"ListMcpResourcesTool",
+			"ReadMcpResourceTool",
 		}
 		allowedPatterns = append(allowedPatterns, mcpTools...)
 	}
 
-	if len(allowedPatterns) > 0 {
-		args = append(args, "--allowedTools", strings.Join(allowedPatterns, ","))
-	}
+	// Session-scoped grants from RequestToolPermission ("allow for this session")
+	allowedPatterns = append(allowedPatterns, GetSessionGrants(sessionID)...)
+
+	// The active environment profile can take tools back out
+	allowedPatterns = guard.FilterAllowList(allowedPatterns)
+
+	// YOLO-tier patterns wait for the user's acknowledgement
+	allowedPatterns, eff.Withheld = withholdDangerous(folder, perms, allowedPatterns)
+	eff.AllowedTools = strings.Join(allowedPatterns, ",")
 
 	// 3. --disallowedTools: Always deny these
 	// NOTE: We only pass this if there are explicit deny patterns.
@@ -459,26 +703,19 @@ func (s *ClaudeCodeService) buildPermissionArgs(folder string) []string {
 
 	// Add built-in tools to deny list when MCP is configured
 	// This forces Claude to use our MCP versions instead of built-in
-	if s.mcpConfig != "" {
+	if s.getMCPConfig(folder) != "" {
 		denyPatterns = append(denyPatterns, "AskUserQuestion", "ExitPlanMode")
 	}
-	if len(denyPatterns) > 0 {
-		args = append(args, "--disallowedTools", strings.Join(denyPatterns, ","))
-	}
+	denyPatterns = append(denyPatterns, guard.DenyList()...)
+	eff.DisallowedTools = strings.Join(denyPatterns, ",")
 
 	// 4. --add-dir: Additional directories (union of global + agent dirs)
-	dirs, err := mgr.CompileDirectories(folder)
-	if err == nil {
-		for _, dir := range dirs {
-			args = append(args, "--add-dir", dir)
-		}
+	if dirs, err := mgr.CompileDirectories(folder); err == nil {
+		eff.AddDirs = dirs
 	}
 
-	if len(args) > 0 {
-		fmt.Printf("[DEBUG] buildPermissionArgs: generated %d permission args\n", len(args))
-	}
-
-	return args
+	eff.Args = eff.args()
+	return eff
 }
 
 // SendMessage sends a message to Claude Code in the specified folder/session.
@@ -489,98 +726,83 @@ func (s *ClaudeCodeService) buildPermissionArgs(folder string) []string {
 // empty = omit the flag entirely and let the CLI use its configured default.
 // The effort parameter (low|medium|high|xhigh|max|auto) is passed to --effort; empty = omit.
 func (s *ClaudeCodeService) SendMessage(folder, sessionId, message string, attachments []types.Attachment, planMode bool, model, effort string) error {
+	_, err := s.SendMessageWithResult(folder, sessionId, message, attachments, planMode, model, effort)
+	return err
+}
+
+// SendMessageWithResult is SendMessage, but also returns the final result text from the
+// CLI's stream-json output. Used by headless callers that print the response.
+func (s *ClaudeCodeService) SendMessageWithResult(folder, sessionId, message string, attachments []types.Attachment, planMode bool, model, effort string) (string, error) {
+	return s.SendMessageWithPriority(folder, sessionId, message, attachments, planMode, model, effort, PriorityInteractive)
+}
+
+// SendMessageWithPriority is SendMessageWithResult with an explicit spawn scheduler
+// lane, so unattended sends (e.g. scheduled prompts) queue behind user sends.
+func (s *ClaudeCodeService) SendMessageWithPriority(folder, sessionId, message string, attachments []types.Attachment, planMode bool, model, effort string, priority SpawnPriority) (string, error) {
 	if folder == "" {
-		return fmt.Errorf("folder is required")
+		return "", fmt.Errorf("folder is required")
 	}
 	if sessionId == "" {
-		return fmt.Errorf("sessionId is required")
+		return "", fmt.Errorf("sessionId is required")
 	}
 	if message == "" && len(attachments) == 0 {
-		return fmt.Errorf("message or attachments required")
+		return "", fmt.Errorf("message or attachments required")
 	}
 
-	// Get claude binary path
-	path := GetClaudePath()
+	// Agents on another provider skip the CLI entirely
+	if backend := backendFor(folder); backend != nil {
+		release, err := s.acquireSendTurn(sessionId, message)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+		return s.sendViaBackend(backend, folder, sessionId, message, attachments, model, priority)
+	}
+
+	// Get claude binary path (per-agent override or global)
+	path := ClaudePathFor(folder)
 	if path == "" {
-		return fmt.Errorf("claude CLI not found in PATH or common locations")
+		return "", fmt.Errorf("claude CLI not found in PATH or common locations")
+	}
+	if caps := GetCLICapabilities(); len(caps.MissingRequired) > 0 {
+		return "", fmt.Errorf("installed claude CLI (%s) does not support required flags %s — please update Claude Code",
+			caps.Version, strings.Join(caps.MissingRequired, ", "))
 	}
 
-	// Determine permission mode
-	permissionMode := "acceptEdits"
+	// Determine permission mode (plan mode overrides the agent's default;
+	// an unacknowledged bypassPermissions is withheld, see buildPermissionArgs)
+	permissionMode, _ := guardPermissionMode(folder, PermissionModeFor(folder))
 	if planMode {
 		permissionMode = "plan"
 	}
 
+	// One send per session at a time; later sends wait their turn
+	release, err := s.acquireSendTurn(sessionId, message)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	// Always use stream-json stdin approach for robust message handling.
 	// This avoids CLI argument parsing issues with special characters (e.g., --- interpreted as option terminator).
-	return s.sendViaStdin(path, folder, sessionId, message, attachments, permissionMode, model, effort)
+	return s.sendViaStdin(path, folder, sessionId, message, attachments, permissionMode, model, effort, priority)
 }
 
 // sendViaStdin sends a message (with optional attachments) via stdin using stream-json format.
 // This is the primary send method — all messages go through stdin to avoid CLI argument parsing
 // issues with special characters like --- (option terminator), quotes, backticks, etc.
 // Required flags: --input-format stream-json, --output-format stream-json, --verbose
-func (s *ClaudeCodeService) sendViaStdin(claudePath, folder, sessionId, message string, attachments []types.Attachment, permissionMode string, model, effort string) error {
-	fmt.Printf("[DEBUG] sendViaStdin: folder=%s sessionId=%s message=%q attachments=%d\n", folder, sessionId, message, len(attachments))
-
-	// Build content blocks array
-	contentBlocks := make([]map[string]any, 0, len(attachments)+1)
-
-	// Add text block if message is not empty
-	if message != "" {
-		contentBlocks = append(contentBlocks, map[string]any{
-			"type": "text",
-			"text": message,
-		})
-	}
+// Returns the final result text parsed from the stream-json output.
+func (s *ClaudeCodeService) sendViaStdin(claudePath, folder, sessionId, message string, attachments []types.Attachment, permissionMode string, model, effort string, priority SpawnPriority) (string, error) {
+	logger.Debugf("sendViaStdin: folder=%s sessionId=%s message=%q attachments=%d", folder, sessionId, message, len(attachments))
 
-	// Add attachment blocks (images or files)
 	for i, att := range attachments {
-		fmt.Printf("[DEBUG] sendViaStdin: attachment[%d] type=%s mediaType=%s dataLen=%d\n", i, att.Type, att.MediaType, len(att.Data))
-
-		if att.Type == "image" {
-			// Image block - send as base64 image
-			contentBlocks = append(contentBlocks, map[string]any{
-				"type": "image",
-				"source": map[string]any{
-					"type":       "base64",
-					"media_type": att.MediaType,
-					"data":       att.Data,
-				},
-			})
-		} else if att.Type == "file" {
-			// File block - use unique XML-style delimiter (avoids collision with ``` in content)
-			// Use filePath for the header, fallback to fileName
-			displayPath := att.FilePath
-			if displayPath == "" {
-				displayPath = att.FileName
-			}
-			ext := att.Extension
-			if ext == "" {
-				ext = "txt"
-			}
-			// Format: <claudefu-file path="..." ext="...">content</claudefu-file>
-			// This delimiter won't appear in normal file content
-			fileContent := fmt.Sprintf("\n\n<claudefu-file path=\"%s\" ext=\"%s\">\n%s\n</claudefu-file>", displayPath, ext, att.Data)
-			contentBlocks = append(contentBlocks, map[string]any{
-				"type": "text",
-				"text": fileContent,
-			})
-		}
-	}
-
-	// Build the user message payload (stream-json input format)
-	payload := map[string]any{
-		"type": "user",
-		"message": map[string]any{
-			"role":    "user",
-			"content": contentBlocks,
-		},
+		logger.Debugf("sendViaStdin: attachment[%d] type=%s mediaType=%s dataLen=%d", i, att.Type, att.MediaType, len(att.Data))
 	}
 
-	jsonBytes, err := json.Marshal(payload)
+	jsonBytes, err := buildStdinPayload(message, attachments)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return "", err
 	}
 
 	// Log first 500 chars of JSON for debugging (don't log full base64)
@@ -588,104 +810,244 @@ func (s *ClaudeCodeService) sendViaStdin(claudePath, folder, sessionId, message
 	if len(jsonPreview) > 500 {
 		jsonPreview = jsonPreview[:500] + "..."
 	}
-	fmt.Printf("[DEBUG] sendViaStdin: JSON payload preview: %s\n", jsonPreview)
-	fmt.Printf("[DEBUG] sendViaStdin: JSON payload total length: %d bytes\n", len(jsonBytes))
+	logger.Debugf("sendViaStdin: JSON payload preview: %s", jsonPreview)
+	logger.Debugf("sendViaStdin: JSON payload total length: %d bytes", len(jsonBytes))
 
 	// Build command args - stream-json input requires these flags.
 	// --model and --effort are passed verbatim; empty values are omitted so the CLI
 	// falls back to its configured default (settings.json, ANTHROPIC_MODEL, or account default).
-	args := []string{
+	args := append(ExtraCLIArgsFor(folder),
 		"--print",
 		"--verbose",
-	}
+	)
 	if model != "" {
-		args = append(args, "--model", model)
+		args = AppendSupportedFlag(args, "--model", model)
 	}
 	if effort != "" {
-		args = append(args, "--effort", effort)
+		args = AppendSupportedFlag(args, "--effort", effort)
 	}
 	args = append(args,
 		"--input-format", "stream-json",
 		"--output-format", "stream-json",
-		"--permission-mode", permissionMode,
 		"--resume", sessionId,
 	)
+	args = AppendPermissionModeArg(args, permissionMode)
 
 	// Add permission args (tools, allowedTools, disallowedTools, add-dir)
-	args = append(args, s.buildPermissionArgs(folder)...)
+	args = append(args, s.buildPermissionArgs(folder, sessionId)...)
 
-	args = append(args, s.getMCPArgs()...)
+	mcpArgs, err := s.getMCPArgs(folder)
+	if err != nil {
+		return "", err
+	}
+	args = append(args, mcpArgs...)
+
+	// Wait for a spawn slot (user sends run ahead of queued background work)
+	kind := "send"
+	if priority == PriorityScheduled {
+		kind = "scheduled-send"
+	}
+	slot, err := spawnScheduler.Acquire(s.ctx, SpawnRequest{Priority: priority, Kind: kind, Folder: folder, SessionID: sessionId})
+	if err != nil {
+		return "", fmt.Errorf("claude command cancelled: %w", err)
+	}
+	defer slot.Release()
 
-	fmt.Printf("[DEBUG] sendViaStdin: running command: %s %v\n", claudePath, args)
+	logger.Debugf("sendViaStdin: running command: %s %v", claudePath, redactArgs(args))
 
 	cmd := exec.CommandContext(s.ctx, claudePath, args...)
 	cmd.Dir = folder
-	cmd.Env = s.buildEnvironment() // Apply custom env vars (e.g., ANTHROPIC_BASE_URL for proxies)
+	cmd.Env = s.buildEnvironment(folder) // Apply custom env vars (e.g., ANTHROPIC_BASE_URL for proxies)
 	cmd.Stdin = bytes.NewReader(jsonBytes)
 
 	// Track the process for potential cancellation
 	s.trackProcess(sessionId, cmd)
 	defer s.untrackProcess(sessionId)
 
-	// Capture output for error reporting
+	// Capture output for error reporting, and forward it line by line as it
+	// arrives for stream watch mode
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if lines := s.streamWriter(folder, sessionId); lines != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, lines)
+		defer lines.Flush()
+	}
 
-	fmt.Printf("[DEBUG] sendViaStdin: executing command...\n")
+	logger.Debugf("sendViaStdin: executing command...")
 
 	// Emit CLI command for debug display (note: with attachments, stdin is piped so we note that)
 	if s.emitFunc != nil {
-		cmdStr := claudePath + " " + strings.Join(args, " ") + " < [stream-json stdin]"
-		s.emitFunc("debug:cli-command", map[string]any{"command": cmdStr, "sessionId": sessionId})
+		cmdStr := claudePath + " " + strings.Join(redactArgs(args), " ") + " < [stream-json stdin]"
+		s.emitFunc("debug:cli-command", map[string]any{"command": cmdStr, "sessionId": sessionId, "envProfile": s.EnvProfile()})
 	}
 
 	// Start the command (non-blocking)
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start claude: %w", err)
+		return "", fmt.Errorf("failed to start claude: %w", err)
 	}
 
-	fmt.Printf("[DEBUG] sendViaStdin: started claude PID=%d for session %s\n", cmd.Process.Pid, sessionId)
+	logger.Debugf("sendViaStdin: started claude PID=%d for session %s", cmd.Process.Pid, sessionId)
+	procStart := metrics.ProcessStarted(metrics.ProcessSend)
+	defer metrics.ProcessExited(metrics.ProcessSend, procStart)
 
 	// Wait for command to complete (or be cancelled via CancelSession)
 	err = cmd.Wait()
-	fmt.Printf("[DEBUG] sendViaStdin: command completed, err=%v\n", err)
+	logger.Debugf("sendViaStdin: command completed, err=%v", err)
 
+	if s.ctx.Err() == nil {
+		rateLimiter.Observe(stdout.String()+stderr.String(), err)
+	}
 	if err != nil {
 		// Check if it was cancelled (context or signal)
 		if s.ctx.Err() != nil {
-			fmt.Printf("[DEBUG] sendViaStdin: CANCELLED\n")
-			return fmt.Errorf("claude command cancelled: %w", s.ctx.Err())
+			logger.Debugf("sendViaStdin: CANCELLED")
+			return "", fmt.Errorf("claude command cancelled: %w", s.ctx.Err())
+		}
+		sendErr := newSendError(err, stdout.String(), stderr.String(), reproduceCommand(folder, claudePath, args))
+		logger.Debugf("sendViaStdin: ERROR exit=%d output: %s", sendErr.ExitCode, sendErr.StderrTail())
+		return "", sendErr
+	}
+
+	logger.Debugf("sendViaStdin: SUCCESS")
+	return ParseStreamResult(stdout.String()), nil
+}
+
+// ParseStreamResult returns the "result" text of the final type=result line in
+// stream-json output, or "" if there is none.
+func ParseStreamResult(output string) string {
+	result := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") || !strings.Contains(line, `"result"`) {
+			continue
+		}
+		var probe struct {
+			Type   string `json:"type"`
+			Result string `json:"result"`
 		}
-		output := stderr.String()
-		if output == "" {
-			output = stdout.String()
+		if err := json.Unmarshal([]byte(line), &probe); err == nil && probe.Type == "result" {
+			result = probe.Result
 		}
-		fmt.Printf("[DEBUG] sendViaStdin: ERROR output: %s\n", output)
-		return fmt.Errorf("claude command failed: %w, output: %s", err, output)
 	}
+	return result
+}
 
-	fmt.Printf("[DEBUG] sendViaStdin: SUCCESS\n")
-	return nil
+// buildStdinPayload builds the stream-json user message piped to the CLI (see buildContentBlocks).
+func buildStdinPayload(message string, attachments []types.Attachment) ([]byte, error) {
+	contentBlocks, err := buildContentBlocks(message, attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the user message payload (stream-json input format)
+	payload := map[string]any{
+		"type": "user",
+		"message": map[string]any{
+			"role":    "user",
+			"content": contentBlocks,
+		},
+	}
+
+	jsonBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return jsonBytes, nil
+}
+
+// buildContentBlocks builds the content of a user message: a text block for the message,
+// base64 image and PDF blocks, and file contents wrapped in <claudefu-file> tags.
+func buildContentBlocks(message string, attachments []types.Attachment) ([]map[string]any, error) {
+	// Build content blocks array
+	contentBlocks := make([]map[string]any, 0, len(attachments)+1)
+
+	// Add text block if message is not empty
+	if message != "" {
+		contentBlocks = append(contentBlocks, map[string]any{
+			"type": "text",
+			"text": message,
+		})
+	}
+
+	// Add attachment blocks (images, files, or PDF documents)
+	for _, att := range attachments {
+		if att.Type == "image" {
+			// Image block - send as base64 image
+			contentBlocks = append(contentBlocks, map[string]any{
+				"type": "image",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": att.MediaType,
+					"data":       att.Data,
+				},
+			})
+		} else if att.Type == "file" {
+			// File block - use unique XML-style delimiter (avoids collision with ``` in content)
+			// Use filePath for the header, fallback to fileName
+			displayPath := att.FilePath
+			if displayPath == "" {
+				displayPath = att.FileName
+			}
+			ext := att.Extension
+			if ext == "" {
+				ext = "txt"
+			}
+			// Format: <claudefu-file path="..." ext="...">content</claudefu-file>
+			// This delimiter won't appear in normal file content
+			fileContent := fmt.Sprintf("\n\n<claudefu-file path=\"%s\" ext=\"%s\">\n%s\n</claudefu-file>", displayPath, ext, att.Data)
+			contentBlocks = append(contentBlocks, map[string]any{
+				"type": "text",
+				"text": fileContent,
+			})
+		} else if att.Type == "document" {
+			// Document block - base64 PDF
+			block := map[string]any{
+				"type": "document",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": att.MediaType,
+					"data":       att.Data,
+				},
+			}
+			if att.FileName != "" {
+				block["title"] = att.FileName
+			}
+			contentBlocks = append(contentBlocks, block)
+		} else {
+			name := att.FileName
+			if name == "" {
+				name = "attachment"
+			}
+			return nil, unsupportedAttachmentError(name, att)
+		}
+	}
+	return contentBlocks, nil
 }
 
+// newSessionPrompt is the first message of every session created by NewSession.
+const newSessionPrompt = "Hello! Starting a new session."
+
 // NewSession creates a new Claude Code session in the specified folder.
 // The model parameter (alias or full ID) is passed to --model verbatim; empty = omit the flag.
 // The effort parameter (low|medium|high|xhigh|max|auto) is passed to --effort; empty = omit.
 // Returns the session ID of the newly created session.
 func (s *ClaudeCodeService) NewSession(folder, model, effort string) (string, error) {
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: folder=%s\n", folder)
+	logger.Debugf("ClaudeCodeService.NewSession: folder=%s", folder)
 
 	if folder == "" {
 		return "", fmt.Errorf("folder is required")
 	}
+	if backend := backendFor(folder); backend != nil {
+		return s.newSessionViaBackend(backend, folder, model)
+	}
 
-	// Get claude binary path
-	path := GetClaudePath()
+	// Get claude binary path (per-agent override or global)
+	path := ClaudePathFor(folder)
 	if path == "" {
 		return "", fmt.Errorf("claude CLI not found in PATH or common locations")
 	}
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: claude path=%s\n", path)
+	logger.Debugf("ClaudeCodeService.NewSession: claude path=%s", path)
 
 	// Start a new session with a simple prompt.
 	// --model/--effort are passed verbatim; empty values fall through to CLI defaults
@@ -693,31 +1055,41 @@ func (s *ClaudeCodeService) NewSession(folder, model, effort string) (string, er
 	// Use --output-format stream-json to parse the session ID from output.
 	// Use acceptEdits to auto-approve file edits in non-interactive mode.
 	// Note: --print with --output-format stream-json requires --verbose.
-	args := []string{
+	args := append(ExtraCLIArgsFor(folder),
 		"--print",
 		"--verbose",
-	}
+	)
 	if model != "" {
-		args = append(args, "--model", model)
+		args = AppendSupportedFlag(args, "--model", model)
 	}
 	if effort != "" {
-		args = append(args, "--effort", effort)
+		args = AppendSupportedFlag(args, "--effort", effort)
 	}
+	args = AppendPermissionModeArg(args, "acceptEdits")
 	args = append(args,
-		"--permission-mode", "acceptEdits",
 		"--output-format", "stream-json",
-		"-p", "Hello! Starting a new session.",
+		"-p", newSessionPrompt,
 	)
 
 	// Add permission args (tools, allowedTools, disallowedTools, add-dir)
-	args = append(args, s.buildPermissionArgs(folder)...)
+	args = append(args, s.buildPermissionArgs(folder, "")...)
 
 	// Add MCP config if configured (enables inter-agent communication)
-	args = append(args, s.getMCPArgs()...)
+	mcpArgs, err := s.getMCPArgs(folder)
+	if err != nil {
+		return "", err
+	}
+	args = append(args, mcpArgs...)
+
+	slot, err := spawnScheduler.Acquire(s.ctx, SpawnRequest{Priority: PriorityInteractive, Kind: "new-session", Folder: folder})
+	if err != nil {
+		return "", fmt.Errorf("claude command cancelled: %w", err)
+	}
+	defer slot.Release()
 
 	cmd := exec.CommandContext(s.ctx, path, args...)
 	cmd.Dir = folder
-	cmd.Env = s.buildEnvironment() // Apply custom env vars (e.g., ANTHROPIC_BASE_URL for proxies)
+	cmd.Env = s.buildEnvironment(folder) // Apply custom env vars (e.g., ANTHROPIC_BASE_URL for proxies)
 
 	// Get stdout to parse session ID
 	stdout, err := cmd.StdoutPipe()
@@ -728,21 +1100,23 @@ func (s *ClaudeCodeService) NewSession(folder, model, effort string) (string, er
 	// Get stderr for debugging
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: failed to create stderr pipe: %v\n", err)
+		logger.Debugf("ClaudeCodeService.NewSession: failed to create stderr pipe: %v", err)
 	}
 
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: starting claude CLI...\n")
+	logger.Debugf("ClaudeCodeService.NewSession: starting claude CLI...")
 	if err := cmd.Start(); err != nil {
-		fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: failed to start: %v\n", err)
+		logger.Debugf("ClaudeCodeService.NewSession: failed to start: %v", err)
 		return "", fmt.Errorf("failed to start claude: %w", err)
 	}
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: claude CLI started, PID=%d\n", cmd.Process.Pid)
+	logger.Debugf("ClaudeCodeService.NewSession: claude CLI started, PID=%d", cmd.Process.Pid)
+	procStart := metrics.ProcessStarted(metrics.ProcessNew)
+	defer metrics.ProcessExited(metrics.ProcessNew, procStart)
 
 	// Read stderr in background
 	go func() {
 		stderrBytes, _ := io.ReadAll(stderr)
 		if len(stderrBytes) > 0 {
-			fmt.Printf("[DEBUG] ClaudeCodeService.NewSession stderr: %s\n", string(stderrBytes))
+			logger.Debugf("ClaudeCodeService.NewSession stderr: %s", string(stderrBytes))
 		}
 	}()
 
@@ -758,37 +1132,37 @@ func (s *ClaudeCodeService) NewSession(folder, model, effort string) (string, er
 			continue
 		}
 
-		fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: line %d: %.100s...\n", lineCount, line)
+		logger.Debugf("ClaudeCodeService.NewSession: line %d: %.100s...", lineCount, line)
 
 		// Classify the streaming event
 		classified, err := types.ClassifyStreamingEvent(line)
 		if err != nil {
-			fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: classify error: %v\n", err)
+			logger.Debugf("ClaudeCodeService.NewSession: classify error: %v", err)
 			continue
 		}
 
-		fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: event type=%s\n", classified.EventType)
+		logger.Debugf("ClaudeCodeService.NewSession: event type=%s", classified.EventType)
 
 		// Extract session_id from any event type
 		if sid := classified.GetSessionID(); sid != "" {
 			sessionId = sid
-			fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: found sessionId=%s\n", sessionId)
+			logger.Debugf("ClaudeCodeService.NewSession: found sessionId=%s", sessionId)
 			// Don't break - continue reading to drain stdout
 		}
 
 		// Stop on result event (success or error)
 		if classified.EventType == types.StreamingEventResultSuccess ||
 			classified.EventType == types.StreamingEventResultError {
-			fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: got result event, stopping scan\n")
+			logger.Debugf("ClaudeCodeService.NewSession: got result event, stopping scan")
 			break
 		}
 	}
 
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: finished scanning, read %d lines\n", lineCount)
+	logger.Debugf("ClaudeCodeService.NewSession: finished scanning, read %d lines", lineCount)
 
 	// Wait for command to complete
 	if err := cmd.Wait(); err != nil {
-		fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: cmd.Wait error: %v\n", err)
+		logger.Debugf("ClaudeCodeService.NewSession: cmd.Wait error: %v", err)
 		// Command may have been cancelled or failed, but we might still have session ID
 		if sessionId == "" {
 			return "", fmt.Errorf("claude command failed: %w", err)
@@ -799,7 +1173,7 @@ func (s *ClaudeCodeService) NewSession(folder, model, effort string) (string, er
 		return "", fmt.Errorf("could not parse session ID from claude output")
 	}
 
-	fmt.Printf("[DEBUG] ClaudeCodeService.NewSession: returning sessionId=%s\n", sessionId)
+	logger.Debugf("ClaudeCodeService.NewSession: returning sessionId=%s", sessionId)
 	return sessionId, nil
 }
 
@@ -813,29 +1187,41 @@ func (s *ClaudeCodeService) RunSlashCommand(folder, sessionId, command string) (
 	if sessionId == "" {
 		return "", fmt.Errorf("sessionId is required")
 	}
+	if provider := ProviderFor(folder); provider != ProviderClaudeCode {
+		return "", fmt.Errorf("slash commands require the claude_code provider (agent uses %s)", provider)
+	}
 
-	path := GetClaudePath()
+	path := ClaudePathFor(folder)
 	if path == "" {
 		return "", fmt.Errorf("claude CLI not found")
 	}
 
-	args := []string{
+	args := append(ExtraCLIArgsFor(folder),
 		"-p",
 		"--resume", sessionId,
 		command,
-	}
+	)
+
+	logger.Debugf("RunSlashCommand: %s %v in %s", path, redactArgs(args), folder)
 
-	fmt.Printf("[DEBUG] RunSlashCommand: %s %v in %s\n", path, args, folder)
+	slot, err := spawnScheduler.Acquire(s.ctx, SpawnRequest{Priority: PriorityInteractive, Kind: "slash-command", Folder: folder, SessionID: sessionId})
+	if err != nil {
+		return "", fmt.Errorf("command cancelled: %w", err)
+	}
+	defer slot.Release()
 
 	cmd := exec.CommandContext(s.ctx, path, args...)
 	cmd.Dir = folder
-	cmd.Env = s.buildEnvironment()
+	cmd.Env = s.buildEnvironment(folder)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	procStart := metrics.ProcessStarted(metrics.ProcessSlash)
+	err = cmd.Run()
+	metrics.ProcessExited(metrics.ProcessSlash, procStart)
+	if err != nil {
 		errOutput := stderr.String()
 		if errOutput == "" {
 			errOutput = stdout.String()
@@ -848,6 +1234,73 @@ func (s *ClaudeCodeService) RunSlashCommand(folder, sessionId, command string) (
 	return output, nil
 }
 
+// PrintOptions tunes a RunPrintWith call.
+type PrintOptions struct {
+	Model     string // --model alias or full ID (empty = CLI default)
+	Ephemeral bool   // Don't save the call as a session in the folder (--no-session-persistence)
+	Nested    bool   // Run for a tool call of a running process (PriorityNested)
+}
+
+// RunPrint runs a stateless one-shot `claude --print` in folder and returns the
+// trimmed response. Used for short helper prompts (descriptions, summaries) that
+// should not touch MCP or spawn subagents.
+func (s *ClaudeCodeService) RunPrint(folder, prompt string) (string, error) {
+	return s.RunPrintWith(folder, prompt, PrintOptions{})
+}
+
+// RunPrintWith is RunPrint with a model override and optional session persistence.
+func (s *ClaudeCodeService) RunPrintWith(folder, prompt string, opts PrintOptions) (string, error) {
+	if folder == "" {
+		return "", fmt.Errorf("folder is required")
+	}
+
+	path := ClaudePathFor(folder)
+	if path == "" {
+		return "", fmt.Errorf("claude CLI not found")
+	}
+
+	args := AppendSupportedFlag(append(ExtraCLIArgsFor(folder), "--print"), "--disallowed-tools", "Task")
+	if opts.Model != "" {
+		args = AppendSupportedFlag(args, "--model", opts.Model)
+	}
+	if opts.Ephemeral {
+		args = AppendSupportedFlag(args, "--no-session-persistence")
+	}
+	args = append(args, "-p", prompt)
+
+	priority := PriorityBackground
+	if opts.Nested {
+		priority = PriorityNested
+	}
+	slot, err := spawnScheduler.Acquire(s.ctx, SpawnRequest{Priority: priority, Kind: "print", Folder: folder})
+	if err != nil {
+		return "", fmt.Errorf("claude command cancelled: %w", err)
+	}
+	defer slot.Release()
+
+	cmd := exec.CommandContext(s.ctx, path, args...)
+	cmd.Dir = folder
+	cmd.Env = s.buildEnvironment(folder)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	procStart := metrics.ProcessStarted(metrics.ProcessOneShot)
+	err = cmd.Run()
+	metrics.ProcessExited(metrics.ProcessOneShot, procStart)
+	rateLimiter.Observe(stdout.String()+stderr.String(), err)
+	if err != nil {
+		errOutput := stderr.String()
+		if errOutput == "" {
+			errOutput = stdout.String()
+		}
+		return "", fmt.Errorf("claude command failed: %w, output: %s", err, errOutput)
+	}
+
+	return strings.TrimSpace(stripANSI(stdout.String())), nil
+}
+
 // stripANSI removes ANSI escape sequences from a string.
 // Handles CSI sequences (colors, cursor movement), OSC sequences (links, titles),
 // and other common terminal escapes.
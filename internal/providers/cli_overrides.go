@@ -0,0 +1,117 @@
+package providers
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// CLIOverride replaces the claude binary and adds CLI args and env vars for one
+// agent folder, e.g. a wrapper script like `claude-proxy` or a client project's
+// own ANTHROPIC_API_KEY. Provider selects a non-CLI backend (see backend.go).
+type CLIOverride struct {
+	Command        string            `json:"command,omitempty"`        // Binary name or path (empty = global command)
+	Args           []string          `json:"args,omitempty"`           // Extra args, added after the global default args
+	Env            map[string]string `json:"env,omitempty"`            // Env vars, applied over the global and profile vars
+	Provider       string            `json:"provider,omitempty"`       // Agent.Provider (empty = claude_code)
+	PermissionMode string            `json:"permissionMode,omitempty"` // Agent.DefaultPermissionMode (empty = acceptEdits)
+	MCPConfig      string            `json:"mcpConfig,omitempty"`      // --mcp-config JSON (empty = the active workspace's server)
+}
+
+var (
+	cliOverridesMu  sync.RWMutex
+	defaultCLIArgs  []string                   // Extra args for every spawned CLI process (from settings)
+	folderOverrides = map[string]CLIOverride{} // folder -> per-agent override
+)
+
+// SetDefaultCLIArgs sets extra args passed to every spawned claude process.
+func SetDefaultCLIArgs(args []string) {
+	cliOverridesMu.Lock()
+	defer cliOverridesMu.Unlock()
+	defaultCLIArgs = slices.Clone(args)
+}
+
+// SetFolderCLIOverride sets the CLI override for an agent folder.
+// An empty override removes it.
+func SetFolderCLIOverride(folder string, override CLIOverride) {
+	cliOverridesMu.Lock()
+	defer cliOverridesMu.Unlock()
+	folder = filepath.Clean(folder)
+	if override.Command == "" && len(override.Args) == 0 && len(override.Env) == 0 && override.Provider == "" && override.PermissionMode == "" && override.MCPConfig == "" {
+		delete(folderOverrides, folder)
+		return
+	}
+	folderOverrides[folder] = CLIOverride{
+		Command:        override.Command,
+		Args:           slices.Clone(override.Args),
+		Env:            maps.Clone(override.Env),
+		Provider:       override.Provider,
+		PermissionMode: override.PermissionMode,
+		MCPConfig:      override.MCPConfig,
+	}
+}
+
+// ClearFolderCLIOverrides removes all per-folder overrides (e.g. on workspace switch).
+func ClearFolderCLIOverrides() {
+	cliOverridesMu.Lock()
+	defer cliOverridesMu.Unlock()
+	folderOverrides = map[string]CLIOverride{}
+}
+
+// ResolveClaudeCommand resolves a binary name or path the way the configured
+// command is resolved (PATH, shell PATH, common install locations).
+// Returns "" if not found.
+func ResolveClaudeCommand(command string) string {
+	return findClaudeBinary(command)
+}
+
+// ClaudePathFor returns the claude binary for an agent folder: the folder's
+// override command if set, otherwise the global GetClaudePath().
+// Returns "" if the binary cannot be found.
+func ClaudePathFor(folder string) string {
+	cliOverridesMu.RLock()
+	override := folderOverrides[filepath.Clean(folder)]
+	cliOverridesMu.RUnlock()
+
+	if override.Command != "" {
+		return findClaudeBinary(override.Command)
+	}
+	return GetClaudePath()
+}
+
+// PermissionModeFor returns the permission mode for sends to an agent folder
+// that don't ask for plan mode: the folder's override if set, otherwise acceptEdits.
+func PermissionModeFor(folder string) string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+	if mode := folderOverrides[filepath.Clean(folder)].PermissionMode; mode != "" {
+		return mode
+	}
+	return "acceptEdits"
+}
+
+// MCPConfigFor returns the --mcp-config JSON set for an agent folder ("" = none).
+func MCPConfigFor(folder string) string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+	return folderOverrides[filepath.Clean(folder)].MCPConfig
+}
+
+// folderEnv returns the env vars set for an agent folder (nil if none).
+func folderEnv(folder string) map[string]string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+	return folderOverrides[filepath.Clean(folder)].Env
+}
+
+// ExtraCLIArgsFor returns the extra args for an agent folder (global defaults,
+// then the folder's own). The result is a fresh slice the caller may append to.
+// Callers put these first so they cannot be swallowed by a variadic flag.
+func ExtraCLIArgsFor(folder string) []string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+
+	args := slices.Clone(defaultCLIArgs)
+	return append(args, folderOverrides[filepath.Clean(folder)].Args...)
+}
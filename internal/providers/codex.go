@@ -0,0 +1,361 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"claudefu/internal/claudehome"
+)
+
+const (
+	// codexDefaultCommand is the OpenAI Codex CLI binary (override per agent via Agent.ClaudeCommand)
+	codexDefaultCommand = "codex"
+	// codexEntryVersion fills the JSONL "version" field of entries the codex backend writes
+	codexEntryVersion = "claudefu-codex"
+)
+
+// codexBackend drives the OpenAI Codex CLI (`codex exec --json`) and adapts
+// its event stream to Claude session JSONL: agent messages become text blocks,
+// reasoning becomes thinking blocks, and command runs, file changes, MCP calls
+// and web searches become tool_use/tool_result pairs. The codex thread ID is
+// stored on every entry (codexThreadId) so the next send resumes the thread.
+type codexBackend struct{}
+
+// codexEvent is one line of `codex exec --json` output.
+type codexEvent struct {
+	Type     string     `json:"type"` // thread.started, item.completed, turn.completed, turn.failed, error
+	ThreadID string     `json:"thread_id"`
+	Item     *codexItem `json:"item"`
+	Usage    *struct {
+		InputTokens       int `json:"input_tokens"`
+		CachedInputTokens int `json:"cached_input_tokens"`
+		OutputTokens      int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Message string `json:"message"`
+}
+
+// codexItem is a completed thread item.
+type codexItem struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"` // agent_message, reasoning, command_execution, file_change, mcp_tool_call, web_search, error
+	Text             string `json:"text"`
+	Command          string `json:"command"`
+	AggregatedOutput string `json:"aggregated_output"`
+	ExitCode         *int   `json:"exit_code"`
+	Status           string `json:"status"`
+	Changes          []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+	} `json:"changes"`
+	Server    string `json:"server"`
+	Tool      string `json:"tool"`
+	Arguments any    `json:"arguments"`
+	Result    any    `json:"result"`
+	Query     string `json:"query"`
+	Message   string `json:"message"`
+}
+
+// NewSession starts a codex thread with req.Message as its first turn.
+func (b *codexBackend) NewSession(ctx context.Context, req BackendRequest) (string, error) {
+	req.SessionID = uuid.New().String()
+	if err := os.MkdirAll(filepath.Dir(claudehome.SessionPath(req.Folder, req.SessionID)), 0755); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if _, err := b.Send(ctx, req); err != nil {
+		return "", err
+	}
+	return req.SessionID, nil
+}
+
+// Send runs one codex turn, resuming the session's codex thread if it has one,
+// and appends the adapted transcript to the session JSONL as events arrive.
+func (b *codexBackend) Send(ctx context.Context, req BackendRequest) (string, error) {
+	prompt, err := codexPrompt(req)
+	if err != nil {
+		return "", err
+	}
+
+	command := codexDefaultCommand
+	if override := folderCommand(req.Folder); override != "" {
+		command = override
+	}
+	codexPath := findClaudeBinary(command)
+	if codexPath == "" {
+		return "", fmt.Errorf("codex CLI not found in PATH or common locations")
+	}
+
+	path := claudehome.SessionPath(req.Folder, req.SessionID)
+	lastUUID, threadID := "", ""
+	err = readSessionEntries(path, func(entry sessionEntry) {
+		if entry.UUID != "" {
+			lastUUID = entry.UUID
+		}
+		if entry.CodexThreadID != "" {
+			threadID = entry.CodexThreadID
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Prompt is read from stdin ("-"), as with the claude stream-json sends
+	args := []string{"exec", "--json", "--skip-git-repo-check", "--cd", req.Folder}
+	if req.Model != "" {
+		args = append(args, "--model", req.Model)
+	}
+	if threadID != "" {
+		args = append(args, "resume", threadID)
+	}
+	args = append(args, "-")
+
+	w := &codexTranscriptWriter{path: path, req: req, parent: lastUUID, threadID: threadID, model: req.Model}
+	start := time.Now()
+	if err := w.append(map[string]any{
+		"type":    "user",
+		"message": map[string]any{"role": "user", "content": prompt},
+	}); err != nil {
+		return "", err
+	}
+
+	logger.Debugf("codex: running %s %v (session %s, thread %q)", codexPath, redactArgs(args), req.SessionID, threadID)
+
+	cmd := exec.CommandContext(ctx, codexPath, args...)
+	cmd.Dir = req.Folder
+	cmd.Env = req.Env
+	cmd.Stdin = strings.NewReader(prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start codex: %w", err)
+	}
+
+	var output strings.Builder
+	result, turnErr := "", ""
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		output.Write(line)
+		output.WriteByte('\n')
+
+		var event codexEvent
+		if json.Unmarshal(line, &event) != nil {
+			continue
+		}
+		switch event.Type {
+		case "thread.started":
+			w.threadID = event.ThreadID
+		case "item.completed":
+			if event.Item == nil {
+				continue
+			}
+			w.flush()
+			if event.Item.Type == "agent_message" {
+				// Held back until the next item or turn.completed, which carries the turn's usage
+				result = event.Item.Text
+				w.pending = event.Item
+				continue
+			}
+			w.record(event.Item)
+		case "turn.completed":
+			if event.Usage != nil {
+				w.usage = map[string]any{
+					"input_tokens":            event.Usage.InputTokens,
+					"cache_read_input_tokens": event.Usage.CachedInputTokens,
+					"output_tokens":           event.Usage.OutputTokens,
+				}
+			}
+			w.flush()
+		case "turn.failed":
+			if event.Error != nil {
+				turnErr = event.Error.Message
+			}
+		case "error":
+			turnErr = event.Message
+		}
+	}
+
+	// A scan error (e.g. an over-long line) stops reading; kill codex so it
+	// doesn't block on a full stdout pipe and Wait can return
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		cmd.Process.Kill()
+	}
+	w.flush()
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("codex command cancelled: %w", ctx.Err())
+	}
+	if scanErr != nil {
+		err = fmt.Errorf("failed to read codex output: %w", scanErr)
+	}
+	if err == nil && turnErr != "" {
+		err = fmt.Errorf("codex turn failed: %s", turnErr)
+	}
+	if err != nil {
+		stderrText := stderr.String()
+		if turnErr != "" {
+			stderrText = strings.TrimSpace(stderrText + "\n" + turnErr)
+		}
+		return "", newSendError(err, output.String(), stderrText, codexPath+" "+strings.Join(redactArgs(args), " "))
+	}
+
+	if err := w.append(map[string]any{
+		"type":       "system",
+		"subtype":    "turn_duration",
+		"durationMs": time.Since(start).Milliseconds(),
+		"isMeta":     false,
+	}); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// codexPrompt flattens a message and its file attachments into one prompt.
+// Images and PDFs are rejected: codex exec only takes image files by path.
+func codexPrompt(req BackendRequest) (string, error) {
+	for _, att := range req.Attachments {
+		if att.Type != "file" {
+			return "", fmt.Errorf("the codex provider only supports file attachments (got %s)", att.Type)
+		}
+	}
+	blocks, err := buildContentBlocks(req.Message, req.Attachments)
+	if err != nil {
+		return "", err
+	}
+	var prompt strings.Builder
+	for _, block := range blocks {
+		text, _ := block["text"].(string)
+		prompt.WriteString(text)
+	}
+	return prompt.String(), nil
+}
+
+// folderCommand returns the agent folder's binary override ("" if none).
+func folderCommand(folder string) string {
+	cliOverridesMu.RLock()
+	defer cliOverridesMu.RUnlock()
+	return folderOverrides[filepath.Clean(folder)].Command
+}
+
+// codexTranscriptWriter appends adapted codex items to a session JSONL,
+// chaining parentUuid and tagging each entry with the codex thread ID.
+type codexTranscriptWriter struct {
+	path     string
+	req      BackendRequest
+	parent   string
+	threadID string
+	model    string
+	usage    map[string]any // Turn usage, attached to the final agent message
+	pending  *codexItem     // Agent message waiting for the next item or the turn's usage
+}
+
+// append writes one entry and makes it the parent of the next.
+func (w *codexTranscriptWriter) append(fields map[string]any) error {
+	if w.threadID != "" {
+		fields["codexThreadId"] = w.threadID
+	}
+	entryUUID := uuid.New().String()
+	if err := appendSessionEntry(w.path, w.req, codexEntryVersion, w.parent, entryUUID, fields); err != nil {
+		return err
+	}
+	w.parent = entryUUID
+	return nil
+}
+
+// assistant writes an assistant entry holding content.
+func (w *codexTranscriptWriter) assistant(itemID string, content []map[string]any) error {
+	model := w.model
+	if model == "" {
+		model = "codex"
+	}
+	message := map[string]any{
+		"id":          "codex_" + itemID,
+		"type":        "message",
+		"role":        "assistant",
+		"model":       model,
+		"content":     content,
+		"stop_reason": nil,
+	}
+	if w.usage != nil {
+		message["usage"] = w.usage
+	}
+	return w.append(map[string]any{"type": "assistant", "message": message})
+}
+
+// toolCall writes a tool_use entry and its tool_result.
+func (w *codexTranscriptWriter) toolCall(itemID, name string, input any, result string, isError bool) error {
+	toolUseID := "codex_" + itemID
+	if err := w.assistant(itemID, []map[string]any{{"type": "tool_use", "id": toolUseID, "name": name, "input": input}}); err != nil {
+		return err
+	}
+	return w.append(map[string]any{
+		"type": "user",
+		"message": map[string]any{"role": "user", "content": []map[string]any{{
+			"type":        "tool_result",
+			"tool_use_id": toolUseID,
+			"content":     result,
+			"is_error":    isError,
+		}}},
+	})
+}
+
+// record writes one item, logging (not failing the turn) on write errors.
+func (w *codexTranscriptWriter) record(item *codexItem) {
+	if err := w.appendItem(item); err != nil {
+		logger.Warnf("codex: failed to record %s item: %v", item.Type, err)
+	}
+}
+
+// flush writes the held-back agent message, if any.
+func (w *codexTranscriptWriter) flush() {
+	if w.pending != nil {
+		w.record(w.pending)
+		w.pending = nil
+	}
+}
+
+// appendItem adapts one completed codex item. Unknown item types are skipped.
+func (w *codexTranscriptWriter) appendItem(item *codexItem) error {
+	switch item.Type {
+	case "agent_message":
+		return w.assistant(item.ID, []map[string]any{{"type": "text", "text": item.Text}})
+	case "reasoning":
+		return w.assistant(item.ID, []map[string]any{{"type": "thinking", "thinking": item.Text, "signature": ""}})
+	case "command_execution":
+		failed := item.Status == "failed" || (item.ExitCode != nil && *item.ExitCode != 0)
+		return w.toolCall(item.ID, "Bash", map[string]any{"command": item.Command}, item.AggregatedOutput, failed)
+	case "file_change":
+		var lines []string
+		for _, change := range item.Changes {
+			lines = append(lines, change.Kind+" "+change.Path)
+		}
+		return w.toolCall(item.ID, "FileChange", map[string]any{"changes": item.Changes}, strings.Join(lines, "\n"), item.Status == "failed")
+	case "mcp_tool_call":
+		result, _ := json.Marshal(item.Result)
+		return w.toolCall(item.ID, "mcp__"+item.Server+"__"+item.Tool, item.Arguments, string(result), item.Status == "failed")
+	case "web_search":
+		return w.toolCall(item.ID, "WebSearch", map[string]any{"query": item.Query}, "", false)
+	case "error":
+		return w.assistant(item.ID, []map[string]any{{"type": "text", "text": "codex error: " + item.Message}})
+	}
+	return nil
+}
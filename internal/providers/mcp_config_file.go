@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"claudefu/internal/fsutil"
+)
+
+// =============================================================================
+// MCP CONFIG FILES
+// The --mcp-config JSON carries the MCP bearer token, and argv is readable by
+// every local user (ps), so the CLI gets the path of a 0600 file holding it
+// instead. Each distinct config is written once, named by its hash; the
+// directory is emptied at startup to drop files holding old tokens.
+// =============================================================================
+
+var (
+	mcpConfigFilesMu sync.Mutex
+	mcpConfigDir     string
+	mcpConfigFiles   = map[string]string{} // config JSON -> file path
+)
+
+// SetMCPConfigDir sets the directory MCP config files are written to (under
+// local/, so it is neither synced nor backed up) and removes the files left
+// there by earlier runs.
+func SetMCPConfigDir(dir string) {
+	mcpConfigFilesMu.Lock()
+	defer mcpConfigFilesMu.Unlock()
+	mcpConfigDir = dir
+	mcpConfigFiles = map[string]string{}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
+// mcpConfigPath returns the path of a 0600 file holding config, writing it
+// if needed.
+func mcpConfigPath(config string) (string, error) {
+	mcpConfigFilesMu.Lock()
+	defer mcpConfigFilesMu.Unlock()
+	if mcpConfigDir == "" {
+		return "", errors.New("MCP config directory not set")
+	}
+	if path, ok := mcpConfigFiles[config]; ok {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	if err := os.MkdirAll(mcpConfigDir, 0700); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(config))
+	path := filepath.Join(mcpConfigDir, hex.EncodeToString(sum[:8])+".json")
+	if err := fsutil.WriteFileAtomic(path, []byte(config), 0600); err != nil {
+		return "", err
+	}
+	mcpConfigFiles[config] = path
+	return path, nil
+}
@@ -0,0 +1,77 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMCPConfigPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "local", "mcp")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(stale, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	SetMCPConfigDir(dir)
+	defer SetMCPConfigDir("")
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale config file kept (stat err %v)", err)
+	}
+
+	config := MCPConfigJSON("127.0.0.1", 9315, "secret-token")
+	path, err := mcpConfigPath(config)
+	if err != nil {
+		t.Fatalf("mcpConfigPath: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("mode = %o, want 600", perm)
+	}
+	if data, _ := os.ReadFile(path); string(data) != config {
+		t.Errorf("file = %s, want %s", data, config)
+	}
+
+	// Same config reuses the file, and a removed file is rewritten
+	os.Remove(path)
+	again, err := mcpConfigPath(config)
+	if err != nil || again != path {
+		t.Fatalf("second mcpConfigPath = %q, %v; want %q", again, err, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file not rewritten: %v", err)
+	}
+	if other, _ := mcpConfigPath(MCPConfigJSON("127.0.0.1", 9315, "rotated")); other == path {
+		t.Error("different configs share a file")
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{
+			[]string{"--mcp-config", `{"mcpServers":{"claudefu":{"headers":{"Authorization":"Bearer abc123"}}}}`},
+			[]string{"--mcp-config", `{"mcpServers":{"claudefu":{"headers":{"Authorization":"Bearer ***"}}}}`},
+		},
+		{[]string{"-H", "Authorization: Bearer abc.def-1"}, []string{"-H", "Authorization: Bearer ***"}},
+		{[]string{"--resume", "s1", "--model", "opus"}, []string{"--resume", "s1", "--model", "opus"}},
+	}
+	for _, tt := range tests {
+		if got := redactArgs(tt.args); !slices.Equal(got, tt.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+	command := reproduceCommand("/src/api", "claude", []string{"--mcp-config", `{"Authorization":"Bearer abc123"}`})
+	if strings.Contains(command, "abc123") {
+		t.Errorf("reproduceCommand leaks the token: %s", command)
+	}
+}
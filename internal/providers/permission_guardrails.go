@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"claudefu/internal/permissions"
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// DANGEROUS-PERMISSION GUARDRAILS
+// YOLO-tier patterns (force pushes, blanket rm, anything in a set's YOLO tier)
+// that end up in an agent's --allowedTools are withheld until the user
+// acknowledges them for that agent folder. Until then the CLI prompts for
+// those commands as usual, and a permissions:dangerous event is emitted once
+// per folder and set of withheld patterns so the UI can ask for confirmation.
+// The bypassPermissions mode skips every check, so it is YOLO-tier as well:
+// until BypassPermissionsAck is acknowledged, sends use acceptEdits instead.
+// =============================================================================
+
+// EffectivePermissions is what a spawned claude process gets for an agent
+// folder: the exact flag values, plus the patterns withheld from AllowedTools.
+type EffectivePermissions struct {
+	Tools           string   `json:"tools,omitempty"`           // --tools value
+	AllowedTools    string   `json:"allowedTools,omitempty"`    // --allowedTools value
+	DisallowedTools string   `json:"disallowedTools,omitempty"` // --disallowedTools value
+	AddDirs         []string `json:"addDirs,omitempty"`         // One --add-dir each
+	PermissionMode  string   `json:"permissionMode"`            // --permission-mode value outside plan mode
+	Withheld        []string `json:"withheld,omitempty"`        // YOLO-tier patterns awaiting acknowledgement
+	Args            []string `json:"args"`                      // The flags as passed (unsupported ones omitted)
+}
+
+// args builds the CLI flags for the compiled values.
+func (e EffectivePermissions) args() []string {
+	var args []string
+	if e.Tools != "" {
+		args = AppendSupportedFlag(args, "--tools", e.Tools)
+	}
+	if e.AllowedTools != "" {
+		args = AppendSupportedFlag(args, "--allowedTools", e.AllowedTools)
+	}
+	if e.DisallowedTools != "" {
+		args = AppendSupportedFlag(args, "--disallowedTools", e.DisallowedTools)
+	}
+	for _, dir := range e.AddDirs {
+		args = AppendSupportedFlag(args, "--add-dir", dir)
+	}
+	return args
+}
+
+// BypassPermissionsAck is the acknowledgement pattern for an agent's
+// bypassPermissions default permission mode.
+const BypassPermissionsAck = "permissionMode:" + types.PermissionModeBypassPermissions
+
+var (
+	dangerousMu     sync.Mutex
+	dangerousAcks   = map[string][]string{} // folder -> acknowledged YOLO-tier patterns
+	dangerousWarned = map[string]string{}   // folder -> withheld patterns last warned about
+)
+
+// SetDangerousAcknowledgements sets the YOLO-tier patterns the user has
+// acknowledged, per agent folder (from settings).
+func SetDangerousAcknowledgements(acks map[string][]string) {
+	dangerousMu.Lock()
+	defer dangerousMu.Unlock()
+	dangerousAcks = make(map[string][]string, len(acks))
+	for folder, patterns := range acks {
+		dangerousAcks[filepath.Clean(folder)] = slices.Clone(patterns)
+	}
+}
+
+// withholdDangerous splits allow into the patterns to pass and the YOLO-tier
+// patterns folder has not acknowledged.
+func withholdDangerous(folder string, perms *permissions.ClaudeFuPermissions, allow []string) (kept, withheld []string) {
+	dangerous := permissions.FindDangerous(perms, allow)
+	if len(dangerous) == 0 {
+		return allow, nil
+	}
+	dangerousMu.Lock()
+	acked := dangerousAcks[filepath.Clean(folder)]
+	dangerousMu.Unlock()
+
+	for _, t := range allow {
+		if slices.Contains(dangerous, t) && !slices.Contains(acked, t) {
+			withheld = append(withheld, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, withheld
+}
+
+// guardPermissionMode returns the permission mode to pass for folder, and
+// whether bypassPermissions was withheld (replaced by acceptEdits) because
+// folder has not acknowledged BypassPermissionsAck.
+func guardPermissionMode(folder, mode string) (string, bool) {
+	if mode != types.PermissionModeBypassPermissions {
+		return mode, false
+	}
+	dangerousMu.Lock()
+	acked := slices.Contains(dangerousAcks[filepath.Clean(folder)], BypassPermissionsAck)
+	dangerousMu.Unlock()
+	if acked {
+		return mode, false
+	}
+	return types.PermissionModeAcceptEdits, true
+}
+
+// warnDangerous emits permissions:dangerous for withheld patterns, once per
+// folder until the set of withheld patterns changes.
+func (s *ClaudeCodeService) warnDangerous(folder string, withheld []string) {
+	key := strings.Join(withheld, ",")
+	folder = filepath.Clean(folder)
+	dangerousMu.Lock()
+	if dangerousWarned[folder] == key {
+		dangerousMu.Unlock()
+		return
+	}
+	dangerousWarned[folder] = key
+	dangerousMu.Unlock()
+
+	if len(withheld) == 0 {
+		return
+	}
+	logger.Warnf("Withholding %d unacknowledged YOLO-tier permissions for %s: %s", len(withheld), folder, key)
+	if s.emitFunc != nil {
+		s.emitFunc("permissions:dangerous", map[string]any{"folder": folder, "patterns": withheld})
+	}
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"slices"
+	"testing"
+
+	"claudefu/internal/permissions"
+	"claudefu/internal/types"
+)
+
+func TestGuardPermissionMode(t *testing.T) {
+	SetDangerousAcknowledgements(map[string][]string{"/src/acked": {BypassPermissionsAck}})
+	defer SetDangerousAcknowledgements(nil)
+
+	tests := []struct {
+		folder       string
+		mode         string
+		wantMode     string
+		wantWithheld bool
+	}{
+		{"/src/api", types.PermissionModeAcceptEdits, types.PermissionModeAcceptEdits, false},
+		{"/src/api", types.PermissionModeDefault, types.PermissionModeDefault, false},
+		{"/src/api", types.PermissionModeBypassPermissions, types.PermissionModeAcceptEdits, true},
+		{"/src/acked", types.PermissionModeBypassPermissions, types.PermissionModeBypassPermissions, false},
+		{"/src/acked/", types.PermissionModeBypassPermissions, types.PermissionModeBypassPermissions, false},
+	}
+	for _, tt := range tests {
+		mode, withheld := guardPermissionMode(tt.folder, tt.mode)
+		if mode != tt.wantMode || withheld != tt.wantWithheld {
+			t.Errorf("guardPermissionMode(%q, %q) = %q, %v; want %q, %v", tt.folder, tt.mode, mode, withheld, tt.wantMode, tt.wantWithheld)
+		}
+	}
+}
+
+func TestWithholdDangerous(t *testing.T) {
+	SetDangerousAcknowledgements(map[string][]string{"/src/acked": {"Bash(git push --force:*)"}})
+	defer SetDangerousAcknowledgements(nil)
+
+	perms := &permissions.ClaudeFuPermissions{
+		ToolPermissions: map[string]permissions.ToolPermission{
+			"deploy": {YOLO: []string{"Bash(make deploy:*)"}},
+		},
+	}
+	allow := []string{"Read", "Bash(go test:*)", "Bash(git push --force:*)", "Bash(make deploy:*)"}
+
+	tests := []struct {
+		name         string
+		folder       string
+		perms        *permissions.ClaudeFuPermissions
+		wantKept     []string
+		wantWithheld []string
+	}{
+		{"pattern and set YOLO tier", "/src/api", perms, []string{"Read", "Bash(go test:*)"}, []string{"Bash(git push --force:*)", "Bash(make deploy:*)"}},
+		{"acknowledged pattern passes", "/src/acked", perms, []string{"Read", "Bash(go test:*)", "Bash(git push --force:*)"}, []string{"Bash(make deploy:*)"}},
+		{"no sets", "/src/api", nil, []string{"Read", "Bash(go test:*)", "Bash(make deploy:*)"}, []string{"Bash(git push --force:*)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, withheld := withholdDangerous(tt.folder, tt.perms, allow)
+			if !slices.Equal(kept, tt.wantKept) || !slices.Equal(withheld, tt.wantWithheld) {
+				t.Errorf("withholdDangerous = %v, %v; want %v, %v", kept, withheld, tt.wantKept, tt.wantWithheld)
+			}
+		})
+	}
+}
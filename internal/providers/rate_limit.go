@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/metrics"
+)
+
+// =============================================================================
+// RATE LIMIT COORDINATOR
+// Every agent shares one Anthropic account, so when one claude process hits a
+// 429 or overloaded_error the others are about to. The coordinator turns those
+// failures into an app-wide cooldown with exponential backoff; while it lasts
+// the spawn scheduler holds new processes in its queue. Any successful run
+// resets the backoff.
+// =============================================================================
+
+const (
+	rateLimitBaseBackoff = 15 * time.Second
+	rateLimitMaxBackoff  = 5 * time.Minute
+)
+
+// rateLimitMarkers are output fragments (lowercased) that mean the API
+// rejected the request for rate or capacity reasons.
+var rateLimitMarkers = []string{
+	`"api_error_status":429`,
+	`"api_error_status":529`,
+	"rate_limit_error",
+	"overloaded_error",
+	"429 too many requests",
+}
+
+// RateLimitStatus is a snapshot of the coordinator, also pushed as ratelimit:status.
+type RateLimitStatus struct {
+	Limited  bool      `json:"limited"`
+	Until    time.Time `json:"until,omitempty"` // Cooldown deadline
+	Failures int       `json:"failures"`        // Consecutive rate-limited runs
+	Backoff  string    `json:"backoff,omitempty"`
+}
+
+// RateLimitCoordinator tracks rate-limit failures across all claude processes.
+type RateLimitCoordinator struct {
+	mu       sync.Mutex
+	failures int
+	until    time.Time
+	timer    *time.Timer
+	onChange func(RateLimitStatus)
+}
+
+// NewRateLimitCoordinator creates a coordinator with no cooldown.
+func NewRateLimitCoordinator() *RateLimitCoordinator {
+	return &RateLimitCoordinator{}
+}
+
+// SetOnChange sets a function called when a cooldown starts, is extended, or ends.
+func (c *RateLimitCoordinator) SetOnChange(fn func(RateLimitStatus)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// IsRateLimitOutput reports whether claude output shows a 429 or overloaded API error.
+func IsRateLimitOutput(output string) bool {
+	lower := strings.ToLower(output)
+	for _, marker := range rateLimitMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Observe records the outcome of a claude run: a rate-limited failure starts
+// (or doubles) the cooldown, a success resets the backoff. Other failures are
+// ignored. Returns true if output was rate-limited.
+func (c *RateLimitCoordinator) Observe(output string, err error) bool {
+	if err == nil {
+		c.reset()
+		return false
+	}
+	if !IsRateLimitOutput(output) {
+		return false
+	}
+	metrics.RateLimitHit()
+
+	c.mu.Lock()
+	c.failures++
+	backoff := min(rateLimitBaseBackoff<<min(c.failures-1, 10), rateLimitMaxBackoff)
+	until := time.Now().Add(backoff)
+	if until.After(c.until) {
+		c.until = until
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(time.Until(c.until), c.expire)
+	status := c.statusLocked()
+	fn := c.onChange
+	c.mu.Unlock()
+
+	spawnScheduler.PauseUntil(status.Until)
+	if fn != nil {
+		fn(status)
+	}
+	return true
+}
+
+// Status returns the current cooldown.
+func (c *RateLimitCoordinator) Status() RateLimitStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statusLocked()
+}
+
+func (c *RateLimitCoordinator) statusLocked() RateLimitStatus {
+	status := RateLimitStatus{Failures: c.failures}
+	if time.Now().Before(c.until) {
+		status.Limited = true
+		status.Until = c.until
+		status.Backoff = time.Until(c.until).Round(time.Second).String()
+	}
+	return status
+}
+
+// reset clears the backoff after a successful run. An active cooldown still
+// runs out; only the next failure starts again from the base backoff.
+func (c *RateLimitCoordinator) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = 0
+}
+
+// expire announces the end of a cooldown.
+func (c *RateLimitCoordinator) expire() {
+	c.mu.Lock()
+	if time.Now().Before(c.until) {
+		c.mu.Unlock()
+		return // Extended since the timer was armed
+	}
+	c.timer = nil
+	status := c.statusLocked()
+	fn := c.onChange
+	c.mu.Unlock()
+	if fn != nil {
+		fn(status)
+	}
+}
+
+// rateLimiter is the app-wide coordinator fed by every claude process ClaudeFu spawns.
+var rateLimiter = NewRateLimitCoordinator()
+
+// RateLimits returns the app-wide rate-limit coordinator.
+func RateLimits() *RateLimitCoordinator {
+	return rateLimiter
+}
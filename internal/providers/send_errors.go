@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Send failure causes reported by ClassifySendFailure
+const (
+	FailureAuth       = "auth"
+	FailureRateLimit  = "rate_limit"
+	FailureNetwork    = "network"
+	FailurePermission = "permission"
+	FailureUnknown    = "unknown"
+)
+
+// stderrTailBytes bounds SendError.StderrTail.
+const stderrTailBytes = 4096
+
+// SendError is returned when the claude process for a send exits with an error.
+// Its message keeps the raw output so existing error-string parsing still works.
+type SendError struct {
+	ExitCode int    // Process exit code (-1 if it was killed or never exited normally)
+	Stderr   string // Full stderr
+	Stdout   string // Full stdout (stream-json; holds the result line on API errors)
+	Command  string // Reproduce command; the stream-json message it reads from stdin is not included
+	Err      error
+}
+
+func (e *SendError) Error() string {
+	output := e.Stderr
+	if output == "" {
+		output = e.Stdout
+	}
+	return fmt.Sprintf("claude command failed: %v, output: %s", e.Err, output)
+}
+
+func (e *SendError) Unwrap() error { return e.Err }
+
+// StderrTail returns the last few KB of stderr (stdout if stderr is empty).
+func (e *SendError) StderrTail() string {
+	output := e.Stderr
+	if strings.TrimSpace(output) == "" {
+		output = e.Stdout
+	}
+	if len(output) > stderrTailBytes {
+		output = "..." + output[len(output)-stderrTailBytes:]
+	}
+	return strings.TrimSpace(output)
+}
+
+func newSendError(err error, stdout, stderr, command string) *SendError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &SendError{ExitCode: exitCode, Stderr: stderr, Stdout: stdout, Command: command, Err: err}
+}
+
+// failurePatterns maps lowercase output fragments to a cause, checked in order.
+var failurePatterns = []struct {
+	cause     string
+	fragments []string
+}{
+	{FailureAuth, []string{"authentication_failed", "oauth token has expired", "invalid api key", "invalid x-api-key", "please run /login", "not logged in"}},
+	{FailureRateLimit, []string{"rate_limit", "rate limit", "usage limit", "overloaded"}},
+	{FailureNetwork, []string{"econnrefused", "econnreset", "enotfound", "etimedout", "getaddrinfo", "fetch failed", "connection refused", "connection reset", "socket hang up", "network error"}},
+	{FailurePermission, []string{"permission denied", "eacces", "eperm", "operation not permitted"}},
+}
+
+// ClassifySendFailure guesses why a send failed from its output and the API
+// status code (0 if unknown).
+func ClassifySendFailure(output string, status int) string {
+	switch {
+	case status == 401 || status == 403:
+		return FailureAuth
+	case status == 429 || status == 529:
+		return FailureRateLimit
+	}
+	lower := strings.ToLower(output)
+	for _, p := range failurePatterns {
+		for _, fragment := range p.fragments {
+			if strings.Contains(lower, fragment) {
+				return p.cause
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// shellQuote quotes s for a POSIX shell if needed.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// bearerPattern matches a bearer credential inside an argument.
+var bearerPattern = regexp.MustCompile(`(Bearer\s+)[^\s"'\\]+`)
+
+// redactArgs returns args with bearer tokens replaced by ***, for command
+// lines that are logged, emitted as events, or shown to the user.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = bearerPattern.ReplaceAllString(arg, "${1}***")
+	}
+	return redacted
+}
+
+// reproduceCommand renders a command line that reruns a send from a terminal
+// (with credentials redacted).
+func reproduceCommand(folder, claudePath string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(claudePath))
+	for _, arg := range redactArgs(args) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return fmt.Sprintf("cd %s && %s < message.jsonl", shellQuote(folder), strings.Join(parts, " "))
+}
@@ -0,0 +1,186 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// PER-SESSION SEND QUEUE
+// Two claude processes resuming the same session race on its JSONL file, so
+// sends to a session run one at a time. A send made while an earlier one is
+// still running waits here (before the spawn scheduler) and can be cancelled
+// until it starts. Waiters get queue:position events: 1-based position while
+// waiting, 0 when started, -1 when cancelled.
+// =============================================================================
+
+// ErrSendCancelled is returned by SendMessage when its queued message is cancelled.
+var ErrSendCancelled = errors.New("queued message cancelled before it was sent")
+
+// QueuedSend is a message waiting for an earlier send to the same session.
+type QueuedSend struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"sessionId"`
+	Preview   string    `json:"preview"` // First line of the message, truncated
+	QueuedAt  time.Time `json:"queuedAt"`
+}
+
+type sendWaiter struct {
+	QueuedSend
+	ready     chan struct{}
+	cancelled chan struct{}
+}
+
+// queuePreviewLen bounds QueuedSend.Preview.
+const queuePreviewLen = 120
+
+// acquireSendTurn waits until no other send to sessionID is running. The
+// returned release hands the session to the next queued send.
+func (s *ClaudeCodeService) acquireSendTurn(sessionID, message string) (func(), error) {
+	s.sendQueueMu.Lock()
+	if !s.busySessions[sessionID] {
+		s.busySessions[sessionID] = true
+		s.sendQueueMu.Unlock()
+		return func() { s.releaseSendTurn(sessionID) }, nil
+	}
+	w := &sendWaiter{
+		QueuedSend: QueuedSend{
+			ID:        uuid.New().String(),
+			SessionID: sessionID,
+			Preview:   queuePreview(message),
+			QueuedAt:  time.Now(),
+		},
+		ready:     make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	s.sendQueues[sessionID] = append(s.sendQueues[sessionID], w)
+	s.sendQueueMu.Unlock()
+	logger.Debugf("acquireSendTurn: session %s busy, queued send %s", sessionID, w.ID[:8])
+	s.emitQueuePositions(sessionID)
+
+	select {
+	case <-w.ready:
+		s.emitQueueEvent(w.QueuedSend, 0)
+		return func() { s.releaseSendTurn(sessionID) }, nil
+	case <-w.cancelled:
+		s.emitQueueEvent(w.QueuedSend, -1)
+		return nil, ErrSendCancelled
+	case <-s.ctx.Done():
+		s.sendQueueMu.Lock()
+		removed := s.removeQueuedLocked(sessionID, w.ID)
+		s.sendQueueMu.Unlock()
+		if !removed {
+			select {
+			case <-w.ready:
+				// Handed the turn just as the app shut down
+				s.releaseSendTurn(sessionID)
+			default:
+			}
+		}
+		return nil, fmt.Errorf("claude command cancelled: %w", s.ctx.Err())
+	}
+}
+
+// releaseSendTurn starts the next queued send for sessionID, if any.
+func (s *ClaudeCodeService) releaseSendTurn(sessionID string) {
+	s.sendQueueMu.Lock()
+	queue := s.sendQueues[sessionID]
+	if len(queue) == 0 {
+		delete(s.busySessions, sessionID)
+		delete(s.sendQueues, sessionID)
+		s.sendQueueMu.Unlock()
+		return
+	}
+	next := queue[0]
+	s.sendQueues[sessionID] = queue[1:]
+	close(next.ready)
+	s.sendQueueMu.Unlock()
+	s.emitQueuePositions(sessionID)
+}
+
+// SessionBusy reports whether a send to sessionID is running or queued.
+func (s *ClaudeCodeService) SessionBusy(sessionID string) bool {
+	s.sendQueueMu.Lock()
+	defer s.sendQueueMu.Unlock()
+	return s.busySessions[sessionID]
+}
+
+// QueuedSends returns the messages waiting for sessionID, in send order.
+func (s *ClaudeCodeService) QueuedSends(sessionID string) []QueuedSend {
+	s.sendQueueMu.Lock()
+	defer s.sendQueueMu.Unlock()
+	result := make([]QueuedSend, 0, len(s.sendQueues[sessionID]))
+	for _, w := range s.sendQueues[sessionID] {
+		result = append(result, w.QueuedSend)
+	}
+	return result
+}
+
+// CancelQueuedSend cancels a queued (not yet started) send; an empty sendID
+// cancels every queued send for the session. Returns how many were cancelled.
+func (s *ClaudeCodeService) CancelQueuedSend(sessionID, sendID string) int {
+	s.sendQueueMu.Lock()
+	var kept, cancelled []*sendWaiter
+	for _, w := range s.sendQueues[sessionID] {
+		if sendID == "" || w.ID == sendID {
+			cancelled = append(cancelled, w)
+		} else {
+			kept = append(kept, w)
+		}
+	}
+	if len(cancelled) > 0 {
+		s.sendQueues[sessionID] = kept
+	}
+	s.sendQueueMu.Unlock()
+
+	for _, w := range cancelled {
+		close(w.cancelled)
+	}
+	if len(cancelled) > 0 {
+		s.emitQueuePositions(sessionID)
+	}
+	return len(cancelled)
+}
+
+// removeQueuedLocked drops a waiter from its session queue. Caller must hold s.sendQueueMu.
+func (s *ClaudeCodeService) removeQueuedLocked(sessionID, sendID string) bool {
+	queue := s.sendQueues[sessionID]
+	for i, w := range queue {
+		if w.ID == sendID {
+			s.sendQueues[sessionID] = append(queue[:i:i], queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// emitQueuePositions emits queue:position for every send still waiting on sessionID.
+func (s *ClaudeCodeService) emitQueuePositions(sessionID string) {
+	for i, q := range s.QueuedSends(sessionID) {
+		s.emitQueueEvent(q, i+1)
+	}
+}
+
+func (s *ClaudeCodeService) emitQueueEvent(q QueuedSend, position int) {
+	if s.emitFunc == nil {
+		return
+	}
+	s.emitFunc("queue:position", map[string]any{
+		"sessionId": q.SessionID,
+		"sendId":    q.ID,
+		"preview":   q.Preview,
+		"position":  position,
+	})
+}
+
+func queuePreview(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if len(line) > queuePreviewLen {
+		line = line[:queuePreviewLen] + "..."
+	}
+	return line
+}
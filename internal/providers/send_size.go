@@ -0,0 +1,253 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"regexp"
+	"slices"
+	"strings"
+
+	"claudefu/internal/types"
+)
+
+// Default send limits. Images follow the Anthropic API's per-image limit; the
+// total stays under the API's 32 MB request cap with room for the session history.
+const (
+	DefaultMaxImageBytes  = 5 * 1024 * 1024
+	DefaultMaxFileBytes   = 1024 * 1024
+	DefaultMaxPDFBytes    = 20 * 1024 * 1024
+	DefaultMaxSendBytes   = 30 * 1024 * 1024
+	DefaultSendWarnTokens = 100000
+
+	// Images are downscaled by the API to roughly 1.15 megapixels (~1600 tokens)
+	maxImageTokens = 1600
+
+	// PDFs are sent as text plus an image of every page; the API accepts up to 100 pages
+	pdfTokensPerPage = 2000
+	maxPDFPages      = 100
+)
+
+// SupportedImageMediaTypes are the image types the API accepts.
+var SupportedImageMediaTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// SupportedDocumentMediaTypes are the document types the API accepts.
+var SupportedDocumentMediaTypes = []string{"application/pdf"}
+
+// SendSizeLimits bounds a single send. Zero fields fall back to the defaults.
+type SendSizeLimits struct {
+	MaxImageBytes  int `json:"maxImageBytes"`  // Decoded size per image
+	MaxFileBytes   int `json:"maxFileBytes"`   // Content size per file attachment
+	MaxPDFBytes    int `json:"maxPdfBytes"`    // Decoded size per PDF document
+	MaxSendBytes   int `json:"maxSendBytes"`   // Whole stream-json payload piped to the CLI
+	SendWarnTokens int `json:"sendWarnTokens"` // Warn (not reject) above this many estimated tokens
+}
+
+// AttachmentEstimate is the estimated cost of one attachment.
+type AttachmentEstimate struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Bytes  int    `json:"bytes"`  // Decoded image/PDF bytes or raw file content bytes
+	Tokens int    `json:"tokens"` // Approximate input tokens
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Pages  int    `json:"pages,omitempty"` // PDF page count (approximate)
+	Error  string `json:"error,omitempty"` // Set when this attachment would be rejected
+}
+
+// SendSizeEstimate reports the approximate cost of a send before the CLI is spawned.
+// OK is false when any limit is exceeded; Errors explains why.
+type SendSizeEstimate struct {
+	MessageBytes  int                  `json:"messageBytes"`
+	MessageTokens int                  `json:"messageTokens"`
+	Attachments   []AttachmentEstimate `json:"attachments"`
+	PayloadBytes  int                  `json:"payloadBytes"` // Size of the stream-json stdin payload
+	TotalTokens   int                  `json:"totalTokens"`
+	Warnings      []string             `json:"warnings,omitempty"`
+	Errors        []string             `json:"errors,omitempty"`
+	OK            bool                 `json:"ok"`
+}
+
+// Err returns the estimate's rejection as an error, or nil if the send is within limits.
+func (e SendSizeEstimate) Err() error {
+	if e.OK {
+		return nil
+	}
+	return fmt.Errorf("message too large to send: %s", strings.Join(e.Errors, "; "))
+}
+
+// withDefaults fills zero limits with the package defaults.
+func (l SendSizeLimits) withDefaults() SendSizeLimits {
+	if l.MaxImageBytes <= 0 {
+		l.MaxImageBytes = DefaultMaxImageBytes
+	}
+	if l.MaxFileBytes <= 0 {
+		l.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if l.MaxPDFBytes <= 0 {
+		l.MaxPDFBytes = DefaultMaxPDFBytes
+	}
+	if l.MaxSendBytes <= 0 {
+		l.MaxSendBytes = DefaultMaxSendBytes
+	}
+	if l.SendWarnTokens <= 0 {
+		l.SendWarnTokens = DefaultSendWarnTokens
+	}
+	return l
+}
+
+// EstimateSendSize estimates the byte and token cost of sending message with attachments
+// and checks it against limits. Text is estimated at ~4 bytes per token; images by their
+// pixel count (width*height/750, capped at the API's downscale size); PDFs per page.
+func EstimateSendSize(message string, attachments []types.Attachment, limits SendSizeLimits) SendSizeEstimate {
+	limits = limits.withDefaults()
+	est := SendSizeEstimate{
+		MessageBytes:  len(message),
+		MessageTokens: estimateTextTokens(message),
+		Attachments:   make([]AttachmentEstimate, 0, len(attachments)),
+	}
+	est.TotalTokens = est.MessageTokens
+
+	for i, att := range attachments {
+		ae := AttachmentEstimate{Index: i, Name: att.FileName, Type: att.Type}
+		if ae.Name == "" {
+			ae.Name = fmt.Sprintf("attachment %d", i+1)
+		}
+
+		switch att.Type {
+		case "image":
+			estimateImage(&ae, att, limits)
+		case "document":
+			estimateDocument(&ae, att, limits)
+		case "file":
+			ae.Bytes = len(att.Data)
+			ae.Tokens = estimateTextTokens(att.Data)
+			if ae.Bytes > limits.MaxFileBytes {
+				ae.Error = fmt.Sprintf("%s is %s (limit %s)", ae.Name, formatBytes(ae.Bytes), formatBytes(limits.MaxFileBytes))
+			}
+		default:
+			ae.Error = unsupportedAttachmentError(ae.Name, att).Error()
+		}
+
+		if ae.Error != "" {
+			est.Errors = append(est.Errors, ae.Error)
+		}
+		est.TotalTokens += ae.Tokens
+		est.Attachments = append(est.Attachments, ae)
+	}
+
+	if payload, err := buildStdinPayload(message, attachments); err != nil {
+		if !slices.Contains(est.Errors, err.Error()) {
+			est.Errors = append(est.Errors, err.Error())
+		}
+	} else {
+		est.PayloadBytes = len(payload)
+		if est.PayloadBytes > limits.MaxSendBytes {
+			est.Errors = append(est.Errors, fmt.Sprintf("total payload is %s (limit %s)",
+				formatBytes(est.PayloadBytes), formatBytes(limits.MaxSendBytes)))
+		}
+	}
+
+	if est.TotalTokens > limits.SendWarnTokens {
+		est.Warnings = append(est.Warnings, fmt.Sprintf("~%d input tokens (warning threshold %d) — this will use a large share of the context window",
+			est.TotalTokens, limits.SendWarnTokens))
+	}
+
+	est.OK = len(est.Errors) == 0
+	return est
+}
+
+// estimateImage decodes an image attachment's base64 data and fills size, dimensions,
+// and token estimate. Formats the stdlib can't decode (webp) get the max token estimate.
+func estimateImage(ae *AttachmentEstimate, att types.Attachment, limits SendSizeLimits) {
+	if !slices.Contains(SupportedImageMediaTypes, att.MediaType) {
+		ae.Error = fmt.Sprintf("%s has unsupported image type %q", ae.Name, att.MediaType)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(att.Data)
+	if err != nil {
+		ae.Bytes = base64.StdEncoding.DecodedLen(len(att.Data))
+		ae.Error = fmt.Sprintf("%s is not valid base64: %v", ae.Name, err)
+		return
+	}
+	ae.Bytes = len(data)
+	if ae.Bytes > limits.MaxImageBytes {
+		ae.Error = fmt.Sprintf("%s is %s (limit %s)", ae.Name, formatBytes(ae.Bytes), formatBytes(limits.MaxImageBytes))
+	}
+
+	ae.Tokens = maxImageTokens
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		ae.Width, ae.Height = cfg.Width, cfg.Height
+		ae.Tokens = min(cfg.Width*cfg.Height/750, maxImageTokens)
+	}
+}
+
+// estimateDocument decodes a PDF attachment's base64 data and fills size, page
+// count, and token estimate.
+func estimateDocument(ae *AttachmentEstimate, att types.Attachment, limits SendSizeLimits) {
+	if !slices.Contains(SupportedDocumentMediaTypes, att.MediaType) {
+		ae.Error = fmt.Sprintf("%s has unsupported document type %q (only PDF is supported)", ae.Name, att.MediaType)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(att.Data)
+	if err != nil {
+		ae.Bytes = base64.StdEncoding.DecodedLen(len(att.Data))
+		ae.Error = fmt.Sprintf("%s is not valid base64: %v", ae.Name, err)
+		return
+	}
+	ae.Bytes = len(data)
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		ae.Error = fmt.Sprintf("%s is not a PDF file", ae.Name)
+		return
+	}
+	if ae.Bytes > limits.MaxPDFBytes {
+		ae.Error = fmt.Sprintf("%s is %s (limit %s)", ae.Name, formatBytes(ae.Bytes), formatBytes(limits.MaxPDFBytes))
+	}
+
+	ae.Pages = max(countPDFPages(data), 1)
+	ae.Tokens = ae.Pages * pdfTokensPerPage
+	if ae.Pages > maxPDFPages && ae.Error == "" {
+		ae.Error = fmt.Sprintf("%s has %d pages (limit %d)", ae.Name, ae.Pages, maxPDFPages)
+	}
+}
+
+// pdfPageObject matches page objects ("/Type /Page") but not the page tree ("/Type /Pages").
+var pdfPageObject = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+// countPDFPages counts page objects in an uncompressed PDF structure. Pages
+// inside compressed object streams are not seen, so this can undercount.
+func countPDFPages(data []byte) int {
+	return len(pdfPageObject.FindAllIndex(data, -1))
+}
+
+// unsupportedAttachmentError explains why an attachment type cannot be sent.
+func unsupportedAttachmentError(name string, att types.Attachment) error {
+	if att.Type == "audio" || strings.HasPrefix(att.MediaType, "audio/") {
+		return fmt.Errorf("%s is an audio file; Claude does not accept audio attachments", name)
+	}
+	return fmt.Errorf("%s has unsupported attachment type %q (supported: image, file, document)", name, att.Type)
+}
+
+// estimateTextTokens approximates tokens for English text and code (~4 bytes per token).
+func estimateTextTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// formatBytes renders a byte count as B, KB, or MB.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
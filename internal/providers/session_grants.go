@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"slices"
+	"sync"
+)
+
+// Session-scoped permission grants ("allow for this session only"). They are kept
+// in memory and added to --allowedTools for every later spawn of the same session
+// until revoked. The MCP permission log records grants and revocations, and
+// replays them into this registry when ClaudeFu starts.
+var (
+	sessionGrantsMu sync.RWMutex
+	sessionGrants   = map[string][]string{} // sessionID -> allow patterns
+)
+
+// AddSessionGrants adds allow patterns (e.g. "Bash(git push:*)") for a session.
+func AddSessionGrants(sessionID string, patterns []string) {
+	if sessionID == "" {
+		return
+	}
+	sessionGrantsMu.Lock()
+	defer sessionGrantsMu.Unlock()
+	for _, p := range patterns {
+		if p != "" && !slices.Contains(sessionGrants[sessionID], p) {
+			sessionGrants[sessionID] = append(sessionGrants[sessionID], p)
+		}
+	}
+}
+
+// GetSessionGrants returns a copy of a session's granted patterns.
+func GetSessionGrants(sessionID string) []string {
+	sessionGrantsMu.RLock()
+	defer sessionGrantsMu.RUnlock()
+	return slices.Clone(sessionGrants[sessionID])
+}
+
+// RevokeSessionGrants removes a session's grants. An empty patterns list revokes all.
+func RevokeSessionGrants(sessionID string, patterns []string) {
+	sessionGrantsMu.Lock()
+	defer sessionGrantsMu.Unlock()
+	if len(patterns) == 0 {
+		delete(sessionGrants, sessionID)
+		return
+	}
+	remaining := slices.DeleteFunc(sessionGrants[sessionID], func(p string) bool {
+		return slices.Contains(patterns, p)
+	})
+	if len(remaining) == 0 {
+		delete(sessionGrants, sessionID)
+		return
+	}
+	sessionGrants[sessionID] = remaining
+}
@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Helpers shared by the non-CLI backends, which write sessions in the
+// Claude Code JSONL format (see backend.go).
+
+// sessionEntry holds the JSONL fields the backends read back from a session.
+type sessionEntry struct {
+	Type          string `json:"type"`
+	UUID          string `json:"uuid"`
+	IsSidechain   bool   `json:"isSidechain"`
+	CodexThreadID string `json:"codexThreadId,omitempty"` // Set by the codex backend
+	Message       struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// readSessionEntries calls fn for every parseable line of a session JSONL.
+// A missing file has no entries.
+func readSessionEntries(path string, fn func(entry sessionEntry)) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry sessionEntry
+			if json.Unmarshal(line, &entry) == nil {
+				fn(entry)
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read session: %w", readErr)
+		}
+	}
+}
+
+// appendSessionEntry appends one JSONL line with the CLI's common envelope fields.
+// version fills the "version" field, which the CLI sets to its own version.
+func appendSessionEntry(path string, req BackendRequest, version, parentUUID, entryUUID string, fields map[string]any) error {
+	entry := map[string]any{
+		"parentUuid":  nil,
+		"isSidechain": false,
+		"userType":    "external",
+		"cwd":         req.Folder,
+		"sessionId":   req.SessionID,
+		"version":     version,
+		"uuid":        entryUUID,
+		"timestamp":   time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+	if parentUUID != "" {
+		entry["parentUuid"] = parentUUID
+	}
+	for key, value := range fields {
+		entry[key] = value
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// lookupEnv returns the value of key in an environment slice ("" if unset).
+func lookupEnv(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			value = v // Later entries win, as with exec.Cmd
+		}
+	}
+	return value
+}
@@ -0,0 +1,317 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// SPAWN SCHEDULER
+// =============================================================================
+
+// SpawnPriority is a scheduler lane. Lower values are dispatched first.
+type SpawnPriority int
+
+const (
+	PriorityInteractive SpawnPriority = iota // User-initiated sends, new sessions, slash commands
+	PriorityBackground                       // Helper prompts, handoffs and inbox auto-dispatch
+	PriorityScheduled                        // Scheduled/unattended runs
+	PriorityNested                           // Spawned by a tool call of a running process (AgentQuery, SelfQuery); exempt from the limit
+)
+
+// String returns the lane name used in queue introspection.
+func (p SpawnPriority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	case PriorityNested:
+		return "nested"
+	default:
+		return "scheduled"
+	}
+}
+
+const spawnLanes = 4
+
+// ErrSpawnCancelled is returned by Acquire when a queued spawn is cancelled.
+var ErrSpawnCancelled = errors.New("spawn cancelled while queued")
+
+// SpawnRequest describes a claude process waiting for or holding a slot.
+type SpawnRequest struct {
+	Priority  SpawnPriority `json:"-"`
+	Lane      string        `json:"lane"`
+	Kind      string        `json:"kind"` // e.g. "send", "new-session", "agent-query"
+	Folder    string        `json:"folder"`
+	SessionID string        `json:"sessionId,omitempty"`
+	Since     time.Time     `json:"since"` // Queued or started at
+}
+
+// SpawnQueueStatus is a snapshot of the scheduler.
+type SpawnQueueStatus struct {
+	MaxConcurrent int            `json:"maxConcurrent"`         // 0 = unlimited
+	PausedUntil   time.Time      `json:"pausedUntil,omitempty"` // Rate-limit cooldown holding the queue
+	Running       []SpawnRequest `json:"running"`
+	Queued        []SpawnRequest `json:"queued"` // Dispatch order
+}
+
+type spawnWaiter struct {
+	req       SpawnRequest
+	ready     chan struct{}
+	cancelled chan struct{}
+}
+
+// SpawnScheduler bounds how many claude processes run at once across the app.
+// Waiting spawns are dispatched by lane, then FIFO. When the limit is above 1,
+// the last slot is reserved for interactive work so a user send never waits
+// behind a queue of background queries. Nested spawns run on their caller's
+// slot: the calling process is blocked on them, so making them wait for a
+// slot could deadlock, and they don't count against the limit.
+type SpawnScheduler struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	running       map[*spawnWaiter]bool
+	lanes         [spawnLanes][]*spawnWaiter
+	pausedUntil   time.Time // No dispatch before this (rate-limit cooldown)
+	resumeTimer   *time.Timer
+	onChange      func()
+}
+
+// SpawnSlot is held while a process runs.
+type SpawnSlot struct {
+	Waited  time.Duration // Time spent queued
+	release func()
+}
+
+// Release frees the slot. Safe to call more than once.
+func (s *SpawnSlot) Release() {
+	if s != nil && s.release != nil {
+		s.release()
+		s.release = nil
+	}
+}
+
+// NewSpawnScheduler creates a scheduler with no concurrency limit.
+func NewSpawnScheduler() *SpawnScheduler {
+	return &SpawnScheduler{running: make(map[*spawnWaiter]bool)}
+}
+
+// SetMaxConcurrent sets the global process limit (<= 0 = unlimited) and
+// dispatches any waiters the new limit allows.
+func (s *SpawnScheduler) SetMaxConcurrent(n int) {
+	s.mu.Lock()
+	s.maxConcurrent = max(n, 0)
+	s.dispatchLocked()
+	s.mu.Unlock()
+	s.notify()
+}
+
+// PauseUntil holds queued spawns until t (running processes are unaffected).
+// A later deadline extends the pause; an earlier one is ignored.
+func (s *SpawnScheduler) PauseUntil(t time.Time) {
+	s.mu.Lock()
+	if !t.After(s.pausedUntil) {
+		s.mu.Unlock()
+		return
+	}
+	s.pausedUntil = t
+	if s.resumeTimer != nil {
+		s.resumeTimer.Stop()
+	}
+	s.resumeTimer = time.AfterFunc(time.Until(t), func() {
+		s.mu.Lock()
+		s.dispatchLocked()
+		s.mu.Unlock()
+		s.notify()
+	})
+	s.mu.Unlock()
+	s.notify()
+}
+
+// SetOnChange sets a function called after the queue or running set changes.
+func (s *SpawnScheduler) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Acquire blocks until req may start, ctx is done, or the spawn is cancelled
+// with CancelQueued.
+func (s *SpawnScheduler) Acquire(ctx context.Context, req SpawnRequest) (*SpawnSlot, error) {
+	req.Lane = req.Priority.String()
+	req.Since = time.Now()
+	w := &spawnWaiter{req: req, ready: make(chan struct{}), cancelled: make(chan struct{})}
+	start := req.Since
+
+	s.mu.Lock()
+	lane := min(max(int(req.Priority), 0), spawnLanes-1)
+	s.lanes[lane] = append(s.lanes[lane], w)
+	s.dispatchLocked()
+	s.mu.Unlock()
+	s.notify()
+
+	var err error
+	select {
+	case <-w.ready:
+		return &SpawnSlot{Waited: time.Since(start), release: func() { s.release(w) }}, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-w.cancelled:
+		err = ErrSpawnCancelled
+	}
+
+	s.mu.Lock()
+	s.removeWaiterLocked(w)
+	handedOver := s.running[w]
+	s.mu.Unlock()
+	if handedOver {
+		// Lost the race: dispatched just as we gave up
+		s.release(w)
+	} else {
+		s.notify()
+	}
+	return nil, err
+}
+
+// CancelQueued cancels a session's queued (not yet running) spawns.
+// Returns true if any were cancelled.
+func (s *SpawnScheduler) CancelQueued(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	s.mu.Lock()
+	found := false
+	for _, lane := range s.lanes {
+		for _, w := range lane {
+			if w.req.SessionID == sessionID {
+				close(w.cancelled)
+				found = true
+			}
+		}
+	}
+	for lane := range s.lanes {
+		kept := s.lanes[lane][:0]
+		for _, w := range s.lanes[lane] {
+			if w.req.SessionID != sessionID {
+				kept = append(kept, w)
+			}
+		}
+		s.lanes[lane] = kept
+	}
+	s.mu.Unlock()
+
+	if found {
+		s.notify()
+	}
+	return found
+}
+
+// Status returns a snapshot of running and queued spawns.
+func (s *SpawnScheduler) Status() SpawnQueueStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := SpawnQueueStatus{
+		MaxConcurrent: s.maxConcurrent,
+		Running:       make([]SpawnRequest, 0, len(s.running)),
+		Queued:        []SpawnRequest{},
+	}
+	for w := range s.running {
+		status.Running = append(status.Running, w.req)
+	}
+	for _, lane := range s.lanes {
+		for _, w := range lane {
+			status.Queued = append(status.Queued, w.req)
+		}
+	}
+	if time.Now().Before(s.pausedUntil) {
+		status.PausedUntil = s.pausedUntil
+	}
+	return status
+}
+
+func (s *SpawnScheduler) release(w *spawnWaiter) {
+	s.mu.Lock()
+	delete(s.running, w)
+	s.dispatchLocked()
+	s.mu.Unlock()
+	s.notify()
+}
+
+// dispatchLocked starts waiters in lane order while slots are free. Caller must hold s.mu.
+func (s *SpawnScheduler) dispatchLocked() {
+	if time.Now().Before(s.pausedUntil) {
+		return
+	}
+	for lane := range s.lanes {
+		for len(s.lanes[lane]) > 0 && s.hasSlotLocked(SpawnPriority(lane)) {
+			w := s.lanes[lane][0]
+			s.lanes[lane] = s.lanes[lane][1:]
+			w.req.Since = time.Now()
+			s.running[w] = true
+			close(w.ready)
+		}
+	}
+}
+
+func (s *SpawnScheduler) hasSlotLocked(p SpawnPriority) bool {
+	if s.maxConcurrent <= 0 || p == PriorityNested {
+		return true
+	}
+	limit := s.maxConcurrent
+	if p != PriorityInteractive && limit > 1 {
+		limit-- // Reserve a slot for interactive work
+	}
+	running := 0
+	for w := range s.running {
+		if w.req.Priority != PriorityNested {
+			running++
+		}
+	}
+	return running < limit
+}
+
+func (s *SpawnScheduler) removeWaiterLocked(w *spawnWaiter) {
+	for lane := range s.lanes {
+		for i, other := range s.lanes[lane] {
+			if other == w {
+				s.lanes[lane] = append(s.lanes[lane][:i], s.lanes[lane][i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (s *SpawnScheduler) notify() {
+	s.mu.Lock()
+	fn := s.onChange
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+type nestedSpawnKey struct{}
+
+// WithNestedSpawn marks ctx as serving a tool call of a running claude
+// process, so the processes spawned for it use PriorityNested.
+func WithNestedSpawn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nestedSpawnKey{}, true)
+}
+
+// IsNestedSpawn reports whether ctx was marked with WithNestedSpawn.
+func IsNestedSpawn(ctx context.Context) bool {
+	nested, _ := ctx.Value(nestedSpawnKey{}).(bool)
+	return nested
+}
+
+// spawnScheduler is the app-wide scheduler used for every claude process ClaudeFu spawns.
+var spawnScheduler = NewSpawnScheduler()
+
+// Spawns returns the app-wide spawn scheduler.
+func Spawns() *SpawnScheduler {
+	return spawnScheduler
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// tryAcquire reports whether a spawn at priority starts right away.
+func tryAcquire(t *testing.T, s *SpawnScheduler, priority SpawnPriority) (*SpawnSlot, bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	slot, err := s.Acquire(ctx, SpawnRequest{Priority: priority, Kind: "test"})
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Acquire: %v", err)
+		}
+		return nil, false
+	}
+	return slot, true
+}
+
+func TestSpawnSchedulerAdmission(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		running []SpawnPriority
+		try     SpawnPriority
+		want    bool
+	}{
+		{"unlimited", 0, []SpawnPriority{PriorityBackground, PriorityBackground}, PriorityBackground, true},
+		{"free slot", 1, nil, PriorityBackground, true},
+		{"full", 1, []SpawnPriority{PriorityInteractive}, PriorityInteractive, false},
+		{"last slot reserved for interactive", 2, []SpawnPriority{PriorityInteractive}, PriorityBackground, false},
+		{"interactive takes reserved slot", 2, []SpawnPriority{PriorityInteractive}, PriorityInteractive, true},
+		{"scheduled respects reservation", 3, []SpawnPriority{PriorityInteractive, PriorityBackground}, PriorityScheduled, false},
+		{"nested exempt when full", 1, []SpawnPriority{PriorityInteractive}, PriorityNested, true},
+		{"nested exempt from reservation", 2, []SpawnPriority{PriorityInteractive}, PriorityNested, true},
+		{"nested not counted", 2, []SpawnPriority{PriorityInteractive, PriorityNested}, PriorityInteractive, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSpawnScheduler()
+			s.SetMaxConcurrent(tt.max)
+			for _, p := range tt.running {
+				slot, ok := tryAcquire(t, s, p)
+				if !ok {
+					t.Fatalf("setup: %s spawn did not start", p)
+				}
+				defer slot.Release()
+			}
+			slot, got := tryAcquire(t, s, tt.try)
+			slot.Release()
+			if got != tt.want {
+				t.Errorf("%s spawn started = %v, want %v", tt.try, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpawnSchedulerDispatchOrder(t *testing.T) {
+	s := NewSpawnScheduler()
+	s.SetMaxConcurrent(1)
+	holder, ok := tryAcquire(t, s, PriorityInteractive)
+	if !ok {
+		t.Fatal("first spawn did not start")
+	}
+
+	// Queue in reverse priority order; dispatch goes by lane, then FIFO
+	started := make(chan string, 4)
+	queue := []struct {
+		kind     string
+		priority SpawnPriority
+	}{
+		{"scheduled", PriorityScheduled},
+		{"background-1", PriorityBackground},
+		{"interactive", PriorityInteractive},
+		{"background-2", PriorityBackground},
+	}
+	for i, q := range queue {
+		go func() {
+			slot, err := s.Acquire(context.Background(), SpawnRequest{Priority: q.priority, Kind: q.kind})
+			if err != nil {
+				started <- "error: " + err.Error()
+				return
+			}
+			started <- q.kind
+			slot.Release()
+		}()
+		for len(s.Status().Queued) != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	holder.Release()
+	want := []string{"interactive", "background-1", "background-2", "scheduled"}
+	for _, w := range want {
+		select {
+		case got := <-started:
+			if got != w {
+				t.Fatalf("started %s, want %s", got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", w)
+		}
+	}
+}
+
+func TestSpawnSchedulerCancelQueued(t *testing.T) {
+	s := NewSpawnScheduler()
+	s.SetMaxConcurrent(1)
+	holder, _ := tryAcquire(t, s, PriorityInteractive)
+	defer holder.Release()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := s.Acquire(context.Background(), SpawnRequest{Priority: PriorityInteractive, SessionID: "s1"})
+		errc <- err
+	}()
+	for len(s.Status().Queued) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if !s.CancelQueued("s1") {
+		t.Fatal("CancelQueued found nothing")
+	}
+	if err := <-errc; !errors.Is(err, ErrSpawnCancelled) {
+		t.Errorf("Acquire error = %v, want ErrSpawnCancelled", err)
+	}
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"bytes"
+	"sync"
+)
+
+// StreamSink receives the stream-json lines a send's claude process writes to
+// stdout, as they are written. It backs the "stream" agent watch mode.
+type StreamSink func(folder, sessionID, line string)
+
+// SetStreamSink sets the function that receives live stream-json output of
+// sends (nil = output is only parsed once the process exits).
+func (s *ClaudeCodeService) SetStreamSink(sink StreamSink) {
+	s.streamSinkMu.Lock()
+	defer s.streamSinkMu.Unlock()
+	s.streamSink = sink
+}
+
+// streamWriter returns a writer that forwards complete stdout lines of a send
+// to the stream sink, or nil if there is no sink.
+func (s *ClaudeCodeService) streamWriter(folder, sessionID string) *streamLineWriter {
+	s.streamSinkMu.RLock()
+	defer s.streamSinkMu.RUnlock()
+	if s.streamSink == nil {
+		return nil
+	}
+	return &streamLineWriter{sink: s.streamSink, folder: folder, sessionID: sessionID}
+}
+
+// streamLineWriter splits written bytes into lines for a StreamSink.
+// A partial trailing line is held until its newline (or Flush) arrives.
+type streamLineWriter struct {
+	sink      StreamSink
+	folder    string
+	sessionID string
+	mu        sync.Mutex
+	buf       []byte
+}
+
+func (w *streamLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush forwards a final line that had no trailing newline.
+func (w *streamLineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.emit(w.buf)
+	w.buf = nil
+}
+
+func (w *streamLineWriter) emit(line []byte) {
+	if line = bytes.TrimSpace(line); len(line) > 0 {
+		w.sink(w.folder, w.sessionID, string(line))
+	}
+}
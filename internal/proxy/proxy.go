@@ -17,8 +17,12 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"claudefu/internal/logging"
 )
 
+var logger = logging.For(logging.Providers)
+
 // Config holds proxy configuration
 type Config struct {
 	Enabled         bool   `json:"enabled"`
@@ -138,7 +142,7 @@ func (s *Service) Start() error {
 	// Ensure log directory exists if logging enabled
 	if s.config.LoggingEnabled && s.config.LogDir != "" {
 		if err := os.MkdirAll(s.config.LogDir, 0755); err != nil {
-			fmt.Printf("[proxy] Warning: could not create log dir %s: %v\n", s.config.LogDir, err)
+			logger.Warnf("Cache fix proxy: could not create log dir %s: %v", s.config.LogDir, err)
 		}
 	}
 
@@ -149,9 +153,9 @@ func (s *Service) Start() error {
 	}
 
 	go func() {
-		fmt.Printf("[proxy] Cache fix proxy started on :%d → %s\n", s.config.Port, s.config.UpstreamURL)
+		logger.Infof("Cache fix proxy started on :%d → %s", s.config.Port, s.config.UpstreamURL)
 		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("[proxy] Server error: %v\n", err)
+			logger.Errorf("Cache fix proxy server error: %v", err)
 		}
 	}()
 
@@ -188,7 +192,7 @@ func (s *Service) Stop() {
 	if done != nil {
 		<-done
 	}
-	fmt.Println("[proxy] Cache fix proxy stopped")
+	logger.Infof("Cache fix proxy stopped")
 }
 
 // Restart stops and starts with new config
@@ -236,7 +240,7 @@ func (s *Service) middleware(next http.Handler) http.Handler {
 			if s.config.CacheFixEnabled {
 				if err := s.fixCacheRequest(r); err != nil {
 					s.errors.Add(1)
-					fmt.Printf("[proxy] Cache fix error: %v\n", err)
+					logger.Errorf("Cache fix error: %v", err)
 					// Continue with original request — don't block on fix failure
 				}
 			}
@@ -347,7 +351,7 @@ func (s *Service) fixCacheRequest(r *http.Request) error {
 					}
 					modified = true
 					s.skillsMoved.Add(1)
-					fmt.Printf("[proxy] Moved skills SR from msg[0] to msg[%d]\n", lastUserIdx)
+					logger.Debugf("Moved skills SR from msg[0] to msg[%d]", lastUserIdx)
 				}
 			}
 		}
@@ -367,7 +371,7 @@ func (s *Service) fixCacheRequest(r *http.Request) error {
 			lastBlock["cache_control"] = cc
 			modified = true
 			s.breakpointsAdded.Add(1)
-			fmt.Printf("[proxy] Added cache_control to msg[0] block[%d]\n", len(content)-1)
+			logger.Debugf("Added cache_control to msg[0] block[%d]", len(content)-1)
 		}
 	}
 
@@ -377,7 +381,7 @@ func (s *Service) fixCacheRequest(r *http.Request) error {
 		if upgraded > 0 {
 			modified = true
 			s.ttlsUpgraded.Add(int64(upgraded))
-			fmt.Printf("[proxy] Upgraded %d cache_control TTLs from 5m to 1h\n", upgraded)
+			logger.Debugf("Upgraded %d cache_control TTLs from 5m to 1h", upgraded)
 		}
 	}
 
@@ -0,0 +1,411 @@
+package runtime
+
+import (
+	"path/filepath"
+	"strings"
+
+	"claudefu/internal/types"
+)
+
+// File actions recorded in a session's file activity.
+const (
+	FileCreated  = "created"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+)
+
+// FileTouch is one change to a file by a tool call.
+type FileTouch struct {
+	Action      string `json:"action"` // One of the File* actions
+	Tool        string `json:"tool"`
+	ToolUseID   string `json:"toolUseId"`
+	MessageUUID string `json:"messageUuid"` // Assistant message holding the tool_use
+	Timestamp   string `json:"timestamp"`
+}
+
+// FileActivity is everything a session did to one file.
+type FileActivity struct {
+	Path    string      `json:"path"`
+	Action  string      `json:"action"`  // Net effect over the session (see mergeFileAction)
+	Touches []FileTouch `json:"touches"` // Oldest first
+}
+
+// FileTracker builds a session's file activity from the tool_use blocks of its
+// messages (Edit, MultiEdit, Write, NotebookEdit, and file-changing Bash
+// commands). A change is recorded once its tool_result arrives without error,
+// so tracking works across message batches. Not safe for concurrent use.
+type FileTracker struct {
+	folder  string // Relative Bash paths are resolved against it
+	files   map[string]*FileActivity
+	order   []string // Paths in first-touched order
+	pending map[string][]pendingFileTouch
+}
+
+// pendingFileTouch is a change awaiting its tool_result.
+type pendingFileTouch struct {
+	path  string
+	touch FileTouch
+}
+
+// NewFileTracker creates a tracker for a session run in folder.
+func NewFileTracker(folder string) *FileTracker {
+	return &FileTracker{
+		folder:  folder,
+		files:   make(map[string]*FileActivity),
+		pending: make(map[string][]pendingFileTouch),
+	}
+}
+
+// ExtractFileActivity returns the file activity of a whole session.
+func ExtractFileActivity(folder string, messages []types.Message) []FileActivity {
+	t := NewFileTracker(folder)
+	t.Add(messages)
+	return t.Activity()
+}
+
+// Add processes messages in order and reports whether any change was recorded.
+func (t *FileTracker) Add(messages []types.Message) bool {
+	recorded := false
+	for _, msg := range messages {
+		if msg.IsSynthetic {
+			continue
+		}
+		for _, block := range msg.ContentBlocks {
+			switch block.Type {
+			case "tool_use":
+				if msg.Type != "assistant" {
+					continue
+				}
+				for _, change := range t.toolFiles(block) {
+					t.pending[block.ID] = append(t.pending[block.ID], pendingFileTouch{
+						path: change.path,
+						touch: FileTouch{
+							Action:      change.action,
+							Tool:        block.Name,
+							ToolUseID:   block.ID,
+							MessageUUID: msg.UUID,
+							Timestamp:   msg.Timestamp,
+						},
+					})
+				}
+			case "tool_result":
+				touches, ok := t.pending[block.ToolUseID]
+				if !ok {
+					continue
+				}
+				delete(t.pending, block.ToolUseID)
+				if block.IsError {
+					continue
+				}
+				for _, p := range touches {
+					if p.touch.Tool == types.ToolNameWrite && !strings.HasPrefix(toolResultText(block.Content), "File created") {
+						p.touch.Action = FileModified
+					}
+					t.record(p.path, p.touch)
+					recorded = true
+				}
+			}
+		}
+	}
+	return recorded
+}
+
+// GetSessionFileActivity returns the files a loaded session changed, in
+// first-touched order. complete is false when messages before the session's
+// last compaction were never loaded, so earlier changes are missing.
+func (rt *WorkspaceRuntime) GetSessionFileActivity(agentID, sessionID string) (files []FileActivity, complete bool, ok bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	agentState, found := rt.agentStates[agentID]
+	if !found {
+		return nil, false, false
+	}
+	session, found := agentState.Sessions[sessionID]
+	if !found || !session.InitialLoadDone {
+		return nil, false, false
+	}
+	if session.fileActivity == nil {
+		return []FileActivity{}, session.HistoryEnd == 0, true
+	}
+	return session.fileActivity.Activity(), session.HistoryEnd == 0, true
+}
+
+// Activity returns the files touched so far, in first-touched order.
+func (t *FileTracker) Activity() []FileActivity {
+	result := make([]FileActivity, 0, len(t.order))
+	for _, path := range t.order {
+		activity := *t.files[path]
+		activity.Touches = append([]FileTouch(nil), activity.Touches...)
+		result = append(result, activity)
+	}
+	return result
+}
+
+// record adds a completed change to a file's activity.
+func (t *FileTracker) record(path string, touch FileTouch) {
+	activity, ok := t.files[path]
+	if !ok {
+		activity = &FileActivity{Path: path}
+		t.files[path] = activity
+		t.order = append(t.order, path)
+	}
+	activity.Action = mergeFileAction(activity.Action, touch.Action)
+	activity.Touches = append(activity.Touches, touch)
+}
+
+// mergeFileAction folds a new change into a file's net action: a created file
+// stays created when edited, and a deleted file that reappears was modified.
+func mergeFileAction(prev, next string) string {
+	switch {
+	case prev == FileCreated && next == FileModified:
+		return FileCreated
+	case prev == FileDeleted && next != FileDeleted:
+		return FileModified
+	default:
+		return next
+	}
+}
+
+// toolFiles returns the files a tool call changes and how. Write is assumed to
+// create; its tool_result tells whether the file already existed.
+func (t *FileTracker) toolFiles(block types.ContentBlock) []fileChange {
+	input, _ := block.Input.(map[string]any)
+	var changes []fileChange
+	switch block.Name {
+	case types.ToolNameWrite:
+		if path, _ := input["file_path"].(string); path != "" {
+			changes = append(changes, fileChange{path: t.resolve(path), action: FileCreated})
+		}
+	case types.ToolNameEdit, "MultiEdit":
+		if path, _ := input["file_path"].(string); path != "" {
+			changes = append(changes, fileChange{path: t.resolve(path), action: FileModified})
+		}
+	case types.ToolNameNotebookEdit:
+		if path, _ := input["notebook_path"].(string); path != "" {
+			changes = append(changes, fileChange{path: t.resolve(path), action: FileModified})
+		}
+	case types.ToolNameBash:
+		command, _ := input["command"].(string)
+		for _, change := range bashFileChanges(command) {
+			changes = append(changes, fileChange{path: t.resolve(change.path), action: change.action})
+		}
+	}
+	return changes
+}
+
+// resolve makes path absolute against the session's folder.
+func (t *FileTracker) resolve(path string) string {
+	if !filepath.IsAbs(path) && t.folder != "" {
+		path = filepath.Join(t.folder, path)
+	}
+	return filepath.Clean(path)
+}
+
+// fileChange is a file a tool call changes.
+type fileChange struct {
+	path   string
+	action string
+}
+
+// bashFileChanges recognizes the common file-changing shell commands (rm,
+// git rm, mv, git mv, cp, touch, sed -i, output redirection). Paths with
+// globs or variables are skipped since they can't be resolved statically.
+func bashFileChanges(command string) []fileChange {
+	var changes []fileChange
+	add := func(path, action string) {
+		if path == "" || path == "/dev/null" || strings.ContainsAny(path, "*?[]{}$`~") {
+			return
+		}
+		changes = append(changes, fileChange{path: path, action: action})
+	}
+
+	for _, segment := range splitShellCommands(stripHeredocs(command)) {
+		words := shellWords(segment)
+
+		// Output redirection (> file, >> file, 2> file)
+		var args []string
+		for i := 0; i < len(words); i++ {
+			w := words[i]
+			op := strings.TrimLeft(w, "0123456789&")
+			if op == ">" || op == ">>" || op == ">|" {
+				if i+1 < len(words) {
+					add(words[i+1], FileModified)
+					i++
+				}
+				continue
+			}
+			if strings.HasPrefix(op, ">&") {
+				continue // Descriptor duplication (2>&1)
+			}
+			if strings.HasPrefix(op, ">") {
+				add(strings.TrimLeft(op, ">|"), FileModified)
+				continue
+			}
+			args = append(args, w)
+		}
+
+		// Skip leading env assignments and sudo
+		for len(args) > 0 && (strings.Contains(args[0], "=") || args[0] == "sudo") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		name, operands := args[0], args[1:]
+		if name == "git" && len(operands) > 0 && (operands[0] == "rm" || operands[0] == "mv") {
+			name, operands = operands[0], operands[1:]
+		}
+
+		var paths, flags []string
+		for _, arg := range operands {
+			if strings.HasPrefix(arg, "-") {
+				flags = append(flags, arg)
+			} else {
+				paths = append(paths, arg)
+			}
+		}
+
+		switch name {
+		case "rm", "unlink":
+			for _, p := range paths {
+				add(p, FileDeleted)
+			}
+		case "touch":
+			for _, p := range paths {
+				add(p, FileModified)
+			}
+		case "mv":
+			if len(paths) == 2 {
+				add(paths[0], FileDeleted)
+				add(paths[1], FileCreated)
+			}
+		case "cp":
+			if len(paths) == 2 {
+				add(paths[1], FileCreated)
+			}
+		case "sed", "perl":
+			inPlace := false
+			for _, f := range flags {
+				if strings.HasPrefix(f, "-i") || (strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && strings.Contains(f, "i")) {
+					inPlace = true
+				}
+			}
+			if inPlace && len(paths) > 1 {
+				// First operand is the script (unless given with -e)
+				for _, p := range paths[1:] {
+					add(p, FileModified)
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// stripHeredocs removes here-document bodies, which are data, not commands.
+func stripHeredocs(command string) string {
+	var kept []string
+	delimiter := ""
+	for _, line := range strings.Split(command, "\n") {
+		if delimiter != "" {
+			if strings.TrimSpace(line) == delimiter {
+				delimiter = ""
+			}
+			continue
+		}
+		kept = append(kept, line)
+		if i := strings.Index(line, "<<"); i >= 0 && !strings.HasPrefix(line[i:], "<<<") {
+			if fields := strings.Fields(strings.TrimLeft(line[i+2:], "-")); len(fields) > 0 {
+				delimiter = strings.Trim(fields[0], `'"`)
+			}
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// splitShellCommands splits a command line on ;, &&, || and | and newlines,
+// ignoring separators inside quotes.
+func splitShellCommands(command string) []string {
+	var segments []string
+	var cur strings.Builder
+	var quote rune
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			cur.WriteRune(r)
+		case r == ';' || r == '\n' || r == '|' || (r == '&' && i+1 < len(runes) && runes[i+1] == '&'):
+			// "|" right after ">" is the >| redirection, not a pipe
+			if r == '|' && i > 0 && runes[i-1] == '>' {
+				cur.WriteRune(r)
+				continue
+			}
+			segments = append(segments, cur.String())
+			cur.Reset()
+			if (r == '&' || r == '|') && i+1 < len(runes) && runes[i+1] == r {
+				i++
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(segments, cur.String())
+}
+
+// shellWords splits a command into words, removing quotes.
+func shellWords(command string) []string {
+	var words []string
+	var cur strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words
+}
+
+// toolResultText returns the text of a tool_result's content.
+func toolResultText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []any:
+		for _, item := range c {
+			if block, ok := item.(map[string]any); ok {
+				if text, ok := block["text"].(string); ok {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitShellCommands(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"go build", []string{"go build"}},
+		{"a; b && c || d | e", []string{"a", "b", "c", "d", "e"}},
+		{"a\nb", []string{"a", "b"}},
+		{`echo 'a; b' && echo "c | d"`, []string{`echo 'a; b'`, `echo "c | d"`}},
+		{"echo hi >| out.txt", []string{"echo hi >| out.txt"}},
+		{"sleep 1 & echo done", []string{"sleep 1 & echo done"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range splitShellCommands(tt.command) {
+			got = append(got, strings.TrimSpace(s))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitShellCommands(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestBashFileChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string // "action path"
+	}{
+		{"rm", "rm -f a.txt b.txt", []string{"deleted a.txt", "deleted b.txt"}},
+		{"git rm", "git rm -r old", []string{"deleted old"}},
+		{"mv", "mv a.go b.go", []string{"deleted a.go", "created b.go"}},
+		{"git mv", "git mv a.go b.go", []string{"deleted a.go", "created b.go"}},
+		{"cp", "cp -p src.go dst.go", []string{"created dst.go"}},
+		{"touch", "touch new.txt", []string{"modified new.txt"}},
+		{"sed in place", "sed -i 's/a/b/' main.go", []string{"modified main.go"}},
+		{"sed to stdout", "sed 's/a/b/' main.go", nil},
+		{"perl in place", "perl -pi -e 's/a/b/' main.go", []string{"modified main.go"}},
+		{"redirection", "echo hi > out.txt 2>&1", []string{"modified out.txt"}},
+		{"attached append", "echo hi >>log.txt", []string{"modified log.txt"}},
+		{"env and sudo", "FOO=1 sudo rm /tmp/x", []string{"deleted /tmp/x"}},
+		{"chained", "go build && rm bin/app", []string{"deleted bin/app"}},
+		{"globs and variables skipped", "rm *.tmp $HOME/x ~/y", nil},
+		{"dev null skipped", "make > /dev/null", nil},
+		{"quoted separators", "echo 'rm x; touch y'", nil},
+		{"heredoc body ignored", "cat <<EOF > notes.md\nrm important\nEOF", []string{"modified notes.md"}},
+		{"read-only", "ls -la && git status", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range bashFileChanges(tt.command) {
+				got = append(got, c.action+" "+c.path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("bashFileChanges(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package runtime
+
+import (
+	"sort"
+
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// BUFFER LIMITS & MEMORY BUDGET
+// Each session buffer is bounded by a message count and optionally a byte size
+// (per agent, falling back to the runtime defaults). On top of that a global
+// budget caps all buffers together: when it is exceeded the largest inactive
+// buffers lose their oldest messages first. Evicted messages stay readable from
+// disk through the byte offsets recorded for each message.
+// =============================================================================
+
+// minBudgetMessages is how many messages the memory budget leaves in a buffer.
+const minBudgetMessages = 50
+
+// BufferLimits bounds a session's message buffer. Zero fields mean "use the
+// default" (for agent limits) or "no limit" (MaxBytes of the defaults).
+type BufferLimits struct {
+	MaxMessages int   `json:"maxMessages"`
+	MaxBytes    int64 `json:"maxBytes"`
+}
+
+// SessionMemoryStats is the buffer usage of one session.
+type SessionMemoryStats struct {
+	AgentID     string `json:"agentId"`
+	SessionID   string `json:"sessionId"`
+	Messages    int    `json:"messages"`
+	Bytes       int64  `json:"bytes"` // Estimated size of the buffered messages
+	Evicted     int    `json:"evicted"`
+	MaxMessages int    `json:"maxMessages"`
+	MaxBytes    int64  `json:"maxBytes"` // 0 = no limit
+}
+
+// RuntimeStats reports message buffer memory across the workspace.
+type RuntimeStats struct {
+	TotalMessages int                  `json:"totalMessages"`
+	TotalBytes    int64                `json:"totalBytes"`
+	BudgetBytes   int64                `json:"budgetBytes"` // 0 = no budget
+	Sessions      []SessionMemoryStats `json:"sessions"`    // Largest first
+}
+
+// SetBufferDefaults sets the limits used by agents without their own, and
+// trims existing buffers to fit.
+func (rt *WorkspaceRuntime) SetBufferDefaults(limits BufferLimits) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = MaxBufferSize
+	}
+	rt.bufferDefaults = limits
+	for _, agentState := range rt.agentStates {
+		rt.trimAgentLocked(agentState)
+	}
+	rt.enforceMemoryBudgetLocked()
+}
+
+// SetAgentBufferLimits overrides the buffer limits of one agent (zero fields
+// fall back to the defaults) and trims its buffers to fit.
+func (rt *WorkspaceRuntime) SetAgentBufferLimits(agentID string, limits BufferLimits) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		agentState = &AgentState{
+			Sessions: make(map[string]*SessionState),
+		}
+		rt.agentStates[agentID] = agentState
+	}
+	agentState.BufferLimits = limits
+	rt.trimAgentLocked(agentState)
+}
+
+// SetMemoryBudget caps the estimated size of all buffers together (0 = no cap).
+func (rt *WorkspaceRuntime) SetMemoryBudget(bytes int64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.memoryBudget = max(bytes, 0)
+	rt.enforceMemoryBudgetLocked()
+}
+
+// GetStats returns per-session buffer usage, largest sessions first.
+func (rt *WorkspaceRuntime) GetStats() RuntimeStats {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	stats := RuntimeStats{BudgetBytes: rt.memoryBudget, Sessions: []SessionMemoryStats{}}
+	for agentID, agentState := range rt.agentStates {
+		limits := rt.limitsLocked(agentState)
+		for sessionID, session := range agentState.Sessions {
+			stats.Sessions = append(stats.Sessions, SessionMemoryStats{
+				AgentID:     agentID,
+				SessionID:   sessionID,
+				Messages:    len(session.Messages),
+				Bytes:       session.BufferBytes,
+				Evicted:     session.Evicted,
+				MaxMessages: limits.MaxMessages,
+				MaxBytes:    limits.MaxBytes,
+			})
+			stats.TotalMessages += len(session.Messages)
+			stats.TotalBytes += session.BufferBytes
+		}
+	}
+	sort.Slice(stats.Sessions, func(i, j int) bool {
+		return stats.Sessions[i].Bytes > stats.Sessions[j].Bytes
+	})
+	return stats
+}
+
+// limitsLocked returns an agent's effective buffer limits. Caller must hold rt.mu.
+func (rt *WorkspaceRuntime) limitsLocked(agentState *AgentState) BufferLimits {
+	limits := agentState.BufferLimits
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = rt.bufferDefaults.MaxMessages
+	}
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = MaxBufferSize
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = rt.bufferDefaults.MaxBytes
+	}
+	return limits
+}
+
+// trimAgentLocked applies an agent's limits to all its sessions. Caller must hold rt.mu.
+func (rt *WorkspaceRuntime) trimAgentLocked(agentState *AgentState) {
+	limits := rt.limitsLocked(agentState)
+	for _, session := range agentState.Sessions {
+		trimSession(session, limits)
+	}
+	rt.recalculateAgentUnread(agentState)
+}
+
+// trimSession drops a session's oldest messages until it fits limits (the
+// newest message is always kept).
+func trimSession(session *SessionState, limits BufferLimits) {
+	excess := len(session.Messages) - limits.MaxMessages
+	if limits.MaxBytes > 0 {
+		bytes := session.BufferBytes
+		for i := 0; i < len(session.Messages)-1 && bytes > limits.MaxBytes; i++ {
+			bytes -= int64(session.messageBytes[i])
+			excess = max(excess, i+1)
+		}
+	}
+	if excess > 0 {
+		session.dropOldest(excess)
+	}
+}
+
+// enforceMemoryBudgetLocked evicts the oldest messages of the largest buffers
+// until all buffers fit the budget. The active session is only trimmed once no
+// other buffer can give up messages. Caller must hold rt.mu.
+func (rt *WorkspaceRuntime) enforceMemoryBudgetLocked() {
+	if rt.memoryBudget <= 0 {
+		return
+	}
+	var total int64
+	for _, agentState := range rt.agentStates {
+		for _, session := range agentState.Sessions {
+			total += session.BufferBytes
+		}
+	}
+
+	evicted := 0
+	for total > rt.memoryBudget {
+		var largest *SessionState
+		var largestAgent *AgentState
+		largestActive := false
+		for agentID, agentState := range rt.agentStates {
+			for sessionID, session := range agentState.Sessions {
+				if len(session.Messages) <= minBudgetMessages {
+					continue
+				}
+				active := agentID == rt.activeAgentID && sessionID == rt.activeSessionID
+				if largest != nil && (active && !largestActive ||
+					active == largestActive && session.BufferBytes <= largest.BufferBytes) {
+					continue
+				}
+				largest, largestAgent, largestActive = session, agentState, active
+			}
+		}
+		if largest == nil {
+			break // Every buffer is down to its minimum
+		}
+
+		before := largest.BufferBytes
+		n := 0
+		for bytes := before; n < len(largest.Messages)-minBudgetMessages && total-(before-bytes) > rt.memoryBudget; n++ {
+			bytes -= int64(largest.messageBytes[n])
+		}
+		largest.dropOldest(n)
+		rt.recalculateAgentUnread(largestAgent)
+		total -= before - largest.BufferBytes
+		evicted += n
+	}
+	if evicted > 0 {
+		logger.Infof("Memory budget: evicted %d buffered messages (now %d of %d bytes)", evicted, total, rt.memoryBudget)
+	}
+}
+
+// appendBuffered adds messages to the buffer along with their size estimates.
+func (s *SessionState) appendBuffered(messages []types.Message) {
+	for _, msg := range messages {
+		size := estimateMessageBytes(msg)
+		s.messageBytes = append(s.messageBytes, size)
+		s.BufferBytes += int64(size)
+	}
+	s.Messages = append(s.Messages, messages...)
+}
+
+// dropOldest evicts the n oldest buffered messages.
+func (s *SessionState) dropOldest(n int) {
+	n = min(n, len(s.Messages))
+	if n <= 0 {
+		return
+	}
+	for _, size := range s.messageBytes[:n] {
+		s.BufferBytes -= int64(size)
+	}
+	// Zero the evicted entries so their content can be collected
+	clear(s.Messages[:n])
+	s.Messages = s.Messages[n:]
+	s.messageBytes = s.messageBytes[n:]
+	s.Evicted += n
+	// Adjust ViewedIndex to account for dropped messages
+	s.ViewedIndex = max(0, s.ViewedIndex-n)
+	s.UnreadCount = max(0, len(s.Messages)-s.ViewedIndex)
+}
+
+// removeBuffered removes the buffered message at index i.
+func (s *SessionState) removeBuffered(i int) {
+	s.BufferBytes -= int64(s.messageBytes[i])
+	s.Messages = append(s.Messages[:i], s.Messages[i+1:]...)
+	s.messageBytes = append(s.messageBytes[:i], s.messageBytes[i+1:]...)
+	if i < s.ViewedIndex {
+		s.ViewedIndex--
+	}
+	s.UnreadCount = max(0, len(s.Messages)-s.ViewedIndex)
+}
+
+// resetBuffer empties the buffer.
+func (s *SessionState) resetBuffer() {
+	s.Messages = make([]types.Message, 0)
+	s.messageBytes = nil
+	s.BufferBytes = 0
+	s.Evicted = 0
+}
+
+// estimateMessageBytes approximates the memory held by a message's content
+// (strings dominate; struct overhead is ignored).
+func estimateMessageBytes(msg types.Message) int {
+	size := len(msg.UUID) + len(msg.Type) + len(msg.Content) + len(msg.Timestamp) + len(msg.CompactionPreview)
+	for _, block := range msg.ContentBlocks {
+		size += len(block.Text) + len(block.Thinking) + len(block.Signature) + len(block.ID) + len(block.Name)
+		size += estimateValueBytes(block.Input) + estimateValueBytes(block.Content)
+		if block.Source != nil {
+			size += len(block.Source.Data)
+		}
+	}
+	return size
+}
+
+// estimateValueBytes approximates the size of decoded JSON content.
+func estimateValueBytes(v any) int {
+	switch v := v.(type) {
+	case string:
+		return len(v)
+	case map[string]any:
+		size := 0
+		for k, item := range v {
+			size += len(k) + estimateValueBytes(item)
+		}
+		return size
+	case []any:
+		size := 0
+		for _, item := range v {
+			size += estimateValueBytes(item)
+		}
+		return size
+	case nil:
+		return 0
+	default:
+		return 8
+	}
+}
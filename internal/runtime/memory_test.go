@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"claudefu/internal/types"
+	"claudefu/internal/workspace"
+)
+
+// bufferedSession returns a session holding n messages of 100 estimated bytes each.
+func bufferedSession(n int) *SessionState {
+	session := &SessionState{}
+	messages := make([]types.Message, n)
+	for i := range messages {
+		messages[i] = types.Message{Content: strings.Repeat("x", 100)}
+	}
+	session.appendBuffered(messages)
+	return session
+}
+
+func TestTrimSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      BufferLimits
+		wantKept    int
+		wantEvicted int
+	}{
+		{"within limits", BufferLimits{MaxMessages: 20}, 10, 0},
+		{"message count", BufferLimits{MaxMessages: 5}, 5, 5},
+		{"byte size", BufferLimits{MaxMessages: 20, MaxBytes: 450}, 4, 6},
+		{"newest message always kept", BufferLimits{MaxMessages: 20, MaxBytes: 50}, 1, 9},
+		{"tighter of both", BufferLimits{MaxMessages: 3, MaxBytes: 800}, 3, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := bufferedSession(10)
+			trimSession(session, tt.limits)
+			if len(session.Messages) != tt.wantKept || session.Evicted != tt.wantEvicted {
+				t.Errorf("kept %d, evicted %d; want %d, %d", len(session.Messages), session.Evicted, tt.wantKept, tt.wantEvicted)
+			}
+			if want := int64(100 * tt.wantKept); session.BufferBytes != want {
+				t.Errorf("BufferBytes = %d, want %d", session.BufferBytes, want)
+			}
+		})
+	}
+}
+
+func TestEnforceMemoryBudget(t *testing.T) {
+	// Two agents with one session each; messages are 100 bytes
+	tests := []struct {
+		name         string
+		budget       int64
+		sizes        [2]int
+		activeFirst  bool
+		wantMessages [2]int
+	}{
+		{"no budget", 0, [2]int{100, 80}, false, [2]int{100, 80}},
+		{"under budget", 20000, [2]int{100, 60}, false, [2]int{100, 60}},
+		{"largest trimmed first", 12000, [2]int{100, 60}, false, [2]int{60, 60}},
+		{"stops at the minimum then moves on", 10000, [2]int{100, 80}, false, [2]int{50, 50}},
+		{"active session spared first", 13000, [2]int{100, 80}, true, [2]int{80, 50}},
+		{"nothing left to evict", 1000, [2]int{60, 60}, false, [2]int{50, 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := NewWorkspaceRuntime(&workspace.Workspace{ID: "ws"}, nil)
+			ids := [2]string{"agent-one", "agent-two"}
+			sessions := [2]*SessionState{}
+			for i, id := range ids {
+				sessions[i] = bufferedSession(tt.sizes[i])
+				rt.agentStates[id] = &AgentState{Sessions: map[string]*SessionState{"session-" + id: sessions[i]}}
+			}
+			if tt.activeFirst {
+				rt.SetActiveSession(ids[0], "session-"+ids[0])
+			}
+
+			rt.SetMemoryBudget(tt.budget)
+			for i, session := range sessions {
+				if got := len(session.Messages); got != tt.wantMessages[i] {
+					t.Errorf("%s kept %d messages, want %d", ids[i], got, tt.wantMessages[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package runtime
+
+import (
+	"strings"
+	"time"
+
+	"claudefu/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// OPTIMISTIC SEND ECHO
+// The CLI writes the user's message to the JSONL only once it has started,
+// so a sent message would appear after a visible delay. Instead the send
+// pipeline appends a provisional copy (IsPending) right away. When the real
+// user message arrives it replaces the provisional one; if the send fails the
+// provisional message is removed. Either way session:pending-resolved tells
+// the frontend which provisional message went away.
+// =============================================================================
+
+// pendingUUIDPrefix marks the UUIDs of provisional messages.
+const pendingUUIDPrefix = "pending-"
+
+// pendingMatchSkew is how far apart a provisional message and the real one
+// may be timestamped when they can't be matched by content (clock skew, and
+// the CLI's own timestamp being taken after startup).
+const pendingMatchSkew = 10 * time.Second
+
+// pendingResolution is a provisional message that was replaced or removed.
+type pendingResolution struct {
+	PendingID string `json:"pendingId"`
+	UUID      string `json:"uuid,omitempty"` // UUID of the real message ("" = send failed)
+}
+
+// AddPendingMessage appends a provisional user message with content to a
+// session and emits it. Returns its ID for RemovePendingMessage, or "" if
+// the session is not loaded.
+func (rt *WorkspaceRuntime) AddPendingMessage(agentID, sessionID, content string) string {
+	rt.mu.Lock()
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		rt.mu.Unlock()
+		return ""
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		rt.mu.Unlock()
+		return ""
+	}
+
+	msg := types.Message{
+		UUID:      pendingUUIDPrefix + uuid.NewString(),
+		Type:      "user",
+		Content:   content,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		IsPending: true,
+	}
+	session.appendBuffered([]types.Message{msg})
+	session.UnreadCount = max(0, len(session.Messages)-session.ViewedIndex)
+	rt.recalculateAgentUnread(agentState)
+	rt.mu.Unlock()
+
+	rt.EmitSessionMessages(agentID, sessionID, []types.Message{msg})
+	return msg.UUID
+}
+
+// RemovePendingMessage removes a provisional message whose send failed. It is
+// a no-op if the real message already replaced it.
+func (rt *WorkspaceRuntime) RemovePendingMessage(agentID, sessionID, pendingID string) {
+	if pendingID == "" {
+		return
+	}
+	rt.mu.Lock()
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		rt.mu.Unlock()
+		return
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		rt.mu.Unlock()
+		return
+	}
+	removed := false
+	for i, msg := range session.Messages {
+		if msg.UUID == pendingID {
+			session.removeBuffered(i)
+			rt.recalculateAgentUnread(agentState)
+			removed = true
+			break
+		}
+	}
+	rt.mu.Unlock()
+
+	if removed {
+		rt.emitPendingResolved(agentID, sessionID, []pendingResolution{{PendingID: pendingID}})
+	}
+}
+
+// reconcilePending removes the provisional messages that real user messages
+// in incoming replace: matched by content first (the real prompt may carry a
+// context block ahead of the text), then by timestamp, oldest first.
+// Caller must hold rt.mu.
+func (s *SessionState) reconcilePending(incoming []types.Message) []pendingResolution {
+	var resolved []pendingResolution
+	for _, real := range incoming {
+		if real.Type != "user" || real.IsPending || real.IsCompaction {
+			continue
+		}
+		index := s.matchPending(real)
+		if index < 0 {
+			continue
+		}
+		resolved = append(resolved, pendingResolution{PendingID: s.Messages[index].UUID, UUID: real.UUID})
+		s.removeBuffered(index)
+	}
+	return resolved
+}
+
+// matchPending returns the buffer index of the provisional message real
+// replaces, or -1. Caller must hold rt.mu.
+func (s *SessionState) matchPending(real types.Message) int {
+	realContent := strings.TrimSpace(real.Content)
+	realTime := parseTimestampToTime(real.Timestamp)
+	byTime := -1
+	for i, msg := range s.Messages {
+		if !msg.IsPending {
+			continue
+		}
+		if content := strings.TrimSpace(msg.Content); content != "" && strings.HasSuffix(realContent, content) {
+			return i
+		}
+		if byTime < 0 {
+			pendingTime := parseTimestampToTime(msg.Timestamp)
+			if realTime.IsZero() || !realTime.Before(pendingTime.Add(-pendingMatchSkew)) {
+				byTime = i
+			}
+		}
+	}
+	return byTime
+}
+
+// emitPendingResolved emits session:pending-resolved for provisional messages
+// that were replaced by their real message or removed after a failed send.
+func (rt *WorkspaceRuntime) emitPendingResolved(agentID, sessionID string, resolved []pendingResolution) {
+	rt.Emit("session:pending-resolved", agentID, sessionID, map[string]any{
+		"resolved": resolved,
+	})
+}
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"slices"
+	"testing"
+
+	"claudefu/internal/types"
+)
+
+func TestReconcilePending(t *testing.T) {
+	// u0 is a real message; p1 and p2 are provisional, sent at 10:00:00 and 10:00:01
+	newSession := func() *SessionState {
+		session := &SessionState{}
+		session.appendBuffered([]types.Message{
+			{UUID: "u0", Type: "user", Content: "hello", Timestamp: "2026-03-04T09:59:00Z"},
+			{UUID: "p1", Type: "user", Content: "fix the bug", Timestamp: "2026-03-04T10:00:00Z", IsPending: true},
+			{UUID: "p2", Type: "user", Content: "run tests ", Timestamp: "2026-03-04T10:00:01Z", IsPending: true},
+		})
+		return session
+	}
+	user := func(uuid, content, timestamp string) types.Message {
+		return types.Message{UUID: uuid, Type: "user", Content: content, Timestamp: timestamp}
+	}
+
+	tests := []struct {
+		name      string
+		incoming  []types.Message
+		want      []pendingResolution
+		remaining []string
+	}{
+		{
+			name:      "by content",
+			incoming:  []types.Message{user("r1", "run tests", "2026-03-04T10:00:05Z")},
+			want:      []pendingResolution{{PendingID: "p2", UUID: "r1"}},
+			remaining: []string{"u0", "p1"},
+		},
+		{
+			name:      "content after a context block",
+			incoming:  []types.Message{user("r1", "<context>branch: main</context>\n\nfix the bug", "2026-03-04T10:00:05Z")},
+			want:      []pendingResolution{{PendingID: "p1", UUID: "r1"}},
+			remaining: []string{"u0", "p2"},
+		},
+		{
+			name:      "by timestamp, oldest first",
+			incoming:  []types.Message{user("r1", "rewritten prompt", "2026-03-04T10:00:02Z")},
+			want:      []pendingResolution{{PendingID: "p1", UUID: "r1"}},
+			remaining: []string{"u0", "p2"},
+		},
+		{
+			name:      "within clock skew",
+			incoming:  []types.Message{user("r1", "rewritten prompt", "2026-03-04T09:59:55Z")},
+			want:      []pendingResolution{{PendingID: "p1", UUID: "r1"}},
+			remaining: []string{"u0", "p2"},
+		},
+		{
+			name:      "missing timestamp",
+			incoming:  []types.Message{user("r1", "rewritten prompt", "")},
+			want:      []pendingResolution{{PendingID: "p1", UUID: "r1"}},
+			remaining: []string{"u0", "p2"},
+		},
+		{
+			name:      "older than any pending",
+			incoming:  []types.Message{user("r1", "rewritten prompt", "2026-03-04T09:58:00Z")},
+			remaining: []string{"u0", "p1", "p2"},
+		},
+		{
+			name: "both in one batch",
+			incoming: []types.Message{
+				user("r1", "fix the bug", "2026-03-04T10:00:03Z"),
+				{UUID: "a1", Type: "assistant", Content: "On it", Timestamp: "2026-03-04T10:00:04Z"},
+				user("r2", "run tests", "2026-03-04T10:00:05Z"),
+			},
+			want:      []pendingResolution{{PendingID: "p1", UUID: "r1"}, {PendingID: "p2", UUID: "r2"}},
+			remaining: []string{"u0"},
+		},
+		{
+			name: "assistant and compaction messages ignored",
+			incoming: []types.Message{
+				{UUID: "a1", Type: "assistant", Content: "fix the bug", Timestamp: "2026-03-04T10:00:03Z"},
+				{UUID: "c1", Type: "user", Content: "fix the bug", Timestamp: "2026-03-04T10:00:03Z", IsCompaction: true},
+			},
+			remaining: []string{"u0", "p1", "p2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newSession()
+			got := session.reconcilePending(tt.incoming)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("resolved = %+v, want %+v", got, tt.want)
+			}
+			var remaining []string
+			for _, msg := range session.Messages {
+				remaining = append(remaining, msg.UUID)
+			}
+			if !slices.Equal(remaining, tt.remaining) {
+				t.Errorf("buffer = %v, want %v", remaining, tt.remaining)
+			}
+			if want := len(session.Messages); len(session.messageBytes) != want {
+				t.Errorf("%d size entries for %d messages", len(session.messageBytes), want)
+			}
+		})
+	}
+}
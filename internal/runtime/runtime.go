@@ -3,27 +3,39 @@
 package runtime
 
 import (
-	"fmt"
 	"maps"
-	"os"
-	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"claudefu/internal/claudehome"
+	"claudefu/internal/logging"
 	"claudefu/internal/types"
 	"claudefu/internal/workspace"
 )
 
+// logger is the runtime subsystem logger (see internal/logging).
+var logger = logging.For(logging.Runtime)
+
 // =============================================================================
 // CONSTANTS
 // =============================================================================
 
-// MaxBufferSize is the maximum number of messages to keep in memory per session.
+// MaxBufferSize is the default maximum number of messages to keep in memory per session.
 // This creates a FIFO buffer - older messages are dropped when limit is exceeded.
 // For older history, we can read from disk on demand.
 // With ~6-8 agents per workspace max, 750 messages per session is reasonable.
+// Settings and per-agent limits can change it (see BufferLimits).
 const MaxBufferSize = 750
 
+// Session presence states, emitted as session:presence.
+const (
+	PresenceIdle      = ""          // No process has run since the session was loaded
+	PresenceThinking  = "thinking"  // Process running, no assistant output yet
+	PresenceStreaming = "streaming" // Assistant output is arriving
+	PresenceCompleted = "completed" // Process exited
+)
+
 // =============================================================================
 // WORKSPACE RUNTIME - Single State Container
 // =============================================================================
@@ -38,14 +50,23 @@ type WorkspaceRuntime struct {
 	activeAgentID   string
 	activeSessionID string
 	emitFunc        func(types.EventEnvelope)
+	bufferDefaults  BufferLimits // Limits for agents without their own
+	memoryBudget    int64        // Cap on all buffers together in bytes (0 = none)
 	mu              sync.RWMutex
+
+	// Frontend event subscriptions (see Subscribe). Until the first Subscribe call
+	// everything is emitted, so frontends that never subscribe keep working.
+	subscriptionsActive bool
+	subscriptions       map[string]bool // subscriptionKey -> subscribed
 }
 
 // AgentState holds runtime state for a single agent.
 type AgentState struct {
-	Agent       workspace.Agent
-	Sessions    map[string]*SessionState // session_id -> state
-	TotalUnread int
+	Agent          workspace.Agent
+	Sessions       map[string]*SessionState // session_id -> state
+	TotalUnread    int
+	PostProcessors []string // Applied to incoming messages in AppendMessages
+	BufferLimits   BufferLimits // Per-agent overrides (zero fields = runtime defaults)
 }
 
 // SessionState holds runtime state for a single session.
@@ -55,6 +76,11 @@ type SessionState struct {
 	AgentID         string
 	Messages        []types.Message
 	FilePosition    int64     // For delta reads from JSONL
+	HistoryEnd      int64     // Offset of the last compaction boundary; earlier messages were not loaded (0 = all loaded)
+	MessageOffsets  map[string]int64 // Message UUID -> byte offset of its JSONL line (kept for evicted messages too)
+	BufferBytes     int64     // Estimated size of Messages
+	Evicted         int       // Messages dropped from the buffer by its limits or the memory budget
+	messageBytes    []int     // Estimated size of each buffered message
 	InitialLoadDone bool      // True after initial load completes (prevents race with delta reads)
 	LastViewedAt    time.Time // Persisted, used to calculate ViewedIndex on load
 	ViewedIndex     int       // Index up to which user has seen messages
@@ -62,6 +88,17 @@ type SessionState struct {
 	Preview         string    // First user message preview
 	Slug            string    // Session slug (e.g., "polymorphic-roaming-hummingbird") - plan file at ~/.claude/plans/{slug}.md
 	LastSendTime    time.Time // Time when user sent last message (for timestamp-based filtering)
+	LastPreview     string    // Last user/assistant text snippet
+	LastRole        string    // Role of the message LastPreview came from ("user" or "assistant")
+	IsStreaming     bool      // True while a Claude CLI process is running for this session
+	PlanMode        bool      // True if the last send was in plan mode
+	Presence        string    // One of the Presence* states
+	PresenceSince   time.Time // When Presence last changed
+	Status          string    // One of the Status* states (see status.go; "" = idle)
+	StatusSince     time.Time // When Status last changed
+	StatusTool      string    // Tool being run or waited on
+	pendingTools    map[string]string // tool_use ID -> tool name, awaiting a tool_result
+	fileActivity    *FileTracker      // Files changed by tool calls (see files.go)
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -78,21 +115,30 @@ func NewWorkspaceRuntime(ws *workspace.Workspace, emitFunc func(types.EventEnvel
 		agentStates:     make(map[string]*AgentState),
 		folderToAgentID: make(map[string]string),
 		emitFunc:        emitFunc,
+		bufferDefaults:  BufferLimits{MaxMessages: MaxBufferSize},
+		subscriptions:   make(map[string]bool),
 	}
 
 	// Initialize agent states and folder mapping
 	for _, agent := range ws.Agents {
-		rt.agentStates[agent.ID] = &AgentState{
-			Agent:       agent,
-			Sessions:    make(map[string]*SessionState),
-			TotalUnread: 0,
-		}
+		rt.agentStates[agent.ID] = newAgentState(agent)
 		rt.folderToAgentID[agent.Folder] = agent.ID
 	}
 
 	return rt
 }
 
+// newAgentState returns the empty runtime state for an agent.
+func newAgentState(agent workspace.Agent) *AgentState {
+	return &AgentState{
+		Agent:          agent,
+		Sessions:       make(map[string]*SessionState),
+		TotalUnread:    0,
+		PostProcessors: agent.PostProcessors,
+		BufferLimits:   BufferLimits{MaxMessages: agent.BufferMaxMessages, MaxBytes: agent.BufferMaxBytes},
+	}
+}
+
 // =============================================================================
 // WORKSPACE ACCESSORS
 // =============================================================================
@@ -140,6 +186,22 @@ func (rt *WorkspaceRuntime) GetAllAgentStates() map[string]*AgentState {
 	return result
 }
 
+// SetAgentPostProcessors sets the message post-processors for an agent.
+// Only affects messages appended afterwards; already-buffered messages are left as-is.
+func (rt *WorkspaceRuntime) SetAgentPostProcessors(agentID string, processors []string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		agentState = &AgentState{
+			Sessions: make(map[string]*SessionState),
+		}
+		rt.agentStates[agentID] = agentState
+	}
+	agentState.PostProcessors = processors
+}
+
 // =============================================================================
 // SESSION STATE MANAGEMENT
 // =============================================================================
@@ -164,7 +226,7 @@ func (rt *WorkspaceRuntime) GetOrCreateSessionState(agentID, sessionID string) *
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
 		// Create agent state if it doesn't exist (e.g., newly added agent)
-		fmt.Printf("[DEBUG] GetOrCreateSessionState: CREATING NEW agentState for agent=%s\n", agentID[:8])
+		logger.Debugf("GetOrCreateSessionState: CREATING NEW agentState for agent=%s", agentID[:8])
 		agentState = &AgentState{
 			Sessions: make(map[string]*SessionState),
 		}
@@ -173,7 +235,7 @@ func (rt *WorkspaceRuntime) GetOrCreateSessionState(agentID, sessionID string) *
 
 	session, exists := agentState.Sessions[sessionID]
 	if !exists {
-		fmt.Printf("[DEBUG] GetOrCreateSessionState: CREATING NEW session=%s agent=%s (FilePosition will be 0!)\n", sessionID[:8], agentID[:8])
+		logger.Debugf("GetOrCreateSessionState: CREATING NEW session=%s agent=%s (FilePosition will be 0!)", sessionID[:8], agentID[:8])
 		session = &SessionState{
 			SessionID:   sessionID,
 			AgentID:     agentID,
@@ -185,7 +247,7 @@ func (rt *WorkspaceRuntime) GetOrCreateSessionState(agentID, sessionID string) *
 		}
 		agentState.Sessions[sessionID] = session
 	} else {
-		fmt.Printf("[DEBUG] GetOrCreateSessionState: FOUND EXISTING session=%s filePos=%d msgCount=%d initialLoadDone=%v\n",
+		logger.Debugf("GetOrCreateSessionState: FOUND EXISTING session=%s filePos=%d msgCount=%d initialLoadDone=%v",
 			sessionID[:8], session.FilePosition, len(session.Messages), session.InitialLoadDone)
 	}
 	return session
@@ -245,6 +307,72 @@ func (rt *WorkspaceRuntime) GetSessionsForAgent(agentID string) []*SessionState
 	return sessions
 }
 
+// SessionCount returns the number of sessions tracked across all agents.
+func (rt *WorkspaceRuntime) SessionCount() int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	count := 0
+	for _, agentState := range rt.agentStates {
+		count += len(agentState.Sessions)
+	}
+	return count
+}
+
+// GetSessionSummaries returns session list entries (with status fields) for an agent.
+func (rt *WorkspaceRuntime) GetSessionSummaries(agentID string) []types.Session {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return nil
+	}
+
+	summaries := make([]types.Session, 0, len(agentState.Sessions))
+	for _, s := range agentState.Sessions {
+		summaries = append(summaries, buildSessionSummary(s))
+	}
+	return summaries
+}
+
+// GetSessionSummary returns the session list entry for a single session, or nil if unknown.
+func (rt *WorkspaceRuntime) GetSessionSummary(agentID, sessionID string) *types.Session {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return nil
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	summary := buildSessionSummary(session)
+	return &summary
+}
+
+// buildSessionSummary converts a SessionState to its list entry. Caller must hold rt.mu.
+func buildSessionSummary(s *SessionState) types.Session {
+	return types.Session{
+		ID:                 s.SessionID,
+		AgentID:            s.AgentID,
+		Preview:            s.Preview,
+		MessageCount:       len(s.Messages),
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
+		LastMessagePreview: s.LastPreview,
+		LastRole:           s.LastRole,
+		HasPendingQuestion: hasPendingQuestion(s.Messages),
+		IsStreaming:        s.IsStreaming,
+		PlanMode:           s.PlanMode,
+		Presence:           s.Presence,
+		Status:             s.statusSnapshot().Status,
+		IsQuery:            types.IsQueryPrompt(s.Preview),
+	}
+}
+
 // RefreshSessionUpdatedAt updates the UpdatedAt timestamp for an existing session.
 // This is called during rescan to sync UpdatedAt with the file's modification time.
 func (rt *WorkspaceRuntime) RefreshSessionUpdatedAt(agentID, sessionID string, updatedAt time.Time) {
@@ -305,18 +433,38 @@ func (rt *WorkspaceRuntime) IsActiveSession(agentID, sessionID string) bool {
 // AppendMessages adds new messages to a session and updates unread counts.
 // Returns the slice of actually added messages (after deduplication).
 func (rt *WorkspaceRuntime) AppendMessages(agentID, sessionID string, messages []types.Message) []types.Message {
+	// Registered before the lock so presence and status events are emitted after unlocking
+	var presenceChanged *SessionState
+	var statusChanged *SessionStatus
+	var pendingResolved []pendingResolution
+	var filesChanged []FileActivity
+	defer func() {
+		if len(pendingResolved) > 0 {
+			rt.emitPendingResolved(agentID, sessionID, pendingResolved)
+		}
+		if filesChanged != nil {
+			rt.Emit("session:files_changed", agentID, sessionID, map[string]any{"files": filesChanged})
+		}
+		if presenceChanged != nil {
+			rt.emitPresence(agentID, sessionID, PresenceStreaming, presenceChanged.PresenceSince)
+		}
+		if statusChanged != nil {
+			rt.emitStatus(agentID, sessionID, *statusChanged)
+		}
+	}()
+
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] AppendMessages: agent %s not found\n", agentID)
+		logger.Debugf("AppendMessages: agent %s not found", agentID)
 		return nil
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] AppendMessages: session %s not found for agent %s\n", sessionID, agentID)
+		logger.Debugf("AppendMessages: session %s not found for agent %s", sessionID, agentID)
 		return nil
 	}
 
@@ -347,22 +495,32 @@ func (rt *WorkspaceRuntime) AppendMessages(agentID, sessionID string, messages [
 	for _, msg := range newMessages {
 		typeCounts[msg.Type]++
 	}
-	fmt.Printf("[DEBUG] AppendMessages: agent=%s session=%s incoming=%d duplicates=%d adding=%d (types: %v) prevCount=%d\n",
+	logger.Debugf("AppendMessages: agent=%s session=%s incoming=%d duplicates=%d adding=%d (types: %v) prevCount=%d",
 		agentID[:8], sessionID[:8], len(messages), duplicateCount, len(newMessages), typeCounts, len(session.Messages))
 
 	if len(newMessages) == 0 {
 		return nil
 	}
 
+	// Real user messages replace their provisional echoes (matched on the raw content)
+	pendingResolved = session.reconcilePending(newMessages)
+
+	// Per-agent post-processing (ANSI stripping, path linking, ...) before storing,
+	// so every consumer of the buffer sees the same processed content
+	newMessages = types.PostProcessMessages(newMessages, agentState.PostProcessors)
+
 	prevCount := len(session.Messages)
-	session.Messages = append(session.Messages, newMessages...)
+	session.appendBuffered(newMessages)
 
-	// Enforce FIFO buffer limit - trim oldest messages if over limit
-	if len(session.Messages) > MaxBufferSize {
-		excess := len(session.Messages) - MaxBufferSize
-		session.Messages = session.Messages[excess:]
-		// Adjust ViewedIndex to account for dropped messages
-		session.ViewedIndex = max(0, session.ViewedIndex-excess)
+	// Enforce FIFO buffer limits - trim oldest messages if over limit
+	trimSession(session, rt.limitsLocked(agentState))
+
+	// Track files changed by tool calls (survives buffer trimming)
+	if session.fileActivity == nil {
+		session.fileActivity = NewFileTracker(agentState.Agent.Folder)
+	}
+	if session.fileActivity.Add(newMessages) && session.InitialLoadDone {
+		filesChanged = session.fileActivity.Activity()
 	}
 
 	// Update timestamps from actual message data
@@ -383,13 +541,36 @@ func (rt *WorkspaceRuntime) AppendMessages(agentID, sessionID string, messages [
 	// Update preview if this is the first message
 	if session.Preview == "" && len(messages) > 0 {
 		for _, msg := range messages {
-			if msg.Type == "user" && msg.Content != "" {
+			if msg.Type == "user" && msg.Content != "" && !msg.IsCompaction {
 				session.Preview = truncatePreview(msg.Content, 100)
 				break
 			}
 		}
 	}
 
+	// Track the most recent text snippet for the session list
+	for i := len(newMessages) - 1; i >= 0; i-- {
+		msg := newMessages[i]
+		if (msg.Type == "user" || msg.Type == "assistant") && msg.Content != "" && !msg.IsSynthetic {
+			session.LastPreview = truncatePreview(msg.Content, 100)
+			session.LastRole = msg.Type
+			break
+		}
+	}
+
+	// First assistant output of a running process moves it from thinking to streaming
+	if session.IsStreaming && session.Presence == PresenceThinking && hasAssistantOutput(newMessages) {
+		session.Presence = PresenceStreaming
+		session.PresenceSince = time.Now()
+		presenceChanged = session
+	}
+
+	// Live messages drive the activity status; the initial load is history
+	if session.InitialLoadDone && session.advanceStatus(newMessages) {
+		status := session.statusSnapshot()
+		statusChanged = &status
+	}
+
 	// Extract session slug from new messages (used to derive plan file path)
 	for _, msg := range messages {
 		if msg.Slug != "" {
@@ -404,6 +585,8 @@ func (rt *WorkspaceRuntime) AppendMessages(agentID, sessionID string, messages [
 	// Update agent total unread
 	rt.recalculateAgentUnread(agentState)
 
+	rt.enforceMemoryBudgetLocked()
+
 	return newMessages
 }
 
@@ -415,13 +598,13 @@ func (rt *WorkspaceRuntime) GetMessages(agentID, sessionID string) []types.Messa
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] GetMessages: agent %s not found\n", agentID)
+		logger.Debugf("GetMessages: agent %s not found", agentID)
 		return nil
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] GetMessages: session %s not found for agent %s\n", sessionID, agentID)
+		logger.Debugf("GetMessages: session %s not found for agent %s", sessionID, agentID)
 		return nil
 	}
 
@@ -430,7 +613,7 @@ func (rt *WorkspaceRuntime) GetMessages(agentID, sessionID string) []types.Messa
 	for _, msg := range session.Messages {
 		typeCounts[msg.Type]++
 	}
-	fmt.Printf("[DEBUG] GetMessages: agent=%s session=%s returning %d messages (types: %v)\n",
+	logger.Debugf("GetMessages: agent=%s session=%s returning %d messages (types: %v)",
 		agentID[:8], sessionID[:8], len(session.Messages), typeCounts)
 
 	// Return a copy with pending question detection applied
@@ -439,6 +622,20 @@ func (rt *WorkspaceRuntime) GetMessages(agentID, sessionID string) []types.Messa
 	return DetectPendingQuestions(result)
 }
 
+// GetSessionSlug returns a session's slug ("" until a message carrying it is loaded).
+func (rt *WorkspaceRuntime) GetSessionSlug(agentID, sessionID string) string {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return ""
+	}
+	if session, ok := agentState.Sessions[sessionID]; ok {
+		return session.Slug
+	}
+	return ""
+}
+
 // GetPlanFilePath returns the active plan file path for a session.
 func (rt *WorkspaceRuntime) GetPlanFilePath(agentID, sessionID string) string {
 	rt.mu.RLock()
@@ -446,27 +643,22 @@ func (rt *WorkspaceRuntime) GetPlanFilePath(agentID, sessionID string) string {
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] GetPlanFilePath: agent %s not found in runtime\n", agentID)
+		logger.Debugf("GetPlanFilePath: agent %s not found in runtime", agentID)
 		return ""
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] GetPlanFilePath: session %s not found for agent %s (have %d sessions)\n", sessionID, agentID, len(agentState.Sessions))
+		logger.Debugf("GetPlanFilePath: session %s not found for agent %s (have %d sessions)", sessionID, agentID, len(agentState.Sessions))
 		return ""
 	}
 
 	if session.Slug == "" {
-		fmt.Printf("[DEBUG] GetPlanFilePath: session %s has no slug\n", sessionID)
-		return ""
-	}
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Printf("[DEBUG] GetPlanFilePath: UserHomeDir error: %v\n", err)
+		logger.Debugf("GetPlanFilePath: session %s has no slug", sessionID)
 		return ""
 	}
-	planPath := filepath.Join(homeDir, ".claude", "plans", session.Slug+".md")
-	fmt.Printf("[DEBUG] GetPlanFilePath: %s → %s\n", session.Slug, planPath)
+	planPath := claudehome.PlanPath(session.Slug)
+	logger.Debugf("GetPlanFilePath: %s → %s", session.Slug, planPath)
 	return planPath
 }
 
@@ -621,11 +813,11 @@ func (rt *WorkspaceRuntime) recalculateAgentUnread(agentState *AgentState) {
 	total := 0
 	for sessionID, session := range agentState.Sessions {
 		if session.UnreadCount > 0 {
-			fmt.Printf("[DEBUG] recalculateAgentUnread: session=%s unread=%d\n", sessionID[:8], session.UnreadCount)
+			logger.Debugf("recalculateAgentUnread: session=%s unread=%d", sessionID[:8], session.UnreadCount)
 		}
 		total += session.UnreadCount
 	}
-	fmt.Printf("[DEBUG] recalculateAgentUnread: agentTotal=%d\n", total)
+	logger.Debugf("recalculateAgentUnread: agentTotal=%d", total)
 	agentState.TotalUnread = total
 }
 
@@ -658,22 +850,107 @@ func (rt *WorkspaceRuntime) SetFilePosition(agentID, sessionID string, pos int64
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] SetFilePosition: agent not found agentID=%s\n", agentID[:8])
+		logger.Debugf("SetFilePosition: agent not found agentID=%s", agentID[:8])
 		return
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] SetFilePosition: session not found sessionID=%s\n", sessionID[:8])
+		logger.Debugf("SetFilePosition: session not found sessionID=%s", sessionID[:8])
 		return
 	}
 
 	oldPos := session.FilePosition
 	session.FilePosition = pos
-	fmt.Printf("[DEBUG] SetFilePosition: session=%s oldPos=%d newPos=%d delta=%d\n",
+	logger.Debugf("SetFilePosition: session=%s oldPos=%d newPos=%d delta=%d",
 		sessionID[:8], oldPos, pos, pos-oldPos)
 }
 
+// SetHistoryEnd records where the loaded messages start when the session was
+// loaded from its last compaction boundary (0 = the whole file was loaded).
+func (rt *WorkspaceRuntime) SetHistoryEnd(agentID, sessionID string, offset int64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if agentState, ok := rt.agentStates[agentID]; ok {
+		if session, ok := agentState.Sessions[sessionID]; ok {
+			session.HistoryEnd = offset
+		}
+	}
+}
+
+// GetHistoryEnd returns the offset before which messages were left on disk at
+// load time (0 = none were).
+func (rt *WorkspaceRuntime) GetHistoryEnd(agentID, sessionID string) int64 {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	if agentState, ok := rt.agentStates[agentID]; ok {
+		if session, ok := agentState.Sessions[sessionID]; ok {
+			return session.HistoryEnd
+		}
+	}
+	return 0
+}
+
+// AddMessageOffsets records where messages start in the session's JSONL file.
+// The first offset seen for a UUID wins (resumed sessions can repeat UUIDs).
+func (rt *WorkspaceRuntime) AddMessageOffsets(agentID, sessionID string, offsets map[string]int64) {
+	if len(offsets) == 0 {
+		return
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		return
+	}
+	if session.MessageOffsets == nil {
+		session.MessageOffsets = make(map[string]int64, len(offsets))
+	}
+	for uuid, offset := range offsets {
+		if _, seen := session.MessageOffsets[uuid]; !seen {
+			session.MessageOffsets[uuid] = offset
+		}
+	}
+}
+
+// GetMessageOffset returns the byte offset of a message's JSONL line.
+func (rt *WorkspaceRuntime) GetMessageOffset(agentID, sessionID, uuid string) (int64, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	if agentState, ok := rt.agentStates[agentID]; ok {
+		if session, ok := agentState.Sessions[sessionID]; ok {
+			offset, ok := session.MessageOffsets[uuid]
+			return offset, ok
+		}
+	}
+	return 0, false
+}
+
+// GetBufferStart returns the byte offset of the oldest buffered message, i.e.
+// where messages evicted from the buffer (or never loaded) end on disk.
+func (rt *WorkspaceRuntime) GetBufferStart(agentID, sessionID string) (int64, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return 0, false
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		return 0, false
+	}
+	for _, msg := range session.Messages {
+		if offset, ok := session.MessageOffsets[msg.UUID]; ok {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
 // =============================================================================
 // SEND TIME TRACKING (for timestamp-based message filtering)
 // =============================================================================
@@ -686,18 +963,65 @@ func (rt *WorkspaceRuntime) SetLastSendTime(agentID, sessionID string, t time.Ti
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] SetLastSendTime: agent not found agentID=%s\n", agentID[:8])
+		logger.Debugf("SetLastSendTime: agent not found agentID=%s", agentID[:8])
 		return
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] SetLastSendTime: session not found sessionID=%s\n", sessionID[:8])
+		logger.Debugf("SetLastSendTime: session not found sessionID=%s", sessionID[:8])
 		return
 	}
 
 	session.LastSendTime = t
-	fmt.Printf("[DEBUG] SetLastSendTime: session=%s time=%v\n", sessionID[:8], t.Format(time.RFC3339))
+	logger.Debugf("SetLastSendTime: session=%s time=%v", sessionID[:8], t.Format(time.RFC3339))
+}
+
+// SetStreaming marks whether a Claude CLI process is running for a session.
+// planMode records the mode of the send that started it (ignored when streaming is false).
+// Starting a process sets presence to thinking; stopping it sets completed.
+// The activity status moves to thinking or idle accordingly.
+func (rt *WorkspaceRuntime) SetStreaming(agentID, sessionID string, streaming, planMode bool) {
+	rt.mu.Lock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		rt.mu.Unlock()
+		return
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		rt.mu.Unlock()
+		return
+	}
+
+	session.IsStreaming = streaming
+	if streaming {
+		session.PlanMode = planMode
+		session.Presence = PresenceThinking
+	} else {
+		session.Presence = PresenceCompleted
+	}
+	session.PresenceSince = time.Now()
+	presence, since := session.Presence, session.PresenceSince
+	statusChanged := session.setProcessStatus(streaming)
+	status := session.statusSnapshot()
+	rt.mu.Unlock()
+
+	rt.emitPresence(agentID, sessionID, presence, since)
+	if statusChanged {
+		rt.emitStatus(agentID, sessionID, status)
+	}
+}
+
+// hasAssistantOutput reports whether messages contain real (non-synthetic) assistant output.
+func hasAssistantOutput(messages []types.Message) bool {
+	for _, msg := range messages {
+		if msg.Type == "assistant" && !msg.IsSynthetic {
+			return true
+		}
+	}
+	return false
 }
 
 // GetLastSendTime returns the time when the user last sent a message.
@@ -719,6 +1043,46 @@ func (rt *WorkspaceRuntime) GetLastSendTime(agentID, sessionID string) time.Time
 	return session.LastSendTime
 }
 
+// =============================================================================
+// EVENT SUBSCRIPTIONS
+// =============================================================================
+
+// subscriptionKey returns the map key for an agent scope (empty sessionID) or a session scope.
+func subscriptionKey(agentID, sessionID string) string {
+	if sessionID == "" {
+		return agentID
+	}
+	return agentID + "/" + sessionID
+}
+
+// Subscribe marks an agent (empty sessionID) or a single session as rendered by the frontend.
+// Once any subscription exists, session:messages and unread:changed are only emitted for
+// subscribed scopes. Internal state keeps updating either way.
+func (rt *WorkspaceRuntime) Subscribe(agentID, sessionID string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.subscriptionsActive = true
+	rt.subscriptions[subscriptionKey(agentID, sessionID)] = true
+}
+
+// Unsubscribe removes a scope previously added with Subscribe.
+func (rt *WorkspaceRuntime) Unsubscribe(agentID, sessionID string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	delete(rt.subscriptions, subscriptionKey(agentID, sessionID))
+}
+
+// IsSubscribed reports whether events for a session should reach the frontend.
+// An agent-level subscription covers all of that agent's sessions.
+func (rt *WorkspaceRuntime) IsSubscribed(agentID, sessionID string) bool {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	if !rt.subscriptionsActive {
+		return true
+	}
+	return rt.subscriptions[agentID] || rt.subscriptions[subscriptionKey(agentID, sessionID)]
+}
+
 // =============================================================================
 // EVENT EMISSION
 // =============================================================================
@@ -742,6 +1106,10 @@ func (rt *WorkspaceRuntime) Emit(eventType string, agentID, sessionID string, pa
 
 // EmitUnreadChanged emits an unread:changed event for a session.
 func (rt *WorkspaceRuntime) EmitUnreadChanged(agentID, sessionID string) {
+	if !rt.IsSubscribed(agentID, sessionID) {
+		return
+	}
+
 	rt.mu.RLock()
 	agentState := rt.agentStates[agentID]
 	var unread, agentTotal int
@@ -753,7 +1121,7 @@ func (rt *WorkspaceRuntime) EmitUnreadChanged(agentID, sessionID string) {
 	}
 	rt.mu.RUnlock()
 
-	fmt.Printf("[DEBUG] EmitUnreadChanged: session=%s unread=%d agentTotal=%d\n", sessionID[:8], unread, agentTotal)
+	logger.Debugf("EmitUnreadChanged: session=%s unread=%d agentTotal=%d", sessionID[:8], unread, agentTotal)
 
 	rt.Emit("unread:changed", agentID, sessionID, map[string]int{
 		"unread":     unread,
@@ -761,10 +1129,23 @@ func (rt *WorkspaceRuntime) EmitUnreadChanged(agentID, sessionID string) {
 	})
 }
 
+// emitPresence emits session:presence. Unlike session:messages it ignores
+// subscriptions, since status chips are shown for sessions that aren't open.
+func (rt *WorkspaceRuntime) emitPresence(agentID, sessionID, presence string, since time.Time) {
+	rt.Emit("session:presence", agentID, sessionID, map[string]any{
+		"state": presence,
+		"since": since.UnixMilli(),
+	})
+}
+
 // EmitSessionMessages emits a session:messages event.
 // Applies pending question detection on FULL session (not just delta) since
 // the tool_use and tool_result may be in different events.
 func (rt *WorkspaceRuntime) EmitSessionMessages(agentID, sessionID string, messages []types.Message) {
+	if !rt.IsSubscribed(agentID, sessionID) {
+		return
+	}
+
 	// Get the full session messages for proper pending question detection
 	// The tool_use (AskUserQuestion) and tool_result (is_error) may be in different events
 	rt.mu.RLock()
@@ -831,6 +1212,49 @@ func (rt *WorkspaceRuntime) Clear() {
 	rt.folderToAgentID = make(map[string]string)
 	rt.activeAgentID = ""
 	rt.activeSessionID = ""
+	rt.subscriptionsActive = false
+	rt.subscriptions = make(map[string]bool)
+}
+
+// AddAgent registers an agent added (or restored) after the runtime was created.
+// Sessions already buffered for the agent are kept.
+func (rt *WorkspaceRuntime) AddAgent(agent workspace.Agent) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	state := newAgentState(agent)
+	if existing, ok := rt.agentStates[agent.ID]; ok {
+		state.Sessions = existing.Sessions
+		state.TotalUnread = existing.TotalUnread
+	}
+	rt.agentStates[agent.ID] = state
+	rt.folderToAgentID[agent.Folder] = agent.ID
+}
+
+// RemoveAgent drops an agent's state: buffered sessions, unread counts, folder
+// mapping, and event subscriptions. Returns true if the active session was the
+// agent's (it is cleared).
+func (rt *WorkspaceRuntime) RemoveAgent(agentID string) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	delete(rt.agentStates, agentID)
+	for folder, id := range rt.folderToAgentID {
+		if id == agentID {
+			delete(rt.folderToAgentID, folder)
+		}
+	}
+	for key := range rt.subscriptions {
+		if key == agentID || strings.HasPrefix(key, agentID+"/") {
+			delete(rt.subscriptions, key)
+		}
+	}
+	if rt.activeAgentID != agentID {
+		return false
+	}
+	rt.activeAgentID = ""
+	rt.activeSessionID = ""
+	return true
 }
 
 // ClearSession clears a session's message cache and resets its state.
@@ -841,27 +1265,46 @@ func (rt *WorkspaceRuntime) ClearSession(agentID, sessionID string) {
 
 	agentState, ok := rt.agentStates[agentID]
 	if !ok {
-		fmt.Printf("[DEBUG] ClearSession: agent %s not found\n", agentID)
+		logger.Debugf("ClearSession: agent %s not found", agentID)
 		return
 	}
 
 	session, ok := agentState.Sessions[sessionID]
 	if !ok {
-		fmt.Printf("[DEBUG] ClearSession: session %s not found for agent %s\n", sessionID, agentID)
+		logger.Debugf("ClearSession: session %s not found for agent %s", sessionID, agentID)
 		return
 	}
 
-	fmt.Printf("[DEBUG] ClearSession: clearing %d messages for agent=%s session=%s\n",
+	logger.Debugf("ClearSession: clearing %d messages for agent=%s session=%s",
 		len(session.Messages), agentID[:8], sessionID[:8])
 
 	// Clear messages and reset state for reload
-	session.Messages = make([]types.Message, 0)
+	session.resetBuffer()
 	session.FilePosition = 0
+	session.HistoryEnd = 0
+	session.MessageOffsets = nil
+	session.fileActivity = nil
 	session.InitialLoadDone = false
 	session.Slug = ""
 	// Keep ViewedIndex and LastViewedAt - these represent user's read state
 }
 
+// RemoveSession drops a session from the runtime (e.g. after its file was deleted).
+func (rt *WorkspaceRuntime) RemoveSession(agentID, sessionID string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return
+	}
+	if _, ok := agentState.Sessions[sessionID]; !ok {
+		return
+	}
+	delete(agentState.Sessions, sessionID)
+	rt.recalculateAgentUnread(agentState)
+}
+
 // =============================================================================
 // PENDING QUESTION DETECTION
 // =============================================================================
@@ -956,7 +1399,7 @@ func DetectPendingQuestions(messages []types.Message) []types.Message {
 				msgTime := parseTimestampToTime(messages[fq.messageIndex].Timestamp)
 				if !msgTime.IsZero() && time.Since(msgTime) > 2*time.Hour {
 					isPending = false
-					fmt.Printf("[DEBUG] DetectPendingQuestions: question %s is stale (%.1f hours old), marking as failed\n",
+					logger.Debugf("DetectPendingQuestions: question %s is stale (%.1f hours old), marking as failed",
 						fq.toolUseID[:8], time.Since(msgTime).Hours())
 				}
 			}
@@ -975,6 +1418,23 @@ func DetectPendingQuestions(messages []types.Message) []types.Message {
 	return messages
 }
 
+// pendingQuestionTail is how many trailing messages hasPendingQuestion inspects.
+// A pending question is by definition at the end of the conversation.
+const pendingQuestionTail = 20
+
+// hasPendingQuestion reports whether the session ends with an unanswered AskUserQuestion.
+func hasPendingQuestion(messages []types.Message) bool {
+	start := max(0, len(messages)-pendingQuestionTail)
+	tail := make([]types.Message, len(messages)-start)
+	copy(tail, messages[start:])
+	for _, msg := range DetectPendingQuestions(tail) {
+		if msg.PendingQuestion != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // convertToMapSlice converts []interface{} to []map[string]interface{}.
 func convertToMapSlice(items []interface{}) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(items))
@@ -0,0 +1,178 @@
+package runtime
+
+import (
+	"strings"
+	"time"
+
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// SESSION ACTIVITY STATUS
+// Presence (runtime.go) is a sidebar chip driven only by the CLI process.
+// Status is the authoritative "is Claude still working" state: it follows the
+// JSONL stream (thinking blocks, assistant text, tool_use and tool_result) as
+// well as the process, so it also tracks sessions driven from a terminal.
+// It is emitted as session:status and read with GetSessionStatus.
+// =============================================================================
+
+// Session activity statuses.
+const (
+	StatusIdle          = "idle"            // Nothing running, ready for the next prompt
+	StatusThinking      = "thinking"        // Waiting for the model (prompt sent, tool result returned, or thinking block)
+	StatusResponding    = "responding"      // Assistant text is arriving
+	StatusToolRunning   = "tool_running"    // A tool_use is waiting for its tool_result
+	StatusWaitingOnUser = "waiting_on_user" // A tool_use needs the user (question, plan review, permission)
+)
+
+// userInputTools are tools whose tool_use blocks until the user responds.
+var userInputTools = map[string]bool{
+	types.ToolNameAskUserQuestion:          true,
+	types.ToolNameExitPlanMode:             true,
+	"mcp__claudefu__AskUserQuestion":       true,
+	"mcp__claudefu__ExitPlanMode":          true,
+	"mcp__claudefu__RequestToolPermission": true,
+}
+
+// SessionStatus is a session's activity status.
+type SessionStatus struct {
+	Status         string    `json:"status"`         // One of the Status* states
+	Since          time.Time `json:"since"`          // When Status last changed
+	Tool           string    `json:"tool,omitempty"` // Tool being run or waited on
+	ProcessRunning bool      `json:"processRunning"` // A Claude CLI process is running for the session
+	Ready          bool      `json:"ready"`          // Idle with no process: a new prompt can be sent
+}
+
+// GetSessionStatus returns a session's activity status.
+// Returns false if the agent or session is not loaded.
+func (rt *WorkspaceRuntime) GetSessionStatus(agentID, sessionID string) (SessionStatus, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	agentState, ok := rt.agentStates[agentID]
+	if !ok {
+		return SessionStatus{}, false
+	}
+	session, ok := agentState.Sessions[sessionID]
+	if !ok {
+		return SessionStatus{}, false
+	}
+	return session.statusSnapshot(), true
+}
+
+// statusSnapshot returns the session's status. Caller must hold rt.mu.
+func (s *SessionState) statusSnapshot() SessionStatus {
+	status := s.Status
+	if status == "" {
+		status = StatusIdle
+	}
+	return SessionStatus{
+		Status:         status,
+		Since:          s.StatusSince,
+		Tool:           s.StatusTool,
+		ProcessRunning: s.IsStreaming,
+		Ready:          status == StatusIdle && !s.IsStreaming,
+	}
+}
+
+// setStatus moves the session to status and reports whether anything changed.
+// Caller must hold rt.mu.
+func (s *SessionState) setStatus(status, tool string) bool {
+	if s.Status == status && s.StatusTool == tool {
+		return false
+	}
+	s.Status, s.StatusTool, s.StatusSince = status, tool, time.Now()
+	return true
+}
+
+// setProcessStatus updates the status when a CLI process starts or exits.
+// Exiting ends every tool still pending (an interrupted turn never gets its
+// tool_results). In --print mode AskUserQuestion fails immediately, so a turn
+// that ends on an unanswered question waits on the user rather than idling.
+// Caller must hold rt.mu.
+func (s *SessionState) setProcessStatus(running bool) bool {
+	if running {
+		return s.setStatus(StatusThinking, "")
+	}
+	s.pendingTools = nil
+	if hasPendingQuestion(s.Messages) {
+		return s.setStatus(StatusWaitingOnUser, types.ToolNameAskUserQuestion)
+	}
+	return s.setStatus(StatusIdle, "")
+}
+
+// advanceStatus runs newly appended messages through the status machine and
+// reports whether the status changed. Caller must hold rt.mu.
+func (s *SessionState) advanceStatus(messages []types.Message) bool {
+	status, tool := s.Status, s.StatusTool
+	for _, msg := range messages {
+		if msg.IsSynthetic {
+			continue
+		}
+		switch msg.Type {
+		case "assistant":
+			for _, block := range msg.ContentBlocks {
+				switch block.Type {
+				case "thinking":
+					status, tool = StatusThinking, ""
+				case "text":
+					if strings.TrimSpace(block.Text) != "" {
+						status, tool = StatusResponding, ""
+					}
+				case "tool_use":
+					if s.pendingTools == nil {
+						s.pendingTools = make(map[string]string)
+					}
+					s.pendingTools[block.ID] = block.Name
+				}
+			}
+			if len(msg.ContentBlocks) == 0 && msg.Content != "" {
+				status, tool = StatusResponding, ""
+			}
+			if len(s.pendingTools) > 0 {
+				status, tool = pendingToolStatus(s.pendingTools)
+			} else if msg.StopReason == "end_turn" && !s.IsStreaming {
+				// Turn finished outside ClaudeFu (no process to report the result)
+				status, tool = StatusIdle, ""
+			}
+		case "user", "tool_result_carrier":
+			returned := false
+			for _, block := range msg.ContentBlocks {
+				if block.Type == "tool_result" {
+					delete(s.pendingTools, block.ToolUseID)
+					returned = true
+				}
+			}
+			switch {
+			case len(s.pendingTools) > 0:
+				status, tool = pendingToolStatus(s.pendingTools)
+			case returned || (msg.Type == "user" && !msg.IsCompaction):
+				// Tool results went back, or a new prompt arrived: the model is up
+				status, tool = StatusThinking, ""
+			}
+		}
+	}
+	return s.setStatus(status, tool)
+}
+
+// pendingToolStatus is the status while tools are pending: waiting on the
+// user if any of them needs input, otherwise running the first pending tool
+// by name (so parallel tool calls report a stable tool).
+func pendingToolStatus(pending map[string]string) (string, string) {
+	var running string
+	for _, name := range pending {
+		if userInputTools[name] {
+			return StatusWaitingOnUser, name
+		}
+		if running == "" || name < running {
+			running = name
+		}
+	}
+	return StatusToolRunning, running
+}
+
+// emitStatus emits session:status. Like session:presence it ignores
+// subscriptions, so orchestrators and spinners see sessions that aren't open.
+func (rt *WorkspaceRuntime) emitStatus(agentID, sessionID string, status SessionStatus) {
+	rt.Emit("session:status", agentID, sessionID, status)
+}
@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"testing"
+
+	"claudefu/internal/types"
+)
+
+func TestAdvanceStatus(t *testing.T) {
+	assistant := func(stopReason string, blocks ...types.ContentBlock) types.Message {
+		return types.Message{Type: "assistant", ContentBlocks: blocks, StopReason: stopReason}
+	}
+	text := func(s string) types.ContentBlock { return types.ContentBlock{Type: "text", Text: s} }
+	toolUse := func(id, name string) types.ContentBlock {
+		return types.ContentBlock{Type: "tool_use", ID: id, Name: name}
+	}
+	toolResult := func(id string) types.Message {
+		return types.Message{Type: "user", ContentBlocks: []types.ContentBlock{{Type: "tool_result", ToolUseID: id}}}
+	}
+
+	tests := []struct {
+		name        string
+		streaming   bool
+		messages    []types.Message
+		wantStatus  string
+		wantTool    string
+		wantChanged bool
+	}{
+		{"prompt", true, []types.Message{{Type: "user", Content: "hi"}}, StatusThinking, "", true},
+		{"thinking block", true, []types.Message{assistant("", types.ContentBlock{Type: "thinking"})}, StatusThinking, "", true},
+		{"text", true, []types.Message{assistant("", text("Sure"))}, StatusResponding, "", true},
+		{"plain content", true, []types.Message{{Type: "assistant", Content: "Sure"}}, StatusResponding, "", true},
+		{"blank text", true, []types.Message{assistant("", text("  "))}, "", "", false},
+		{"tool use", true, []types.Message{assistant("tool_use", text("Running"), toolUse("t1", "Bash"))}, StatusToolRunning, "Bash", true},
+		{"parallel tools report the first by name", true, []types.Message{assistant("tool_use", toolUse("t1", "Read"), toolUse("t2", "Grep"))}, StatusToolRunning, "Grep", true},
+		{"question waits on user", true, []types.Message{assistant("tool_use", toolUse("t1", "Bash"), toolUse("t2", types.ToolNameAskUserQuestion))}, StatusWaitingOnUser, types.ToolNameAskUserQuestion, true},
+		{"tool result", true, []types.Message{assistant("tool_use", toolUse("t1", "Bash")), toolResult("t1")}, StatusThinking, "", true},
+		{"partial results", true, []types.Message{assistant("tool_use", toolUse("t1", "Bash"), toolUse("t2", "Read")), toolResult("t1")}, StatusToolRunning, "Read", true},
+		{"result carrier", true, []types.Message{
+			assistant("tool_use", toolUse("t1", "Bash")),
+			{Type: "tool_result_carrier", ContentBlocks: []types.ContentBlock{{Type: "tool_result", ToolUseID: "t1"}}},
+		}, StatusThinking, "", true},
+		{"end of turn outside claudefu", false, []types.Message{assistant("end_turn", text("Done"))}, StatusIdle, "", true},
+		{"end of turn while the process runs", true, []types.Message{assistant("end_turn", text("Done"))}, StatusResponding, "", true},
+		{"synthetic skipped", true, []types.Message{{Type: "user", Content: "hi", IsSynthetic: true}}, "", "", false},
+		{"compaction skipped", true, []types.Message{{Type: "user", Content: "summary", IsCompaction: true}}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &SessionState{IsStreaming: tt.streaming}
+			changed := session.advanceStatus(tt.messages)
+			if session.Status != tt.wantStatus || session.StatusTool != tt.wantTool || changed != tt.wantChanged {
+				t.Errorf("status = %q/%q changed=%v, want %q/%q changed=%v",
+					session.Status, session.StatusTool, changed, tt.wantStatus, tt.wantTool, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestSetProcessStatus(t *testing.T) {
+	session := &SessionState{}
+	session.advanceStatus([]types.Message{{
+		Type:          "assistant",
+		ContentBlocks: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Bash"}},
+	}})
+	if !session.setProcessStatus(false) || session.Status != StatusIdle || len(session.pendingTools) != 0 {
+		t.Errorf("after exit: status %q, %d pending tools; want idle, none", session.Status, len(session.pendingTools))
+	}
+	if !session.setProcessStatus(true) || session.Status != StatusThinking {
+		t.Errorf("after start: status %q, want %q", session.Status, StatusThinking)
+	}
+}
@@ -3,22 +3,14 @@
 package scaffold
 
 import (
+	"claudefu/internal/claudehome"
 	"claudefu/internal/workspace"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
-// encodeProjectPath encodes a folder path like Claude Code does.
-// Claude CLI replaces every non-alphanumeric character with "-".
-var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
-
-func encodeProjectPath(path string) string {
-	return nonAlphanumeric.ReplaceAllString(path, "-")
-}
-
 // ScaffoldCheck reports which agent setup items exist.
 type ScaffoldCheck struct {
 	HasProjectsDir bool `json:"hasProjectsDir"`
@@ -47,14 +39,8 @@ func CheckAgentSetup(folder string) (*ScaffoldCheck, error) {
 
 	check := &ScaffoldCheck{}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
 	// Projects dir — exists if directory is present
-	encoded := encodeProjectPath(folder)
-	projectDir := filepath.Join(home, ".claude", "projects", encoded)
+	projectDir := claudehome.ProjectDir(folder)
 	if info, err := os.Stat(projectDir); err == nil && info.IsDir() {
 		check.HasProjectsDir = true
 	}
@@ -116,15 +102,7 @@ func EnsureAgentSetup(folder, configPath string, identity AgentIdentity, opts Sc
 
 // ensureClaudeProjectsDir creates ~/.claude/projects/{encoded-folder}/ if missing.
 func ensureClaudeProjectsDir(folder string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	encoded := encodeProjectPath(folder)
-	projectDir := filepath.Join(home, ".claude", "projects", encoded)
-
-	return os.MkdirAll(projectDir, 0755)
+	return os.MkdirAll(claudehome.ProjectDir(folder), 0755)
 }
 
 // ensureClaudeMD copies the CLAUDE.md template to the agent folder if missing.
@@ -0,0 +1,122 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/git"
+)
+
+// Issue severities. Folders with an error issue can't be added as agents.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// FolderIssue is one finding about a prospective agent folder.
+type FolderIssue struct {
+	Code     string `json:"code"`     // e.g. "folder_missing", "no_claude_md"
+	Severity string `json:"severity"` // error, warning, info
+	Message  string `json:"message"`
+}
+
+// FolderValidation is the result of checking a folder before adding it as an agent.
+type FolderValidation struct {
+	Folder       string        `json:"folder"`
+	Exists       bool          `json:"exists"`
+	IsGitRepo    bool          `json:"isGitRepo"`
+	RepoRoot     string        `json:"repoRoot,omitempty"` // Set when the folder is inside a repo
+	SessionCount int           `json:"sessionCount"`       // Prior sessions in ~/.claude/projects
+	HasClaudeMD  bool          `json:"hasClaudeMD"`
+	Issues       []FolderIssue `json:"issues"`
+}
+
+// OK reports whether the folder has no error issues.
+func (v *FolderValidation) OK() bool {
+	for _, issue := range v.Issues {
+		if issue.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// FirstError returns the first error issue's message ("" if none).
+func (v *FolderValidation) FirstError() string {
+	for _, issue := range v.Issues {
+		if issue.Severity == SeverityError {
+			return issue.Message
+		}
+	}
+	return ""
+}
+
+// Add records an issue.
+func (v *FolderValidation) Add(code, severity, message string) {
+	v.Issues = append(v.Issues, FolderIssue{Code: code, Severity: severity, Message: message})
+}
+
+// ValidateFolder checks a prospective agent folder: it must be an existing
+// absolute directory; not being a git repository, having no prior Claude
+// sessions, or lacking a CLAUDE.md are reported as warnings.
+func ValidateFolder(folder string) *FolderValidation {
+	v := &FolderValidation{Folder: folder, Issues: []FolderIssue{}}
+	if strings.TrimSpace(folder) == "" {
+		v.Add("folder_empty", SeverityError, "No folder given")
+		return v
+	}
+	if !filepath.IsAbs(folder) {
+		v.Add("not_absolute", SeverityError, "Folder must be an absolute path: "+folder)
+		return v
+	}
+
+	info, err := os.Stat(folder)
+	switch {
+	case os.IsNotExist(err):
+		v.Add("folder_missing", SeverityError, "Folder does not exist: "+folder)
+		return v
+	case err != nil:
+		v.Add("folder_unreadable", SeverityError, "Folder can't be read: "+err.Error())
+		return v
+	case !info.IsDir():
+		v.Add("not_directory", SeverityError, "Not a directory: "+folder)
+		return v
+	}
+	v.Exists = true
+
+	if claudehome.SameDir(folder, claudehome.HomeDir()) {
+		v.Add("home_directory", SeverityWarning, "Folder is your home directory; Claude will be able to see everything in it")
+	}
+
+	if root, err := git.RepoRoot(folder); err == nil {
+		v.IsGitRepo = true
+		v.RepoRoot = root
+		if !claudehome.SameDir(root, folder) {
+			v.Add("git_subdirectory", SeverityInfo, "Folder is inside the git repository at "+root)
+		}
+	} else {
+		v.Add("not_git_repo", SeverityWarning, "Folder is not in a git repository; per-turn diffs and git status won't be available")
+	}
+
+	if entries, err := os.ReadDir(claudehome.ProjectDir(folder)); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+				v.SessionCount++
+			}
+		}
+	}
+	if v.SessionCount == 0 {
+		v.Add("no_sessions", SeverityInfo, "No previous Claude sessions for this folder")
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, "CLAUDE.md")); err == nil {
+		v.HasClaudeMD = true
+	} else {
+		v.Add("no_claude_md", SeverityWarning, "No CLAUDE.md; the agent starts without project instructions")
+	}
+
+	return v
+}
@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+		"* * * foo *",
+		"@yearly",
+	}
+	for _, expr := range tests {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday 2026-03-04 10:07 UTC
+	base := time.Date(2026, 3, 4, 10, 7, 0, 0, time.UTC)
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every 15 minutes", "*/15 * * * *", base, at(2026, 3, 4, 10, 15)},
+		{"step from offset", "5/20 * * * *", base, at(2026, 3, 4, 10, 25)},
+		{"list", "0,30 * * * *", base, at(2026, 3, 4, 10, 30)},
+		{"strictly after", "0 * * * *", time.Date(2026, 3, 4, 10, 0, 30, 0, time.UTC), at(2026, 3, 4, 11, 0)},
+		{"hourly alias", "@hourly", base, at(2026, 3, 4, 11, 0)},
+		{"daily alias", "@daily", base, at(2026, 3, 5, 0, 0)},
+		{"alias is case-insensitive", "@Daily", base, at(2026, 3, 5, 0, 0)},
+		{"weekdays skips weekend", "@weekdays", at(2026, 3, 6, 18, 0), at(2026, 3, 9, 9, 0)},
+		{"monthly alias", "@monthly", base, at(2026, 4, 1, 0, 0)},
+		{"7 is Sunday", "0 12 * * 7", base, at(2026, 3, 8, 12, 0)},
+		{"day names", "0 12 * * fri-sat", base, at(2026, 3, 6, 12, 0)},
+		{"dom or dow", "0 0 15 * mon", base, at(2026, 3, 9, 0, 0)},
+		{"month names roll the year", "30 8 * jan-mar *", at(2026, 3, 31, 9, 0), at(2027, 1, 1, 8, 30)},
+		{"leap day", "0 0 29 2 *", base, at(2028, 2, 29, 0, 0)},
+		{"never matches", "0 0 31 2 *", base, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := c.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
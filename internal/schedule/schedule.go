@@ -0,0 +1,423 @@
+// Package schedule runs recurring agent prompts on cron schedules
+// ("every weekday at 9am, send X to the devops agent"). Schedules are
+// persisted as JSON at {configPath}/schedules.json; a ticker loop fires due
+// schedules through a FireFunc supplied by the app.
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"claudefu/internal/logging"
+)
+
+var logger = logging.For(logging.App)
+
+// tickInterval is how often due schedules are checked (cron has minute resolution).
+const tickInterval = 20 * time.Second
+
+// Schedule is a recurring prompt sent to an agent.
+type Schedule struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	WorkspaceID string     `json:"workspaceId"`
+	AgentID     string     `json:"agentId"`
+	Cron        string     `json:"cron"` // Five-field cron expression or @-alias, local time
+	Prompt      string     `json:"prompt"`
+	SessionID   string     `json:"sessionId,omitempty"` // Session to send into; empty = new session each run
+	Model       string     `json:"model,omitempty"`     // --model override (empty = CLI default)
+	Enabled     bool       `json:"enabled"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	NextRunAt   *time.Time `json:"nextRunAt,omitempty"`
+
+	// Last run (set when a run finishes)
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastSessionID string     `json:"lastSessionId,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	Running       bool       `json:"running,omitempty"` // A run is in flight (reset on load)
+}
+
+// Input holds the editable fields of a schedule.
+type Input struct {
+	Name      string `json:"name"`
+	AgentID   string `json:"agentId"`
+	Cron      string `json:"cron"`
+	Prompt    string `json:"prompt"`
+	SessionID string `json:"sessionId"`
+	Model     string `json:"model"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// FireFunc sends a schedule's prompt. It returns the session the prompt went to.
+// Called on its own goroutine; it may block until the response completes.
+type FireFunc func(s Schedule) (sessionID string, err error)
+
+// scheduleFile is the on-disk format.
+type scheduleFile struct {
+	Version   int        `json:"version"`
+	Schedules []Schedule `json:"schedules"`
+}
+
+const scheduleFileVersion = 1
+
+// Manager persists schedules and fires them when due.
+type Manager struct {
+	path      string // ~/.claudefu/schedules.json
+	schedules []Schedule
+	running   map[string]bool // Schedule IDs with a run in flight
+	fire      FireFunc
+	onChange  func(s Schedule)
+	mu        sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewManager loads schedules from {configPath}/schedules.json.
+func NewManager(configPath string) (*Manager, error) {
+	m := &Manager{
+		path:    filepath.Join(configPath, "schedules.json"),
+		running: make(map[string]bool),
+	}
+	data, err := os.ReadFile(m.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	if err == nil {
+		var file scheduleFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse schedules: %w", err)
+		}
+		m.schedules = file.Schedules
+	}
+	for i := range m.schedules {
+		m.schedules[i].Running = false
+	}
+	return m, nil
+}
+
+// SetOnChange sets a callback invoked after a schedule is run or its result recorded.
+func (m *Manager) SetOnChange(fn func(s Schedule)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// List returns a workspace's schedules (all workspaces if workspaceID is empty).
+func (m *Manager) List(workspaceID string) []Schedule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []Schedule{}
+	for _, s := range m.schedules {
+		if workspaceID == "" || s.WorkspaceID == workspaceID {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Get returns a schedule by ID.
+func (m *Manager) Get(id string) (*Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	s := m.schedules[i]
+	return &s, nil
+}
+
+// Create adds a schedule to a workspace.
+func (m *Manager) Create(workspaceID string, in Input) (*Schedule, error) {
+	if workspaceID == "" {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	s := Schedule{
+		ID:          uuid.New().String(),
+		WorkspaceID: workspaceID,
+		CreatedAt:   time.Now(),
+	}
+	if err := apply(&s, in); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.save(append(slices.Clone(m.schedules), s)); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Update replaces a schedule's editable fields and recomputes its next run.
+func (m *Manager) Update(id string, in Input) (*Schedule, error) {
+	return m.update(id, func(s *Schedule) error {
+		return apply(s, in)
+	})
+}
+
+// SetEnabled enables or disables a schedule.
+func (m *Manager) SetEnabled(id string, enabled bool) (*Schedule, error) {
+	return m.update(id, func(s *Schedule) error {
+		s.Enabled = enabled
+		s.NextRunAt = nextRun(s, time.Now())
+		return nil
+	})
+}
+
+// Delete removes a schedule. A run already in flight is not interrupted.
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	return m.save(slices.Delete(slices.Clone(m.schedules), i, i+1))
+}
+
+// RunNow fires a schedule immediately, regardless of its cron or enabled state.
+func (m *Manager) RunNow(id string) error {
+	m.mu.Lock()
+	i := m.indexOf(id)
+	if i < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	if m.fire == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("scheduler not started")
+	}
+	if m.running[id] {
+		m.mu.Unlock()
+		return fmt.Errorf("schedule is already running")
+	}
+	s := m.startRunLocked(i)
+	m.mu.Unlock()
+
+	go m.run(s)
+	return nil
+}
+
+// Start begins firing due schedules with fire. Runs missed while the app was
+// closed are skipped, not caught up: next runs are recomputed from now.
+func (m *Manager) Start(fire FireFunc) {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.fire = fire
+	now := time.Now()
+	for i := range m.schedules {
+		m.schedules[i].NextRunAt = nextRun(&m.schedules[i], now)
+	}
+	if err := m.save(m.schedules); err != nil {
+		logger.Warnf("Failed to save schedules: %v", err)
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	stop, done := m.stop, m.done
+	m.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				m.fireDue(now)
+			}
+		}
+	}()
+}
+
+// Stop stops the ticker loop. Runs in flight continue until their send completes.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// fireDue starts every enabled schedule whose next run has passed.
+// A schedule whose previous run is still in flight skips this occurrence.
+func (m *Manager) fireDue(now time.Time) {
+	m.mu.Lock()
+	var due []Schedule
+	for i := range m.schedules {
+		s := &m.schedules[i]
+		if !s.Enabled || s.NextRunAt == nil || now.Before(*s.NextRunAt) {
+			continue
+		}
+		if m.running[s.ID] {
+			logger.Warnf("Schedule %q: previous run still in progress, skipping", s.Name)
+			s.NextRunAt = nextRun(s, now)
+			continue
+		}
+		due = append(due, m.startRunLocked(i))
+	}
+	if len(due) > 0 {
+		if err := m.save(m.schedules); err != nil {
+			logger.Warnf("Failed to save schedules: %v", err)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range due {
+		go m.run(s)
+	}
+}
+
+// startRunLocked marks schedule i as running and advances its next run. Caller must hold m.mu.
+func (m *Manager) startRunLocked(i int) Schedule {
+	now := time.Now()
+	s := &m.schedules[i]
+	m.running[s.ID] = true
+	s.Running = true
+	s.LastRunAt = &now
+	s.NextRunAt = nextRun(s, now)
+	return *s
+}
+
+// run fires a schedule and records the result.
+func (m *Manager) run(s Schedule) {
+	sessionID, err := m.fire(s)
+
+	m.mu.Lock()
+	delete(m.running, s.ID)
+	var updated *Schedule
+	if i := m.indexOf(s.ID); i >= 0 {
+		updated = &m.schedules[i]
+		updated.Running = false
+		updated.LastSessionID = sessionID
+		updated.LastError = ""
+		if err != nil {
+			updated.LastError = err.Error()
+		}
+		if err := m.save(m.schedules); err != nil {
+			logger.Warnf("Failed to save schedules: %v", err)
+		}
+	}
+	onChange := m.onChange
+	m.mu.Unlock()
+
+	if updated != nil && onChange != nil {
+		onChange(*updated)
+	}
+}
+
+// update applies fn to one schedule and persists.
+func (m *Manager) update(id string, fn func(s *Schedule) error) (*Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	updated := slices.Clone(m.schedules)
+	s := &updated[i]
+	if err := fn(s); err != nil {
+		return nil, err
+	}
+	s.UpdatedAt = time.Now()
+	if err := m.save(updated); err != nil {
+		return nil, err
+	}
+	result := *s
+	return &result, nil
+}
+
+// save writes schedules to disk (atomic rename) and updates the cache. Caller must hold m.mu.
+func (m *Manager) save(schedules []Schedule) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(scheduleFile{Version: scheduleFileVersion, Schedules: schedules}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedules: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	m.schedules = schedules
+	return nil
+}
+
+func (m *Manager) indexOf(id string) int {
+	return slices.IndexFunc(m.schedules, func(s Schedule) bool { return s.ID == id })
+}
+
+// apply validates in and copies it onto s.
+func apply(s *Schedule, in Input) error {
+	in.Prompt = strings.TrimSpace(in.Prompt)
+	if in.AgentID == "" {
+		return fmt.Errorf("agent is required")
+	}
+	if in.Prompt == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	if _, err := ParseCron(in.Cron); err != nil {
+		return err
+	}
+
+	s.Name = strings.TrimSpace(in.Name)
+	if s.Name == "" {
+		s.Name = in.Prompt
+		if runes := []rune(s.Name); len(runes) > 40 {
+			s.Name = string(runes[:40]) + "…"
+		}
+	}
+	s.AgentID = in.AgentID
+	s.Cron = strings.TrimSpace(in.Cron)
+	s.Prompt = in.Prompt
+	s.SessionID = in.SessionID
+	s.Model = in.Model
+	s.Enabled = in.Enabled
+	s.UpdatedAt = time.Now()
+	s.NextRunAt = nextRun(s, time.Now())
+	return nil
+}
+
+// nextRun returns when an enabled schedule next fires after t (nil if disabled or never).
+func nextRun(s *Schedule, t time.Time) *time.Time {
+	if !s.Enabled {
+		return nil
+	}
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return nil
+	}
+	next := cron.Next(t.Local())
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
@@ -0,0 +1,307 @@
+// Package search maintains an on-disk full-text index (SQLite FTS5) of session
+// message text across all agents, so conversations can be found without loading
+// every JSONL file. Indexing is incremental: each file's byte offset is stored
+// and only appended lines are read on later updates.
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	_ "modernc.org/sqlite"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/fsutil"
+	"claudefu/internal/logging"
+	"claudefu/internal/types"
+)
+
+var logger = logging.For(logging.App)
+
+// IndexFile is the index database name under {configPath}/local/ (derived, per-machine data).
+const IndexFile = "search.db"
+
+// Hit is one matching message.
+type Hit struct {
+	Folder    string  `json:"folder"`
+	SessionID string  `json:"sessionId"`
+	UUID      string  `json:"uuid"`
+	Role      string  `json:"role"` // user, assistant
+	Timestamp string  `json:"timestamp"`
+	Snippet   string  `json:"snippet"` // Match context with terms wrapped in [ ]
+	Score     float64 `json:"score"`   // bm25 rank (lower is better)
+}
+
+// Index is the session full-text index.
+type Index struct {
+	db *sql.DB
+	mu sync.Mutex // Serializes writers (SQLite allows one at a time)
+}
+
+// Open opens or creates the index at {configPath}/local/search.db.
+func Open(configPath string) (*Index, error) {
+	path := filepath.Join(configPath, "local", IndexFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	schema := `
+		CREATE TABLE IF NOT EXISTS indexed_files (
+			path TEXT PRIMARY KEY,
+			folder TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			offset INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_files_folder ON indexed_files(folder);
+		CREATE VIRTUAL TABLE IF NOT EXISTS messages USING fts5(
+			content,
+			folder UNINDEXED,
+			session_id UNINDEXED,
+			uuid UNINDEXED,
+			role UNINDEXED,
+			timestamp UNINDEXED,
+			tokenize = 'porter unicode61'
+		);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create search schema: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the index database.
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// IndexFolder indexes every session file of an agent folder. Returns the number
+// of files that had new content.
+func (ix *Index) IndexFolder(folder string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(claudehome.ProjectDir(folder), "*.jsonl"))
+	if err != nil {
+		return 0, err
+	}
+	ix.removeMissing(folder, paths)
+
+	updated := 0
+	for _, path := range paths {
+		sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		n, err := ix.IndexSession(folder, sessionID)
+		if err != nil {
+			logger.Warnf("search: failed to index %s: %v", path, err)
+			continue
+		}
+		if n > 0 {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// IndexSession indexes lines appended to a session file since the last call.
+// A file that shrank (rewritten, e.g. by a rewind) is reindexed from the start.
+// Returns the number of messages added.
+func (ix *Index) IndexSession(folder, sessionID string) (int, error) {
+	path := claudehome.SessionPath(folder, sessionID)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return 0, ix.removeLocked(path)
+	} else if err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	err = ix.db.QueryRow(`SELECT offset FROM indexed_files WHERE path = ?`, path).Scan(&offset)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	if info.Size() == offset {
+		return 0, nil
+	}
+
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if info.Size() < offset {
+		offset = 0
+		if _, err := tx.Exec(`DELETE FROM messages WHERE session_id = ? AND folder = ?`, sessionID, folder); err != nil {
+			return 0, err
+		}
+	}
+
+	insert, err := tx.Prepare(`INSERT INTO messages (content, folder, session_id, uuid, role, timestamp) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insert.Close()
+
+	added := 0
+	offset, err = fsutil.ReadLinesFrom(path, offset, func(line string) error {
+		msg := messageText(line)
+		if msg == nil {
+			return nil
+		}
+		if _, err := insert.Exec(msg.Content, folder, sessionID, msg.UUID, msg.Type, msg.Timestamp); err != nil {
+			return err
+		}
+		added++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO indexed_files (path, folder, session_id, offset) VALUES (?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET offset = excluded.offset`, path, folder, sessionID, offset); err != nil {
+		return 0, err
+	}
+	return added, tx.Commit()
+}
+
+// RemoveSession drops a session from the index (e.g. after it was deleted).
+func (ix *Index) RemoveSession(folder, sessionID string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.removeLocked(claudehome.SessionPath(folder, sessionID))
+}
+
+// removeMissing drops indexed sessions of a folder whose files are no longer in paths
+// (deleted outside the watcher's view, e.g. while ClaudeFu was not running).
+func (ix *Index) removeMissing(folder string, paths []string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	rows, err := ix.db.Query(`SELECT path FROM indexed_files WHERE folder = ?`, folder)
+	if err != nil {
+		return
+	}
+	present := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		present[p] = true
+	}
+	var missing []string
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil && !present[path] {
+			missing = append(missing, path)
+		}
+	}
+	rows.Close()
+
+	for _, path := range missing {
+		if err := ix.removeLocked(path); err != nil {
+			logger.Warnf("search: failed to remove %s: %v", path, err)
+		}
+	}
+}
+
+func (ix *Index) removeLocked(path string) error {
+	var folder, sessionID string
+	err := ix.db.QueryRow(`SELECT folder, session_id FROM indexed_files WHERE path = ?`, path).Scan(&folder, &sessionID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err := ix.db.Exec(`DELETE FROM messages WHERE session_id = ? AND folder = ?`, sessionID, folder); err != nil {
+		return err
+	}
+	_, err = ix.db.Exec(`DELETE FROM indexed_files WHERE path = ?`, path)
+	return err
+}
+
+// Search finds messages matching query, best matches first. folders limits the
+// search to those agent folders (empty = all). Every word must match; a trailing
+// "*" on a word matches prefixes. limit <= 0 defaults to 50.
+func (ix *Index) Search(query string, folders []string, limit int) ([]Hit, error) {
+	match := buildMatchQuery(query)
+	if match == "" {
+		return []Hit{}, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	sqlQuery := `SELECT folder, session_id, uuid, role, timestamp,
+			snippet(messages, 0, '[', ']', '…', 16), bm25(messages)
+		FROM messages WHERE messages MATCH ?`
+	args := []any{match}
+	if len(folders) > 0 {
+		sqlQuery += ` AND folder IN (?` + strings.Repeat(`, ?`, len(folders)-1) + `)`
+		for _, folder := range folders {
+			args = append(args, folder)
+		}
+	}
+	sqlQuery += ` ORDER BY bm25(messages) LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := ix.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []Hit{}
+	for rows.Next() {
+		var h Hit
+		if err := rows.Scan(&h.Folder, &h.SessionID, &h.UUID, &h.Role, &h.Timestamp, &h.Snippet, &h.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// messageText returns the user/assistant message on a JSONL line if it has text.
+func messageText(line string) *types.Message {
+	classified, err := types.ClassifyJSONLEvent(line)
+	if err != nil || classified == nil {
+		return nil
+	}
+	msg := types.ConvertToMessage(classified)
+	if msg == nil || (msg.Type != "user" && msg.Type != "assistant") || strings.TrimSpace(msg.Content) == "" {
+		return nil
+	}
+	return msg
+}
+
+// buildMatchQuery turns free text into an FTS5 query: each word is quoted (so
+// punctuation and FTS operators are literal) and all words must match.
+func buildMatchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '*' && r != '_'
+	})
+	var terms []string
+	for _, w := range words {
+		prefix := strings.HasSuffix(w, "*")
+		w = strings.Trim(w, "*")
+		if w == "" {
+			continue
+		}
+		term := `"` + w + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
@@ -8,13 +8,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/logging"
 )
 
+var logger = logging.For(logging.App)
+
 // Service provides session management primitives.
 type Service struct {
 	claudeProjectsPath string
@@ -22,9 +26,8 @@ type Service struct {
 
 // NewService creates a new SessionService.
 func NewService() *Service {
-	home, _ := os.UserHomeDir()
 	return &Service{
-		claudeProjectsPath: filepath.Join(home, ".claude", "projects"),
+		claudeProjectsPath: claudehome.ProjectsDir(),
 	}
 }
 
@@ -40,8 +43,7 @@ func (s *Service) CreateSession(folder string) (string, error) {
 	sessionID := uuid.New().String()
 	userMsgID := uuid.New().String()
 	assistantMsgID := uuid.New().String()
-	encodedFolder := encodeFolder(folder)
-	projectDir := filepath.Join(s.claudeProjectsPath, encodedFolder)
+	projectDir := filepath.Join(s.claudeProjectsPath, claudehome.EncodeProjectPath(folder))
 	now := time.Now().UTC()
 
 	// Ensure directory exists
@@ -243,19 +245,10 @@ type UsageInfo struct {
 	OutputTokens int `json:"output_tokens"`
 }
 
-// encodeFolder converts folder path to Claude's encoded format.
-// Claude CLI replaces every non-alphanumeric character with "-".
-var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
-
-func encodeFolder(folder string) string {
-	return nonAlphanumeric.ReplaceAllString(folder, "-")
-}
-
 // DuplicateSession copies an existing session JSONL to a new UUID file.
 // Returns the new session ID.
 func (s *Service) DuplicateSession(folder, sourceSessionID string) (string, error) {
-	encodedFolder := encodeFolder(folder)
-	projectDir := filepath.Join(s.claudeProjectsPath, encodedFolder)
+	projectDir := filepath.Join(s.claudeProjectsPath, claudehome.EncodeProjectPath(folder))
 	sourcePath := filepath.Join(projectDir, sourceSessionID+".jsonl")
 
 	// Read source file
@@ -288,6 +281,6 @@ func (s *Service) DuplicateSession(folder, sourceSessionID string) (string, erro
 		}
 	}
 
-	fmt.Printf("[SESSION] Duplicated %s → %s (%d messages)\n", sourceSessionID, newSessionID, messageCount)
+	logger.Infof("Duplicated %s → %s (%d messages)", sourceSessionID, newSessionID, messageCount)
 	return newSessionID, nil
 }
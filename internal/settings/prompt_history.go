@@ -0,0 +1,151 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudefu/internal/fsutil"
+)
+
+const PromptHistoryFile = "prompt-history.json"
+
+// MaxPromptHistoryPerSession caps stored prompts per session (oldest are dropped).
+const MaxPromptHistoryPerSession = 200
+
+// PromptHistoryEntry is one sent prompt.
+type PromptHistoryEntry struct {
+	Text      string `json:"text"`
+	SentAt    int64  `json:"sentAt"`              // Unix ms
+	SessionID string `json:"sessionId,omitempty"` // Only set in cross-session search results
+}
+
+// PromptHistory maps folder paths to session ID -> sent prompts (oldest first)
+// Example: {"/Users/foo/project": {"session-123": [{"text": "fix the build", "sentAt": 1704067200000}]}}
+type PromptHistory map[string]map[string][]PromptHistoryEntry
+
+// AddPrompt records a sent prompt for a session. Re-sending the most recent
+// prompt only refreshes its timestamp (like a shell's ignoredups).
+func (sm *SessionManager) AddPrompt(folder, sessionId, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.history[folder] == nil {
+		sm.history[folder] = make(map[string][]PromptHistoryEntry)
+	}
+	entries := sm.history[folder][sessionId]
+	now := time.Now().UnixMilli()
+
+	if n := len(entries); n > 0 && entries[n-1].Text == text {
+		entries[n-1].SentAt = now
+	} else {
+		entries = append(entries, PromptHistoryEntry{Text: text, SentAt: now})
+		if len(entries) > MaxPromptHistoryPerSession {
+			entries = entries[len(entries)-MaxPromptHistoryPerSession:]
+		}
+	}
+	sm.history[folder][sessionId] = entries
+
+	return sm.saveHistory()
+}
+
+// GetPromptHistory returns a session's prompts newest first (up-arrow order),
+// optionally filtered by a case-insensitive substring. limit <= 0 returns all.
+func (sm *SessionManager) GetPromptHistory(folder, sessionId, query string, limit int) []PromptHistoryEntry {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	entries := sm.history[folder][sessionId]
+	result := make([]PromptHistoryEntry, 0, len(entries))
+	query = strings.ToLower(query)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if query != "" && !strings.Contains(strings.ToLower(entries[i].Text), query) {
+			continue
+		}
+		result = append(result, entries[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// SearchPromptHistory searches every session of a folder, newest first,
+// with SessionID set on each result. limit <= 0 returns all matches.
+func (sm *SessionManager) SearchPromptHistory(folder, query string, limit int) []PromptHistoryEntry {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	var result []PromptHistoryEntry
+	for sessionId, entries := range sm.history[folder] {
+		for _, entry := range entries {
+			if query != "" && !strings.Contains(strings.ToLower(entry.Text), query) {
+				continue
+			}
+			entry.SessionID = sessionId
+			result = append(result, entry)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].SentAt > result[j].SentAt
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// ClearPromptHistory removes a session's prompt history.
+func (sm *SessionManager) ClearPromptHistory(folder, sessionId string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.history[folder][sessionId]; !ok {
+		return nil
+	}
+	delete(sm.history[folder], sessionId)
+	if len(sm.history[folder]) == 0 {
+		delete(sm.history, folder)
+	}
+	return sm.saveHistory()
+}
+
+// historyPath returns the path for prompt-history.json in local/ (per-machine state)
+func (sm *SessionManager) historyPath() string {
+	return filepath.Join(sm.configPath, "local", PromptHistoryFile)
+}
+
+// loadHistory reads prompt history from local/ (per-machine state)
+func (sm *SessionManager) loadHistory() error {
+	data, err := os.ReadFile(sm.historyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // File doesn't exist, use defaults
+		}
+		return err
+	}
+
+	return json.Unmarshal(data, &sm.history)
+}
+
+// saveHistory writes prompt history to local/ (per-machine state)
+func (sm *SessionManager) saveHistory() error {
+	jsonData, err := json.Marshal(sm.history)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sm.historyPath()), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(sm.historyPath(), jsonData, 0644)
+}
@@ -0,0 +1,137 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"claudefu/internal/fsutil"
+)
+
+const SessionLabelsFile = "session-labels.json"
+
+// SessionLabel holds the user's tags and pinned flag for a session
+type SessionLabel struct {
+	Tags   []string `json:"tags,omitempty"`
+	Pinned bool     `json:"pinned,omitempty"`
+}
+
+// SessionLabels maps folder paths to session ID -> labels
+// Example: {"/Users/foo/project": {"session-123": {"tags": ["bug-hunt"], "pinned": true}}}
+type SessionLabels map[string]map[string]SessionLabel
+
+// NormalizeSessionTags trims tags and drops empty and duplicate ones
+// (case-insensitive, first spelling wins), keeping their order.
+func NormalizeSessionTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.ContainsFunc(result, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// SetSessionTags replaces a session's tags (normalized). Returns the tags stored.
+func (sm *SessionManager) SetSessionTags(folder, sessionId string, tags []string) ([]string, error) {
+	tags = NormalizeSessionTags(tags)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	label := sm.labels[folder][sessionId]
+	label.Tags = tags
+	sm.setLabel(folder, sessionId, label)
+	return tags, sm.saveLabels()
+}
+
+// SetSessionPinned pins or unpins a session
+func (sm *SessionManager) SetSessionPinned(folder, sessionId string, pinned bool) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	label := sm.labels[folder][sessionId]
+	label.Pinned = pinned
+	sm.setLabel(folder, sessionId, label)
+	return sm.saveLabels()
+}
+
+// setLabel stores label, removing the entry (and empty folder) when it is empty. Caller holds mu.
+func (sm *SessionManager) setLabel(folder, sessionId string, label SessionLabel) {
+	if len(label.Tags) == 0 && !label.Pinned {
+		delete(sm.labels[folder], sessionId)
+		if len(sm.labels[folder]) == 0 {
+			delete(sm.labels, folder)
+		}
+		return
+	}
+	if sm.labels[folder] == nil {
+		sm.labels[folder] = make(map[string]SessionLabel)
+	}
+	sm.labels[folder][sessionId] = label
+}
+
+// GetSessionLabel returns a session's tags and pinned flag (zero value if none)
+func (sm *SessionManager) GetSessionLabel(folder, sessionId string) SessionLabel {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	label := sm.labels[folder][sessionId]
+	label.Tags = slices.Clone(label.Tags)
+	return label
+}
+
+// GetAllSessionLabels returns the labels of all labeled sessions in a folder
+func (sm *SessionManager) GetAllSessionLabels(folder string) map[string]SessionLabel {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	result := make(map[string]SessionLabel, len(sm.labels[folder]))
+	for k, v := range sm.labels[folder] {
+		v.Tags = slices.Clone(v.Tags)
+		result[k] = v
+	}
+	return result
+}
+
+// GetSessionTagNames returns every tag used by a folder's sessions, sorted
+func (sm *SessionManager) GetSessionTagNames(folder string) []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	var all []string
+	for _, label := range sm.labels[folder] {
+		all = append(all, label.Tags...)
+	}
+	result := NormalizeSessionTags(all)
+	sort.Slice(result, func(i, j int) bool { return strings.ToLower(result[i]) < strings.ToLower(result[j]) })
+	return result
+}
+
+// loadLabels reads session labels from disk (root — synced config, like session names)
+func (sm *SessionManager) loadLabels() error {
+	data, err := os.ReadFile(filepath.Join(sm.configPath, SessionLabelsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // File doesn't exist, use defaults
+		}
+		return err
+	}
+
+	return json.Unmarshal(data, &sm.labels)
+}
+
+// saveLabels writes session labels to disk
+func (sm *SessionManager) saveLabels() error {
+	jsonData, err := json.MarshalIndent(sm.labels, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(filepath.Join(sm.configPath, SessionLabelsFile), jsonData, 0644)
+}
@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"claudefu/internal/fsutil"
+)
+
+const SessionLinksFile = "session-links.json"
+
+// SessionLink records that a session continues work handed off from another
+// agent's session (AgentHandoff), so the UI can show "continued from".
+type SessionLink struct {
+	FromAgentID   string   `json:"fromAgentId,omitempty"`
+	FromAgentSlug string   `json:"fromAgentSlug"`
+	FromFolder    string   `json:"fromFolder,omitempty"`
+	FromSessionID string   `json:"fromSessionId,omitempty"` // Empty if the handing-off session was unknown
+	Summary       string   `json:"summary,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	CreatedAt     int64    `json:"createdAt"` // Unix ms
+}
+
+// SessionLinks maps folder paths to session ID -> the link it was created from
+// Example: {"/Users/foo/frontend": {"session-456": {"fromAgentSlug": "backend", "fromSessionId": "session-123", ...}}}
+type SessionLinks map[string]map[string]SessionLink
+
+// SetSessionLink records where a session's work was handed off from
+func (sm *SessionManager) SetSessionLink(folder, sessionId string, link SessionLink) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.links[folder] == nil {
+		sm.links[folder] = make(map[string]SessionLink)
+	}
+	sm.links[folder][sessionId] = link
+
+	return sm.saveLinks()
+}
+
+// GetSessionLink returns where a session was handed off from (nil if it wasn't)
+func (sm *SessionManager) GetSessionLink(folder, sessionId string) *SessionLink {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if link, ok := sm.links[folder][sessionId]; ok {
+		return &link
+	}
+	return nil
+}
+
+// GetAllSessionLinks returns the links of all sessions in a folder
+func (sm *SessionManager) GetAllSessionLinks(folder string) map[string]SessionLink {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	result := make(map[string]SessionLink, len(sm.links[folder]))
+	for k, v := range sm.links[folder] {
+		result[k] = v
+	}
+	return result
+}
+
+// GetHandoffsFrom returns the sessions (folder -> session IDs) continued from a session
+func (sm *SessionManager) GetHandoffsFrom(folder, sessionId string) map[string][]string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	result := make(map[string][]string)
+	for targetFolder, links := range sm.links {
+		for targetSession, link := range links {
+			if link.FromFolder == folder && link.FromSessionID == sessionId {
+				result[targetFolder] = append(result[targetFolder], targetSession)
+			}
+		}
+	}
+	return result
+}
+
+// loadLinks reads session links from disk (root — synced config, like session names)
+func (sm *SessionManager) loadLinks() error {
+	data, err := os.ReadFile(filepath.Join(sm.configPath, SessionLinksFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // File doesn't exist, use defaults
+		}
+		return err
+	}
+
+	return json.Unmarshal(data, &sm.links)
+}
+
+// saveLinks writes session links to disk
+func (sm *SessionManager) saveLinks() error {
+	jsonData, err := json.MarshalIndent(sm.links, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(filepath.Join(sm.configPath, SessionLinksFile), jsonData, 0644)
+}
@@ -2,13 +2,17 @@ package settings
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"claudefu/internal/fsutil"
+	"claudefu/internal/logging"
 )
 
+var logger = logging.For(logging.App)
+
 const SessionNamesFile = "session-names.json"
 const SessionViewsFile = "session-views.json"
 
@@ -25,16 +29,22 @@ type SessionManager struct {
 	configPath string
 	names      SessionNames
 	views      SessionViews
+	history    PromptHistory
+	links      SessionLinks
+	labels     SessionLabels
 	mu         sync.RWMutex
 }
 
 // NewSessionManager creates a new session manager.
-// Session names stay in root (synced config), session views go to local/ (per-machine state).
+// Session names stay in root (synced config), session views and prompt history go to local/ (per-machine state).
 func NewSessionManager(configPath string) (*SessionManager, error) {
 	sm := &SessionManager{
 		configPath: configPath,
 		names:      make(SessionNames),
 		views:      make(SessionViews),
+		history:    make(PromptHistory),
+		links:      make(SessionLinks),
+		labels:     make(SessionLabels),
 	}
 
 	// Migrate session-views.json from root to local/ (one-time)
@@ -43,6 +53,9 @@ func NewSessionManager(configPath string) (*SessionManager, error) {
 	// Load existing session names (from root — synced config) and views (from local/)
 	_ = sm.load()
 	_ = sm.loadViews()
+	_ = sm.loadHistory()
+	_ = sm.loadLinks()
+	_ = sm.loadLabels()
 
 	return sm, nil
 }
@@ -122,7 +135,7 @@ func (sm *SessionManager) save() error {
 		return err
 	}
 
-	return os.WriteFile(path, jsonData, 0644)
+	return fsutil.WriteFileAtomic(path, jsonData, 0644)
 }
 
 // ============================================================================
@@ -171,6 +184,16 @@ func (sm *SessionManager) GetAllLastViewed(folder string) map[string]int64 {
 	return make(map[string]int64)
 }
 
+// ReloadViews re-reads session-views.json from disk, replacing the in-memory view state.
+// Used after the file was restored from a sync or backup while the app was running.
+func (sm *SessionManager) ReloadViews() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.views = make(SessionViews)
+	return sm.loadViews()
+}
+
 // viewsPath returns the path for session-views.json in local/ (per-machine state)
 func (sm *SessionManager) viewsPath() string {
 	return filepath.Join(sm.configPath, "local", SessionViewsFile)
@@ -193,9 +216,9 @@ func (sm *SessionManager) migrateViewsToLocal() {
 	os.MkdirAll(filepath.Join(sm.configPath, "local"), 0755)
 
 	if err := os.Rename(oldPath, newPath); err != nil {
-		fmt.Printf("[WARN] Failed to migrate session-views.json to local/: %v\n", err)
+		logger.Warnf("Failed to migrate session-views.json to local/: %v", err)
 	} else {
-		fmt.Printf("[INFO] Migrated session-views.json to local/session-views.json\n")
+		logger.Infof("Migrated session-views.json to local/session-views.json")
 	}
 }
 
@@ -219,5 +242,5 @@ func (sm *SessionManager) saveViews() error {
 		return err
 	}
 
-	return os.WriteFile(sm.viewsPath(), jsonData, 0644)
+	return fsutil.WriteFileAtomic(sm.viewsPath(), jsonData, 0644)
 }
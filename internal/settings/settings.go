@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"claudefu/internal/fsutil"
+	"claudefu/internal/hooks"
 )
 
 const (
@@ -25,6 +28,8 @@ type Settings struct {
 	SifuEnabled           bool              `json:"sifuEnabled"`           // enable Sifu workspace agent (global toggle)
 	SifuRootFolder        string            `json:"sifuRootFolder"`        // parent folder for all workspace Sifus (supports ~/)
 	ClaudeCodeCommand     string            `json:"claudeCodeCommand"`     // custom claude CLI binary name or path (default: "claude")
+	ClaudeExtraArgs       []string          `json:"claudeExtraArgs,omitempty"` // extra args for every spawned claude process (e.g., ["--debug"])
+	MaxConcurrentSpawns   int               `json:"maxConcurrentSpawns,omitempty"` // max claude processes at once across all agents (0 = unlimited)
 
 	// Cache fix proxy settings (top-level = fallback for machines without a MachineSettings entry)
 	ProxyEnabled  bool   `json:"proxyEnabled"`  // Enable cache fix proxy (default: false)
@@ -34,6 +39,76 @@ type Settings struct {
 	ProxyLogging  bool   `json:"proxyLogging"`  // Enable request/response logging (default: false)
 	ProxyLogDir   string `json:"proxyLogDir"`   // Log directory (default: ~/.claudefu/proxy-logs/)
 
+	// Metrics endpoint (Prometheus text format at http://{host}:{port}/metrics)
+	MetricsEnabled bool   `json:"metricsEnabled"`        // Serve /metrics (default: false)
+	MetricsHost    string `json:"metricsHost,omitempty"` // Bind address (default: 127.0.0.1; 0.0.0.0 exposes it to the network)
+	MetricsPort    int    `json:"metricsPort"`           // Metrics port (default: 9360)
+
+	// Send size limits, checked before spawning the CLI (0 = built-in default)
+	MaxImageBytes  int `json:"maxImageBytes,omitempty"`  // Per-image limit (default: 5 MB)
+	MaxFileBytes   int `json:"maxFileBytes,omitempty"`   // Per-file attachment limit (default: 1 MB)
+	MaxPDFBytes    int `json:"maxPdfBytes,omitempty"`    // Per-PDF limit (default: 20 MB)
+	MaxSendBytes   int `json:"maxSendBytes,omitempty"`   // Whole message payload limit (default: 30 MB)
+	SendWarnTokens int `json:"sendWarnTokens,omitempty"` // Warn above this many estimated tokens (default: 100000)
+
+	// Config backup: versions ~/.claudefu (minus secrets) in local/backup.git
+	BackupEnabled         bool `json:"backupEnabled,omitempty"`         // Automatic snapshots (default: false)
+	BackupIntervalMinutes int  `json:"backupIntervalMinutes,omitempty"` // Minutes between change checks (default: 5)
+
+	// Session file polling for agents with watchMode "poll" (network filesystems)
+	WatchPollIntervalMs int `json:"watchPollIntervalMs,omitempty"` // Milliseconds between scans (default: 2000)
+
+	// Unread badges for other workspaces: their agents' session files are checked periodically
+	WorkspaceScanIntervalSec int `json:"workspaceScanIntervalSec,omitempty"` // Seconds between checks (default: 60, -1 = off)
+
+	// Live plan panel: watch ~/.claude/plans and emit plan:updated for active sessions
+	WatchPlanFiles bool `json:"watchPlanFiles,omitempty"` // (default: false)
+
+	// Session load: messages before the last compaction stay on disk until paged in
+	LoadFullHistory bool `json:"loadFullHistory,omitempty"` // Load pre-compaction messages too (default: false)
+
+	// In-memory session buffers (agents can override the per-session limits)
+	BufferMaxMessages int   `json:"bufferMaxMessages,omitempty"` // Messages kept per session (default: 750)
+	BufferMaxBytes    int64 `json:"bufferMaxBytes,omitempty"`    // Estimated bytes kept per session (default: 0 = no limit)
+	MemoryBudgetMB    int   `json:"memoryBudgetMB,omitempty"`    // All session buffers combined (default: 0 = no budget)
+
+	// Logging (see internal/logging); recent entries are viewable in-app
+	LogLevel      string            `json:"logLevel,omitempty"`      // debug, info, warn, error (default: info)
+	LogSubsystems map[string]string `json:"logSubsystems,omitempty"` // Per-subsystem level or "off" (app, watcher, runtime, providers, mcp)
+	LogToFile     bool              `json:"logToFile,omitempty"`     // Also write to ~/.claudefu/local/logs/claudefu.log (default: false)
+
+	// AgentQuery/SelfQuery sessions ("AgentQuery: ..." prompts) created in agent folders
+	QuerySessionRetentionDays int  `json:"querySessionRetentionDays,omitempty"` // Delete query sessions idle this many days (0 = keep)
+	ShowQuerySessions         bool `json:"showQuerySessions,omitempty"`         // Include query sessions in session lists (default: false)
+
+	// Name untitled sessions after their first turn with a one-shot claude --print call
+	AutoTitleSessions bool   `json:"autoTitleSessions,omitempty"` // (default: false)
+	AutoTitleModel    string `json:"autoTitleModel,omitempty"`    // Model for the title call (default: haiku)
+
+	// Session summaries (SummarizeSession, SessionSummary MCP tool)
+	SummaryModel string `json:"summaryModel,omitempty"` // Model for the summary call (default: haiku)
+
+	// YOLO-tier permission patterns the user confirmed may be auto-approved, per
+	// agent folder; unconfirmed ones are withheld from --allowedTools
+	AcknowledgedDangerousPermissions map[string][]string `json:"acknowledgedDangerousPermissions,omitempty"`
+
+	// Global kill-switch for agents' inbox auto-respond (see Agent.InboxAutoRespond)
+	InboxAutoRespondPaused bool `json:"inboxAutoRespondPaused,omitempty"` // Stop all automatic inbox dispatches (default: false)
+
+	// BrowserAgent bridge endpoint (workspaces can override it in their MCP config)
+	BrowserBridgeHost     string `json:"browserBridgeHost,omitempty"`     // (default: localhost)
+	BrowserBridgePort     int    `json:"browserBridgePort,omitempty"`     // (default: 9320)
+	BrowserBridgeTLS      bool   `json:"browserBridgeTLS,omitempty"`      // Use https/wss (default: false)
+	BrowserBridgeInsecure bool   `json:"browserBridgeInsecure,omitempty"` // Accept self-signed certificates (default: false)
+	BrowserBridgeToken    string `json:"browserBridgeToken,omitempty"`    // Bearer token for the bridge (default: none)
+
+	// Native OS notifications, sent from Go so they show while the window is minimized
+	DesktopNotifications      bool            `json:"desktopNotifications,omitempty"`      // Master switch (default: false)
+	DesktopNotificationEvents map[string]bool `json:"desktopNotificationEvents,omitempty"` // Per kind: response_complete, question, permission_request, inbox_high_priority (missing = on)
+
+	// Shell commands run on emitted events, with the event envelope JSON on stdin
+	Hooks []hooks.Hook `json:"hooks,omitempty"`
+
 	// Per-machine proxy settings, keyed by os.Hostname()
 	MachineSettings map[string]MachineProxySettings `json:"machineSettings,omitempty"`
 }
@@ -120,6 +195,7 @@ func defaultSettings() *Settings {
 		ProxyPort:     9350,
 		ProxyCacheFix: true,
 		ProxyCacheTTL: "5m",
+		MetricsPort:   9360,
 	}
 }
 
@@ -220,6 +296,19 @@ func (m *Manager) ClearAuth() error {
 	return m.SaveAuth(*defaultAuth())
 }
 
+// ReloadSettings re-reads settings.json from disk, e.g. after a backup restore.
+func (m *Manager) ReloadSettings() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := defaultSettings()
+	if err := m.readJSON(SettingsFile, s); err != nil {
+		return err
+	}
+	m.settings = s
+	return nil
+}
+
 // loadSettings loads settings from disk
 func (m *Manager) loadSettings() error {
 	return m.readJSON(SettingsFile, m.settings)
@@ -239,20 +328,15 @@ func (m *Manager) writeJSON(filename string, data interface{}) error {
 		return err
 	}
 
-	return os.WriteFile(path, jsonData, 0600) // Restrictive permissions for sensitive data
+	return fsutil.WriteFileWithBackup(path, jsonData, 0600) // Restrictive permissions for sensitive data
 }
 
 // readJSON reads JSON from a file
 func (m *Manager) readJSON(filename string, target interface{}) error {
 	path := filepath.Join(m.configPath, filename)
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // File doesn't exist, use defaults
-		}
+	if err := fsutil.ReadJSON(path, target); err != nil && !os.IsNotExist(err) {
 		return err
 	}
-
-	return json.Unmarshal(data, target)
+	return nil // A missing file keeps the defaults
 }
@@ -0,0 +1,318 @@
+// Package summary generates structured summaries of Claude Code sessions
+// (overview, decisions, open questions, files touched) with a one-shot model
+// call, and caches them per session keyed by the session's last message UUID.
+// Summaries roll forward: when a session grows, the cached summary is updated
+// from the new messages only, instead of re-reading the whole transcript.
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/export"
+	"claudefu/internal/fsutil"
+	"claudefu/internal/runtime"
+	"claudefu/internal/types"
+)
+
+// maxTranscriptChars caps the transcript sent to the model; older messages
+// are dropped first.
+const maxTranscriptChars = 60000
+
+// maxToolInputChars caps each tool call's input in the transcript
+const maxToolInputChars = 300
+
+// Summary is a structured summary of a session up to LastMessageUUID.
+type Summary struct {
+	Folder          string    `json:"folder"`
+	SessionID       string    `json:"sessionId"`
+	LastMessageUUID string    `json:"lastMessageUuid"` // Cache key: the summary covers the session up to this message
+	MessageCount    int       `json:"messageCount"`
+	Overview        string    `json:"overview"`
+	Decisions       []string  `json:"decisions"`
+	OpenQuestions   []string  `json:"openQuestions"`
+	FilesTouched    []File    `json:"filesTouched"` // From tool calls, in first-touched order
+	GeneratedAt     time.Time `json:"generatedAt"`
+	Rolling         bool      `json:"rolling,omitempty"` // Updated from the previous summary plus new messages
+}
+
+// File is a file the session changed and its net change (see runtime.FileActivity).
+type File struct {
+	Path    string `json:"path"`
+	Action  string `json:"action"`  // created, modified or deleted
+	Changes int    `json:"changes"` // Tool calls that changed it
+}
+
+// RunFunc runs prompt with a model in folder and returns its text output. ctx
+// carries the caller's spawn context (see providers.WithNestedSpawn).
+type RunFunc func(ctx context.Context, folder, prompt string) (string, error)
+
+// Service generates and caches session summaries. Safe for concurrent use;
+// concurrent requests for the same session share one model call.
+type Service struct {
+	dir string // e.g. ~/.claudefu/local/summaries
+	run RunFunc
+
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+type call struct {
+	done    chan struct{}
+	summary *Summary
+	err     error
+}
+
+// NewService creates a service caching summaries under dir.
+func NewService(dir string, run RunFunc) *Service {
+	return &Service{dir: dir, run: run, inflight: make(map[string]*call)}
+}
+
+// Cached returns the cached summary of a session (nil if none) and whether it
+// still covers the session's latest message.
+func (s *Service) Cached(folder, sessionID string) (*Summary, bool) {
+	if claudehome.ValidateSessionID(sessionID) != nil {
+		return nil, false
+	}
+	cached := s.load(sessionID)
+	if cached == nil || cached.Folder != folder {
+		return nil, false
+	}
+	messages, err := export.LoadMessages(folder, sessionID)
+	if err != nil {
+		return cached, false
+	}
+	return cached, cached.LastMessageUUID == lastMessageUUID(messages)
+}
+
+// Summarize returns an up-to-date summary of a session, generating it (or
+// rolling the cached one forward) when the session changed since it was cached.
+func (s *Service) Summarize(ctx context.Context, folder, sessionID string) (*Summary, error) {
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return nil, err
+	}
+	key := folder + "/" + sessionID
+	s.mu.Lock()
+	if c, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		<-c.done
+		return c.summary, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	s.inflight[key] = c
+	s.mu.Unlock()
+
+	c.summary, c.err = s.summarize(ctx, folder, sessionID)
+	close(c.done)
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	s.mu.Unlock()
+	return c.summary, c.err
+}
+
+func (s *Service) summarize(ctx context.Context, folder, sessionID string) (*Summary, error) {
+	messages, err := export.LoadMessages(folder, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	last := lastMessageUUID(messages)
+	if last == "" {
+		return nil, fmt.Errorf("session has no messages to summarize")
+	}
+
+	previous := s.load(sessionID)
+	if previous != nil && previous.Folder == folder && previous.LastMessageUUID == last {
+		return previous, nil
+	}
+
+	// Roll the previous summary forward when its last message is still in the session
+	newMessages := messages
+	rolling := false
+	if previous != nil && previous.Folder == folder {
+		if i := slices.IndexFunc(messages, func(m types.Message) bool { return m.UUID == previous.LastMessageUUID }); i >= 0 {
+			newMessages = messages[i+1:]
+			rolling = true
+		}
+	}
+
+	doc := export.BuildDocument(export.Meta{Folder: folder, SessionID: sessionID}, newMessages)
+	prompt := buildPrompt(renderTranscript(doc), previous, rolling)
+	output, err := s.run(ctx, folder, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	summary := parseSummary(output)
+	summary.Folder = folder
+	summary.SessionID = sessionID
+	summary.LastMessageUUID = last
+	summary.MessageCount = len(messages)
+	summary.GeneratedAt = time.Now()
+	summary.Rolling = rolling
+	summary.FilesTouched = filesTouched(runtime.ExtractFileActivity(folder, messages))
+
+	if err := s.save(summary); err != nil {
+		return summary, fmt.Errorf("summary generated but not cached: %w", err)
+	}
+	return summary, nil
+}
+
+// Format renders a summary as a context block for another agent's prompt
+// (AgentHandoff packets, AgentQuery context).
+func (sum *Summary) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<session_summary session=\"%s\">\n", sum.SessionID)
+	if sum.Overview != "" {
+		fmt.Fprintf(&b, "<overview>\n%s\n</overview>\n", sum.Overview)
+	}
+	writeList := func(tag string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "<%s>\n", tag)
+		for _, item := range items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		fmt.Fprintf(&b, "</%s>\n", tag)
+	}
+	files := make([]string, 0, len(sum.FilesTouched))
+	for _, f := range sum.FilesTouched {
+		files = append(files, fmt.Sprintf("%s (%s)", f.Path, f.Action))
+	}
+	writeList("decisions", sum.Decisions)
+	writeList("open_questions", sum.OpenQuestions)
+	writeList("files_touched", files)
+	b.WriteString("</session_summary>")
+	return b.String()
+}
+
+// lastMessageUUID returns the UUID of the last user or assistant message.
+func lastMessageUUID(messages []types.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].UUID != "" && (messages[i].Type == "user" || messages[i].Type == "assistant") {
+			return messages[i].UUID
+		}
+	}
+	return ""
+}
+
+// filesTouched condenses a session's file activity for the summary.
+func filesTouched(activity []runtime.FileActivity) []File {
+	files := make([]File, 0, len(activity))
+	for _, a := range activity {
+		files = append(files, File{Path: a.Path, Action: a.Action, Changes: len(a.Touches)})
+	}
+	return files
+}
+
+// renderTranscript writes a compact transcript, keeping the most recent
+// maxTranscriptChars.
+func renderTranscript(doc export.Document) string {
+	var parts []string
+	for _, entry := range doc.Entries {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[%s]", strings.ToUpper(entry.Role))
+		if entry.Text != "" {
+			b.WriteString(" " + entry.Text)
+		}
+		for _, tc := range entry.ToolCalls {
+			input, _ := json.Marshal(tc.Input)
+			fmt.Fprintf(&b, "\n  (tool %s %s)", tc.Name, truncate(string(input), maxToolInputChars))
+		}
+		parts = append(parts, b.String())
+	}
+
+	total := 0
+	start := len(parts)
+	for start > 0 && total+len(parts[start-1]) <= maxTranscriptChars {
+		start--
+		total += len(parts[start]) + 2
+	}
+	transcript := strings.Join(parts[start:], "\n\n")
+	if start > 0 {
+		transcript = fmt.Sprintf("(%d earlier messages omitted)\n\n", start) + transcript
+	}
+	return transcript
+}
+
+// buildPrompt asks for a JSON summary of transcript, updating previous when rolling.
+func buildPrompt(transcript string, previous *Summary, rolling bool) string {
+	var b strings.Builder
+	b.WriteString("Summarize the Claude Code session below for another engineer or agent who will continue the work.\n")
+	b.WriteString(`Respond with ONLY a JSON object: {"overview": "2-4 sentences on the goal and current state", ` +
+		`"decisions": ["decision and its reason", ...], "openQuestions": ["unresolved question or remaining task", ...]}` + "\n")
+	if rolling && previous != nil {
+		prev, _ := json.Marshal(map[string]any{
+			"overview":      previous.Overview,
+			"decisions":     previous.Decisions,
+			"openQuestions": previous.OpenQuestions,
+		})
+		b.WriteString("\nThis is an update: the session continued after the previous summary below. ")
+		b.WriteString("Merge in the new messages; drop questions that were resolved.\n")
+		fmt.Fprintf(&b, "<previous_summary>\n%s\n</previous_summary>\n", prev)
+		fmt.Fprintf(&b, "\n<new_messages>\n%s\n</new_messages>", transcript)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n<transcript>\n%s\n</transcript>", transcript)
+	return b.String()
+}
+
+// parseSummary reads the model's JSON reply. Output that isn't JSON becomes the overview.
+func parseSummary(output string) *Summary {
+	summary := &Summary{}
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start >= 0 && end > start && json.Unmarshal([]byte(output[start:end+1]), summary) == nil {
+		summary.Overview = strings.TrimSpace(summary.Overview)
+	} else {
+		summary.Overview = strings.TrimSpace(output)
+	}
+	if summary.Decisions == nil {
+		summary.Decisions = []string{}
+	}
+	if summary.OpenQuestions == nil {
+		summary.OpenQuestions = []string{}
+	}
+	return summary
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+// path returns the cache file of a session.
+func (s *Service) path(sessionID string) string {
+	return filepath.Join(s.dir, filepath.Base(sessionID)+".json")
+}
+
+// load reads a cached summary (nil if none or unreadable).
+func (s *Service) load(sessionID string) *Summary {
+	var summary Summary
+	if err := fsutil.ReadJSON(s.path(sessionID), &summary); err != nil {
+		return nil
+	}
+	return &summary
+}
+
+// save writes summary to the cache.
+func (s *Service) save(summary *Summary) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(s.path(summary.SessionID), data, 0644)
+}
@@ -0,0 +1,394 @@
+// Package tasks provides a workspace-level task graph: tasks with dependencies,
+// assigned to agents, so an orchestrator agent can coordinate multi-agent work.
+// Each workspace's graph is persisted as JSON at {configPath}/{workspace_id}.json.
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stored task statuses.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+	StatusCancelled  = "cancelled"
+)
+
+// Derived graph states (in addition to the stored statuses above).
+const (
+	StateBlocked = "blocked" // Pending, waiting on unfinished dependencies
+	StateReady   = "ready"   // Pending, all dependencies done
+)
+
+// ValidStatuses lists the statuses accepted by SetStatus.
+var ValidStatuses = []string{StatusPending, StatusInProgress, StatusDone, StatusCancelled}
+
+// Task is a unit of work in a workspace task graph.
+type Task struct {
+	ID              string     `json:"id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description,omitempty"`
+	DependsOn       []string   `json:"dependsOn,omitempty"`       // Task IDs that must be done first
+	AssignedAgentID string     `json:"assignedAgentId,omitempty"` // Agent responsible for the task
+	SessionID       string     `json:"sessionId,omitempty"`       // Session the work happens in (drives activity)
+	Status          string     `json:"status"`
+	Result          string     `json:"result,omitempty"` // Completion summary
+	CreatedBy       string     `json:"createdBy,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
+
+// CreateInput holds the fields for a new task.
+type CreateInput struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	DependsOn       []string `json:"dependsOn"`
+	AssignedAgentID string   `json:"assignedAgentId"`
+	SessionID       string   `json:"sessionId"`
+	CreatedBy       string   `json:"createdBy"`
+}
+
+// Node is a task plus its derived state, as rendered in the DAG.
+type Node struct {
+	Task
+	State     string   `json:"state"`               // Status, or blocked/ready for pending tasks
+	BlockedBy []string `json:"blockedBy,omitempty"` // Unfinished dependency IDs
+	Activity  string   `json:"activity,omitempty"`  // Session activity from ActivityFunc ("streaming", "idle")
+}
+
+// Edge points from a dependency to the task that depends on it.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the renderable DAG for a workspace.
+type Graph struct {
+	WorkspaceID string `json:"workspaceId"`
+	Nodes       []Node `json:"nodes"`
+	Edges       []Edge `json:"edges"`
+}
+
+// ActivityFunc reports the activity of an agent session ("streaming", "idle", or "").
+type ActivityFunc func(agentID, sessionID string) string
+
+// graphFile is the on-disk format.
+type graphFile struct {
+	Version int    `json:"version"`
+	Tasks   []Task `json:"tasks"`
+}
+
+const graphFileVersion = 1
+
+// Manager loads, mutates, and persists per-workspace task graphs.
+type Manager struct {
+	configPath string            // ~/.claudefu/tasks
+	graphs     map[string][]Task // workspaceID → tasks (creation order), lazily loaded
+	activity   ActivityFunc
+	mu         sync.Mutex
+}
+
+// NewManager creates a task manager storing graphs under configPath.
+func NewManager(configPath string) *Manager {
+	return &Manager{
+		configPath: configPath,
+		graphs:     make(map[string][]Task),
+	}
+}
+
+// SetActivityFunc sets how Graph derives session activity for assigned tasks.
+func (m *Manager) SetActivityFunc(fn ActivityFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activity = fn
+}
+
+// List returns all tasks in a workspace, in creation order.
+func (m *Manager) List(workspaceID string) ([]Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(tasks), nil
+}
+
+// Get returns a single task.
+func (m *Manager) Get(workspaceID, taskID string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	i := indexOf(tasks, taskID)
+	if i < 0 {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	task := tasks[i]
+	return &task, nil
+}
+
+// Create adds a task. Dependencies must already exist, so the graph stays acyclic.
+func (m *Manager) Create(workspaceID string, in CreateInput) (*Task, error) {
+	if in.Title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []string
+	for _, dep := range in.DependsOn {
+		if dep == "" || slices.Contains(deps, dep) {
+			continue
+		}
+		if indexOf(tasks, dep) < 0 {
+			return nil, fmt.Errorf("dependency not found: %s", dep)
+		}
+		deps = append(deps, dep)
+	}
+
+	now := time.Now()
+	task := Task{
+		ID:              uuid.New().String(),
+		Title:           in.Title,
+		Description:     in.Description,
+		DependsOn:       deps,
+		AssignedAgentID: in.AssignedAgentID,
+		SessionID:       in.SessionID,
+		Status:          StatusPending,
+		CreatedBy:       in.CreatedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := m.save(workspaceID, append(tasks, task)); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Assign sets the agent (and optionally the session) responsible for a task.
+func (m *Manager) Assign(workspaceID, taskID, agentID, sessionID string) (*Task, error) {
+	return m.update(workspaceID, taskID, func(t *Task) error {
+		if t.Status == StatusDone || t.Status == StatusCancelled {
+			return fmt.Errorf("task is %s", t.Status)
+		}
+		if t.AssignedAgentID != agentID {
+			t.SessionID = "" // A session belongs to the previous assignee
+		}
+		t.AssignedAgentID = agentID
+		if sessionID != "" {
+			t.SessionID = sessionID
+		}
+		return nil
+	})
+}
+
+// Complete marks a task done. All its dependencies must be done first.
+func (m *Manager) Complete(workspaceID, taskID, result string) (*Task, error) {
+	return m.update(workspaceID, taskID, func(t *Task) error {
+		if t.Status == StatusCancelled {
+			return fmt.Errorf("task is cancelled")
+		}
+		if blocked := m.blockedBy(workspaceID, *t); len(blocked) > 0 {
+			return fmt.Errorf("task is blocked by unfinished dependencies: %v", blocked)
+		}
+		now := time.Now()
+		t.Status = StatusDone
+		t.Result = result
+		t.CompletedAt = &now
+		return nil
+	})
+}
+
+// SetStatus sets a task's stored status (e.g. in_progress when work starts, or cancelled).
+func (m *Manager) SetStatus(workspaceID, taskID, status string) (*Task, error) {
+	if status == StatusDone {
+		return m.Complete(workspaceID, taskID, "")
+	}
+	if !slices.Contains(ValidStatuses, status) {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return m.update(workspaceID, taskID, func(t *Task) error {
+		t.Status = status
+		t.CompletedAt = nil
+		return nil
+	})
+}
+
+// Delete removes a task. Fails if other tasks depend on it.
+func (m *Manager) Delete(workspaceID, taskID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return err
+	}
+	i := indexOf(tasks, taskID)
+	if i < 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	for _, t := range tasks {
+		if slices.Contains(t.DependsOn, taskID) {
+			return fmt.Errorf("task %q depends on it; delete or cancel that task first", t.Title)
+		}
+	}
+	return m.save(workspaceID, slices.Delete(slices.Clone(tasks), i, i+1))
+}
+
+// Graph returns the workspace DAG with derived per-task state.
+// Pending tasks are blocked or ready depending on their dependencies; a pending
+// task whose session is streaming is reported as in_progress.
+func (m *Manager) Graph(workspaceID string) (*Graph, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{WorkspaceID: workspaceID, Nodes: []Node{}, Edges: []Edge{}}
+	for _, t := range tasks {
+		node := Node{Task: t, State: t.Status}
+		if m.activity != nil && t.AssignedAgentID != "" && t.SessionID != "" {
+			node.Activity = m.activity(t.AssignedAgentID, t.SessionID)
+		}
+		if t.Status == StatusPending {
+			node.BlockedBy = m.blockedBy(workspaceID, t)
+			switch {
+			case len(node.BlockedBy) > 0:
+				node.State = StateBlocked
+			case node.Activity == "streaming":
+				node.State = StatusInProgress
+			default:
+				node.State = StateReady
+			}
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		for _, dep := range t.DependsOn {
+			graph.Edges = append(graph.Edges, Edge{From: dep, To: t.ID})
+		}
+	}
+	return graph, nil
+}
+
+// update applies fn to one task and persists. fn may return an error to abort.
+func (m *Manager) update(workspaceID, taskID string, fn func(t *Task) error) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks, err := m.load(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	i := indexOf(tasks, taskID)
+	if i < 0 {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+
+	updated := slices.Clone(tasks)
+	task := &updated[i]
+	if err := fn(task); err != nil {
+		return nil, err
+	}
+	task.UpdatedAt = time.Now()
+
+	if err := m.save(workspaceID, updated); err != nil {
+		return nil, err
+	}
+	result := *task
+	return &result, nil
+}
+
+// blockedBy returns the IDs of t's dependencies that are not done.
+// Cancelled dependencies count as unfinished. Caller must hold m.mu.
+func (m *Manager) blockedBy(workspaceID string, t Task) []string {
+	tasks := m.graphs[workspaceID]
+	var blocked []string
+	for _, dep := range t.DependsOn {
+		if i := indexOf(tasks, dep); i < 0 || tasks[i].Status != StatusDone {
+			blocked = append(blocked, dep)
+		}
+	}
+	return blocked
+}
+
+// load returns the cached tasks for a workspace, reading from disk on first use.
+// Caller must hold m.mu.
+func (m *Manager) load(workspaceID string) ([]Task, error) {
+	if workspaceID == "" {
+		return nil, fmt.Errorf("no workspace loaded")
+	}
+	if tasks, ok := m.graphs[workspaceID]; ok {
+		return tasks, nil
+	}
+
+	var file graphFile
+	data, err := os.ReadFile(m.graphPath(workspaceID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read task graph: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse task graph: %w", err)
+		}
+	}
+
+	m.graphs[workspaceID] = file.Tasks
+	return file.Tasks, nil
+}
+
+// save writes tasks to disk (atomic rename) and updates the cache. Caller must hold m.mu.
+func (m *Manager) save(workspaceID string, tasks []Task) error {
+	if err := os.MkdirAll(m.configPath, 0755); err != nil {
+		return fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(graphFile{Version: graphFileVersion, Tasks: tasks}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal task graph: %w", err)
+	}
+
+	path := m.graphPath(workspaceID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task graph: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write task graph: %w", err)
+	}
+
+	m.graphs[workspaceID] = tasks
+	return nil
+}
+
+func (m *Manager) graphPath(workspaceID string) string {
+	return filepath.Join(m.configPath, workspaceID+".json")
+}
+
+func indexOf(tasks []Task, id string) int {
+	return slices.IndexFunc(tasks, func(t Task) bool { return t.ID == id })
+}
@@ -272,6 +272,19 @@ func (c *ClassifiedJSONLEvent) IsUserMessage() bool {
 	return true
 }
 
+// IsCompactionBoundary returns true if this event starts a compacted context:
+// a compact_boundary system event, or the compaction summary user message
+// (older CLI versions write only the summary).
+func (c *ClassifiedJSONLEvent) IsCompactionBoundary() bool {
+	switch c.EventType {
+	case JSONLEventSystem:
+		return c.System != nil && c.System.Subtype == SystemSubtypeCompactBoundary
+	case JSONLEventUser:
+		return c.User != nil && c.User.IsCompactSummary
+	}
+	return false
+}
+
 // IsAssistantMessage returns true if this is a displayable assistant message.
 func (c *ClassifiedJSONLEvent) IsAssistantMessage() bool {
 	if c.EventType != JSONLEventAssistant || c.Assistant == nil {
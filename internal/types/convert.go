@@ -4,11 +4,14 @@
 package types
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
+
+	"claudefu/internal/logging"
 )
 
+var logger = logging.For(logging.App)
+
 // imageRefPattern matches duplicate image reference messages that should be filtered out.
 var imageRefPattern = regexp.MustCompile(`^\s*\[Image: source: [^\]]+\]\s*$`)
 
@@ -36,6 +39,35 @@ func ConvertToMessage(classified *ClassifiedJSONLEvent) *Message {
 	}
 }
 
+// ConvertStreamingLine converts a line of the CLI's stream-json stdout to a
+// displayable Message. Assistant and user lines have the same shape and UUID
+// as the JSONL events the CLI writes for them, so they convert the same way
+// and dedupe against the file watcher's later read. Stream lines carry no
+// timestamp; timestamp is used instead. Returns nil for other events, lines
+// that don't parse, and subagent events (those go to the subagent's own file).
+func ConvertStreamingLine(line, timestamp string) *Message {
+	streaming, err := ClassifyStreamingEvent(line)
+	if err != nil {
+		return nil
+	}
+	switch {
+	case streaming.Assistant != nil && streaming.Assistant.ParentToolUseID == "":
+	case streaming.User != nil && streaming.User.ParentToolUseID == "":
+	default:
+		return nil
+	}
+
+	classified, err := ClassifyJSONLEvent(line)
+	if err != nil {
+		return nil
+	}
+	msg := ConvertToMessage(classified)
+	if msg != nil && msg.Timestamp == "" {
+		msg.Timestamp = timestamp
+	}
+	return msg
+}
+
 // =============================================================================
 // SUMMARY EVENT CONVERSION
 // =============================================================================
@@ -282,7 +314,7 @@ func extractContentBlock(blockMap map[string]any) *ContentBlock {
 				isError = v
 			default:
 				// Unexpected type - log and treat as false
-				fmt.Printf("[WARN] tool_result is_error unexpected type: %T value: %v\n", isErrVal, isErrVal)
+				logger.Warnf("tool_result is_error unexpected type: %T value: %v", isErrVal, isErrVal)
 			}
 		}
 		toolUseID := getString(blockMap, "tool_use_id")
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// MESSAGE POST-PROCESSORS
+// =============================================================================
+
+// Post-processor names (configured per agent in Agent.PostProcessors).
+const (
+	PostProcessLinkPaths      = "link_paths"      // Turn absolute file paths in assistant text into file:// links
+	PostProcessStripANSI      = "strip_ansi"      // Remove ANSI escape sequences from tool results (Bash output)
+	PostProcessCollapseBase64 = "collapse_base64" // Replace huge base64 blobs in text/tool results with a placeholder
+)
+
+// AllPostProcessors lists every available post-processor in application order.
+var AllPostProcessors = []string{
+	PostProcessStripANSI,
+	PostProcessCollapseBase64,
+	PostProcessLinkPaths,
+}
+
+// minBase64BlobLen is the shortest run of base64 characters that gets collapsed.
+const minBase64BlobLen = 1024
+
+var (
+	ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+	base64BlobRe = regexp.MustCompile(`[A-Za-z0-9+/]{256,}={0,2}`) // RE2 caps repeats at 1000; length checked in collapseBase64
+	// Absolute path with an extension, optionally followed by :line. Must start a word
+	// (not inside an existing link or URL).
+	filePathRe = regexp.MustCompile(`(^|\s)(/(?:[\w.\-]+/)*[\w.\-]+\.[A-Za-z0-9]+)(:\d+)?`)
+)
+
+// PostProcessMessages applies the named processors to user/assistant messages.
+// Messages are copied (content blocks included) so the input slice is not mutated.
+// Unknown processor names are ignored.
+func PostProcessMessages(messages []Message, processors []string) []Message {
+	if len(processors) == 0 || len(messages) == 0 {
+		return messages
+	}
+
+	enabled := make(map[string]bool, len(processors))
+	for _, p := range processors {
+		enabled[p] = true
+	}
+
+	result := make([]Message, len(messages))
+	for i, msg := range messages {
+		if msg.Type == "assistant" {
+			msg.Content = postProcessText(msg.Content, enabled)
+		}
+		if len(msg.ContentBlocks) > 0 {
+			blocks := make([]ContentBlock, len(msg.ContentBlocks))
+			copy(blocks, msg.ContentBlocks)
+			for j := range blocks {
+				postProcessBlock(&blocks[j], msg.Type, enabled)
+			}
+			msg.ContentBlocks = blocks
+		}
+		result[i] = msg
+	}
+	return result
+}
+
+// postProcessBlock applies processors to a single content block in place.
+func postProcessBlock(block *ContentBlock, msgType string, enabled map[string]bool) {
+	switch block.Type {
+	case "text":
+		if msgType == "assistant" {
+			block.Text = postProcessText(block.Text, enabled)
+		}
+	case "tool_result":
+		content, ok := block.Content.(string)
+		if !ok {
+			return
+		}
+		if enabled[PostProcessStripANSI] {
+			content = ansiEscapeRe.ReplaceAllString(content, "")
+		}
+		if enabled[PostProcessCollapseBase64] {
+			content = collapseBase64(content)
+		}
+		block.Content = content
+	}
+}
+
+// postProcessText applies processors to assistant markdown text.
+func postProcessText(text string, enabled map[string]bool) string {
+	if text == "" {
+		return text
+	}
+	if enabled[PostProcessStripANSI] {
+		text = ansiEscapeRe.ReplaceAllString(text, "")
+	}
+	if enabled[PostProcessCollapseBase64] {
+		text = collapseBase64(text)
+	}
+	if enabled[PostProcessLinkPaths] {
+		text = linkFilePaths(text)
+	}
+	return text
+}
+
+// collapseBase64 replaces long base64 runs with a size placeholder.
+func collapseBase64(s string) string {
+	return base64BlobRe.ReplaceAllStringFunc(s, func(blob string) string {
+		if len(blob) < minBase64BlobLen {
+			return blob
+		}
+		return fmt.Sprintf("[base64 blob, %d chars collapsed]", len(blob))
+	})
+}
+
+// linkFilePaths turns absolute file paths into markdown links, skipping fenced
+// code blocks and inline code so code samples render untouched.
+func linkFilePaths(text string) string {
+	fences := strings.Split(text, "```")
+	for i := 0; i < len(fences); i += 2 { // Even segments are outside fences
+		spans := strings.Split(fences[i], "`")
+		for j := 0; j < len(spans); j += 2 { // Even spans are outside inline code
+			spans[j] = filePathRe.ReplaceAllString(spans[j], "$1[$2$3](file://$2)")
+		}
+		fences[i] = strings.Join(spans, "`")
+	}
+	return strings.Join(fences, "```")
+}
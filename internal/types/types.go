@@ -2,7 +2,10 @@
 // These types are used across workspace, watcher, and runtime packages.
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // =============================================================================
 // MESSAGE TYPES (from JSONL parsing)
@@ -22,6 +25,7 @@ type Message struct {
 	StopReason        string           `json:"stopReason,omitempty"`      // "stop_sequence" when complete (JSONL), "end_turn" (streaming), null when tools pending
 	Usage             *TokenUsage      `json:"usage,omitempty"`           // Token usage for assistant messages (input/output/cache tokens)
 	Slug              string           `json:"slug,omitempty"`            // Session slug (e.g., "polymorphic-roaming-hummingbird") - plan file at ~/.claude/plans/{slug}.md
+	IsPending         bool             `json:"isPending,omitempty"`       // Provisional echo of a sent user message, replaced when the CLI writes it
 }
 
 // PendingQuestion tracks a failed AskUserQuestion tool call that needs user interaction.
@@ -75,9 +79,9 @@ type ImageSource struct {
 // For files: Frontend reads file content via ReadFileContent and passes raw text.
 // Supported image media types: image/png, image/jpeg, image/gif, image/webp
 type Attachment struct {
-	Type      string `json:"type"`                 // "image" or "file"
+	Type      string `json:"type"`                 // "image", "file", or "document" (PDF)
 	MediaType string `json:"media_type"`           // MIME type
-	Data      string `json:"data"`                 // Base64 for images, raw content for files
+	Data      string `json:"data"`                 // Base64 for images and documents, raw content for files
 	// File-specific fields
 	FilePath  string `json:"filePath,omitempty"`   // Absolute path for file attachments
 	FileName  string `json:"fileName,omitempty"`   // Display name
@@ -98,6 +102,13 @@ type EventEnvelope struct {
 	Payload     any `json:"payload"` // Event-specific data
 }
 
+// WorkspaceEvent namespaces an event name by workspace, for events of workspaces
+// running in the background ("workspace:<id>:session:messages"). The active
+// workspace's events keep their plain names.
+func WorkspaceEvent(workspaceID, eventType string) string {
+	return "workspace:" + workspaceID + ":" + eventType
+}
+
 // =============================================================================
 // SESSION TYPES
 // =============================================================================
@@ -111,6 +122,32 @@ type Session struct {
 	MessageCount int       `json:"messageCount"` // Total messages in session
 	CreatedAt    time.Time `json:"createdAt"`    // From first message timestamp
 	UpdatedAt    time.Time `json:"updatedAt"`    // From last message timestamp
+
+	// Status fields computed by the runtime so the sidebar doesn't need the conversation
+	LastMessagePreview string `json:"lastMessagePreview,omitempty"` // Last user/assistant text snippet
+	LastRole           string `json:"lastRole,omitempty"`           // "user" or "assistant"
+	HasPendingQuestion bool   `json:"hasPendingQuestion"`           // Unanswered AskUserQuestion at the tail
+	IsStreaming        bool   `json:"isStreaming"`                  // Claude CLI process currently running
+	PlanMode           bool   `json:"planMode"`                     // Last send was in plan mode
+	Presence           string `json:"presence,omitempty"`           // Live status chip: thinking, streaming, or completed
+	Status             string `json:"status,omitempty"`             // Activity status: idle, thinking, responding, tool_running, waiting_on_user
+	IsQuery            bool   `json:"isQuery,omitempty"`            // Created by AgentQuery/SelfQuery (see IsQueryPrompt)
+
+	// User labels from the session manager (filled in by the App, not the runtime)
+	Tags   []string `json:"tags,omitempty"`
+	Pinned bool     `json:"pinned,omitempty"`
+}
+
+// Prompt prefixes that mark sessions created by the AgentQuery and SelfQuery MCP tools.
+const (
+	AgentQueryPrefix = "AgentQuery: "
+	SelfQueryPrefix  = "SelfQuery: "
+)
+
+// IsQueryPrompt reports whether a session's first user message marks it as an
+// AgentQuery/SelfQuery session rather than a regular conversation.
+func IsQueryPrompt(preview string) bool {
+	return strings.HasPrefix(preview, AgentQueryPrefix) || strings.HasPrefix(preview, SelfQueryPrefix)
 }
 
 // =============================================================================
@@ -147,8 +184,24 @@ const (
 const (
 	WatchModeFile   = "file"   // Watch JSONL files via fsnotify
 	WatchModeStream = "stream" // Parse CLI streaming output directly
+	WatchModePoll   = "poll"   // Stat-poll JSONL files (network filesystems where fsnotify is unreliable)
+)
+
+// =============================================================================
+// PERMISSION MODE
+// =============================================================================
+
+// Permission modes for Agent.DefaultPermissionMode (the CLI's --permission-mode choices)
+const (
+	PermissionModeDefault           = "default"           // Ask before edits and commands
+	PermissionModeAcceptEdits       = "acceptEdits"       // Auto-accept file edits (ClaudeFu's default)
+	PermissionModePlan              = "plan"              // Plan only, no changes
+	PermissionModeBypassPermissions = "bypassPermissions" // Skip all permission checks
 )
 
+// PermissionModes lists the supported permission modes.
+var PermissionModes = []string{PermissionModeDefault, PermissionModeAcceptEdits, PermissionModePlan, PermissionModeBypassPermissions}
+
 // =============================================================================
 // UNREAD TRACKING
 // =============================================================================
@@ -0,0 +1,61 @@
+package usage
+
+import (
+	"strings"
+
+	"claudefu/internal/types"
+)
+
+// price is USD per million tokens.
+type price struct {
+	input  float64
+	output float64
+}
+
+// modelPrices maps model name prefixes to list prices, most specific first.
+// Unknown models are priced as Sonnet.
+var modelPrices = []struct {
+	prefix string
+	price  price
+}{
+	{"claude-opus-4-5", price{5, 25}},
+	{"claude-opus-4-6", price{5, 25}},
+	{"claude-opus", price{15, 75}},
+	{"claude-3-opus", price{15, 75}},
+	{"claude-sonnet", price{3, 15}},
+	{"claude-3-7-sonnet", price{3, 15}},
+	{"claude-3-5-sonnet", price{3, 15}},
+	{"claude-haiku-4", price{1, 5}},
+	{"claude-3-5-haiku", price{0.8, 4}},
+	{"claude-3-haiku", price{0.25, 1.25}},
+}
+
+var defaultPrice = price{3, 15}
+
+// Cache pricing relative to the input price.
+const (
+	cacheWrite5mMultiplier = 1.25
+	cacheWrite1hMultiplier = 2.0
+	cacheReadMultiplier    = 0.1
+)
+
+// EstimateCost returns the list-price cost in USD of one API response.
+// It ignores subscription plans and discounts, so treat it as an estimate.
+func EstimateCost(model string, u types.TokenUsage) float64 {
+	p := defaultPrice
+	for _, mp := range modelPrices {
+		if strings.HasPrefix(model, mp.prefix) {
+			p = mp.price
+			break
+		}
+	}
+
+	cacheWrite := float64(u.CacheCreationInputTokens) * cacheWrite5mMultiplier
+	if cc := u.CacheCreation; cc != nil && cc.Ephemeral5mInputTokens+cc.Ephemeral1hInputTokens > 0 {
+		cacheWrite = float64(cc.Ephemeral5mInputTokens)*cacheWrite5mMultiplier +
+			float64(cc.Ephemeral1hInputTokens)*cacheWrite1hMultiplier
+	}
+
+	inputUnits := float64(u.InputTokens) + cacheWrite + float64(u.CacheReadInputTokens)*cacheReadMultiplier
+	return (inputUnits*p.input + float64(u.OutputTokens)*p.output) / 1_000_000
+}
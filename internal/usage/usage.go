@@ -0,0 +1,247 @@
+// Package usage aggregates token usage and estimated cost from the usage blocks
+// of assistant messages in Claude Code session files (including subagent
+// transcripts). Files are read incrementally by byte offset, and repeated lines
+// of the same API message (Claude Code writes one line per content block) are
+// counted once.
+package usage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/fsutil"
+	"claudefu/internal/types"
+)
+
+// DayFormat is the layout of day keys in Stats.ByDay (local time).
+const DayFormat = "2006-01-02"
+
+// Totals is accumulated token usage.
+type Totals struct {
+	InputTokens         int64   `json:"inputTokens"`
+	OutputTokens        int64   `json:"outputTokens"`
+	CacheCreationTokens int64   `json:"cacheCreationTokens"`
+	CacheReadTokens     int64   `json:"cacheReadTokens"`
+	Messages            int     `json:"messages"` // API responses counted
+	CostUSD             float64 `json:"costUsd"`  // Estimate from list prices (see EstimateCost)
+}
+
+// Add adds other to t.
+func (t *Totals) Add(other Totals) {
+	t.InputTokens += other.InputTokens
+	t.OutputTokens += other.OutputTokens
+	t.CacheCreationTokens += other.CacheCreationTokens
+	t.CacheReadTokens += other.CacheReadTokens
+	t.Messages += other.Messages
+	t.CostUSD += other.CostUSD
+}
+
+// Stats is usage for one agent folder, broken down several ways.
+type Stats struct {
+	Total     Totals            `json:"total"`
+	ByDay     map[string]Totals `json:"byDay"`     // DayFormat -> totals
+	BySession map[string]Totals `json:"bySession"` // sessionID -> totals
+	ByModel   map[string]Totals `json:"byModel"`   // model -> totals
+}
+
+// entry is the usage of one API message.
+type entry struct {
+	day   string
+	model string
+	usage types.TokenUsage
+}
+
+func (e entry) totals() Totals {
+	return Totals{
+		InputTokens:         int64(e.usage.InputTokens),
+		OutputTokens:        int64(e.usage.OutputTokens),
+		CacheCreationTokens: int64(e.usage.CacheCreationInputTokens),
+		CacheReadTokens:     int64(e.usage.CacheReadInputTokens),
+		Messages:            1,
+		CostUSD:             EstimateCost(e.model, e.usage),
+	}
+}
+
+// Tracker holds usage for every session it has been asked to read.
+type Tracker struct {
+	mu      sync.Mutex
+	offsets map[string]int64                       // file path -> bytes read
+	entries map[string]map[string]map[string]entry // folder -> sessionID -> message ID -> usage
+}
+
+// NewTracker creates an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		offsets: make(map[string]int64),
+		entries: make(map[string]map[string]map[string]entry),
+	}
+}
+
+// UpdateFolder reads new content from every session in an agent folder.
+func (t *Tracker) UpdateFolder(folder string) error {
+	paths, err := filepath.Glob(filepath.Join(claudehome.ProjectDir(folder), "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if strings.HasPrefix(sessionID, "agent-") {
+			continue
+		}
+		t.UpdateSession(folder, sessionID)
+	}
+	return nil
+}
+
+// UpdateSession reads new content from a session file and its subagent
+// transcripts. Returns true if the session's usage changed.
+func (t *Tracker) UpdateSession(folder, sessionID string) bool {
+	paths := []string{claudehome.SessionPath(folder, sessionID)}
+	if subagents, err := filepath.Glob(filepath.Join(claudehome.SubagentsDir(folder, sessionID), "*.jsonl")); err == nil {
+		paths = append(paths, subagents...)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changed := false
+	for _, path := range paths {
+		if t.readFileLocked(path, folder, sessionID) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// RemoveSession forgets a session (e.g. after it was deleted).
+func (t *Tracker) RemoveSession(folder, sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries[folder], sessionID)
+	sessionPath := claudehome.SessionPath(folder, sessionID)
+	subagentsDir := claudehome.SubagentsDir(folder, sessionID) + string(filepath.Separator)
+	for path := range t.offsets {
+		if path == sessionPath || strings.HasPrefix(path, subagentsDir) {
+			delete(t.offsets, path)
+		}
+	}
+}
+
+// SessionTotals returns a session's accumulated usage.
+func (t *Tracker) SessionTotals(folder, sessionID string) Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total Totals
+	for _, e := range t.entries[folder][sessionID] {
+		total.Add(e.totals())
+	}
+	return total
+}
+
+// Stats returns a folder's usage from messages on or after since (zero = all time).
+func (t *Tracker) Stats(folder string, since time.Time) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := Stats{
+		ByDay:     make(map[string]Totals),
+		BySession: make(map[string]Totals),
+		ByModel:   make(map[string]Totals),
+	}
+	sinceDay := ""
+	if !since.IsZero() {
+		sinceDay = since.Local().Format(DayFormat)
+	}
+	for sessionID, messages := range t.entries[folder] {
+		for _, e := range messages {
+			if e.day < sinceDay {
+				continue
+			}
+			totals := e.totals()
+			stats.Total.Add(totals)
+			addTo(stats.ByDay, e.day, totals)
+			addTo(stats.BySession, sessionID, totals)
+			addTo(stats.ByModel, e.model, totals)
+		}
+	}
+	return stats
+}
+
+func addTo(m map[string]Totals, key string, totals Totals) {
+	current := m[key]
+	current.Add(totals)
+	m[key] = current
+}
+
+// usageLine is the subset of an assistant JSONL line needed for accounting.
+type usageLine struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	Message   struct {
+		ID    string           `json:"id"`
+		Model string           `json:"model"`
+		Usage types.TokenUsage `json:"usage"`
+	} `json:"message"`
+}
+
+// readFileLocked reads lines appended to path since the last call. A file that
+// shrank (rewritten) is reread from the start. Caller must hold t.mu.
+func (t *Tracker) readFileLocked(path, folder, sessionID string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	offset := t.offsets[path]
+	if info.Size() == offset {
+		return false
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+
+	sessions, ok := t.entries[folder]
+	if !ok {
+		sessions = make(map[string]map[string]entry)
+		t.entries[folder] = sessions
+	}
+	messages, ok := sessions[sessionID]
+	if !ok {
+		messages = make(map[string]entry)
+		sessions[sessionID] = messages
+	}
+
+	changed := false
+	offset, err = fsutil.ReadLinesFrom(path, offset, func(line string) error {
+		if !strings.Contains(line, `"usage"`) {
+			return nil
+		}
+		var parsed usageLine
+		if json.Unmarshal([]byte(line), &parsed) != nil || parsed.Type != types.EventTypeAssistant {
+			return nil
+		}
+		msg := parsed.Message
+		if msg.ID == "" || msg.Model == "<synthetic>" || (msg.Usage.InputTokens == 0 && msg.Usage.OutputTokens == 0) {
+			return nil
+		}
+		ts, err := time.Parse(time.RFC3339Nano, parsed.Timestamp)
+		if err != nil {
+			return nil
+		}
+		// Later lines of the same message carry the final output token count
+		messages[msg.ID] = entry{day: ts.Local().Format(DayFormat), model: msg.Model, usage: msg.Usage}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return changed
+	}
+	t.offsets[path] = offset
+	return changed
+}
@@ -0,0 +1,279 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"claudefu/internal/types"
+)
+
+// =============================================================================
+// BYTE-OFFSET INDEX
+// For each session the watcher records where every message's JSONL line starts
+// (plus its timestamp and type), so paging and rewinding can seek straight to a
+// message instead of scanning a multi-hundred-MB file. Indexes are persisted at
+// {configPath}/local/index/{sessionID}.jsonl as entry lines; each appended
+// batch ends with a {"size":N} line recording how much of the session file is
+// covered. Offsets only mean something for this machine's session files, so the
+// index lives under local/ (neither synced nor backed up).
+// =============================================================================
+
+// IndexEntry locates one message in a session file.
+type IndexEntry struct {
+	UUID      string `json:"uuid,omitempty"`
+	Offset    int64  `json:"offset,omitempty"` // Byte offset of the message's line
+	Timestamp string `json:"timestamp,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Size      int64  `json:"size,omitempty"` // Set only on checkpoint lines
+}
+
+// sessionIndex is the loaded index of one session.
+type sessionIndex struct {
+	size    int64 // Bytes of the session file covered
+	entries []IndexEntry
+	byUUID  map[string]int // UUID -> first entry index
+}
+
+// OffsetIndex maintains the per-session byte-offset indexes. Safe for concurrent use.
+type OffsetIndex struct {
+	dir      string // ~/.claudefu/local/index
+	sessions map[string]*sessionIndex
+	mu       sync.Mutex
+}
+
+// NewOffsetIndex creates an index store under configPath, dropping the index
+// kept in the synced tree by earlier versions (it is rebuilt on demand).
+func NewOffsetIndex(configPath string) *OffsetIndex {
+	os.RemoveAll(filepath.Join(configPath, "index"))
+	return &OffsetIndex{
+		dir:      filepath.Join(configPath, "local", "index"),
+		sessions: make(map[string]*sessionIndex),
+	}
+}
+
+// Reset replaces a session's index after a full read of its file. Nothing is
+// written if the persisted index already covers the same bytes and messages.
+func (x *OffsetIndex) Reset(sessionID string, entries []IndexEntry, size int64) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if idx := x.get(sessionID); idx != nil && idx.size == size && len(idx.entries) == len(entries) {
+		return nil
+	}
+	idx := newSessionIndex(entries, size)
+	x.sessions[sessionID] = idx
+	if size == 0 {
+		os.Remove(x.path(sessionID))
+		return nil
+	}
+	return x.write(sessionID, idx.entries, size, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+}
+
+// Append adds the messages read from bytes [from, size) of a session file. It
+// is ignored unless the index covers exactly the bytes before from; the next
+// full read (Reset) repairs it.
+func (x *OffsetIndex) Append(sessionID string, from int64, entries []IndexEntry, size int64) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	idx := x.get(sessionID)
+	if idx == nil || idx.size != from || size <= from {
+		return nil
+	}
+	for _, e := range entries {
+		if _, seen := idx.byUUID[e.UUID]; !seen && e.UUID != "" {
+			idx.byUUID[e.UUID] = len(idx.entries)
+		}
+		idx.entries = append(idx.entries, e)
+	}
+	idx.size = size
+	return x.write(sessionID, entries, size, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+}
+
+// Remove deletes a session's index.
+func (x *OffsetIndex) Remove(sessionID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.sessions, sessionID)
+	os.Remove(x.path(sessionID))
+}
+
+// Offset returns the byte offset of a message's line.
+func (x *OffsetIndex) Offset(sessionID, uuid string) (int64, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	idx := x.get(sessionID)
+	if idx == nil {
+		return 0, false
+	}
+	i, ok := idx.byUUID[uuid]
+	if !ok {
+		return 0, false
+	}
+	return idx.entries[i].Offset, true
+}
+
+// PageStart returns where a page of limit display messages ending at end
+// starts, and how many display messages precede it. Tool result carriers don't
+// count toward the limit. ok is false if the session isn't indexed up to end.
+func (x *OffsetIndex) PageStart(sessionID string, end int64, limit int) (start int64, older int, ok bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	idx := x.get(sessionID)
+	if idx == nil || end > idx.size {
+		return 0, 0, false
+	}
+	n := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].Offset >= end })
+	i, count := n, 0
+	for i > 0 && count < limit {
+		i--
+		if idx.entries[i].Type != "tool_result_carrier" {
+			count++
+		}
+	}
+	for _, e := range idx.entries[:i] {
+		if e.Type != "tool_result_carrier" {
+			older++
+		}
+	}
+	if i < len(idx.entries) {
+		start = idx.entries[i].Offset
+	} else {
+		start = end
+	}
+	if i == 0 {
+		start = 0 // Keep any leading non-message lines with the first page
+	}
+	return start, older, true
+}
+
+// SetOffsetIndex sets where session byte offsets are recorded.
+func (fw *FileWatcher) SetOffsetIndex(ix *OffsetIndex) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.offsetIndex = ix
+}
+
+// MessageOffset returns the byte offset of a message's line in its session file.
+func (fw *FileWatcher) MessageOffset(sessionID, uuid string) (int64, bool) {
+	if ix := fw.getOffsetIndex(); ix != nil {
+		return ix.Offset(sessionID, uuid)
+	}
+	return 0, false
+}
+
+// PageStart returns where a page of limit display messages ending at byte end
+// starts in a session file (see OffsetIndex.PageStart).
+func (fw *FileWatcher) PageStart(sessionID string, end int64, limit int) (int64, int, bool) {
+	if ix := fw.getOffsetIndex(); ix != nil {
+		return ix.PageStart(sessionID, end, limit)
+	}
+	return 0, 0, false
+}
+
+func (fw *FileWatcher) getOffsetIndex() *OffsetIndex {
+	fw.mu.RLock()
+	defer fw.mu.RUnlock()
+	return fw.offsetIndex
+}
+
+func newIndexEntry(msg *types.Message, offset int64) IndexEntry {
+	return IndexEntry{UUID: msg.UUID, Offset: offset, Timestamp: msg.Timestamp, Type: msg.Type}
+}
+
+func newSessionIndex(entries []IndexEntry, size int64) *sessionIndex {
+	idx := &sessionIndex{size: size, entries: entries, byUUID: make(map[string]int, len(entries))}
+	for i, e := range entries {
+		if _, seen := idx.byUUID[e.UUID]; !seen && e.UUID != "" {
+			idx.byUUID[e.UUID] = i
+		}
+	}
+	return idx
+}
+
+func (x *OffsetIndex) path(sessionID string) string {
+	return filepath.Join(x.dir, filepath.Base(sessionID)+".jsonl")
+}
+
+// get returns a session's index, loading it from disk on first use (nil if
+// there is none). Caller must hold x.mu.
+func (x *OffsetIndex) get(sessionID string) *sessionIndex {
+	if idx, ok := x.sessions[sessionID]; ok {
+		return idx
+	}
+	idx, err := x.load(sessionID)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Discarding offset index for %s: %v", sessionID, err)
+		}
+		return nil
+	}
+	x.sessions[sessionID] = idx
+	return idx
+}
+
+// load reads a persisted index. Entries after the last checkpoint (an
+// interrupted append) are dropped. Caller must hold x.mu.
+func (x *OffsetIndex) load(sessionID string) (*sessionIndex, error) {
+	file, err := os.Open(x.path(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []IndexEntry
+	var size int64
+	committed := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e IndexEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			break
+		}
+		if e.Size > 0 {
+			size = e.Size
+			committed = len(entries)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newSessionIndex(entries[:committed], size), nil
+}
+
+// write persists entries followed by a size checkpoint. Caller must hold x.mu.
+func (x *OffsetIndex) write(sessionID string, entries []IndexEntry, size int64, flag int) error {
+	if err := os.MkdirAll(x.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	file, err := os.OpenFile(x.path(sessionID), flag, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write offset index: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write offset index: %w", err)
+		}
+	}
+	if err := enc.Encode(IndexEntry{Size: size}); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write offset index: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write offset index: %w", err)
+	}
+	return file.Close()
+}
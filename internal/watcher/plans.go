@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claudefu/internal/claudehome"
+)
+
+// =============================================================================
+// PLAN FILE WATCHING
+// Plan mode writes ~/.claude/plans/{slug}.md, where the slug comes from the
+// session's messages. The plans directory is watched (rather than single files)
+// because the slug of a new session is only known once Claude has written to
+// it, and the Write tool may replace the file instead of appending. Changes to
+// the plan of an agent's active session are emitted as plan:updated.
+// =============================================================================
+
+// SetPlanWatching turns plan:updated events on or off.
+func (fw *FileWatcher) SetPlanWatching(enabled bool) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if !enabled {
+		if fw.plansDir != "" {
+			fw.watcher.Remove(fw.plansDir)
+			fw.plansDir = ""
+		}
+		return nil
+	}
+	if fw.plansDir != "" {
+		return nil
+	}
+
+	dir := claudehome.PlansDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create plans directory: %w", err)
+	}
+	if err := fw.watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch plans directory: %w", err)
+	}
+	fw.plansDir = dir
+	return nil
+}
+
+// isPlanFile reports whether path is a plan in the watched plans directory.
+func (fw *FileWatcher) isPlanFile(path string) bool {
+	fw.mu.RLock()
+	defer fw.mu.RUnlock()
+	return fw.plansDir != "" && strings.HasSuffix(path, ".md") && filepath.Dir(path) == fw.plansDir
+}
+
+// handlePlanChange emits plan:updated for every active session whose slug
+// matches the changed plan file.
+func (fw *FileWatcher) handlePlanChange(path string) {
+	slug := strings.TrimSuffix(filepath.Base(path), ".md")
+
+	fw.mu.RLock()
+	rt := fw.runtime
+	sessions := make(map[string]string, len(fw.agentSessionPaths)) // agentID -> sessionID
+	for agentID, sessionPath := range fw.agentSessionPaths {
+		sessions[agentID] = strings.TrimSuffix(filepath.Base(sessionPath), ".jsonl")
+	}
+	fw.mu.RUnlock()
+
+	if rt == nil {
+		return
+	}
+
+	var content []byte
+	for agentID, sessionID := range sessions {
+		if rt.GetSessionSlug(agentID, sessionID) != slug {
+			continue
+		}
+		if content == nil {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				logger.Debugf("handlePlanChange: failed to read %s: %v", path, err)
+				return
+			}
+			content = data
+		}
+		rt.Emit("plan:updated", agentID, sessionID, map[string]any{
+			"agentId":   agentID,
+			"sessionId": sessionID,
+			"planPath":  path,
+			"content":   string(content),
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often polled session directories are stat-scanned.
+const DefaultPollInterval = 2 * time.Second
+
+// minPollInterval keeps a misconfigured interval from hammering a network share.
+const minPollInterval = 250 * time.Millisecond
+
+// fileStamp is the part of a session file's metadata compared between polls.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// =============================================================================
+// POLL MODE
+// fsnotify is unreliable on NFS/SMB mounts (writes from other hosts produce no
+// inotify events, and adding a watch can fail outright). Folders in poll mode
+// get no fsnotify watches; instead their sessions directory is listed on a
+// timer and size/mtime changes are fed through the same handlers.
+// =============================================================================
+
+// SetPollInterval sets how often polled folders are scanned (<= 0 = default).
+func (fw *FileWatcher) SetPollInterval(d time.Duration) {
+	if d <= 0 {
+		d = DefaultPollInterval
+	}
+	d = max(d, minPollInterval)
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.pollInterval = d
+}
+
+// SetFolderPolling switches a folder between fsnotify and stat polling.
+// Call before StartWatchingAgent so no fsnotify watch is attempted. Agents
+// sharing a folder share its mode; the last call wins. Disabling polling on a
+// folder that fell back to it (watch limit) retries the fsnotify watches.
+func (fw *FileWatcher) SetFolderPolling(folder string, enabled bool) {
+	sessionsDir := GetSessionsDir(folder)
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	_, polling := fw.polledDirs[sessionsDir]
+	_, degraded := fw.degradedDirs[sessionsDir]
+	if enabled && degraded {
+		// Already polling; now it is the configured mode rather than a fallback
+		delete(fw.degradedDirs, sessionsDir)
+		return
+	}
+	if enabled == polling && !degraded {
+		return
+	}
+
+	if enabled {
+		// Drop fsnotify watches; the poller takes over from the current state
+		if fw.watchedDirs[sessionsDir] {
+			fw.watcher.Remove(sessionsDir)
+			delete(fw.watchedDirs, sessionsDir)
+		}
+		for path := range fw.watchedFiles {
+			if filepath.Dir(path) == sessionsDir {
+				fw.watcher.Remove(path)
+				delete(fw.watchedFiles, path)
+			}
+		}
+		fw.polledDirs[sessionsDir] = scanSessionsDir(sessionsDir)
+		logger.Debugf("SetFolderPolling: polling %s", sessionsDir)
+		return
+	}
+
+	// Leaving poll mode (or retrying after a watch-limit fallback): a watch
+	// that still fails on the limit puts the folder back in degraded polling
+	delete(fw.polledDirs, sessionsDir)
+	delete(fw.degradedDirs, sessionsDir)
+	if _, err := os.Stat(sessionsDir); err == nil && len(fw.folderToAgentIDs[folder]) > 0 {
+		if err := fw.addWatchLocked(sessionsDir); err == nil {
+			fw.watchedDirs[sessionsDir] = true
+		} else {
+			logger.Warnf("SetFolderPolling: failed to watch %s: %v", sessionsDir, err)
+		}
+	}
+	for _, path := range fw.agentSessionPaths {
+		if filepath.Dir(path) == sessionsDir && !fw.watchedFiles[path] && !fw.isPolledDir(sessionsDir) {
+			if err := fw.addWatchLocked(path); err == nil {
+				fw.watchedFiles[path] = true
+			}
+		}
+	}
+	logger.Debugf("SetFolderPolling: watching %s via fsnotify", sessionsDir)
+}
+
+// isPolledDir reports whether a sessions directory is in poll mode. Caller must hold fw.mu.
+func (fw *FileWatcher) isPolledDir(sessionsDir string) bool {
+	_, ok := fw.polledDirs[sessionsDir]
+	return ok
+}
+
+// poll scans polled directories until the watcher is closed.
+func (fw *FileWatcher) poll() {
+	for {
+		fw.mu.RLock()
+		interval := fw.pollInterval
+		fw.mu.RUnlock()
+
+		select {
+		case <-fw.ctx.Done():
+			return
+		case <-time.After(interval):
+			fw.pollOnce()
+		}
+	}
+}
+
+// pollOnce lists each polled sessions directory and dispatches creates,
+// changes, and removals found since the previous scan.
+func (fw *FileWatcher) pollOnce() {
+	fw.mu.RLock()
+	dirs := make([]string, 0, len(fw.polledDirs))
+	for dir := range fw.polledDirs {
+		dirs = append(dirs, dir)
+	}
+	fw.mu.RUnlock()
+
+	for _, dir := range dirs {
+		current := scanSessionsDir(dir)
+		if current == nil {
+			continue // Transient read error: keep the previous scan
+		}
+
+		fw.mu.Lock()
+		previous, ok := fw.polledDirs[dir]
+		if ok {
+			fw.polledDirs[dir] = current
+		}
+		fw.mu.Unlock()
+		if !ok {
+			continue // Polling disabled meanwhile
+		}
+
+		for name, stamp := range current {
+			path := filepath.Join(dir, name)
+			old, existed := previous[name]
+			switch {
+			case !existed:
+				fw.handleFileCreate(path)
+			case stamp != old:
+				fw.handleFileChange(path)
+			}
+		}
+		for name := range previous {
+			if _, exists := current[name]; !exists {
+				fw.handleFileRemove(filepath.Join(dir, name))
+			}
+		}
+	}
+}
+
+// scanSessionsDir returns the .jsonl files in dir with their size and mtime.
+// A missing directory scans as empty; any other error returns nil.
+func scanSessionsDir(dir string) map[string]fileStamp {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]fileStamp{}
+	}
+	if err != nil {
+		logger.Debugf("scanSessionsDir: %s: %v", dir, err)
+		return nil
+	}
+	result := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		result[entry.Name()] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package watcher
+
+import (
+	"errors"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch modes reported by FolderWatchStatus.
+const (
+	WatchModeFsnotify = "fsnotify" // Live fsnotify watches
+	WatchModePoll     = "poll"     // Polling, configured by the agent's watch mode
+	WatchModeDegraded = "degraded" // Polling because a watch could not be added (OS limit)
+)
+
+// =============================================================================
+// WATCH-LIMIT RESILIENCE
+// Linux caps inotify watches per user (fs.inotify.max_user_watches) and
+// instances (max_user_instances); with many projects open the cap is hit and
+// watcher.Add fails with ENOSPC/EMFILE. Instead of silently losing live
+// updates, the affected sessions directory falls back to polling (see
+// poller.go) and the failure is reported through the watch error hook. Errors
+// from fsnotify's error channel (e.g. queue overflow) are reported the same way.
+// =============================================================================
+
+// WatchError is a watcher failure reported to the watch error hook.
+type WatchError struct {
+	Folder   string `json:"folder,omitempty"` // Agent folder affected ("" = watcher-wide)
+	Error    string `json:"error"`
+	Degraded bool   `json:"degraded"` // The folder fell back to polling
+}
+
+// WatchStatus is how a folder's sessions directory is being watched.
+type WatchStatus struct {
+	Folder string    `json:"folder"`
+	Mode   string    `json:"mode"`             // WatchModeFsnotify, WatchModePoll or WatchModeDegraded
+	Reason string    `json:"reason,omitempty"` // Why the folder is degraded
+	Since  time.Time `json:"since,omitempty"`  // When it degraded
+}
+
+// degradation records why a sessions directory fell back to polling.
+type degradation struct {
+	reason string
+	since  time.Time
+}
+
+// IsWatchLimitError reports whether err means the OS watch or descriptor limit was hit.
+func IsWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
+}
+
+// SetWatchErrorHook sets a function called (on its own goroutine) when a watch
+// fails or a folder falls back to polling.
+func (fw *FileWatcher) SetWatchErrorHook(hook func(WatchError)) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.onWatchError = hook
+}
+
+// FolderWatchStatus reports how folder's sessions directory is watched.
+func (fw *FileWatcher) FolderWatchStatus(folder string) WatchStatus {
+	sessionsDir := GetSessionsDir(folder)
+	fw.mu.RLock()
+	defer fw.mu.RUnlock()
+
+	status := WatchStatus{Folder: folder, Mode: WatchModeFsnotify}
+	if d, ok := fw.degradedDirs[sessionsDir]; ok {
+		status.Mode, status.Reason, status.Since = WatchModeDegraded, d.reason, d.since
+	} else if fw.isPolledDir(sessionsDir) {
+		status.Mode = WatchModePoll
+	}
+	return status
+}
+
+// addWatchLocked adds an fsnotify watch on path (a sessions directory or a
+// session file in one). If the OS watch limit is hit, the sessions directory
+// falls back to polling and the error is still returned: callers check
+// isPolledDir to tell the two apart. Caller must hold fw.mu.
+func (fw *FileWatcher) addWatchLocked(path string) error {
+	err := fw.watcher.Add(path)
+	if err == nil || !IsWatchLimitError(err) {
+		return err
+	}
+	sessionsDir := path
+	if filepath.Ext(path) == ".jsonl" {
+		sessionsDir = filepath.Dir(path)
+	}
+	fw.degradeLocked(sessionsDir, err)
+	return err
+}
+
+// degradeLocked switches sessionsDir to polling after err. Caller must hold fw.mu.
+func (fw *FileWatcher) degradeLocked(sessionsDir string, err error) {
+	if _, already := fw.degradedDirs[sessionsDir]; already {
+		return
+	}
+	if !fw.isPolledDir(sessionsDir) {
+		if fw.watchedDirs[sessionsDir] {
+			fw.watcher.Remove(sessionsDir)
+			delete(fw.watchedDirs, sessionsDir)
+		}
+		for path := range fw.watchedFiles {
+			if filepath.Dir(path) == sessionsDir {
+				fw.watcher.Remove(path)
+				delete(fw.watchedFiles, path)
+			}
+		}
+		fw.polledDirs[sessionsDir] = scanSessionsDir(sessionsDir)
+	}
+	fw.degradedDirs[sessionsDir] = degradation{reason: err.Error(), since: time.Now()}
+	logger.Warnf("Watch limit reached (%v): polling %s instead", err, sessionsDir)
+
+	folder := ""
+	for f := range fw.folderToAgentIDs {
+		if GetSessionsDir(f) == sessionsDir {
+			folder = f
+			break
+		}
+	}
+	fw.reportWatchErrorLocked(WatchError{Folder: folder, Error: err.Error(), Degraded: true})
+}
+
+// handleWatchError handles an error from fsnotify's error channel. A queue
+// overflow means events were dropped, so every watched session file is re-read.
+func (fw *FileWatcher) handleWatchError(err error) {
+	logger.Warnf("File watcher error: %v", err)
+
+	fw.mu.Lock()
+	var resync []string
+	if errors.Is(err, fsnotify.ErrEventOverflow) {
+		for path := range fw.watchedFiles {
+			resync = append(resync, path)
+		}
+	}
+	fw.reportWatchErrorLocked(WatchError{Error: err.Error()})
+	fw.mu.Unlock()
+
+	for _, path := range resync {
+		fw.handleFileChange(path)
+	}
+}
+
+// reportWatchErrorLocked calls the watch error hook. Caller must hold fw.mu.
+func (fw *FileWatcher) reportWatchErrorLocked(watchErr WatchError) {
+	if hook := fw.onWatchError; hook != nil {
+		go hook(watchErr)
+	}
+}
@@ -9,17 +9,22 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 
+	"claudefu/internal/claudehome"
+	"claudefu/internal/logging"
+	"claudefu/internal/metrics"
 	"claudefu/internal/runtime"
 	"claudefu/internal/types"
 )
 
+// logger is the watcher subsystem logger (see internal/logging).
+var logger = logging.For(logging.Watcher)
+
 // =============================================================================
 // FILE WATCHER - Monitors JSONL Files
 // =============================================================================
@@ -36,6 +41,15 @@ type FileWatcher struct {
 	agentSessionPaths  map[string]string             // agentID -> watched session file path (one per agent)
 	subagentWatchers   map[string]*SubagentWatcher  // agentID -> subagent watcher (one per active session)
 	pendingChanges     map[string]*time.Timer       // path -> debounce timer (batches rapid writes during streaming)
+	onSessionChange    func(folder, sessionID string) // Optional hook for session file writes/creates (e.g., search indexing)
+	onSessionRemove    func(folder, sessionID string) // Optional hook for session files deleted outside ClaudeFu
+	polledDirs         map[string]map[string]fileStamp // sessions dir -> last scan (poll mode folders only)
+	degradedDirs       map[string]degradation          // sessions dir -> why it fell back to polling (watch limit)
+	onWatchError       func(WatchError)                // Optional hook for watch failures (see resilience.go)
+	pollInterval       time.Duration
+	plansDir           string // Watched plans directory ("" = plan watching off)
+	loadFullHistory    bool   // Load messages from before the last compaction
+	offsetIndex        *OffsetIndex // Persisted message byte offsets (nil = not kept)
 	mu                 sync.RWMutex
 	ctx               context.Context
 	cancel            context.CancelFunc
@@ -58,11 +72,15 @@ func NewFileWatcher() (*FileWatcher, error) {
 		agentSessionPaths:  make(map[string]string),
 		subagentWatchers:   make(map[string]*SubagentWatcher),
 		pendingChanges:     make(map[string]*time.Timer),
+		polledDirs:         make(map[string]map[string]fileStamp),
+		degradedDirs:       make(map[string]degradation),
+		pollInterval:       DefaultPollInterval,
 		ctx:               ctx,
 		cancel:            cancel,
 	}
 
 	go fw.run()
+	go fw.poll()
 	return fw, nil
 }
 
@@ -76,6 +94,22 @@ func (fw *FileWatcher) SetRuntime(rt *runtime.WorkspaceRuntime) {
 	}
 }
 
+// SetSessionChangeHook sets a function called (on the watcher goroutine) after a
+// session file in a watched folder is created or written. Keep it cheap.
+func (fw *FileWatcher) SetSessionChangeHook(hook func(folder, sessionID string)) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.onSessionChange = hook
+}
+
+// SetSessionRemoveHook sets a function called (on the watcher goroutine) after a
+// session file in a watched folder is deleted or moved away.
+func (fw *FileWatcher) SetSessionRemoveHook(hook func(folder, sessionID string)) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.onSessionRemove = hook
+}
+
 // SetWorkspaceContext sets the workspace context for event routing.
 // This maps folder paths to agent IDs for proper event routing.
 // NOTE: Multiple agents can share the same folder, each watching a different session.
@@ -115,7 +149,7 @@ func (fw *FileWatcher) SetActiveSessionWatch(agentID, sessionID string) {
 		}
 	}
 	if folder == "" {
-		fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s not found in folderToAgentIDs\n", agentID[:8])
+		logger.Debugf("SetActiveSessionWatch: agent %s not found in folderToAgentIDs", agentID[:8])
 		return
 	}
 
@@ -123,7 +157,7 @@ func (fw *FileWatcher) SetActiveSessionWatch(agentID, sessionID string) {
 
 	// Skip if this agent is already watching this exact file
 	if fw.agentSessionPaths[agentID] == newPath {
-		fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s already watching %s\n", agentID[:8], sessionID[:8])
+		logger.Debugf("SetActiveSessionWatch: agent %s already watching %s", agentID[:8], sessionID[:8])
 		return
 	}
 
@@ -131,20 +165,28 @@ func (fw *FileWatcher) SetActiveSessionWatch(agentID, sessionID string) {
 	if oldPath, exists := fw.agentSessionPaths[agentID]; exists {
 		fw.watcher.Remove(oldPath)
 		delete(fw.watchedFiles, oldPath)
-		fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s unwatched previous session\n", agentID[:8])
+		logger.Debugf("SetActiveSessionWatch: agent %s unwatched previous session", agentID[:8])
 	}
 
-	// Watch new session file for this agent
-	if err := fw.watcher.Add(newPath); err == nil {
+	// Watch new session file for this agent (polled folders are picked up by the poller)
+	if fw.isPolledDir(filepath.Dir(newPath)) {
+		fw.agentSessionPaths[agentID] = newPath
+		logger.Debugf("SetActiveSessionWatch: agent %s now polling session=%s", agentID[:8], sessionID[:8])
+		go fw.handleFileChange(newPath)
+	} else if err := fw.addWatchLocked(newPath); err == nil {
 		fw.watchedFiles[newPath] = true
 		fw.agentSessionPaths[agentID] = newPath
-		fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s now watching session=%s\n", agentID[:8], sessionID[:8])
+		logger.Debugf("SetActiveSessionWatch: agent %s now watching session=%s", agentID[:8], sessionID[:8])
 
 		// Force an immediate delta read to pick up any messages written while
 		// the file was unwatched (e.g., session was just selected after being idle).
 		go fw.handleFileChange(newPath)
+	} else if fw.isPolledDir(filepath.Dir(newPath)) {
+		// Watch limit reached: the folder fell back to polling
+		fw.agentSessionPaths[agentID] = newPath
+		go fw.handleFileChange(newPath)
 	} else {
-		fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s failed to watch %s: %v\n", agentID[:8], newPath, err)
+		logger.Debugf("SetActiveSessionWatch: agent %s failed to watch %s: %v", agentID[:8], newPath, err)
 	}
 
 	// Stop previous subagent watcher for this agent (if any)
@@ -157,11 +199,17 @@ func (fw *FileWatcher) SetActiveSessionWatch(agentID, sessionID string) {
 	if fw.runtime != nil {
 		sw, err := NewSubagentWatcher(fw.runtime, agentID, sessionID, folder)
 		if err == nil {
-			if err := sw.Start(); err == nil {
+			if err = sw.Start(); err == nil {
 				fw.subagentWatchers[agentID] = sw
-				fmt.Printf("[DEBUG] SetActiveSessionWatch: agent %s started subagent watcher for session=%s\n", agentID[:8], sessionID[:8])
+				logger.Debugf("SetActiveSessionWatch: agent %s started subagent watcher for session=%s", agentID[:8], sessionID[:8])
+			} else {
+				sw.Stop() // Release its inotify instance
 			}
 		}
+		if err != nil && IsWatchLimitError(err) {
+			// Each subagent watcher is its own inotify instance (max_user_instances)
+			fw.reportWatchErrorLocked(WatchError{Folder: folder, Error: "subagent watcher: " + err.Error()})
+		}
 	}
 }
 
@@ -174,7 +222,7 @@ func (fw *FileWatcher) ClearActiveSessionWatch(agentID string) {
 		fw.watcher.Remove(oldPath)
 		delete(fw.watchedFiles, oldPath)
 		delete(fw.agentSessionPaths, agentID)
-		fmt.Printf("[DEBUG] ClearActiveSessionWatch: agent %s unwatched %s\n", agentID[:8], oldPath)
+		logger.Debugf("ClearActiveSessionWatch: agent %s unwatched %s", agentID[:8], oldPath)
 	}
 
 	// Stop subagent watcher for this agent
@@ -199,15 +247,20 @@ func (fw *FileWatcher) run() {
 				return
 			}
 			if event.Has(fsnotify.Write) {
+				metrics.WatcherEvent("write")
 				fw.debounceFileChange(event.Name)
 			} else if event.Has(fsnotify.Create) {
+				metrics.WatcherEvent("create")
 				fw.handleFileCreate(event.Name)
+			} else if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				metrics.WatcherEvent("remove")
+				fw.handleFileRemove(event.Name)
 			}
-		case _, ok := <-fw.watcher.Errors:
+		case err, ok := <-fw.watcher.Errors:
 			if !ok {
 				return
 			}
-			// Log error but continue
+			fw.handleWatchError(err)
 		}
 	}
 }
@@ -244,6 +297,11 @@ func (fw *FileWatcher) debounceFileChange(path string) {
 
 // handleFileChange reads new content from a changed file.
 func (fw *FileWatcher) handleFileChange(path string) {
+	if fw.isPlanFile(path) {
+		fw.handlePlanChange(path)
+		return
+	}
+
 	// Only process .jsonl files
 	if !strings.HasSuffix(path, ".jsonl") {
 		return
@@ -269,6 +327,7 @@ func (fw *FileWatcher) handleFileChange(path string) {
 	if folder == "" || sessionID == "" {
 		return
 	}
+	fw.notifySessionChange(folder, sessionID)
 
 	// Get all agent IDs for this folder (multiple agents can share a folder)
 	fw.mu.RLock()
@@ -293,7 +352,7 @@ func (fw *FileWatcher) handleFileChange(path string) {
 
 	// Skip delta reads until initial load is complete (prevents race condition)
 	if !rt.IsInitialLoadDone(agentID, sessionID) {
-		fmt.Printf("[DEBUG] handleFileChange: skipping - initial load not done for session=%s\n", sessionID[:8])
+		logger.Debugf("handleFileChange: skipping - initial load not done for session=%s", sessionID[:8])
 		return
 	}
 
@@ -307,18 +366,19 @@ func (fw *FileWatcher) handleFileChange(path string) {
 	// Detailed position tracking for debugging
 	oldPosition := session.FilePosition
 	delta := currentSize - oldPosition
-	fmt.Printf("[DEBUG] handleFileChange: session=%s filePos=%d fileSize=%d delta=%d bytes\n",
+	logger.Debugf("handleFileChange: session=%s filePos=%d fileSize=%d delta=%d bytes",
 		sessionID[:8], oldPosition, currentSize, delta)
 
 	// WARN if delta is suspiciously large (>100KB - could be image/document upload or position reset)
 	if delta > 100*1024 {
-		fmt.Printf("[WARN] handleFileChange: LARGE DELTA detected! session=%s delta=%d bytes (possible image/document upload)\n",
+		logger.Warnf("handleFileChange: LARGE DELTA detected! session=%s delta=%d bytes (possible image/document upload)",
 			sessionID[:8], delta)
 	}
 
 	// Read new messages from file (limit to currentSize to avoid reading content still being written)
-	newMessages := fw.readNewMessagesLimited(path, oldPosition, currentSize)
-	fmt.Printf("[DEBUG] handleFileChange: read %d messages from pos=%d (limited to %d)\n", len(newMessages), oldPosition, currentSize)
+	newMessages, offsets := fw.readNewMessagesLimited(path, oldPosition, currentSize)
+	rt.AddMessageOffsets(agentID, sessionID, offsets)
+	logger.Debugf("handleFileChange: read %d messages from pos=%d (limited to %d)", len(newMessages), oldPosition, currentSize)
 	if len(newMessages) == 0 {
 		return
 	}
@@ -347,7 +407,7 @@ func (fw *FileWatcher) handleFileChange(path string) {
 				skippedOld++
 			}
 		}
-		fmt.Printf("[DEBUG] handleFileChange: timestamp filter cutoff=%v, kept=%d, skippedOld=%d\n",
+		logger.Debugf("handleFileChange: timestamp filter cutoff=%v, kept=%d, skippedOld=%d",
 			cutoffTime.Format(time.RFC3339), len(filteredMessages), skippedOld)
 		newMessages = filteredMessages
 
@@ -361,7 +421,7 @@ func (fw *FileWatcher) handleFileChange(path string) {
 
 	// Update file position to what we actually read up to (currentSize), NOT current EOF
 	// This ensures we don't skip content if Claude wrote more while we were processing
-	fmt.Printf("[DEBUG] handleFileChange: updating file position from %d to %d (delta: %d bytes)\n", oldPosition, currentSize, currentSize-oldPosition)
+	logger.Debugf("handleFileChange: updating file position from %d to %d (delta: %d bytes)", oldPosition, currentSize, currentSize-oldPosition)
 	rt.SetFilePosition(agentID, sessionID, currentSize)
 
 	// Append messages to runtime (returns only the actually added messages after deduplication)
@@ -369,7 +429,7 @@ func (fw *FileWatcher) handleFileChange(path string) {
 
 	// If nothing was actually added (all duplicates), skip emission
 	if len(addedMessages) == 0 {
-		fmt.Printf("[DEBUG] handleFileChange: all %d messages were duplicates, skipping emission\n", len(newMessages))
+		logger.Debugf("handleFileChange: all %d messages were duplicates, skipping emission", len(newMessages))
 		return
 	}
 
@@ -377,12 +437,17 @@ func (fw *FileWatcher) handleFileChange(path string) {
 	rt.EmitUnreadChanged(agentID, sessionID)
 
 	// Emit session:messages (we already filtered to only active session above)
-	fmt.Printf("[DEBUG] handleFileChange: emitting session:messages for %d messages\n", len(addedMessages))
+	logger.Debugf("handleFileChange: emitting session:messages for %d messages", len(addedMessages))
 	rt.EmitSessionMessages(agentID, sessionID, addedMessages)
 }
 
 // handleFileCreate handles new file creation (new sessions).
 func (fw *FileWatcher) handleFileCreate(path string) {
+	if fw.isPlanFile(path) {
+		fw.handlePlanChange(path)
+		return
+	}
+
 	// Only process .jsonl files
 	if !strings.HasSuffix(path, ".jsonl") {
 		return
@@ -408,6 +473,8 @@ func (fw *FileWatcher) handleFileCreate(path string) {
 	if folder == "" || sessionID == "" {
 		return
 	}
+	fw.rearmSessionWatch(path)
+	fw.notifySessionChange(folder, sessionID)
 
 	// Get all agent IDs for this folder (multiple agents can share a folder)
 	fw.mu.RLock()
@@ -422,7 +489,7 @@ func (fw *FileWatcher) handleFileCreate(path string) {
 	// selects a session. Each agent has 100+ historical sessions; we only watch one per agent.
 
 	// Load initial messages (file may already have content if created externally)
-	messages, filePos := fw.loadInitialMessages(path)
+	messages, filePos, historyEnd, offsets := fw.loadInitialMessages(path)
 
 	// Check if this is a real session (has user/assistant messages)
 	hasRealMessages := false
@@ -433,12 +500,12 @@ func (fw *FileWatcher) handleFileCreate(path string) {
 		}
 	}
 
-	fmt.Printf("[DEBUG] handleFileCreate: session=%s messages=%d hasRealMessages=%v filePos=%d\n",
+	logger.Debugf("handleFileCreate: session=%s messages=%d hasRealMessages=%v filePos=%d",
 		sessionID[:8], len(messages), hasRealMessages, filePos)
 
 	// Skip summary-only sessions (no actual user/assistant messages)
 	if !hasRealMessages && len(messages) > 0 {
-		fmt.Printf("[DEBUG] handleFileCreate: Skipping summary-only session: %s\n", sessionID)
+		logger.Debugf("handleFileCreate: Skipping summary-only session: %s", sessionID)
 		return
 	}
 
@@ -453,30 +520,114 @@ func (fw *FileWatcher) handleFileCreate(path string) {
 		// Add loaded messages to session (if any)
 		if len(messages) > 0 {
 			rt.AppendMessages(agentID, sessionID, messages)
-			fmt.Printf("[DEBUG] handleFileCreate: loaded %d messages for agent=%s session=%s\n",
+			logger.Debugf("handleFileCreate: loaded %d messages for agent=%s session=%s",
 				len(messages), agentID[:8], sessionID[:8])
 		}
 
 		// Set file position for future delta reads
 		rt.SetFilePosition(agentID, sessionID, filePos)
+		rt.SetHistoryEnd(agentID, sessionID, historyEnd)
+		rt.AddMessageOffsets(agentID, sessionID, offsets)
 
 		// Mark initial load done - now delta reads can proceed
 		rt.MarkInitialLoadDone(agentID, sessionID)
 
 		// Emit session:discovered event
+		summary := rt.GetSessionSummary(agentID, sessionID)
+		if summary == nil {
+			continue
+		}
 		rt.Emit("session:discovered", agentID, sessionID, map[string]any{
 			"agentId": agentID,
-			"session": types.Session{
-				ID:           sessionID,
-				AgentID:      agentID,
-				MessageCount: len(messages),
-				CreatedAt:    session.CreatedAt,
-				UpdatedAt:    session.UpdatedAt,
-			},
+			"session": summary,
 		})
 	}
 }
 
+// handleFileRemove handles a session file being deleted or renamed away.
+// If the file is gone, the session is dropped from the runtime and session:removed
+// is emitted. If it still exists (replaced by an atomic rename), only the file
+// watch is re-armed, since inotify watches follow the old inode.
+func (fw *FileWatcher) handleFileRemove(path string) {
+	if !strings.HasSuffix(path, ".jsonl") || strings.HasPrefix(filepath.Base(path), "agent-") {
+		return
+	}
+
+	fw.mu.Lock()
+	if t, exists := fw.pendingChanges[path]; exists {
+		t.Stop()
+		delete(fw.pendingChanges, path)
+	}
+	delete(fw.watchedFiles, path)
+	rt := fw.runtime
+	hook := fw.onSessionRemove
+	fw.mu.Unlock()
+
+	if _, err := os.Stat(path); err == nil {
+		fw.rearmSessionWatch(path)
+		return
+	}
+	if rt == nil {
+		return
+	}
+
+	folder, sessionID := fw.parseSessionPath(path)
+	if folder == "" || sessionID == "" {
+		return
+	}
+	if ix := fw.getOffsetIndex(); ix != nil {
+		ix.Remove(sessionID)
+	}
+	fw.mu.RLock()
+	agentIDs := fw.folderToAgentIDs[folder]
+	fw.mu.RUnlock()
+
+	removed := false
+	for _, agentID := range agentIDs {
+		if rt.GetSessionState(agentID, sessionID) == nil {
+			continue // Already removed (e.g. deleted through ClaudeFu)
+		}
+		if activeAgent, activeSession := rt.GetActiveSession(); activeAgent == agentID && activeSession == sessionID {
+			rt.ClearActiveSession()
+		}
+		rt.RemoveSession(agentID, sessionID)
+		rt.Emit("session:removed", agentID, sessionID, map[string]any{
+			"sessionId": sessionID,
+			"external":  true,
+		})
+		removed = true
+	}
+	if removed {
+		logger.Infof("Session file removed externally: %s", path)
+		if hook != nil {
+			hook(folder, sessionID)
+		}
+	}
+}
+
+// rearmSessionWatch re-adds the file watch for path if it is some agent's
+// active session and the watch was lost (file removed, renamed, or replaced).
+func (fw *FileWatcher) rearmSessionWatch(path string) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.watchedFiles[path] || fw.isPolledDir(filepath.Dir(path)) {
+		return
+	}
+	for _, watched := range fw.agentSessionPaths {
+		if watched != path {
+			continue
+		}
+		if err := fw.addWatchLocked(path); err != nil {
+			logger.Debugf("rearmSessionWatch: failed to watch %s: %v", path, err)
+			return
+		}
+		fw.watchedFiles[path] = true
+		logger.Debugf("rearmSessionWatch: re-armed watch on %s", path)
+		return
+	}
+}
+
 // =============================================================================
 // AGENT WATCHING
 // =============================================================================
@@ -501,7 +652,7 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 
 	// Guard: skip session discovery if agent was already loaded
 	if alreadyLoaded {
-		fmt.Printf("[DEBUG] StartWatchingAgent: SKIPPING agent=%s (already loaded)\n", agentID[:8])
+		logger.Debugf("StartWatchingAgent: SKIPPING agent=%s (already loaded)", agentID[:8])
 		return nil
 	}
 
@@ -514,14 +665,15 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 		return nil
 	}
 
-	// Watch the sessions directory for new files
+	// Watch the sessions directory for new files (unless the poller covers it)
 	fw.mu.Lock()
-	if !fw.watchedDirs[sessionsDir] {
-		if err := fw.watcher.Add(sessionsDir); err != nil {
+	if !fw.watchedDirs[sessionsDir] && !fw.isPolledDir(sessionsDir) {
+		if err := fw.addWatchLocked(sessionsDir); err == nil {
+			fw.watchedDirs[sessionsDir] = true
+		} else if !fw.isPolledDir(sessionsDir) {
 			fw.mu.Unlock()
 			return err
 		}
-		fw.watchedDirs[sessionsDir] = true
 	}
 	fw.mu.Unlock()
 
@@ -546,7 +698,7 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 		filePath := filepath.Join(sessionsDir, entry.Name())
 
 		// Load initial messages first to check if this is a real session
-		messages, filePos := fw.loadInitialMessages(filePath)
+		messages, filePos, historyEnd, offsets := fw.loadInitialMessages(filePath)
 
 		// Skip summary-only sessions (no actual user/assistant messages)
 		hasRealMessages := false
@@ -557,7 +709,7 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 			}
 		}
 		if !hasRealMessages {
-			fmt.Printf("[DEBUG] Skipping summary-only session: %s\n", sessionID)
+			logger.Debugf("Skipping summary-only session: %s", sessionID)
 			continue
 		}
 
@@ -572,6 +724,8 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 			rt.AppendMessages(agentID, sessionID, messages)
 		}
 		rt.SetFilePosition(agentID, sessionID, filePos)
+		rt.SetHistoryEnd(agentID, sessionID, historyEnd)
+		rt.AddMessageOffsets(agentID, sessionID, offsets)
 
 		// Refresh UpdatedAt from file modification time (more accurate than message timestamps
 		// when the session has been updated externally while ClaudeFu wasn't watching)
@@ -600,7 +754,7 @@ func (fw *FileWatcher) StartWatchingAgent(agentID, folder string, lastViewedMap
 	fw.mu.Lock()
 	fw.loadedAgents[agentID] = true
 	fw.mu.Unlock()
-	fmt.Printf("[DEBUG] StartWatchingAgent: completed agent=%s\n", agentID[:8])
+	logger.Debugf("StartWatchingAgent: completed agent=%s", agentID[:8])
 
 	return nil
 }
@@ -670,7 +824,7 @@ func (fw *FileWatcher) RescanSessions(agentID, folder string, lastViewedMap map[
 		filePath := filepath.Join(sessionsDir, entry.Name())
 
 		// Load initial messages first to check if this is a real session
-		messages, filePos := fw.loadInitialMessages(filePath)
+		messages, filePos, historyEnd, offsets := fw.loadInitialMessages(filePath)
 
 		// Skip summary-only sessions (no actual user/assistant messages)
 		hasRealMessages := false
@@ -695,6 +849,8 @@ func (fw *FileWatcher) RescanSessions(agentID, folder string, lastViewedMap map[
 			rt.AppendMessages(agentID, sessionID, messages)
 		}
 		rt.SetFilePosition(agentID, sessionID, filePos)
+		rt.SetHistoryEnd(agentID, sessionID, historyEnd)
+		rt.AddMessageOffsets(agentID, sessionID, offsets)
 
 		// Initialize viewed state from persisted lastViewedAt
 		lastViewed := int64(0)
@@ -715,7 +871,7 @@ func (fw *FileWatcher) RescanSessions(agentID, folder string, lastViewedMap map[
 	}
 
 	if newCount > 0 || reloadedCount > 0 || refreshedCount > 0 {
-		fmt.Printf("[DEBUG] RescanSessions: agent=%s new=%d reloaded=%d refreshed=%d\n",
+		logger.Debugf("RescanSessions: agent=%s new=%d reloaded=%d refreshed=%d",
 			agentID[:8], newCount, reloadedCount, refreshedCount)
 	}
 
@@ -757,6 +913,8 @@ func (fw *FileWatcher) StopWatchingAgent(agentID, folder string) {
 				fw.watcher.Remove(sessionsDir)
 				delete(fw.watchedDirs, sessionsDir)
 			}
+			delete(fw.polledDirs, sessionsDir)
+			delete(fw.degradedDirs, sessionsDir)
 
 			// Unwatch all session files in this directory
 			for filePath := range fw.watchedFiles {
@@ -771,6 +929,12 @@ func (fw *FileWatcher) StopWatchingAgent(agentID, folder string) {
 	}
 }
 
+// WatchCount returns the number of paths held by the underlying fsnotify
+// watcher (subagent watchers not included).
+func (fw *FileWatcher) WatchCount() int {
+	return len(fw.watcher.WatchList())
+}
+
 // StopAllWatchers stops watching all agents.
 func (fw *FileWatcher) StopAllWatchers() {
 	fw.mu.Lock()
@@ -797,8 +961,10 @@ func (fw *FileWatcher) StopAllWatchers() {
 	}
 	fw.watchedFiles = make(map[string]bool)
 
-	// Clear folder mapping
+	// Clear folder mapping and poll mode
 	fw.folderToAgentIDs = make(map[string][]string)
+	fw.polledDirs = make(map[string]map[string]fileStamp)
+	fw.degradedDirs = make(map[string]degradation)
 
 	// Clear loaded agents (allows re-loading on next StartWatchingAgent)
 	fw.loadedAgents = make(map[string]bool)
@@ -830,23 +996,25 @@ func (fw *FileWatcher) ReloadSession(agentID, folder, sessionID string) error {
 	sessionsDir := GetSessionsDir(folder)
 	filePath := filepath.Join(sessionsDir, sessionID+".jsonl")
 
-	fmt.Printf("[DEBUG] ReloadSession: clearing and reloading agent=%s session=%s from %s\n",
+	logger.Debugf("ReloadSession: clearing and reloading agent=%s session=%s from %s",
 		agentID[:8], sessionID[:8], filePath)
 
 	// Clear the session cache in runtime
 	rt.ClearSession(agentID, sessionID)
 
 	// Reload messages from JSONL
-	messages, filePos := fw.loadInitialMessages(filePath)
+	messages, filePos, historyEnd, offsets := fw.loadInitialMessages(filePath)
 	if len(messages) > 0 {
 		rt.AppendMessages(agentID, sessionID, messages)
 	}
 	rt.SetFilePosition(agentID, sessionID, filePos)
+	rt.SetHistoryEnd(agentID, sessionID, historyEnd)
+	rt.AddMessageOffsets(agentID, sessionID, offsets)
 
 	// Mark initial load complete
 	rt.MarkInitialLoadDone(agentID, sessionID)
 
-	fmt.Printf("[DEBUG] ReloadSession: loaded %d messages, filePos=%d\n", len(messages), filePos)
+	logger.Debugf("ReloadSession: loaded %d messages, filePos=%d", len(messages), filePos)
 	return nil
 }
 
@@ -857,21 +1025,22 @@ func (fw *FileWatcher) ReloadSession(agentID, folder, sessionID string) error {
 // readNewMessagesLimited reads new messages from startPos up to endPos (exclusive).
 // This prevents reading content that's still being written by Claude Code.
 // The endPos should be the file size observed at the START of handleFileChange.
-func (fw *FileWatcher) readNewMessagesLimited(path string, startPos, endPos int64) []types.Message {
+// Also returns the byte offset of each message's line (by UUID) for disk paging.
+func (fw *FileWatcher) readNewMessagesLimited(path string, startPos, endPos int64) ([]types.Message, map[string]int64) {
 	if endPos <= startPos {
-		return nil
+		return nil, nil
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	defer file.Close()
 
 	// Seek to last known position
 	_, err = file.Seek(startPos, 0)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	// Limit reading to exactly the bytes we observed at the start
@@ -879,10 +1048,9 @@ func (fw *FileWatcher) readNewMessagesLimited(path string, startPos, endPos int6
 	limitReader := io.LimitReader(file, endPos-startPos)
 
 	var messages []types.Message
-	scanner := bufio.NewScanner(limitReader)
-	// Increase buffer for large lines (images can be 1.5MB+ when base64 encoded)
-	scanBuf := make([]byte, 0, 64*1024)
-	scanner.Buffer(scanBuf, 10*1024*1024) // 10MB max line size
+	var entries []IndexEntry
+	offsets := make(map[string]int64)
+	scanner := newLineScanner(limitReader, startPos)
 
 	lineNum := 0
 	for scanner.Scan() {
@@ -895,50 +1063,90 @@ func (fw *FileWatcher) readNewMessagesLimited(path string, startPos, endPos int6
 		msg := fw.parseLine(line)
 		if msg != nil {
 			messages = append(messages, *msg)
-			fmt.Printf("[DEBUG] readNewMessagesLimited: line %d, len=%d, type=%s, uuid=%s\n", lineNum, len(line), msg.Type, msg.UUID[:8])
+			entries = append(entries, newIndexEntry(msg, scanner.Offset()))
+			if msg.UUID != "" {
+				offsets[msg.UUID] = scanner.Offset()
+			}
+			logger.Debugf("readNewMessagesLimited: line %d, len=%d, type=%s, uuid=%s", lineNum, len(line), msg.Type, msg.UUID[:8])
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		fmt.Printf("[DEBUG] readNewMessagesLimited: scanner error: %v\n", err)
+		logger.Debugf("readNewMessagesLimited: scanner error: %v", err)
+	} else if ix := fw.getOffsetIndex(); ix != nil {
+		sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if err := ix.Append(sessionID, startPos, entries, endPos); err != nil {
+			logger.Warnf("%v", err)
+		}
 	}
 
-	return messages
+	return messages, offsets
 }
 
 // loadInitialMessages loads messages from a file for initial session load.
-// Returns messages and the file position (EOF).
-func (fw *FileWatcher) loadInitialMessages(filePath string) ([]types.Message, int64) {
+// Returns messages, the file position (EOF), and the history end: unless full
+// history loading is on, only messages from the last compaction boundary on are
+// returned and historyEnd is that boundary's byte offset (0 = nothing skipped).
+// Earlier messages can be paged in with ReadHistory. The last return value maps
+// message UUIDs (including skipped ones) to the byte offset of their line.
+func (fw *FileWatcher) loadInitialMessages(filePath string) ([]types.Message, int64, int64, map[string]int64) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		fmt.Printf("[DEBUG] loadInitialMessages: failed to open %s: %v\n", filePath, err)
-		return nil, 0
+		logger.Debugf("loadInitialMessages: failed to open %s: %v", filePath, err)
+		return nil, 0, 0, nil
 	}
 	defer file.Close()
 
+	fw.mu.RLock()
+	compactionAware := !fw.loadFullHistory
+	fw.mu.RUnlock()
+
 	// Read all messages to find compaction point
 	var allMessages []types.Message
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 10*1024*1024) // 10MB max line size for large image messages
+	var entries []IndexEntry
+	offsets := make(map[string]int64)
+	scanner := newLineScanner(file, 0)
 
 	lineCount := 0
 	parseFailures := 0
+	var boundaryOffset int64
+	boundaryIndex := 0
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineStart := scanner.Offset()
 		lineCount++
 		if line == "" {
 			continue
 		}
-		if msg := fw.parseLine(line); msg != nil {
+		classified, err := types.ClassifyJSONLEvent(line)
+		if err != nil || classified == nil {
+			parseFailures++
+			continue
+		}
+		if compactionAware && classified.IsCompactionBoundary() && len(allMessages) > boundaryIndex {
+			// Summary right after a boundary event keeps the earlier boundary
+			boundaryOffset = lineStart
+			boundaryIndex = len(allMessages)
+		}
+		if msg := types.ConvertToMessage(classified); msg != nil {
+			metrics.AddMessagesParsed(1)
 			allMessages = append(allMessages, *msg)
+			entries = append(entries, newIndexEntry(msg, lineStart))
+			if _, seen := offsets[msg.UUID]; !seen && msg.UUID != "" {
+				offsets[msg.UUID] = lineStart
+			}
 		} else {
 			parseFailures++
 		}
 	}
 
-	// Load ALL messages for proper deduplication when Claude Code resumes
-	// (Claude Code may write context that includes old messages - we need all UUIDs to deduplicate)
+	// Load ALL messages since the last compaction for proper deduplication when Claude Code
+	// resumes (Claude Code may write context that includes old messages - we need their UUIDs)
 	// Note: The frontend handles pagination/display limits via GetConversationPaged
+	skipped := 0
+	if boundaryIndex > 0 {
+		skipped = boundaryIndex
+		allMessages = allMessages[boundaryIndex:]
+	}
 
 	// Debug: count message types
 	typeCounts := make(map[string]int)
@@ -954,23 +1162,114 @@ func (fw *FileWatcher) loadInitialMessages(filePath string) ([]types.Message, in
 		filePos = fileInfo.Size()
 	}
 
-	fmt.Printf("[DEBUG] loadInitialMessages: file=%s lines=%d parsed=%d failures=%d loading=%d filePos=%d (types: %v)\n",
-		sessionID, lineCount, len(allMessages), parseFailures, len(allMessages), filePos, typeCounts)
+	logger.Debugf("loadInitialMessages: file=%s lines=%d parsed=%d failures=%d loading=%d skippedBeforeCompaction=%d filePos=%d (types: %v)",
+		sessionID, lineCount, len(allMessages)+skipped, parseFailures, len(allMessages), skipped, filePos, typeCounts)
+
+	if ix := fw.getOffsetIndex(); ix != nil {
+		if err := ix.Reset(strings.TrimSuffix(sessionID, ".jsonl"), entries, filePos); err != nil {
+			logger.Warnf("%v", err)
+		}
+	}
+
+	return allMessages, filePos, boundaryOffset, offsets
+}
+
+// SetLoadFullHistory controls whether sessions load messages from before their
+// last compaction (off by default; see loadInitialMessages).
+func (fw *FileWatcher) SetLoadFullHistory(enabled bool) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.loadFullHistory = enabled
+}
+
+// ReadHistory reads the messages of a session file that precede offset (its
+// history end), for paging in what initial load left on disk.
+func (fw *FileWatcher) ReadHistory(folder, sessionID string, offset int64) ([]types.Message, error) {
+	return fw.ReadRange(folder, sessionID, 0, offset)
+}
+
+// ReadRange reads the messages whose lines lie in bytes [start, end) of a
+// session file. start must be the offset of a line.
+func (fw *FileWatcher) ReadRange(folder, sessionID string, start, end int64) ([]types.Message, error) {
+	if end <= start {
+		return []types.Message{}, nil
+	}
+	file, err := os.Open(filepath.Join(GetSessionsDir(folder), sessionID+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	messages := []types.Message{}
+	scanner := newLineScanner(io.LimitReader(file, end-start), start)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			if msg := fw.parseLine(line); msg != nil {
+				messages = append(messages, *msg)
+			}
+		}
+	}
+	return messages, scanner.Err()
+}
 
-	return allMessages, filePos
+// notifySessionChange calls the session change hook, if any.
+func (fw *FileWatcher) notifySessionChange(folder, sessionID string) {
+	fw.mu.RLock()
+	hook := fw.onSessionChange
+	fw.mu.RUnlock()
+	if hook != nil {
+		hook(folder, sessionID)
+	}
 }
 
 // =============================================================================
 // JSONL PARSING
 // =============================================================================
 
+// lineScanner scans JSONL lines while tracking the byte offset of each line.
+type lineScanner struct {
+	*bufio.Scanner
+	offset int64 // Offset of the current line
+	next   int64 // Offset just past the bytes consumed so far
+}
+
+// newLineScanner scans r, whose first byte is at offset base in the file.
+func newLineScanner(r io.Reader, base int64) *lineScanner {
+	s := &lineScanner{Scanner: bufio.NewScanner(r), offset: base, next: base}
+	s.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // 10MB max line size for large image messages
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		s.next += int64(advance)
+		return advance, token, err
+	})
+	return s
+}
+
+// Scan advances to the next line.
+func (s *lineScanner) Scan() bool {
+	s.offset = s.next
+	return s.Scanner.Scan()
+}
+
+// Offset returns the byte offset of the current line.
+func (s *lineScanner) Offset() int64 {
+	return s.offset
+}
+
 // parseLine parses a JSONL line into a Message using the classifier.
 func (fw *FileWatcher) parseLine(line string) *types.Message {
 	classified, err := types.ClassifyJSONLEvent(line)
 	if err != nil || classified == nil {
 		return nil
 	}
-	return types.ConvertToMessage(classified)
+	msg := types.ConvertToMessage(classified)
+	if msg != nil {
+		metrics.AddMessagesParsed(1)
+	}
+	return msg
 }
 
 // =============================================================================
@@ -988,15 +1287,13 @@ func (fw *FileWatcher) parseSessionPath(path string) (folder, sessionID string)
 	base := filepath.Base(path)
 	sessionID = strings.TrimSuffix(base, ".jsonl")
 
-	// The folder name is encoded (/ replaced with -)
+	// The folder name is encoded (non-alphanumerics replaced with -)
 	// Find which agent folder this belongs to
 	fw.mu.RLock()
 	defer fw.mu.RUnlock()
 
 	for f := range fw.folderToAgentIDs {
-		encodedName := encodeProjectPath(f)
-		expectedDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName)
-		if dir == expectedDir {
+		if claudehome.SameDir(dir, claudehome.ProjectDir(f)) {
 			return f, sessionID
 		}
 	}
@@ -1046,22 +1343,12 @@ func parseTimestampToTime(ts string) time.Time {
 // PATH HELPERS (exported for use by other packages)
 // =============================================================================
 
-// encodeProjectPath encodes a folder path like Claude Code does.
-// Claude CLI replaces every non-alphanumeric character with "-".
-var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
-
-func encodeProjectPath(path string) string {
-	return nonAlphanumeric.ReplaceAllString(path, "-")
-}
-
 // BuildSessionPath constructs the path to a session JSONL file.
 func BuildSessionPath(folder, sessionID string) string {
-	encodedName := encodeProjectPath(folder)
-	return filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	return claudehome.SessionPath(folder, sessionID)
 }
 
 // GetSessionsDir returns the directory containing session files for a folder.
 func GetSessionsDir(folder string) string {
-	encodedName := encodeProjectPath(folder)
-	return filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName)
+	return claudehome.ProjectDir(folder)
 }
@@ -0,0 +1,196 @@
+package workspace
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AgentGroup is a named section of a workspace's agent list. Groups are shown
+// in Workspace.Groups order; agents within a group keep their Workspace.Agents
+// order. Agents whose GroupID is empty (or unknown) are ungrouped.
+type AgentGroup struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Collapsed bool   `json:"collapsed,omitempty"` // Collapsed in the sidebar
+}
+
+// GroupedAgents is one section of the agent list. Group is nil for ungrouped agents.
+type GroupedAgents struct {
+	Group  *AgentGroup `json:"group,omitempty"`
+	Agents []Agent     `json:"agents"`
+}
+
+// GenerateGroupID creates a new UUID for an agent group
+func GenerateGroupID() string {
+	return uuid.New().String()
+}
+
+// FindGroup returns the group with the given ID, or nil.
+func (ws *Workspace) FindGroup(groupID string) *AgentGroup {
+	for i := range ws.Groups {
+		if ws.Groups[i].ID == groupID {
+			return &ws.Groups[i]
+		}
+	}
+	return nil
+}
+
+// validateGroupName trims name and checks it is non-empty and not used by
+// another group (case-insensitive).
+func (ws *Workspace) validateGroupName(groupID, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("group name is empty")
+	}
+	for _, g := range ws.Groups {
+		if g.ID != groupID && strings.EqualFold(g.Name, name) {
+			return "", fmt.Errorf("group %q already exists", name)
+		}
+	}
+	return name, nil
+}
+
+// AddGroup appends a new, expanded group.
+func (ws *Workspace) AddGroup(name string) (*AgentGroup, error) {
+	name, err := ws.validateGroupName("", name)
+	if err != nil {
+		return nil, err
+	}
+	ws.Groups = append(ws.Groups, AgentGroup{ID: GenerateGroupID(), Name: name})
+	return &ws.Groups[len(ws.Groups)-1], nil
+}
+
+// RenameGroup renames a group.
+func (ws *Workspace) RenameGroup(groupID, name string) error {
+	group := ws.FindGroup(groupID)
+	if group == nil {
+		return fmt.Errorf("group not found: %s", groupID)
+	}
+	name, err := ws.validateGroupName(groupID, name)
+	if err != nil {
+		return err
+	}
+	group.Name = name
+	return nil
+}
+
+// SetGroupCollapsed sets whether a group is collapsed.
+func (ws *Workspace) SetGroupCollapsed(groupID string, collapsed bool) error {
+	group := ws.FindGroup(groupID)
+	if group == nil {
+		return fmt.Errorf("group not found: %s", groupID)
+	}
+	group.Collapsed = collapsed
+	return nil
+}
+
+// RemoveGroup deletes a group. Its agents become ungrouped.
+func (ws *Workspace) RemoveGroup(groupID string) error {
+	if ws.FindGroup(groupID) == nil {
+		return fmt.Errorf("group not found: %s", groupID)
+	}
+	ws.Groups = slices.DeleteFunc(ws.Groups, func(g AgentGroup) bool { return g.ID == groupID })
+	for i := range ws.Agents {
+		if ws.Agents[i].GroupID == groupID {
+			ws.Agents[i].GroupID = ""
+		}
+	}
+	return nil
+}
+
+// ReorderGroups reorders the groups. orderedIDs is the full list of group IDs
+// in the desired order.
+func (ws *Workspace) ReorderGroups(orderedIDs []string) error {
+	if len(orderedIDs) != len(ws.Groups) {
+		return fmt.Errorf("expected %d group IDs, got %d", len(ws.Groups), len(orderedIDs))
+	}
+	reordered := make([]AgentGroup, 0, len(orderedIDs))
+	for _, id := range orderedIDs {
+		group := ws.FindGroup(id)
+		if group == nil {
+			return fmt.Errorf("group not found: %s", id)
+		}
+		if slices.ContainsFunc(reordered, func(g AgentGroup) bool { return g.ID == id }) {
+			return fmt.Errorf("duplicate group ID: %s", id)
+		}
+		reordered = append(reordered, *group)
+	}
+	ws.Groups = reordered
+	return nil
+}
+
+// SetAgentGroup moves an agent into a group ("" = ungrouped). When index is
+// >= 0 the agent is also moved to that position among the group's agents
+// (clamped to the end); otherwise it keeps its place in Workspace.Agents.
+func (ws *Workspace) SetAgentGroup(agentID, groupID string, index int) error {
+	if groupID != "" && ws.FindGroup(groupID) == nil {
+		return fmt.Errorf("group not found: %s", groupID)
+	}
+	from := slices.IndexFunc(ws.Agents, func(a Agent) bool { return a.ID == agentID })
+	if from < 0 {
+		return fmt.Errorf("agent not found: %s", agentID)
+	}
+	agent := ws.Agents[from]
+	agent.GroupID = groupID
+	if index < 0 {
+		ws.Agents[from] = agent
+		return nil
+	}
+
+	// Find the slot of the index-th member of the target group (the agent itself excluded)
+	agents := slices.Delete(slices.Clone(ws.Agents), from, from+1)
+	to, seen := len(agents), 0
+	for i, a := range agents {
+		if ws.groupOf(a) != groupID {
+			continue
+		}
+		if seen == index {
+			to = i
+			break
+		}
+		seen++
+		to = i + 1
+	}
+	ws.Agents = slices.Insert(agents, to, agent)
+	return nil
+}
+
+// groupOf returns the agent's group ID, or "" if it is ungrouped or its group no longer exists.
+func (ws *Workspace) groupOf(agent Agent) string {
+	if agent.GroupID == "" || ws.FindGroup(agent.GroupID) == nil {
+		return ""
+	}
+	return agent.GroupID
+}
+
+// GroupedAgents returns the agents section by section: each group in order
+// (including empty ones), then the ungrouped agents if there are any.
+func (ws *Workspace) GroupedAgents() []GroupedAgents {
+	sections := make([]GroupedAgents, 0, len(ws.Groups)+1)
+	for i := range ws.Groups {
+		sections = append(sections, GroupedAgents{Group: &ws.Groups[i], Agents: []Agent{}})
+	}
+	var ungrouped []Agent
+	for _, agent := range ws.Agents {
+		i := slices.IndexFunc(ws.Groups, func(g AgentGroup) bool { return g.ID == agent.GroupID })
+		if agent.GroupID == "" || i < 0 {
+			ungrouped = append(ungrouped, agent)
+			continue
+		}
+		sections[i].Agents = append(sections[i].Agents, agent)
+	}
+	if len(ungrouped) > 0 {
+		sections = append(sections, GroupedAgents{Agents: ungrouped})
+	}
+	return sections
+}
+
+// pruneGroupRefs ungroups agents whose group no longer exists.
+func (ws *Workspace) pruneGroupRefs() {
+	for i := range ws.Agents {
+		ws.Agents[i].GroupID = ws.groupOf(ws.Agents[i])
+	}
+}
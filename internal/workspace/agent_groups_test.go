@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetAgentGroup(t *testing.T) {
+	// a, b in g1; c in g2; d ungrouped; g3 empty
+	newWorkspace := func() *Workspace {
+		return &Workspace{
+			Groups: []AgentGroup{{ID: "g1", Name: "One"}, {ID: "g2", Name: "Two"}, {ID: "g3", Name: "Three"}},
+			Agents: []Agent{{ID: "a", GroupID: "g1"}, {ID: "b", GroupID: "g1"}, {ID: "c", GroupID: "g2"}, {ID: "d"}},
+		}
+	}
+	tests := []struct {
+		name      string
+		agentID   string
+		groupID   string
+		index     int
+		wantOrder []string
+		wantErr   bool
+	}{
+		{name: "keep position", agentID: "d", groupID: "g1", index: -1, wantOrder: []string{"a", "b", "c", "d"}},
+		{name: "first in group", agentID: "d", groupID: "g1", index: 0, wantOrder: []string{"d", "a", "b", "c"}},
+		{name: "between members", agentID: "d", groupID: "g1", index: 1, wantOrder: []string{"a", "d", "b", "c"}},
+		{name: "index past end clamps", agentID: "d", groupID: "g1", index: 99, wantOrder: []string{"a", "b", "d", "c"}},
+		{name: "into another group", agentID: "a", groupID: "g2", index: 0, wantOrder: []string{"b", "a", "c", "d"}},
+		{name: "ungroup", agentID: "a", groupID: "", index: 0, wantOrder: []string{"b", "c", "a", "d"}},
+		{name: "empty group", agentID: "a", groupID: "g3", index: 0, wantOrder: []string{"b", "c", "d", "a"}},
+		{name: "unknown group", agentID: "a", groupID: "nope", index: 0, wantErr: true},
+		{name: "unknown agent", agentID: "nope", groupID: "g1", index: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newWorkspace()
+			err := ws.SetAgentGroup(tt.agentID, tt.groupID, tt.index)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetAgentGroup: %v", err)
+			}
+			var order []string
+			for _, a := range ws.Agents {
+				order = append(order, a.ID)
+				if a.ID == tt.agentID && a.GroupID != tt.groupID {
+					t.Errorf("agent %s GroupID = %q, want %q", a.ID, a.GroupID, tt.groupID)
+				}
+			}
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestSaveWorkspaceRoundTripsGroups(t *testing.T) {
+	m := NewManager(t.TempDir())
+	ws := &Workspace{
+		ID:     GenerateWorkspaceID(),
+		Name:   "test",
+		Groups: []AgentGroup{{ID: "g1", Name: "Backend", Collapsed: true}, {ID: "g2", Name: "Frontend"}},
+		Agents: []Agent{{ID: GenerateAgentID(), Folder: "/src/api", GroupID: "g1"}},
+	}
+	if err := m.SaveWorkspace(ws); err != nil {
+		t.Fatalf("SaveWorkspace: %v", err)
+	}
+	loaded, err := m.LoadWorkspace(ws.ID)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+	if !slices.Equal(loaded.Groups, ws.Groups) {
+		t.Errorf("groups = %+v, want %+v", loaded.Groups, ws.Groups)
+	}
+	if len(loaded.Agents) != 1 || loaded.Agents[0].GroupID != "g1" {
+		t.Errorf("agents = %+v, want one agent in g1", loaded.Agents)
+	}
+}
@@ -0,0 +1,242 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"claudefu/internal/fsutil"
+	"claudefu/internal/permissions"
+)
+
+// =============================================================================
+// AGENT TEMPLATES
+// An agent template bootstraps one agent: its settings, ClaudeFu permissions,
+// MCP slug/description, and a CLAUDE.md skeleton. Templates are stored in
+// ~/.claudefu/agent-templates/{id}.json. Text fields may use {{ AGENT_SLUG }},
+// {{ AGENT_ID }}, and {{ PROJECT_NAME }} (the new folder's basename).
+// =============================================================================
+
+// AgentTemplate is a saved agent blueprint.
+type AgentTemplate struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	// MCP identity convention
+	SlugPattern    string `json:"slugPattern,omitempty"`    // Slug when none is given, e.g. "{{ PROJECT_NAME }}-api"
+	MCPDescription string `json:"mcpDescription,omitempty"` // AGENT_DESCRIPTION for agents without one
+
+	WatchMode      string   `json:"watchMode,omitempty"`
+	MCPEnabled     *bool    `json:"mcpEnabled,omitempty"`
+	PostProcessors []string `json:"postProcessors,omitempty"`
+	Specialization string   `json:"specialization,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	ClaudeCommand  string   `json:"claudeCommand,omitempty"`
+	ClaudeArgs     []string `json:"claudeArgs,omitempty"`
+
+	// Permission mode preset, e.g. "plan" for an architect agent ("" = acceptEdits)
+	DefaultPermissionMode string `json:"defaultPermissionMode,omitempty"`
+
+	// Agent-specific ClaudeFu permissions (nil = leave the new agent on the global ones)
+	Permissions *permissions.ClaudeFuPermissions `json:"permissions,omitempty"`
+
+	// CLAUDE.md written to folders that don't have one ("" = none)
+	ClaudeMD string `json:"claudeMD,omitempty"`
+}
+
+// AgentTemplateSummary is a minimal reference for listing agent templates.
+type AgentTemplateSummary struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description,omitempty"`
+	Specialization string    `json:"specialization,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// agentTemplatesDir returns ~/.claudefu/agent-templates.
+func (m *Manager) agentTemplatesDir() string {
+	return filepath.Join(m.configPath, "agent-templates")
+}
+
+func (m *Manager) agentTemplatePath(id string) string {
+	return filepath.Join(m.agentTemplatesDir(), sanitizeFilename(id)+".json")
+}
+
+// NewAgentTemplate builds a template from an agent. Whole-word occurrences of
+// the agent's project name, slug, and ID in slug, description, and claudeMD
+// are replaced with placeholders so they fit the agent created from the
+// template. Permissions are filled by the caller.
+func NewAgentTemplate(agent Agent, name, description, claudeMD string) *AgentTemplate {
+	projectName := filepath.Base(agent.Folder)
+	slug := agent.GetSlug()
+	placeholder := func(s, old, key string) string {
+		if old == "" {
+			return s
+		}
+		return replaceWholeWord(s, old, "{{ "+key+" }}")
+	}
+	tmpl := &AgentTemplate{
+		ID:             uuid.New().String(),
+		Name:           name,
+		Description:    description,
+		CreatedAt:      time.Now(),
+		SlugPattern:    placeholder(slug, Slugify(projectName), "PROJECT_NAME"),
+		MCPDescription: placeholder(agent.Description, projectName, "PROJECT_NAME"),
+		WatchMode:      agent.WatchMode,
+		MCPEnabled:     agent.MCPEnabled,
+		PostProcessors: agent.PostProcessors,
+		Specialization: agent.Specialization,
+		Tags:           agent.Tags,
+		ClaudeCommand:  agent.ClaudeCommand,
+		ClaudeArgs:     agent.ClaudeArgs,
+
+		DefaultPermissionMode: agent.DefaultPermissionMode,
+	}
+	if claudeMD != "" {
+		tmpl.ClaudeMD = placeholder(placeholder(claudeMD, agent.ID, "AGENT_ID"), slug, "AGENT_SLUG")
+	}
+	return tmpl
+}
+
+// replaceWholeWord replaces the occurrences of old in s that are not part of a
+// longer word (letters, digits and "_"), so a slug like "app" leaves
+// "application" alone.
+func replaceWholeWord(s, old, replacement string) string {
+	var b strings.Builder
+	last := 0
+	for start := 0; start < len(s); {
+		i := strings.Index(s[start:], old)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(old)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if isWordRune(before) || isWordRune(after) {
+			start = i + 1
+			continue
+		}
+		b.WriteString(s[last:i])
+		b.WriteString(replacement)
+		last, start = end, end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// RenderAgentTemplate substitutes the agent template placeholders in text.
+func RenderAgentTemplate(text, agentID, slug, folder string) string {
+	return ProcessTemplate(text, map[string]string{
+		"AGENT_SLUG":   slug,
+		"AGENT_ID":     agentID,
+		"PROJECT_NAME": filepath.Base(folder),
+	})
+}
+
+// Slug returns the template's slug for folder ("" if it has no slug pattern).
+func (t *AgentTemplate) Slug(folder string) string {
+	if t.SlugPattern == "" {
+		return ""
+	}
+	return Slugify(ProcessTemplate(t.SlugPattern, map[string]string{
+		"PROJECT_NAME": Slugify(filepath.Base(folder)),
+	}))
+}
+
+// SaveAgentTemplate writes an agent template, assigning an ID if it has none.
+func (m *Manager) SaveAgentTemplate(tmpl *AgentTemplate) error {
+	if strings.TrimSpace(tmpl.Name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if tmpl.ID == "" {
+		tmpl.ID = uuid.New().String()
+	}
+	if err := os.MkdirAll(m.agentTemplatesDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tmpl, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(m.agentTemplatePath(tmpl.ID), data, 0644)
+}
+
+// GetAgentTemplate loads an agent template by ID.
+func (m *Manager) GetAgentTemplate(id string) (*AgentTemplate, error) {
+	data, err := os.ReadFile(m.agentTemplatePath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("agent template not found: %s", id)
+		}
+		return nil, err
+	}
+	var tmpl AgentTemplate
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse agent template %s: %w", id, err)
+	}
+	return &tmpl, nil
+}
+
+// ListAgentTemplates returns all saved agent templates, sorted by name.
+func (m *Manager) ListAgentTemplates() ([]AgentTemplateSummary, error) {
+	entries, err := os.ReadDir(m.agentTemplatesDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AgentTemplateSummary{}, nil
+		}
+		return nil, err
+	}
+
+	result := []AgentTemplateSummary{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(m.agentTemplatesDir(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var tmpl AgentTemplate
+		if err := json.Unmarshal(data, &tmpl); err != nil {
+			logger.Warnf("Skipping unreadable agent template %s: %v", entry.Name(), err)
+			continue
+		}
+		result = append(result, AgentTemplateSummary{
+			ID:             tmpl.ID,
+			Name:           tmpl.Name,
+			Description:    tmpl.Description,
+			Specialization: tmpl.Specialization,
+			CreatedAt:      tmpl.CreatedAt,
+		})
+	}
+	slices.SortFunc(result, func(a, b AgentTemplateSummary) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return result, nil
+}
+
+// DeleteAgentTemplate removes an agent template by ID.
+func (m *Manager) DeleteAgentTemplate(id string) error {
+	if err := os.Remove(m.agentTemplatePath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("agent template not found: %s", id)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package workspace
+
+import "testing"
+
+func TestReplaceWholeWord(t *testing.T) {
+	tests := []struct {
+		s, old, want string
+	}{
+		{"app", "app", "X"},
+		{"The app agent", "app", "The X agent"},
+		{"application mapping apps app_name", "app", "application mapping apps app_name"},
+		{"app-server (app).", "app", "X-server (X)."},
+		{"my-app and my-apple", "my-app", "X and my-apple"},
+		{"appapp app", "app", "appapp X"},
+		{"café app", "caf", "café app"},
+		{"no match", "app", "no match"},
+	}
+	for _, tt := range tests {
+		if got := replaceWholeWord(tt.s, tt.old, "X"); got != tt.want {
+			t.Errorf("replaceWholeWord(%q, %q) = %q, want %q", tt.s, tt.old, got, tt.want)
+		}
+	}
+}
+
+func TestNewAgentTemplateKeepsWordsContainingTheSlug(t *testing.T) {
+	agent := Agent{ID: "a1", Folder: "/src/app", Slug: "app"}
+	tmpl := NewAgentTemplate(agent, "Web", "", "# app\nThe application maps app routes.\n")
+	want := "# {{ AGENT_SLUG }}\nThe application maps {{ AGENT_SLUG }} routes.\n"
+	if tmpl.ClaudeMD != want {
+		t.Errorf("ClaudeMD = %q, want %q", tmpl.ClaudeMD, want)
+	}
+	if tmpl.SlugPattern != "{{ PROJECT_NAME }}" {
+		t.Errorf("SlugPattern = %q, want {{ PROJECT_NAME }}", tmpl.SlugPattern)
+	}
+}
@@ -0,0 +1,211 @@
+package workspace
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"claudefu/internal/types"
+)
+
+// SupportedProviders lists the Agent.Provider values that can drive an agent
+// (mirrors providers.Names). An empty provider means the default (claude_code).
+var SupportedProviders = []string{"claude_code", "anthropic", "codex"}
+
+// AgentUpdate is a partial update to an agent. Nil fields are left unchanged.
+// Folder is not updatable — agent identity is keyed by folder, so moving an
+// agent means removing it and adding the new folder.
+type AgentUpdate struct {
+	Slug           *string   `json:"slug,omitempty"`
+	WatchMode      *string   `json:"watchMode,omitempty"`
+	Provider       *string   `json:"provider,omitempty"`
+	Specialization *string   `json:"specialization,omitempty"`
+	ClaudeMdPath   *string   `json:"claudeMdPath,omitempty"`
+	MCPEnabled     *bool     `json:"mcpEnabled,omitempty"`
+	PostProcessors *[]string `json:"postProcessors,omitempty"`
+	Tags           *[]string `json:"tags,omitempty"`
+	ClaudeCommand  *string   `json:"claudeCommand,omitempty"`
+	ClaudeArgs     *[]string `json:"claudeArgs,omitempty"`
+
+	Env *map[string]string `json:"env,omitempty"`
+
+	DefaultPermissionMode *string `json:"defaultPermissionMode,omitempty"`
+
+	BufferMaxMessages *int   `json:"bufferMaxMessages,omitempty"`
+	BufferMaxBytes    *int64 `json:"bufferMaxBytes,omitempty"`
+
+	InboxAutoInject  *bool `json:"inboxAutoInject,omitempty"`
+	InboxAutoRespond *bool `json:"inboxAutoRespond,omitempty"`
+}
+
+// AgentUpdateFrom builds a full-replacement update from an agent struct
+// (used by the legacy whole-struct UpdateAgent binding).
+func AgentUpdateFrom(agent Agent) AgentUpdate {
+	mcpEnabled := agent.GetMCPEnabled()
+	postProcessors := agent.PostProcessors
+	tags := agent.Tags
+	claudeArgs := agent.ClaudeArgs
+	env := agent.Env
+	return AgentUpdate{
+		Slug:           &agent.Slug,
+		WatchMode:      &agent.WatchMode,
+		Provider:       &agent.Provider,
+		Specialization: &agent.Specialization,
+		ClaudeMdPath:   &agent.ClaudeMdPath,
+		MCPEnabled:     &mcpEnabled,
+		PostProcessors: &postProcessors,
+		Tags:           &tags,
+		ClaudeCommand:  &agent.ClaudeCommand,
+		ClaudeArgs:     &claudeArgs,
+
+		Env: &env,
+
+		DefaultPermissionMode: &agent.DefaultPermissionMode,
+
+		BufferMaxMessages: &agent.BufferMaxMessages,
+		BufferMaxBytes:    &agent.BufferMaxBytes,
+
+		InboxAutoInject:  &agent.InboxAutoInject,
+		InboxAutoRespond: &agent.InboxAutoRespond,
+	}
+}
+
+// Apply copies the set fields onto agent and returns the JSON names of the fields
+// whose value actually changed.
+func (u AgentUpdate) Apply(agent *Agent) []string {
+	var changed []string
+	setString := func(name string, dst *string, src *string) {
+		if src != nil && *dst != *src {
+			*dst = *src
+			changed = append(changed, name)
+		}
+	}
+
+	if u.Slug != nil && *u.Slug != "" && *u.Slug != agent.GetSlug() {
+		agent.Slug = *u.Slug
+		changed = append(changed, "slug")
+	}
+	setString("watchMode", &agent.WatchMode, u.WatchMode)
+	setString("provider", &agent.Provider, u.Provider)
+	setString("specialization", &agent.Specialization, u.Specialization)
+	setString("claudeMdPath", &agent.ClaudeMdPath, u.ClaudeMdPath)
+	if u.MCPEnabled != nil && *u.MCPEnabled != agent.GetMCPEnabled() {
+		enabled := *u.MCPEnabled
+		agent.MCPEnabled = &enabled
+		changed = append(changed, "mcpEnabled")
+	}
+	if u.PostProcessors != nil && !slices.Equal(*u.PostProcessors, agent.PostProcessors) {
+		agent.PostProcessors = slices.Clone(*u.PostProcessors)
+		changed = append(changed, "postProcessors")
+	}
+	if u.Tags != nil {
+		tags := NormalizeTags(*u.Tags)
+		if !slices.Equal(tags, agent.Tags) {
+			agent.Tags = tags
+			changed = append(changed, "tags")
+		}
+	}
+	if u.ClaudeCommand != nil {
+		command := strings.TrimSpace(*u.ClaudeCommand)
+		setString("claudeCommand", &agent.ClaudeCommand, &command)
+	}
+	if u.ClaudeArgs != nil && !slices.Equal(*u.ClaudeArgs, agent.ClaudeArgs) {
+		agent.ClaudeArgs = slices.Clone(*u.ClaudeArgs)
+		changed = append(changed, "claudeArgs")
+	}
+	if u.Env != nil && !maps.Equal(*u.Env, agent.Env) {
+		agent.Env = maps.Clone(*u.Env)
+		changed = append(changed, "env")
+	}
+	setString("defaultPermissionMode", &agent.DefaultPermissionMode, u.DefaultPermissionMode)
+	if u.BufferMaxMessages != nil && *u.BufferMaxMessages != agent.BufferMaxMessages {
+		agent.BufferMaxMessages = *u.BufferMaxMessages
+		changed = append(changed, "bufferMaxMessages")
+	}
+	if u.BufferMaxBytes != nil && *u.BufferMaxBytes != agent.BufferMaxBytes {
+		agent.BufferMaxBytes = *u.BufferMaxBytes
+		changed = append(changed, "bufferMaxBytes")
+	}
+	if u.InboxAutoInject != nil && *u.InboxAutoInject != agent.InboxAutoInject {
+		agent.InboxAutoInject = *u.InboxAutoInject
+		changed = append(changed, "inboxAutoInject")
+	}
+	if u.InboxAutoRespond != nil && *u.InboxAutoRespond != agent.InboxAutoRespond {
+		agent.InboxAutoRespond = *u.InboxAutoRespond
+		changed = append(changed, "inboxAutoRespond")
+	}
+	return changed
+}
+
+// ValidateAgent checks an agent's configuration before it is saved to ws:
+// the slug is a valid MCP identifier and unique (case-insensitive) among the
+// workspace's other agents, the folder exists, and the provider, watch mode
+// and permission mode are supported. changed is the field list returned by
+// AgentUpdate.Apply; the global registry is only checked for a slug collision
+// when "slug" is in it, so unrelated edits never trip over a registry entry.
+func (m *Manager) ValidateAgent(ws *Workspace, agent Agent, changed []string) error {
+	slug := agent.GetSlug()
+	if slug == "" || Slugify(slug) != slug {
+		return fmt.Errorf("invalid slug %q: use letters, digits, and dashes only", slug)
+	}
+	for _, other := range ws.Agents {
+		if other.ID != agent.ID && strings.EqualFold(other.GetSlug(), slug) {
+			return fmt.Errorf("slug %q is already used by another agent in this workspace", slug)
+		}
+	}
+	if slices.Contains(changed, "slug") {
+		if info, folder := m.FindAgentBySlug(slug); info != nil && folder != agent.Folder {
+			return fmt.Errorf("slug %q is already used by the agent at %s", slug, folder)
+		}
+	}
+
+	if agent.Folder == "" {
+		return fmt.Errorf("agent folder is empty")
+	}
+	if info, err := os.Stat(agent.Folder); err != nil {
+		return fmt.Errorf("agent folder not found: %s", agent.Folder)
+	} else if !info.IsDir() {
+		return fmt.Errorf("agent folder is not a directory: %s", agent.Folder)
+	}
+
+	if agent.Provider != "" && !slices.Contains(SupportedProviders, agent.Provider) {
+		return fmt.Errorf("unsupported provider: %s", agent.Provider)
+	}
+	if agent.WatchMode != "" && !slices.Contains([]string{types.WatchModeFile, types.WatchModeStream, types.WatchModePoll}, agent.WatchMode) {
+		return fmt.Errorf("unsupported watch mode: %s", agent.WatchMode)
+	}
+	if agent.DefaultPermissionMode != "" && !slices.Contains(types.PermissionModes, agent.DefaultPermissionMode) {
+		return fmt.Errorf("unsupported permission mode: %s", agent.DefaultPermissionMode)
+	}
+	if agent.BufferMaxMessages < 0 || agent.BufferMaxBytes < 0 {
+		return fmt.Errorf("buffer limits cannot be negative")
+	}
+	if err := ValidateEnvVarNames(agent.Env); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateEnvVarNames checks that every key of vars is a usable environment variable name.
+func ValidateEnvVarNames(vars map[string]string) error {
+	for key := range vars {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "= \t\n") {
+			return fmt.Errorf("invalid environment variable name: %q", key)
+		}
+	}
+	return nil
+}
+
+// NormalizeTags lowercases and trims tags, dropping empties and duplicates.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
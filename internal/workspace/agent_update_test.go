@@ -0,0 +1,33 @@
+package workspace
+
+import "testing"
+
+func TestValidateAgentSlugCollision(t *testing.T) {
+	m := NewManager(t.TempDir())
+	m.RegisterAgentID("/elsewhere/api", GenerateAgentID())
+	m.UpdateAgentSlug("/elsewhere/api", "api")
+
+	folder := t.TempDir()
+	ws := &Workspace{Agents: []Agent{{ID: "other", Slug: "web", Folder: folder}}}
+	tests := []struct {
+		name    string
+		slug    string
+		changed []string
+		wantErr bool
+	}{
+		{"registry collision on rename", "api", []string{"slug"}, true},
+		{"registry not checked for other edits", "api", []string{"env"}, false},
+		{"workspace collision", "web", []string{"env"}, true},
+		{"free slug", "worker", []string{"slug"}, false},
+		{"invalid slug", "Not A Slug", []string{"slug"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := Agent{ID: "self", Slug: tt.slug, Folder: folder}
+			err := m.ValidateAgent(ws, agent, tt.changed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAgent = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
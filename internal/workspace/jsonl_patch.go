@@ -4,12 +4,15 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"claudefu/internal/claudehome"
 )
 
 // PatchQuestionAnswer patches a failed AskUserQuestion tool_result with a successful answer.
@@ -22,8 +25,7 @@ import (
 // 4. Writes the modified file back
 func PatchQuestionAnswer(folder, sessionID, toolUseID string, questions []map[string]any, answers map[string]string) error {
 	// Build JSONL path
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	// Read all lines
 	data, err := os.ReadFile(sessionPath)
@@ -91,7 +93,7 @@ func PatchQuestionAnswer(folder, sessionID, toolUseID string, questions []map[st
 			// 2. Set content to formatted answer string
 			blockMap["content"] = formatAnswerContent(questions, answers)
 
-			fmt.Printf("[PATCH] Patched tool_result %s: is_error=false, content set\n", toolUseID)
+			logger.Debugf("Patched tool_result %s: is_error=false, content set", toolUseID)
 
 			// Update the block in the content array
 			content[j] = blockMap
@@ -139,7 +141,7 @@ func PatchQuestionAnswer(folder, sessionID, toolUseID string, questions []map[st
 		return fmt.Errorf("failed to write patched session file: %w", err)
 	}
 
-	fmt.Printf("[PATCH] Successfully wrote patched JSONL to %s\n", sessionPath)
+	logger.Infof("Successfully wrote patched JSONL to %s", sessionPath)
 	return nil
 }
 
@@ -176,7 +178,7 @@ func deleteStaleAssistantMessages(lines []string, patchedIdx int) []string {
 				deleteStart = i
 			}
 			deleteEnd = i
-			fmt.Printf("[PATCH] Marking stale assistant message at line %d for deletion\n", i)
+			logger.Debugf("Marking stale assistant message at line %d for deletion", i)
 
 		case "user":
 			// Check if this is a real user message or just a tool_result carrier
@@ -201,7 +203,7 @@ func deleteStaleAssistantMessages(lines []string, patchedIdx int) []string {
 done:
 	// Remove the marked lines
 	if deleteStart != -1 && deleteEnd >= deleteStart {
-		fmt.Printf("[PATCH] Deleting lines %d-%d (stale responses)\n", deleteStart, deleteEnd)
+		logger.Debugf("Deleting lines %d-%d (stale responses)", deleteStart, deleteEnd)
 		lines = append(lines[:deleteStart], lines[deleteEnd+1:]...)
 	}
 
@@ -285,8 +287,7 @@ func formatAnswerContent(questions []map[string]any, answers map[string]string)
 // The message uses a special prefix that the frontend can detect for styling.
 func AppendCancellationMarker(folder, sessionID string) error {
 	// Build JSONL path
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	// Create the cancellation marker message
 	// Use a prefix that frontend can detect: [CANCELLED]
@@ -317,7 +318,7 @@ func AppendCancellationMarker(folder, sessionID string) error {
 		return fmt.Errorf("failed to append cancellation marker: %w", err)
 	}
 
-	fmt.Printf("[DEBUG] AppendCancellationMarker: wrote marker to %s\n", sessionPath)
+	logger.Debugf("AppendCancellationMarker: wrote marker to %s", sessionPath)
 	return nil
 }
 
@@ -328,8 +329,7 @@ func AppendCancellationMarker(folder, sessionID string) error {
 // For ACCEPT: writes toolUseResult: {plan, isAgent, filePath}
 // For REJECT: writes toolUseResult: "Error: ..." (string, not object)
 func WritePlanReviewResult(folder, sessionID, toolUseID, assistantUUID, slug string, accepted bool, plan, planFilePath, feedback string) error {
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	// Common metadata fields matching Claude Code's native format.
 	// Missing parentUuid/isSidechain/sessionId causes Claude Code to reject
@@ -423,7 +423,7 @@ func WritePlanReviewResult(folder, sessionID, toolUseID, assistantUUID, slug str
 	if !accepted {
 		action = "REJECTED"
 	}
-	fmt.Printf("[PATCH] WritePlanReviewResult: %s plan, wrote synthetic entry to %s\n", action, sessionPath)
+	logger.Infof("WritePlanReviewResult: %s plan, wrote synthetic entry to %s", action, sessionPath)
 	return nil
 }
 
@@ -433,8 +433,7 @@ func WritePlanReviewResult(folder, sessionID, toolUseID, assistantUUID, slug str
 // This is needed because the MCP CallToolRequest doesn't expose the tool_use_id
 // that Claude assigned when calling our MCP tool.
 func FindLatestToolUseID(folder, sessionID, toolName string) (toolID string, assistantUUID string, err error) {
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	f, err := os.Open(sessionPath)
 	if err != nil {
@@ -503,7 +502,7 @@ func FindLatestToolUseID(folder, sessionID, toolName string) (toolID string, ass
 			if !ok {
 				continue
 			}
-			fmt.Printf("[PATCH] FindLatestToolUseID: found %s → %s (assistant uuid=%s)\n", toolName, tid, msgUUID)
+			logger.Debugf("FindLatestToolUseID: found %s → %s (assistant uuid=%s)", toolName, tid, msgUUID)
 			return tid, msgUUID, nil
 		}
 	}
@@ -517,8 +516,7 @@ func FindLatestToolUseID(folder, sessionID, toolName string) (toolID string, ass
 // This is used as a fallback when FindLatestToolUseID fails on the parent session JSONL,
 // indicating the tool was called from a Plan/Task subagent.
 func FindToolUseInSubagents(folder, sessionID, toolName string) (toolID, assistantUUID, subagentPath, planContent string, err error) {
-	encodedName := encodeProjectPath(folder)
-	subagentsDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID, "subagents")
+	subagentsDir := claudehome.SubagentsDir(folder, sessionID)
 
 	entries, err := os.ReadDir(subagentsDir)
 	if err != nil {
@@ -562,12 +560,12 @@ func FindToolUseInSubagents(folder, sessionID, toolName string) (toolID, assista
 	for _, fe := range jsonlFiles[:limit] {
 		tid, uuid, plan, scanErr := scanSubagentForToolUse(fe.path, toolName)
 		if scanErr == nil && tid != "" {
-			fmt.Printf("[PATCH] FindToolUseInSubagents: found %s in %s → %s\n", toolName, filepath.Base(fe.path), tid)
+			logger.Debugf("FindToolUseInSubagents: found %s in %s → %s", toolName, filepath.Base(fe.path), tid)
 			return tid, uuid, fe.path, plan, nil
 		}
 	}
 
-	return "", "", "", "", fmt.Errorf("no tool_use block found for %s in any recent subagent")
+	return "", "", "", "", fmt.Errorf("no tool_use block found for %s in any recent subagent", toolName)
 }
 
 // scanSubagentForToolUse scans a single subagent JSONL backwards for a tool_use matching
@@ -648,8 +646,7 @@ func scanSubagentForToolUse(jsonlPath, toolName string) (toolID, assistantUUID,
 // Always truncates downward — no parent_uuid patching needed.
 // Returns the number of lines removed, or an error.
 func DeleteFromMessage(folder, sessionID, messageUUID string) (int, error) {
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	data, err := os.ReadFile(sessionPath)
 	if err != nil {
@@ -659,26 +656,7 @@ func DeleteFromMessage(folder, sessionID, messageUUID string) (int, error) {
 	lines := strings.Split(string(data), "\n")
 
 	// Find the line with the matching UUID
-	cutIndex := -1
-	for i, line := range lines {
-		if line == "" {
-			continue
-		}
-		if !strings.Contains(line, messageUUID) {
-			continue
-		}
-
-		var event map[string]any
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			continue
-		}
-
-		if event["uuid"] == messageUUID {
-			cutIndex = i
-			break
-		}
-	}
-
+	cutIndex := findMessageLine(lines, messageUUID)
 	if cutIndex < 0 {
 		return 0, fmt.Errorf("message not found: %s", messageUUID)
 	}
@@ -705,6 +683,154 @@ func DeleteFromMessage(folder, sessionID, messageUUID string) (int, error) {
 		return 0, fmt.Errorf("failed to write truncated session file: %w", err)
 	}
 
-	fmt.Printf("[PATCH] DeleteFromMessage: removed %d lines from %s (cut at line %d, uuid=%s)\n", removed, sessionPath, cutIndex, messageUUID)
+	logger.Infof("DeleteFromMessage: removed %d lines from %s (cut at line %d, uuid=%s)", removed, sessionPath, cutIndex, messageUUID)
 	return removed, nil
 }
+
+// RewindSession truncates a session after the specified message UUID, keeping
+// the message itself, so the conversation can continue from that point. The
+// original file is first copied to {sessionID}.jsonl.{unix}.bak next to it.
+// Returns the number of JSONL lines removed and the backup path.
+func RewindSession(folder, sessionID, messageUUID string) (int, string, error) {
+	sessionPath := claudehome.SessionPath(folder, sessionID)
+
+	data, err := os.ReadFile(sessionPath)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	index := findMessageLine(lines, messageUUID)
+	if index < 0 {
+		return 0, "", fmt.Errorf("message not found: %s", messageUUID)
+	}
+
+	removed := 0
+	for _, line := range lines[index+1:] {
+		if line != "" {
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, "", nil // Already the last message
+	}
+
+	backupPath := fmt.Sprintf("%s.%d.bak", sessionPath, time.Now().Unix())
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return 0, "", fmt.Errorf("failed to back up session file: %w", err)
+	}
+
+	output := strings.Join(lines[:index+1], "\n") + "\n"
+	if err := os.WriteFile(sessionPath, []byte(output), 0644); err != nil {
+		return 0, "", fmt.Errorf("failed to write rewound session file: %w", err)
+	}
+
+	logger.Infof("RewindSession: removed %d lines from %s after uuid=%s (backup %s)", removed, sessionPath, messageUUID, backupPath)
+	return removed, backupPath, nil
+}
+
+// RewindSessionAt is RewindSession for a message whose line starts at a known
+// byte offset (from the watcher's offset index). The file is streamed rather
+// than loaded; if the line at offset isn't the message, it falls back to
+// RewindSession.
+func RewindSessionAt(folder, sessionID, messageUUID string, offset int64) (int, string, error) {
+	sessionPath := claudehome.SessionPath(folder, sessionID)
+
+	file, err := os.Open(sessionPath)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read session file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, "", fmt.Errorf("failed to read session file: %w", err)
+	}
+	reader := bufio.NewReaderSize(file, 64*1024)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("failed to read session file: %w", err)
+	}
+	var event map[string]any
+	if json.Unmarshal(line, &event) != nil || event["uuid"] != messageUUID {
+		file.Close()
+		return RewindSession(folder, sessionID, messageUUID)
+	}
+	cut := offset + int64(len(line))
+
+	// Count the non-empty lines after the message
+	removed := 0
+	inLine := false
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := reader.Read(buf)
+		for _, b := range buf[:n] {
+			if b == '\n' {
+				if inLine {
+					removed++
+				}
+				inLine = false
+			} else {
+				inLine = true
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to read session file: %w", err)
+		}
+	}
+	if inLine {
+		removed++
+	}
+	if removed == 0 {
+		return 0, "", nil // Already the last message
+	}
+
+	backupPath := fmt.Sprintf("%s.%d.bak", sessionPath, time.Now().Unix())
+	if err := copyFile(sessionPath, backupPath); err != nil {
+		return 0, "", fmt.Errorf("failed to back up session file: %w", err)
+	}
+	if err := os.Truncate(sessionPath, cut); err != nil {
+		return 0, "", fmt.Errorf("failed to write rewound session file: %w", err)
+	}
+
+	logger.Infof("RewindSession: removed %d lines from %s after uuid=%s at offset %d (backup %s)", removed, sessionPath, messageUUID, offset, backupPath)
+	return removed, backupPath, nil
+}
+
+// copyFile copies src to a new file at dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// findMessageLine returns the index of the JSONL line whose uuid is messageUUID, or -1.
+func findMessageLine(lines []string, messageUUID string) int {
+	for i, line := range lines {
+		if line == "" || !strings.Contains(line, messageUUID) {
+			continue
+		}
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		if event["uuid"] == messageUUID {
+			return i
+		}
+	}
+	return -1
+}
@@ -3,7 +3,6 @@ package workspace
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -49,7 +48,7 @@ func NewMetaSchemaManager(configPath string) *MetaSchemaManager {
 func DefaultSchema() MetaSchema {
 	var schema MetaSchema
 	if err := json.Unmarshal(defaults.MetaSchemaJSON(), &schema); err != nil {
-		log.Printf("Warning: failed to parse embedded default meta schema: %v", err)
+		logger.Warnf("failed to parse embedded default meta schema: %v", err)
 		return MetaSchema{Version: 1}
 	}
 	return schema
@@ -163,7 +162,7 @@ func (m *MetaSchemaManager) SaveSchema(schema MetaSchema) error {
 			}
 		}
 		if !found {
-			log.Printf("Auto-adding missing system workspace attribute: %s", sysAttr.Name)
+			logger.Infof("Auto-adding missing system workspace attribute: %s", sysAttr.Name)
 			schema.WorkspaceAttributes = append(schema.WorkspaceAttributes, sysAttr)
 		}
 	}
@@ -181,7 +180,7 @@ func (m *MetaSchemaManager) SaveSchema(schema MetaSchema) error {
 			}
 		}
 		if !found {
-			log.Printf("Auto-adding missing system agent attribute: %s", sysAttr.Name)
+			logger.Infof("Auto-adding missing system agent attribute: %s", sysAttr.Name)
 			schema.AgentAttributes = append(schema.AgentAttributes, sysAttr)
 		}
 	}
@@ -3,7 +3,6 @@ package workspace
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,6 +34,7 @@ var allMigrations = []Migration{
 	{8, "fix-agent-slug-description", migrateFixAgentSlugDescription},
 	{9, "add-agent-type-to-schema", migrateAddAgentTypeToSchema},
 	{10, "add-agent-model-attrs-to-schema", migrateAddAgentModelAttrs},
+	{11, "workspaces-v4-to-v5-agent-groups", migrateWorkspacesToV5},
 }
 
 // RunMigrations runs all pending migrations in order.
@@ -46,14 +46,14 @@ func (m *Manager) RunMigrations() error {
 		if mig.Version <= state.LastMigration {
 			continue
 		}
-		log.Printf("Running migration %d: %s", mig.Version, mig.Name)
+		logger.Infof("Running migration %d: %s", mig.Version, mig.Name)
 		if err := mig.Run(m.configPath, m); err != nil {
 			return fmt.Errorf("migration %d (%s) failed: %w", mig.Version, mig.Name, err)
 		}
 		state.LastMigration = mig.Version
 		state.CompletedAt = time.Now().UTC().Format(time.RFC3339)
 		m.saveMigrationState(state)
-		log.Printf("Migration %d complete: %s", mig.Version, mig.Name)
+		logger.Infof("Migration %d complete: %s", mig.Version, mig.Name)
 	}
 
 	return nil
@@ -76,11 +76,11 @@ func (m *Manager) loadMigrationState() MigrationState {
 func (m *Manager) saveMigrationState(state MigrationState) {
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
-		log.Printf("Warning: failed to marshal migration state: %v", err)
+		logger.Warnf("failed to marshal migration state: %v", err)
 		return
 	}
 	if err := os.WriteFile(m.migrationStatePath(), data, 0644); err != nil {
-		log.Printf("Warning: failed to save migration state: %v", err)
+		logger.Warnf("failed to save migration state: %v", err)
 	}
 }
 
@@ -130,7 +130,7 @@ func migrateAgentsV1ToV2(configPath string, m *Manager) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("Migration 1: agents.json v1→v2: %d entries", len(v2.Agents))
+	logger.Infof("Migration 1: agents.json v1→v2: %d entries", len(v2.Agents))
 	return os.WriteFile(agentsPath, data, 0644)
 }
 
@@ -154,7 +154,7 @@ func migrateCurrentJSONToLocal(configPath string, m *Manager) error {
 	if err := os.Rename(oldPath, newPath); err != nil {
 		return fmt.Errorf("failed to move current.json to local/: %w", err)
 	}
-	log.Printf("Migration 2: moved current.json → local/current.json")
+	logger.Infof("Migration 2: moved current.json → local/current.json")
 	return nil
 }
 
@@ -221,7 +221,7 @@ func migratePopulateWorkspaceRegistry(configPath string, m *Manager) error {
 			},
 		}
 		changed = true
-		log.Printf("Migration 3: registered workspace %s → %s", wsID, name)
+		logger.Infof("Migration 3: registered workspace %s → %s", wsID, name)
 	}
 
 	if changed {
@@ -308,7 +308,7 @@ func migrateAgentsCamelCaseToMeta(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 4: agents.json camelCase → ALL_CAPS meta")
+		logger.Infof("Migration 4: agents.json camelCase → ALL_CAPS meta")
 	}
 	return nil
 }
@@ -386,7 +386,7 @@ func migrateWorkspacesCamelCaseToMeta(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 5: workspaces.json camelCase → ALL_CAPS meta")
+		logger.Infof("Migration 5: workspaces.json camelCase → ALL_CAPS meta")
 	}
 	return nil
 }
@@ -446,7 +446,7 @@ func migrateInitMetaIfNilSchemaSystemAttrs(configPath string, m *Manager) error
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 6: ensured system attributes in meta-schema.json")
+		logger.Infof("Migration 6: ensured system attributes in meta-schema.json")
 	}
 	return nil
 }
@@ -490,7 +490,7 @@ func migrateRemoveAgentName(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 7: removed AGENT_NAME from agent registry meta")
+		logger.Infof("Migration 7: removed AGENT_NAME from agent registry meta")
 	}
 
 	// Also remove AGENT_NAME from meta-schema if present
@@ -518,7 +518,7 @@ func migrateRemoveAgentName(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 7: removed AGENT_NAME from meta-schema.json")
+		logger.Infof("Migration 7: removed AGENT_NAME from meta-schema.json")
 	}
 
 	return nil
@@ -556,7 +556,7 @@ func migrateFixAgentSlugDescription(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 8: fixed AGENT_SLUG description, removed AGENT_NAME")
+		logger.Infof("Migration 8: fixed AGENT_SLUG description, removed AGENT_NAME")
 	}
 
 	// Also clean up any remaining AGENT_NAME from agent registry meta
@@ -584,7 +584,7 @@ func migrateFixAgentSlugDescription(configPath string, m *Manager) error {
 		if err != nil {
 			return err
 		}
-		log.Printf("Migration 8: cleaned AGENT_NAME from agent registry")
+		logger.Infof("Migration 8: cleaned AGENT_NAME from agent registry")
 	}
 
 	return nil
@@ -637,7 +637,7 @@ func migrateAddAgentTypeToSchema(configPath string, m *Manager) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("Migration 9: added AGENT_TYPE system attribute to meta-schema")
+	logger.Infof("Migration 9: added AGENT_TYPE system attribute to meta-schema")
 	return nil
 }
 
@@ -692,6 +692,54 @@ func migrateAddAgentModelAttrs(configPath string, m *Manager) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("Migration 10: added %d agent model attributes to meta-schema", len(toAdd))
+	logger.Infof("Migration 10: added %d agent model attributes to meta-schema", len(toAdd))
+	return nil
+}
+
+// =============================================================================
+// Migration 11: Rewrite workspace files as v5 (agent groups)
+// =============================================================================
+
+func migrateWorkspacesToV5(configPath string, m *Manager) error {
+	workspacesDir := filepath.Join(configPath, "workspaces")
+	entries, err := os.ReadDir(workspacesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	upgraded := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(workspacesDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var versionCheck struct {
+			Version int `json:"version"`
+		}
+		if err := json.Unmarshal(raw, &versionCheck); err != nil || versionCheck.Version >= 5 {
+			continue // Corrupt or already v5+
+		}
+
+		ws, err := m.LoadWorkspace(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		// Pre-v4 files are upgraded (agent IDs reconciled) when the workspace is next opened
+		if ws.Version < 4 {
+			continue
+		}
+		m.ExtractRuntimeToStateFile(ws)
+		if err := m.SaveWorkspace(m.UpgradeWorkspaceSchema(ws)); err != nil {
+			return err
+		}
+		upgraded++
+	}
+	logger.Infof("Migration 11: upgraded %d workspaces to v5", upgraded)
 	return nil
 }
@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/types"
+)
+
+// PruneQuerySessions deletes AgentQuery/SelfQuery sessions in a folder whose
+// JSONL file has not been modified for maxAge, along with their sidecar
+// directory ({sessionId}/ with subagents and tool results).
+// Regular sessions are never touched. Returns the deleted session IDs.
+func PruneQuerySessions(folder string, maxAge time.Duration) ([]string, error) {
+	projectDir := claudehome.ProjectDir(folder)
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var deleted []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".jsonl") || strings.HasPrefix(name, "agent-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		filePath := filepath.Join(projectDir, name)
+		if preview, _ := getSessionPreview(filePath); !types.IsQueryPrompt(preview) {
+			continue
+		}
+
+		sessionID := strings.TrimSuffix(name, ".jsonl")
+		if err := os.Remove(filePath); err != nil {
+			logger.Warnf("PruneQuerySessions: failed to delete %s: %v", filePath, err)
+			continue
+		}
+		os.RemoveAll(filepath.Join(projectDir, sessionID))
+		deleted = append(deleted, sessionID)
+	}
+	return deleted, nil
+}
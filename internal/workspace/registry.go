@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"claudefu/internal/fsutil"
 )
 
 // AgentRegistry maintains a global folder→agent info mapping so that the same
@@ -86,17 +88,15 @@ func (r *AgentRegistry) loadLocked() error {
 		r.lastLoadMtime = stat.ModTime()
 	}
 
-	raw, err := os.ReadFile(r.filePath)
-	if err != nil {
+	if err := fsutil.ReadJSON(r.filePath, &r.data); err != nil {
 		if os.IsNotExist(err) {
-			log.Printf("Agent registry not found at %s, starting fresh", r.filePath)
+			logger.Infof("Agent registry not found at %s, starting fresh", r.filePath)
 			return nil
 		}
-		return err
-	}
-
-	if err := json.Unmarshal(raw, &r.data); err != nil {
-		log.Printf("Warning: corrupt agent registry at %s, starting fresh: %v", r.filePath, err)
+		if _, ok := err.(*fs.PathError); ok {
+			return err
+		}
+		logger.Warnf("corrupt agent registry at %s, starting fresh: %v", r.filePath, err)
 		return nil
 	}
 
@@ -112,7 +112,7 @@ func (r *AgentRegistry) loadLocked() error {
 		}
 	}
 
-	log.Printf("Agent registry loaded: %d entries", len(r.data.Agents))
+	logger.Infof("Agent registry loaded: %d entries", len(r.data.Agents))
 	return nil
 }
 
@@ -136,7 +136,7 @@ func (r *AgentRegistry) ReloadIfChanged() error {
 		return nil // Unchanged since last load/save
 	}
 
-	log.Printf("[AgentRegistry] agents.json changed externally (mtime advanced), reloading")
+	logger.Infof("Agent registry: agents.json changed externally (mtime advanced), reloading")
 	return r.loadLocked()
 }
 
@@ -162,7 +162,7 @@ func (r *AgentRegistry) save() error {
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(r.filePath, raw, 0644); err != nil {
+	if err := fsutil.WriteFileWithBackup(r.filePath, raw, 0644); err != nil {
 		return err
 	}
 	// Update mtime tracker so our own save doesn't trigger a self-reload
@@ -231,9 +231,9 @@ func (r *AgentRegistry) GetOrCreateID(folder string) string {
 	id := uuid.New().String()
 	r.data.Agents[folder] = AgentInfo{ID: id}
 	if err := r.save(); err != nil {
-		log.Printf("Warning: failed to persist agent registry: %v", err)
+		logger.Warnf("failed to persist agent registry: %v", err)
 	}
-	log.Printf("Agent registry: new entry %s → %s", folder, id)
+	logger.Infof("Agent registry: new entry %s → %s", folder, id)
 	return id
 }
 
@@ -270,7 +270,7 @@ func (r *AgentRegistry) RegisterID(folder, id string) {
 	}
 	r.data.Agents[folder] = AgentInfo{ID: id}
 	if err := r.save(); err != nil {
-		log.Printf("Warning: failed to persist agent registry after register: %v", err)
+		logger.Warnf("failed to persist agent registry after register: %v", err)
 	}
 }
 
@@ -295,7 +295,7 @@ func (r *AgentRegistry) UpdateAgentSlug(folder, slug string) {
 	if changed {
 		r.data.Agents[folder] = info
 		if err := r.save(); err != nil {
-			log.Printf("Warning: failed to persist agent registry after meta update: %v", err)
+			logger.Warnf("failed to persist agent registry after meta update: %v", err)
 		}
 	}
 }
@@ -433,7 +433,7 @@ func (r *AgentRegistry) SyncAgentIDsFromRegistry(ws *Workspace) map[string]strin
 			oldID := agent.ID
 			agent.ID = info.ID
 			changed[oldID] = info.ID
-			log.Printf("Agent registry: reconciled agent %q (%s → %s)", agent.GetSlug(), oldID, info.ID)
+			logger.Infof("Agent registry: reconciled agent %q (%s → %s)", agent.GetSlug(), oldID, info.ID)
 		}
 
 		// Only populate slug/name if registry doesn't have them yet (first-write-wins).
@@ -452,7 +452,7 @@ func (r *AgentRegistry) SyncAgentIDsFromRegistry(ws *Workspace) map[string]strin
 
 	if len(changed) > 0 || metaUpdated {
 		if err := r.save(); err != nil {
-			log.Printf("Warning: failed to persist agent registry after reconciliation: %v", err)
+			logger.Warnf("failed to persist agent registry after reconciliation: %v", err)
 		}
 	}
 
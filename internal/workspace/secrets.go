@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"claudefu/internal/fsutil"
+	"claudefu/internal/integrations"
+)
+
+// =============================================================================
+// WORKSPACE SECRETS
+// Credentials are kept out of workspaces/{id}.json, which Syncthing syncs and
+// the backup service versions, in local/secrets/{id}.json (0600): the MCP auth
+// and remote answer tokens, Slack/Discord webhook URLs and Slack bot
+// credentials, and per-agent env vars (often API keys). SaveWorkspace splits
+// them off and LoadWorkspace merges them back, so the rest of the app always
+// sees complete workspaces. Secrets still inline in an older workspace file are
+// moved out on its next save.
+// =============================================================================
+
+// workspaceSecrets is the content of local/secrets/{id}.json.
+type workspaceSecrets struct {
+	MCPAuthToken       string                       `json:"mcpAuthToken,omitempty"`
+	MCPRemoteToken     string                       `json:"mcpRemoteToken,omitempty"`
+	SlackWebhookURL    string                       `json:"slackWebhookUrl,omitempty"`
+	SlackBotToken      string                       `json:"slackBotToken,omitempty"`
+	SlackSigningSecret string                       `json:"slackSigningSecret,omitempty"`
+	DiscordWebhookURL  string                       `json:"discordWebhookUrl,omitempty"`
+	AgentEnv           map[string]map[string]string `json:"agentEnv,omitempty"` // Agent ID -> env vars
+}
+
+func (s *workspaceSecrets) empty() bool {
+	return s.MCPAuthToken == "" && s.MCPRemoteToken == "" &&
+		s.SlackWebhookURL == "" && s.SlackBotToken == "" && s.SlackSigningSecret == "" &&
+		s.DiscordWebhookURL == "" && len(s.AgentEnv) == 0
+}
+
+// secretsPath returns the secrets file of a workspace (or of a trash entry,
+// see TrashAgent).
+func (m *Manager) secretsPath(id string) string {
+	return filepath.Join(m.configPath, "local", "secrets", id+".json")
+}
+
+// saveSecrets writes secrets for id, removing the file when there are none.
+func (m *Manager) saveSecrets(id string, secrets *workspaceSecrets) error {
+	path := m.secretsPath(id)
+	if secrets.empty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0600)
+}
+
+// loadSecrets reads the secrets for id (empty if there are none).
+func (m *Manager) loadSecrets(id string) *workspaceSecrets {
+	var secrets workspaceSecrets
+	if err := fsutil.ReadJSON(m.secretsPath(id), &secrets); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("Failed to read secrets for %s: %v", id, err)
+	}
+	return &secrets
+}
+
+// splitSecrets returns copies of ws's MCP config and integrations with their
+// credentials removed, and the credentials. ws is not modified; agent env
+// vars are collected but left for the caller to omit.
+func splitSecrets(ws *Workspace) (*MCPConfig, *integrations.Config, *workspaceSecrets) {
+	secrets := &workspaceSecrets{}
+	mcp := ws.MCPConfig
+	if mcp != nil {
+		stripped := *mcp
+		secrets.MCPAuthToken, stripped.AuthToken = mcp.AuthToken, ""
+		secrets.MCPRemoteToken, stripped.RemoteToken = mcp.RemoteToken, ""
+		mcp = &stripped
+	}
+
+	integ := ws.Integrations
+	if integ != nil {
+		stripped := *integ
+		if s := integ.Slack; s != nil {
+			slack := *s
+			secrets.SlackWebhookURL, slack.WebhookURL = s.WebhookURL, ""
+			secrets.SlackBotToken, slack.BotToken = s.BotToken, ""
+			secrets.SlackSigningSecret, slack.SigningSecret = s.SigningSecret, ""
+			stripped.Slack = &slack
+		}
+		if d := integ.Discord; d != nil {
+			discord := *d
+			secrets.DiscordWebhookURL, discord.WebhookURL = d.WebhookURL, ""
+			stripped.Discord = &discord
+		}
+		integ = &stripped
+	}
+
+	for _, a := range ws.Agents {
+		if len(a.Env) > 0 {
+			if secrets.AgentEnv == nil {
+				secrets.AgentEnv = make(map[string]map[string]string)
+			}
+			secrets.AgentEnv[a.ID] = a.Env
+		}
+	}
+	return mcp, integ, secrets
+}
+
+// mergeSecrets fills ws with the credentials in secrets. Values in secrets win
+// over ones still inline in an older workspace file.
+func mergeSecrets(ws *Workspace, secrets *workspaceSecrets) {
+	if secrets.MCPAuthToken != "" || secrets.MCPRemoteToken != "" {
+		if ws.MCPConfig == nil {
+			ws.MCPConfig = &MCPConfig{}
+		}
+		ws.MCPConfig.AuthToken = overlay(ws.MCPConfig.AuthToken, secrets.MCPAuthToken)
+		ws.MCPConfig.RemoteToken = overlay(ws.MCPConfig.RemoteToken, secrets.MCPRemoteToken)
+	}
+	if secrets.SlackWebhookURL != "" || secrets.SlackBotToken != "" || secrets.SlackSigningSecret != "" {
+		if ws.Integrations == nil {
+			ws.Integrations = &integrations.Config{}
+		}
+		if ws.Integrations.Slack == nil {
+			ws.Integrations.Slack = &integrations.SlackConfig{}
+		}
+		slack := ws.Integrations.Slack
+		slack.WebhookURL = overlay(slack.WebhookURL, secrets.SlackWebhookURL)
+		slack.BotToken = overlay(slack.BotToken, secrets.SlackBotToken)
+		slack.SigningSecret = overlay(slack.SigningSecret, secrets.SlackSigningSecret)
+	}
+	if secrets.DiscordWebhookURL != "" {
+		if ws.Integrations == nil {
+			ws.Integrations = &integrations.Config{}
+		}
+		if ws.Integrations.Discord == nil {
+			ws.Integrations.Discord = &integrations.DiscordConfig{}
+		}
+		ws.Integrations.Discord.WebhookURL = secrets.DiscordWebhookURL
+	}
+	for i := range ws.Agents {
+		if env, ok := secrets.AgentEnv[ws.Agents[i].ID]; ok {
+			ws.Agents[i].Env = maps.Clone(env)
+		}
+	}
+}
+
+// overlay returns secret if set, else current.
+func overlay(current, secret string) string {
+	if secret != "" {
+		return secret
+	}
+	return current
+}
+
+// hasInlineSecrets reports whether the workspace file at path still holds
+// credentials inline (written before they moved to local/secrets).
+func hasInlineSecrets(path string) bool {
+	var ws Workspace
+	if err := fsutil.ReadJSON(path, &ws); err != nil {
+		return false
+	}
+	_, _, secrets := splitSecrets(&ws)
+	return !secrets.empty()
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"claudefu/internal/integrations"
+)
+
+// TestSaveWorkspaceKeepsSecretsLocal checks that credentials never reach the
+// synced workspace file and survive SaveWorkspace + LoadWorkspace, both for
+// new saves and for workspace files that still hold them inline.
+func TestSaveWorkspaceKeepsSecretsLocal(t *testing.T) {
+	secretValues := []string{"mcp-auth", "mcp-remote", "https://hooks.slack.test/x", "xoxb-1", "sign-1", "https://discord.test/x", "sk-key"}
+	newWorkspace := func() *Workspace {
+		return &Workspace{
+			ID:        GenerateWorkspaceID(),
+			Name:      "test",
+			MCPConfig: &MCPConfig{Enabled: true, AuthToken: "mcp-auth", RemoteToken: "mcp-remote"},
+			Integrations: &integrations.Config{
+				Slack:   &integrations.SlackConfig{WebhookURL: "https://hooks.slack.test/x", BotToken: "xoxb-1", SigningSecret: "sign-1", Channel: "#ops"},
+				Discord: &integrations.DiscordConfig{WebhookURL: "https://discord.test/x"},
+			},
+			Agents: []Agent{{ID: "agent-1", Folder: "/src/api", Env: map[string]string{"API_KEY": "sk-key"}}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		legacy bool // workspace file written with inline secrets before the save
+	}{
+		{name: "new save"},
+		{name: "legacy inline secrets", legacy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m := NewManager(dir)
+			ws := newWorkspace()
+			wsPath := filepath.Join(dir, "workspaces", ws.ID+".json")
+			if tt.legacy {
+				data, _ := json.Marshal(ws)
+				if err := os.MkdirAll(filepath.Dir(wsPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(wsPath, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+				loaded, err := m.LoadWorkspace(ws.ID)
+				if err != nil {
+					t.Fatalf("LoadWorkspace legacy: %v", err)
+				}
+				ws = loaded
+			}
+			if err := m.SaveWorkspace(ws); err != nil {
+				t.Fatalf("SaveWorkspace: %v", err)
+			}
+			if ws.MCPConfig.AuthToken != "mcp-auth" || ws.Agents[0].Env["API_KEY"] != "sk-key" {
+				t.Error("SaveWorkspace cleared the in-memory secrets")
+			}
+
+			for _, path := range []string{wsPath, wsPath + ".bak"} {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					continue
+				}
+				for _, secret := range secretValues {
+					if strings.Contains(string(data), secret) {
+						t.Errorf("%s contains %q", filepath.Base(path), secret)
+					}
+				}
+			}
+			info, err := os.Stat(m.secretsPath(ws.ID))
+			if err != nil {
+				t.Fatalf("secrets file: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("secrets file mode = %o, want 600", perm)
+			}
+
+			loaded, err := m.LoadWorkspace(ws.ID)
+			if err != nil {
+				t.Fatalf("LoadWorkspace: %v", err)
+			}
+			want := newWorkspace()
+			if !reflect.DeepEqual(loaded.MCPConfig, want.MCPConfig) {
+				t.Errorf("MCPConfig = %+v, want %+v", loaded.MCPConfig, want.MCPConfig)
+			}
+			if !reflect.DeepEqual(loaded.Integrations, want.Integrations) {
+				t.Errorf("Integrations = %+v, want %+v", loaded.Integrations, want.Integrations)
+			}
+			if !reflect.DeepEqual(loaded.Agents[0].Env, want.Agents[0].Env) {
+				t.Errorf("agent env = %v, want %v", loaded.Agents[0].Env, want.Agents[0].Env)
+			}
+		})
+	}
+}
+
+func TestTrashAgentKeepsEnvLocal(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir)
+	ws := &Workspace{ID: GenerateWorkspaceID(), Name: "test"}
+	agent := Agent{ID: "agent-1", Env: map[string]string{"API_KEY": "sk-key"}}
+
+	entry, err := m.TrashAgent(ws, agent, 0)
+	if err != nil {
+		t.Fatalf("TrashAgent: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "trash", entry.ID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-key") {
+		t.Error("trash entry contains the agent env")
+	}
+
+	got, err := m.GetTrashEntry(entry.ID)
+	if err != nil {
+		t.Fatalf("GetTrashEntry: %v", err)
+	}
+	if got.Agent.Env["API_KEY"] != "sk-key" {
+		t.Errorf("restored env = %v, want API_KEY", got.Agent.Env)
+	}
+
+	m.RemoveTrashEntry(entry.ID)
+	if _, err := os.Stat(m.secretsPath(trashSecretsID(entry.ID))); !os.IsNotExist(err) {
+		t.Errorf("secrets kept after RemoveTrashEntry (stat err %v)", err)
+	}
+}
@@ -0,0 +1,198 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/fsutil"
+)
+
+// =============================================================================
+// SESSION ARCHIVE - Move or delete Claude Code session files
+// =============================================================================
+
+// Archived sessions are moved (JSONL plus the {sessionId}/ sidecar directory with
+// subagents and tool results) to ~/.claudefu/local/session-archive/{encoded-folder}/.
+// They live under local/ because ~/.claude/projects is per-machine too. Claude Code's
+// sessions-index.json is updated so /resume no longer lists them. Moves fall back
+// to copy and remove when ~/.claudefu and ~/.claude are on different filesystems.
+
+// SessionsIndexFile is Claude Code's per-project session index.
+const SessionsIndexFile = "sessions-index.json"
+
+// ArchivedSession describes a session in the archive.
+type ArchivedSession struct {
+	SessionID  string    `json:"sessionId"`
+	Preview    string    `json:"preview"`
+	ArchivedAt time.Time `json:"archivedAt"` // When it was moved (file mtime is kept for LastModified)
+	Size       int64     `json:"size"`
+}
+
+// sessionArchiveDir returns the archive directory for an agent folder.
+func (m *Manager) sessionArchiveDir(folder string) string {
+	return filepath.Join(m.configPath, "local", "session-archive", claudehome.EncodeProjectPath(folder))
+}
+
+// ArchiveSession moves a session out of Claude Code's project directory into the archive.
+func (m *Manager) ArchiveSession(folder, sessionID string) error {
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+	src := claudehome.SessionPath(folder, sessionID)
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	archiveDir := m.sessionArchiveDir(folder)
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return fmt.Errorf("failed to create session archive: %w", err)
+	}
+	if err := fsutil.Move(src, filepath.Join(archiveDir, sessionID+".jsonl")); err != nil {
+		return fmt.Errorf("failed to archive session: %w", err)
+	}
+	// Record archive time separately; the JSONL keeps its mtime
+	now := time.Now()
+	os.WriteFile(filepath.Join(archiveDir, sessionID+".archived"), []byte(now.Format(time.RFC3339)), 0644)
+
+	sidecar := filepath.Join(claudehome.ProjectDir(folder), sessionID)
+	if _, err := os.Stat(sidecar); err == nil {
+		if err := fsutil.Move(sidecar, filepath.Join(archiveDir, sessionID)); err != nil {
+			logger.Warnf("ArchiveSession: failed to move %s: %v", sidecar, err)
+		}
+	}
+
+	removeFromSessionsIndex(folder, sessionID)
+	return nil
+}
+
+// DeleteSession permanently deletes a session's JSONL file and sidecar directory.
+func (m *Manager) DeleteSession(folder, sessionID string) error {
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+	path := claudehome.SessionPath(folder, sessionID)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("session not found: %s", sessionID)
+		}
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	os.RemoveAll(filepath.Join(claudehome.ProjectDir(folder), sessionID))
+
+	removeFromSessionsIndex(folder, sessionID)
+	return nil
+}
+
+// ListArchivedSessions returns an agent folder's archived sessions, most recently archived first.
+func (m *Manager) ListArchivedSessions(folder string) ([]ArchivedSession, error) {
+	archiveDir := m.sessionArchiveDir(folder)
+	paths, err := filepath.Glob(filepath.Join(archiveDir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []ArchivedSession{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		preview, _ := getSessionPreview(path)
+		archivedAt := info.ModTime()
+		if data, err := os.ReadFile(filepath.Join(archiveDir, sessionID+".archived")); err == nil {
+			if t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data))); err == nil {
+				archivedAt = t
+			}
+		}
+		sessions = append(sessions, ArchivedSession{
+			SessionID:  sessionID,
+			Preview:    preview,
+			ArchivedAt: archivedAt,
+			Size:       info.Size(),
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ArchivedAt.After(sessions[j].ArchivedAt)
+	})
+	return sessions, nil
+}
+
+// RestoreArchivedSession moves an archived session back into Claude Code's project directory.
+func (m *Manager) RestoreArchivedSession(folder, sessionID string) error {
+	if err := claudehome.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+	archiveDir := m.sessionArchiveDir(folder)
+	src := filepath.Join(archiveDir, sessionID+".jsonl")
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("archived session not found: %s", sessionID)
+	}
+	dst := claudehome.SessionPath(folder, sessionID)
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("session %s already exists", sessionID)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := fsutil.Move(src, dst); err != nil {
+		return fmt.Errorf("failed to restore session: %w", err)
+	}
+	os.Remove(filepath.Join(archiveDir, sessionID+".archived"))
+
+	sidecar := filepath.Join(archiveDir, sessionID)
+	if _, err := os.Stat(sidecar); err == nil {
+		if err := fsutil.Move(sidecar, filepath.Join(claudehome.ProjectDir(folder), sessionID)); err != nil {
+			logger.Warnf("RestoreArchivedSession: failed to move %s: %v", sidecar, err)
+		}
+	}
+	return nil
+}
+
+// removeFromSessionsIndex drops a session's entry from Claude Code's
+// sessions-index.json, if the file exists. Unknown fields are preserved.
+func removeFromSessionsIndex(folder, sessionID string) {
+	path := filepath.Join(claudehome.ProjectDir(folder), SessionsIndexFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var index map[string]any
+	if err := json.Unmarshal(data, &index); err != nil {
+		logger.Warnf("Failed to parse %s: %v", path, err)
+		return
+	}
+	entries, ok := index["entries"].([]any)
+	if !ok {
+		return
+	}
+
+	kept := make([]any, 0, len(entries))
+	for _, e := range entries {
+		if entry, ok := e.(map[string]any); ok && entry["sessionId"] == sessionID {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(kept) == len(entries) {
+		return
+	}
+	index["entries"] = kept
+
+	out, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := fsutil.WriteFileAtomic(path, out, 0644); err != nil {
+		logger.Warnf("Failed to update %s: %v", path, err)
+	}
+}
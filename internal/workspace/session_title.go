@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"claudefu/internal/claudehome"
+	"claudefu/internal/types"
+)
+
+// SessionExchange is the opening of a session: the first user prompt and the
+// assistant's text reply to it.
+type SessionExchange struct {
+	UserPrompt    string
+	AssistantText string
+	UserTurns     int // User prompts seen, stopping at 2 (tool results and compactions excluded)
+}
+
+// maxExchangeChars caps each side of a SessionExchange
+const maxExchangeChars = 4000
+
+// ReadFirstExchange reads a session's first prompt and reply from its JSONL.
+// It stops at the second user prompt, so long sessions are not read in full.
+func ReadFirstExchange(folder, sessionID string) (SessionExchange, error) {
+	var ex SessionExchange
+	f, err := os.Open(claudehome.SessionPath(folder, sessionID))
+	if err != nil {
+		return ex, err
+	}
+	defer f.Close()
+
+	var reply strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+scan:
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		classified, err := types.ClassifyJSONLEvent(line)
+		if err != nil {
+			continue
+		}
+		msg := types.ConvertToMessage(classified)
+		if msg == nil || msg.IsSynthetic || msg.IsCompaction {
+			continue
+		}
+		switch msg.Type {
+		case "user":
+			ex.UserTurns++
+			if ex.UserTurns > 1 {
+				break scan // The first exchange is complete
+			}
+			ex.UserPrompt = truncateRunes(msg.Content, maxExchangeChars)
+		case "assistant":
+			if ex.UserTurns == 1 && msg.Content != "" && reply.Len() < maxExchangeChars {
+				if reply.Len() > 0 {
+					reply.WriteString("\n")
+				}
+				reply.WriteString(msg.Content)
+			}
+		}
+	}
+	ex.AssistantText = truncateRunes(reply.String(), maxExchangeChars)
+	return ex, scanner.Err()
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
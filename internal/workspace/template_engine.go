@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"claudefu/internal/claudehome"
 )
 
 // ProcessTemplate replaces {{ KEY }} placeholders with values from the provided map.
@@ -118,7 +120,7 @@ func (m *Manager) GenerateSifuClaudeMD(ws *Workspace, sifuFolder string) error {
 		return fmt.Errorf("failed to write sifu CLAUDE.md: %w", err)
 	}
 
-	fmt.Printf("[INFO] Generated Sifu CLAUDE.md at %s (%d agents, %d TDA refs)\n",
+	logger.Infof("Generated Sifu CLAUDE.md at %s (%d agents, %d TDA refs)",
 		outputPath, len(agentSections), len(allTdaRefs))
 	return nil
 }
@@ -158,8 +160,7 @@ func (m *Manager) collectAgentMeta(folder string) map[string]string {
 	}
 
 	// Derive Claude project folder
-	encoded := regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(folder, "-")
-	values["AGENT_CLAUDE_PROJECT_FOLDER"] = "~/.claude/projects/" + encoded + "/"
+	values["AGENT_CLAUDE_PROJECT_FOLDER"] = "~/.claude/projects/" + claudehome.EncodeProjectPath(folder) + "/"
 
 	return values
 }
@@ -331,7 +332,7 @@ func (m *Manager) GenerateSifuPermissions(ws *Workspace, sifuFolder string) erro
 		return fmt.Errorf("failed to write sifu permissions: %w", err)
 	}
 
-	fmt.Printf("[INFO] Generated Sifu permissions at %s (%d dirs)\n", permsPath, len(dirs))
+	logger.Infof("Generated Sifu permissions at %s (%d dirs)", permsPath, len(dirs))
 	return nil
 }
 
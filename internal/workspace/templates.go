@@ -0,0 +1,327 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"claudefu/internal/permissions"
+)
+
+// =============================================================================
+// WORKSPACE TEMPLATES
+// A template is a reusable copy of a workspace's agents and config, stored in
+// ~/.claudefu/templates/{name}.json. Creating a workspace from it maps each
+// template folder to a new one (e.g. ~/work/shop-a/api -> ~/work/shop-b/api).
+// =============================================================================
+
+// TemplateAgent is an agent as recorded in a template.
+type TemplateAgent struct {
+	Folder         string   `json:"folder"` // Folder in the source workspace
+	Slug           string   `json:"slug"`
+	WatchMode      string   `json:"watchMode,omitempty"`
+	MCPEnabled     *bool    `json:"mcpEnabled,omitempty"`
+	PostProcessors []string `json:"postProcessors,omitempty"`
+	Specialization string   `json:"specialization,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	ClaudeCommand  string   `json:"claudeCommand,omitempty"`
+	ClaudeArgs     []string `json:"claudeArgs,omitempty"`
+
+	DefaultPermissionMode string `json:"defaultPermissionMode,omitempty"`
+
+	// Agent-specific ClaudeFu permissions (nil = agent used the global ones)
+	Permissions *permissions.ClaudeFuPermissions `json:"permissions,omitempty"`
+}
+
+// WorkspaceTemplate is a saved workspace blueprint.
+type WorkspaceTemplate struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Agents        []TemplateAgent `json:"agents"`
+	MCPConfig     *MCPConfig      `json:"mcpConfig,omitempty"`
+	EnvProfiles   []EnvProfile    `json:"envProfiles,omitempty"`
+	ActiveProfile string          `json:"activeProfile,omitempty"`
+}
+
+// WorkspaceTemplateSummary is a minimal reference for listing templates.
+type WorkspaceTemplateSummary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	AgentCount  int       `json:"agentCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// templatesDir returns ~/.claudefu/templates.
+func (m *Manager) templatesDir() string {
+	return filepath.Join(m.configPath, "templates")
+}
+
+func (m *Manager) templatePath(name string) string {
+	return filepath.Join(m.templatesDir(), sanitizeFilename(name)+".json")
+}
+
+// NewTemplateFromWorkspace builds a template from a workspace. The Sifu agent is
+// left out (every workspace gets its own). Permissions are filled by the caller.
+func NewTemplateFromWorkspace(ws *Workspace, name, description string) *WorkspaceTemplate {
+	tmpl := &WorkspaceTemplate{
+		Name:          name,
+		Description:   description,
+		CreatedAt:     time.Now(),
+		Agents:        []TemplateAgent{},
+		EnvProfiles:   ws.EnvProfiles,
+		ActiveProfile: ws.ActiveProfile,
+	}
+	if ws.MCPConfig != nil {
+		cfg := *ws.MCPConfig
+		cfg.RemoteToken = "" // Secrets stay with the workspace
+		cfg.AuthToken = ""
+		tmpl.MCPConfig = &cfg
+	}
+	for _, a := range ws.Agents {
+		if a.IsSifu() {
+			continue
+		}
+		tmpl.Agents = append(tmpl.Agents, TemplateAgent{
+			Folder:         a.Folder,
+			Slug:           a.GetSlug(),
+			WatchMode:      a.WatchMode,
+			MCPEnabled:     a.MCPEnabled,
+			PostProcessors: a.PostProcessors,
+			Specialization: a.Specialization,
+			Tags:           a.Tags,
+			ClaudeCommand:  a.ClaudeCommand,
+			ClaudeArgs:     a.ClaudeArgs,
+
+			DefaultPermissionMode: a.DefaultPermissionMode,
+		})
+	}
+	return tmpl
+}
+
+// SaveWorkspaceTemplate writes a template, replacing one with the same name.
+func (m *Manager) SaveWorkspaceTemplate(tmpl *WorkspaceTemplate) error {
+	if strings.TrimSpace(tmpl.Name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if err := os.MkdirAll(m.templatesDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tmpl, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.templatePath(tmpl.Name), data, 0644)
+}
+
+// GetWorkspaceTemplate loads a template by name.
+func (m *Manager) GetWorkspaceTemplate(name string) (*WorkspaceTemplate, error) {
+	data, err := os.ReadFile(m.templatePath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("template not found: %s", name)
+		}
+		return nil, err
+	}
+	var tmpl WorkspaceTemplate
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return &tmpl, nil
+}
+
+// ListWorkspaceTemplates returns all saved templates, sorted by name.
+func (m *Manager) ListWorkspaceTemplates() ([]WorkspaceTemplateSummary, error) {
+	entries, err := os.ReadDir(m.templatesDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []WorkspaceTemplateSummary{}, nil
+		}
+		return nil, err
+	}
+
+	result := []WorkspaceTemplateSummary{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(m.templatesDir(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var tmpl WorkspaceTemplate
+		if err := json.Unmarshal(data, &tmpl); err != nil {
+			logger.Warnf("Skipping unreadable template %s: %v", entry.Name(), err)
+			continue
+		}
+		result = append(result, WorkspaceTemplateSummary{
+			Name:        tmpl.Name,
+			Description: tmpl.Description,
+			AgentCount:  len(tmpl.Agents),
+			CreatedAt:   tmpl.CreatedAt,
+		})
+	}
+	slices.SortFunc(result, func(a, b WorkspaceTemplateSummary) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return result, nil
+}
+
+// DeleteWorkspaceTemplate removes a template by name.
+func (m *Manager) DeleteWorkspaceTemplate(name string) error {
+	if err := os.Remove(m.templatePath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("template not found: %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// MapTemplateFolder rewrites a template folder using folderMap. An exact key
+// wins; otherwise the longest key that is a parent directory of folder is
+// replaced (so mapping one project root moves every agent under it).
+// Unmapped folders are returned unchanged.
+func MapTemplateFolder(folder string, folderMap map[string]string) string {
+	if mapped, ok := folderMap[folder]; ok && mapped != "" {
+		return filepath.Clean(mapped)
+	}
+	bestFrom, bestTo := "", ""
+	for from, to := range folderMap {
+		from = filepath.Clean(from)
+		if to == "" || !strings.HasPrefix(folder, from+string(filepath.Separator)) {
+			continue
+		}
+		if len(from) > len(bestFrom) {
+			bestFrom, bestTo = from, to
+		}
+	}
+	if bestFrom == "" {
+		return folder
+	}
+	return filepath.Join(bestTo, strings.TrimPrefix(folder, bestFrom))
+}
+
+// CreateWorkspaceFromTemplate creates a workspace (and makes it current, like
+// CreateWorkspace) with the template's agents at their mapped folders. Every
+// mapped folder must exist. Slugs come from the agent registry when the folder
+// is already known, otherwise the template slug, made unique if another folder
+// already uses it.
+func (m *Manager) CreateWorkspaceFromTemplate(tmpl *WorkspaceTemplate, name string, folderMap map[string]string) (*Workspace, error) {
+	folders := make([]string, len(tmpl.Agents))
+	var missing []string
+	for i, ta := range tmpl.Agents {
+		folders[i] = MapTemplateFolder(ta.Folder, folderMap)
+		if info, err := os.Stat(folders[i]); err != nil || !info.IsDir() {
+			missing = append(missing, folders[i])
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("folders not found: %s", strings.Join(missing, ", "))
+	}
+
+	ws, err := m.CreateWorkspace(name)
+	if err != nil {
+		return nil, err
+	}
+	ws.MCPConfig = tmpl.MCPConfig
+	ws.EnvProfiles = tmpl.EnvProfiles
+	ws.ActiveProfile = tmpl.ActiveProfile
+
+	for i, ta := range tmpl.Agents {
+		folder := folders[i]
+		if HasAgentWithFolder(ws, folder) {
+			continue
+		}
+		agent := Agent{
+			ID:             m.GetOrCreateAgentID(folder),
+			Folder:         folder,
+			WatchMode:      ta.WatchMode,
+			MCPEnabled:     ta.MCPEnabled,
+			PostProcessors: ta.PostProcessors,
+			Specialization: ta.Specialization,
+			Tags:           ta.Tags,
+			ClaudeCommand:  ta.ClaudeCommand,
+			ClaudeArgs:     ta.ClaudeArgs,
+
+			DefaultPermissionMode: ta.DefaultPermissionMode,
+		}
+		if info := m.GetAgentInfo(folder); info != nil && info.GetSlug() != "" {
+			agent.Slug = info.GetSlug()
+		} else {
+			agent.Slug = m.UniqueAgentSlug(ws, ta.Slug, folder)
+			m.UpdateAgentSlug(folder, agent.Slug)
+		}
+		ws.Agents = append(ws.Agents, agent)
+	}
+
+	if err := m.SaveWorkspace(ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// UniqueAgentSlug returns slug, or slug-2, slug-3, ... if it is taken in ws
+// or by another folder in the agent registry.
+func (m *Manager) UniqueAgentSlug(ws *Workspace, slug, folder string) string {
+	if slug == "" {
+		slug = Slugify(filepath.Base(folder))
+	}
+	taken := func(s string) bool {
+		for _, other := range ws.Agents {
+			if strings.EqualFold(other.GetSlug(), s) {
+				return true
+			}
+		}
+		info, owner := m.FindAgentBySlug(s)
+		return info != nil && owner != folder
+	}
+	candidate := slug
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", slug, n)
+	}
+	return candidate
+}
+
+// DuplicateWorkspace copies a workspace's agents and config into a new
+// workspace. The Sifu agent is not copied (each workspace gets its own), and
+// the current workspace is not changed.
+func (m *Manager) DuplicateWorkspace(id, newName string) (*Workspace, error) {
+	src, err := m.LoadWorkspace(id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(newName) == "" {
+		newName = src.Name + " (copy)"
+	}
+
+	dup := &Workspace{
+		ID:            GenerateWorkspaceID(),
+		Name:          newName,
+		Agents:        slices.DeleteFunc(slices.Clone(src.Agents), func(a Agent) bool { return a.IsSifu() }),
+		EnvProfiles:   slices.Clone(src.EnvProfiles),
+		ActiveProfile: src.ActiveProfile,
+	}
+	if src.MCPConfig != nil {
+		cfg := *src.MCPConfig
+		cfg.AuthToken = "" // Each workspace gets its own tokens (App.DuplicateWorkspace issues a remote token)
+		cfg.RemoteToken = ""
+		dup.MCPConfig = &cfg
+	}
+	if err := m.SaveWorkspace(dup); err != nil {
+		return nil, err
+	}
+
+	state := &WorkspaceState{LastOpened: time.Now()}
+	if err := m.SaveWorkspaceState(dup.ID, state); err != nil {
+		logger.Warnf("Failed to save initial workspace state: %v", err)
+	}
+	if m.workspaceRegistry != nil {
+		m.workspaceRegistry.GetOrCreateInfo(dup.ID, dup.Name)
+	}
+	return dup, nil
+}
@@ -0,0 +1,287 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// TRASH - Soft delete for agents and workspaces
+// =============================================================================
+
+// Trash entries live in ~/.claudefu/trash/{entryID}.json until restored or
+// purged. Agents only need their workspace membership recorded (identity stays
+// in agents.json and session files are never touched); workspaces snapshot the
+// raw workspace JSON, registry meta, and local state file so they can be
+// rebuilt byte-for-byte. Credentials stay out of the trash dir (it is synced
+// like the rest of the config): a trashed agent's env vars go to
+// local/secrets/trash-{entryID}.json, and a trashed workspace keeps its
+// local/secrets/{id}.json until the entry is restored or removed.
+
+// DefaultTrashRetention is how long trashed items are kept before purge.
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// Trash entry kinds
+const (
+	TrashKindAgent     = "agent"
+	TrashKindWorkspace = "workspace"
+)
+
+// TrashEntry describes a soft-deleted agent or workspace.
+type TrashEntry struct {
+	ID            string    `json:"id"`
+	Kind          string    `json:"kind"` // "agent" or "workspace"
+	DeletedAt     time.Time `json:"deletedAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	WorkspaceID   string    `json:"workspaceId"`
+	WorkspaceName string    `json:"workspaceName"`
+
+	// Agent entries
+	Agent      *Agent `json:"agent,omitempty"`
+	AgentIndex int    `json:"agentIndex,omitempty"` // Position in the workspace agent list
+
+	// Workspace entries
+	WorkspaceJSON  json.RawMessage `json:"workspaceJson,omitempty"`  // Raw workspaces/{id}.json
+	WorkspaceMeta  *WorkspaceInfo  `json:"workspaceMeta,omitempty"`  // Registry entry from workspaces.json
+	WorkspaceState json.RawMessage `json:"workspaceState,omitempty"` // Raw local/workspace-state/{id}.json
+}
+
+// trashDir returns the trash directory path.
+func (m *Manager) trashDir() string {
+	return filepath.Join(m.configPath, "trash")
+}
+
+// TrashAgent records an agent removed from a workspace so it can be restored.
+// The caller is responsible for removing the agent from ws and saving.
+func (m *Manager) TrashAgent(ws *Workspace, agent Agent, index int) (*TrashEntry, error) {
+	now := time.Now()
+	env := agent.Env
+	agent.Env = nil
+	entry := &TrashEntry{
+		ID:            uuid.New().String(),
+		Kind:          TrashKindAgent,
+		DeletedAt:     now,
+		ExpiresAt:     now.Add(DefaultTrashRetention),
+		WorkspaceID:   ws.ID,
+		WorkspaceName: ws.Name,
+		Agent:         &agent,
+		AgentIndex:    index,
+	}
+	secrets := &workspaceSecrets{}
+	if len(env) > 0 {
+		secrets.AgentEnv = map[string]map[string]string{agent.ID: env}
+	}
+	if err := m.saveSecrets(trashSecretsID(entry.ID), secrets); err != nil {
+		return nil, fmt.Errorf("failed to save agent env: %w", err)
+	}
+	if err := m.saveTrashEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// TrashWorkspace snapshots a workspace into the trash and then deletes it.
+// Same preconditions as DeleteWorkspace (cannot trash the only workspace).
+func (m *Manager) TrashWorkspace(id string) (*TrashEntry, error) {
+	wsPath := filepath.Join(m.configPath, "workspaces", id+".json")
+	if hasInlineSecrets(wsPath) {
+		// Move credentials to local/secrets before the file is copied into the trash
+		if ws, err := m.LoadWorkspace(id); err == nil {
+			if err := m.SaveWorkspace(ws); err != nil {
+				return nil, fmt.Errorf("failed to move workspace secrets: %w", err)
+			}
+		}
+	}
+	raw, err := os.ReadFile(wsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+
+	now := time.Now()
+	entry := &TrashEntry{
+		ID:            uuid.New().String(),
+		Kind:          TrashKindWorkspace,
+		DeletedAt:     now,
+		ExpiresAt:     now.Add(DefaultTrashRetention),
+		WorkspaceID:   id,
+		WorkspaceJSON: raw,
+		WorkspaceMeta: m.GetWorkspaceMeta(id),
+	}
+	if entry.WorkspaceMeta != nil {
+		entry.WorkspaceName = entry.WorkspaceMeta.GetName()
+	}
+	if entry.WorkspaceName == "" {
+		var ws Workspace
+		if json.Unmarshal(raw, &ws) == nil {
+			entry.WorkspaceName = ws.Name
+		}
+	}
+	statePath := filepath.Join(m.configPath, "local", "workspace-state", id+".json")
+	if state, err := os.ReadFile(statePath); err == nil {
+		entry.WorkspaceState = state
+	}
+
+	// Write the trash entry BEFORE deleting so a failure never loses data
+	if err := m.saveTrashEntry(entry); err != nil {
+		return nil, err
+	}
+	if err := m.DeleteWorkspace(id); err != nil {
+		m.RemoveTrashEntry(entry.ID)
+		return nil, err
+	}
+	m.DeleteWorkspaceState(id)
+
+	return entry, nil
+}
+
+// RestoreWorkspace rebuilds a trashed workspace (file, registry entry, local state)
+// and removes the trash entry. Returns the restored workspace.
+func (m *Manager) RestoreWorkspace(entryID string) (*Workspace, error) {
+	entry, err := m.GetTrashEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Kind != TrashKindWorkspace {
+		return nil, fmt.Errorf("trash entry %s is not a workspace", entryID)
+	}
+
+	wsPath := filepath.Join(m.configPath, "workspaces", entry.WorkspaceID+".json")
+	if _, err := os.Stat(wsPath); err == nil {
+		return nil, fmt.Errorf("workspace already exists: %s", entry.WorkspaceID)
+	}
+	if err := os.WriteFile(wsPath, entry.WorkspaceJSON, 0644); err != nil {
+		return nil, fmt.Errorf("failed to restore workspace file: %w", err)
+	}
+
+	if m.workspaceRegistry != nil {
+		if entry.WorkspaceMeta != nil {
+			m.workspaceRegistry.Put(*entry.WorkspaceMeta)
+		} else {
+			m.workspaceRegistry.GetOrCreateInfo(entry.WorkspaceID, entry.WorkspaceName)
+		}
+	}
+
+	if len(entry.WorkspaceState) > 0 {
+		statePath := filepath.Join(m.configPath, "local", "workspace-state", entry.WorkspaceID+".json")
+		if err := os.WriteFile(statePath, entry.WorkspaceState, 0644); err != nil {
+			logger.Warnf("RestoreWorkspace: failed to restore state file: %v", err)
+		}
+	}
+
+	ws, err := m.LoadWorkspace(entry.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	m.RemoveTrashEntry(entryID)
+	return ws, nil
+}
+
+// GetTrashEntry loads a single trash entry by ID, including a trashed agent's
+// env vars.
+func (m *Manager) GetTrashEntry(entryID string) (*TrashEntry, error) {
+	entry, err := m.readTrashEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Agent != nil {
+		secrets := m.loadSecrets(trashSecretsID(entryID))
+		if env, ok := secrets.AgentEnv[entry.Agent.ID]; ok {
+			entry.Agent.Env = env
+		}
+	}
+	return entry, nil
+}
+
+// readTrashEntry loads a trash entry as stored (without agent env vars).
+func (m *Manager) readTrashEntry(entryID string) (*TrashEntry, error) {
+	data, err := os.ReadFile(filepath.Join(m.trashDir(), entryID+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("trash entry not found: %s", entryID)
+		}
+		return nil, err
+	}
+	var entry TrashEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse trash entry %s: %w", entryID, err)
+	}
+	return &entry, nil
+}
+
+// ListTrash returns all trash entries, most recently deleted first.
+func (m *Manager) ListTrash() []TrashEntry {
+	entries := []TrashEntry{} // Initialize as empty slice, not nil (nil becomes JSON null)
+
+	files, err := os.ReadDir(m.trashDir())
+	if err != nil {
+		return entries
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		entry, err := m.readTrashEntry(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries
+}
+
+// RemoveTrashEntry deletes a trash entry permanently, along with its
+// credentials unless it was restored.
+func (m *Manager) RemoveTrashEntry(entryID string) {
+	if entry, err := m.readTrashEntry(entryID); err == nil && entry.Kind == TrashKindWorkspace {
+		wsPath := filepath.Join(m.configPath, "workspaces", entry.WorkspaceID+".json")
+		if _, err := os.Stat(wsPath); os.IsNotExist(err) {
+			os.Remove(m.secretsPath(entry.WorkspaceID))
+		}
+	}
+	os.Remove(m.secretsPath(trashSecretsID(entryID)))
+	os.Remove(filepath.Join(m.trashDir(), entryID+".json")) // Ignore error if already gone
+}
+
+// trashSecretsID names the secrets file of a trash entry.
+func trashSecretsID(entryID string) string {
+	return "trash-" + entryID
+}
+
+// PurgeExpiredTrash removes entries past their expiry. Returns the number purged.
+func (m *Manager) PurgeExpiredTrash() int {
+	now := time.Now()
+	purged := 0
+	for _, entry := range m.ListTrash() {
+		if now.After(entry.ExpiresAt) {
+			m.RemoveTrashEntry(entry.ID)
+			purged++
+		}
+	}
+	return purged
+}
+
+// saveTrashEntry writes a trash entry to disk.
+func (m *Manager) saveTrashEntry(entry *TrashEntry) error {
+	if err := os.MkdirAll(m.trashDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create trash dir: %w", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.trashDir(), entry.ID+".json"), data, 0644)
+}
@@ -0,0 +1,122 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WhosWhoFileName is the generated collaborator list written to each agent's .claude dir.
+// Agents include it from their CLAUDE.md with: @.claude/claudefu.agents.md
+const WhosWhoFileName = "claudefu.agents.md"
+
+// whosWhoHeader opens every generated file; RemoveWhosWho only deletes files that have it.
+const whosWhoHeader = "<!-- Generated by ClaudeFu — do not edit, changes are overwritten. -->"
+
+// GenerateWhosWho writes {folder}/.claude/claudefu.agents.md for every agent in the
+// workspace, describing all MCP-enabled agents (slug, folder, specialization, tags, description)
+// so agents know their collaborators without relying solely on tool descriptions.
+// Files are only rewritten when their content changes. Per-agent failures are logged.
+func (m *Manager) GenerateWhosWho(ws *Workspace) error {
+	if ws == nil {
+		return fmt.Errorf("workspace is nil")
+	}
+
+	wsName := ws.Name
+	if info := m.GetWorkspaceMeta(ws.ID); info != nil && info.GetName() != "" {
+		wsName = info.GetName()
+	}
+
+	written := 0
+	for _, agent := range ws.Agents {
+		if agent.Folder == "" {
+			continue
+		}
+		if info, err := os.Stat(agent.Folder); err != nil || !info.IsDir() {
+			continue // Folder missing (unmounted drive, moved project) — nothing to write into
+		}
+
+		content := m.buildWhosWho(ws, wsName, agent.ID)
+		claudeDir := filepath.Join(agent.Folder, ".claude")
+		target := filepath.Join(claudeDir, WhosWhoFileName)
+		if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+			continue
+		}
+
+		if err := os.MkdirAll(claudeDir, 0755); err != nil {
+			logger.Warnf("GenerateWhosWho: failed to create %s: %v", claudeDir, err)
+			continue
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			logger.Warnf("GenerateWhosWho: failed to write %s: %v", target, err)
+			continue
+		}
+		written++
+	}
+
+	if written > 0 {
+		logger.Infof("Generated %s for %d agents in workspace %s", WhosWhoFileName, written, wsName)
+	}
+	return nil
+}
+
+// RemoveWhosWho deletes the generated collaborator list from an agent folder
+// (e.g. after the agent is removed from its workspace). A file that doesn't
+// carry the generated header was written by hand and is left alone.
+func RemoveWhosWho(folder string) error {
+	target := filepath.Join(folder, ".claude", WhosWhoFileName)
+	content, err := os.ReadFile(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(content, []byte(whosWhoHeader)) {
+		return nil
+	}
+	return os.Remove(target)
+}
+
+// buildWhosWho renders the collaborator list as seen by selfID (its own entry is marked).
+func (m *Manager) buildWhosWho(ws *Workspace, wsName, selfID string) []byte {
+	var sb strings.Builder
+	sb.WriteString(whosWhoHeader + "\n\n")
+	sb.WriteString(fmt.Sprintf("# Workspace Agents: %s\n\n", wsName))
+	sb.WriteString("These agents share this ClaudeFu workspace. Use the claudefu MCP tools ")
+	sb.WriteString("(AgentQuery, AgentMessage, AgentBroadcast) with an agent's slug to collaborate.\n")
+
+	for _, agent := range ws.Agents {
+		if !agent.GetMCPEnabled() {
+			continue
+		}
+
+		description := agent.Description
+		if info := m.GetAgentInfo(agent.Folder); info != nil && info.Meta["AGENT_DESCRIPTION"] != "" {
+			description = info.Meta["AGENT_DESCRIPTION"] // Registry is fresher than the in-memory copy
+		}
+
+		heading := agent.GetSlug()
+		if agent.ID == selfID {
+			heading += " (you)"
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", heading))
+		sb.WriteString(fmt.Sprintf("- **Folder:** `%s`\n", agent.Folder))
+		if agent.IsSifu() {
+			sb.WriteString("- **Role:** Sifu (workspace orchestrator)\n")
+		}
+		if agent.Specialization != "" {
+			sb.WriteString(fmt.Sprintf("- **Specialization:** %s\n", agent.Specialization))
+		}
+		if len(agent.Tags) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Tags:** %s\n", strings.Join(agent.Tags, ", ")))
+		}
+		if description != "" {
+			sb.WriteString(fmt.Sprintf("- **Description:** %s\n", description))
+		}
+	}
+
+	return []byte(sb.String())
+}
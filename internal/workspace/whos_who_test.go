@@ -0,0 +1,67 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateWhosWhoWritesOnlyOnChange(t *testing.T) {
+	m := NewManager(t.TempDir())
+	folder := t.TempDir()
+	ws := &Workspace{ID: GenerateWorkspaceID(), Name: "test", Agents: []Agent{{ID: GenerateAgentID(), Slug: "api", Folder: folder}}}
+	target := filepath.Join(folder, ".claude", WhosWhoFileName)
+
+	if err := m.GenerateWhosWho(ws); err != nil {
+		t.Fatalf("GenerateWhosWho: %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(target, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.GenerateWhosWho(ws); err != nil {
+		t.Fatalf("GenerateWhosWho: %v", err)
+	}
+	if info, err := os.Stat(target); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("unchanged content was rewritten (stat %v)", err)
+	}
+
+	ws.Agents[0].Specialization = "billing"
+	if err := m.GenerateWhosWho(ws); err != nil {
+		t.Fatalf("GenerateWhosWho: %v", err)
+	}
+	if info, err := os.Stat(target); err != nil || info.ModTime().Equal(old) {
+		t.Errorf("changed content was not rewritten (stat %v)", err)
+	}
+}
+
+func TestRemoveWhosWho(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // "" = no file
+		wantKept bool
+	}{
+		{"generated", whosWhoHeader + "\n\n# Workspace Agents: test\n", false},
+		{"hand-written", "# My agents\n", true},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			target := filepath.Join(folder, ".claude", WhosWhoFileName)
+			if tt.content != "" {
+				os.MkdirAll(filepath.Dir(target), 0755)
+				if err := os.WriteFile(target, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := RemoveWhosWho(folder); err != nil {
+				t.Fatalf("RemoveWhosWho: %v", err)
+			}
+			if _, err := os.Stat(target); (err == nil) != tt.wantKept {
+				t.Errorf("file kept = %v, want %v", err == nil, tt.wantKept)
+			}
+		})
+	}
+}
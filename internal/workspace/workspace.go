@@ -3,28 +3,34 @@ package workspace
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"net"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"claudefu/internal/claudehome"
+	"claudefu/internal/fsutil"
+	"claudefu/internal/integrations"
+	"claudefu/internal/logging"
 	"claudefu/internal/types"
 )
 
+var logger = logging.For(logging.App)
+
 // Agent represents a configured agent in a workspace.
 // Identity (slug, description) comes from registry via PopulateAgentsFromRegistry.
 // There is no separate "name" — AGENT_SLUG is the single identifier everywhere.
 type Agent struct {
 	ID                string `json:"id"`                          // UUID for stable identification
 	Folder            string `json:"folder"`                      // Project folder path this agent monitors
-	WatchMode         string `json:"watchMode,omitempty"`         // "file" or "stream" (default: file)
+	WatchMode         string `json:"watchMode,omitempty"`         // "file", "stream", or "poll" (default: file)
 	SelectedSessionID string `json:"selectedSessionId,omitempty"` // Last viewed session for this agent
-	Provider          string `json:"provider,omitempty"`          // claude_code, anthropic, openai
+	Provider          string `json:"provider,omitempty"`          // claude_code (CLI, default), anthropic (Messages API) or codex (Codex CLI)
 	Specialization    string `json:"specialization,omitempty"`    // backend, frontend, devops, etc.
 	ClaudeMdPath      string `json:"claudeMdPath,omitempty"`      // Custom CLAUDE.md path override
 
@@ -35,6 +41,46 @@ type Agent struct {
 
 	// Per-workspace MCP config (stored in workspace JSON)
 	MCPEnabled *bool `json:"mcpEnabled,omitempty"` // Participates in inter-agent communication (default: true)
+
+	// Message post-processors applied by the runtime (see types.AllPostProcessors)
+	PostProcessors []string `json:"postProcessors,omitempty"`
+
+	// Free-form labels for scoping AgentMessage/AgentBroadcast (e.g. "api", "ui")
+	Tags []string `json:"tags,omitempty"`
+
+	// Sidebar section this agent is listed under (empty = ungrouped, see Workspace.Groups)
+	GroupID string `json:"groupId,omitempty"`
+
+	// Per-agent Claude CLI overrides (empty = global ClaudeCodeCommand / ClaudeExtraArgs)
+	ClaudeCommand string   `json:"claudeCommand,omitempty"` // Binary name or path, e.g. a wrapper script
+	ClaudeArgs    []string `json:"claudeArgs,omitempty"`    // Extra args added after the global ones
+
+	// Permission mode for sends that don't ask for plan mode: plan, acceptEdits,
+	// default or bypassPermissions (default: acceptEdits)
+	DefaultPermissionMode string `json:"defaultPermissionMode,omitempty"`
+
+	// Env vars for this agent's claude processes, applied over the global
+	// ClaudeEnvVars and the active env profile (e.g. ANTHROPIC_BASE_URL, ANTHROPIC_API_KEY)
+	Env map[string]string `json:"env,omitempty"`
+
+	// Per-agent session buffer limits (0 = global BufferMaxMessages / BufferMaxBytes)
+	BufferMaxMessages int   `json:"bufferMaxMessages,omitempty"` // Messages kept in memory per session
+	BufferMaxBytes    int64 `json:"bufferMaxBytes,omitempty"`    // Estimated bytes kept in memory per session
+
+	// Prepend undelivered inbox messages to the next message the user sends (default: false)
+	InboxAutoInject bool `json:"inboxAutoInject,omitempty"`
+
+	// Resume the selected session with high-priority inbox messages when the
+	// agent has no running claude process (default: false)
+	InboxAutoRespond bool `json:"inboxAutoRespond,omitempty"`
+}
+
+// GetDefaultPermissionMode returns the agent's permission mode, defaulting to "acceptEdits"
+func (a *Agent) GetDefaultPermissionMode() string {
+	if a.DefaultPermissionMode == "" {
+		return types.PermissionModeAcceptEdits
+	}
+	return a.DefaultPermissionMode
 }
 
 // GetWatchMode returns the agent's watch mode, defaulting to "file"
@@ -104,10 +150,61 @@ type SelectedSession struct {
 
 // MCPConfig holds MCP server configuration for a workspace
 type MCPConfig struct {
-	Enabled bool `json:"enabled"` // Master switch for MCP server (default: true)
-	Port    int  `json:"port"`    // SSE server port (default: 9315)
+	Enabled              bool `json:"enabled"`                        // Master switch for MCP server (default: true)
+	Port                 int  `json:"port"`                           // SSE server port (default: 9315)
+	MaxConcurrentQueries int  `json:"maxConcurrentQueries,omitempty"` // AgentQuery/SelfQuery child process limit (default: 2, -1 = unlimited)
+	QueryCacheTTLMinutes int  `json:"queryCacheTTLMinutes,omitempty"` // Reuse AgentQuery/SelfQuery answers this long (default: 0 = off)
+
+	// Remote answers: a token-protected page at http://<host>:<port>/remote/ for
+	// answering questions, permission requests, and plan reviews from a phone
+	RemoteEnabled bool   `json:"remoteEnabled,omitempty"`
+	RemoteToken   string `json:"remoteToken,omitempty"`
+
+	// Agent authentication: MCP requests must carry "Authorization: Bearer <AuthToken>".
+	// The token is generated when the server first starts and is injected into --mcp-config.
+	AuthToken    string `json:"authToken,omitempty"`
+	AuthDisabled bool   `json:"authDisabled,omitempty"` // Accept clients without the token (e.g. hand-configured external tools)
+
+	// BindAddress is the interface the server listens on (default: 127.0.0.1,
+	// or all interfaces while remote answers are on so phones can reach /remote/)
+	BindAddress string `json:"bindAddress,omitempty"`
+
+	// BrowserBridge overrides the app-wide BrowserAgent bridge endpoint for this workspace
+	BrowserBridge *BrowserBridgeConfig `json:"browserBridge,omitempty"`
+}
+
+// BrowserBridgeConfig is the endpoint of the Chrome extension bridge used by BrowserAgent
+type BrowserBridgeConfig struct {
+	Host               string `json:"host,omitempty"`               // (default: localhost)
+	Port               int    `json:"port,omitempty"`               // (default: 9320)
+	TLS                bool   `json:"tls,omitempty"`                // Use https/wss
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // Accept self-signed bridge certificates
+	Token              string `json:"token,omitempty"`              // Sent as "Authorization: Bearer <token>"
+}
+
+// DefaultBrowserBridgePort is the bridge port when unset
+const DefaultBrowserBridgePort = 9320
+
+// URL returns the bridge URL for path, with scheme "http" or "ws" (upgraded to
+// https/wss when TLS is on)
+func (c BrowserBridgeConfig) URL(scheme, path string) string {
+	host := c.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := c.Port
+	if port == 0 {
+		port = DefaultBrowserBridgePort
+	}
+	if c.TLS {
+		scheme += "s"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(port)), path)
 }
 
+// DefaultMaxConcurrentQueries is the AgentQuery/SelfQuery concurrency limit when unset
+const DefaultMaxConcurrentQueries = 2
+
 // GetPort returns the configured port or default (9315)
 func (c *MCPConfig) GetPort() int {
 	if c == nil || c.Port == 0 {
@@ -116,6 +213,74 @@ func (c *MCPConfig) GetPort() int {
 	return c.Port
 }
 
+// GetMaxConcurrentQueries returns the query concurrency limit (0 = unlimited)
+func (c *MCPConfig) GetMaxConcurrentQueries() int {
+	if c == nil || c.MaxConcurrentQueries == 0 {
+		return DefaultMaxConcurrentQueries
+	}
+	if c.MaxConcurrentQueries < 0 {
+		return 0
+	}
+	return c.MaxConcurrentQueries
+}
+
+// GetQueryCacheTTL returns how long AgentQuery/SelfQuery answers are cached (0 = off)
+func (c *MCPConfig) GetQueryCacheTTL() time.Duration {
+	if c == nil || c.QueryCacheTTLMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.QueryCacheTTLMinutes) * time.Minute
+}
+
+// GetBindAddress returns the host the server listens on ("" = all interfaces)
+func (c *MCPConfig) GetBindAddress() string {
+	if c == nil {
+		return "127.0.0.1"
+	}
+	switch c.BindAddress {
+	case "":
+	case "0.0.0.0", "::":
+		return ""
+	case "localhost":
+		return "127.0.0.1" // Clients resolving localhost to ::1 would miss an IPv4-only listener
+	default:
+		return c.BindAddress
+	}
+	if c.RemoteEnabled {
+		return ""
+	}
+	return "127.0.0.1"
+}
+
+// ClientHost returns the host local clients (the Claude CLI) connect to
+func (c *MCPConfig) ClientHost() string {
+	if addr := c.GetBindAddress(); addr != "" {
+		return addr
+	}
+	return "localhost"
+}
+
+// ValidateBindAddress checks that addr is empty, "localhost" or an IP address
+func ValidateBindAddress(addr string) error {
+	if addr == "" || addr == "localhost" || net.ParseIP(addr) != nil {
+		return nil
+	}
+	return fmt.Errorf("invalid bind address %q: use an IP address such as 127.0.0.1 or 0.0.0.0", addr)
+}
+
+// AuthRequired reports whether MCP clients must present the auth token
+func (c *MCPConfig) AuthRequired() bool {
+	return c == nil || !c.AuthDisabled
+}
+
+// RemoteAccessToken returns the remote answer token, or "" if remote answers are off
+func (c *MCPConfig) RemoteAccessToken() string {
+	if c == nil || !c.RemoteEnabled {
+		return ""
+	}
+	return c.RemoteToken
+}
+
 // IsEnabled returns whether MCP is enabled (default: true)
 func (c *MCPConfig) IsEnabled() bool {
 	if c == nil {
@@ -124,29 +289,75 @@ func (c *MCPConfig) IsEnabled() bool {
 	return c.Enabled
 }
 
+// EnvProfile is a named environment for a workspace (e.g. "dev", "prod-adjacent").
+// Switching profiles changes the env vars, permission guards, and protected paths
+// of every Claude process spawned in the workspace.
+type EnvProfile struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	EnvVars        map[string]string `json:"envVars,omitempty"`        // Override the global Claude env vars
+	DenyTiers      []string          `json:"denyTiers,omitempty"`      // Risk tiers denied in every permission set ("permissive", "yolo")
+	DenySets       []string          `json:"denySets,omitempty"`       // Permission set IDs denied entirely (e.g. "deploy")
+	DenyPatterns   []string          `json:"denyPatterns,omitempty"`   // Extra denied tool patterns (e.g. "Bash(terraform apply:*)")
+	ProtectedPaths []string          `json:"protectedPaths,omitempty"` // Paths Claude may not edit or write under
+}
+
 // Workspace represents a saved workspace configuration
 type Workspace struct {
-	Version         int              `json:"version"`                   // Schema version (4 = slim agents, no name/folder duplication)
-	ID              string           `json:"id"`
-	Name            string           `json:"name"`
-	Agents          []Agent          `json:"agents"`
-	MCPConfig       *MCPConfig       `json:"mcpConfig,omitempty"`       // MCP server configuration
-	SelectedSession *SelectedSession `json:"selectedSession,omitempty"` // In-memory only (set by populateWorkspaceFromState)
-	LastOpened      time.Time        `json:"lastOpened"`                // In-memory only (set by populateWorkspaceFromState); kept for backward compat read
+	Version         int                  `json:"version"` // Schema version (4 = slim agents, no name/folder duplication; 5 = agent groups)
+	ID              string               `json:"id"`
+	Name            string               `json:"name"`
+	Agents          []Agent              `json:"agents"`                    // In display order
+	Groups          []AgentGroup         `json:"groups,omitempty"`          // Agent list sections, in display order
+	MCPConfig       *MCPConfig           `json:"mcpConfig,omitempty"`       // MCP server configuration
+	EnvProfiles     []EnvProfile         `json:"envProfiles,omitempty"`     // Named environment profiles
+	ActiveProfile   string               `json:"activeProfile,omitempty"`   // Name of the active EnvProfile (empty = none)
+	Integrations    *integrations.Config `json:"integrations,omitempty"`    // Slack/Discord webhooks for NotifyUser, question timeouts, plan reviews
+	SelectedSession *SelectedSession     `json:"selectedSession,omitempty"` // In-memory only (set by populateWorkspaceFromState)
+	LastOpened      time.Time            `json:"lastOpened"`                // In-memory only (set by populateWorkspaceFromState); kept for backward compat read
+}
+
+// GetActiveProfile returns the active environment profile, or nil if none is active.
+func (ws *Workspace) GetActiveProfile() *EnvProfile {
+	if ws == nil || ws.ActiveProfile == "" {
+		return nil
+	}
+	for i := range ws.EnvProfiles {
+		if ws.EnvProfiles[i].Name == ws.ActiveProfile {
+			return &ws.EnvProfiles[i]
+		}
+	}
+	return nil
 }
 
 // CurrentWorkspaceVersion is the latest workspace schema version
-const CurrentWorkspaceVersion = 4
+const CurrentWorkspaceVersion = 5
 
 // agentDiskEntry is the slim on-disk representation of an agent.
 // Agent identity (name, folder, slug) lives exclusively in agents.json.
 // agentDiskEntry is the slim on-disk representation of an agent.
 // Agent identity (name, folder, slug, description) lives exclusively in agents.json registry.
-// Only per-workspace config (watchMode, mcpEnabled) is stored here.
+// Only per-workspace config (watchMode, mcpEnabled, postProcessors, specialization, tags,
+// group, provider, CLI overrides, buffer limits, inbox options) is stored here; env vars
+// are kept with the other credentials in local/secrets (see secrets.go).
 type agentDiskEntry struct {
-	ID         string `json:"id"`
-	WatchMode  string `json:"watchMode,omitempty"`
-	MCPEnabled *bool  `json:"mcpEnabled,omitempty"`
+	ID             string   `json:"id"`
+	WatchMode      string   `json:"watchMode,omitempty"`
+	MCPEnabled     *bool    `json:"mcpEnabled,omitempty"`
+	PostProcessors []string `json:"postProcessors,omitempty"`
+	Specialization string   `json:"specialization,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	GroupID        string   `json:"groupId,omitempty"`
+	Provider       string   `json:"provider,omitempty"`
+	ClaudeCommand  string   `json:"claudeCommand,omitempty"`
+	ClaudeArgs     []string `json:"claudeArgs,omitempty"`
+
+	DefaultPermissionMode string `json:"defaultPermissionMode,omitempty"`
+
+	BufferMaxMessages int   `json:"bufferMaxMessages,omitempty"`
+	BufferMaxBytes    int64 `json:"bufferMaxBytes,omitempty"`
+	InboxAutoInject   bool  `json:"inboxAutoInject,omitempty"`
+	InboxAutoRespond  bool  `json:"inboxAutoRespond,omitempty"`
 }
 
 // workspaceDisk is the on-disk representation of a workspace (v4 slim format).
@@ -155,7 +366,13 @@ type workspaceDisk struct {
 	ID        string           `json:"id"`
 	Name      string           `json:"name"`
 	Agents    []agentDiskEntry `json:"agents"`
+	Groups    []AgentGroup     `json:"groups,omitempty"`
 	MCPConfig *MCPConfig       `json:"mcpConfig,omitempty"`
+
+	EnvProfiles   []EnvProfile `json:"envProfiles,omitempty"`
+	ActiveProfile string       `json:"activeProfile,omitempty"`
+
+	Integrations *integrations.Config `json:"integrations,omitempty"`
 }
 
 // WorkspaceSummary is a minimal reference for listing workspaces
@@ -197,7 +414,7 @@ func isValidUUID(s string) bool {
 // UpgradeWorkspaceSchema upgrades a workspace to the current schema version.
 // This handles backwards compatibility for workspaces created before UUID support.
 func (m *Manager) UpgradeWorkspaceSchema(ws *Workspace) *Workspace {
-	if ws.Version < CurrentWorkspaceVersion {
+	if ws.Version < 4 {
 		// Migrate agents to have proper UUIDs (using registry for stability)
 		for i := range ws.Agents {
 			if ws.Agents[i].ID == "" || !isValidUUID(ws.Agents[i].ID) {
@@ -208,8 +425,10 @@ func (m *Manager) UpgradeWorkspaceSchema(ws *Workspace) *Workspace {
 				ws.Agents[i].WatchMode = types.WatchModeFile
 			}
 		}
-		ws.Version = CurrentWorkspaceVersion
 	}
+	// v5 adds optional agent groups; ungroup agents left pointing at a removed group
+	ws.pruneGroupRefs()
+	ws.Version = CurrentWorkspaceVersion
 	return ws
 }
 
@@ -238,17 +457,17 @@ func NewManager(configPath string) *Manager {
 	// Initialize and load registries (pure deserialization — no migrations in Load)
 	registry := NewAgentRegistry(configPath)
 	if err := registry.Load(); err != nil {
-		fmt.Printf("Warning: failed to load agent registry: %v\n", err)
+		logger.Warnf("failed to load agent registry: %v", err)
 	}
 
 	wsRegistry := NewWorkspaceRegistry(configPath)
 	if err := wsRegistry.Load(); err != nil {
-		fmt.Printf("Warning: failed to load workspace registry: %v\n", err)
+		logger.Warnf("failed to load workspace registry: %v", err)
 	}
 
 	metaSchema := NewMetaSchemaManager(configPath)
 	if err := metaSchema.Load(); err != nil {
-		fmt.Printf("Warning: failed to load meta schema: %v\n", err)
+		logger.Warnf("failed to load meta schema: %v", err)
 	}
 
 	m := &Manager{
@@ -260,7 +479,7 @@ func NewManager(configPath string) *Manager {
 
 	// Run sequential migrations (all migration logic lives in migrations.go)
 	if err := m.RunMigrations(); err != nil {
-		fmt.Printf("Warning: migration failed: %v\n", err)
+		logger.Warnf("migration failed: %v", err)
 	}
 
 	return m
@@ -431,7 +650,7 @@ func (m *Manager) EnsureSifuAgent(ws *Workspace, sifuEnabled bool, sifuRootFolde
 		if err := m.SaveWorkspace(ws); err != nil {
 			return fmt.Errorf("failed to save workspace after adding sifu: %w", err)
 		}
-		fmt.Printf("[INFO] EnsureSifuAgent: added sifu agent %s to workspace %s (prepended)\n", sifuSlug, ws.ID)
+		logger.Infof("EnsureSifuAgent: added sifu agent %s to workspace %s (prepended)", sifuSlug, ws.ID)
 	} else {
 		// Ensure sifu is at index 0 — it may have been appended by an older version
 		m.ensureSifuFirst(ws)
@@ -439,7 +658,7 @@ func (m *Manager) EnsureSifuAgent(ws *Workspace, sifuEnabled bool, sifuRootFolde
 
 	// Always refresh permissions (additive merge of all agent folders)
 	if genErr := m.GenerateSifuPermissions(ws, sifuFolder); genErr != nil {
-		fmt.Printf("[WARN] EnsureSifuAgent: failed to generate permissions: %v\n", genErr)
+		logger.Warnf("EnsureSifuAgent: failed to generate permissions: %v", genErr)
 	}
 
 	// CLAUDE.md is NOT auto-generated — user triggers via RefreshSifuAgent
@@ -464,9 +683,9 @@ func (m *Manager) ensureSifuFirst(ws *Workspace) {
 			sifu := ws.Agents[i]
 			ws.Agents = append(append([]Agent{sifu}, ws.Agents[:i]...), ws.Agents[i+1:]...)
 			if err := m.SaveWorkspace(ws); err != nil {
-				fmt.Printf("[WARN] ensureSifuFirst: failed to save: %v\n", err)
+				logger.Warnf("ensureSifuFirst: failed to save: %v", err)
 			} else {
-				fmt.Printf("[INFO] ensureSifuFirst: moved %s to index 0\n", sifu.GetSlug())
+				logger.Infof("ensureSifuFirst: moved %s to index 0", sifu.GetSlug())
 			}
 			return
 		}
@@ -589,13 +808,8 @@ func (m *Manager) GetAllWorkspaces() ([]WorkspaceSummary, error) {
 		}
 
 		wsPath := filepath.Join(workspacesDir, entry.Name())
-		data, err := os.ReadFile(wsPath)
-		if err != nil {
-			continue
-		}
-
 		var ws Workspace
-		if err := json.Unmarshal(data, &ws); err != nil {
+		if err := fsutil.ReadJSON(wsPath, &ws); err != nil {
 			continue
 		}
 
@@ -651,19 +865,14 @@ func (m *Manager) EnsureLocalDirs() error {
 // Reads from local/current.json (per-machine state).
 func (m *Manager) GetCurrentWorkspaceID() (string, error) {
 	currentPath := filepath.Join(m.configPath, "local", "current.json")
-	data, err := os.ReadFile(currentPath)
-	if err != nil {
+	var current CurrentWorkspace
+	if err := fsutil.ReadJSON(currentPath, &current); err != nil {
 		if os.IsNotExist(err) {
 			return "", nil // No current workspace set
 		}
 		return "", err
 	}
 
-	var current CurrentWorkspace
-	if err := json.Unmarshal(data, &current); err != nil {
-		return "", err
-	}
-
 	return current.ID, nil
 }
 
@@ -675,21 +884,18 @@ func (m *Manager) SetCurrentWorkspace(id string) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(m.configPath, "local", "current.json"), data, 0644)
+	return fsutil.WriteFileAtomic(filepath.Join(m.configPath, "local", "current.json"), data, 0644)
 }
 
 // LoadWorkspaceState reads per-machine runtime state from local/workspace-state/{id}.json
 func (m *Manager) LoadWorkspaceState(workspaceID string) *WorkspaceState {
 	statePath := filepath.Join(m.configPath, "local", "workspace-state", workspaceID+".json")
-	data, err := os.ReadFile(statePath)
-	if err != nil {
-		// Not found is normal (first run or new workspace)
-		return &WorkspaceState{}
-	}
-
 	var state WorkspaceState
-	if err := json.Unmarshal(data, &state); err != nil {
-		fmt.Printf("[WARN] Failed to parse workspace state %s: %v\n", workspaceID, err)
+	if err := fsutil.ReadJSON(statePath, &state); err != nil {
+		// Not found is normal (first run or new workspace)
+		if !os.IsNotExist(err) {
+			logger.Warnf("Failed to parse workspace state %s: %v", workspaceID, err)
+		}
 		return &WorkspaceState{}
 	}
 	return &state
@@ -726,7 +932,7 @@ func (m *Manager) SaveWorkspaceState(workspaceID string, state *WorkspaceState)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(statePath, data, 0644)
+	return fsutil.WriteFileAtomic(statePath, data, 0644)
 }
 
 // DeleteWorkspaceState removes the local workspace state file for a workspace.
@@ -775,14 +981,14 @@ func (m *Manager) ExtractRuntimeToStateFile(ws *Workspace) {
 
 	// Save to local state file
 	if err := m.SaveWorkspaceState(ws.ID, state); err != nil {
-		fmt.Printf("[WARN] Failed to save workspace state during migration: %v\n", err)
+		logger.Warnf("Failed to save workspace state during migration: %v", err)
 		return
 	}
 
 	// Clean runtime fields from workspace JSON
 	m.clearRuntimeFields(ws)
 
-	fmt.Printf("[INFO] Migrated runtime fields from workspace %s to local/workspace-state/\n", ws.ID)
+	logger.Infof("Migrated runtime fields from workspace %s to local/workspace-state/", ws.ID)
 }
 
 // clearRuntimeFields removes runtime fields from workspace in-memory
@@ -809,19 +1015,46 @@ func (m *Manager) SaveWorkspace(ws *Workspace) error {
 		ws.ID = GenerateWorkspaceID()
 	}
 
+	// Credentials go to local/secrets (see secrets.go), written first so a
+	// failure never leaves a workspace file without them
+	mcpConfig, integ, secrets := splitSecrets(ws)
+	if err := m.saveSecrets(ws.ID, secrets); err != nil {
+		return fmt.Errorf("failed to save workspace secrets: %w", err)
+	}
+
 	// Build slim disk struct — no name/folder/slug duplication
 	disk := workspaceDisk{
 		Version:   CurrentWorkspaceVersion,
 		ID:        ws.ID,
 		Name:      ws.Name,
-		MCPConfig: ws.MCPConfig,
+		Groups:    ws.Groups,
+		MCPConfig: mcpConfig,
+
+		EnvProfiles:   ws.EnvProfiles,
+		ActiveProfile: ws.ActiveProfile,
+
+		Integrations: integ,
 	}
 	disk.Agents = make([]agentDiskEntry, len(ws.Agents))
 	for i, a := range ws.Agents {
 		disk.Agents[i] = agentDiskEntry{
-			ID:         a.ID,
-			WatchMode:  a.WatchMode,
-			MCPEnabled: a.MCPEnabled,
+			ID:             a.ID,
+			WatchMode:      a.WatchMode,
+			MCPEnabled:     a.MCPEnabled,
+			PostProcessors: a.PostProcessors,
+			Specialization: a.Specialization,
+			Tags:           a.Tags,
+			GroupID:        a.GroupID,
+			Provider:       a.Provider,
+			ClaudeCommand:  a.ClaudeCommand,
+			ClaudeArgs:     a.ClaudeArgs,
+
+			DefaultPermissionMode: a.DefaultPermissionMode,
+
+			BufferMaxMessages: a.BufferMaxMessages,
+			BufferMaxBytes:    a.BufferMaxBytes,
+			InboxAutoInject:   a.InboxAutoInject,
+			InboxAutoRespond:  a.InboxAutoRespond,
 		}
 	}
 
@@ -831,8 +1064,16 @@ func (m *Manager) SaveWorkspace(ws *Workspace) error {
 	}
 
 	wsPath := filepath.Join(m.configPath, "workspaces", ws.ID+".json")
-	fmt.Printf("[DEBUG] SaveWorkspace: writing %d agents to %s (%d bytes)\n", len(disk.Agents), wsPath, len(data))
-	return os.WriteFile(wsPath, data, 0644)
+	legacy := hasInlineSecrets(wsPath)
+	logger.Debugf("SaveWorkspace: writing %d agents to %s (%d bytes)", len(disk.Agents), wsPath, len(data))
+	if err := fsutil.WriteFileWithBackup(wsPath, data, 0644); err != nil {
+		return err
+	}
+	if legacy {
+		// The backup copy is the old file with its inline credentials
+		return fsutil.WriteFileAtomic(wsPath+fsutil.BackupSuffix, data, 0644)
+	}
+	return nil
 }
 
 
@@ -840,18 +1081,14 @@ func (m *Manager) SaveWorkspace(ws *Workspace) error {
 func (m *Manager) LoadWorkspace(id string) (*Workspace, error) {
 	// Direct file lookup by ID
 	wsPath := filepath.Join(m.configPath, "workspaces", id+".json")
-	data, err := os.ReadFile(wsPath)
-	if err != nil {
+	var ws Workspace
+	if err := fsutil.ReadJSON(wsPath, &ws); err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("workspace not found: %s", id)
 		}
 		return nil, err
 	}
-
-	var ws Workspace
-	if err := json.Unmarshal(data, &ws); err != nil {
-		return nil, err
-	}
+	mergeSecrets(&ws, m.loadSecrets(id))
 
 	// Enrich agents with name/folder/slug from the registry (v4 slim format).
 	// Safe to call on old-format workspaces: PopulateAgentsFromRegistry skips agents
@@ -866,7 +1103,7 @@ func (m *Manager) LoadWorkspace(id string) (*Workspace, error) {
 	if m.workspaceRegistry != nil {
 		if regInfo := m.workspaceRegistry.GetInfo(ws.ID); regInfo != nil {
 			if regName := regInfo.GetName(); regName != "" && regName != ws.Name {
-				log.Printf("Syncing workspace name from registry: %q → %q (%s)", ws.Name, regName, ws.ID)
+				logger.Infof("Syncing workspace name from registry: %q → %q (%s)", ws.Name, regName, ws.ID)
 				ws.Name = regName
 				// Write back to ws-{id}.json to heal the discrepancy
 				_ = m.SaveWorkspace(&ws)
@@ -892,7 +1129,7 @@ func (m *Manager) CreateWorkspace(name string) (*Workspace, error) {
 	// Save initial workspace state (LastOpened) to local/
 	state := &WorkspaceState{LastOpened: time.Now()}
 	if err := m.SaveWorkspaceState(ws.ID, state); err != nil {
-		fmt.Printf("[WARN] Failed to save initial workspace state: %v\n", err)
+		logger.Warnf("Failed to save initial workspace state: %v", err)
 	}
 
 	// Register in workspace registry
@@ -902,7 +1139,7 @@ func (m *Manager) CreateWorkspace(name string) (*Workspace, error) {
 
 	// Set as current workspace
 	if err := m.SetCurrentWorkspace(ws.ID); err != nil {
-		fmt.Printf("[WARN] Failed to set current workspace: %v\n", err)
+		logger.Warnf("Failed to set current workspace: %v", err)
 	}
 
 	return ws, nil
@@ -910,9 +1147,7 @@ func (m *Manager) CreateWorkspace(name string) (*Workspace, error) {
 
 // GetSessions returns chat sessions for a folder from Claude Code's storage
 func (m *Manager) GetSessions(folder string) ([]Session, error) {
-	// Encode folder path like Claude Code does
-	encodedName := encodeProjectPath(folder)
-	projectDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName)
+	projectDir := claudehome.ProjectDir(folder)
 
 	entries, err := os.ReadDir(projectDir)
 	if err != nil {
@@ -962,18 +1197,6 @@ func (m *Manager) GetSessions(folder string) ([]Session, error) {
 	return sessions, nil
 }
 
-// encodeProjectPath encodes a folder path like Claude Code does.
-// Claude CLI replaces every non-alphanumeric character with "-".
-func encodeProjectPath(path string) string {
-	return encodeProjectPathRegex(path)
-}
-
-var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
-
-func encodeProjectPathRegex(path string) string {
-	return nonAlphanumeric.ReplaceAllString(path, "-")
-}
-
 // getSessionPreview reads first user message from session file using the classifier.
 func getSessionPreview(filePath string) (string, int) {
 	file, err := os.Open(filePath)
@@ -1056,8 +1279,7 @@ func (m *Manager) GetConversation(folder, sessionID string) (*Conversation, erro
 // limit: max messages to return (0 = all)
 // offset: skip this many messages from the end (for loading older messages)
 func (m *Manager) GetConversationPaged(folder, sessionID string, limit, offset int) (*Conversation, error) {
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	data, err := os.ReadFile(sessionPath)
 	if err != nil {
@@ -1135,8 +1357,7 @@ func (m *Manager) GetConversationPaged(folder, sessionID string, limit, offset i
 
 // GetUnreadCount returns the number of messages in a session after the given timestamp
 func (m *Manager) GetUnreadCount(folder, sessionID string, lastViewedMs int64) (int, error) {
-	encodedName := encodeProjectPath(folder)
-	sessionPath := filepath.Join(os.Getenv("HOME"), ".claude", "projects", encodedName, sessionID+".jsonl")
+	sessionPath := claudehome.SessionPath(folder, sessionID)
 
 	data, err := os.ReadFile(sessionPath)
 	if err != nil {
@@ -1212,16 +1433,7 @@ func (m *Manager) GetAllUnreadCounts(folder string, lastViewedMap map[string]int
 // The subagent files are stored at:
 // ~/.claude/projects/{encodedFolder}/{sessionID}/subagents/{subagentID}.jsonl
 func (m *Manager) GetSubagentConversation(folder, sessionID, subagentID string) ([]types.Message, error) {
-	encodedName := encodeProjectPath(folder)
-	subagentPath := filepath.Join(
-		os.Getenv("HOME"),
-		".claude",
-		"projects",
-		encodedName,
-		sessionID,
-		"subagents",
-		subagentID+".jsonl",
-	)
+	subagentPath := filepath.Join(claudehome.SubagentsDir(folder, sessionID), subagentID+".jsonl")
 
 	data, err := os.ReadFile(subagentPath)
 	if err != nil {
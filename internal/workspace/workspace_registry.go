@@ -3,12 +3,14 @@ package workspace
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+
+	"claudefu/internal/fsutil"
 )
 
 // WorkspaceInfo holds the full identity and metadata for a registered workspace.
@@ -56,16 +58,14 @@ func (r *WorkspaceRegistry) Load() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	raw, err := os.ReadFile(r.filePath)
-	if err != nil {
+	if err := fsutil.ReadJSON(r.filePath, &r.data); err != nil {
 		if os.IsNotExist(err) {
-			log.Printf("Workspace registry not found at %s, starting fresh", r.filePath)
+			logger.Infof("Workspace registry not found at %s, starting fresh", r.filePath)
 			return nil
 		}
-		return fmt.Errorf("failed to read workspace registry: %w", err)
-	}
-
-	if err := json.Unmarshal(raw, &r.data); err != nil {
+		if _, ok := err.(*fs.PathError); ok {
+			return fmt.Errorf("failed to read workspace registry: %w", err)
+		}
 		return fmt.Errorf("failed to parse workspace registry: %w", err)
 	}
 
@@ -115,9 +115,9 @@ func (r *WorkspaceRegistry) GetOrCreateInfo(workspaceID, name string) *Workspace
 	}
 	r.data.Workspaces[workspaceID] = info
 	if err := r.save(); err != nil {
-		log.Printf("Warning: failed to persist workspace registry: %v", err)
+		logger.Warnf("failed to persist workspace registry: %v", err)
 	}
-	log.Printf("Workspace registry: new entry %s → %s (%s)", workspaceID, name, info.GetSlug())
+	logger.Infof("Workspace registry: new entry %s → %s (%s)", workspaceID, name, info.GetSlug())
 	cp := info
 	return &cp
 }
@@ -157,7 +157,7 @@ func (r *WorkspaceRegistry) SyncName(workspaceID, name string) {
 	info.Meta["WORKSPACE_NAME"] = name
 	r.data.Workspaces[workspaceID] = info
 	if err := r.save(); err != nil {
-		log.Printf("Warning: failed to sync workspace name to registry: %v", err)
+		logger.Warnf("failed to sync workspace name to registry: %v", err)
 	}
 }
 
@@ -171,7 +171,21 @@ func (r *WorkspaceRegistry) Delete(workspaceID string) {
 	}
 	delete(r.data.Workspaces, workspaceID)
 	if err := r.save(); err != nil {
-		log.Printf("Warning: failed to persist workspace registry after delete: %v", err)
+		logger.Warnf("failed to persist workspace registry after delete: %v", err)
+	}
+}
+
+// Put inserts or replaces a workspace entry. Used when restoring from trash.
+func (r *WorkspaceRegistry) Put(info WorkspaceInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if info.Meta == nil {
+		info.Meta = make(map[string]string)
+	}
+	r.data.Workspaces[info.ID] = info
+	if err := r.save(); err != nil {
+		logger.Warnf("failed to persist workspace registry after put: %v", err)
 	}
 }
 
@@ -192,7 +206,7 @@ func (r *WorkspaceRegistry) save() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(r.filePath, data, 0644)
+	return fsutil.WriteFileWithBackup(r.filePath, data, 0644)
 }
 
 // marshalSorted produces deterministic JSON with workspace IDs sorted alphabetically.
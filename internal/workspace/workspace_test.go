@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSaveWorkspaceRoundTripsAgentSettings checks that per-agent settings
+// survive SaveWorkspace + LoadWorkspace (the slim v4+ disk format only keeps
+// the fields agentDiskEntry lists).
+func TestSaveWorkspaceRoundTripsAgentSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(*Agent)
+		get  func(Agent) any
+		want any
+	}{
+		{
+			name: "buffer max messages",
+			set:  func(a *Agent) { a.BufferMaxMessages = 250 },
+			get:  func(a Agent) any { return a.BufferMaxMessages },
+			want: 250,
+		},
+		{
+			name: "buffer max bytes",
+			set:  func(a *Agent) { a.BufferMaxBytes = 8 << 20 },
+			get:  func(a Agent) any { return a.BufferMaxBytes },
+			want: int64(8 << 20),
+		},
+		{
+			name: "inbox auto-inject",
+			set:  func(a *Agent) { a.InboxAutoInject = true },
+			get:  func(a Agent) any { return a.InboxAutoInject },
+			want: true,
+		},
+		{
+			name: "inbox auto-respond",
+			set:  func(a *Agent) { a.InboxAutoRespond = true },
+			get:  func(a Agent) any { return a.InboxAutoRespond },
+			want: true,
+		},
+		{
+			name: "env",
+			set:  func(a *Agent) { a.Env = map[string]string{"ANTHROPIC_BASE_URL": "http://localhost:4000"} },
+			get:  func(a Agent) any { return a.Env },
+			want: map[string]string{"ANTHROPIC_BASE_URL": "http://localhost:4000"},
+		},
+		{
+			name: "provider",
+			set:  func(a *Agent) { a.Provider = "codex" },
+			get:  func(a Agent) any { return a.Provider },
+			want: "codex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(t.TempDir())
+			agent := Agent{ID: GenerateAgentID(), Folder: "/src/api"}
+			tt.set(&agent)
+			ws := &Workspace{ID: GenerateWorkspaceID(), Name: "test", Agents: []Agent{agent}}
+			if err := m.SaveWorkspace(ws); err != nil {
+				t.Fatalf("SaveWorkspace: %v", err)
+			}
+			loaded, err := m.LoadWorkspace(ws.ID)
+			if err != nil {
+				t.Fatalf("LoadWorkspace: %v", err)
+			}
+			if len(loaded.Agents) != 1 {
+				t.Fatalf("loaded %d agents, want 1", len(loaded.Agents))
+			}
+			if got := tt.get(loaded.Agents[0]); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("after reload = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
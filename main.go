@@ -123,6 +123,11 @@ func parseCLIArgs() *CLIArgs {
 }
 
 func main() {
+	// Headless subcommands (`claudefu send|sessions|agents ...`) run without the GUI
+	if runHeadlessCommand(os.Args[1:]) {
+		return
+	}
+
 	// Parse CLI args before Wails starts (e.g., `claudefu .` or `claudefu /path --workspace "name"`)
 	cliArgs := parseCLIArgs()
 